@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	acctFile      string
+	acctHostsCSV  string
+	acctHostsFile string
+	acctInsecure  bool
+	acctTimeout   time.Duration
+	acctBatchSize int
+	acctDryRun    bool
+	acctUsername  string
+)
+
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "Manage BMC accounts via the Redfish AccountService",
+}
+
+var accountsSetPasswordCmd = &cobra.Command{
+	Use:   "set-password",
+	Short: "Change the password of --username on every BMC",
+	Long: `Change the password of the AccountService account named --username on every
+selected BMC, then log in with the new password to verify it. The new
+password is read from REDFISH_NEW_PASSWORD or, when that is unset, prompted
+for twice on the terminal; it is never taken from a flag. REDFISH_USER and
+REDFISH_PASSWORD are the credentials used to make the change, and may be
+the account being changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if acctUsername == "" {
+			return errors.New("--username is required")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(acctFile, acctHostsCSV, acctHostsFile)
+		if err != nil {
+			return err
+		}
+		var newPassword string
+		if !acctDryRun {
+			if newPassword, err = newAccountPassword(); err != nil {
+				return err
+			}
+		}
+
+		var mu sync.Mutex
+		var ok, failed int
+		forEachTarget(cmd.Context(), targets, acctBatchSize, acctTimeout, func(ctx context.Context, t bmcTarget) {
+			var path string
+			var err error
+			if acctDryRun {
+				path, err = redfish.FindAccount(ctx, t.Host, user, pass, acctInsecure, acctTimeout, acctUsername)
+			} else {
+				path, err = redfish.SetAccountPassword(ctx, t.Host, user, pass, acctInsecure, acctTimeout, acctUsername, newPassword)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed++
+				diag.Warnf("%s: set-password: %v", t.label(), err)
+			case acctDryRun:
+				ok++
+				fmt.Printf("[dry-run] would PATCH Password of %s on %s (%s)\n", path, t.label(), t.Host)
+			default:
+				ok++
+				diag.Infof("%s: password of %s changed and verified", t.label(), path)
+			}
+		})
+
+		fmt.Printf("Set password: %d succeeded, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "set-password failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// newAccountPassword returns REDFISH_NEW_PASSWORD, or prompts for the new
+// password twice when stdin is a terminal.
+func newAccountPassword() (string, error) {
+	if pw := os.Getenv("REDFISH_NEW_PASSWORD"); pw != "" {
+		return pw, nil
+	}
+	if confirmInput == os.Stdin && !stdinIsTerminal() {
+		return "", errors.New("REDFISH_NEW_PASSWORD is not set and stdin is not a terminal to prompt on")
+	}
+	pw, err := readSecret("New BMC password: ")
+	if err != nil {
+		return "", err
+	}
+	again, err := readSecret("Repeat new BMC password: ")
+	if err != nil {
+		return "", err
+	}
+	if pw == "" {
+		return "", errors.New("the new password must not be empty")
+	}
+	if pw != again {
+		return "", errors.New("the passwords do not match")
+	}
+	return pw, nil
+}
+
+func init() {
+	rootCmd.AddCommand(accountsCmd)
+	accountsCmd.AddCommand(accountsSetPasswordCmd)
+	accountsCmd.PersistentFlags().StringVarP(&acctFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	accountsCmd.PersistentFlags().StringVar(&acctHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	accountsCmd.PersistentFlags().StringVar(&acctHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	accountsCmd.PersistentFlags().BoolVar(&acctInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	accountsCmd.PersistentFlags().DurationVar(&acctTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	accountsCmd.PersistentFlags().IntVar(&acctBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	accountsSetPasswordCmd.Flags().StringVar(&acctUsername, "username", "", "account whose password is changed (its UserName in AccountService)")
+	accountsSetPasswordCmd.Flags().BoolVar(&acctDryRun, "dry-run", false, "plan only: print the account URI that would be patched on each BMC")
+}
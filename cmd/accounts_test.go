@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAccountPassword(t *testing.T) {
+	old := confirmInput
+	defer func() { confirmInput = old }()
+	t.Setenv("REDFISH_NEW_PASSWORD", "")
+
+	confirmInput = strings.NewReader("s3cret\ns3cret\n")
+	if pw, err := newAccountPassword(); err != nil || pw != "s3cret" {
+		t.Errorf("newAccountPassword = %q, %v", pw, err)
+	}
+	confirmInput = strings.NewReader("s3cret\nsecret\n")
+	if _, err := newAccountPassword(); err == nil || !strings.Contains(err.Error(), "do not match") {
+		t.Errorf("expected a mismatch error, got %v", err)
+	}
+	confirmInput = strings.NewReader("\n\n")
+	if _, err := newAccountPassword(); err == nil {
+		t.Error("expected an error for an empty password")
+	}
+
+	// The environment takes precedence over the prompt
+	t.Setenv("REDFISH_NEW_PASSWORD", "from-env")
+	if pw, err := newAccountPassword(); err != nil || pw != "from-env" {
+		t.Errorf("newAccountPassword = %q, %v", pw, err)
+	}
+}
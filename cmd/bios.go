@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	biosFile      string
+	biosHostsCSV  string
+	biosHostsFile string
+	biosInsecure  bool
+	biosTimeout   time.Duration
+	biosBatchSize int
+	biosDryRun    bool
+	biosFromFile  string
+)
+
+var biosCmd = &cobra.Command{
+	Use:   "bios",
+	Short: "Read and stage BIOS attributes via the Redfish Bios resource",
+}
+
+var biosGetCmd = &cobra.Command{
+	Use:   "get [attribute...]",
+	Short: "Print BIOS attributes (all, or the ones named) of every system",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(biosFile, biosHostsCSV, biosHostsFile)
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var ok, failed int
+		forEachTarget(cmd.Context(), targets, biosBatchSize, biosTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.GetBios(ctx, t.Host, user, pass, biosInsecure, biosTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: bios get: %v", t.label(), err)
+				return
+			}
+			for _, s := range systems {
+				if s.Err != nil {
+					failed++
+					diag.Warnf("%s %s: bios get: %v", t.label(), path.Base(s.SystemPath), s.Err)
+					continue
+				}
+				ok++
+				names := args
+				if len(names) == 0 {
+					for k := range s.Attributes {
+						names = append(names, k)
+					}
+					sort.Strings(names)
+				}
+				for _, name := range names {
+					v, found := s.Attributes[name]
+					line := fmt.Sprintf("%s %s: %s=%v", t.label(), path.Base(s.SystemPath), name, v)
+					if !found {
+						line = fmt.Sprintf("%s %s: %s not present", t.label(), path.Base(s.SystemPath), name)
+					}
+					if p, staged := s.Pending[name]; staged {
+						line += fmt.Sprintf(" (pending: %v)", p)
+					}
+					fmt.Println(line)
+				}
+			}
+		})
+		fmt.Printf("BIOS get: %d system(s) reported, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "bios get failed for %d system(s) or BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var biosSetCmd = &cobra.Command{
+	Use:   "set [name=value...]",
+	Short: "Stage BIOS attributes on every system, skipping systems already set",
+	Long: `Stage BIOS attributes in the Bios settings object (@Redfish.Settings) of
+every system. Attributes come from --from-file (a YAML or JSON map) and from
+name=value arguments, which take precedence. Each system's current and
+desired values are compared first, and only differing attributes are sent;
+systems that already match are skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		attrs, err := biosAttributes(biosFromFile, args)
+		if err != nil {
+			return err
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(biosFile, biosHostsCSV, biosHostsFile)
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var changed, unchanged, failed, reboot int
+		forEachTarget(cmd.Context(), targets, biosBatchSize, biosTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.SetBios(ctx, t.Host, user, pass, biosInsecure, biosTimeout, attrs, biosDryRun)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: bios set: %v", t.label(), err)
+				return
+			}
+			for _, s := range systems {
+				sys := path.Base(s.SystemPath)
+				for _, c := range s.Changes {
+					fmt.Printf("%s %s: %s %v -> %v\n", t.label(), sys, c.Name, c.Current, c.Desired)
+				}
+				switch {
+				case s.Err != nil:
+					failed++
+					diag.Warnf("%s %s: bios set: %v", t.label(), sys, s.Err)
+				case len(s.Changes) == 0:
+					unchanged++
+					diag.Infof("%s %s: BIOS already set; skipped", t.label(), sys)
+				case biosDryRun:
+					changed++
+					body, _ := json.Marshal(s.Body)
+					fmt.Printf("[dry-run] would PATCH %s on %s with %s\n", s.Path, t.label(), body)
+				default:
+					changed++
+					note := ""
+					if s.RebootRequired {
+						reboot++
+						note = "; reboot required"
+					}
+					diag.Infof("%s %s: staged %d BIOS attribute(s)%s", t.label(), sys, len(s.Changes), note)
+				}
+			}
+		})
+		verb := "changed"
+		if biosDryRun {
+			verb = "would change"
+		}
+		fmt.Printf("BIOS set: %d system(s) %s, %d unchanged, %d failed\n", changed, verb, unchanged, failed)
+		if reboot > 0 {
+			fmt.Printf("%d system(s) need a reboot to apply the new BIOS settings\n", reboot)
+		}
+		if err := checkOutcome(changed+unchanged, failed, "bios set failed for %d system(s) or BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// biosAttributes merges the attribute map in file (YAML or JSON) with
+// name=value arguments, which win.
+func biosAttributes(file string, args []string) (map[string]any, error) {
+	attrs := map[string]any{}
+	if file != "" {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(raw, &attrs); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+	}
+	for _, a := range args {
+		name, value, ok := strings.Cut(a, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid attribute %q (want name=value)", a)
+		}
+		attrs[name] = value
+	}
+	if len(attrs) == 0 {
+		return nil, errors.New("no attributes to set (pass name=value arguments or --from-file)")
+	}
+	return attrs, nil
+}
+
+func init() {
+	rootCmd.AddCommand(biosCmd)
+	biosCmd.AddCommand(biosGetCmd, biosSetCmd)
+	biosCmd.PersistentFlags().StringVarP(&biosFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	biosCmd.PersistentFlags().StringVar(&biosHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	biosCmd.PersistentFlags().StringVar(&biosHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	biosCmd.PersistentFlags().BoolVar(&biosInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	biosCmd.PersistentFlags().DurationVar(&biosTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	biosCmd.PersistentFlags().IntVar(&biosBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	biosSetCmd.Flags().StringVar(&biosFromFile, "from-file", "", "YAML or JSON map of BIOS attributes to set")
+	biosSetCmd.Flags().BoolVar(&biosDryRun, "dry-run", false, "plan only: print each system's changes and PATCH body without sending it")
+}
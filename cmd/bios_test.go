@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBiosAttributes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "bios.yaml")
+	if err := os.WriteFile(file, []byte("BootMode: Legacy\nSriovEnable: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := biosAttributes(file, []string{"BootMode=Uefi", "Label=a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"BootMode": "Uefi", "SriovEnable": true, "Label": "a=b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("biosAttributes = %v, want %v", got, want)
+	}
+	for _, args := range [][]string{nil, {"BootMode"}, {"=Uefi"}} {
+		if _, err := biosAttributes("", args); err == nil {
+			t.Errorf("biosAttributes(%q): expected an error", args)
+		}
+	}
+}
+
+func TestBiosSetDryRunShowsDiff(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`))
+		case "/redfish/v1/Systems/Node0":
+			_, _ = w.Write([]byte(`{}`))
+		case "/redfish/v1/Systems/Node0/Bios":
+			_, _ = w.Write([]byte(`{"Attributes":{"BootMode":"Legacy","SriovEnable":true}}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	biosHostsCSV = strings.TrimPrefix(srv.URL, "https://")
+	biosInsecure, biosDryRun = true, true
+	defer func() { biosHostsCSV, biosInsecure, biosDryRun = "", false, false }()
+
+	biosSetCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error {
+		return biosSetCmd.RunE(biosSetCmd, []string{"BootMode=Uefi", "SriovEnable=true"})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Node0: BootMode Legacy -> Uefi",
+		`[dry-run] would PATCH /redfish/v1/Systems/Node0/Bios on`,
+		`{"Attributes":{"BootMode":"Uefi"}}`,
+		"BIOS set: 1 system(s) would change, 0 unchanged, 0 failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "SriovEnable") {
+		t.Errorf("unchanged attribute reported:\n%s", out)
+	}
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bmcFile        string
+	bmcHostsCSV    string
+	bmcHostsFile   string
+	bmcInsecure    bool
+	bmcTimeout     time.Duration
+	bmcDryRun      bool
+	bmcBatchSize   int
+	bmcForce       bool
+	bmcWait        bool
+	bmcWaitTimeout time.Duration
+	bmcStagger     time.Duration
+	bmcXnames      []string
+	bmcYes         bool
+)
+
+// bmcPollInterval is how often --wait probes a resetting BMC.
+var bmcPollInterval = 5 * time.Second
+
+var bmcCmd = &cobra.Command{
+	Use:   "bmc",
+	Short: "Manage the BMCs themselves via Redfish Managers",
+}
+
+var bmcResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Restart the selected BMCs (Manager.Reset GracefulRestart, or ForceRestart with --force)",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		sel, err := selectTargets(bmcFile, bmcHostsCSV, bmcHostsFile, bmcXnames)
+		if err != nil {
+			return err
+		}
+		resetType := "GracefulRestart"
+		if bmcForce {
+			resetType = "ForceRestart"
+		}
+
+		if bmcDryRun {
+			for _, t := range sel.targets {
+				fmt.Printf("[dry-run] would POST Manager.Reset ResetType=%s to %s (%s)\n", resetType, t.label(), t.Host)
+			}
+			return nil
+		}
+		if err := confirmDestructive("restart the BMC", sel, []string{"ResetType=" + resetType}, bmcYes); err != nil {
+			return err
+		}
+
+		staggerMu.Lock()
+		staggerNext = map[string]time.Time{}
+		staggerMu.Unlock()
+
+		var mu sync.Mutex
+		var ok, failed int
+		// The per-host timeout is applied after any stagger delay
+		forEachTarget(cmd.Context(), sel.targets, bmcBatchSize, 0, func(ctx context.Context, t bmcTarget) {
+			hl := diag.HostLogFrom(ctx)
+			if err := staggerWait(ctx, t); err != nil {
+				return
+			}
+			rctx, cancel := ctx, context.CancelFunc(func() {})
+			if bmcTimeout > 0 {
+				rctx, cancel = context.WithTimeout(ctx, bmcTimeout)
+			}
+			err := redfish.ResetManager(rctx, t.Host, user, pass, bmcInsecure, bmcTimeout, resetType)
+			cancel()
+			resetAt := time.Now()
+			if err != nil {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+				hl.Warnf("bmc reset: %v", err)
+				return
+			}
+			hl.Infof("%s requested", resetType)
+			if !bmcWait {
+				mu.Lock()
+				ok++
+				mu.Unlock()
+				return
+			}
+			wctx, cancel := context.WithTimeout(ctx, bmcWaitTimeout)
+			took, err := redfish.WaitForRecovery(wctx, t.Host, user, pass, bmcInsecure, resetAt, bmcPollInterval, 2*bmcPollInterval)
+			cancel()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				hl.Warnf("not back after %s: %v", took.Round(time.Second), err)
+				return
+			}
+			ok++
+			hl.Infof("back after %s", took.Round(time.Second))
+		})
+
+		fmt.Printf("BMC reset: %d succeeded, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "bmc reset failed for %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var (
+	staggerMu   sync.Mutex
+	staggerNext = map[string]time.Time{}
+)
+
+// staggerWait delays t until --stagger has passed since the previous reset
+// in the same chassis, so both controllers of a blade are never restarted
+// together. Hosts without a chassis xname are not delayed.
+func staggerWait(ctx context.Context, t bmcTarget) error {
+	chassis := xname.Chassis(t.Xname)
+	if bmcStagger <= 0 || chassis == "" {
+		return nil
+	}
+	staggerMu.Lock()
+	start := time.Now()
+	if next := staggerNext[chassis]; next.After(start) {
+		start = next
+	}
+	staggerNext[chassis] = start.Add(bmcStagger)
+	staggerMu.Unlock()
+
+	select {
+	case <-time.After(time.Until(start)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(bmcCmd)
+	bmcCmd.AddCommand(bmcResetCmd)
+	bmcCmd.PersistentFlags().StringVarP(&bmcFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	bmcCmd.PersistentFlags().StringVar(&bmcHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	bmcCmd.PersistentFlags().StringVar(&bmcHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	bmcCmd.PersistentFlags().BoolVar(&bmcInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	bmcCmd.PersistentFlags().DurationVar(&bmcTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	bmcCmd.PersistentFlags().BoolVar(&bmcDryRun, "dry-run", false, "plan only: print actions without posting")
+	bmcCmd.PersistentFlags().IntVar(&bmcBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	bmcResetCmd.Flags().BoolVar(&bmcForce, "force", false, "use ForceRestart instead of GracefulRestart")
+	bmcResetCmd.Flags().BoolVar(&bmcWait, "wait", false, "poll each BMC until it answers Redfish again and report how long it took")
+	bmcResetCmd.Flags().DurationVar(&bmcWaitTimeout, "wait-timeout", 10*time.Minute, "how long --wait waits for each BMC")
+	bmcResetCmd.Flags().DurationVar(&bmcStagger, "stagger", 0, "minimum delay between resets of BMCs in the same chassis")
+	bmcResetCmd.Flags().StringSliceVar(&bmcXnames, "xname", nil, "only the BMCs within these cabinet, chassis, slot, BMC, or node xnames (comma-separated or repeated)")
+	bmcResetCmd.Flags().BoolVarP(&bmcYes, "yes", "y", false, "skip the confirmation prompt")
+}
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	bmcSettingsFile string
+	bmcNTP          string
+	bmcNTPServers   []string
+	bmcSyslog       string
+	bmcSyslogTarget []string
+)
+
+// bmcSettings is the --settings file of bmc config.
+type bmcSettings struct {
+	NTP struct {
+		Enabled *bool    `yaml:"enabled"`
+		Servers []string `yaml:"servers"`
+	} `yaml:"ntp"`
+	Syslog struct {
+		Enabled *bool    `yaml:"enabled"`
+		Servers []string `yaml:"servers"`
+	} `yaml:"syslog"`
+}
+
+var bmcConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Set NTP and remote syslog on the selected BMCs",
+	Long: `Set the NTP servers and remote syslog targets of the selected BMCs through
+the first Manager's NetworkProtocol resource. The desired settings come from
+--settings, a YAML file such as
+
+  ntp:
+    enabled: true
+    servers: [10.1.0.1, 10.1.0.2]
+  syslog:
+    enabled: true
+    servers: [10.1.0.3:514]
+
+and from the --ntp, --ntp-servers, --syslog, and --syslog-servers flags,
+which take precedence. Settings that are not given are left as they are.
+
+For every BMC the current and desired values are printed before anything
+is changed; --dry-run stops there. A setting a BMC has no property for is
+noted as "unsupported: <field>" without failing the BMC.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		want, err := desiredManagerConfig()
+		if err != nil {
+			return err
+		}
+		if want.NTPEnabled == nil && want.NTPServers == nil && want.SyslogEnabled == nil && want.SyslogServers == nil {
+			return errors.New("nothing to configure: give --settings or at least one of --ntp, --ntp-servers, --syslog, --syslog-servers")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(bmcFile, bmcHostsCSV, bmcHostsFile)
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var changed, unchanged, failed int
+		forEachTarget(cmd.Context(), targets, bmcBatchSize, bmcTimeout, func(ctx context.Context, t bmcTarget) {
+			plan, err := redfish.PlanManagerConfig(ctx, t.Host, user, pass, bmcInsecure, bmcTimeout, want)
+			if err == nil {
+				// The diff is shown before the BMC is changed
+				mu.Lock()
+				fmt.Print(formatConfigPlan(t, plan))
+				mu.Unlock()
+				if !bmcDryRun {
+					err = redfish.ApplyManagerConfig(ctx, t.Host, user, pass, bmcInsecure, bmcTimeout, plan)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: bmc config: %v", t.label(), err)
+				return
+			}
+			if len(plan.Changes) > 0 {
+				changed++
+			} else {
+				unchanged++
+			}
+		})
+
+		verb := "changed"
+		if bmcDryRun {
+			verb = "would change"
+		}
+		fmt.Printf("BMC config: %d %s, %d unchanged, %d failed\n", changed, verb, unchanged, failed)
+		if err := checkOutcome(changed+unchanged, failed, "bmc config failed for %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// desiredManagerConfig merges --settings with the flags.
+func desiredManagerConfig() (redfish.ManagerConfig, error) {
+	var want redfish.ManagerConfig
+	if bmcSettingsFile != "" {
+		raw, err := os.ReadFile(bmcSettingsFile)
+		if err != nil {
+			return want, err
+		}
+		var s bmcSettings
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		if err := dec.Decode(&s); err != nil {
+			return want, fmt.Errorf("%s: %w", bmcSettingsFile, err)
+		}
+		want = redfish.ManagerConfig{NTPEnabled: s.NTP.Enabled, NTPServers: s.NTP.Servers, SyslogEnabled: s.Syslog.Enabled, SyslogServers: s.Syslog.Servers}
+	}
+	var err error
+	if want.NTPEnabled, err = parseEnable("--ntp", bmcNTP, want.NTPEnabled); err != nil {
+		return want, err
+	}
+	if want.SyslogEnabled, err = parseEnable("--syslog", bmcSyslog, want.SyslogEnabled); err != nil {
+		return want, err
+	}
+	if len(bmcNTPServers) > 0 {
+		want.NTPServers = bmcNTPServers
+	}
+	if len(bmcSyslogTarget) > 0 {
+		want.SyslogServers = bmcSyslogTarget
+	}
+	return want, nil
+}
+
+// parseEnable returns def for an empty value, or whether value is "enable".
+func parseEnable(flag, value string, def *bool) (*bool, error) {
+	switch value {
+	case "":
+		return def, nil
+	case "enable", "disable":
+		on := value == "enable"
+		return &on, nil
+	}
+	return nil, fmt.Errorf("%s must be enable or disable, got %q", flag, value)
+}
+
+// formatConfigPlan renders the current and desired values of one BMC.
+func formatConfigPlan(t bmcTarget, plan redfish.ManagerConfigPlan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s):", t.label(), t.Host)
+	if len(plan.Changes) == 0 && len(plan.Unsupported) == 0 {
+		b.WriteString(" no changes\n")
+		return b.String()
+	}
+	b.WriteString("\n")
+	for _, c := range plan.Changes {
+		fmt.Fprintf(&b, "  %s: %s -> %s\n", c.Field, c.Current, c.Desired)
+	}
+	for _, f := range plan.Unsupported {
+		fmt.Fprintf(&b, "  unsupported: %s\n", f)
+	}
+	return b.String()
+}
+
+func init() {
+	bmcCmd.AddCommand(bmcConfigCmd)
+	bmcConfigCmd.Flags().StringVar(&bmcSettingsFile, "settings", "", "YAML file with the desired ntp and syslog settings")
+	bmcConfigCmd.Flags().StringVar(&bmcNTP, "ntp", "", "enable or disable NTP")
+	bmcConfigCmd.Flags().StringSliceVar(&bmcNTPServers, "ntp-servers", nil, "NTP servers to set, replacing the current list")
+	bmcConfigCmd.Flags().StringVar(&bmcSyslog, "syslog", "", "enable or disable remote syslog")
+	bmcConfigCmd.Flags().StringSliceVar(&bmcSyslogTarget, "syslog-servers", nil, "remote syslog targets (host or host:port) to set, replacing the current list")
+}
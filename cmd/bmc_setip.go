@@ -0,0 +1,356 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bmcCurrentFile   string
+	bmcLeasesFile    string
+	bmcNetmask       string
+	bmcGateway       string
+	bmcVerifyTimeout time.Duration
+	bmcKeepGoing     bool
+)
+
+// Results of bmc set-ip for one BMC.
+const (
+	setIPMoved      = "moved"
+	setIPAlready    = "already-set"
+	setIPRejected   = "rejected"
+	setIPUnverified = "unverified"
+	setIPNotStarted = "not-started"
+)
+
+// setIPRow is one BMC of bmc set-ip: where it was, where it is going, and
+// where it answered last.
+type setIPRow struct {
+	xname  string
+	mac    string
+	old    string // the current (DHCP) address
+	new    string // the planned address from bmcs[], possibly with a port
+	result string
+	nowOn  string // the address it answers on, or a note
+}
+
+var bmcSetIPCmd = &cobra.Command{
+	Use:   "set-ip",
+	Short: "Move BMCs from their DHCP address to the static address planned in bmcs[]",
+	Long: `Give every BMC of the inventory the static IPv4 address planned for it in
+bmcs[], with --netmask and --gateway, by PATCHing the EthernetInterface of
+its Manager and turning DHCPv4 off.
+
+Each BMC is reached at its current address, from --current (lines of
+address,xname as in --hosts-file) or else from the dnsmasq --leases-file
+lease of its bmcs[] MAC. After the change, the BMC must answer an
+authenticated Redfish request on its new address, from the same interface,
+within --verify-timeout before the next BMC is started. The first failure
+stops the run unless --keep-going is given.
+
+The table printed at the end records, for every BMC, which address it
+answers on now: the new one, the old one when the change was refused or not
+applied, or neither.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if bmcFile == "" {
+			return errors.New("--file is required: the planned addresses come from its bmcs[]")
+		}
+		if bmcHostsCSV != "" || bmcHostsFile != "" {
+			return errors.New("set-ip takes its BMCs from --file; give their current addresses with --current")
+		}
+		if bmcCurrentFile == "" && bmcLeasesFile == "" {
+			return errors.New("give --current or --leases-file to find the current address of each BMC")
+		}
+		mask, err := parseNetmask(bmcNetmask)
+		if err != nil {
+			return err
+		}
+		var gateway net.IP
+		if bmcGateway != "" {
+			if gateway = net.ParseIP(bmcGateway).To4(); gateway == nil {
+				return fmt.Errorf("--gateway %q is not an IPv4 address", bmcGateway)
+			}
+		}
+		rows, err := planSetIP(mask, gateway)
+		if err != nil {
+			return err
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+
+		index := map[bmcTarget]int{}
+		var all []bmcTarget
+		for i, r := range rows {
+			if r.result != "" {
+				continue
+			}
+			t := bmcTarget{Host: r.old, Xname: r.xname}
+			index[t] = i
+			all = append(all, t)
+		}
+		targets, err := filterByXname(all, bmcXnames)
+		if err != nil {
+			return err
+		}
+		sel := selection{targets: targets, total: len(all), xnames: bmcXnames}
+		cfg := redfish.IPv4Static{SubnetMask: net.IP(mask).String()}
+		if gateway != nil {
+			cfg.Gateway = gateway.String()
+		}
+
+		if bmcDryRun {
+			for _, t := range targets {
+				r := rows[index[t]]
+				fmt.Printf("[dry-run] would move %s from %s to %s netmask %s gateway %s\n", t.label(), r.old, r.new, cfg.SubnetMask, valueOrDash(cfg.Gateway))
+			}
+			return nil
+		}
+		if err := confirmDestructive("change the address of the BMC", sel, []string{"netmask " + cfg.SubnetMask, "gateway " + valueOrDash(cfg.Gateway)}, bmcYes); err != nil {
+			return err
+		}
+
+		// A failure cancels runCtx so no further BMC is started
+		runCtx, stop := context.WithCancel(cmd.Context())
+		defer stop()
+		var mu sync.Mutex
+		var moved, failed int
+		for _, t := range targets {
+			rows[index[t]].result = setIPNotStarted
+		}
+		forEachTarget(runCtx, targets, 1, 0, func(ctx context.Context, t bmcTarget) {
+			r := &rows[index[t]]
+			c := cfg
+			c.Address = hostWithoutPort(r.new)
+			setBMCAddress(ctx, diag.HostLogFrom(ctx), r, c, user, pass)
+			mu.Lock()
+			defer mu.Unlock()
+			if r.result == setIPMoved {
+				moved++
+				return
+			}
+			failed++
+			if !bmcKeepGoing {
+				stop()
+			}
+		})
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "XNAME\tOLD\tNEW\tRESULT\tNOW ON")
+		counts := map[string]int{}
+		for _, r := range rows {
+			if r.result == "" {
+				continue
+			}
+			counts[r.result]++
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.xname, valueOrDash(r.old), r.new, r.result, valueOrDash(r.nowOn))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("BMC set-ip: %d moved, %d already set, %d failed, %d not started\n", moved, counts[setIPAlready], failed, counts[setIPNotStarted])
+		if failed > 0 && !bmcKeepGoing && counts[setIPNotStarted] > 0 {
+			diag.Warnf("stopped after the first failure; pass --keep-going to continue past failures")
+		}
+		if err := checkOutcome(moved+counts[setIPAlready], failed, "bmc set-ip failed for %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// planSetIP reads the BMCs of --file with their current addresses. BMCs
+// without a planned or current address are logged and left out; those
+// already on their planned address are marked setIPAlready. A planned
+// address outside the subnet of gateway is an error, found before any BMC
+// is changed.
+func planSetIP(mask net.IPMask, gateway net.IP) ([]setIPRow, error) {
+	doc, err := readInventory(bmcFile)
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]string{}
+	if bmcLeasesFile != "" {
+		leases, err := readLeases(bmcLeasesFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range doc.BMCs {
+			if ip := leases[strings.ToLower(b.MAC)]; ip != "" {
+				current[b.Xname] = ip
+			}
+		}
+	}
+	if bmcCurrentFile != "" {
+		listed, err := readHostsFile(bmcCurrentFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range listed {
+			if t.Xname == "" {
+				return nil, fmt.Errorf("%s: %s has no xname; --current lines are address,xname", bmcCurrentFile, t.Host)
+			}
+			current[t.Xname] = t.Host
+		}
+	}
+
+	var rows []setIPRow
+	for _, b := range doc.BMCs {
+		r := setIPRow{xname: b.Xname, mac: b.MAC, old: current[b.Xname], new: b.IP}
+		ip := hostWithoutPort(b.IP)
+		switch {
+		case b.IP == "":
+			diag.Warnf("%s: no ip in bmcs[]; skipping", b.Xname)
+			continue
+		case net.ParseIP(ip).To4() == nil:
+			return nil, fmt.Errorf("%s: planned ip %q is not an IPv4 address", b.Xname, b.IP)
+		case gateway != nil && !net.ParseIP(ip).Mask(mask).Equal(gateway.Mask(mask)):
+			return nil, fmt.Errorf("%s: planned ip %s is not in the subnet of --gateway %s with --netmask %s", b.Xname, ip, gateway, net.IP(mask))
+		case r.old == "":
+			diag.Warnf("%s: no current address in --current or --leases-file; skipping", b.Xname)
+			continue
+		case r.old == b.IP:
+			r.result, r.nowOn = setIPAlready, b.IP
+		}
+		redfish.PinIdentity(r.old, b.Xname, b.TLSFingerprint)
+		redfish.PinIdentity(b.IP, b.Xname, b.TLSFingerprint)
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
+
+// setBMCAddress moves the BMC of r to cfg and records in r where it answers
+// afterwards.
+func setBMCAddress(ctx context.Context, hl *diag.HostLog, r *setIPRow, cfg redfish.IPv4Static, user, pass string) {
+	rctx, cancel := context.WithTimeout(ctx, bmcTimeout)
+	change, err := redfish.SetManagerIPv4(rctx, r.old, user, pass, bmcInsecure, bmcTimeout, r.mac, cfg)
+	cancel()
+	if err != nil && !errors.Is(err, redfish.ErrAddressUnconfirmed) {
+		r.result, r.nowOn = setIPRejected, r.old
+		hl.Warnf("set-ip: %v; still on %s", err, r.old)
+		return
+	}
+	if err != nil {
+		hl.Infof("%v; checking %s", err, r.new)
+	} else {
+		hl.Infof("%s -> %s requested on %s", r.old, cfg.Address, change.Path)
+	}
+	mac := change.MACAddress
+	if mac == "" {
+		mac = r.mac
+	}
+	wctx, cancel := context.WithTimeout(ctx, bmcVerifyTimeout)
+	err = redfish.WaitForAddress(wctx, r.new, user, pass, bmcInsecure, mac, bmcPollInterval)
+	cancel()
+	if err == nil {
+		r.result, r.nowOn = setIPMoved, r.new
+		hl.Infof("answering on %s", r.new)
+		return
+	}
+	r.result = setIPUnverified
+	// Record where the BMC is left, so it can be found again
+	pctx, cancel := context.WithTimeout(ctx, bmcTimeout)
+	defer cancel()
+	if redfish.WaitForAddress(pctx, r.old, user, pass, bmcInsecure, mac, bmcPollInterval) == nil {
+		r.nowOn = r.old
+		hl.Warnf("set-ip: not answering on %s: %v; still on %s", r.new, err, r.old)
+		return
+	}
+	r.nowOn = "neither"
+	hl.Warnf("set-ip: answering on neither %s (%v) nor %s; check the BMC's console or DHCP server", r.new, err, r.old)
+}
+
+// parseNetmask parses a dotted IPv4 netmask, e.g. 255.255.255.0, or a prefix
+// length, e.g. 24 or /24.
+func parseNetmask(s string) (net.IPMask, error) {
+	if s == "" {
+		return nil, errors.New("--netmask is required, e.g. 255.255.255.0 or 24")
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(s, "/")); err == nil {
+		if n < 1 || n > 32 {
+			return nil, fmt.Errorf("--netmask prefix length %d must be between 1 and 32", n)
+		}
+		return net.CIDRMask(n, 32), nil
+	}
+	ip := net.ParseIP(s).To4()
+	if ip == nil {
+		return nil, fmt.Errorf("--netmask %q is not an IPv4 netmask or prefix length", s)
+	}
+	mask := net.IPMask(ip)
+	if ones, bits := mask.Size(); ones == 0 || bits == 0 {
+		return nil, fmt.Errorf("--netmask %s is not a contiguous netmask", s)
+	}
+	return mask, nil
+}
+
+// readLeases reads a dnsmasq lease file, lines of "<expiry> <mac> <ip>
+// <hostname> <client-id>", into a map from lowercase MAC to IP. Of several
+// leases of one MAC the one expiring last (0 is never) wins. DHCPv6 lines are
+// ignored.
+func readLeases(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+	leases := map[string]string{}
+	expiry := map[string]int64{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] == "duid" {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%s:%d: want <expiry> <mac> <ip> ...", path, n)
+		}
+		exp, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid expiry %q", path, n, fields[0])
+		}
+		hw, err := net.ParseMAC(fields[1])
+		if err != nil || net.ParseIP(fields[2]).To4() == nil {
+			// DHCPv6 leases have an IAID and an IPv6 address here
+			continue
+		}
+		if exp == 0 {
+			exp = 1<<63 - 1
+		}
+		mac := hw.String()
+		if _, seen := leases[mac]; !seen || exp >= expiry[mac] {
+			leases[mac], expiry[mac] = fields[2], exp
+		}
+	}
+	return leases, sc.Err()
+}
+
+func init() {
+	bmcCmd.AddCommand(bmcSetIPCmd)
+	bmcSetIPCmd.Flags().StringVar(&bmcCurrentFile, "current", "", "file of the BMCs' current addresses, one address,xname per line as in --hosts-file")
+	bmcSetIPCmd.Flags().StringVar(&bmcLeasesFile, "leases-file", "", "dnsmasq lease file to find the current address of each BMC by its bmcs[] MAC (--current takes precedence)")
+	bmcSetIPCmd.Flags().StringVar(&bmcNetmask, "netmask", "", "netmask of the new addresses, e.g. 255.255.255.0 or 24 (required)")
+	bmcSetIPCmd.Flags().StringVar(&bmcGateway, "gateway", "", "default gateway to set; every planned address must be in its subnet")
+	bmcSetIPCmd.Flags().DurationVar(&bmcVerifyTimeout, "verify-timeout", 2*time.Minute, "how long each BMC has to answer on its new address before it counts as failed")
+	bmcSetIPCmd.Flags().BoolVar(&bmcKeepGoing, "keep-going", false, "continue with the next BMC after a failure instead of stopping")
+	bmcSetIPCmd.Flags().StringSliceVar(&bmcXnames, "xname", nil, "only the BMCs within these cabinet, chassis, slot, BMC, or node xnames (comma-separated or repeated)")
+	bmcSetIPCmd.Flags().BoolVarP(&bmcYes, "yes", "y", false, "skip the confirmation prompt")
+}
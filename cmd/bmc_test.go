@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBMCResetStaggersWithinChassis(t *testing.T) {
+	var mu sync.Mutex
+	resets := map[string]time.Time{}
+	newBMC := func() string {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/redfish/v1/Managers":
+				fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+			case r.Method == "GET":
+				fmt.Fprint(w, `{}`)
+			default:
+				mu.Lock()
+				resets[r.Host] = time.Now()
+				mu.Unlock()
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return strings.TrimPrefix(srv.URL, "https://")
+	}
+	b0, b1, other := newBMC(), newBMC(), newBMC()
+	inv := filepath.Join(t.TempDir(), "inventory.yaml")
+	content := fmt.Sprintf("bmcs:\n  - xname: x9000c1s0b0\n    ip: %s\n  - xname: x9000c1s0b1\n    ip: %s\n  - xname: x9000c3s0b0\n    ip: %s\n", b0, b1, other)
+	if err := os.WriteFile(inv, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bmcFile, bmcHostsCSV = inv, ""
+	bmcInsecure, bmcTimeout, bmcBatchSize = true, 5*time.Second, 3
+	bmcStagger, bmcYes = 300*time.Millisecond, true
+	defer func() { bmcStagger, bmcYes = 0, false }()
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+
+	bmcResetCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return bmcResetCmd.RunE(bmcResetCmd, nil) })
+	if err != nil {
+		t.Fatalf("bmc reset: %v\n%s", err, out)
+	}
+	if len(resets) != 3 {
+		t.Fatalf("resets = %v\n%s", resets, out)
+	}
+	// Gaps are measured at the POST, after the GETs that precede it
+	abs := func(d time.Duration) time.Duration { return max(d, -d) }
+	if gap := abs(resets[b1].Sub(resets[b0])); gap < bmcStagger*3/4 {
+		t.Errorf("same-chassis resets %s apart, want about %s", gap, bmcStagger)
+	}
+	first := resets[b0]
+	if resets[b1].Before(first) {
+		first = resets[b1]
+	}
+	if gap := abs(resets[other].Sub(first)); gap > bmcStagger*3/4 {
+		t.Errorf("other chassis should not be delayed, gap %s", gap)
+	}
+}
+
+func TestDesiredManagerConfig(t *testing.T) {
+	settings := filepath.Join(t.TempDir(), "bmc.yaml")
+	if err := os.WriteFile(settings, []byte("ntp:\n  enabled: false\n  servers: [10.1.0.1]\nsyslog:\n  servers: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bmcSettingsFile, bmcNTP, bmcNTPServers = settings, "enable", []string{"10.1.0.9"}
+	t.Cleanup(func() { bmcSettingsFile, bmcNTP, bmcNTPServers = "", "", nil })
+
+	want, err := desiredManagerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flags win over the file; an empty list in the file clears the servers
+	if want.NTPEnabled == nil || !*want.NTPEnabled || strings.Join(want.NTPServers, ",") != "10.1.0.9" ||
+		want.SyslogServers == nil || len(want.SyslogServers) != 0 || want.SyslogEnabled != nil {
+		t.Errorf("desiredManagerConfig = %+v", want)
+	}
+
+	if err := os.WriteFile(settings, []byte("ntp:\n  server: [10.1.0.1]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := desiredManagerConfig(); err == nil {
+		t.Error("expected an error for the misspelled key server")
+	}
+}
+
+// setIPBMC serves a BMC whose manager interface has mac, and counts the
+// PATCHes of it.
+func setIPBMC(t *testing.T, mac string, patches *int, mu *sync.Mutex) string {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{}`)
+		case "/redfish/v1/Managers/BMC/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces/eth0"}]}`)
+		case "/redfish/v1/Managers/BMC/EthernetInterfaces/eth0":
+			if r.Method == "PATCH" {
+				mu.Lock()
+				*patches++
+				mu.Unlock()
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			fmt.Fprintf(w, `{"MACAddress":%q,"IPv4Addresses":[{"Address":"127.0.0.1"}],"IPv4StaticAddresses":[]}`, mac)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestBMCSetIPStopsAtFirstFailure(t *testing.T) {
+	var mu sync.Mutex
+	var patches int
+	aOld, aNew := setIPBMC(t, "02:23:28:01:30:00", &patches, &mu), setIPBMC(t, "02:23:28:01:30:00", &patches, &mu)
+	bOld := setIPBMC(t, "02:23:28:01:30:10", &patches, &mu)
+	cOld := setIPBMC(t, "02:23:28:01:31:00", &patches, &mu)
+	// d is already on its planned address; nothing answers on b's, port 1
+	dNow := setIPBMC(t, "02:23:28:01:31:10", &patches, &mu)
+	dir := t.TempDir()
+	inv := filepath.Join(dir, "inventory.yaml")
+	content := fmt.Sprintf(`bmcs:
+  - {xname: x9000c1s0b0, mac: "02:23:28:01:30:00", ip: "%s"}
+  - {xname: x9000c1s0b1, mac: "02:23:28:01:30:10", ip: "127.0.0.1:1"}
+  - {xname: x9000c1s1b0, mac: "02:23:28:01:31:00", ip: "%s"}
+  - {xname: x9000c1s1b1, mac: "02:23:28:01:31:10", ip: "%s"}
+  - {xname: x9000c1s2b0, mac: "02:23:28:01:32:00", ip: "127.0.0.1:2"}
+`, aNew, dNow, dNow)
+	current := filepath.Join(dir, "current.txt")
+	list := fmt.Sprintf("%s,x9000c1s0b0\n%s,x9000c1s0b1\n%s,x9000c1s1b0\n%s,x9000c1s1b1\n", aOld, bOld, cOld, dNow)
+	for path, data := range map[string]string{inv: content, current: list} {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bmcFile, bmcHostsCSV, bmcHostsFile, bmcCurrentFile = inv, "", "", current
+	bmcNetmask, bmcGateway = "8", "127.0.0.1"
+	bmcInsecure, bmcTimeout, bmcVerifyTimeout, bmcYes = true, 5*time.Second, 500*time.Millisecond, true
+	oldPoll := bmcPollInterval
+	bmcPollInterval = 50 * time.Millisecond
+	t.Cleanup(func() {
+		bmcFile, bmcCurrentFile, bmcNetmask, bmcGateway, bmcYes = "", "", "", "", false
+		bmcPollInterval = oldPoll
+	})
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+
+	bmcSetIPCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return bmcSetIPCmd.RunE(bmcSetIPCmd, nil) })
+	if err == nil {
+		t.Fatalf("expected a failure\n%s", out)
+	}
+	for _, want := range []string{
+		"WARN: x9000c1s2b0: no current address in --current or --leases-file; skipping",
+		"x9000c1s0b0  " + aOld + "  " + aNew + "  moved        " + aNew,
+		"x9000c1s0b1  " + bOld + "  127.0.0.1:1",
+		"unverified   " + bOld,
+		"x9000c1s1b0  " + cOld + "  " + dNow + "  not-started  -",
+		"x9000c1s1b1  " + dNow + "  " + dNow + "  already-set  " + dNow,
+		"BMC set-ip: 1 moved, 1 already set, 1 failed, 1 not started",
+		"stopped after the first failure",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if patches != 2 {
+		t.Errorf("%d PATCHes, want 2 (a and b)", patches)
+	}
+}
+
+func TestReadLeasesAndNetmask(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnsmasq.leases")
+	leases := `1700000000 02:23:28:01:30:00 10.0.0.57 bmc-a *
+1700000500 02:23:28:01:30:00 10.0.0.58 bmc-a *
+0 02:23:28:01:30:10 10.0.0.60 * 01:02:23:28:01:30:10
+duid 00:01:00:01:2b:4e:7c:aa:52:54:00:12:34:56
+1700000000 1234 fd00::5 host6 00:01:00:01
+`
+	if err := os.WriteFile(path, []byte(leases), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readLeases(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["02:23:28:01:30:00"] != "10.0.0.58" || got["02:23:28:01:30:10"] != "10.0.0.60" {
+		t.Errorf("readLeases = %v", got)
+	}
+
+	for in, want := range map[string]string{"255.255.255.0": "ffffff00", "24": "ffffff00", "/20": "fffff000"} {
+		if m, err := parseNetmask(in); err != nil || m.String() != want {
+			t.Errorf("parseNetmask(%q) = %v, %v; want %s", in, m, err, want)
+		}
+	}
+	for _, bad := range []string{"", "255.0.255.0", "33", "ten"} {
+		if _, err := parseNetmask(bad); err == nil {
+			t.Errorf("parseNetmask(%q) should fail", bad)
+		}
+	}
+}
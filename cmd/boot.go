@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bootFile       string
+	bootHostsCSV   string
+	bootHostsFile  string
+	bootInsecure   bool
+	bootTimeout    time.Duration
+	bootDryRun     bool
+	bootBatchSize  int
+	bootPersistent bool
+)
+
+var bootCmd = &cobra.Command{
+	Use:   "boot",
+	Short: "Inspect and set the Redfish boot source override and boot order",
+}
+
+var bootSetPXECmd = &cobra.Command{
+	Use:   "set-pxe",
+	Short: "Set a one-time (or --persistent) PXE boot override on every system",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(bootFile, bootHostsCSV, bootHostsFile)
+		if err != nil {
+			return err
+		}
+		enabled := "Once"
+		if bootPersistent {
+			enabled = "Continuous"
+		}
+
+		if bootDryRun {
+			body, err := json.Marshal(redfish.BootOverridePatch("Pxe", enabled))
+			if err != nil {
+				return err
+			}
+			for _, t := range targets {
+				fmt.Printf("[dry-run] would PATCH every system on %s (%s) with %s\n", t.label(), t.Host, body)
+			}
+			return nil
+		}
+
+		var mu sync.Mutex
+		var ok, failed int
+		forEachTarget(cmd.Context(), targets, bootBatchSize, bootTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.SetBootOverride(ctx, t.Host, user, pass, bootInsecure, bootTimeout, "Pxe", enabled)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: boot set-pxe: %v", t.label(), err)
+				return
+			}
+			for _, s := range systems {
+				if s.Err != nil {
+					failed++
+					diag.Warnf("%s %s: boot set-pxe: %v", t.label(), path.Base(s.SystemPath), s.Err)
+					continue
+				}
+				ok++
+				diag.Infof("%s %s: boot override set to %s (%s)", t.label(), path.Base(s.SystemPath), s.Target, s.Enabled)
+			}
+		})
+
+		fmt.Printf("Boot set-pxe: %d system(s) succeeded, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "boot set-pxe failed for %d system(s) or BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var bootShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the current boot source override of every system",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(bootFile, bootHostsCSV, bootHostsFile)
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var ok, failed int
+		forEachTarget(cmd.Context(), targets, bootBatchSize, bootTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.GetBootOverrides(ctx, t.Host, user, pass, bootInsecure, bootTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: boot show: %v", t.label(), err)
+				return
+			}
+			for _, s := range systems {
+				if s.Err != nil {
+					failed++
+					diag.Warnf("%s %s: boot show: %v", t.label(), path.Base(s.SystemPath), s.Err)
+					continue
+				}
+				ok++
+				fmt.Printf("%s %s: target=%s enabled=%s mode=%s\n", t.label(), path.Base(s.SystemPath),
+					valueOrDash(s.Target), valueOrDash(s.Enabled), valueOrDash(s.Mode))
+			}
+		})
+		fmt.Printf("Boot show: %d system(s) reported, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "boot show failed for %d system(s) or BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// valueOrDash returns s, or "-" when s is empty, for tabular output.
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(bootCmd)
+	bootCmd.AddCommand(bootSetPXECmd, bootShowCmd)
+	bootCmd.PersistentFlags().StringVarP(&bootFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	bootCmd.PersistentFlags().StringVar(&bootHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	bootCmd.PersistentFlags().StringVar(&bootHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	bootCmd.PersistentFlags().BoolVar(&bootInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	bootCmd.PersistentFlags().DurationVar(&bootTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	bootCmd.PersistentFlags().BoolVar(&bootDryRun, "dry-run", false, "plan only: print the PATCH body (set-pxe) or the before and after boot order (order set) per system without changing it")
+	bootCmd.PersistentFlags().IntVar(&bootBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	bootSetPXECmd.Flags().BoolVar(&bootPersistent, "persistent", false, "use BootSourceOverrideEnabled=Continuous instead of Once")
+}
@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bootOrderFirst []string
+	bootOrderLast  []string
+)
+
+var bootOrderCmd = &cobra.Command{
+	Use:   "order",
+	Short: "Inspect and set the persistent boot order of every system",
+}
+
+var bootOrderShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the BootOrder and boot options of every system",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(bootFile, bootHostsCSV, bootHostsFile)
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var ok, failed int
+		forEachTarget(cmd.Context(), targets, bootBatchSize, bootTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.GetBootOrders(ctx, t.Host, user, pass, bootInsecure, bootTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: boot order show: %v", t.label(), err)
+				return
+			}
+			for _, s := range systems {
+				if s.Err != nil {
+					failed++
+					diag.Warnf("%s %s: boot order show: %v", t.label(), path.Base(s.SystemPath), s.Err)
+					continue
+				}
+				ok++
+				fmt.Printf("%s %s: BootOrder %s\n", t.label(), path.Base(s.SystemPath), formatBootOrder(s.Order))
+				for _, ref := range s.Order {
+					fmt.Printf("  %s  %s\n", ref, s.Name(ref))
+				}
+				// Options that are not in the order do not boot
+				for _, o := range s.Options {
+					if !slices.Contains(s.Order, o.Reference) {
+						fmt.Printf("  %s  %s (not in BootOrder)\n", o.Reference, valueOrDash(o.DisplayName))
+					}
+				}
+			}
+		})
+		fmt.Printf("Boot order show: %d system(s) reported, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "boot order show failed for %d system(s) or BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var bootOrderSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Reorder the persistent BootOrder of every system by boot option name",
+	Long: `Reorder the persistent BootOrder of every system. Boot options whose
+DisplayName matches a --first pattern move to the front and those matching a
+--last pattern to the end, grouped by pattern in the order given; the others
+keep their order in between. Patterns are globs (* and ?) matched against
+the whole DisplayName, ignoring case.
+
+Example: PXE over IPv4 first, disks last:
+
+  ochami_bootstrap boot order set --file inventory.yaml --first "*pxe*ipv4*" --last "*hdd*"
+
+A pattern that matches no boot option of a system fails that system.
+Systems already in the resulting order are reported as no-op and left
+alone. With --dry-run the boot orders are read and the before and after
+orders are printed, but nothing is changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if len(bootOrderFirst) == 0 && len(bootOrderLast) == 0 {
+			return errors.New("at least one --first or --last pattern is required")
+		}
+		spec := redfish.BootOrderSpec{First: bootOrderFirst, Last: bootOrderLast}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(bootFile, bootHostsCSV, bootHostsFile)
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var changed, unchanged, failed int
+		forEachTarget(cmd.Context(), targets, bootBatchSize, bootTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.SetBootOrder(ctx, t.Host, user, pass, bootInsecure, bootTimeout, spec, !bootDryRun)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: boot order set: %v", t.label(), err)
+				return
+			}
+			for _, s := range systems {
+				sys := path.Base(s.SystemPath)
+				switch {
+				case s.Err != nil:
+					failed++
+					diag.Warnf("%s %s: boot order set: %v", t.label(), sys, s.Err)
+				case !s.Changed:
+					unchanged++
+					diag.Infof("%s %s: boot order already %s (no-op)", t.label(), sys, formatBootOrder(s.Order))
+				case bootDryRun:
+					changed++
+					fmt.Printf("[dry-run] %s %s: would change BootOrder %s -> %s\n", t.label(), sys, formatBootOrder(s.Order), formatBootOrder(s.NewOrder))
+				default:
+					changed++
+					diag.Infof("%s %s: boot order %s -> %s", t.label(), sys, formatBootOrder(s.Order), formatBootOrder(s.NewOrder))
+				}
+			}
+		})
+
+		verb := "changed"
+		if bootDryRun {
+			verb = "would change"
+		}
+		fmt.Printf("Boot order set: %d system(s) %s, %d no-op, %d failed\n", changed, verb, unchanged, failed)
+		if err := checkOutcome(changed+unchanged, failed, "boot order set failed for %d system(s) or BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// formatBootOrder renders a BootOrder array as [Boot0001, Boot0002].
+func formatBootOrder(order []string) string {
+	return "[" + strings.Join(order, ", ") + "]"
+}
+
+func init() {
+	bootCmd.AddCommand(bootOrderCmd)
+	bootOrderCmd.AddCommand(bootOrderShowCmd, bootOrderSetCmd)
+	bootOrderSetCmd.Flags().StringSliceVar(&bootOrderFirst, "first", nil, "boot option name patterns (e.g. \"*pxe*ipv4*\") to move to the front, in order (comma-separated or repeated)")
+	bootOrderSetCmd.Flags().StringSliceVar(&bootOrderLast, "last", nil, "boot option name patterns (e.g. \"*hdd*\") to move to the end, in order (comma-separated or repeated)")
+}
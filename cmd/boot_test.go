@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBootSetPXEDryRunPrintsBody(t *testing.T) {
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	bootFile = ""
+	bootHostsCSV = "10.1.1.10,10.1.1.11"
+	bootDryRun = true
+	bootPersistent = true
+	defer func() { bootDryRun, bootPersistent = false, false }()
+
+	bootSetPXECmd.SetContext(context.Background())
+	output, err := captureOutput(t, func() error { return bootSetPXECmd.RunE(bootSetPXECmd, nil) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(output, "[dry-run]") != 2 {
+		t.Fatalf("expected one dry-run line per host, got:\n%s", output)
+	}
+	if !strings.Contains(output, `"BootSourceOverrideEnabled":"Continuous"`) || !strings.Contains(output, `"BootSourceOverrideTarget":"Pxe"`) {
+		t.Fatalf("dry-run output missing PATCH body:\n%s", output)
+	}
+}
@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	certFile      string
+	certHostsCSV  string
+	certHostsFile string
+	certInsecure  bool
+	certTimeout   time.Duration
+	certBatchSize int
+	certDryRun    bool
+	certCert      string
+	certKey       string
+	certCSROut    string
+	certCertIn    string
+	certNoVerify  bool
+	certCSR       redfish.CSRRequest
+)
+
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Manage BMC HTTPS certificates via the Redfish CertificateService",
+}
+
+var certsInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Replace the HTTPS certificate of every selected BMC",
+	Long: `Replace the HTTPS certificate of every selected BMC, in one of three modes:
+
+  --cert FILE [--key FILE]  install the same PEM certificate (and key) everywhere
+  --csr-out DIR             have each BMC generate a key and write its CSR to DIR
+  --cert-in DIR             install the certificates signed from those CSRs
+
+In the two-phase mode, sign DIR/<name>.csr into DIR/<name>.pem, where <name>
+is the BMC's xname, or its host when it has none. A DIR/<name>.key next to
+it is sent along for BMCs whose key was generated elsewhere.
+
+After installing, each BMC is contacted again with certificate verification
+against --ca-cert (or the system roots) to confirm the new certificate is
+served. Use --insecure for the install itself while BMCs still present
+their self-signed certificates.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		modes := 0
+		for _, set := range []bool{certCert != "", certCSROut != "", certCertIn != ""} {
+			if set {
+				modes++
+			}
+		}
+		if modes != 1 {
+			return errors.New("exactly one of --cert, --csr-out, or --cert-in is required")
+		}
+		if certKey != "" && certCert == "" {
+			return errors.New("--key requires --cert")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(certFile, certHostsCSV, certHostsFile)
+		if err != nil {
+			return err
+		}
+		var shared string
+		if certCert != "" {
+			if shared, err = readPEMPair(certCert, certKey); err != nil {
+				return err
+			}
+		}
+		if certCSROut != "" && !certDryRun {
+			if err := os.MkdirAll(certCSROut, 0o755); err != nil {
+				return err
+			}
+		}
+
+		var mu sync.Mutex
+		var ok, unsupported, failed int
+		forEachTarget(cmd.Context(), targets, certBatchSize, certTimeout, func(ctx context.Context, t bmcTarget) {
+			msg, err := installCertificate(ctx, t, user, pass, shared)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrCertificateServiceUnsupported):
+				unsupported++
+				diag.Warnf("%s: unsupported: %v", t.label(), err)
+			case err != nil:
+				failed++
+				diag.Warnf("%s: certs install: %v", t.label(), err)
+			case certDryRun:
+				ok++
+				fmt.Println(msg)
+			default:
+				ok++
+				diag.Infof("%s: %s", t.label(), msg)
+			}
+		})
+
+		verb := "installed"
+		if certCSROut != "" {
+			verb = "CSR written"
+		}
+		fmt.Printf("Certificates: %d %s, %d unsupported, %d failed\n", ok, verb, unsupported, failed)
+		if err := checkOutcome(ok, failed+unsupported, "certs install failed on %d BMC(s), %d without CertificateService", failed, unsupported); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// installCertificate runs the selected mode against one BMC and returns
+// what it did.
+func installCertificate(ctx context.Context, t bmcTarget, user, pass, shared string) (string, error) {
+	name := certFileName(t)
+	if certCSROut != "" {
+		path := filepath.Join(certCSROut, name+".csr")
+		if certDryRun {
+			return fmt.Sprintf("[dry-run] would POST GenerateCSR to %s (%s) and write %s", t.label(), t.Host, path), nil
+		}
+		req := certCSR
+		// The address clients connect to
+		req.CommonName = hostWithoutPort(t.Host)
+		if t.Xname != "" {
+			req.AlternativeNames = []string{t.Xname}
+		}
+		csr, err := redfish.GenerateCSR(ctx, t.Host, user, pass, certInsecure, certTimeout, req)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(csr), 0o644); err != nil {
+			return "", err
+		}
+		return "CSR written to " + path, nil
+	}
+
+	pem, source := shared, certCert
+	if certCertIn != "" {
+		source = filepath.Join(certCertIn, name+".pem")
+		keyPath := filepath.Join(certCertIn, name+".key")
+		if _, err := os.Stat(keyPath); errors.Is(err, fs.ErrNotExist) {
+			keyPath = ""
+		}
+		var err error
+		if pem, err = readPEMPair(source, keyPath); err != nil {
+			return "", err
+		}
+	}
+	if certDryRun {
+		return fmt.Sprintf("[dry-run] would install %s as the HTTPS certificate of %s (%s)", source, t.label(), t.Host), nil
+	}
+	uri, err := redfish.InstallCertificate(ctx, t.Host, user, pass, certInsecure, certTimeout, pem)
+	if err != nil {
+		return "", err
+	}
+	if certNoVerify {
+		return "certificate installed at " + uri, nil
+	}
+	if err := redfish.VerifyCertificate(ctx, t.Host, certTimeout); err != nil {
+		return "", fmt.Errorf("certificate installed at %s but not verified: %w", uri, err)
+	}
+	return "certificate installed at " + uri + " and verified", nil
+}
+
+// readPEMPair returns the PEM certificate at certPath followed by the PEM
+// key at keyPath, if any.
+func readPEMPair(certPath, keyPath string) (string, error) {
+	cert, err := os.ReadFile(certPath)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(string(cert), "-----BEGIN CERTIFICATE-----") {
+		return "", fmt.Errorf("%s: no PEM certificate", certPath)
+	}
+	pem := strings.TrimRight(string(cert), "\n") + "\n"
+	if keyPath != "" {
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return "", err
+		}
+		if !strings.Contains(string(key), "PRIVATE KEY-----") {
+			return "", fmt.Errorf("%s: no PEM private key", keyPath)
+		}
+		pem += strings.TrimRight(string(key), "\n") + "\n"
+	}
+	return pem, nil
+}
+
+// certFileName is the base name of a BMC's CSR and certificate files.
+func certFileName(t bmcTarget) string {
+	return strings.NewReplacer(":", "_", "/", "_").Replace(t.label())
+}
+
+func init() {
+	rootCmd.AddCommand(certsCmd)
+	certsCmd.AddCommand(certsInstallCmd)
+	certsCmd.PersistentFlags().StringVarP(&certFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	certsCmd.PersistentFlags().StringVar(&certHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	certsCmd.PersistentFlags().StringVar(&certHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	certsCmd.PersistentFlags().BoolVar(&certInsecure, "insecure", false, "skip TLS certificate verification while installing (verification afterwards always checks)")
+	certsCmd.PersistentFlags().DurationVar(&certTimeout, "timeout", time.Minute, "per-BMC request timeout")
+	certsCmd.PersistentFlags().IntVar(&certBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	certsCmd.PersistentFlags().BoolVar(&certDryRun, "dry-run", false, "plan only: print actions without contacting BMCs")
+	certsInstallCmd.Flags().StringVar(&certCert, "cert", "", "PEM certificate to install on every BMC")
+	certsInstallCmd.Flags().StringVar(&certKey, "key", "", "PEM private key of --cert")
+	certsInstallCmd.Flags().StringVar(&certCSROut, "csr-out", "", "have each BMC generate a key pair and write its CSR to this directory as <xname or host>.csr")
+	certsInstallCmd.Flags().StringVar(&certCertIn, "cert-in", "", "install <xname or host>.pem (plus .key, if present) from this directory on each BMC")
+	certsInstallCmd.Flags().BoolVar(&certNoVerify, "no-verify", false, "do not reconnect with verification to confirm the new certificate")
+	certsInstallCmd.Flags().StringVar(&certCSR.Organization, "csr-org", "", "CSR organization (O)")
+	certsInstallCmd.Flags().StringVar(&certCSR.OrganizationalUnit, "csr-ou", "", "CSR organizational unit (OU)")
+	certsInstallCmd.Flags().StringVar(&certCSR.City, "csr-city", "", "CSR city or locality (L)")
+	certsInstallCmd.Flags().StringVar(&certCSR.State, "csr-state", "", "CSR state or province (ST)")
+	certsInstallCmd.Flags().StringVar(&certCSR.Country, "csr-country", "", "CSR two-letter country code (C)")
+}
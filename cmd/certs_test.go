@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCertsInstallTwoPhase(t *testing.T) {
+	var installed string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/CertificateService":
+			fmt.Fprint(w, `{}`)
+		case r.URL.Path == "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/bmc"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers/bmc/NetworkProtocol":
+			fmt.Fprint(w, `{}`)
+		case strings.HasSuffix(r.URL.Path, ".GenerateCSR"):
+			fmt.Fprint(w, `{"CSRString":"CSR"}`)
+		case r.URL.Path == "/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates" && r.Method == "POST":
+			installed = r.URL.Path
+		case r.URL.Path == "/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates":
+			fmt.Fprint(w, `{"Members":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("REDFISH_USER", "u")
+	t.Setenv("REDFISH_PASSWORD", "p")
+	host := strings.TrimPrefix(srv.URL, "https://")
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsFile, []byte(host+",x9000c1s0b0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certHostsFile, certInsecure, certCSROut = hostsFile, true, dir
+	t.Cleanup(func() { certHostsFile, certInsecure, certCSROut, certCertIn, certNoVerify = "", false, "", "", false })
+
+	cmd := certsInstallCmd
+	cmd.SetContext(context.Background())
+	if out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) }); err != nil {
+		t.Fatalf("--csr-out: %v\n%s", err, out)
+	}
+	if csr, err := os.ReadFile(filepath.Join(dir, "x9000c1s0b0.csr")); err != nil || string(csr) != "CSR" {
+		t.Fatalf("CSR file = %q, %v", csr, err)
+	}
+
+	// Phase two installs the signed certificate, named after the same xname
+	certCSROut, certCertIn, certNoVerify = "", dir, true
+	if err := os.WriteFile(filepath.Join(dir, "x9000c1s0b0.pem"), []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err != nil || installed == "" || !strings.Contains(out, "Certificates: 1 installed, 0 unsupported, 0 failed") {
+		t.Errorf("--cert-in: err=%v installed=%q\n%s", err, installed, out)
+	}
+}
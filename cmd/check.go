@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkFile           string
+	checkHostsCSV       string
+	checkHostsFile      string
+	checkInsecure       bool
+	checkTimeout        time.Duration
+	checkBatchSize      int
+	checkAuth           bool
+	checkOutput         string
+	checkMaxUnreachable int
+)
+
+// hostCheck is one row of `check` output.
+type hostCheck struct {
+	Host           string `json:"host"`
+	Xname          string `json:"xname,omitempty"`
+	Status         string `json:"status"`
+	LatencyMS      int64  `json:"latency_ms"`
+	RedfishVersion string `json:"redfish_version,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that every selected BMC is reachable and answers Redfish",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if checkOutput != "table" && checkOutput != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		var user, pass string
+		if checkAuth {
+			var err error
+			if user, pass, err = redfishCredentials(); err != nil {
+				return err
+			}
+		}
+		targets, err := resolveHosts(checkFile, checkHostsCSV, checkHostsFile)
+		if err != nil {
+			return err
+		}
+
+		results := make([]hostCheck, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+			results[i] = hostCheck{Host: t.Host, Xname: t.Xname}
+		}
+		var mu sync.Mutex
+		forEachTarget(cmd.Context(), targets, checkBatchSize, checkTimeout, func(ctx context.Context, t bmcTarget) {
+			res := redfish.CheckHost(ctx, t.Host, user, pass, checkInsecure, checkTimeout, checkAuth)
+			mu.Lock()
+			defer mu.Unlock()
+			r := &results[index[t]]
+			r.Status = res.Status
+			r.LatencyMS = res.Latency.Milliseconds()
+			r.RedfishVersion = res.RedfishVersion
+			if res.Err != nil {
+				r.Error = res.Err.Error()
+			}
+		})
+
+		counts := map[string]int{}
+		var checked []hostCheck // hosts not reached before an interrupt are left out
+		for _, r := range results {
+			if r.Status != "" {
+				counts[r.Status]++
+				checked = append(checked, r)
+			}
+		}
+		if checkOutput == "json" {
+			out, err := json.MarshalIndent(checked, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		} else {
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "HOST\tSTATUS\tLATENCY\tREDFISH\tERROR")
+			for _, r := range checked {
+				host := r.Host
+				if r.Xname != "" {
+					host = r.Xname + " (" + r.Host + ")"
+				}
+				latency := "-"
+				if r.Status != redfish.CheckUnreachable {
+					latency = fmt.Sprintf("%dms", r.LatencyMS)
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", host, r.Status, latency, valueOrDash(r.RedfishVersion), valueOrDash(r.Error))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Printf("Check: %d ok, %d unreachable, %d tls-error, %d auth-failed, %d error\n",
+				counts[redfish.CheckOK], counts[redfish.CheckUnreachable], counts[redfish.CheckTLSError],
+				counts[redfish.CheckAuthFailed], counts[redfish.CheckError])
+		}
+
+		ok := counts[redfish.CheckOK]
+		if failed := len(checked) - ok; failed > checkMaxUnreachable {
+			return checkOutcome(ok, failed, "%d host(s) failed the check (--max-unreachable %d)", failed, checkMaxUnreachable)
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().StringVarP(&checkFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	checkCmd.Flags().StringVar(&checkHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	checkCmd.Flags().StringVar(&checkHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	checkCmd.Flags().BoolVar(&checkInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	checkCmd.Flags().DurationVar(&checkTimeout, "timeout", 10*time.Second, "per-BMC check timeout")
+	checkCmd.Flags().IntVar(&checkBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	checkCmd.Flags().BoolVar(&checkAuth, "auth", true, "also authenticate with REDFISH_USER/REDFISH_PASSWORD (--auth=false only probes the service root)")
+	checkCmd.Flags().StringVarP(&checkOutput, "output", "o", "table", "output format: table or json")
+	checkCmd.Flags().IntVar(&checkMaxUnreachable, "max-unreachable", 0, "number of hosts allowed to fail before the command exits non-zero")
+}
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckReportsEachHost(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RedfishVersion":"1.9.0","Members":[]}`)
+	}))
+	defer srv.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := l.Addr().String()
+	l.Close() // nolint:errcheck
+
+	checkHostsCSV = strings.TrimPrefix(srv.URL, "https://") + "," + closed
+	checkInsecure = true
+	checkTimeout = 2 * time.Second
+	checkAuth = true
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	defer func() { checkHostsCSV, checkOutput, checkMaxUnreachable = "", "table", 0 }()
+	checkCmd.SetContext(context.Background())
+
+	checkOutput = "table"
+	out, err := captureOutput(t, func() error { return checkCmd.RunE(checkCmd, nil) })
+	var oe *outcomeError
+	if !errors.As(err, &oe) || oe.code != exitPartial {
+		t.Fatalf("err = %v, want a partial failure\n%s", err, out)
+	}
+	for _, want := range []string{"1.9.0", "unreachable", "Check: 1 ok, 1 unreachable, 0 tls-error, 0 auth-failed, 0 error"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// One failure is tolerated with --max-unreachable 1
+	checkOutput, checkMaxUnreachable = "json", 1
+	out, err = captureOutput(t, func() error { return checkCmd.RunE(checkCmd, nil) })
+	if err != nil {
+		t.Fatalf("--max-unreachable 1: %v", err)
+	}
+	var rows []hostCheck
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("json output: %v\n%s", err, out)
+	}
+	if len(rows) != 2 || rows[0].Status != "ok" || rows[1].Status != "unreachable" || rows[1].Host != closed {
+		t.Errorf("rows = %+v", rows)
+	}
+}
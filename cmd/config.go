@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is read from the working directory when neither
+// --config nor $BOOTSTRAP_CONFIG names a config file.
+const defaultConfigFile = "bootstrap.yaml"
+
+// configEnvPrefix prefixes the environment variable of each flag, e.g.
+// BOOTSTRAP_BATCH_SIZE for --batch-size.
+const configEnvPrefix = "BOOTSTRAP_"
+
+var configFile string
+
+// configCredentials are config keys that are not flags: each supplies its
+// environment variable when that is unset.
+var configCredentials = map[string]string{
+	"redfish-user":     "REDFISH_USER",
+	"redfish-password": "REDFISH_PASSWORD",
+	"smd-token":        "SMD_TOKEN",
+}
+
+// Value sources, from highest to lowest precedence.
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceConfig  = "config"
+	sourceDefault = "default"
+)
+
+// config is a loaded config file: flag names (or configCredentials keys)
+// mapped to values in flag syntax.
+type config struct {
+	path   string // empty when no file was found
+	values map[string]string
+	// exported lists the configCredentials environment variables that
+	// apply set from the file
+	exported map[string]bool
+}
+
+// activeConfig is the config loaded for the running command.
+var activeConfig config
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration file",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [command...]",
+	Short: "Print the effective value and source of every flag of a command",
+	Long: `Print the value every flag of the given command (e.g. "discover" or
+"firmware status"; the root command when omitted) would have, merged from
+flags, BOOTSTRAP_* environment variables, the config file, and defaults, in
+that order of precedence, with the source of each value. Passwords and
+tokens are redacted.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		target, rest, err := rootCmd.Find(args)
+		if err != nil || len(rest) > 0 {
+			return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+		}
+		cfg := activeConfig
+		if cfg.path != "" {
+			fmt.Printf("# config file: %s\n", cfg.path)
+		} else {
+			fmt.Println("# config file: none")
+		}
+		// Root flags given on this command line are shared with target
+		flags := pflag.NewFlagSet(target.Name(), pflag.ContinueOnError)
+		flags.AddFlagSet(target.LocalFlags())
+		flags.AddFlagSet(target.InheritedFlags())
+		var names []string
+		flags.VisitAll(func(f *pflag.Flag) {
+			if !skipConfigFlag(f.Name) {
+				names = append(names, f.Name)
+			}
+		})
+		slices.Sort(names)
+		for _, name := range names {
+			value, source := cfg.resolve(flags.Lookup(name))
+			fmt.Printf("%s: %s # %s\n", name, quoteConfigValue(redactConfig(name, value)), source)
+		}
+		for _, key := range slices.Sorted(maps.Keys(configCredentials)) {
+			env := configCredentials[key]
+			value, source := os.Getenv(env), sourceEnv
+			if cfg.exported[env] {
+				source = sourceConfig
+			}
+			if value != "" {
+				fmt.Printf("%s: %s # %s\n", key, quoteConfigValue(redactConfig(key, value)), source)
+			}
+		}
+		return nil
+	},
+}
+
+// loadConfig reads the config file named by --config, $BOOTSTRAP_CONFIG, or
+// defaultConfigFile when it exists. Keys must be flag names of some command
+// under root or configCredentials keys.
+func loadConfig(root *cobra.Command) (config, error) {
+	cfg := config{path: configFile, values: map[string]string{}, exported: map[string]bool{}}
+	if cfg.path == "" {
+		cfg.path = os.Getenv(configEnvPrefix + "CONFIG")
+	}
+	explicit := cfg.path != ""
+	if !explicit {
+		cfg.path = defaultConfigFile
+	}
+	raw, err := os.ReadFile(cfg.path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return config{values: cfg.values, exported: cfg.exported}, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("config: %w", err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", cfg.path, err)
+	}
+	valid := configKeys(root)
+	var unknown []string
+	for key, v := range doc {
+		if !slices.Contains(valid, key) {
+			unknown = append(unknown, key)
+			continue
+		}
+		switch v := v.(type) {
+		case nil:
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			cfg.values[key] = strings.Join(items, ",")
+		case map[string]any:
+			return cfg, fmt.Errorf("config %s: %s: expected a value or a list", cfg.path, key)
+		default:
+			cfg.values[key] = fmt.Sprint(v)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return cfg, fmt.Errorf("config %s: unknown key(s) %s; valid keys: %s", cfg.path, strings.Join(unknown, ", "), strings.Join(valid, ", "))
+	}
+	return cfg, nil
+}
+
+// configKeys returns the sorted flag names of root and its subcommands, and
+// the configCredentials keys.
+func configKeys(root *cobra.Command) []string {
+	keys := slices.Sorted(maps.Keys(configCredentials))
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		c.LocalFlags().VisitAll(func(f *pflag.Flag) {
+			if !skipConfigFlag(f.Name) && !slices.Contains(keys, f.Name) {
+				keys = append(keys, f.Name)
+			}
+		})
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+	slices.Sort(keys)
+	return keys
+}
+
+// skipConfigFlag reports flags that cannot be set from a config file.
+func skipConfigFlag(name string) bool {
+	return name == "config" || name == "help"
+}
+
+// resolve returns the effective value of f and its source.
+func (c config) resolve(f *pflag.Flag) (string, string) {
+	if f.Changed {
+		return f.Value.String(), sourceFlag
+	}
+	if v, ok := os.LookupEnv(flagEnv(f.Name)); ok {
+		return v, sourceEnv
+	}
+	if v, ok := c.values[f.Name]; ok {
+		return v, sourceConfig
+	}
+	return f.DefValue, sourceDefault
+}
+
+// apply sets every flag of cmd that was not given on the command line from
+// its environment variable or the config file, and exports configCredentials
+// whose variables are unset. Flags set this way are not marked Changed, so
+// checks for flags the user passed explicitly are unaffected.
+func (c config) apply(cmd *cobra.Command) error {
+	var errs []error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if skipConfigFlag(f.Name) {
+			return
+		}
+		value, source := c.resolve(f)
+		var err error
+		switch source {
+		case sourceEnv:
+			err = f.Value.Set(value)
+			if err != nil {
+				err = fmt.Errorf("$%s: %w", flagEnv(f.Name), err)
+			}
+		case sourceConfig:
+			err = f.Value.Set(value)
+			if err != nil {
+				err = fmt.Errorf("config %s: %s: %w", c.path, f.Name, err)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	})
+	for key, env := range configCredentials {
+		if v, ok := c.values[key]; ok && os.Getenv(env) == "" {
+			if err := os.Setenv(env, v); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			c.exported[env] = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flagEnv returns the environment variable of flag name.
+func flagEnv(name string) string {
+	return configEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// redactConfig hides the value of keys that hold secrets.
+func redactConfig(key, value string) string {
+	k := strings.ToLower(key)
+	if value != "" && (strings.Contains(k, "password") || strings.Contains(k, "token") || strings.Contains(k, "secret")) {
+		return "REDACTED"
+	}
+	return value
+}
+
+// quoteConfigValue quotes values that YAML would otherwise misread.
+func quoteConfigValue(v string) string {
+	if v == "" || strings.ContainsAny(v, ":#[]{},&*!|>'\"%@`") || strings.TrimSpace(v) != v {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file of flag defaults (default: $BOOTSTRAP_CONFIG, else ./bootstrap.yaml if present)")
+}
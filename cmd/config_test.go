@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// configTestCommand returns a root with one subcommand defining the flags
+// the config tests set.
+func configTestCommand(batch *int, timeout *time.Duration, hosts *string, targets *[]string) (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().StringVar(&configFile, "config", "", "")
+	sub := &cobra.Command{Use: "sub", RunE: func(*cobra.Command, []string) error { return nil }}
+	sub.Flags().IntVar(batch, "batch-size", 0, "")
+	sub.Flags().DurationVar(timeout, "timeout", time.Minute, "")
+	sub.Flags().StringVar(hosts, "hosts", "", "")
+	sub.Flags().StringSliceVar(targets, "targets", nil, "")
+	root.AddCommand(sub)
+	return root, sub
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := t.TempDir() + "/bootstrap.yaml"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	var batch int
+	var timeout time.Duration
+	var hosts string
+	var targets []string
+	root, sub := configTestCommand(&batch, &timeout, &hosts, &targets)
+	t.Cleanup(func() { configFile = "" })
+	path := writeConfig(t, "batch-size: 10\ntimeout: 30s\nhosts: cfg-host\ntargets: [a, b]\nredfish-user: admin\n")
+	t.Setenv("BOOTSTRAP_TIMEOUT", "45s")
+	t.Setenv("REDFISH_USER", "")
+
+	if err := sub.ParseFlags([]string{"--config", path, "--hosts", "flag-host"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.apply(sub); err != nil {
+		t.Fatal(err)
+	}
+	if hosts != "flag-host" || timeout != 45*time.Second || batch != 10 || strings.Join(targets, ",") != "a,b" {
+		t.Errorf("hosts=%q timeout=%s batch=%d targets=%v; want flag > env > config", hosts, timeout, batch, targets)
+	}
+	if sub.Flags().Changed("batch-size") {
+		t.Error("a value from the config file marked --batch-size as given on the command line")
+	}
+	if got := os.Getenv("REDFISH_USER"); got != "admin" || !cfg.exported["REDFISH_USER"] {
+		t.Errorf("REDFISH_USER = %q, want it set from the config file", got)
+	}
+}
+
+func TestConfigUnknownKey(t *testing.T) {
+	var batch int
+	var timeout time.Duration
+	var hosts string
+	var targets []string
+	root, _ := configTestCommand(&batch, &timeout, &hosts, &targets)
+	configFile = writeConfig(t, "batch-size: 4\nbatchsize: 4\n")
+	t.Cleanup(func() { configFile = "" })
+
+	_, err := loadConfig(root)
+	if err == nil || !strings.Contains(err.Error(), "unknown key(s) batchsize; valid keys: batch-size, hosts,") {
+		t.Errorf("err = %v, want the unknown key and the valid keys", err)
+	}
+}
+
+func TestConfigShowRedactsSecrets(t *testing.T) {
+	configFile = writeConfig(t, "insecure: true\nredfish-password: s3cret\n")
+	t.Cleanup(func() { configFile, activeConfig = "", config{} })
+	t.Setenv("REDFISH_PASSWORD", "")
+
+	var err error
+	if activeConfig, err = loadConfig(rootCmd); err != nil {
+		t.Fatal(err)
+	}
+	if err := activeConfig.apply(configShowCmd); err != nil {
+		t.Fatal(err)
+	}
+	configShowCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return configShowCmd.RunE(configShowCmd, []string{"discover"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"insecure: true # config\n", "timeout: 12s # default\n", "redfish-password: REDACTED # config\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("config show output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("config show printed the password:\n%s", out)
+	}
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// confirmInput is where confirm and readSecret read answers from; tests
+// replace it.
+var confirmInput io.Reader = os.Stdin
+
+// confirm prints prompt and reports whether the operator answered y or yes.
+func confirm(prompt string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", prompt)
+	line, err := bufio.NewReader(confirmInput).ReadString('\n')
+	if err != nil && line == "" {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// confirmListMax is how many BMCs confirmDestructive lists by name.
+const confirmListMax = 10
+
+// confirmDestructive prints what action is about to do to the BMCs of sel,
+// with details such as the image, and unless yes is set requires the
+// operator to type "yes". Without a terminal to ask on it fails rather than
+// waiting for an answer that never comes.
+func confirmDestructive(action string, sel selection, details []string, yes bool) error {
+	if yes {
+		return nil
+	}
+	fmt.Printf("About to %s on %d BMC(s):\n", action, len(sel.targets))
+	for i, t := range sel.targets {
+		if i == confirmListMax {
+			fmt.Printf("  ... and %d more\n", len(sel.targets)-confirmListMax)
+			break
+		}
+		if t.Xname != "" {
+			fmt.Printf("  %s (%s)\n", t.Xname, t.Host)
+		} else {
+			fmt.Printf("  %s\n", t.Host)
+		}
+	}
+	if len(sel.xnames) > 0 {
+		fmt.Printf("  --xname %s matched %d of %d BMC(s)\n", strings.Join(sel.xnames, ","), len(sel.targets), sel.total)
+	}
+	for _, d := range details {
+		fmt.Printf("  %s\n", d)
+	}
+	if confirmInput == os.Stdin && !stdinIsTerminal() {
+		return errors.New("stdin is not a terminal to confirm on; pass --yes to proceed without confirmation")
+	}
+	fmt.Print(`Type "yes" to continue: `)
+	line, err := bufio.NewReader(confirmInput).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if strings.TrimSpace(line) != "yes" {
+		return errors.New("aborted; pass --yes to skip the confirmation")
+	}
+	return nil
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// readSecret prints prompt to stderr and reads one line from confirmInput,
+// with terminal echo turned off while it is typed. The line is read a byte
+// at a time so that consecutive calls do not lose buffered input.
+func readSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if confirmInput == os.Stdin && stdinIsTerminal() && stty("-echo") == nil {
+		defer func() {
+			_ = stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := confirmInput.Read(buf)
+		if n == 1 {
+			if buf[0] == '\n' {
+				break
+			}
+			b.WriteByte(buf[0])
+		}
+		if err == io.EOF && b.Len() > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(b.String(), "\r"), nil
+}
+
+// stty applies a terminal setting to stdin.
+func stty(arg string) error {
+	c := exec.Command("stty", arg)
+	c.Stdin = os.Stdin
+	return c.Run()
+}
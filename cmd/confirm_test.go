@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConfirmDestructive(t *testing.T) {
+	old := confirmInput
+	defer func() { confirmInput = old }()
+
+	var targets []bmcTarget
+	for i := range 12 {
+		targets = append(targets, bmcTarget{Host: fmt.Sprintf("10.0.0.%d", i+1), Xname: fmt.Sprintf("x1000c0s%db0", i)})
+	}
+	sel := selection{targets: targets, total: 40, xnames: []string{"x1000c0"}}
+
+	confirmInput = strings.NewReader("yes\n")
+	out, err := captureOutput(t, func() error {
+		return confirmDestructive("update firmware", sel, []string{"image=http://10.0.0.254/fw.bin"}, false)
+	})
+	if err != nil {
+		t.Fatalf("answer yes: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"About to update firmware on 12 BMC(s):",
+		"  x1000c0s9b0 (10.0.0.10)\n  ... and 2 more\n",
+		"--xname x1000c0 matched 12 of 40 BMC(s)",
+		"image=http://10.0.0.254/fw.bin",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "x1000c0s10b0") {
+		t.Errorf("summary lists more than %d BMCs:\n%s", confirmListMax, out)
+	}
+
+	// Anything but "yes", including y, aborts
+	for _, answer := range []string{"y\n", "no\n", ""} {
+		confirmInput = strings.NewReader(answer)
+		if _, err := captureOutput(t, func() error { return confirmDestructive("restart the BMC", sel, nil, false) }); err == nil {
+			t.Errorf("answer %q: want an error", answer)
+		}
+	}
+
+	// --yes neither prints nor reads
+	confirmInput = strings.NewReader("")
+	if out, err := captureOutput(t, func() error { return confirmDestructive("restart the BMC", sel, nil, true) }); err != nil || out != "" {
+		t.Errorf("with --yes: err = %v, output %q", err, out)
+	}
+}
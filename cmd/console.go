@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	conFile      string
+	conHostsCSV  string
+	conHostsFile string
+	conInsecure  bool
+	conTimeout   time.Duration
+	conBatchSize int
+	conXnames    []string
+	conOutDir    string
+	conPaths     []string
+)
+
+var consoleCmd = &cobra.Command{
+	Use:   "console",
+	Short: "Read node console output via Redfish",
+}
+
+var consoleCaptureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "Save the console or boot log of every selected BMC to --out-dir",
+	Long: `Save the console or boot log of every selected BMC to --out-dir as
+<xname or host>.log, e.g. to see why a node failed to PXE boot.
+
+Each --path is tried in turn until one exists on the BMC. In a path,
+{system} and {manager} stand for every System and Manager of the BMC, and
+vendor=path tries the path only on BMCs whose service root reports that
+Vendor. A LogService or LogEntry collection is saved as the Message of each
+entry, one per line; any other resource, such as an Oem one-shot endpoint,
+is saved as served. BMCs where no path exists are listed as unsupported.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if conOutDir == "" {
+			return errors.New("--out-dir is required")
+		}
+		paths := redfish.DefaultConsolePaths
+		if len(conPaths) > 0 {
+			paths = nil
+			for _, s := range conPaths {
+				p, err := redfish.ParseConsolePath(s)
+				if err != nil {
+					return fmt.Errorf("--path: %w", err)
+				}
+				paths = append(paths, p)
+			}
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		sel, err := selectTargets(conFile, conHostsCSV, conHostsFile, conXnames)
+		if err != nil {
+			return err
+		}
+		targets := sel.targets
+		if err := os.MkdirAll(conOutDir, 0o755); err != nil {
+			return err
+		}
+
+		// Results are kept per target so output follows inventory order
+		type captured struct {
+			bytes int
+			file  string
+		}
+		results := make([]*captured, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		var mu sync.Mutex
+		var unsupported []string
+		failed := 0
+		forEachTarget(cmd.Context(), targets, conBatchSize, conTimeout, func(ctx context.Context, t bmcTarget) {
+			consoles, err := redfish.CaptureConsole(ctx, t.Host, user, pass, conInsecure, conTimeout, paths)
+			var file string
+			var n int
+			if err == nil {
+				file = filepath.Join(conOutDir, consoleFileName(t))
+				data := consoleData(consoles)
+				n = len(data)
+				err = writeFileAtomic(file, data)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrNoConsole):
+				unsupported = append(unsupported, t.label())
+			case err != nil:
+				failed++
+				diag.Warnf("%s: console capture: %v", t.label(), err)
+			default:
+				results[index[t]] = &captured{bytes: n, file: file}
+			}
+		})
+
+		ok := 0
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "HOST\tBYTES\tFILE")
+		for i, r := range results {
+			if r == nil {
+				continue
+			}
+			ok++
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", targets[i].label(), r.bytes, r.file)
+		}
+		if ok > 0 {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		if len(unsupported) > 0 {
+			fmt.Printf("Unsupported (no console resource): %s\n", strings.Join(unsupported, ", "))
+		}
+		fmt.Printf("Console: %d captured, %d unsupported, %d failed\n", ok, len(unsupported), failed)
+		if err := checkOutcome(ok+len(unsupported), failed, "console capture failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// consoleFileName is the name of a BMC's file in --out-dir.
+func consoleFileName(t bmcTarget) string {
+	return strings.NewReplacer(":", "_", "/", "_").Replace(t.label()) + ".log"
+}
+
+// consoleData joins the consoles of one BMC; with more than one, each is
+// headed by its path.
+func consoleData(consoles []redfish.Console) []byte {
+	if len(consoles) == 1 {
+		return consoles[0].Data
+	}
+	var buf bytes.Buffer
+	for i, c := range consoles {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "==> %s <==\n", c.Path)
+		buf.Write(c.Data)
+	}
+	return buf.Bytes()
+}
+
+func init() {
+	rootCmd.AddCommand(consoleCmd)
+	consoleCmd.AddCommand(consoleCaptureCmd)
+	consoleCmd.PersistentFlags().StringVarP(&conFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	consoleCmd.PersistentFlags().StringVar(&conHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	consoleCmd.PersistentFlags().StringVar(&conHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	consoleCmd.PersistentFlags().BoolVar(&conInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	consoleCmd.PersistentFlags().DurationVar(&conTimeout, "timeout", 60*time.Second, "per-BMC request timeout")
+	consoleCmd.PersistentFlags().IntVar(&conBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	consoleCmd.PersistentFlags().StringSliceVar(&conXnames, "xname", nil, "only the BMCs within these cabinet, chassis, slot, BMC, or node xnames (comma-separated or repeated)")
+	consoleCaptureCmd.Flags().StringVar(&conOutDir, "out-dir", "", "directory to write one <xname or host>.log per BMC to")
+	consoleCaptureCmd.Flags().StringSliceVar(&conPaths, "path", nil, "console resources to try in order, as path or vendor=path with {system} and {manager} placeholders (default: the Console, SOL, and OpenBMC HostLogger log services)")
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsoleCapture(t *testing.T) {
+	withConsole := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/redfish/v1":
+			fmt.Fprint(w, `{"Vendor":"Contoso"}`)
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case "/redfish/v1/Systems/Node0/LogServices/SOL/Entries":
+			fmt.Fprint(w, `{"Members":[{"Id":"1","Message":"PXE-E18: Server response timeout."}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer withConsole.Close()
+	without := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1":
+			fmt.Fprint(w, `{}`)
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer without.Close()
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	host, other := strings.TrimPrefix(withConsole.URL, "https://"), strings.TrimPrefix(without.URL, "https://")
+
+	dir := t.TempDir()
+	conFile, conHostsCSV, conHostsFile, conXnames = "", host+","+other, "", nil
+	conInsecure, conTimeout, conBatchSize = true, 5*time.Second, 2
+	conOutDir, conPaths = dir, []string{"{system}/LogServices/SOL/Entries"}
+	t.Cleanup(func() { conHostsCSV, conOutDir, conPaths = "", "", nil })
+
+	consoleCaptureCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return consoleCaptureCmd.RunE(consoleCaptureCmd, nil) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	name := strings.ReplaceAll(host, ":", "_") + ".log"
+	got, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil || string(got) != "PXE-E18: Server response timeout.\n" {
+		t.Fatalf("%s = %q, %v", name, got, err)
+	}
+	for _, want := range []string{
+		host + "  34     " + filepath.Join(dir, name),
+		"Unsupported (no console resource): " + other,
+		"Console: 1 captured, 1 unsupported, 0 failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
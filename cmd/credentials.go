@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var (
+	passwordFile     string
+	credentialHelper string
+)
+
+// credentialHelperTimeout bounds a --credential-helper run, e.g. one waiting
+// on a vault login.
+const credentialHelperTimeout = time.Minute
+
+// redfishCredentials returns the Redfish username from REDFISH_USER and the
+// password from --credential-helper, --password-file (or
+// REDFISH_PASSWORD_FILE), or REDFISH_PASSWORD, in that order. Commands
+// call it once, so a helper runs once per command.
+func redfishCredentials() (string, string, error) {
+	user := os.Getenv("REDFISH_USER")
+	pass, err := redfishPassword()
+	if err != nil {
+		return "", "", err
+	}
+	if user == "" || pass == "" {
+		return "", "", errors.New("REDFISH_USER and a password (REDFISH_PASSWORD, --password-file, or --credential-helper) are required")
+	}
+	return user, pass, nil
+}
+
+// redfishPassword reads the password from its configured source. Errors
+// never include the secret.
+func redfishPassword() (string, error) {
+	file := passwordFile
+	if file == "" {
+		file = os.Getenv("REDFISH_PASSWORD_FILE")
+	}
+	switch {
+	case credentialHelper != "" && file != "":
+		return "", errors.New("--credential-helper cannot be combined with --password-file or REDFISH_PASSWORD_FILE")
+	case credentialHelper != "":
+		return runCredentialHelper(credentialHelper)
+	case file != "":
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("password file: %w", err)
+		}
+		pass := trimSecret(raw)
+		if pass == "" {
+			return "", fmt.Errorf("password file %s is empty", file)
+		}
+		return pass, nil
+	}
+	return os.Getenv("REDFISH_PASSWORD"), nil
+}
+
+// runCredentialHelper runs command with sh and returns its standard output
+// as the password. Its standard error is passed through.
+func runCredentialHelper(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialHelperTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	c := exec.CommandContext(ctx, "sh", "-c", command)
+	c.Stdout = &out
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("credential helper did not finish within %s", credentialHelperTimeout)
+		}
+		return "", fmt.Errorf("credential helper: %w", err)
+	}
+	pass := trimSecret(out.Bytes())
+	if pass == "" {
+		return "", errors.New("credential helper printed no password")
+	}
+	return pass, nil
+}
+
+// trimSecret drops the trailing newline (LF or CRLF) that files and
+// commands usually end with. Other whitespace is part of the secret.
+func trimSecret(raw []byte) string {
+	s := strings.TrimSuffix(string(raw), "\n")
+	return strings.TrimSuffix(s, "\r")
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedfishCredentialsSources(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "password")
+	if err := os.WriteFile(file, []byte("from file \r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { passwordFile, credentialHelper = "", "" })
+
+	cases := []struct {
+		name, file, envFile, helper string
+		want, wantErr               string
+	}{
+		{name: "env", want: "from env"},
+		{name: "file", file: file, want: "from file "},
+		{name: "env file", envFile: file, want: "from file "},
+		{name: "helper", helper: "printf 'from helper\\n'", want: "from helper"},
+		{name: "empty file", file: empty, wantErr: "is empty"},
+		{name: "failing helper", helper: "exit 3", wantErr: "credential helper"},
+		{name: "silent helper", helper: "true", wantErr: "printed no password"},
+		{name: "helper and file", file: file, helper: "echo x", wantErr: "cannot be combined"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("REDFISH_USER", "admin")
+			t.Setenv("REDFISH_PASSWORD", "from env")
+			t.Setenv("REDFISH_PASSWORD_FILE", tc.envFile)
+			passwordFile, credentialHelper = tc.file, tc.helper
+			user, pass, err := redfishCredentials()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user != "admin" || pass != tc.want {
+				t.Errorf("credentials = %q, %q; want admin, %q", user, pass, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// withRunDeadline bounds a whole run by --deadline, given as a duration from
+// now (e.g. 2h) or an absolute RFC 3339 time. An empty value adds no bound.
+func withRunDeadline(ctx context.Context, deadline string) (context.Context, context.CancelFunc, error) {
+	if deadline == "" {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	if d, err := time.ParseDuration(deadline); err == nil {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		return ctx, cancel, nil
+	}
+	t, err := time.Parse(time.RFC3339, deadline)
+	if err != nil {
+		return nil, nil, fmt.Errorf("--deadline must be a duration like 2h or an RFC 3339 time, got %q", deadline)
+	}
+	ctx, cancel := context.WithDeadline(ctx, t)
+	return ctx, cancel, nil
+}
+
+// deadlineHit reports whether runCtx ended because --deadline passed rather
+// than because the command was interrupted.
+func deadlineHit(parent, runCtx context.Context) bool {
+	return parent.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded)
+}
+
+// hostTimeout returns --host-timeout, or the per-request timeout when it is
+// not set, as the budget for all of one host's work.
+func hostTimeout(host, request time.Duration) time.Duration {
+	if host > 0 {
+		return host
+	}
+	return request
+}
+
+// deadlineNote is appended to a run summary when n hosts were skipped
+// because --deadline passed before they started.
+func deadlineNote(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d skipped (deadline)", n)
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestWithRunDeadline(t *testing.T) {
+	ctx := context.Background()
+	for _, spec := range []string{"", "90m", "2030-01-02T03:04:05Z"} {
+		runCtx, cancel, err := withRunDeadline(ctx, spec)
+		if err != nil {
+			t.Fatalf("withRunDeadline(%q): %v", spec, err)
+		}
+		d, ok := runCtx.Deadline()
+		switch {
+		case spec == "" && ok:
+			t.Errorf("empty --deadline set deadline %v", d)
+		case spec == "90m" && (!ok || time.Until(d) < 89*time.Minute):
+			t.Errorf("--deadline 90m set deadline %v", d)
+		case strings.HasPrefix(spec, "2030") && !d.Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)):
+			t.Errorf("--deadline %s set deadline %v", spec, d)
+		}
+		cancel()
+	}
+	if _, _, err := withRunDeadline(ctx, "tomorrow"); err == nil {
+		t.Error("expected an error for --deadline tomorrow")
+	}
+}
+
+func TestFirmwareDeadlineSkipsUnstartedHosts(t *testing.T) {
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	defer func() {
+		fwHostsCSV, fwImageURI, fwDeadline, fwTargets, fwBatchSize, fwYes = "", "", "", nil, 0, false
+	}()
+	// Hosts in TEST-NET are never contacted: the deadline has already passed
+	fwHostsCSV = "192.0.2.1,192.0.2.2"
+	fwImageURI = "http://example.com/fw.bin"
+	fwTargets = []string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}
+	fwDeadline = "2001-01-01T00:00:00Z"
+	fwYes = true
+
+	for _, batch := range []int{1, 4} {
+		fwBatchSize = batch
+		firmwareCmd.SetContext(context.Background())
+		out, err := captureOutput(t, func() error { return firmwareCmd.RunE(firmwareCmd, nil) })
+		if err != nil {
+			t.Fatalf("batch %d: unexpected error: %v\n%s", batch, err, out)
+		}
+		if !strings.Contains(out, "0 failed, 2 skipped (deadline)") || !strings.Contains(out, "192.0.2.2: skipped (deadline)") {
+			t.Errorf("batch %d: expected both hosts skipped, got:\n%s", batch, out)
+		}
+	}
+
+	fwBatchSize = 2
+	firmwareStatusCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return firmwareStatusCmd.RunE(firmwareStatusCmd, nil) })
+	if err != nil {
+		t.Fatalf("status: unexpected error: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Hosts: 0 read, 0 failed, 2 skipped (deadline)") {
+		t.Errorf("status: expected both hosts skipped, got:\n%s", out)
+	}
+}
+
+// slotHogs returns n BMCs backed by mockRedfishFirmwareServer. The first
+// batch of them to send a request hang until that request is cancelled, so
+// they hold their slots until their --host-timeout runs out; the others
+// answer at once.
+func slotHogs(t *testing.T, n, batch int) []string {
+	t.Helper()
+	var arrivals atomic.Int32
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	var hosts []string
+	for range n {
+		backend := mockRedfishFirmwareServer(t, 0, nil, nil)
+		var once sync.Once
+		var hog bool
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			once.Do(func() { hog = arrivals.Add(1) <= int32(batch) })
+			if hog {
+				select {
+				case <-r.Context().Done():
+				case <-release:
+				}
+				return
+			}
+			backend.Config.Handler.ServeHTTP(w, r)
+		}))
+		t.Cleanup(srv.Close)
+		hosts = append(hosts, strings.TrimPrefix(srv.URL, "https://"))
+	}
+	return hosts
+}
+
+// TestHostTimeoutStartsWithSlot runs more hosts than --batch-size allows at
+// once, with a --host-timeout shorter than the wait for a slot: a host's
+// budget must start when it gets its slot, not when it is queued.
+func TestHostTimeoutStartsWithSlot(t *testing.T) {
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	oldFile, oldHosts, oldImage, oldTargets, oldInsecure, oldTimeout := fwFile, fwHostsCSV, fwImageURI, fwTargets, fwInsecure, fwTimeout
+	oldBatch, oldHostTimeout, oldFormat, oldYes, oldPreflight := fwBatchSize, fwHostTimeout, fwFormat, fwYes, skipPreflight
+	t.Cleanup(func() {
+		fwFile, fwHostsCSV, fwImageURI, fwTargets, fwInsecure, fwTimeout = oldFile, oldHosts, oldImage, oldTargets, oldInsecure, oldTimeout
+		fwBatchSize, fwHostTimeout, fwFormat, fwYes, skipPreflight = oldBatch, oldHostTimeout, oldFormat, oldYes, oldPreflight
+	})
+	fwFile, fwImageURI, fwTargets, fwInsecure, fwTimeout, fwYes = "", "http://10.0.0.1/firmware.bin", []string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, true, 30*time.Second, true
+	// The pre-flight probe would reach the hosts before their slots do
+	skipPreflight = true
+
+	// Two hosts hog the two slots for a whole --host-timeout. The other two
+	// only start once it has run out: had their budget started while they
+	// were queued, they would have none left.
+	run := func(c *cobra.Command) (string, error) {
+		fwHostsCSV, fwBatchSize, fwHostTimeout = strings.Join(slotHogs(t, 4, 2), ","), 2, time.Second
+		c.SetContext(context.Background())
+		return captureOutput(t, func() error { return c.RunE(c, nil) })
+	}
+
+	out, err := run(firmwareCmd)
+	if err == nil || strings.Count(out, "Triggered firmware update") != 2 || strings.Count(out, "firmware update failed") != 2 {
+		t.Errorf("firmware: err = %v, want the two queued hosts updated and the two hogs failed:\n%s", err, out)
+	}
+	fwFormat = "json"
+	out, err = run(firmwareStatusCmd)
+	if err == nil || strings.Count(out, `"status": "idle"`) != 2 {
+		t.Errorf("firmware status: err = %v, want the two queued hosts read:\n%s", err, out)
+	}
+}
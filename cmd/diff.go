@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"bootstrap/internal/inventory"
+
+	"github.com/spf13/cobra"
+)
+
+var diffOutput string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old.yaml> <new.yaml> | diff <file.yaml>",
+	Short: "Show added, removed, and modified entries between two inventory files",
+	Long: `Compare bmcs[] and nodes[] of two inventory files by xname. With a single
+argument the file is compared against <file>.bak, the copy discover leaves
+behind before rewriting it.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		oldPath, newPath := args[0]+".bak", args[0]
+		if len(args) == 2 {
+			oldPath, newPath = args[0], args[1]
+		}
+		if diffOutput != "table" && diffOutput != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		oldDoc, err := readInventory(oldPath)
+		if err != nil {
+			return err
+		}
+		newDoc, err := readInventory(newPath)
+		if err != nil {
+			return err
+		}
+		res := inventory.Diff(oldDoc, newDoc)
+
+		if diffOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(res)
+		}
+		if res.Empty() {
+			fmt.Println("No changes")
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHANGE\tSECTION\tXNAME\tDETAIL")
+		for _, group := range []struct {
+			kind    string
+			changes []inventory.Change
+		}{{"added", res.Added}, {"removed", res.Removed}, {"modified", res.Modified}} {
+			for _, c := range group.changes {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", group.kind, c.Section, c.Xname, changeDetail(c))
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("%d added, %d removed, %d modified\n", len(res.Added), len(res.Removed), len(res.Modified))
+		return nil
+	},
+}
+
+// changeDetail renders field changes as "mac a -> b, ip c -> d".
+func changeDetail(c inventory.Change) string {
+	parts := make([]string, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		parts = append(parts, fmt.Sprintf("%s %s -> %s", f.Field, valueOrDash(f.Old), valueOrDash(f.New)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "table", "output format: table or json")
+}
@@ -9,25 +9,64 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"bootstrap/internal/diag"
 	"bootstrap/internal/discover"
 	"bootstrap/internal/inventory"
 	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var (
-	discFile        string
-	discBMCSubnet   string
-	discNodeSubnet  string
-	discNodeStartIP string
-	discInsecure    bool
-	discTimeout     time.Duration
-	discSSHPubKey   string
-	discDryRun      bool
+	discFile          string
+	discOut           string
+	discBMCSubnet     string
+	discNodeSubnet    string
+	discNodeStartIP   string
+	discInsecure      bool
+	discTimeout       time.Duration
+	discHostTimeout   time.Duration
+	discDeadline      string
+	discSSHPubKey     string
+	discDryRun        bool
+	discReleaseStale  bool
+	discPruneOrphans  bool
+	discNodesPerBMC   int
+	discCountFirst    bool
+	discForce         bool
+	discProgress      string
+	discNICExclude    string
+	discIPStrategy    string
+	discIPOffset      int
+	discNICInclude    []string
+	discExplainNICs   bool
+	discReserve       []string
+	discDefaultRole   string
+	discDetails       bool
+	discAllowDupMACs  bool
+	discSkipFile      string
+	discOnlyFile      string
+	discSortBMCs      bool
+	discReuseByMAC    bool
+	discFailOnMAC     bool
+	discCacheDir      string
+	discFromCache     bool
+	discCacheMaxAge   time.Duration
+	discControllers   bool
+	discAggregate     bool
+	discWatch         time.Duration
+	discUntilComplete bool
+	discDNS           string
+	discRecordBMCIPs  bool
+	discPinIdentity   bool
+	discIncludeAbsent bool
+
+	// discStdout is the real stdout while --out - sends the report to stderr
+	discStdout *os.File
 )
 
 var discoverCmd = &cobra.Command{
@@ -37,6 +76,36 @@ var discoverCmd = &cobra.Command{
 		if discFile == "" {
 			return fmt.Errorf("--file is required")
 		}
+		if discProgress != "auto" && discProgress != "off" {
+			return fmt.Errorf("--progress must be auto or off")
+		}
+		if discIPStrategy != discover.IPStrategySequential && discIPStrategy != discover.IPStrategyNIDOffset {
+			return fmt.Errorf("--ip-strategy must be %s or %s", discover.IPStrategySequential, discover.IPStrategyNIDOffset)
+		}
+		if discIPOffset != 0 && discIPStrategy != discover.IPStrategyNIDOffset {
+			return fmt.Errorf("--ip-offset requires --ip-strategy %s", discover.IPStrategyNIDOffset)
+		}
+		switch {
+		case discWatch < 0:
+			return fmt.Errorf("--watch must be a positive interval")
+		case discUntilComplete && discWatch == 0:
+			return fmt.Errorf("--until-complete requires --watch")
+		case discWatch > 0 && discDeadline != "":
+			return fmt.Errorf("--deadline cannot be combined with --watch")
+		case discWatch > 0 && discOut == "-":
+			return fmt.Errorf("--out - cannot be combined with --watch")
+		case discFromCache && discCacheDir == "":
+			return fmt.Errorf("--from-cache requires --cache-dir")
+		case discCacheMaxAge != 0 && !discFromCache:
+			return fmt.Errorf("--cache-max-age requires --from-cache")
+		case discFromCache && (discWatch > 0 || discSSHPubKey != ""):
+			return fmt.Errorf("--from-cache cannot be combined with --watch or --ssh-pubkey")
+		}
+		nicRules, err := redfish.ParseNICRules(discNICExclude, discNICInclude)
+		if err != nil {
+			return err
+		}
+		nicRules.Explain = discExplainNICs
 		// Validate subnet flags - at least one must be provided
 		if discBMCSubnet == "" && discNodeSubnet == "" {
 			return fmt.Errorf("at least one of --bmc-subnet or --node-subnet is required")
@@ -48,39 +117,54 @@ var discoverCmd = &cobra.Command{
 		if discNodeSubnet == "" {
 			discNodeSubnet = discBMCSubnet
 		}
-		user := os.Getenv("REDFISH_USER")
-		pass := os.Getenv("REDFISH_PASSWORD")
-		if user == "" || pass == "" {
-			return fmt.Errorf("REDFISH_USER and REDFISH_PASSWORD env vars are required")
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		if discOut == "-" {
+			// stdout carries only the inventory; the report goes to stderr
+			discStdout, os.Stdout = os.Stdout, os.Stderr
+			defer func() { os.Stdout = discStdout }()
 		}
 
 		raw, err := os.ReadFile(discFile)
 		if err != nil {
 			return err
 		}
-		var doc inventory.FileFormat
-		if err := yaml.Unmarshal(raw, &doc); err != nil {
+		doc, tree, err := inventory.ParseDocument(raw)
+		if err != nil {
 			return err
 		}
 		if len(doc.BMCs) == 0 {
 			return fmt.Errorf("input must contain non-empty bmcs[]")
 		}
 
+		skip, err := listedBMCs(doc.BMCs, discSkipFile, discOnlyFile)
+		if err != nil {
+			return err
+		}
+
 		// Dry-run: only show what would be contacted and exit.
 		if discDryRun {
 			hosts := make([]string, 0, len(doc.BMCs))
 			for _, b := range doc.BMCs {
-				host := b.IP
-				if host == "" {
-					host = b.Xname
+				if cmd.Context().Err() != nil {
+					return errInterrupted
+				}
+				if skip[b.Xname] {
+					continue
 				}
+				host := b.Address()
 				hosts = append(hosts, host)
 			}
 			fmt.Printf("[dry-run] would contact %d BMC(s): %v\n", len(hosts), hosts)
+			if len(skip) > 0 {
+				fmt.Printf("[dry-run] would skip %d listed BMC(s), keeping their nodes\n", len(skip))
+			}
 			if discBMCSubnet == discNodeSubnet {
-				fmt.Printf("[dry-run] would allocate BMC and node IPs from subnet %s and write back to %s\n", discNodeSubnet, discFile)
+				fmt.Printf("[dry-run] would allocate BMC and node IPs from subnet %s and write %s\n", discNodeSubnet, discTarget())
 			} else {
-				fmt.Printf("[dry-run] would allocate BMC IPs from subnet %s and node IPs from subnet %s, writing to %s\n", discBMCSubnet, discNodeSubnet, discFile)
+				fmt.Printf("[dry-run] would allocate BMC IPs from subnet %s and node IPs from subnet %s, writing %s\n", discBMCSubnet, discNodeSubnet, discTarget())
 			}
 			if discSSHPubKey != "" {
 				fmt.Printf("[dry-run] would set SSH authorized keys on each BMC from %s\n", discSSHPubKey)
@@ -88,6 +172,32 @@ var discoverCmd = &cobra.Command{
 			return nil
 		}
 
+		if discCacheDir != "" {
+			if err := redfish.ConfigureCache(redfish.CacheOptions{Dir: discCacheDir, Replay: discFromCache, MaxAge: discCacheMaxAge}); err != nil {
+				return err
+			}
+			defer redfish.ConfigureCache(redfish.CacheOptions{}) //nolint:errcheck
+		}
+
+		start := time.Now()
+		runCtx, cancelRun, err := withRunDeadline(cmd.Context(), discDeadline)
+		if err != nil {
+			return err
+		}
+		defer cancelRun()
+		perHost := hostTimeout(discHostTimeout, discTimeout)
+		if !discFromCache {
+			var sample []bmcTarget
+			for _, b := range doc.BMCs {
+				if !skip[b.Xname] {
+					sample = append(sample, bmcTarget{Host: b.Address(), Xname: b.Xname})
+				}
+			}
+			if err := preflightCredentials(runCtx, sample, user, pass, discInsecure, discTimeout, preflightSample); err != nil {
+				return err
+			}
+		}
+
 		// Optionally set SSH authorized keys on each BMC if provided.
 		if discSSHPubKey != "" {
 			keyBytes, err := os.ReadFile(discSSHPubKey)
@@ -96,47 +206,375 @@ var discoverCmd = &cobra.Command{
 			}
 			authorized := string(keyBytes)
 			for _, b := range doc.BMCs {
-				host := b.IP
-				if host == "" {
-					host = b.Xname
+				if cmd.Context().Err() != nil {
+					return errInterrupted
 				}
-				ctx := cmd.Context()
-				if discTimeout > 0 {
-					var cancel context.CancelFunc
-					ctx, cancel = context.WithTimeout(ctx, discTimeout)
-					defer cancel()
+				if runCtx.Err() != nil {
+					break // discovery below reports the BMCs left unvisited
 				}
-				if err := redfish.SetAuthorizedKeys(ctx, host, user, pass, discInsecure, discTimeout, authorized); err != nil {
-					fmt.Fprintf(os.Stderr, "WARN: %s: set authorized keys: %v\n", b.Xname, err)
+				if skip[b.Xname] {
+					continue
+				}
+				host := b.Address()
+				ctx := runCtx
+				var cancel context.CancelFunc
+				if perHost > 0 {
+					ctx, cancel = context.WithTimeout(ctx, perHost)
+				}
+				err := redfish.SetAuthorizedKeys(ctx, host, user, pass, discInsecure, discTimeout, authorized)
+				if cancel != nil {
+					// Released per BMC, not when the whole run returns
+					cancel()
+				}
+				if err != nil {
+					diag.Warnf("%s: set authorized keys: %v", b.Xname, err)
 				}
 			}
 		}
 
-		nodes, err := discover.UpdateNodes(&doc, discBMCSubnet, discNodeSubnet, discNodeStartIP, user, pass, discInsecure, discTimeout)
+		var progress *progressPrinter
+		if discProgress == "auto" {
+			progress = newProgressPrinter(os.Stdout, stdoutIsTerminal())
+		}
+		opts := discover.Options{
+			BMCSubnet:          discBMCSubnet,
+			NodeSubnet:         discNodeSubnet,
+			NodeStartIP:        discNodeStartIP,
+			User:               user,
+			Pass:               pass,
+			Insecure:           discInsecure,
+			Timeout:            discTimeout,
+			HostTimeout:        discHostTimeout,
+			Reserve:            discReserve,
+			ReleaseStale:       discReleaseStale,
+			PruneOrphans:       discPruneOrphans,
+			NodesPerBMC:        discNodesPerBMC,
+			CountFirst:         discCountFirst,
+			IgnoreCapacity:     discForce,
+			ReuseByMAC:         discReuseByMAC,
+			DefaultRole:        discDefaultRole,
+			CollectDetails:     discDetails,
+			IPStrategy:         discIPStrategy,
+			IPOffset:           discIPOffset,
+			NICRules:           nicRules,
+			Skip:               skip,
+			AllowDuplicateMACs: discAllowDupMACs,
+
+			IncludeChassisControllers: discControllers,
+			Aggregate:                 discAggregate,
+			RecordBMCIPs:              discRecordBMCIPs,
+			PinIdentity:               discPinIdentity,
+			IncludeAbsent:             discIncludeAbsent,
+			Pacer:                     newPacer(1),
+		}
+		if discDNS != "" {
+			opts.Resolver = discover.NewResolver(discDNS)
+		}
+		if discWatch > 0 {
+			return watchDiscovery(cmd.Context(), opts)
+		}
+		if progress != nil {
+			opts.Progress = progress.update
+		}
+		res, err := discover.UpdateNodes(runCtx, &doc, opts)
+		if progress != nil {
+			progress.finish()
+		}
 		if err != nil {
 			return err
 		}
-		doc.Nodes = nodes
-		bytes, err := yaml.Marshal(&doc)
-		if err != nil {
+		for _, n := range res.Released {
+			diag.Infof("Released %s (was %s)", n.IP, n.Xname)
+		}
+		if discReleaseStale {
+			fmt.Printf("Released %d stale node IP(s)\n", len(res.Released))
+		}
+		if discReuseByMAC {
+			fmt.Printf("Moved %d node(s) to a new xname by MAC\n", len(res.Renamed))
+		}
+		reportOrphans(res.Orphans)
+		if discPinIdentity {
+			fmt.Printf("Pinned the TLS identity of %d BMC(s)\n", len(res.Pinned))
+		}
+		if discControllers {
+			fmt.Printf("Discovered %d chassis controller(s)\n", len(res.Controllers))
+		}
+		if discAggregate {
+			fmt.Printf("Discovered %d BMC(s) through their chassis controller\n", len(res.Aggregated))
+		}
+		setMetric("bmcs_total", float64(len(doc.BMCs)))
+		setMetric("bmcs_failed", float64(len(res.Failed)))
+		setMetric("nodes_discovered", float64(len(res.Nodes)-res.CarriedOver-keptOrphans(res.Orphans)))
+		setMetric("ips_allocated", float64(res.Allocated))
+		noteFailures(res.Failed, countCategories(res.Categories))
+		if err := checkMACChanges(res.MACChanges); err != nil {
 			return err
 		}
-		if err := os.WriteFile(discFile, bytes, 0o644); err != nil {
+		if err := saveDiscovery(&doc, tree, raw, res, true); err != nil {
 			return err
 		}
-		fmt.Printf("Updated %s with %d node record(s)\n", discFile, len(nodes))
+		nodes := doc.Nodes
+		expired := 0
+		if res.Interrupted && deadlineHit(cmd.Context(), runCtx) {
+			for _, x := range res.Skipped {
+				diag.Warnf("%s: skipped (deadline)", x)
+			}
+			expired = len(res.Skipped)
+		} else if res.Interrupted {
+			fmt.Printf("Interrupted: wrote %s with %d node record(s) (%d kept from BMCs not yet visited or failed)\n", discTarget(), len(nodes), res.CarriedOver)
+			return errInterrupted
+		}
+		fmt.Printf("Updated %s with %d node record(s)\n", discTarget(), len(nodes))
+		ok := res.Queried - len(res.Failed)
+		fmt.Printf("Discover: %d BMC(s) succeeded, %d failed, %d skipped (listed), %d duplicate MAC(s) detected%s\n",
+			ok, len(res.Failed), len(res.Listed), len(res.Duplicates), deadlineNote(expired))
+		if len(res.Absent) > 0 {
+			fmt.Printf("Skipped %d system(s) reported Absent: %s\n", len(res.Absent), strings.Join(res.Absent, ", "))
+		}
+		printMACChanges(res.MACChanges)
+		for _, st := range res.Subnets {
+			fmt.Println(st)
+		}
+		printCategories(countCategories(res.Categories))
+		fmt.Printf("Wall time: %s\n", time.Since(start).Round(time.Millisecond))
+		if slow := slowestBMCs(res.Timings, 5); len(slow) > 0 {
+			fmt.Println("Slowest BMCs:")
+			for _, s := range slow {
+				fmt.Printf("  %s  %s\n", s.Xname, s.Duration.Round(time.Millisecond))
+			}
+		}
+		if err := checkOutcome(ok, len(res.Failed), "discovery failed for %d BMC(s)", len(res.Failed)); err != nil {
+			return err
+		}
+		if len(res.Duplicates) > 0 {
+			// The inventory was written, but DHCP cannot serve both nodes
+			return &outcomeError{msg: fmt.Sprintf("%d duplicate MAC(s) detected", len(res.Duplicates)), code: exitPartial}
+		}
 		return nil
 	},
 }
 
+// printMACChanges lists the nodes rediscovered with a different MAC, whose
+// DHCP reservations elsewhere are now stale.
+func printMACChanges(changes []discover.MACChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("Changed MAC(s) on %d node(s):\n", len(changes))
+	for _, c := range changes {
+		fmt.Printf("  %s  %s -> %s\n", c.Xname, c.Old, c.New)
+	}
+}
+
+// reportOrphans warns about each previous node whose BMC is no longer in
+// bmcs[] and says whether it was kept or pruned.
+func reportOrphans(orphans []inventory.Entry) {
+	if len(orphans) == 0 {
+		return
+	}
+	for _, n := range orphans {
+		if discPruneOrphans && !n.Static {
+			diag.Warnf("%s: BMC not in bmcs[]; pruned", n.Xname)
+		} else {
+			diag.Warnf("%s: BMC not in bmcs[]; keeping it", n.Xname)
+		}
+	}
+	if discPruneOrphans {
+		fmt.Printf("Pruned %d orphaned node(s)\n", len(orphans)-keptOrphans(orphans))
+	} else {
+		fmt.Printf("Kept %d orphaned node(s) whose BMC is not in bmcs[] (pass --prune-orphans to drop them)\n", len(orphans))
+	}
+}
+
+// keptOrphans counts the orphans left in nodes[]: all of them, or with
+// --prune-orphans only the static ones.
+func keptOrphans(orphans []inventory.Entry) int {
+	if !discPruneOrphans {
+		return len(orphans)
+	}
+	kept := 0
+	for _, n := range orphans {
+		if n.Static {
+			kept++
+		}
+	}
+	return kept
+}
+
+// checkMACChanges returns an error, before the inventory is written, when
+// --fail-on-mac-change is given and discovery found changed MACs.
+func checkMACChanges(changes []discover.MACChange) error {
+	if !discFailOnMAC || len(changes) == 0 {
+		return nil
+	}
+	printMACChanges(changes)
+	return fmt.Errorf("%d node MAC change(s) detected; %s was not updated (confirm the swaps, then rerun without --fail-on-mac-change)", len(changes), discTarget())
+}
+
+// saveDiscovery writes the nodes[] and controllers[] of res (and bmcs[]
+// with --sort-bmcs) into doc and tree and replaces --file with the result,
+// or writes it to --out instead. With backup, raw, the file as it was read,
+// is first saved to <file>.bak; --file is never touched with --out.
+func saveDiscovery(doc *inventory.FileFormat, tree *inventory.Document, raw []byte, res discover.Result, backup bool) error {
+	doc.Nodes = res.Nodes
+	doc.Controllers = res.Controllers
+	// Sorted by xname so reruns give clean diffs whatever order BMCs answer in
+	inventory.Canonicalize(doc, discSortBMCs)
+	// Only nodes[] (and bmcs[] with --sort-bmcs or --record-bmc-ips, or
+	// when identities were pinned) is rewritten; comments and other keys
+	// stay as they are
+	if err := tree.Set("nodes", doc.Nodes); err != nil {
+		return err
+	}
+	if discSortBMCs || discRecordBMCIPs || len(res.Pinned) > 0 {
+		if err := tree.Set("bmcs", doc.BMCs); err != nil {
+			return err
+		}
+	}
+	if len(doc.Controllers) > 0 {
+		if err := tree.Set("controllers", doc.Controllers); err != nil {
+			return err
+		}
+	}
+	bytes, err := tree.Bytes()
+	if err != nil {
+		return err
+	}
+	switch discOut {
+	case "-":
+		_, err := discStdout.Write(bytes)
+		return err
+	case "":
+	default:
+		return writeFileAtomic(discOut, bytes)
+	}
+	if backup {
+		// Keep the previous file so `diff <file>` can show what this run changed.
+		if err := os.WriteFile(discFile+".bak", raw, 0o644); err != nil {
+			return fmt.Errorf("write backup: %w", err)
+		}
+	}
+	return writeFileAtomic(discFile, bytes)
+}
+
+// discTarget names where the discovered inventory is written.
+func discTarget() string {
+	switch discOut {
+	case "":
+		return discFile
+	case "-":
+		return "stdout"
+	}
+	return discOut
+}
+
+// listedBMCs returns the bmcs[] xnames that --skip-file lists or, when
+// --only-file is given, that it does not list. Both files hold one xname or
+// host per line; blank lines and # comments are ignored. A line matching no
+// BMC is warned about so a typo does not go unnoticed.
+func listedBMCs(bmcs []inventory.Entry, skipFile, onlyFile string) (map[string]bool, error) {
+	skip := map[string]bool{}
+	if onlyFile != "" {
+		only, err := matchListFile(bmcs, onlyFile)
+		if err != nil {
+			return nil, err
+		}
+		for i, b := range bmcs {
+			if !only[i] {
+				skip[b.Xname] = true
+			}
+		}
+	}
+	if skipFile != "" {
+		listed, err := matchListFile(bmcs, skipFile)
+		if err != nil {
+			return nil, err
+		}
+		for i := range listed {
+			skip[bmcs[i].Xname] = true
+		}
+	}
+	return skip, nil
+}
+
+// matchListFile returns the indexes in bmcs of the BMCs listed in path, by
+// xname (in any case) or by IP or hostname.
+func matchListFile(bmcs []inventory.Entry, path string) (map[int]bool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	matched := map[int]bool{}
+	for n, line := range strings.Split(string(raw), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		want := line
+		if x, err := xname.Normalize(line); err == nil {
+			want = x
+		}
+		found := false
+		for i, b := range bmcs {
+			x := b.Xname
+			if nx, err := xname.Normalize(x); err == nil {
+				x = nx
+			}
+			if x == want || b.IP == line || b.Host != "" && strings.EqualFold(b.Host, line) {
+				matched[i], found = true, true
+			}
+		}
+		if !found {
+			diag.Warnf("%s:%d: %q matches no BMC in bmcs[]", path, n+1, line)
+		}
+	}
+	return matched, nil
+}
+
 func init() {
 	rootCmd.AddCommand(discoverCmd)
-	discoverCmd.Flags().StringVarP(&discFile, "file", "f", "", "YAML file containing bmcs[] and nodes[] (nodes will be overwritten)")
+	discoverCmd.Flags().StringVarP(&discFile, "file", "f", "", "YAML file containing bmcs[] and nodes[] (nodes will be overwritten unless --out is given)")
+	discoverCmd.Flags().StringVarP(&discOut, "out", "o", "", "write the updated inventory here instead of back to --file, which is then only read (- = YAML on stdout, with the report on stderr)")
 	discoverCmd.Flags().StringVar(&discBMCSubnet, "bmc-subnet", "", "CIDR for BMC IPs, e.g. 192.168.100.0/24 (if not specified, uses --node-subnet)")
 	discoverCmd.Flags().StringVar(&discNodeSubnet, "node-subnet", "", "CIDR for node IPs, e.g. 10.42.0.0/24 (if not specified, uses --bmc-subnet)")
 	discoverCmd.Flags().StringVar(&discNodeStartIP, "node-start-ip", "", "Start node IP allocation at this address (skips all IPs before it)")
-	discoverCmd.Flags().BoolVar(&discInsecure, "insecure", true, "allow insecure TLS to BMCs")
-	discoverCmd.Flags().DurationVar(&discTimeout, "timeout", 12*time.Second, "per-BMC discovery timeout")
+	discoverCmd.Flags().BoolVar(&discInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	discoverCmd.Flags().DurationVar(&discTimeout, "timeout", 12*time.Second, "per-request timeout for BMC calls")
+	discoverCmd.Flags().DurationVar(&discHostTimeout, "host-timeout", 0, "bound on all of one BMC's discovery work (default: --timeout)")
+	discoverCmd.Flags().StringVar(&discDeadline, "deadline", "", "bound on the whole run, as a duration (2h) or RFC 3339 time; BMCs not started by then are skipped and keep their previous nodes")
 	discoverCmd.Flags().StringVar(&discSSHPubKey, "ssh-pubkey", "", "Path to an SSH public key to set as AuthorizedKeys on each BMC (optional)")
 	discoverCmd.Flags().BoolVar(&discDryRun, "dry-run", false, "plan only: print which BMCs would be contacted and exit")
+	discoverCmd.Flags().StringSliceVar(&discReserve, "reserve", nil, "IPs or ranges never to allocate, e.g. 10.42.0.50-10.42.0.99,10.42.0.200 (adds to reserved[] in the file)")
+	discoverCmd.Flags().BoolVar(&discDetails, "collect-details", false, "also record node serial, model, and SKU and each BMC's firmware_version")
+	discoverCmd.Flags().StringVar(&discDefaultRole, "default-role", "", "role (e.g. compute, management) for nodes that do not already have one")
+	discoverCmd.Flags().StringVar(&discIPStrategy, "ip-strategy", discover.IPStrategySequential, "node IP allocation: sequential (next free address) or nid-offset (subnet base + nid + --ip-offset)")
+	discoverCmd.Flags().IntVar(&discIPOffset, "ip-offset", 0, "added to each node's nid with --ip-strategy nid-offset, e.g. 10 gives nid 1 the address .11")
+	discoverCmd.Flags().StringVar(&discNICExclude, "nic-exclude", redfish.DefaultNICExclude, "never boot from NICs whose Description matches this case-insensitive regular expression (empty = none)")
+	discoverCmd.Flags().StringSliceVar(&discNICInclude, "nic-include", nil, "EthernetInterface Ids (e.g. 1,ManagementEthernet) always treated as bootable, even when excluded")
+	discoverCmd.Flags().BoolVar(&discExplainNICs, "explain-nics", false, "log every EthernetInterface of each system with the rule that chose or rejected it (with --log-format json, one record per NIC)")
+	discoverCmd.Flags().StringVar(&discProgress, "progress", "auto", "progress reporting: auto (in place on a terminal, a line every 10s otherwise) or off")
+	discoverCmd.Flags().StringVar(&discSkipFile, "skip-file", "", "file listing BMCs (xname or host per line) not to contact; their previous nodes are kept")
+	discoverCmd.Flags().StringVar(&discOnlyFile, "only-file", "", "file listing the only BMCs (xname or host per line) to contact; the others keep their previous nodes")
+	discoverCmd.Flags().BoolVar(&discSortBMCs, "sort-bmcs", false, "also sort bmcs[] by xname and normalize their MACs when writing the file")
+	discoverCmd.Flags().BoolVar(&discAllowDupMACs, "allow-duplicate-macs", false, "keep nodes whose MAC was already found on another node instead of skipping them")
+	discoverCmd.Flags().BoolVar(&discControllers, "include-chassis-controllers", false, "query bmcs[] entries with a chassis controller xname (e.g. x9000c1b0) for their manager NIC and record it in controllers[]")
+	discoverCmd.Flags().BoolVar(&discAggregate, "aggregate", false, "read the systems of each chassis through its chassis controller entry in bmcs[] (e.g. x9000c1b0) in one session; BMCs whose systems it cannot serve completely are queried directly")
+	discoverCmd.Flags().BoolVar(&discReuseByMAC, "reuse-by-mac", false, "give a node found under a new xname the IP of the previous node with its MAC (e.g. a moved blade) and drop the old entry")
+	discoverCmd.Flags().BoolVar(&discFailOnMAC, "fail-on-mac-change", false, "exit without writing the file when a node is rediscovered under its xname with a different MAC (e.g. a swapped blade)")
+	discoverCmd.Flags().StringVar(&discCacheDir, "cache-dir", "", "write every successful Redfish GET response to this directory, one file per host and path, for replay with --from-cache")
+	discoverCmd.Flags().BoolVar(&discFromCache, "from-cache", false, "replay discovery from --cache-dir without contacting any BMC; BMCs without cached responses fail")
+	discoverCmd.Flags().DurationVar(&discCacheMaxAge, "cache-max-age", 0, "with --from-cache, treat entries recorded longer ago than this (e.g. 24h) as missing (0 = any age)")
+	addNotifyFlags(discoverCmd)
+	addPreflightFlag(discoverCmd.Flags())
+	discoverCmd.Flags().DurationVar(&discWatch, "watch", 0, "rerun discovery at this interval (e.g. 5m), querying only BMCs that failed or have no nodes, until interrupted")
+	discoverCmd.Flags().BoolVar(&discUntilComplete, "until-complete", false, "with --watch, exit once every BMC has at least one node entry")
+	discoverCmd.Flags().StringVar(&discDNS, "dns", "", "DNS server (host or host:port) to resolve bmcs[] entries without an ip by their host or xname (default: the system resolver)")
+	discoverCmd.Flags().BoolVar(&discPinIdentity, "pin-identity", false, "record the TLS certificate fingerprint of each BMC without one (or, with --accept-new-identity, with a changed one) as its tls_fingerprint in bmcs[]; later runs refuse a BMC presenting another certificate")
+	discoverCmd.Flags().BoolVar(&discRecordBMCIPs, "record-bmc-ips", false, "write the address each BMC's name resolved to into its ip in bmcs[]")
+	discoverCmd.Flags().IntVar(&discNodesPerBMC, "nodes-per-bmc", 2, "nodes expected behind each BMC when checking that --node-subnet has enough free addresses before discovery starts (0 = no check)")
+	discoverCmd.Flags().BoolVar(&discCountFirst, "count-first", false, "for the capacity check, count each BMC's Systems collection instead of assuming --nodes-per-bmc")
+	discoverCmd.Flags().BoolVar(&discForce, "force", false, "start discovery even when --node-subnet has too few free addresses, with a warning")
+	discoverCmd.Flags().BoolVar(&discPruneOrphans, "prune-orphans", false, "drop previous nodes whose BMC is no longer in bmcs[] (static entries are kept); by default they are kept and reported")
+	discoverCmd.Flags().BoolVar(&discIncludeAbsent, "include-absent", false, "also record systems whose Status.State is Absent (e.g. the empty slots of a half-populated blade); by default they are skipped and listed in the summary")
+	discoverCmd.Flags().BoolVar(&discReleaseStale, "release-stale", false, "return IPs of nodes that were not rediscovered to the pool before allocating new ones")
 }
@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/inventory"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestListedBMCs(t *testing.T) {
+	bmcs := []inventory.Entry{
+		{Xname: "x9000c1s0b0", IP: "10.1.1.20"},
+		{Xname: "x9000c1s1b0", IP: "10.1.1.21"},
+		{Xname: "x9000c1s2b0", IP: "10.1.1.22"},
+	}
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	skipFile := write("skip.txt", "# RMA pending\n\nX9000C1S1B0  # case 1234\nx9000c9s9b0\n")
+	onlyFile := write("only.txt", "10.1.1.20\nx9000c1s1b0\n")
+
+	skip, err := listedBMCs(bmcs, skipFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"x9000c1s1b0": true}; !reflect.DeepEqual(skip, want) {
+		t.Errorf("--skip-file: %v, want %v", skip, want)
+	}
+	skip, err = listedBMCs(bmcs, skipFile, onlyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"x9000c1s1b0": true, "x9000c1s2b0": true}; !reflect.DeepEqual(skip, want) {
+		t.Errorf("--skip-file with --only-file: %v, want %v", skip, want)
+	}
+	if _, err := listedBMCs(bmcs, filepath.Join(dir, "missing.txt"), ""); err == nil {
+		t.Error("expected an error for a missing list file")
+	}
+}
+
+func TestDiscoverOut(t *testing.T) {
+	var hits atomic.Int32
+	bmc := mockDiscoveryBMC(t, "aa:bb:cc:00:00:01", 0, &hits)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "inventory.yaml")
+	inv := fmt.Sprintf("# managed in git\nbmcs:\n  - xname: x9000c1s0b0\n    ip: %s\nnodes: []\n", bmc)
+	if err := os.WriteFile(file, []byte(inv), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	oldFile, oldOut, oldBMC, oldNode, oldInsecure, oldTimeout, oldProgress := discFile, discOut, discBMCSubnet, discNodeSubnet, discInsecure, discTimeout, discProgress
+	t.Cleanup(func() {
+		discFile, discOut, discBMCSubnet, discNodeSubnet, discInsecure, discTimeout, discProgress = oldFile, oldOut, oldBMC, oldNode, oldInsecure, oldTimeout, oldProgress
+	})
+	discFile, discBMCSubnet, discNodeSubnet, discInsecure, discTimeout, discProgress = file, "10.0.0.0/24", "10.0.0.0/24", true, 5*time.Second, "off"
+	discoverCmd.SetContext(context.Background())
+
+	unchanged := func(t *testing.T) {
+		t.Helper()
+		if raw, err := os.ReadFile(file); err != nil || string(raw) != inv {
+			t.Errorf("--file changed to %q (%v)", raw, err)
+		}
+		if _, err := os.Stat(file + ".bak"); err == nil {
+			t.Error("--out should not leave a backup of --file")
+		}
+	}
+
+	t.Run("file", func(t *testing.T) {
+		discOut = filepath.Join(dir, "discovered.yaml")
+		out, err := captureOutput(t, func() error { return discoverCmd.RunE(discoverCmd, nil) })
+		if err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+		if !strings.Contains(out, "Updated "+discOut+" with 1 node record(s)") {
+			t.Errorf("output should name --out:\n%s", out)
+		}
+		unchanged(t)
+		raw, err := os.ReadFile(discOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(raw), "# managed in git") {
+			t.Errorf("--out should keep the comments of --file:\n%s", raw)
+		}
+		doc, err := readInventory(discOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.BMCs) != 1 || len(doc.Nodes) != 1 || doc.Nodes[0].MAC != "aa:bb:cc:00:00:01" {
+			t.Errorf("--out = %+v, want the BMC and its node", doc)
+		}
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		discOut = "-"
+		oldStdout, oldStderr := os.Stdout, os.Stderr
+		outR, outW, _ := os.Pipe()
+		errR, errW, _ := os.Pipe()
+		os.Stdout, os.Stderr = outW, errW
+		err := discoverCmd.RunE(discoverCmd, nil)
+		stdout := os.Stdout
+		outW.Close() //nolint:errcheck
+		errW.Close() //nolint:errcheck
+		os.Stdout, os.Stderr = oldStdout, oldStderr
+		yamlOut, _ := io.ReadAll(outR)
+		report, _ := io.ReadAll(errR)
+		if err != nil {
+			t.Fatalf("%v\n%s", err, report)
+		}
+		if stdout != outW {
+			t.Error("os.Stdout was not restored")
+		}
+		if !strings.Contains(string(report), "Updated stdout with 1 node record(s)") {
+			t.Errorf("the report should go to stderr:\n%s", report)
+		}
+		var doc inventory.FileFormat
+		if err := yaml.Unmarshal(yamlOut, &doc); err != nil {
+			t.Fatalf("stdout is not the inventory: %v\n%s", err, yamlOut)
+		}
+		if len(doc.Nodes) != 1 {
+			t.Errorf("stdout nodes = %+v, want 1", doc.Nodes)
+		}
+		unchanged(t)
+	})
+}
+
+func TestDiscoverExplainNICs(t *testing.T) {
+	var hits atomic.Int32
+	bmc := mockDiscoveryBMC(t, "aa:bb:cc:00:00:01", 0, &hits)
+	file := filepath.Join(t.TempDir(), "inventory.yaml")
+	if err := os.WriteFile(file, []byte(fmt.Sprintf("bmcs:\n  - xname: x9000c1s0b0\n    ip: %s\nnodes: []\n", bmc)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	oldFile, oldOut, oldBMC, oldNode, oldInsecure, oldTimeout, oldProgress := discFile, discOut, discBMCSubnet, discNodeSubnet, discInsecure, discTimeout, discProgress
+	t.Cleanup(func() {
+		discFile, discOut, discBMCSubnet, discNodeSubnet, discInsecure, discTimeout, discProgress = oldFile, oldOut, oldBMC, oldNode, oldInsecure, oldTimeout, oldProgress
+		discExplainNICs = false
+		_ = diag.Configure(false, false, "text")
+	})
+	discFile, discOut, discBMCSubnet, discNodeSubnet, discInsecure, discTimeout, discProgress = file, filepath.Join(t.TempDir(), "out.yaml"), "10.0.0.0/24", "10.0.0.0/24", true, 5*time.Second, "off"
+	discExplainNICs = true
+	discoverCmd.SetContext(context.Background())
+
+	out, err := captureOutput(t, func() error { return discoverCmd.RunE(discoverCmd, nil) })
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	want := `x9000c1s0b0: /redfish/v1/Systems/Node0: NIC eth0: chosen #1: heuristic: UefiDevicePath contains "ipv4" (MAC aa:bb:cc:00:00:01, link -, UefiDevicePath PciRoot(0x0)/MAC(0)/IPv4(0.0.0.0), IPv4 origins -)`
+	if !strings.Contains(out, want+"\n") {
+		t.Errorf("output lacks the NIC decision %q:\n%s", want, out)
+	}
+
+	// With --log-format json the decision is a record with its fields
+	if err := diag.Configure(false, false, "json"); err != nil {
+		t.Fatal(err)
+	}
+	out, err = captureOutput(t, func() error { return discoverCmd.RunE(discoverCmd, nil) })
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	found := false
+	for _, line := range strings.Split(out, "\n") {
+		var rec map[string]any
+		if json.Unmarshal([]byte(line), &rec) != nil || rec["nic"] == nil {
+			continue
+		}
+		found = true
+		if rec["host"] != "x9000c1s0b0" || rec["system"] != "/redfish/v1/Systems/Node0" || rec["nic"] != "eth0" ||
+			rec["mac"] != "aa:bb:cc:00:00:01" || rec["rank"] != float64(1) || rec["reason"] != `heuristic: UefiDevicePath contains "ipv4"` {
+			t.Errorf("NIC record = %v", rec)
+		}
+	}
+	if !found {
+		t.Errorf("no NIC record in the json log:\n%s", out)
+	}
+}
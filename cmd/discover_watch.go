@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/discover"
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/xname"
+)
+
+// watchDiscovery runs discovery passes every --watch interval. The first
+// pass queries every BMC; later ones only the BMCs that failed in the
+// previous pass or have no node in nodes[]. Each pass rewrites --file, or
+// --out, and prints what it added; once --out is written, later passes
+// start from it. An interrupt stops the loop once the pass in flight has
+// been written; a second one stops the pass itself. With --until-complete
+// the loop ends once every BMC has a node.
+func watchDiscovery(ctx context.Context, opts discover.Options) error {
+	listed := opts.Skip
+	var failed map[string]bool
+	in := discFile
+	for pass := 1; ; pass++ {
+		raw, err := os.ReadFile(in)
+		if err != nil {
+			return err
+		}
+		doc, tree, err := inventory.ParseDocument(raw)
+		if err != nil {
+			return err
+		}
+		before := make(map[string]bool, len(doc.Nodes))
+		for _, n := range doc.Nodes {
+			before[strings.ToLower(n.Xname)] = true
+		}
+
+		skip := make(map[string]bool, len(doc.BMCs))
+		pending := 0
+		for _, b := range doc.BMCs {
+			switch {
+			case listed[b.Xname]:
+				skip[b.Xname] = true
+			case pass > 1 && !failed[normalizedXname(b.Xname)] && bmcComplete(doc, b):
+				skip[b.Xname] = true
+			default:
+				pending++
+			}
+		}
+		if pending == 0 && discUntilComplete {
+			fmt.Println("Every BMC has a node entry")
+			return nil
+		}
+
+		var res discover.Result
+		if pending > 0 {
+			opts.Skip = skip
+			var progress *progressPrinter
+			if discProgress == "auto" {
+				progress = newProgressPrinter(os.Stdout, stdoutIsTerminal())
+				opts.Progress = progress.update
+			}
+			passCtx, done := finishPassContext(ctx)
+			res, err = discover.UpdateNodes(passCtx, &doc, opts)
+			done()
+			if progress != nil {
+				progress.finish()
+			}
+			if err != nil {
+				return err
+			}
+			if err := checkMACChanges(res.MACChanges); err != nil {
+				return err
+			}
+			if err := saveDiscovery(&doc, tree, raw, res, pass == 1); err != nil {
+				return err
+			}
+			if discOut != "" {
+				in = discOut
+			}
+			printMACChanges(res.MACChanges)
+		}
+		noteFailures(res.Failed, countCategories(res.Categories))
+		failed = make(map[string]bool, len(res.Failed))
+		for _, x := range res.Failed {
+			failed[normalizedXname(x)] = true
+		}
+		added, missing := 0, 0
+		for _, n := range doc.Nodes {
+			if !before[strings.ToLower(n.Xname)] {
+				added++
+			}
+		}
+		for _, b := range doc.BMCs {
+			if !listed[b.Xname] && !bmcComplete(doc, b) {
+				missing++
+			}
+		}
+		fmt.Printf("Pass %d (%d BMC(s) queried): %d new node(s), %d BMC(s) still unreachable, %d BMC(s) without nodes\n",
+			pass, res.Queried, added, len(res.Failed), missing)
+
+		if ctx.Err() != nil {
+			return errInterrupted
+		}
+		if discUntilComplete && missing == 0 {
+			fmt.Println("Every BMC has a node entry")
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errInterrupted
+		case <-time.After(discWatch):
+		}
+	}
+}
+
+// finishPassContext returns a context for one watch pass that is not
+// cancelled when ctx is, so an interrupt lets the pass finish, but is
+// cancelled by a second interrupt. done must be called when the pass ends.
+func finishPassContext(ctx context.Context) (context.Context, func()) {
+	passCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	ended := make(chan struct{})
+	go func() {
+		select {
+		case <-ended:
+			return
+		case <-ctx.Done():
+		}
+		diag.Warnf("interrupted: finishing the current pass (interrupt again to stop it now)")
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sig)
+		select {
+		case <-sig:
+			cancel()
+		case <-ended:
+		}
+	}()
+	return passCtx, func() {
+		close(ended)
+		cancel()
+	}
+}
+
+// bmcComplete reports whether b has a node in doc, or, for a chassis
+// controller, an entry in controllers[] when they are discovered and
+// otherwise always.
+func bmcComplete(doc inventory.FileFormat, b inventory.Entry) bool {
+	if cc, _, ok := xname.ParseChassisBMC(b.Xname); ok {
+		if !discControllers {
+			return true
+		}
+		for _, c := range doc.Controllers {
+			if c.Xname == cc {
+				return true
+			}
+		}
+		return false
+	}
+	prefix := normalizedXname(b.Xname) + "n"
+	for _, n := range doc.Nodes {
+		if strings.HasPrefix(normalizedXname(n.Xname), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizedXname returns the canonical form of x, or x itself when it is
+// not an xname.
+func normalizedXname(x string) string {
+	if n, err := xname.Normalize(x); err == nil {
+		return n
+	}
+	if cc, _, ok := xname.ParseChassisBMC(x); ok {
+		return cc
+	}
+	return x
+}
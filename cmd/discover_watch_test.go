@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bootstrap/internal/discover"
+)
+
+// mockDiscoveryBMC serves one system with one PXE NIC of the given MAC. The
+// first failFirst requests for /Systems answer 503. hits counts them all.
+func mockDiscoveryBMC(t *testing.T, mac string, failFirst int32, hits *atomic.Int32) string {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch p := r.URL.Path; {
+		case p == "/redfish/v1/Systems":
+			if hits.Add(1) <= failFirst {
+				http.Error(w, "starting", http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case p == "/redfish/v1/Systems/Node0/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0/EthernetInterfaces/eth0"}]}`)
+		case strings.HasSuffix(p, "/eth0"):
+			fmt.Fprintf(w, `{"Id":"eth0","MACAddress":%q,"UefiDevicePath":"PciRoot(0x0)/MAC(0)/IPv4(0.0.0.0)"}`, mac)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestWatchDiscoveryUntilComplete(t *testing.T) {
+	var upHits, lateHits atomic.Int32
+	up := mockDiscoveryBMC(t, "aa:bb:cc:00:00:01", 0, &upHits)
+	late := mockDiscoveryBMC(t, "aa:bb:cc:00:00:02", 1, &lateHits)
+
+	file := filepath.Join(t.TempDir(), "inventory.yaml")
+	inv := fmt.Sprintf("bmcs:\n  - xname: x9000c1s0b0\n    ip: %s\n  - xname: x9000c1s1b0\n    ip: %s\nnodes: []\n", up, late)
+	if err := os.WriteFile(file, []byte(inv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	oldFile, oldWatch, oldUntil, oldProgress := discFile, discWatch, discUntilComplete, discProgress
+	t.Cleanup(func() {
+		discFile, discWatch, discUntilComplete, discProgress = oldFile, oldWatch, oldUntil, oldProgress
+	})
+	discFile, discWatch, discUntilComplete, discProgress = file, 10*time.Millisecond, true, "off"
+
+	opts := discover.Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second}
+	if err := watchDiscovery(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := readInventory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Nodes) != 2 {
+		t.Fatalf("nodes = %+v, want one per BMC", doc.Nodes)
+	}
+	// The second pass only queries the BMC that failed the first
+	if upHits.Load() != 1 || lateHits.Load() != 2 {
+		t.Errorf("Systems requests: %d and %d, want 1 and 2", upHits.Load(), lateHits.Load())
+	}
+	if _, err := os.Stat(file + ".bak"); err != nil {
+		t.Errorf("the first pass should keep a backup: %v", err)
+	}
+}
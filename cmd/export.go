@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/export"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	expFile        string
+	expOut         string
+	expIncludeBMCs bool
+	expLeaseTime   string
+	expGroup       string
+	expNextServer  string
+	expBootFile    string
+	expKernel      string
+	expInitrd      string
+	expParams      string
+	expByChassis   bool
+	expFormat      string
+	expDomain      string
+	expAlias       string
+	expBMCSuffix   string
+	expClass       string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Render the inventory for DHCP and other services",
+}
+
+var exportDnsmasqCmd = &cobra.Command{
+	Use:   "dnsmasq",
+	Short: "Write nodes[] (and optionally bmcs[]) as dnsmasq dhcp-host entries",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if expFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		doc, err := readInventory(expFile)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		skipped, err := export.Dnsmasq(&buf, doc, export.DnsmasqOptions{LeaseTime: expLeaseTime, IncludeBMCs: expIncludeBMCs})
+		if err != nil {
+			return err
+		}
+		if len(skipped) > 0 {
+			diag.Warnf("skipped %d entr(ies) missing a MAC or IP: %s", len(skipped), strings.Join(skipped, ", "))
+		}
+		return writeOutput(expOut, buf.Bytes())
+	},
+}
+
+var exportDhcpdCmd = &cobra.Command{
+	Use:   "dhcpd",
+	Short: "Write nodes[] (and optionally bmcs[]) as ISC dhcpd host stanzas",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if expFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		if expGroup == "" && (expNextServer != "" || expBootFile != "") {
+			return fmt.Errorf("--next-server and --filename require --group")
+		}
+		doc, err := readInventory(expFile)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		skipped, err := export.Dhcpd(&buf, doc, export.DhcpdOptions{
+			IncludeBMCs: expIncludeBMCs,
+			Group:       expGroup,
+			NextServer:  expNextServer,
+			Filename:    expBootFile,
+		})
+		if err != nil {
+			return err
+		}
+		if len(skipped) > 0 {
+			diag.Warnf("skipped %d entr(ies) missing a MAC or IP: %s", len(skipped), strings.Join(skipped, ", "))
+		}
+		return writeOutput(expOut, buf.Bytes())
+	},
+}
+
+var exportBSSCmd = &cobra.Command{
+	Use:   "bss",
+	Short: "Write BSS bootparams JSON for nodes[]",
+	Long: `Write a JSON array of BSS bootparams covering every node in nodes[].
+--params may contain {xname}, {ip}, and {nid} placeholders; when it does, one
+entry is written per node. A node's nid is its 1-based position in nodes[].`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if expFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		if expKernel == "" || expInitrd == "" {
+			return fmt.Errorf("--kernel and --initrd are required")
+		}
+		doc, err := readInventory(expFile)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := export.BSS(&buf, doc, export.BSSOptions{
+			Kernel:    expKernel,
+			Initrd:    expInitrd,
+			Params:    expParams,
+			ByChassis: expByChassis,
+		}); err != nil {
+			return err
+		}
+		return writeOutput(expOut, buf.Bytes())
+	},
+}
+
+var exportHostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "Write bmcs[] and nodes[] as /etc/hosts lines or an Ansible inventory",
+	Long: `Write one "<ip> <xname> [aliases...]" line per entry of bmcs[] and nodes[],
+sorted by IP, for /etc/hosts. bmcs[] is always included.
+
+--alias names nodes from a pattern with {xname}, {nid}, and {nid:N} (zero
+padded to N digits) placeholders, e.g. nid{nid:06}. --bmc-suffix gives every
+BMC the name of its first node plus a suffix, e.g. -mgmt. --domain adds the
+FQDN of every name. An IP used twice is an error.
+
+--format ansible-inventory writes an INI Ansible inventory instead, with a
+group per chassis for nodes and for BMCs.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if expFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		render := export.Hosts
+		switch expFormat {
+		case "hosts":
+		case "ansible-inventory":
+			render = export.AnsibleInventory
+		default:
+			return fmt.Errorf("--format must be hosts or ansible-inventory")
+		}
+		doc, err := readInventory(expFile)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		skipped, err := render(&buf, doc, export.HostsOptions{Domain: expDomain, Alias: expAlias, BMCSuffix: expBMCSuffix})
+		if err != nil {
+			return err
+		}
+		if len(skipped) > 0 {
+			diag.Warnf("skipped %d entr(ies) missing an IP: %s", len(skipped), strings.Join(skipped, ", "))
+		}
+		return writeOutput(expOut, buf.Bytes())
+	},
+}
+
+var exportSLSCmd = &cobra.Command{
+	Use:   "sls",
+	Short: "Write bmcs[] and nodes[] as SLS hardware JSON",
+	Long: `Write the SLS Hardware map, keyed by xname, for bmcs[] and nodes[]: a
+comptype_nodebmc per BMC (a comptype_chassis_bmc per chassis controller) and a
+comptype_node per node, with Parent and Children linking nodes to their BMC and
+BMCs to their slot. ExtraProperties carry each entry's NID, Role, MAC, and IP;
+a node's NID is determined as for export bss.
+
+An xname that is not of the kind its list holds, or is used twice, is an error,
+reported before anything is written.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if expFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		doc, err := readInventory(expFile)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := export.SLS(&buf, doc, export.SLSOptions{Class: expClass}); err != nil {
+			return err
+		}
+		return writeOutput(expOut, buf.Bytes())
+	},
+}
+
+// writeOutput writes data to path, or to stdout when path is empty or "-".
+func writeOutput(path string, data []byte) error {
+	if path == "" || path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportDnsmasqCmd, exportDhcpdCmd, exportBSSCmd, exportHostsCmd, exportSLSCmd)
+	exportCmd.PersistentFlags().StringVarP(&expFile, "file", "f", "", "Inventory file to read bmcs[] and nodes[] from")
+	exportCmd.PersistentFlags().StringVarP(&expOut, "out", "o", "", "Output path (default stdout)")
+	exportCmd.PersistentFlags().BoolVar(&expIncludeBMCs, "include-bmcs", false, "also export bmcs[] entries")
+	exportDnsmasqCmd.Flags().StringVar(&expLeaseTime, "lease-time", "", "lease time appended to each dhcp-host line, e.g. infinite or 12h")
+	exportDhcpdCmd.Flags().StringVar(&expGroup, "group", "", "wrap all host stanzas in a group with this name")
+	exportDhcpdCmd.Flags().StringVar(&expNextServer, "next-server", "", "next-server (TFTP/PXE server) set on the group")
+	exportDhcpdCmd.Flags().StringVar(&expBootFile, "filename", "", "PXE boot filename set on the group")
+	exportBSSCmd.Flags().StringVar(&expKernel, "kernel", "", "kernel URL")
+	exportBSSCmd.Flags().StringVar(&expInitrd, "initrd", "", "initrd URL")
+	exportBSSCmd.Flags().StringVar(&expParams, "params", "", "kernel parameters; may contain {xname}, {ip}, and {nid}")
+	exportBSSCmd.Flags().BoolVar(&expByChassis, "by-chassis", false, "write one bootparams entry per chassis")
+	exportHostsCmd.Flags().StringVar(&expFormat, "format", "hosts", "output format: hosts or ansible-inventory")
+	exportHostsCmd.Flags().StringVar(&expDomain, "domain", "", "domain appended to every name as an FQDN alias")
+	exportHostsCmd.Flags().StringVar(&expAlias, "alias", "", "node alias pattern; may contain {xname}, {nid}, and {nid:N}")
+	exportHostsCmd.Flags().StringVar(&expBMCSuffix, "bmc-suffix", "", "alias every BMC as its first node's name plus this suffix, e.g. -mgmt")
+	exportSLSCmd.Flags().StringVar(&expClass, "class", "River", "SLS class of every component: River, Mountain, or Hill")
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	factsFile      string
+	factsHostsCSV  string
+	factsHostsFile string
+	factsInsecure  bool
+	factsTimeout   time.Duration
+	factsBatchSize int
+	factsOutput    string
+)
+
+// hostFacts is one row of `facts` output.
+type hostFacts struct {
+	Host  string `json:"host"`
+	Xname string `json:"xname,omitempty"`
+	redfish.Facts
+	Error string `json:"error,omitempty"`
+
+	done bool // false for hosts not reached before an interrupt
+}
+
+var factsCmd = &cobra.Command{
+	Use:   "facts",
+	Short: "Show the Redfish version, vendor, product, and BMC firmware of every BMC",
+	Long: `Show what each BMC is: RedfishVersion, Vendor, and Product from the
+service root (/redfish/v1), and FirmwareVersion and Model from its first
+Manager. Fields a BMC does not report are shown as "-". Only a BMC whose
+service root or Manager cannot be read fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if factsOutput != "table" && factsOutput != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(factsFile, factsHostsCSV, factsHostsFile)
+		if err != nil {
+			return err
+		}
+
+		// Each target writes only its own row
+		rows := make([]hostFacts, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+			rows[i] = hostFacts{Host: t.Host, Xname: t.Xname}
+		}
+		forEachTarget(cmd.Context(), targets, factsBatchSize, factsTimeout, func(ctx context.Context, t bmcTarget) {
+			f, err := redfish.GetFacts(ctx, t.Host, user, pass, factsInsecure, factsTimeout)
+			r := &rows[index[t]]
+			r.Facts, r.done = f, true
+			if err != nil {
+				r.Error = err.Error()
+				diag.Warnf("%s: facts: %v", t.label(), err)
+			}
+		})
+
+		var reported []hostFacts
+		ok, failed := 0, 0
+		for _, r := range rows {
+			switch {
+			case !r.done:
+				continue
+			case r.Error != "":
+				failed++
+			default:
+				ok++
+			}
+			reported = append(reported, r)
+		}
+		if factsOutput == "json" {
+			out, err := json.MarshalIndent(reported, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		} else {
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "HOST\tREDFISH\tVENDOR\tPRODUCT\tFIRMWARE\tMODEL\tERROR")
+			for _, r := range reported {
+				host := r.Host
+				if r.Xname != "" {
+					host = r.Xname + " (" + r.Host + ")"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", host, valueOrDash(r.RedfishVersion), valueOrDash(r.Vendor),
+					valueOrDash(r.Product), valueOrDash(r.FirmwareVersion), valueOrDash(r.Model), valueOrDash(r.Error))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Printf("Facts: %d BMC(s) reported, %d failed\n", ok, failed)
+		}
+		if err := checkOutcome(ok, failed, "facts could not be read from %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(factsCmd)
+	factsCmd.Flags().StringVarP(&factsFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	factsCmd.Flags().StringVar(&factsHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	factsCmd.Flags().StringVar(&factsHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	factsCmd.Flags().BoolVar(&factsInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	factsCmd.Flags().DurationVar(&factsTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	factsCmd.Flags().IntVar(&factsBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	factsCmd.Flags().StringVarP(&factsOutput, "output", "o", "table", "output format: table or json")
+}
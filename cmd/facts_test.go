@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFactsShowsMissingFieldsAsDash(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1":
+			fmt.Fprint(w, `{"RedfishVersion":"1.6.0","Vendor":"Dell"}`)
+		case "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/iDRAC.Embedded.1"}]}`)
+		case "/redfish/v1/Managers/iDRAC.Embedded.1":
+			fmt.Fprint(w, `{"FirmwareVersion":"6.10.30.00"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	factsHostsCSV, factsInsecure, factsTimeout, factsOutput = host, true, 5*time.Second, "table"
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	defer func() { factsHostsCSV, factsOutput = "", "table" }()
+	factsCmd.SetContext(context.Background())
+
+	out, err := captureOutput(t, func() error { return factsCmd.RunE(factsCmd, nil) })
+	if err != nil {
+		t.Fatalf("facts: %v\n%s", err, out)
+	}
+	fields := strings.Fields(strings.Split(out, "\n")[1])
+	if want := []string{host, "1.6.0", "Dell", "-", "6.10.30.00", "-", "-"}; strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("row = %v, want %v\n%s", fields, want, out)
+	}
+	if !strings.Contains(out, "Facts: 1 BMC(s) reported, 0 failed") {
+		t.Errorf("summary missing:\n%s", out)
+	}
+
+	factsOutput = "json"
+	out, err = captureOutput(t, func() error { return factsCmd.RunE(factsCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]string
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("json output: %v\n%s", err, out)
+	}
+	if len(rows) != 1 || rows[0]["vendor"] != "Dell" || rows[0]["firmware_version"] != "6.10.30.00" || rows[0]["product"] != "" {
+		t.Errorf("rows = %v", rows)
+	}
+}
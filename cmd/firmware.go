@@ -6,33 +6,53 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"bootstrap/internal/inventory"
+	"bootstrap/internal/diag"
+	"bootstrap/internal/imageserver"
 	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var (
 	fwFile            string
 	fwHostsCSV        string
+	fwHostsFile       string
 	fwType            string
 	fwImageURI        string
 	fwTargets         []string
 	fwProtocol        string
+	fwAutoProtocol    bool
+	fwApplyTime       string
 	fwInsecure        bool
 	fwTimeout         time.Duration
+	fwHostTimeout     time.Duration
+	fwDeadline        string
 	fwDryRun          bool
 	fwForce           bool
 	fwExpectedVersion string
 	fwBatchSize       int
+	fwPush            string
+	fwServeFile       string
+	fwServeAddr       string
+	fwWait            time.Duration
+	fwUseRecorded     bool
+	fwGroupBy         string
+	fwFailedHostsOut  string
+	fwAbortThreshold  int
+	fwXnames          []string
+	fwYes             bool
 )
 
 // defaultTargets returns target list for shorthand types.
@@ -54,13 +74,16 @@ func defaultTargets(t string) ([]string, error) {
 
 var firmwareCmd = &cobra.Command{
 	Use:   "firmware",
-	Short: "Update firmware via Redfish SimpleUpdate",
+	Short: "Update firmware via Redfish SimpleUpdate or multipart push",
 	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
-		if fwFile == "" && fwHostsCSV == "" {
-			return errors.New("at least one of --file or --hosts is required")
+		sources := 0
+		for _, v := range []string{fwImageURI, fwPush, fwServeFile} {
+			if v != "" {
+				sources++
+			}
 		}
-		if fwImageURI == "" {
-			return errors.New("--image-uri is required")
+		if sources != 1 {
+			return errors.New("exactly one of --image-uri, --push, or --serve-file is required")
 		}
 		if len(fwTargets) == 0 {
 			if fwType == "" {
@@ -73,154 +96,590 @@ var firmwareCmd = &cobra.Command{
 			}
 		}
 
-		user := os.Getenv("REDFISH_USER")
-		pass := os.Getenv("REDFISH_PASSWORD")
-		if user == "" || pass == "" {
-			return fmt.Errorf("REDFISH_USER and REDFISH_PASSWORD env vars are required")
+		if fwGroupBy != "none" && fwGroupBy != "blade" && fwGroupBy != "chassis" {
+			return errors.New("--group-by must be blade, chassis, or none")
+		}
+		if fwAbortThreshold < 0 {
+			return errors.New("--abort-threshold must not be negative")
+		}
+		if fwAutoProtocol && fwPush != "" {
+			return errors.New("--auto-protocol does not apply to --push")
+		}
+		if fwAutoProtocol && cmd.Flags().Changed("protocol") {
+			return errors.New("--auto-protocol and --protocol are mutually exclusive")
+		}
+		applyTime, err := redfish.ParseApplyTime(fwApplyTime)
+		if err != nil {
+			return fmt.Errorf("--apply-time: %w", err)
+		}
+		if fwFormat != "" && fwFormat != "json" {
+			return errors.New("--format must be json")
+		}
+
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
 		}
 
 		// Determine hosts to target
-		hosts := []string{}
-		if strings.TrimSpace(fwHostsCSV) != "" {
-			for _, h := range strings.Split(fwHostsCSV, ",") {
-				h = strings.TrimSpace(h)
-				if h != "" {
-					hosts = append(hosts, h)
+		sel, err := selectTargets(fwFile, fwHostsCSV, fwHostsFile, fwXnames)
+		if err != nil {
+			return err
+		}
+		bmcs := sel.targets
+		if !fwDryRun {
+			image := "image=" + fwImageURI
+			switch {
+			case fwPush != "":
+				image = "push=" + fwPush
+			case fwServeFile != "":
+				image = "serve-file=" + fwServeFile
+			}
+			details := []string{image, fmt.Sprintf("targets=%v", fwTargets)}
+			if err := confirmDestructive("update firmware", sel, details, fwYes); err != nil {
+				return err
+			}
+		}
+		hosts := make([]string, 0, len(bmcs))
+		for _, b := range bmcs {
+			hosts = append(hosts, b.Host)
+		}
+		recorded := map[string]map[string]string{} // host -> firmware recorded in --file
+		if fwUseRecorded && fwFile != "" {
+			doc, err := readInventory(fwFile)
+			if err != nil {
+				return err
+			}
+			for _, b := range doc.BMCs {
+				recorded[b.Address()] = b.Firmware
+			}
+		}
+
+		// With --serve-file the image URI points back at a local listener
+		imageURI, protocol := fwImageURI, fwProtocol
+		var srv *imageserver.Server
+		if fwServeFile != "" {
+			addrHost, _, err := net.SplitHostPort(fwServeAddr)
+			if err != nil {
+				return fmt.Errorf("--serve-addr: %w", err)
+			}
+			if ip := net.ParseIP(addrHost); addrHost == "" || (ip != nil && ip.IsUnspecified()) {
+				if addrHost, err = imageserver.LocalIPFor(hosts[0]); err != nil {
+					return fmt.Errorf("determine address BMCs can reach (set --serve-addr): %w", err)
+				}
+			}
+			if fwDryRun {
+				imageURI = fmt.Sprintf("http://%s/%s", net.JoinHostPort(addrHost, "<port>"), filepath.Base(fwServeFile))
+			} else {
+				srv, err = imageserver.Start(fwServeAddr, fwServeFile)
+				if err != nil {
+					return err
+				}
+				imageURI = srv.URL(addrHost)
+				diag.Infof("Serving %s at %s", fwServeFile, imageURI)
+			}
+			protocol = "HTTP"
+		}
+		// The served image is always HTTP, whatever --auto-protocol says
+		autoProtocol := fwAutoProtocol && fwServeFile == ""
+		runCtx, cancelRun, err := withRunDeadline(cmd.Context(), fwDeadline)
+		if err != nil {
+			return err
+		}
+		defer cancelRun()
+		if err := preflightCredentials(runCtx, bmcs, user, pass, fwInsecure, fwTimeout, preflightSample); err != nil {
+			return err
+		}
+		perHost := hostTimeout(fwHostTimeout, fwTimeout)
+		var triggered, skipped, failed, expired, aborted, critical atomic.Int64
+		categories := map[string]int{} // failures by redfish.Categorize category
+		// outcomes[i] is set for hosts[i] when it needs a retry
+		outcomes := make([]string, len(hosts))
+		// results[i] is the --format json record of hosts[i]; each host
+		// writes only its own
+		results := make([]firmwareResult, len(hosts))
+		for i, h := range hosts {
+			results[i].Host = h
+		}
+		// In json mode the records replace each host's progress lines
+		progress := func(hl *diag.HostLog, format string, args ...any) {
+			if fwFormat != "json" {
+				hl.Infof(format, args...)
+			}
+		}
+		// With --abort-threshold, no host is started once that many failed
+		abort := func() bool {
+			return fwAbortThreshold > 0 && failed.Load() >= int64(fwAbortThreshold)
+		}
+
+		// A pushed image is opened once and streamed to every host
+		var img *redfish.FirmwareImage
+		if fwPush != "" {
+			var err error
+			img, err = redfish.OpenFirmwareImage(fwPush)
+			if err != nil {
+				return fmt.Errorf("open firmware image: %w", err)
+			}
+			defer img.Close() // nolint:errcheck
+		}
+		update := func(ctx context.Context, i int, host string) error {
+			if fwUseRecorded && fwExpectedVersion != "" && !fwForce && atVersion(recorded[host], fwTargets, fwExpectedVersion) {
+				return fmt.Errorf("%w: recorded firmware already at expected version %s", redfish.ErrAlreadyAtVersion, fwExpectedVersion)
+			}
+			if !fwForce {
+				if err := checkManagerHealth(ctx, host, user, pass); err != nil {
+					return err
+				}
+			}
+			if img != nil && applyTime == "" {
+				return redfish.MultipartUpdate(ctx, host, user, pass, fwInsecure, fwTimeout, img, fwTargets, "", fwExpectedVersion, fwForce)
+			}
+			caps, err := redfish.GetUpdateCapabilities(ctx, host, user, pass, fwInsecure, fwTimeout)
+			if err != nil {
+				return fmt.Errorf("read UpdateService: %w", err)
+			}
+			if img != nil {
+				hostApplyTime, err := redfish.SelectApplyTime(caps.MultipartApplyTimes, applyTime)
+				if err != nil {
+					return err
 				}
+				results[i].ApplyTime = applyTime
+				return redfish.MultipartUpdate(ctx, host, user, pass, fwInsecure, fwTimeout, img, fwTargets, hostApplyTime, fwExpectedVersion, fwForce)
 			}
-		} else {
-			// Load from inventory file
-			raw, err := os.ReadFile(fwFile)
+			hostProtocol, err := redfish.SelectTransferProtocol(caps.TransferProtocols, protocol, imageURI, autoProtocol)
 			if err != nil {
 				return err
 			}
-			var doc inventory.FileFormat
-			if err := yaml.Unmarshal(raw, &doc); err != nil {
+			hostApplyTime, err := redfish.SelectApplyTime(caps.SimpleUpdateApplyTimes, applyTime)
+			if err != nil {
 				return err
 			}
-			if len(doc.BMCs) == 0 {
-				return fmt.Errorf("input must contain non-empty bmcs[]")
+			results[i].Protocol, results[i].ApplyTime = hostProtocol, applyTime
+			return redfish.SimpleUpdate(ctx, host, user, pass, fwInsecure, fwTimeout, imageURI, fwTargets, hostProtocol, hostApplyTime, fwExpectedVersion, fwForce)
+		}
+		// The dry run reads each UpdateService so the plan shows the
+		// protocols and apply times every BMC allows
+		dryRunAction := func(ctx context.Context, i int, host string) string {
+			results[i].Outcome, results[i].ApplyTime = "dry-run", applyTime
+			at := " apply-time=default"
+			if applyTime != "" {
+				at = " apply-time=" + applyTime
 			}
-			for _, b := range doc.BMCs {
-				host := b.IP
-				if host == "" {
-					host = b.Xname
+			if !fwForce {
+				if err := checkManagerHealth(ctx, host, user, pass); err != nil {
+					results[i].Error = err.Error()
+					return fmt.Sprintf("[dry-run] would not update %s: %v", host, err)
 				}
-				hosts = append(hosts, host)
 			}
+			if img != nil && applyTime == "" {
+				return fmt.Sprintf("[dry-run] would push %s (%d bytes) to %s with targets=%v%s", img.Name(), img.Size(), host, fwTargets, at)
+			}
+			caps, err := redfish.GetUpdateCapabilities(ctx, host, user, pass, fwInsecure, fwTimeout)
+			if err != nil {
+				results[i].Error = err.Error()
+				if img != nil {
+					return fmt.Sprintf("[dry-run] would push %s (%d bytes) to %s with targets=%v%s (allowed: unknown: %v)", img.Name(), img.Size(), host, fwTargets, at, err)
+				}
+				results[i].Protocol = protocol
+				return fmt.Sprintf("[dry-run] would POST SimpleUpdate on %s with image=%s targets=%v protocol=%s (allowed: unknown: %v)%s",
+					host, imageURI, fwTargets, protocol, err, at)
+			}
+			if img != nil {
+				if _, err := redfish.SelectApplyTime(caps.MultipartApplyTimes, applyTime); err != nil {
+					results[i].Error = err.Error()
+					return fmt.Sprintf("[dry-run] would not update %s: %v", host, err)
+				}
+				return fmt.Sprintf("[dry-run] would push %s (%d bytes) to %s with targets=%v%s (allowed: %s)",
+					img.Name(), img.Size(), host, fwTargets, at, allowedNote(caps.MultipartApplyTimes))
+			}
+			hostProtocol, err := redfish.SelectTransferProtocol(caps.TransferProtocols, protocol, imageURI, autoProtocol)
+			if err == nil {
+				_, err = redfish.SelectApplyTime(caps.SimpleUpdateApplyTimes, applyTime)
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+				return fmt.Sprintf("[dry-run] would not update %s: %v", host, err)
+			}
+			results[i].Protocol = hostProtocol
+			if applyTime != "" {
+				at += " (allowed: " + allowedNote(caps.SimpleUpdateApplyTimes) + ")"
+			}
+			return fmt.Sprintf("[dry-run] would POST SimpleUpdate on %s with image=%s targets=%v protocol=%s (allowed: %s)%s",
+				host, imageURI, fwTargets, hostProtocol, allowedNote(caps.TransferProtocols), at)
 		}
 
-		// Apply firmware update to each host
-		if fwBatchSize <= 1 {
-			// Serial execution
-			for _, host := range hosts {
-				ctx := cmd.Context()
-				var cancel context.CancelFunc
-				if fwTimeout > 0 {
-					ctx, cancel = context.WithTimeout(ctx, fwTimeout)
+		index := make(map[bmcTarget]int, len(bmcs))
+		for i, t := range bmcs {
+			index[t] = i
+		}
+		locks := groupLocks(bmcs, fwGroupBy)
+		// started[i] is set when hosts[i] got past its slot and group lock;
+		// the others were left out by --deadline or an interrupt
+		started := make([]bool, len(hosts))
+		var mu sync.Mutex // protects categories and the dry-run lines
+		// updateHost updates one BMC once forEachTarget has given it a slot.
+		// Its lines are prefixed with its xname and printed together once it
+		// is done.
+		updateHost := func(ctx context.Context, t bmcTarget) {
+			i, host := index[t], t.Host
+			hl := diag.HostLogFrom(ctx)
+			// With --group-by, one host per blade or chassis at a time
+			if lock := locks[i]; lock != nil {
+				select {
+				case lock <- struct{}{}:
+				case <-ctx.Done():
+					return
 				}
-				if fwDryRun {
-					dryRunMsg := fmt.Sprintf("[dry-run] would POST SimpleUpdate on %s with image=%s targets=%v protocol=%s",
-						host, fwImageURI, fwTargets, fwProtocol)
-					if fwExpectedVersion != "" {
-						dryRunMsg += fmt.Sprintf(" expected-version=%s", fwExpectedVersion)
-						if fwForce {
-							dryRunMsg += " (force=true)"
-						}
-					}
-					fmt.Println(dryRunMsg)
-					if cancel != nil {
-						cancel()
+				defer func() { <-lock }()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			started[i] = true
+			if abort() {
+				aborted.Add(1)
+				outcomes[i] = outcomeAborted
+				return
+			}
+			// The host's budget starts now that it may run, not when queued
+			if perHost > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, perHost)
+				defer cancel()
+			}
+
+			if fwDryRun {
+				dryRunMsg := dryRunAction(ctx, i, host)
+				if fwExpectedVersion != "" {
+					dryRunMsg += fmt.Sprintf(" expected-version=%s", fwExpectedVersion)
+					if fwForce {
+						dryRunMsg += " (force=true)"
 					}
-					continue
 				}
-				err := redfish.SimpleUpdate(ctx, host, user, pass, fwInsecure, fwTimeout, fwImageURI, fwTargets, fwProtocol, fwExpectedVersion, fwForce)
-				if cancel != nil {
-					cancel()
+				if fwFormat != "json" {
+					mu.Lock()
+					fmt.Println(dryRunMsg)
+					mu.Unlock()
 				}
-				if err != nil {
-					// Check if this is a "skipping update" message
-					if strings.Contains(err.Error(), "skipping update") {
-						fmt.Printf("%s: %v\n", host, err)
-					} else {
-						fmt.Fprintf(os.Stderr, "WARN: %s: firmware update failed: %v\n", host, err)
-					}
-				} else {
-					fmt.Printf("Triggered firmware update on %s\n", host)
+				return
+			}
+
+			err := update(ctx, i, host)
+			switch {
+			case err == nil:
+				triggered.Add(1)
+				results[i].Outcome = "triggered"
+				progress(hl, "Triggered firmware update")
+			case errors.Is(err, errManagerCritical):
+				critical.Add(1)
+				results[i].Outcome, results[i].Error = outcomeCritical, err.Error()
+				outcomes[i] = outcomeCritical
+				hl.Warnf("%v", err)
+			case errors.Is(err, redfish.ErrAlreadyAtVersion):
+				skipped.Add(1)
+				results[i].Outcome = "skipped"
+				progress(hl, "%v", err)
+			default:
+				failed.Add(1)
+				results[i].Outcome, results[i].Error = outcomeFailed, err.Error()
+				outcomes[i] = outcomeFailed
+				category := redfish.Categorize(err)
+				mu.Lock()
+				categories[category]++
+				mu.Unlock()
+				hl.Failf(category, "firmware update failed: %v", err)
+			}
+		}
+		forEachTarget(runCtx, interleaveGroups(bmcs, locks), fwBatchSize, 0, updateHost)
+		if deadlineHit(cmd.Context(), runCtx) {
+			for i, t := range bmcs {
+				if started[i] {
+					continue
 				}
+				expired.Add(1)
+				outcomes[i] = outcomeExpired
+				hl := diag.NewHostLog(t.label())
+				hl.Warnf("skipped (deadline)")
+				hl.Flush()
 			}
-		} else {
-			// Parallel execution with semaphore to limit concurrency
-			var wg sync.WaitGroup
-			sem := make(chan struct{}, fwBatchSize)
-			var mu sync.Mutex // Protect stdout/stderr writes
-
-			for _, host := range hosts {
-				wg.Add(1)
-				go func(h string) {
-					defer wg.Done()
-					sem <- struct{}{}        // Acquire semaphore
-					defer func() { <-sem }() // Release semaphore
-
-					ctx := cmd.Context()
-					var cancel context.CancelFunc
-					if fwTimeout > 0 {
-						ctx, cancel = context.WithTimeout(ctx, fwTimeout)
-					}
-					if cancel != nil {
-						defer cancel()
-					}
+		}
+		if srv != nil {
+			serveUntilDownloaded(cmd.Context(), srv, int(triggered.Load()))
+		}
+		if fwFormat == "json" {
+			if err := printFirmwareResults(results, outcomes); err != nil {
+				return err
+			}
+		}
+		if fwDryRun {
+			return checkInterrupted(cmd.Context())
+		}
+		// A host skipped because it is already at the expected version succeeded
+		ok, bad, notAttempted := int(triggered.Load()+skipped.Load()), int(failed.Load()), int(aborted.Load())
+		unhealthy := int(critical.Load())
+		abortNote := ""
+		if notAttempted > 0 {
+			abortNote = fmt.Sprintf(", %d not attempted (aborted after %d failures)", notAttempted, fwAbortThreshold)
+		}
+		if unhealthy > 0 {
+			abortNote = fmt.Sprintf(", %d not updated (manager Critical)", unhealthy) + abortNote
+		}
+		setMetric("bmcs_total", float64(len(hosts)))
+		setMetric("bmcs_failed", float64(bad))
+		setMetric("firmware_updates_triggered", float64(triggered.Load()))
+		setMetric("firmware_updates_failed", float64(bad))
+		var failedLabels []string
+		for i, t := range bmcs {
+			if outcomes[i] != "" {
+				failedLabels = append(failedLabels, t.label())
+			}
+		}
+		noteFailures(failedLabels, categories)
+		if fwFormat != "json" {
+			fmt.Printf("Firmware update: %d triggered, %d skipped, %d failed%s%s\n", triggered.Load(), skipped.Load(), bad, abortNote, deadlineNote(int(expired.Load())))
+			printCategories(categories)
+		}
+		if fwFailedHostsOut != "" {
+			if err := writeFailedHosts(fwFailedHostsOut, bmcs, outcomes); err != nil {
+				return fmt.Errorf("write --failed-hosts-out: %w", err)
+			}
+		}
+		if notAttempted > 0 {
+			// Hosts left out by the abort count against the run like failures
+			return checkOutcome(ok, bad+unhealthy+notAttempted, "firmware update failed on %d host(s); %d not attempted after --abort-threshold %d", bad+unhealthy, notAttempted, fwAbortThreshold)
+		}
+		if unhealthy > 0 {
+			// So do hosts left alone for their health: they still need the update
+			return checkOutcome(ok, bad+unhealthy, "firmware update failed on %d host(s); %d not updated with a Critical manager (--force to update them)", bad, unhealthy)
+		}
+		if err := checkOutcome(ok, bad, "firmware update failed on %d host(s)", bad); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
 
-					if fwDryRun {
-						dryRunMsg := fmt.Sprintf("[dry-run] would POST SimpleUpdate on %s with image=%s targets=%v protocol=%s",
-							h, fwImageURI, fwTargets, fwProtocol)
-						if fwExpectedVersion != "" {
-							dryRunMsg += fmt.Sprintf(" expected-version=%s", fwExpectedVersion)
-							if fwForce {
-								dryRunMsg += " (force=true)"
-							}
-						}
-						mu.Lock()
-						fmt.Println(dryRunMsg)
-						mu.Unlock()
-						return
-					}
+// firmwareResult is one host's record in `firmware --format json` output.
+// ApplyTime is the --apply-time sent; without one the BMC's default
+// applies.
+type firmwareResult struct {
+	Host      string `json:"host"`
+	Outcome   string `json:"outcome"`
+	Protocol  string `json:"protocol,omitempty"`
+	ApplyTime string `json:"apply_time,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
 
-					err := redfish.SimpleUpdate(ctx, h, user, pass, fwInsecure, fwTimeout, fwImageURI, fwTargets, fwProtocol, fwExpectedVersion, fwForce)
+// printFirmwareResults prints results as JSON, taking the outcome of hosts
+// that were aborted or missed --deadline from outcomes. Hosts never reached
+// before an interrupt are left out.
+func printFirmwareResults(results []firmwareResult, outcomes []string) error {
+	var reported []firmwareResult
+	for i, r := range results {
+		if r.Outcome == "" {
+			r.Outcome = outcomes[i]
+		}
+		if r.Outcome != "" {
+			reported = append(reported, r)
+		}
+	}
+	out, err := json.MarshalIndent(reported, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
 
-					mu.Lock()
-					if err != nil {
-						// Check if this is a "skipping update" message
-						if strings.Contains(err.Error(), "skipping update") {
-							fmt.Printf("%s: %v\n", h, err)
-						} else {
-							fmt.Fprintf(os.Stderr, "WARN: %s: firmware update failed: %v\n", h, err)
-						}
-					} else {
-						fmt.Printf("Triggered firmware update on %s\n", h)
-					}
-					mu.Unlock()
-				}(host)
+// allowedNote lists the values a BMC advertises, or says it advertises
+// none.
+func allowedNote(values []string) string {
+	if len(values) == 0 {
+		return "not advertised"
+	}
+	return strings.Join(values, ", ")
+}
+
+// Outcomes recorded for hosts that --failed-hosts-out lists for a retry.
+const (
+	outcomeFailed  = "failed"
+	outcomeAborted = "not attempted (aborted)"
+	outcomeExpired = "skipped (deadline)"
+	// outcomeCritical is a host not flashed because its manager reported
+	// Critical health; it needs attention before a retry
+	outcomeCritical = "skipped (manager Critical)"
+)
+
+// writeFailedHosts writes the targets with an outcome to path in the
+// --hosts-file format, grouped under a comment naming the outcome, so the
+// file can be fed back to --hosts-file to retry them. An empty file means
+// nothing needs a retry.
+func writeFailedHosts(path string, targets []bmcTarget, outcomes []string) error {
+	var b strings.Builder
+	for _, outcome := range []string{outcomeFailed, outcomeCritical, outcomeAborted, outcomeExpired} {
+		header := false
+		for i, t := range targets {
+			if outcomes[i] != outcome {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(&b, "# %s\n", outcome)
+				header = true
+			}
+			if t.Xname != "" {
+				fmt.Fprintf(&b, "%s,%s\n", t.Host, t.Xname)
+			} else {
+				fmt.Fprintln(&b, t.Host)
 			}
-			wg.Wait()
 		}
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// errManagerCritical is returned for a host whose manager reports Critical
+// health: flashing a degraded BMC is how it gets bricked.
+var errManagerCritical = errors.New("manager health is Critical")
+
+// checkManagerHealth returns errManagerCritical when the manager of host
+// reports Critical health. A status that cannot be read does not stop the
+// update; the update itself then reports what is wrong.
+func checkManagerHealth(ctx context.Context, host, user, pass string) error {
+	st, err := redfish.GetManagerStatus(ctx, host, user, pass, fwInsecure, fwTimeout)
+	if err != nil {
+		diag.Logf("%s: manager status: %v; not checked", host, err)
 		return nil
-	},
+	}
+	if st.Critical() {
+		return fmt.Errorf("%w (status %s); not updating it (pass --force to update anyway)", errManagerCritical, st)
+	}
+	return nil
+}
+
+// groupLocks returns, for each target, the lock shared by the targets in
+// the same blade or chassis (by is "blade" or "chassis"), or nil when the
+// target is not grouped: by is "none" or it has no BMC xname.
+func groupLocks(targets []bmcTarget, by string) []chan struct{} {
+	locks := make([]chan struct{}, len(targets))
+	if by == "none" {
+		return locks
+	}
+	kind := xname.KindSlot
+	if by == "chassis" {
+		kind = xname.KindChassis
+	}
+	byGroup := map[string]chan struct{}{}
+	for i, t := range targets {
+		x, err := xname.Parse(t.Xname)
+		group, ok := x.Ancestor(kind)
+		if err != nil || !ok {
+			diag.Warnf("%s: no xname to group by %s; updating it independently", t.label(), by)
+			continue
+		}
+		key := group.String()
+		if byGroup[key] == nil {
+			byGroup[key] = make(chan struct{}, 1)
+		}
+		locks[i] = byGroup[key]
+	}
+	return locks
+}
+
+// interleaveGroups orders targets so that those sharing a lock (see
+// groupLocks) are spread out, taking one from each group in turn. The hosts
+// holding forEachTarget's slots then mostly belong to different groups
+// instead of queuing for the same one. Without groups the order is kept.
+func interleaveGroups(targets []bmcTarget, locks []chan struct{}) []bmcTarget {
+	var order []chan struct{} // groups, in order of first appearance
+	members := map[chan struct{}][]bmcTarget{}
+	for i, t := range targets {
+		lock := locks[i]
+		if lock == nil {
+			// An ungrouped target is a group of its own
+			lock = make(chan struct{})
+		}
+		if _, ok := members[lock]; !ok {
+			order = append(order, lock)
+		}
+		members[lock] = append(members[lock], t)
+	}
+	if len(order) == len(targets) {
+		return targets
+	}
+	out := make([]bmcTarget, 0, len(targets))
+	for len(out) < len(targets) {
+		for _, lock := range order {
+			if m := members[lock]; len(m) > 0 {
+				out = append(out, m[0])
+				members[lock] = m[1:]
+			}
+		}
+	}
+	return out
+}
+
+// atVersion reports whether the recorded firmware of every target equals
+// version.
+func atVersion(recorded map[string]string, targets []string, version string) bool {
+	if len(targets) == 0 {
+		return false
+	}
+	for _, t := range targets {
+		if recorded[t] != version {
+			return false
+		}
+	}
+	return true
+}
+
+// serveUntilDownloaded keeps srv up until want hosts have fetched the image,
+// --wait elapses, or ctx is cancelled, then shuts it down.
+func serveUntilDownloaded(ctx context.Context, srv *imageserver.Server, want int) {
+	if ctx.Err() == nil && want > 0 {
+		wctx, cancel := context.WithTimeout(ctx, fwWait)
+		if err := srv.WaitForDownloads(wctx, want); err != nil && ctx.Err() == nil {
+			diag.Warnf("only %d of %d host(s) downloaded the image within %s", srv.Downloads(), want, fwWait)
+		}
+		cancel()
+	}
+	sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Close(sctx); err != nil {
+		diag.Warnf("stop image server: %v", err)
+	}
+	fmt.Printf("Image served to %d host(s)\n", srv.Downloads())
 }
 
 func init() {
 	rootCmd.AddCommand(firmwareCmd)
 	// Make flags persistent so subcommands (like `firmware status`) inherit them
 	firmwareCmd.PersistentFlags().StringVarP(&fwFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
-	firmwareCmd.PersistentFlags().StringVar(&fwHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file)")
+	firmwareCmd.PersistentFlags().StringVar(&fwHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	firmwareCmd.PersistentFlags().StringVar(&fwHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
 	firmwareCmd.PersistentFlags().StringVar(&fwType, "type", "", "Firmware type preset: cc|nc|bios (ignored if --targets provided)")
-	firmwareCmd.PersistentFlags().StringVar(&fwImageURI, "image-uri", "", "Firmware image URI accessible by BMC (required)")
+	firmwareCmd.PersistentFlags().StringVar(&fwImageURI, "image-uri", "", "Firmware image URI accessible by BMC (required unless --push)")
 	firmwareCmd.PersistentFlags().StringSliceVar(&fwTargets, "targets", nil, "Explicit FirmwareInventory target URIs (advanced)")
-	firmwareCmd.PersistentFlags().StringVar(&fwProtocol, "protocol", "HTTP", "TransferProtocol for SimpleUpdate (HTTP/HTTPS)")
-	firmwareCmd.PersistentFlags().BoolVar(&fwInsecure, "insecure", true, "allow insecure TLS to BMCs")
-	firmwareCmd.PersistentFlags().DurationVar(&fwTimeout, "timeout", 5*time.Minute, "per-BMC firmware request timeout")
-	firmwareCmd.PersistentFlags().BoolVar(&fwDryRun, "dry-run", false, "plan only: print SimpleUpdate actions without posting")
-	firmwareCmd.PersistentFlags().BoolVar(&fwForce, "force", false, "force update even if already at expected version")
+	firmwareCmd.PersistentFlags().StringVar(&fwProtocol, "protocol", "HTTP", "TransferProtocol for SimpleUpdate (HTTP/HTTPS); must be one the BMC allows")
+	firmwareCmd.Flags().StringVar(&fwApplyTime, "apply-time", "", "when the BMC applies the image: Immediate, OnReset (staged until the next reset), or AtMaintenanceWindowStart; must be one the BMC supports (default: the BMC's own)")
+	firmwareCmd.Flags().StringVar(&fwFormat, "format", "", "output format: json (one record per host, with the protocol and apply time used)")
+	firmwareCmd.Flags().BoolVar(&fwAutoProtocol, "auto-protocol", false, "use the TransferProtocol matching the --image-uri scheme, if the BMC allows it, instead of --protocol")
+	firmwareCmd.PersistentFlags().BoolVar(&fwInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	firmwareCmd.PersistentFlags().DurationVar(&fwTimeout, "timeout", 5*time.Minute, "per-request timeout for BMC calls")
+	firmwareCmd.PersistentFlags().DurationVar(&fwHostTimeout, "host-timeout", 0, "bound on all of one host's work (default: --timeout)")
+	firmwareCmd.PersistentFlags().StringVar(&fwDeadline, "deadline", "", "bound on the whole run, as a duration (2h) or RFC 3339 time; hosts not started by then are skipped")
+	firmwareCmd.PersistentFlags().BoolVar(&fwDryRun, "dry-run", false, "plan only: print SimpleUpdate actions, with the protocols each BMC allows, without posting")
+	firmwareCmd.PersistentFlags().BoolVar(&fwForce, "force", false, "force update even if already at expected version or the manager reports Critical health")
 	firmwareCmd.PersistentFlags().StringVar(&fwExpectedVersion, "expected-version", "", "expected version string; skip update if already at this version (unless --force)")
+	firmwareCmd.Flags().StringVar(&fwServeFile, "serve-file", "", "serve this local image over HTTP and use its URL as --image-uri")
+	firmwareCmd.Flags().StringVar(&fwServeAddr, "serve-addr", ":0", "listen address for --serve-file; an unspecified host uses the local address that routes to the first BMC")
+	addNotifyFlags(firmwareCmd)
+	addPreflightFlag(firmwareCmd.Flags())
+	firmwareCmd.Flags().DurationVar(&fwWait, "wait", 10*time.Minute, "with --serve-file, how long to keep serving until every triggered host has downloaded the image")
+	firmwareCmd.Flags().BoolVar(&fwUseRecorded, "use-recorded", false, "skip hosts whose firmware recorded in --file (see `firmware status --record`) already matches --expected-version, without querying them")
+	firmwareCmd.Flags().StringVar(&fwGroupBy, "group-by", "none", "with --batch-size > 1, update at most one BMC per blade or chassis at a time: blade, chassis, or none")
+	firmwareCmd.Flags().StringVar(&fwFailedHostsOut, "failed-hosts-out", "", "write the hosts that failed, were not attempted, or missed --deadline to this file, in --hosts-file format")
+	firmwareCmd.Flags().IntVar(&fwAbortThreshold, "abort-threshold", 0, "stop starting new hosts once this many have failed; running updates finish (0 = never)")
+	firmwareCmd.Flags().StringSliceVar(&fwXnames, "xname", nil, "only the BMCs within these cabinet, chassis, slot, BMC, or node xnames (comma-separated or repeated)")
+	firmwareCmd.Flags().BoolVarP(&fwYes, "yes", "y", false, "skip the confirmation prompt")
+	firmwareCmd.Flags().StringVar(&fwPush, "push", "", "local firmware image to upload to each BMC's MultipartHttpPushUri instead of SimpleUpdate")
 	firmwareCmd.PersistentFlags().IntVar(&fwBatchSize, "batch-size", 0, "number of concurrent firmware updates (0 or 1 = serial, >1 = parallel)")
 }
@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"bootstrap/internal/compliance"
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fwCompManifest    string
+	fwCompOutput      string
+	fwCompRemediation string
+)
+
+// complianceHost is one BMC of a compliance report.
+type complianceHost struct {
+	BMC    string   `json:"bmc"`
+	Xname  string   `json:"xname,omitempty"`
+	Kind   string   `json:"kind,omitempty"`
+	Models []string `json:"models,omitempty"`
+	compliance.Result
+	Error string `json:"error,omitempty"`
+}
+
+var firmwareComplianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Check the firmware of the selected BMCs against a manifest of blessed versions",
+	Long: `Read the FirmwareInventory and the Manager and System models of every
+selected BMC and check each component against the rules of --manifest, a
+YAML file of blessed versions per component type (nC, cC, BIOS) and
+hardware model:
+
+  rules:
+    - type: nC
+      model: EX425
+      version: nc.1.9.8
+    - type: nC            # every other model
+      version: nc.1.9.0
+    - type: cC
+      version: cc.1.9.8
+    - name: bios-ex425
+      type: BIOS
+      model: EX4*
+      version: ex425.bios-1.2.0
+
+Each BMC is compliant, outdated, or unknown (no rule applies, a component a
+rule checks is not reported, or the BMC could not be read). With
+--remediation-dir, the outdated BMCs of each rule are written to
+<dir>/<rule>.hosts for firmware --hosts-file. BMCs that are not compliant
+fail the command.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if fwCompManifest == "" {
+			return errors.New("--manifest is required")
+		}
+		if fwCompOutput != "table" && fwCompOutput != "json" {
+			return errors.New("--output must be table or json")
+		}
+		raw, err := os.ReadFile(fwCompManifest)
+		if err != nil {
+			return err
+		}
+		manifest, err := compliance.ParseManifest(raw)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", fwCompManifest, err)
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(fwFile, fwHostsCSV, fwHostsFile)
+		if err != nil {
+			return err
+		}
+
+		hosts := make([]complianceHost, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+			hosts[i] = complianceHost{BMC: t.Host, Xname: t.Xname, Result: compliance.Result{Result: compliance.Unknown}, Error: "not read"}
+		}
+		var mu sync.Mutex
+		forEachTarget(cmd.Context(), targets, fwBatchSize, fwTimeout, func(ctx context.Context, t bmcTarget) {
+			h := complianceHost{BMC: t.Host, Xname: t.Xname}
+			components, err := redfish.ListFirmwareInventory(ctx, t.Host, user, pass, fwInsecure, fwTimeout)
+			var models redfish.Models
+			if err == nil {
+				models, err = redfish.GetModels(ctx, t.Host, user, pass, fwInsecure, fwTimeout)
+			}
+			if err != nil {
+				diag.HostLogFrom(ctx).Warnf("firmware compliance: %v", err)
+				h.Result, h.Error = compliance.Result{Result: compliance.Unknown}, err.Error()
+			} else {
+				in := compliance.Host{Kind: compliance.KindOf(t.Xname, len(models.Systems) > 0)}
+				for _, m := range append([]string{models.Manager}, models.Systems...) {
+					if m != "" && !slices.ContainsFunc(in.Models, func(s string) bool { return strings.EqualFold(s, m) }) {
+						in.Models = append(in.Models, m)
+					}
+				}
+				for _, c := range components {
+					in.Components = append(in.Components, compliance.Component{ID: c.ID, Version: c.Version})
+				}
+				h.Kind, h.Models, h.Result = in.Kind, in.Models, manifest.Evaluate(in)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			hosts[index[t]] = h
+		})
+
+		var summary compliance.Summary
+		for _, h := range hosts {
+			summary.Add(h.Result.Result)
+		}
+		if fwCompOutput == "json" {
+			if err := printComplianceJSON(hosts, summary); err != nil {
+				return err
+			}
+		} else if err := printComplianceTable(hosts, summary); err != nil {
+			return err
+		}
+		if fwCompRemediation != "" {
+			if err := writeRemediation(fwCompRemediation, manifest, hosts); err != nil {
+				return fmt.Errorf("write --remediation-dir: %w", err)
+			}
+		}
+		if err := checkOutcome(summary.Compliant, summary.Outdated+summary.Unknown, "%d BMC(s) outdated and %d unknown against %s",
+			summary.Outdated, summary.Unknown, fwCompManifest); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// printComplianceTable prints one line per check, or per BMC without any,
+// and the fleet percentages.
+func printComplianceTable(hosts []complianceHost, summary compliance.Summary) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tRESULT\tRULE\tCOMPONENT\tEXPECTED\tACTUAL")
+	for _, h := range hosts {
+		label := bmcTarget{Host: h.BMC, Xname: h.Xname}.label()
+		switch {
+		case h.Error != "":
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t(not read: %s)\n", label, h.Result.Result, h.Error)
+		case len(h.Checks) == 0:
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t(no rule for %s %s)\n", label, h.Result.Result, h.Kind, valueOrDash(strings.Join(h.Models, "/")))
+		}
+		for _, c := range h.Checks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", label, h.Result.Result, c.Rule, valueOrDash(c.Component), c.Expected, valueOrDash(c.Actual))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("Compliance: %d BMC(s): %.1f%% compliant (%d), %.1f%% outdated (%d), %.1f%% unknown (%d)\n",
+		summary.Hosts, summary.Percent(summary.Compliant), summary.Compliant, summary.Percent(summary.Outdated), summary.Outdated,
+		summary.Percent(summary.Unknown), summary.Unknown)
+	return nil
+}
+
+// complianceSummary is the fleet summary of the JSON report.
+type complianceSummary struct {
+	compliance.Summary
+	CompliantPercent float64 `json:"compliant_percent"`
+	OutdatedPercent  float64 `json:"outdated_percent"`
+	UnknownPercent   float64 `json:"unknown_percent"`
+}
+
+// printComplianceJSON prints the report for dashboards: every BMC with its
+// checks, and the fleet counts and percentages.
+func printComplianceJSON(hosts []complianceHost, summary compliance.Summary) error {
+	out, err := json.MarshalIndent(struct {
+		Hosts   []complianceHost  `json:"hosts"`
+		Summary complianceSummary `json:"summary"`
+	}{hosts, complianceSummary{summary, summary.Percent(summary.Compliant), summary.Percent(summary.Outdated), summary.Percent(summary.Unknown)}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// unsafeFileChars are replaced in rule names used as file names.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// writeRemediation writes <dir>/<rule>.hosts for every rule of manifest,
+// listing in the --hosts-file format the BMCs with a component the rule
+// found outdated. A rule with none gets a file with only its header, so a
+// list from an earlier run is not left behind.
+func writeRemediation(dir string, manifest compliance.Manifest, hosts []complianceHost) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, r := range manifest.Rules {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# rule %s: %s components %s to version %s; update with firmware --type %s --hosts-file <this file>\n",
+			r.Name, r.Type, r.Component, r.Version, strings.ToLower(r.Type))
+		n := 0
+		for _, h := range hosts {
+			outdated := false
+			for _, c := range h.Checks {
+				outdated = outdated || c.Rule == r.Name && c.Result == compliance.Outdated
+			}
+			if !outdated {
+				continue
+			}
+			n++
+			if h.Xname != "" {
+				fmt.Fprintf(&b, "%s,%s\n", h.BMC, h.Xname)
+			} else {
+				fmt.Fprintln(&b, h.BMC)
+			}
+		}
+		path := filepath.Join(dir, unsafeFileChars.ReplaceAllString(r.Name, "_")+".hosts")
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			return err
+		}
+		if fwCompOutput != "json" {
+			fmt.Printf("Wrote %s (%d BMC(s))\n", path, n)
+		}
+	}
+	return nil
+}
+
+func init() {
+	firmwareCmd.AddCommand(firmwareComplianceCmd)
+	firmwareComplianceCmd.Flags().StringVar(&fwCompManifest, "manifest", "", "YAML manifest of blessed firmware versions per component type (nC, cC, BIOS) and hardware model")
+	firmwareComplianceCmd.Flags().StringVarP(&fwCompOutput, "output", "o", "table", "output format: table or json")
+	firmwareComplianceCmd.Flags().StringVar(&fwCompRemediation, "remediation-dir", "", "write the outdated BMCs of each rule to <dir>/<rule>.hosts, in the --hosts-file format")
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// complianceBMC serves a node controller with one EX425 system, its BMC
+// firmware at bmcVersion and its BIOS at 1.2.0.
+func complianceBMC(t *testing.T, bmcVersion string) string {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/UpdateService/FirmwareInventory":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/UpdateService/FirmwareInventory/BMC"},{"@odata.id":"/redfish/v1/UpdateService/FirmwareInventory/Node0.BIOS"}]}`)
+		case "/redfish/v1/UpdateService/FirmwareInventory/BMC":
+			fmt.Fprintf(w, `{"Id":"BMC","Version":%q}`, bmcVersion)
+		case "/redfish/v1/UpdateService/FirmwareInventory/Node0.BIOS":
+			fmt.Fprint(w, `{"Id":"Node0.BIOS","Version":"1.2.0"}`)
+		case "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Model":"WNC"}`)
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case "/redfish/v1/Systems/Node0":
+			fmt.Fprint(w, `{"Model":"EX425"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestFirmwareCompliance(t *testing.T) {
+	current, old := complianceBMC(t, "nc.1.9.8"), complianceBMC(t, "nc.1.9.7")
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	dir := t.TempDir()
+	hostsFile, manifest, remediation := filepath.Join(dir, "hosts"), filepath.Join(dir, "manifest.yaml"), filepath.Join(dir, "remediation")
+	if err := os.WriteFile(hostsFile, []byte(current+",x9000c1s0b0\n"+old+",x9000c1s1b0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(manifest, []byte("rules:\n  - type: nC\n    model: EX425\n    version: nc.1.9.8\n  - type: BIOS\n    version: 1.2.0\n  - type: cC\n    version: cc.1.9.8\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fwHostsFile, fwInsecure, fwCompManifest, fwCompRemediation = hostsFile, true, manifest, remediation
+	t.Cleanup(func() {
+		fwHostsFile, fwInsecure, fwCompManifest, fwCompOutput, fwCompRemediation = "", false, "", "table", ""
+	})
+	firmwareComplianceCmd.SetContext(context.Background())
+
+	out, err := captureOutput(t, func() error { return firmwareComplianceCmd.RunE(firmwareComplianceCmd, nil) })
+	var oe *outcomeError
+	if !errors.As(err, &oe) || oe.code != exitPartial {
+		t.Fatalf("err = %v, want a partial failure for the outdated BMC\n%s", err, out)
+	}
+	for _, want := range []string{
+		"x9000c1s1b0  outdated   nC-EX425  BMC         nc.1.9.8  nc.1.9.7",
+		"x9000c1s0b0  compliant  BIOS      Node0.BIOS  1.2.0     1.2.0",
+		"Compliance: 2 BMC(s): 50.0% compliant (1), 50.0% outdated (1), 0.0% unknown (0)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table output missing %q:\n%s", want, out)
+		}
+	}
+	got, err := os.ReadFile(filepath.Join(remediation, "nC-EX425.hosts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(got)), "\n"); len(lines) != 2 || lines[1] != old+",x9000c1s1b0" {
+		t.Errorf("remediation file =\n%s\nwant only the outdated BMC", got)
+	}
+	if targets, err := resolveHosts("", "", filepath.Join(remediation, "nC-EX425.hosts")); err != nil || len(targets) != 1 {
+		t.Errorf("remediation file as --hosts-file = %v, %v", targets, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(remediation, "cC.hosts")); strings.Count(string(got), "\n") != 1 {
+		t.Errorf("a rule with nothing to do should get only its header:\n%s", got)
+	}
+
+	fwCompOutput, fwCompRemediation = "json", ""
+	out, _ = captureOutput(t, func() error { return firmwareComplianceCmd.RunE(firmwareComplianceCmd, nil) })
+	var report struct {
+		Hosts []struct {
+			Xname  string   `json:"xname"`
+			Kind   string   `json:"kind"`
+			Models []string `json:"models"`
+			Result string   `json:"result"`
+			Checks []struct {
+				Rule, Expected, Actual, Result string
+			} `json:"checks"`
+		} `json:"hosts"`
+		Summary struct {
+			Hosts            int     `json:"hosts"`
+			Outdated         int     `json:"outdated"`
+			CompliantPercent float64 `json:"compliant_percent"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("json output: %v\n%s", err, out)
+	}
+	h := report.Hosts[1]
+	if h.Xname != "x9000c1s1b0" || h.Kind != "nC" || len(h.Models) != 2 || h.Result != "outdated" || len(h.Checks) != 2 || h.Checks[0].Actual != "nc.1.9.7" {
+		t.Errorf("json host = %+v", h)
+	}
+	if report.Summary.Hosts != 2 || report.Summary.Outdated != 1 || report.Summary.CompliantPercent != 50 {
+		t.Errorf("json summary = %+v", report.Summary)
+	}
+}
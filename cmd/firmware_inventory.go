@@ -0,0 +1,330 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	fwInvComponents []string
+	fwInvOutput     string
+	fwInvBaseline   string
+)
+
+// Component outcomes against a --baseline.
+const (
+	baselineOK       = "ok"
+	baselineOutdated = "outdated"
+	baselineMissing  = "missing"
+)
+
+// firmwareComponentRow is one component of the firmware inventory report.
+type firmwareComponentRow struct {
+	BMC        string `json:"bmc"`
+	Xname      string `json:"xname,omitempty"`
+	Component  string `json:"component"`
+	Name       string `json:"name,omitempty"`
+	Version    string `json:"version"`
+	Updateable bool   `json:"updateable"`
+	Expected   string `json:"expected,omitempty"`
+	Status     string `json:"status,omitempty"`
+}
+
+// firmwareHostResult is the compliance of one BMC with the --baseline.
+type firmwareHostResult struct {
+	BMC       string   `json:"bmc"`
+	Xname     string   `json:"xname,omitempty"`
+	Compliant bool     `json:"compliant"`
+	Outdated  []string `json:"outdated,omitempty"`
+	Missing   []string `json:"missing,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// firmwareFleetSummary counts the BMCs of a compliance report.
+type firmwareFleetSummary struct {
+	Pass   int `json:"pass"`
+	Fail   int `json:"fail"`
+	Failed int `json:"failed"`
+}
+
+var firmwareInventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "List every FirmwareInventory component of the selected BMCs",
+	Long: `Read every member of /redfish/v1/UpdateService/FirmwareInventory on the
+selected BMCs and print the host, component Id, version, and whether the
+component is updateable. --component keeps the components whose Id contains
+one of the given substrings.
+
+With --baseline, a YAML or JSON map of component Id to expected version,
+each BMC is checked against it instead: a component is ok, outdated, or
+missing, and a BMC passes when all of its baseline components are ok. BMCs
+that do not pass, or cannot be read, fail the command.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if fwInvOutput != "table" && fwInvOutput != "csv" && fwInvOutput != "json" {
+			return fmt.Errorf("--output must be table, csv, or json")
+		}
+		var baseline map[string]string
+		if fwInvBaseline != "" {
+			var err error
+			if baseline, err = readFirmwareBaseline(fwInvBaseline); err != nil {
+				return err
+			}
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(fwFile, fwHostsCSV, fwHostsFile)
+		if err != nil {
+			return err
+		}
+
+		// Components are kept per target so output follows inventory order
+		results := make([][]redfish.FirmwareComponent, len(targets))
+		errs := make([]error, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		var mu sync.Mutex
+		var read, failed int
+		forEachTarget(cmd.Context(), targets, fwBatchSize, fwTimeout, func(ctx context.Context, t bmcTarget) {
+			components, err := redfish.ListFirmwareInventory(ctx, t.Host, user, pass, fwInsecure, fwTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				errs[index[t]] = err
+				diag.Warnf("%s: firmware inventory: %v", t.label(), err)
+				return
+			}
+			read++
+			results[index[t]] = components
+		})
+
+		if baseline != nil {
+			return reportFirmwareCompliance(cmd.Context(), targets, results, errs, baseline)
+		}
+
+		rows := []firmwareComponentRow{}
+		for i, components := range results {
+			for _, c := range components {
+				if matchesComponent(c.ID) {
+					rows = append(rows, firmwareComponentRow{BMC: targets[i].Host, Xname: targets[i].Xname, Component: c.ID,
+						Name: c.Name, Version: c.Version, Updateable: c.Updateable})
+				}
+			}
+		}
+		if err := writeFirmwareRows(rows, false); err != nil {
+			return err
+		}
+		if fwInvOutput == "table" {
+			fmt.Printf("Firmware inventory: %d component(s) on %d BMC(s), %d failed\n", len(rows), read, failed)
+		}
+		if err := checkOutcome(read, failed, "reading the firmware inventory failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// reportFirmwareCompliance compares the components of every BMC read with
+// baseline and prints the components, the result per BMC, and a summary.
+func reportFirmwareCompliance(ctx context.Context, targets []bmcTarget, results [][]redfish.FirmwareComponent, errs []error, baseline map[string]string) error {
+	ids := make([]string, 0, len(baseline))
+	for id := range baseline {
+		if matchesComponent(id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	rows := []firmwareComponentRow{}
+	hosts := make([]firmwareHostResult, 0, len(targets))
+	var summary firmwareFleetSummary
+	for i, t := range targets {
+		host := firmwareHostResult{BMC: t.Host, Xname: t.Xname}
+		if errs[i] != nil || (results[i] == nil && ctx.Err() != nil) {
+			host.Error = "not read"
+			if errs[i] != nil {
+				host.Error = errs[i].Error()
+			}
+			summary.Failed++
+			hosts = append(hosts, host)
+			continue
+		}
+		for _, id := range ids {
+			row := firmwareComponentRow{BMC: t.Host, Xname: t.Xname, Component: id, Expected: baseline[id], Status: baselineMissing}
+			for _, c := range results[i] {
+				if strings.EqualFold(c.ID, id) {
+					row.Name, row.Version, row.Updateable = c.Name, c.Version, c.Updateable
+					row.Status = baselineOutdated
+					if c.Version == baseline[id] {
+						row.Status = baselineOK
+					}
+					break
+				}
+			}
+			switch row.Status {
+			case baselineOutdated:
+				host.Outdated = append(host.Outdated, id)
+			case baselineMissing:
+				host.Missing = append(host.Missing, id)
+			}
+			rows = append(rows, row)
+		}
+		host.Compliant = len(host.Outdated)+len(host.Missing) == 0
+		if host.Compliant {
+			summary.Pass++
+		} else {
+			summary.Fail++
+		}
+		hosts = append(hosts, host)
+	}
+
+	if fwInvOutput == "json" {
+		out, err := json.MarshalIndent(struct {
+			Components []firmwareComponentRow `json:"components"`
+			Hosts      []firmwareHostResult   `json:"hosts"`
+			Summary    firmwareFleetSummary   `json:"summary"`
+		}{rows, hosts, summary}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	} else {
+		if err := writeFirmwareRows(rows, true); err != nil {
+			return err
+		}
+	}
+	if fwInvOutput == "table" {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "\nHOST\tRESULT\tDETAIL")
+		for _, h := range hosts {
+			label := bmcTarget{Host: h.BMC, Xname: h.Xname}.label()
+			switch {
+			case h.Error != "":
+				fmt.Fprintf(tw, "%s\tERROR\t%s\n", label, h.Error)
+			case h.Compliant:
+				fmt.Fprintf(tw, "%s\tPASS\t-\n", label)
+			default:
+				fmt.Fprintf(tw, "%s\tFAIL\t%d outdated, %d missing\n", label, len(h.Outdated), len(h.Missing))
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("Compliance: %d BMC(s) pass, %d fail, %d could not be read\n", summary.Pass, summary.Fail, summary.Failed)
+	}
+	if err := checkOutcome(summary.Pass, summary.Fail+summary.Failed, "%d BMC(s) do not match the baseline and %d could not be read",
+		summary.Fail, summary.Failed); err != nil {
+		return err
+	}
+	return checkInterrupted(ctx)
+}
+
+// writeFirmwareRows prints rows as a table or CSV (JSON is handled by the
+// callers), with the expected version and status when compliance is set.
+func writeFirmwareRows(rows []firmwareComponentRow, compliance bool) error {
+	switch fwInvOutput {
+	case "json":
+		out, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		header := []string{"bmc", "xname", "component", "name", "version", "updateable"}
+		if compliance {
+			header = append(header, "expected", "status")
+		}
+		_ = w.Write(header)
+		for _, r := range rows {
+			rec := []string{r.BMC, r.Xname, r.Component, r.Name, r.Version, strconv.FormatBool(r.Updateable)}
+			if compliance {
+				rec = append(rec, r.Expected, r.Status)
+			}
+			_ = w.Write(rec)
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		if len(rows) == 0 {
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if compliance {
+			fmt.Fprintln(tw, "HOST\tCOMPONENT\tVERSION\tEXPECTED\tSTATUS")
+		} else {
+			fmt.Fprintln(tw, "HOST\tCOMPONENT\tVERSION\tUPDATEABLE")
+		}
+		for _, r := range rows {
+			label := bmcTarget{Host: r.BMC, Xname: r.Xname}.label()
+			if compliance {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", label, r.Component, valueOrDash(r.Version), r.Expected, r.Status)
+			} else {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", label, r.Component, valueOrDash(r.Version), r.Updateable)
+			}
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// matchesComponent reports whether id contains one of the --component
+// substrings, ignoring case, or --component is not set.
+func matchesComponent(id string) bool {
+	if len(fwInvComponents) == 0 {
+		return true
+	}
+	for _, sub := range fwInvComponents {
+		if strings.Contains(strings.ToLower(id), strings.ToLower(sub)) {
+			return true
+		}
+	}
+	return false
+}
+
+// readFirmwareBaseline reads a YAML or JSON map of component Id to expected
+// version.
+func readFirmwareBaseline(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	baseline := map[string]string{}
+	if err := yaml.Unmarshal(raw, &baseline); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(baseline) == 0 {
+		return nil, errors.New("--baseline lists no components")
+	}
+	return baseline, nil
+}
+
+func init() {
+	firmwareCmd.AddCommand(firmwareInventoryCmd)
+	firmwareInventoryCmd.Flags().StringSliceVar(&fwInvComponents, "component", nil, "only components whose Id contains one of these substrings (comma-separated or repeated)")
+	firmwareInventoryCmd.Flags().StringVarP(&fwInvOutput, "output", "o", "table", "output format: table, csv, or json")
+	firmwareInventoryCmd.Flags().StringVar(&fwInvBaseline, "baseline", "", "YAML or JSON map of component Id to expected version; report per-BMC compliance with it")
+}
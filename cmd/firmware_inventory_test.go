@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// firmwareInventoryBMC serves a two-page FirmwareInventory with the given
+// BMC version.
+func firmwareInventoryBMC(t *testing.T, bmcVersion string) string {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/UpdateService/FirmwareInventory":
+			if r.URL.Query().Get("$skip") == "" {
+				fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/UpdateService/FirmwareInventory/BMC"}],
+					"Members@odata.nextLink":"/redfish/v1/UpdateService/FirmwareInventory?$skip=1"}`)
+				return
+			}
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/UpdateService/FirmwareInventory/BIOS"}]}`)
+		case "/redfish/v1/UpdateService/FirmwareInventory/BMC":
+			fmt.Fprintf(w, `{"Id":"BMC","Name":"BMC Firmware","Version":%q,"Updateable":true}`, bmcVersion)
+		case "/redfish/v1/UpdateService/FirmwareInventory/BIOS":
+			fmt.Fprint(w, `{"Id":"BIOS","Version":"2.1","Updateable":false}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestFirmwareInventory(t *testing.T) {
+	current, old := firmwareInventoryBMC(t, "1.9"), firmwareInventoryBMC(t, "1.7")
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	fwHostsCSV, fwInsecure = current+","+old, true
+	t.Cleanup(func() {
+		fwHostsCSV, fwInsecure, fwInvComponents, fwInvOutput, fwInvBaseline = "", false, nil, "table", ""
+	})
+	firmwareInventoryCmd.SetContext(context.Background())
+
+	out, err := captureOutput(t, func() error { return firmwareInventoryCmd.RunE(firmwareInventoryCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"BIOS", "2.1", "false", "1.7", "Firmware inventory: 4 component(s) on 2 BMC(s), 0 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table output missing %q:\n%s", want, out)
+		}
+	}
+
+	fwInvComponents, fwInvOutput = []string{"bm"}, "csv"
+	out, err = captureOutput(t, func() error { return firmwareInventoryCmd.RunE(firmwareInventoryCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "bmc,xname,component,name,version,updateable\n" + current + ",,BMC,BMC Firmware,1.9,true\n" + old + ",,BMC,BMC Firmware,1.7,true\n"; out != want {
+		t.Errorf("csv output:\n%s\nwant:\n%s", out, want)
+	}
+
+	baseline := filepath.Join(t.TempDir(), "baseline.yaml")
+	if err := os.WriteFile(baseline, []byte("BMC: \"1.9\"\nBIOS: \"2.1\"\nCPLD: \"3\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fwInvComponents, fwInvOutput, fwInvBaseline = []string{"bmc", "bios"}, "json", baseline
+	out, err = captureOutput(t, func() error { return firmwareInventoryCmd.RunE(firmwareInventoryCmd, nil) })
+	var oe *outcomeError
+	if !errors.As(err, &oe) || oe.code != exitPartial {
+		t.Fatalf("expected a partial outcome, got %v", err)
+	}
+	var report struct {
+		Components []firmwareComponentRow `json:"components"`
+		Hosts      []firmwareHostResult   `json:"hosts"`
+		Summary    firmwareFleetSummary   `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(report.Components) != 4 || !report.Hosts[0].Compliant || report.Hosts[1].Compliant ||
+		strings.Join(report.Hosts[1].Outdated, ",") != "BMC" || report.Summary != (firmwareFleetSummary{Pass: 1, Fail: 1}) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	fwInvComponents, fwInvOutput = nil, "table"
+	out, _ = captureOutput(t, func() error { return firmwareInventoryCmd.RunE(firmwareInventoryCmd, nil) })
+	for _, want := range []string{"CPLD", "missing", "outdated", "FAIL", "1 outdated, 1 missing", "Compliance: 0 BMC(s) pass, 2 fail, 0 could not be read"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("compliance table missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -12,63 +12,55 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"bootstrap/internal/diag"
 	"bootstrap/internal/inventory"
 	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var (
 	// reuse firmware flags (made persistent)
 	fwStatusInterval time.Duration
 	fwFormat         string
+	fwRecord         bool
 )
 
 var firmwareStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Query BMC firmware versions and in-progress updates",
 	RunE: func(cmd *cobra.Command, args []string) error { // nolint:revive
-		user := os.Getenv("REDFISH_USER")
-		pass := os.Getenv("REDFISH_PASSWORD")
-		if user == "" || pass == "" {
-			return errors.New("REDFISH_USER and REDFISH_PASSWORD env vars are required")
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
 		}
 
-		// Determine hosts to target (reuse logic from firmware.go)
-		hosts := []string{}
-		if strings.TrimSpace(fwHostsCSV) != "" {
-			for _, h := range strings.Split(fwHostsCSV, ",") {
-				h = strings.TrimSpace(h)
-				if h != "" {
-					hosts = append(hosts, h)
-				}
-			}
-		} else {
-			raw, err := os.ReadFile(fwFile)
-			if err != nil {
-				return err
-			}
-			var doc inventory.FileFormat
-			if err := yaml.Unmarshal(raw, &doc); err != nil {
+		if fwRecord && (fwFile == "" || strings.TrimSpace(fwHostsCSV) != "" || fwHostsFile != "") {
+			return errors.New("--record requires --file and cannot be combined with --hosts or --hosts-file")
+		}
+
+		// Determine hosts to target
+		bmcs, err := resolveHosts(fwFile, fwHostsCSV, fwHostsFile)
+		if err != nil {
+			return err
+		}
+		hosts := make([]string, 0, len(bmcs))
+		for _, b := range bmcs {
+			hosts = append(hosts, b.Host)
+		}
+		var doc inventory.FileFormat
+		if fwRecord {
+			if doc, err = readInventory(fwFile); err != nil {
 				return err
 			}
-			if len(doc.BMCs) == 0 {
-				return fmt.Errorf("input must contain non-empty bmcs[]")
-			}
-			for _, b := range doc.BMCs {
-				host := b.IP
-				if host == "" {
-					host = b.Xname
-				}
-				hosts = append(hosts, host)
-			}
 		}
 
 		if len(hosts) == 0 {
@@ -94,7 +86,6 @@ var firmwareStatusCmd = &cobra.Command{
 		var mu sync.Mutex
 		versionCounts := map[string]int{}
 		inProgress := int32(0)
-		errorsList := map[string]string{}
 
 		// Collect per-target summaries for JSON output
 		type hostSummary struct {
@@ -102,25 +93,66 @@ var firmwareStatusCmd = &cobra.Command{
 			Target           string `json:"target"`
 			ObservedVersion  string `json:"observed_version"`
 			RequestedVersion string `json:"requested_version,omitempty"`
-			Status           string `json:"status"` // one of: in-progress, error, idle
+			Status           string `json:"status"` // one of: in-progress, error, idle, skipped (deadline)
 			Error            string `json:"error,omitempty"`
+			Category         string `json:"category,omitempty"` // redfish.Categorize category when unreadable
+			label            string // the BMC's xname, or host; summaries are sorted by it
 		}
 		var hostSummaries []hostSummary
+		// unreachable holds hosts whose firmware inventory could not be read;
+		// errors reported by a BMC about its own health are not failures
+		unreachable := map[string]bool{}
+		categories := map[string]string{} // host -> category of its first read failure
+
+		runCtx, cancelRun, err := withRunDeadline(cmd.Context(), fwDeadline)
+		if err != nil {
+			return err
+		}
+		defer cancelRun()
+		perHost := hostTimeout(fwHostTimeout, fwTimeout)
+		expired := 0
+		// skipExpired reports a host that --deadline left unstarted
+		skipExpired := func(hl *diag.HostLog, h, label string) {
+			if !deadlineHit(cmd.Context(), runCtx) {
+				return
+			}
+			hl.Warnf("skipped (deadline)")
+			mu.Lock()
+			defer mu.Unlock()
+			expired++
+			hostSummaries = append(hostSummaries, hostSummary{Host: h, ObservedVersion: "(unknown)", Status: "skipped (deadline)", label: label})
+		}
 
 		sem := make(chan struct{}, max(1, fwBatchSize))
+		pacer := newPacer(fwBatchSize)
 		var wg sync.WaitGroup
-		for _, host := range hosts {
+		for i, host := range hosts {
 			wg.Add(1)
-			h := host
+			h, label := host, bmcs[i].label()
 			go func() {
 				defer wg.Done()
-				sem <- struct{}{}
+				hl := diag.NewHostLog(label)
+				defer hl.Flush()
+				select {
+				case sem <- struct{}{}:
+				case <-runCtx.Done():
+					skipExpired(hl, h, label)
+					return
+				}
 				defer func() { <-sem }()
+				// A wait cut short by --stagger or --batch-pause is handled below
+				_ = pacer.Start(runCtx)
+				if runCtx.Err() != nil {
+					skipExpired(hl, h, label)
+					return
+				}
+				defer pacer.Done()
 
-				ctx := cmd.Context()
-				if fwTimeout > 0 {
+				// The host's budget starts now that it has a slot, not when queued
+				ctx := runCtx
+				if perHost > 0 {
 					var cancel context.CancelFunc
-					ctx, cancel = context.WithTimeout(ctx, fwTimeout)
+					ctx, cancel = context.WithTimeout(ctx, perHost)
 					defer cancel()
 				}
 
@@ -167,10 +199,18 @@ var firmwareStatusCmd = &cobra.Command{
 					var perrTarget string
 					var verTarget string
 					var anyInProgressTarget bool
+					var category string
 
 					inv, err := redfish.GetFirmwareInventory(ctx, h, user, pass, fwInsecure, fwTimeout, target)
 					if err != nil {
 						perrTarget = err.Error()
+						category = redfish.Categorize(err)
+						mu.Lock()
+						unreachable[h] = true
+						if categories[h] == "" {
+							categories[h] = category
+						}
+						mu.Unlock()
 					} else {
 						verTarget = inv.Version
 						// If the inventory reports a non-OK Health, treat as error and include conditions
@@ -249,10 +289,6 @@ var firmwareStatusCmd = &cobra.Command{
 					// Update aggregates and per-target list
 					mu.Lock()
 					versionCounts[verTarget]++
-					if combinedErr != "" {
-						// use host+target key so multiple targets per host are visible
-						errorsList[fmt.Sprintf("%s %s", h, target)] = combinedErr
-					}
 					if status == "in-progress" {
 						atomic.AddInt32(&inProgress, 1)
 					}
@@ -263,12 +299,61 @@ var firmwareStatusCmd = &cobra.Command{
 						RequestedVersion: fwExpectedVersion,
 						Status:           status,
 						Error:            combinedErr,
+						Category:         category,
+						label:            label,
 					})
 					mu.Unlock()
 				}
 			}()
 		}
 		wg.Wait()
+		// Summaries follow xname order whatever order the hosts finished in;
+		// a host's targets stay in --targets order
+		slices.SortStableFunc(hostSummaries, func(a, b hostSummary) int { return xname.Compare(a.label, b.label) })
+
+		if fwRecord && cmd.Context().Err() == nil {
+			observed := map[string]map[string]string{}
+			for _, hs := range hostSummaries {
+				if hs.ObservedVersion == "(unknown)" {
+					continue
+				}
+				if observed[hs.Host] == nil {
+					observed[hs.Host] = map[string]string{}
+				}
+				observed[hs.Host][hs.Target] = hs.ObservedVersion
+			}
+			n, err := recordFirmware(fwFile, &doc, observed)
+			if err != nil {
+				return err
+			}
+			diag.Infof("Recorded firmware versions for %d BMC(s) in %s", n, fwFile)
+		}
+
+		read := len(hosts) - len(unreachable) - expired
+		setMetric("bmcs_total", float64(len(hosts)))
+		setMetric("bmcs_failed", float64(len(unreachable)))
+		setMetric("firmware_updates_in_progress", float64(atomic.LoadInt32(&inProgress)))
+		var failedLabels []string
+		for _, b := range bmcs {
+			if unreachable[b.Host] {
+				failedLabels = append(failedLabels, b.label())
+			}
+		}
+		noteFailures(failedLabels, countCategories(categories))
+		versioned, compliant := 0, 0
+		for _, hs := range hostSummaries {
+			if hs.ObservedVersion != "(unknown)" {
+				versioned++
+			}
+			if fwExpectedVersion != "" && hs.ObservedVersion == fwExpectedVersion {
+				compliant++
+			}
+		}
+		setMetric("firmware_targets_total", float64(versioned))
+		if fwExpectedVersion != "" {
+			setMetric("firmware_targets_compliant", float64(compliant))
+		}
+		outcome := checkOutcome(read, len(unreachable), "firmware status could not be read on %d host(s)", len(unreachable))
 
 		// JSON format option
 		if strings.EqualFold(fwFormat, "json") {
@@ -277,7 +362,10 @@ var firmwareStatusCmd = &cobra.Command{
 				return err
 			}
 			fmt.Println(string(out))
-			return nil
+			if outcome != nil {
+				return outcome
+			}
+			return checkInterrupted(cmd.Context())
 		}
 
 		// Print human-readable summary
@@ -291,22 +379,57 @@ var firmwareStatusCmd = &cobra.Command{
 		}
 		fmt.Printf("  In-progress updates: %d\n", atomic.LoadInt32(&inProgress))
 		fmt.Println("  Versions:")
-		for v, c := range versionCounts {
-			fmt.Printf("    %s: %d\n", v, c)
+		for _, v := range slices.Sorted(maps.Keys(versionCounts)) {
+			fmt.Printf("    %s: %d\n", v, versionCounts[v])
 		}
-		if len(errorsList) > 0 {
-			fmt.Println("  Errors:")
-			for h, e := range errorsList {
-				fmt.Printf("    %s: %s\n", h, e)
+		errorsShown := false
+		for _, hs := range hostSummaries {
+			if hs.Error == "" {
+				continue
 			}
+			if !errorsShown {
+				fmt.Println("  Errors:")
+				errorsShown = true
+			}
+			// host and target, so multiple targets per host are visible
+			fmt.Printf("    %s %s: %s\n", hs.Host, hs.Target, hs.Error)
 		}
-
-		return nil
+		fmt.Printf("  Hosts: %d read, %d failed%s\n", read, len(unreachable), deadlineNote(expired))
+		printCategories(countCategories(categories))
+		if outcome != nil {
+			return outcome
+		}
+		return checkInterrupted(cmd.Context())
 	},
 }
 
+// recordFirmware merges observed versions (host -> target -> version) into
+// the firmware map of the matching bmcs[] entries and rewrites path. It
+// returns the number of BMC entries updated.
+func recordFirmware(path string, doc *inventory.FileFormat, observed map[string]map[string]string) (int, error) {
+	updated := 0
+	for i := range doc.BMCs {
+		b := &doc.BMCs[i]
+		host := b.Address()
+		versions, ok := observed[host]
+		if !ok {
+			continue
+		}
+		if b.Firmware == nil {
+			b.Firmware = map[string]string{}
+		}
+		for target, v := range versions {
+			b.Firmware[target] = v
+		}
+		updated++
+	}
+	return updated, writeInventory(path, *doc, "bmcs")
+}
+
 func init() {
 	firmwareCmd.AddCommand(firmwareStatusCmd)
 	firmwareStatusCmd.Flags().DurationVar(&fwStatusInterval, "interval", 5*time.Second, "poll interval (not used in single-run summary, reserved for future watch command)")
 	firmwareStatusCmd.Flags().StringVar(&fwFormat, "format", "", "output format: json")
+	addNotifyFlags(firmwareStatusCmd)
+	firmwareStatusCmd.Flags().BoolVar(&fwRecord, "record", false, "write the observed versions into the firmware map of each bmcs[] entry in --file")
 }
@@ -7,11 +7,13 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -496,3 +498,118 @@ func TestFirmwareStatusDetectsTaskServiceRunning(t *testing.T) {
 		t.Fatalf("expected one in-progress update via TaskService, got:\n%s", output)
 	}
 }
+
+func TestFirmwareStatusRecord(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/UpdateService/FirmwareInventory/BMC") {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"Id":"BMC","Version":"nc.1.12.0","Status":{"Health":"OK","State":"Enabled"}}`)
+			return
+		}
+		http.NotFound(w, r)
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	fwFile = makeInventoryFile(t, host)
+	defer os.Remove(fwFile) //nolint:errcheck
+	content := fmt.Sprintf("bmcs:\n  - xname: x9000c1s0b0\n    ip: %s\n    firmware:\n      Node0.BIOS: \"1.0\"\n  - xname: x9000c1s0b1\n    ip: 127.0.0.1:1\nnodes:\n  - xname: x9000c1s0b0n0\n    mac: aa:bb:cc:dd:ee:00\n    ip: 10.0.0.1\n", host)
+	if err := os.WriteFile(fwFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fwHostsCSV = ""
+	fwBatchSize = 1
+	fwTargets = []string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}
+	fwInsecure = true
+	fwTimeout = 2 * time.Second
+	fwRecord = true
+	defer func() { fwRecord = false }()
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+
+	old := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+
+	// The unreachable second BMC makes this a partial failure
+	cmd := firmwareStatusCmd
+	cmd.SetContext(context.Background())
+	err := cmd.RunE(cmd, []string{})
+	var oe *outcomeError
+	if !errors.As(err, &oe) || oe.code != exitPartial {
+		t.Fatalf("err = %v, want partial failure", err)
+	}
+	w.Close() //nolint:errcheck
+
+	doc, err := readInventory(fwFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"Node0.BIOS": "1.0", "/redfish/v1/UpdateService/FirmwareInventory/BMC": "nc.1.12.0"}
+	if len(doc.BMCs) != 2 || doc.BMCs[0].Xname != "x9000c1s0b0" || fmt.Sprint(doc.BMCs[0].Firmware) != fmt.Sprint(want) {
+		t.Errorf("bmcs[0] = %+v, want firmware %v", doc.BMCs[0], want)
+	}
+	if doc.BMCs[1].Firmware != nil {
+		t.Errorf("unreachable BMC should have no firmware recorded: %+v", doc.BMCs[1])
+	}
+	if len(doc.Nodes) != 1 || doc.Nodes[0].MAC != "aa:bb:cc:dd:ee:00" {
+		t.Errorf("nodes[] not preserved: %+v", doc.Nodes)
+	}
+}
+
+func TestFirmwareStatusSortedByXname(t *testing.T) {
+	// The BMCs answer in neither file nor xname order; the summary still
+	// follows xname order
+	xnames := []string{"x9000c1s2b0", "x9000c1s10b0", "x9000c1s0b0"}
+	hostXname := map[string]string{}
+	content := "bmcs:\n"
+	for i, x := range xnames {
+		delay := time.Duration(len(xnames)-i) * 50 * time.Millisecond
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/UpdateService/FirmwareInventory/BMC") {
+				time.Sleep(delay)
+				fmt.Fprint(w, `{"Id":"BMC","Version":"1.0","Status":{"Health":"OK","State":"Enabled"}}`)
+				return
+			}
+			http.NotFound(w, r)
+		}))
+		defer server.Close()
+		host := strings.TrimPrefix(server.URL, "https://")
+		hostXname[host] = x
+		content += fmt.Sprintf("  - xname: %s\n    ip: %s\n", x, host)
+	}
+	fwFile = filepath.Join(t.TempDir(), "inventory.yaml")
+	if err := os.WriteFile(fwFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fwHostsCSV = ""
+	fwBatchSize = 3
+	fwTargets = []string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}
+	fwInsecure = true
+	fwTimeout = 2 * time.Second
+	fwFormat = "json"
+	defer func() { fwFormat, fwBatchSize = "", 0 }()
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+
+	firmwareStatusCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return firmwareStatusCmd.RunE(firmwareStatusCmd, nil) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	var summaries []struct {
+		Host string `json:"host"`
+	}
+	if err := json.Unmarshal([]byte(out), &summaries); err != nil {
+		t.Fatalf("not JSON: %v\n%s", err, out)
+	}
+	var got []string
+	for _, s := range summaries {
+		got = append(got, hostXname[s.Host])
+	}
+	if want := []string{"x9000c1s0b0", "x9000c1s2b0", "x9000c1s10b0"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("summary order = %v, want %v", got, want)
+	}
+}
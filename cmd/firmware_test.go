@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -147,6 +148,7 @@ func TestFirmwareParallelExecution(t *testing.T) {
 			fwInsecure = true
 			fwTimeout = 5 * time.Second
 			fwDryRun = false
+			fwYes = true
 			fwBatchSize = tt.batchSize
 			fwTargets = nil
 			fwExpectedVersion = ""
@@ -216,7 +218,7 @@ func TestFirmwareParallelExecution(t *testing.T) {
 // TestFirmwareDryRunParallel tests dry-run mode with parallelism
 func TestFirmwareDryRunParallel(t *testing.T) {
 	server := mockRedfishFirmwareServer(t, 0, nil, nil)
-	_ = server // server only used to provide a host string if needed
+	host := strings.TrimPrefix(server.URL, "https://")
 
 	t.Setenv("REDFISH_USER", "testuser")
 	t.Setenv("REDFISH_PASSWORD", "testpass")
@@ -228,14 +230,15 @@ func TestFirmwareDryRunParallel(t *testing.T) {
 	}
 	defer os.Remove(tmpFile.Name()) //nolint: errcheck
 
-	inventory := `bmcs:
+	// The dry run reads each UpdateService, so every BMC is the mock
+	inventory := fmt.Sprintf(`bmcs:
   - xname: x9000c1s0b0
-    ip: 10.1.1.10
+    ip: %[1]s
   - xname: x9000c1s1b0
-    ip: 10.1.1.11
+    ip: %[1]s
   - xname: x9000c1s2b0
-    ip: 10.1.1.12
-`
+    ip: %[1]s
+`, host)
 	if _, err := tmpFile.WriteString(inventory); err != nil {
 		t.Fatal(err)
 	}
@@ -247,6 +250,8 @@ func TestFirmwareDryRunParallel(t *testing.T) {
 	fwImageURI = "http://10.0.0.1/firmware.bin"
 	fwProtocol = "HTTP"
 	fwDryRun = true
+	fwInsecure = true
+	fwTimeout = 5 * time.Second
 	fwBatchSize = 3
 	fwTargets = nil
 	fwExpectedVersion = "1.2.3"
@@ -280,6 +285,52 @@ func TestFirmwareDryRunParallel(t *testing.T) {
 	if !strings.Contains(output, "expected-version=1.2.3") {
 		t.Fatalf("expected-version not found in dry-run output: %s", output)
 	}
+	if !strings.Contains(output, "protocol=HTTP (allowed: not advertised)") {
+		t.Fatalf("allowed protocols not found in dry-run output: %s", output)
+	}
+}
+
+func TestFirmwareTransferProtocol(t *testing.T) {
+	var posted atomic.Value
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/UpdateService":
+			fmt.Fprint(w, `{"Actions":{"#UpdateService.SimpleUpdate":{"TransferProtocol@Redfish.AllowableValues":["HTTPS","TFTP"]}}}`)
+		case r.Method == "POST":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posted.Store(body["TransferProtocol"])
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("REDFISH_USER", "testuser")
+	t.Setenv("REDFISH_PASSWORD", "testpass")
+
+	fwFile, fwHostsCSV, fwHostsFile = "", strings.TrimPrefix(srv.URL, "https://"), ""
+	fwType, fwTargets, fwImageURI, fwProtocol = "bmc", nil, "https://10.0.0.1/firmware.bin", "HTTP"
+	fwInsecure, fwTimeout, fwDryRun, fwExpectedVersion, fwForce, fwBatchSize, fwYes = true, 5*time.Second, false, "", false, 0, true
+	t.Cleanup(func() { fwHostsCSV, fwAutoProtocol = "", false })
+
+	cmd := firmwareCmd
+	cmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err == nil || !strings.Contains(out, "TransferProtocol HTTP is not supported (BMC allows HTTPS, TFTP") {
+		t.Fatalf("err = %v, want HTTP rejected before posting\n%s", err, out)
+	}
+	if posted.Load() != nil {
+		t.Fatalf("SimpleUpdate was posted with %v", posted.Load())
+	}
+
+	fwAutoProtocol = true
+	if out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) }); err != nil {
+		t.Fatalf("--auto-protocol: %v\n%s", err, out)
+	}
+	if got := posted.Load(); got != "HTTPS" {
+		t.Errorf("TransferProtocol = %v, want HTTPS from the image URI", got)
+	}
 }
 
 // TestFirmwareSemaphoreLimiting tests that semaphore correctly limits concurrency
@@ -317,6 +368,7 @@ func TestFirmwareSemaphoreLimiting(t *testing.T) {
 	fwInsecure = true
 	fwTimeout = 10 * time.Second
 	fwDryRun = false
+	fwYes = true
 	fwBatchSize = 3
 	fwTargets = nil
 
@@ -385,3 +437,274 @@ func TestDefaultTargets(t *testing.T) {
 		})
 	}
 }
+
+// TestFirmwareGroupByBlade checks that the two BMCs of a blade are never
+// updated at the same time, while different blades still run in parallel.
+func TestFirmwareGroupByBlade(t *testing.T) {
+	var max0, cur0, max1, cur1, maxAll, curAll int32
+	enter := func(maxC, curC *int32) {
+		cur := atomic.AddInt32(curC, 1)
+		for prev := atomic.LoadInt32(maxC); cur > prev && !atomic.CompareAndSwapInt32(maxC, prev, cur); prev = atomic.LoadInt32(maxC) {
+		}
+	}
+	track := func(maxC, curC *int32) http.HandlerFunc {
+		inner := mockRedfishFirmwareServer(t, 0, nil, nil).Config.Handler
+		return func(w http.ResponseWriter, r *http.Request) {
+			// Count the request towards its blade and the whole run
+			enter(maxC, curC)
+			enter(&maxAll, &curAll)
+			defer atomic.AddInt32(curC, -1)
+			defer atomic.AddInt32(&curAll, -1)
+			time.Sleep(100 * time.Millisecond)
+			inner.ServeHTTP(w, r)
+		}
+	}
+	blade0 := httptest.NewTLSServer(track(&max0, &cur0))
+	defer blade0.Close()
+	blade1 := httptest.NewTLSServer(track(&max1, &cur1))
+	defer blade1.Close()
+	t.Setenv("REDFISH_USER", "testuser")
+	t.Setenv("REDFISH_PASSWORD", "testpass")
+
+	h0, h1 := strings.TrimPrefix(blade0.URL, "https://"), strings.TrimPrefix(blade1.URL, "https://")
+	inv := fmt.Sprintf(`bmcs:
+  - {xname: x9000c1s0b0, ip: %[1]s}
+  - {xname: x9000c1s0b1, ip: %[1]s}
+  - {xname: x9000c1s1b0, ip: %[2]s}
+  - {xname: x9000c1s1b1, ip: %[2]s}
+`, h0, h1)
+	path := t.TempDir() + "/inventory.yaml"
+	if err := os.WriteFile(path, []byte(inv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fwFile, fwHostsCSV, fwHostsFile = path, "", ""
+	fwType, fwTargets, fwImageURI, fwProtocol = "bmc", nil, "http://10.0.0.1/firmware.bin", "HTTP"
+	fwInsecure, fwTimeout, fwDryRun, fwExpectedVersion, fwForce, fwYes = true, 5*time.Second, false, "", false, true
+	fwBatchSize, fwGroupBy = 4, "blade"
+	t.Cleanup(func() { fwBatchSize, fwGroupBy = 0, "none" })
+
+	cmd := firmwareCmd
+	cmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	if n := strings.Count(out, "Triggered firmware update"); n != 4 {
+		t.Fatalf("expected 4 updates, got %d:\n%s", n, out)
+	}
+	if max0 != 1 || max1 != 1 {
+		t.Errorf("BMCs of one blade overlapped: max in flight per blade = %d, %d", max0, max1)
+	}
+	if maxAll < 2 {
+		t.Errorf("blades did not run in parallel: max in flight = %d", maxAll)
+	}
+}
+
+func TestInterleaveGroups(t *testing.T) {
+	targets := []bmcTarget{
+		{Xname: "x9000c1s0b0"}, {Xname: "x9000c1s0b1"}, {Xname: "x9000c1s1b0"},
+		{Xname: "x9000c1s1b1"}, {Host: "10.0.0.9"}, {Xname: "x9000c3s0b0"},
+	}
+	label := func(ts []bmcTarget) string {
+		var l []string
+		for _, t := range ts {
+			l = append(l, t.label())
+		}
+		return strings.Join(l, " ")
+	}
+	for by, want := range map[string]string{
+		"none":    "x9000c1s0b0 x9000c1s0b1 x9000c1s1b0 x9000c1s1b1 10.0.0.9 x9000c3s0b0",
+		"blade":   "x9000c1s0b0 x9000c1s1b0 10.0.0.9 x9000c3s0b0 x9000c1s0b1 x9000c1s1b1",
+		"chassis": "x9000c1s0b0 10.0.0.9 x9000c3s0b0 x9000c1s0b1 x9000c1s1b0 x9000c1s1b1",
+	} {
+		if got := label(interleaveGroups(targets, groupLocks(targets, by))); got != want {
+			t.Errorf("--group-by %s: order = %s, want %s", by, got, want)
+		}
+	}
+}
+
+func TestFirmwareSkipsHostsAtExpectedVersion(t *testing.T) {
+	a, b := mockRedfishFirmwareServer(t, 0, nil, nil), mockRedfishFirmwareServer(t, 0, nil, nil)
+	t.Setenv("REDFISH_USER", "testuser")
+	t.Setenv("REDFISH_PASSWORD", "testpass")
+	oldHosts, oldExpected, oldBatch := fwHostsCSV, fwExpectedVersion, fwBatchSize
+	t.Cleanup(func() { fwHostsCSV, fwExpectedVersion, fwBatchSize = oldHosts, oldExpected, oldBatch })
+
+	fwFile, fwHostsFile = "", ""
+	fwHostsCSV = strings.TrimPrefix(a.URL, "https://") + "," + strings.TrimPrefix(b.URL, "https://")
+	fwType, fwTargets, fwImageURI, fwProtocol = "bmc", nil, "http://10.0.0.1/firmware.bin", "HTTP"
+	fwInsecure, fwTimeout, fwDryRun, fwExpectedVersion, fwForce, fwYes = true, 5*time.Second, false, "1.0.0", false, true
+	fwBatchSize = 2
+
+	cmd := firmwareCmd
+	cmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err != nil {
+		t.Fatalf("a host at the expected version is not a failure: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "0 triggered, 2 skipped, 0 failed") || strings.Count(out, "already at expected version 1.0.0") != 2 {
+		t.Errorf("expected both hosts skipped:\n%s", out)
+	}
+}
+
+func TestFirmwareAbortThreshold(t *testing.T) {
+	bad := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+	good := mockRedfishFirmwareServer(t, 0, nil, nil)
+	t.Setenv("REDFISH_USER", "testuser")
+	t.Setenv("REDFISH_PASSWORD", "testpass")
+
+	// Two names for the failing BMC, then one that would succeed
+	port := bad.Listener.Addr().(*net.TCPAddr).Port
+	bad1, bad2 := fmt.Sprintf("127.0.0.1:%d", port), fmt.Sprintf("localhost:%d", port)
+	okHost := strings.TrimPrefix(good.URL, "https://")
+	dir := t.TempDir()
+	hostsFile := dir + "/hosts"
+	if err := os.WriteFile(hostsFile, []byte(fmt.Sprintf("%s,x9000c1s0b0\n%s\n%s,x9000c1s1b0\n", bad1, bad2, okHost)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fwFile, fwHostsCSV, fwHostsFile = "", "", hostsFile
+	fwType, fwTargets, fwImageURI, fwProtocol = "bmc", nil, "http://10.0.0.1/firmware.bin", "HTTP"
+	fwInsecure, fwTimeout, fwDryRun, fwExpectedVersion, fwForce, fwYes = true, 5*time.Second, false, "", false, true
+	fwBatchSize, fwAbortThreshold, fwFailedHostsOut = 0, 2, dir+"/failed"
+	t.Cleanup(func() { fwHostsFile, fwAbortThreshold, fwFailedHostsOut = "", 0, "" })
+
+	cmd := firmwareCmd
+	cmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "1 not attempted") {
+		t.Fatalf("err = %v, want the aborted host reported\n%s", err, out)
+	}
+	if !strings.Contains(out, "0 triggered, 0 skipped, 2 failed, 1 not attempted (aborted after 2 failures)") {
+		t.Errorf("summary does not separate aborted hosts:\n%s", out)
+	}
+	got, err := os.ReadFile(fwFailedHostsOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("# failed\n%s,x9000c1s0b0\n%s\n# not attempted (aborted)\n%s,x9000c1s1b0\n", bad1, bad2, okHost)
+	if string(got) != want {
+		t.Errorf("--failed-hosts-out =\n%s\nwant\n%s", got, want)
+	}
+
+	// The file is accepted by --hosts-file
+	targets, err := resolveHosts("", "", fwFailedHostsOut)
+	if err != nil || len(targets) != 3 || targets[2].Xname != "x9000c1s1b0" {
+		t.Errorf("resolveHosts(--failed-hosts-out) = %v, %v", targets, err)
+	}
+}
+
+func TestFirmwareApplyTime(t *testing.T) {
+	var posted atomic.Value
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/UpdateService":
+			fmt.Fprint(w, `{"Actions":{"#UpdateService.SimpleUpdate":{"@Redfish.OperationApplyTimeSupport":{"SupportedValues":["Immediate","OnReset"]}}}}`)
+		case r.Method == "POST":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posted.Store(body)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("REDFISH_USER", "testuser")
+	t.Setenv("REDFISH_PASSWORD", "testpass")
+
+	fwFile, fwHostsCSV, fwHostsFile = "", strings.TrimPrefix(srv.URL, "https://"), ""
+	fwType, fwTargets, fwImageURI, fwProtocol = "bmc", nil, "http://10.0.0.1/firmware.bin", "HTTP"
+	fwInsecure, fwTimeout, fwDryRun, fwExpectedVersion, fwForce, fwBatchSize, fwYes = true, 5*time.Second, false, "", false, 0, true
+	fwApplyTime, fwFormat = "onreset", "json"
+	t.Cleanup(func() { fwHostsCSV, fwApplyTime, fwFormat = "", "", "" })
+
+	cmd := firmwareCmd
+	cmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err != nil {
+		t.Fatalf("OnReset: %v\n%s", err, out)
+	}
+	body, _ := posted.Load().(map[string]any)
+	if got := body["@Redfish.OperationApplyTime"]; got != "OnReset" {
+		t.Errorf("posted apply time = %v, want OnReset", got)
+	}
+	var results []firmwareResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("json output: %v\n%s", err, out)
+	}
+	if len(results) != 1 || results[0].Outcome != "triggered" || results[0].ApplyTime != "OnReset" || results[0].Protocol != "HTTP" {
+		t.Errorf("results = %+v, want one triggered OnReset update over HTTP", results)
+	}
+
+	posted = atomic.Value{}
+	fwApplyTime, fwFormat = "AtMaintenanceWindowStart", ""
+	out, err = captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err == nil || !strings.Contains(out, "apply time AtMaintenanceWindowStart is not supported (BMC allows Immediate, OnReset)") {
+		t.Fatalf("err = %v, want the host rejected before posting\n%s", err, out)
+	}
+	if posted.Load() != nil {
+		t.Fatalf("SimpleUpdate was posted with %v", posted.Load())
+	}
+}
+
+func TestFirmwareSkipsCriticalManager(t *testing.T) {
+	var posts atomic.Int32
+	critical := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Id":"BMC","Status":{"State":"Enabled","Health":"Critical"}}`)
+		case r.URL.Path == "/redfish/v1/UpdateService":
+			fmt.Fprint(w, `{"Actions":{"#UpdateService.SimpleUpdate":{"target":"/redfish/v1/UpdateService/Actions/UpdateService.SimpleUpdate"}}}`)
+		case r.Method == "POST":
+			posts.Add(1)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer critical.Close()
+	good := mockRedfishFirmwareServer(t, 0, nil, nil)
+	t.Setenv("REDFISH_USER", "testuser")
+	t.Setenv("REDFISH_PASSWORD", "testpass")
+	dir := t.TempDir()
+	badHost, okHost := strings.TrimPrefix(critical.URL, "https://"), strings.TrimPrefix(good.URL, "https://")
+
+	fwFile, fwHostsCSV, fwHostsFile = "", badHost+","+okHost, ""
+	fwType, fwTargets, fwImageURI, fwProtocol = "bmc", nil, "http://10.0.0.1/firmware.bin", "HTTP"
+	fwInsecure, fwTimeout, fwDryRun, fwExpectedVersion, fwForce, fwYes = true, 5*time.Second, false, "", false, true
+	fwBatchSize, fwFailedHostsOut = 0, dir+"/failed"
+	t.Cleanup(func() { fwHostsCSV, fwFailedHostsOut, fwForce = "", "", false })
+
+	cmd := firmwareCmd
+	cmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "1 not updated with a Critical manager") {
+		t.Fatalf("err = %v, want the Critical host counted against the run\n%s", err, out)
+	}
+	if posts.Load() != 0 {
+		t.Errorf("the Critical BMC got %d update request(s)", posts.Load())
+	}
+	if !strings.Contains(out, "1 triggered, 0 skipped, 0 failed, 1 not updated (manager Critical)") {
+		t.Errorf("summary does not report the Critical host:\n%s", out)
+	}
+	got, err := os.ReadFile(fwFailedHostsOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# skipped (manager Critical)\n" + badHost + "\n"; string(got) != want {
+		t.Errorf("--failed-hosts-out =\n%s\nwant\n%s", got, want)
+	}
+
+	fwForce = true
+	out, err = captureOutput(t, func() error { return cmd.RunE(cmd, nil) })
+	if err != nil || posts.Load() != 1 {
+		t.Errorf("with --force: err = %v, %d update request(s); want the update sent\n%s", err, posts.Load(), out)
+	}
+}
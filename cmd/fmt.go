@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"bootstrap/internal/inventory"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fmtSortBMCs bool
+	fmtCheck    bool
+)
+
+var formatCmd = &cobra.Command{
+	Use:   "fmt <file.yaml>...",
+	Short: "Rewrite inventory files in the canonical form discover writes",
+	Long: `Rewrite each inventory file the way discover writes it: nodes[] (and bmcs[]
+with --sort-bmcs) sorted by xname in natural order, MACs in lowercase
+colon-separated form, and the fields of every entry in a fixed order.
+Comments and keys outside bmcs[] and nodes[] are kept.
+
+With --check nothing is written; the files that are not canonical are
+listed and the command fails.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		var unformatted int
+		for _, path := range args {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			out, err := canonicalInventory(raw, fmtSortBMCs)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if bytes.Equal(raw, out) {
+				continue
+			}
+			unformatted++
+			if fmtCheck {
+				fmt.Println(path)
+				continue
+			}
+			if err := os.WriteFile(path, out, 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("Formatted %s\n", path)
+		}
+		if fmtCheck && unformatted > 0 {
+			return errors.New("some files are not canonical; run fmt without --check to rewrite them")
+		}
+		return nil
+	},
+}
+
+// canonicalInventory returns raw with bmcs[], nodes[], and controllers[]
+// canonicalized.
+func canonicalInventory(raw []byte, sortBMCs bool) ([]byte, error) {
+	doc, tree, err := inventory.ParseDocument(raw)
+	if err != nil {
+		return nil, err
+	}
+	inventory.Canonicalize(&doc, sortBMCs)
+	// A missing section is not added
+	for _, s := range []struct {
+		key     string
+		entries []inventory.Entry
+	}{{"bmcs", doc.BMCs}, {"nodes", doc.Nodes}, {"controllers", doc.Controllers}} {
+		if s.entries == nil {
+			continue
+		}
+		if err := tree.Set(s.key, s.entries); err != nil {
+			return nil, err
+		}
+	}
+	return tree.Bytes()
+}
+
+func init() {
+	rootCmd.AddCommand(formatCmd)
+	formatCmd.Flags().BoolVar(&fmtSortBMCs, "sort-bmcs", false, "also sort bmcs[] by xname")
+	formatCmd.Flags().BoolVar(&fmtCheck, "check", false, "list files that are not canonical instead of rewriting them, and fail if there are any")
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalInventory(t *testing.T) {
+	raw := []byte(`# site inventory
+bmcs:
+    - xname: x9000c1s10b0
+      ip: 10.1.1.30
+      mac: 02-23-28-01-30-A0
+    - xname: x9000c1s9b0
+      mac: ""
+      ip: 10.1.1.29
+nodes:
+    - xname: x9000c1s10b0n0
+      mac: 0040A688D910
+      ip: 10.42.0.2
+    # pulled for RMA
+    - xname: x9000c1s9b0n0
+      mac: 00:40:A6:88:D9:09
+      ip: 10.42.0.1
+      location: rack 7
+`)
+	want := `# site inventory
+bmcs:
+    - xname: x9000c1s10b0
+      mac: 02:23:28:01:30:a0
+      ip: 10.1.1.30
+    - xname: x9000c1s9b0
+      mac: ""
+      ip: 10.1.1.29
+nodes:
+    # pulled for RMA
+    - xname: x9000c1s9b0n0
+      mac: 00:40:a6:88:d9:09
+      ip: 10.42.0.1
+      location: rack 7
+    - xname: x9000c1s10b0n0
+      mac: 00:40:a6:88:d9:10
+      ip: 10.42.0.2
+`
+	out, err := canonicalInventory(raw, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	again, err := canonicalInventory(out, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("fmt is not idempotent:\n%s", again)
+	}
+
+	out, err = canonicalInventory(raw, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); strings.Index(got, "xname: x9000c1s9b0\n") > strings.Index(got, "xname: x9000c1s10b0\n") {
+		t.Errorf("--sort-bmcs should put x9000c1s9b0 first:\n%s", got)
+	}
+}
@@ -5,11 +5,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"bootstrap/internal/initbmcs"
 	"bootstrap/internal/inventory"
+	"bootstrap/internal/scan"
+	"bootstrap/internal/xname"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -22,47 +29,337 @@ var (
 	initStartIP      string
 	initNodesPerChas int
 	initNodesPerBMC  int
+	initBladeNodes   int
+	initSlotsPerChas int
 	initStartNID     int
+	initScan         string
+	initScanTimeout  time.Duration
+	initScanParallel int
+	initInsecure     bool
+	initCabinetType  string
+	initSlots        string
+	initControllers  bool
+	initAppend       bool
+	initUpdate       bool
 )
 
 var initBmcsCmd = &cobra.Command{
 	Use:   "init-bmcs",
 	Short: "Generate initial inventory with BMC entries",
+	Args:  cobra.NoArgs,
+	RunE:  runInitBmcs,
+}
+
+// initCmd groups the generators under one name: "init bmcs" is init-bmcs
+// and "init from-template" is init-bmcs from-template. Anything else is an
+// error rather than a run of init-bmcs that overwrites --file.
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate an initial inventory",
+	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
-		if initFile == "" {
-			return fmt.Errorf("--file is required")
+		return cmd.Help()
+	},
+}
+
+var initSubBmcsCmd = &cobra.Command{
+	Use:   "bmcs",
+	Short: "Generate initial inventory with BMC entries",
+	Args:  cobra.NoArgs,
+	RunE:  runInitBmcs,
+}
+
+// runInitBmcs generates bmcs[] from the --chassis or --cabinet-type flags,
+// or from the BMCs that answer --scan, and writes them to --file.
+func runInitBmcs(cmd *cobra.Command, args []string) error { //nolint:revive
+	if initFile == "" {
+		return fmt.Errorf("--file is required")
+	}
+	if initScan != "" {
+		return runInitScan(cmd)
+	}
+	doc, found, err := readAppendTarget()
+	if err != nil {
+		return err
+	}
+	if initBMCSubnet == "" {
+		return fmt.Errorf("--bmc-subnet is required")
+	}
+	chassis := initbmcs.ParseChassisSpec(initChassis)
+	if len(chassis) == 0 {
+		return fmt.Errorf("--chassis must specify at least one entry, e.g. x9000c1=02:23:28:01")
+	}
+	normalized := make(map[string]string, len(chassis))
+	for c, mac := range chassis {
+		x, err := xname.Parse(c)
+		if err != nil || x.Kind != xname.KindChassis {
+			return fmt.Errorf("--chassis: %q is not a chassis xname like x9000c1", c)
+		}
+		normalized[x.String()] = mac
+	}
+	chassis = normalized
+	var tmpl initbmcs.Template
+	switch initCabinetType {
+	case initbmcs.Mountain:
+		if cmd.Flags().Changed("slots") {
+			return fmt.Errorf("--slots applies only to --cabinet-type river")
 		}
-		if initBMCSubnet == "" {
-			return fmt.Errorf("--bmc-subnet is required")
+
+		tmpl, err = initbmcs.MountainTemplate(chassis, initbmcs.Geometry{
+			NodesPerChassis: initNodesPerChas,
+			NodesPerBMC:     initNodesPerBMC,
+			NodesPerBlade:   initBladeNodes,
+			SlotsPerChassis: initSlotsPerChas,
+		}, initStartNID, initBMCSubnet, initStartIP)
+	case initbmcs.River:
+		// River servers have one node per BMC and one BMC per slot
+		for _, f := range []string{"nodes-per-chassis", "nodes-per-bmc", "nodes-per-blade", "slots-per-chassis"} {
+			if cmd.Flags().Changed(f) {
+				return fmt.Errorf("--%s does not apply to river cabinets; use --slots", f)
+			}
+		}
+		if initControllers {
+			return fmt.Errorf("--chassis-controllers applies only to --cabinet-type mountain")
 		}
-		chassis := initbmcs.ParseChassisSpec(initChassis)
-		if len(chassis) == 0 {
-			return fmt.Errorf("--chassis must specify at least one entry, e.g. x9000c1=02:23:28:01")
+		first, last, perr := initbmcs.ParseSlotRange(initSlots)
+		if perr != nil {
+			return perr
 		}
-		bmcs, err := initbmcs.Generate(chassis, initNodesPerChas, initNodesPerBMC, initStartNID, initBMCSubnet, initStartIP)
+		tmpl, err = initbmcs.RiverTemplate(chassis, first, last, initStartNID, initBMCSubnet, initStartIP)
+	default:
+		return fmt.Errorf("unknown --cabinet-type %q (use mountain or river)", initCabinetType)
+	}
+	if err != nil {
+		return err
+	}
+	tmpl.Existing = doc.BMCs
+	bmcs, err := tmpl.Generate()
+	if err != nil {
+		return err
+	}
+	if initControllers {
+		// Chassis controllers come last so the node BMCs keep the
+		// addresses they get without the flag
+		ccs, err := initbmcs.ChassisControllers(chassis, append(slices.Clone(doc.BMCs), bmcs...), initBMCSubnet, initStartIP)
 		if err != nil {
 			return err
 		}
-		doc := inventory.FileFormat{BMCs: bmcs, Nodes: nil}
-		bytes, err := yaml.Marshal(&doc)
+		bmcs = append(bmcs, ccs...)
+	}
+	return writeGenerated(doc, found, bmcs, "")
+}
+
+const initFromTemplateLong = `Generate bmcs[] from a YAML template describing cabinets, their chassis
+and populated slots, the nodes per blade and per BMC, and MAC and IP
+patterns, for racks that differ from what the init-bmcs flags describe:
+
+  subnet: 192.168.100.0/24
+  start_ip: 192.168.100.10
+  start_nid: 1
+  cabinets:
+    - cabinet: 9000
+      chassis: 1,3
+      slots: 0-4,6-7            # slot 5 is empty
+      nodes_per_blade: 4
+      nodes_per_bmc: 2
+      mac: "02:23:28:{chassis_hex}:3{slot}:{bmc}0"
+    - cabinet: 3000
+      chassis: 0
+      slots: 1-36
+      nodes_per_blade: 1
+      nodes_per_bmc: 1
+      mac: "02:23:28:05:{slot:02d}:00"
+      ip: "10.254.1.{slot}"
+
+Patterns take {cabinet}, {chassis}, {chassis_hex}, {slot}, {bmc}, and
+{nid}, each optionally with a printf verb such as {slot:02d}. A cabinet
+without an ip pattern gets its addresses from subnet. Errors name the
+template line and field.`
+
+var initFromTemplateCmd = &cobra.Command{
+	Use:   "from-template <template.yaml>",
+	Short: "Generate initial inventory with BMC entries from a rack template",
+	Long:  initFromTemplateLong,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runInitFromTemplate,
+}
+
+var initSubTemplateCmd = &cobra.Command{
+	Use:   "from-template <template.yaml>",
+	Short: "Generate initial inventory with BMC entries from a rack template",
+	Long:  initFromTemplateLong,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runInitFromTemplate,
+}
+
+// runInitFromTemplate generates bmcs[] from the rack template args[0] and
+// writes them to --file.
+func runInitFromTemplate(cmd *cobra.Command, args []string) error { //nolint:revive
+	if initFile == "" {
+		return fmt.Errorf("--file is required")
+	}
+	doc, found, err := readAppendTarget()
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	tmpl, err := initbmcs.ParseTemplate(raw)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	tmpl.Existing = doc.BMCs
+	bmcs, err := tmpl.Generate()
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return writeGenerated(doc, found, bmcs, args[0])
+}
+
+// readAppendTarget returns, with --append, the inventory in --file that
+// generated BMCs are merged into and whether the file exists. A file that
+// does not parse is refused rather than overwritten.
+func readAppendTarget() (inventory.FileFormat, bool, error) {
+	if initUpdate && !initAppend {
+		return inventory.FileFormat{}, false, errors.New("--update-existing requires --append")
+	}
+	if !initAppend {
+		return inventory.FileFormat{}, false, nil
+	}
+	doc, err := readInventory(initFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return inventory.FileFormat{}, false, nil
+	}
+	if err != nil {
+		return doc, false, fmt.Errorf("refusing to append: %w", err)
+	}
+	return doc, true, nil
+}
+
+// writeGenerated writes the generated bmcs to --file: as a new inventory
+// unless found, else merged into doc's bmcs[] with the rest of the file kept
+// as it was. template names the rack template they came from, if any.
+func writeGenerated(doc inventory.FileFormat, found bool, bmcs []inventory.Entry, template string) error {
+	from := ""
+	if template != "" {
+		from = " from " + template
+	}
+	if !found {
+		out, err := yaml.Marshal(&inventory.FileFormat{BMCs: bmcs})
 		if err != nil {
 			return err
 		}
-		if err := os.WriteFile(initFile, bytes, 0o644); err != nil {
+		if err := os.WriteFile(initFile, out, 0o644); err != nil {
 			return err
 		}
-		fmt.Printf("Wrote initial BMC inventory to %s with %d entries\n", initFile, len(bmcs))
+		fmt.Printf("Wrote initial BMC inventory to %s with %d entries%s\n", initFile, len(bmcs), from)
 		return nil
-	},
+	}
+	var added, listed int
+	doc.BMCs, added, listed = mergeGenerated(doc.BMCs, bmcs, initUpdate)
+	if err := writeInventory(initFile, doc, "bmcs"); err != nil {
+		return err
+	}
+	outcome := "skipped (already listed)"
+	if initUpdate {
+		outcome = "updated"
+	}
+	fmt.Printf("Appended to %s%s: %d BMC(s) added, %d %s\n", initFile, from, added, listed, outcome)
+	return nil
+}
+
+// mergeGenerated appends the generated BMCs whose xname is not in existing
+// and counts them as added. The others are counted as listed and left
+// alone, or with update get the generated MAC, IP, and NID while keeping
+// their other fields.
+func mergeGenerated(existing, generated []inventory.Entry, update bool) (merged []inventory.Entry, added, listed int) {
+	merged = slices.Clone(existing)
+	index := make(map[string]int, len(existing))
+	for i, e := range existing {
+		index[strings.ToLower(e.Xname)] = i
+	}
+	for _, g := range generated {
+		i, ok := index[strings.ToLower(g.Xname)]
+		if !ok {
+			merged = append(merged, g)
+			added++
+			continue
+		}
+		listed++
+		if update {
+			merged[i].MAC, merged[i].IP, merged[i].NID = g.MAC, g.IP, g.NID
+		}
+	}
+	return merged, added, listed
+}
+
+// runInitScan probes --scan for live BMCs and appends the responders that are
+// not already listed to bmcs[] in --file, preserving existing entries.
+func runInitScan(cmd *cobra.Command) error {
+	user, pass, err := redfishCredentials()
+	if err != nil {
+		return err
+	}
+	doc, err := readInventory(initFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	known := map[string]bool{}
+	for _, b := range doc.BMCs {
+		if b.IP != "" {
+			known[b.IP] = true
+		}
+	}
+
+	found, err := scan.Subnet(cmd.Context(), initScan, known, scan.Options{
+		User:        user,
+		Pass:        pass,
+		Insecure:    initInsecure,
+		Timeout:     initScanTimeout,
+		Concurrency: initScanParallel,
+	})
+	if err != nil {
+		return err
+	}
+	doc.BMCs = append(doc.BMCs, found...)
+	if err := writeInventory(initFile, doc, "bmcs"); err != nil {
+		return err
+	}
+	fmt.Printf("Scan of %s found %d new BMC(s) (%d already listed); wrote %s\n", initScan, len(found), len(known), initFile)
+	return nil
 }
 
 func init() {
-	rootCmd.AddCommand(initBmcsCmd)
-	initBmcsCmd.Flags().StringVarP(&initFile, "file", "f", "", "Output YAML file containing bmcs[] and nodes[]")
-	initBmcsCmd.Flags().StringVar(&initChassis, "chassis", "x9000c1=02:23:28:01,x9000c3=02:23:28:03", "comma-separated chassis=macprefix list")
-	initBmcsCmd.Flags().StringVar(&initBMCSubnet, "bmc-subnet", "192.168.100.0/24", "BMC subnet in CIDR notation, e.g. 192.168.100.0/24")
-	initBmcsCmd.Flags().StringVar(&initStartIP, "start-ip", "1", "Start IP allocation at this address (skips all IPs before it)")
-	initBmcsCmd.Flags().IntVar(&initNodesPerChas, "nodes-per-chassis", 32, "number of nodes per chassis")
-	initBmcsCmd.Flags().IntVar(&initNodesPerBMC, "nodes-per-bmc", 2, "number of nodes managed by each BMC")
-	initBmcsCmd.Flags().IntVar(&initStartNID, "start-nid", 1, "starting node id (1-based)")
+	rootCmd.AddCommand(initBmcsCmd, initCmd)
+	initBmcsCmd.AddCommand(initFromTemplateCmd)
+	initCmd.AddCommand(initSubBmcsCmd, initSubTemplateCmd)
+	for _, c := range []*cobra.Command{initBmcsCmd, initCmd} {
+		c.PersistentFlags().StringVarP(&initFile, "file", "f", "", "Output YAML file containing bmcs[] and nodes[]")
+		c.PersistentFlags().BoolVar(&initAppend, "append", false, "merge the generated BMCs into an existing --file instead of replacing it; nodes[] and comments are kept")
+		c.PersistentFlags().BoolVar(&initUpdate, "update-existing", false, "with --append, update the MAC, IP, and NID of BMCs already listed instead of skipping them")
+	}
+	for _, c := range []*cobra.Command{initBmcsCmd, initSubBmcsCmd} {
+		addInitBmcsFlags(c)
+	}
+}
+
+// addInitBmcsFlags adds the flags of init-bmcs, which "init bmcs" shares.
+func addInitBmcsFlags(c *cobra.Command) {
+	c.Flags().StringVar(&initChassis, "chassis", "x9000c1=02:23:28:01,x9000c3=02:23:28:03", "comma-separated chassis=macprefix[/subnet] list; a subnet (e.g. 192.168.100 or 192.168.100.0/24) overrides --bmc-subnet for that chassis")
+	c.Flags().StringVar(&initBMCSubnet, "bmc-subnet", "192.168.100.0/24", "BMC subnet in CIDR notation, e.g. 192.168.100.0/24")
+	c.Flags().StringVar(&initStartIP, "start-ip", "1", "Start IP allocation at this address, or at the Nth address of each subnet when a number N (skips all IPs before it)")
+	c.Flags().IntVar(&initNodesPerChas, "nodes-per-chassis", 32, "number of nodes per chassis")
+	c.Flags().IntVar(&initNodesPerBMC, "nodes-per-bmc", 2, "number of nodes managed by each BMC")
+	c.Flags().IntVar(&initBladeNodes, "nodes-per-blade", 4, "number of nodes on each mountain blade (one blade per slot)")
+	c.Flags().IntVar(&initSlotsPerChas, "slots-per-chassis", 8, "number of blade slots in each mountain chassis (e.g. 7 for EX2500)")
+	c.Flags().IntVar(&initStartNID, "start-nid", 1, "starting node id (1-based)")
+	c.Flags().StringVar(&initCabinetType, "cabinet-type", initbmcs.Mountain, "cabinet geometry: mountain (liquid-cooled blades) or river (one 1U server per slot)")
+	c.Flags().StringVar(&initSlots, "slots", "1-36", "with --cabinet-type river, the range of populated slots, e.g. 1-36")
+	c.Flags().BoolVar(&initControllers, "chassis-controllers", false, "also emit one chassis controller entry (e.g. x9000c1b0, MAC <prefix>:00:00) per mountain chassis")
+	c.Flags().StringVar(&initScan, "scan", "", "probe this CIDR for live Redfish BMCs and append responders to bmcs[] instead of generating from --chassis")
+	c.Flags().DurationVar(&initScanTimeout, "scan-timeout", 2*time.Second, "per-address probe timeout for --scan")
+	c.Flags().IntVar(&initScanParallel, "scan-concurrency", 64, "number of addresses probed in parallel for --scan")
+	c.Flags().BoolVar(&initInsecure, "insecure", false, "skip TLS certificate verification for BMCs (used by --scan)")
 }
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bootstrap/internal/initbmcs"
+
+	"github.com/spf13/cobra"
+)
+
+func TestInitBmcsAppend(t *testing.T) {
+	inv := filepath.Join(t.TempDir(), "inventory.yaml")
+	if err := os.WriteFile(inv, []byte(`# lab inventory
+bmcs:
+    - xname: x9000c1s0b0 # hand-fixed
+      mac: "02:23:28:01:30:00"
+      ip: 192.168.100.50
+nodes:
+    - xname: x9000c1s0b0n0
+      mac: aa:bb:cc:dd:ee:ff
+      ip: 10.0.0.1
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	initFile, initChassis, initBMCSubnet, initStartIP, initStartNID = inv, "x9000c1=02:23:28:01", "192.168.100.0/24", "1", 1
+	initNodesPerChas, initNodesPerBMC, initBladeNodes, initSlotsPerChas = 8, 2, 4, 8
+	initCabinetType, initScan, initControllers = initbmcs.Mountain, "", false
+	initAppend, initUpdate = true, false
+	t.Cleanup(func() { initAppend, initUpdate = false, false })
+
+	out, err := captureOutput(t, func() error { return initBmcsCmd.RunE(initBmcsCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "3 BMC(s) added, 1 skipped (already listed)") {
+		t.Errorf("summary: %s", out)
+	}
+	raw, _ := os.ReadFile(inv)
+	doc, err := readInventory(inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.BMCs) != 4 || doc.BMCs[0].IP != "192.168.100.50" || doc.BMCs[0].NID != 0 || len(doc.Nodes) != 1 {
+		t.Fatalf("appended inventory:\n%s", raw)
+	}
+	// The existing BMC's address is not handed out again
+	if doc.BMCs[1].Xname != "x9000c1s0b1" || doc.BMCs[1].IP != "192.168.100.1" {
+		t.Errorf("first added BMC = %+v", doc.BMCs[1])
+	}
+	if !strings.HasPrefix(string(raw), "# lab inventory\n") || !strings.Contains(string(raw), "# hand-fixed") {
+		t.Errorf("comments were dropped:\n%s", raw)
+	}
+
+	initUpdate = true
+	out, err = captureOutput(t, func() error { return initBmcsCmd.RunE(initBmcsCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "0 BMC(s) added, 4 updated") {
+		t.Errorf("summary: %s", out)
+	}
+	if doc, _ = readInventory(inv); len(doc.BMCs) != 4 || doc.BMCs[0].NID != 1 || doc.BMCs[0].IP != "192.168.100.50" {
+		t.Errorf("updated BMCs = %+v", doc.BMCs)
+	}
+
+	// A file that does not parse is never overwritten
+	if err := os.WriteFile(inv, []byte("bmcs: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := captureOutput(t, func() error { return initBmcsCmd.RunE(initBmcsCmd, nil) }); err == nil || !strings.Contains(err.Error(), "refusing to append") {
+		t.Errorf("unparsable file: err = %v", err)
+	}
+	if raw, _ := os.ReadFile(inv); string(raw) != "bmcs: [\n" {
+		t.Errorf("unparsable file was rewritten:\n%s", raw)
+	}
+}
+
+func TestInitSubcommands(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want *cobra.Command
+		ok   bool
+	}{
+		{[]string{"init", "bmcs"}, initSubBmcsCmd, true},
+		{[]string{"init", "from-template", "rack.yaml"}, initSubTemplateCmd, true},
+		{[]string{"init-bmcs", "from-template", "rack.yaml"}, initFromTemplateCmd, true},
+		// A mistyped subcommand must not run init-bmcs, which overwrites --file
+		{[]string{"init", "from-tempalte", "rack.yaml"}, initCmd, false},
+		{[]string{"init", "bmcs", "extra"}, initSubBmcsCmd, false},
+		{[]string{"init-bmcs", "bmcs"}, initBmcsCmd, false},
+	} {
+		cmd, args, err := rootCmd.Find(tc.args)
+		if err != nil || cmd != tc.want {
+			t.Errorf("%v: found %s (%v), want %s", tc.args, cmd.CommandPath(), err, tc.want.CommandPath())
+			continue
+		}
+		if err := cmd.ValidateArgs(args); (err == nil) != tc.ok {
+			t.Errorf("%v: ValidateArgs(%q) = %v", tc.args, args, err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	hwFile      string
+	hwHostsCSV  string
+	hwHostsFile string
+	hwInsecure  bool
+	hwTimeout   time.Duration
+	hwBatchSize int
+	hwOutput    string
+)
+
+// hardwareRow is one node of the `inventory hardware` report. A BMC that
+// could not be read at all has a single row with Error set.
+type hardwareRow struct {
+	Node           string  `json:"node"`
+	BMC            string  `json:"bmc"`
+	System         string  `json:"system,omitempty"`
+	Model          string  `json:"model,omitempty"`
+	Processors     int     `json:"processors"`
+	ProcessorModel string  `json:"processor_model,omitempty"`
+	Cores          int     `json:"cores"`
+	MemoryGiB      float64 `json:"memory_gib"`
+	NICs           int     `json:"nics"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// hardwareTotals sums the rows without an error.
+type hardwareTotals struct {
+	Nodes      int     `json:"nodes"`
+	Processors int     `json:"processors"`
+	Cores      int     `json:"cores"`
+	MemoryTiB  float64 `json:"memory_tib"`
+	NICs       int     `json:"nics"`
+	Failed     int     `json:"failed"`
+}
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Report on the hardware behind the BMCs",
+}
+
+var inventoryHardwareCmd = &cobra.Command{
+	Use:   "hardware",
+	Short: "Report CPU, memory, and NIC counts per node",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if hwOutput != "table" && hwOutput != "csv" && hwOutput != "json" {
+			return fmt.Errorf("--output must be table, csv, or json")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(hwFile, hwHostsCSV, hwHostsFile)
+		if err != nil {
+			return err
+		}
+
+		// Rows are kept per target so the report follows the target order
+		byTarget := make([][]hardwareRow, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		forEachTarget(cmd.Context(), targets, hwBatchSize, hwTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.GetHardware(ctx, t.Host, user, pass, hwInsecure, hwTimeout)
+			byTarget[index[t]] = hardwareRows(t, systems, err)
+		})
+
+		var rows []hardwareRow
+		var totals hardwareTotals
+		for _, list := range byTarget {
+			for _, r := range list {
+				rows = append(rows, r)
+				if r.Error != "" {
+					totals.Failed++
+					continue
+				}
+				totals.Nodes++
+				totals.Processors += r.Processors
+				totals.Cores += r.Cores
+				totals.MemoryTiB += r.MemoryGiB / 1024
+				totals.NICs += r.NICs
+			}
+		}
+
+		switch hwOutput {
+		case "json":
+			out, err := json.MarshalIndent(struct {
+				Nodes  []hardwareRow  `json:"nodes"`
+				Totals hardwareTotals `json:"totals"`
+			}{rows, totals}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		case "csv":
+			w := csv.NewWriter(os.Stdout)
+			_ = w.Write([]string{"node", "bmc", "system", "model", "processors", "processor_model", "cores", "memory_gib", "nics", "error"})
+			for _, r := range rows {
+				_ = w.Write([]string{r.Node, r.BMC, r.System, r.Model, strconv.Itoa(r.Processors), r.ProcessorModel,
+					strconv.Itoa(r.Cores), strconv.FormatFloat(r.MemoryGiB, 'f', -1, 64), strconv.Itoa(r.NICs), r.Error})
+			}
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return err
+			}
+		default:
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NODE\tBMC\tSYSTEM\tCPUS\tCORES\tMEMORY\tNICS\tERROR")
+			for _, r := range rows {
+				if r.Error != "" {
+					fmt.Fprintf(tw, "%s\t%s\t%s\t-\t-\t-\t-\t%s\n", r.Node, r.BMC, valueOrDash(r.System), r.Error)
+					continue
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s GiB\t%d\t-\n", r.Node, r.BMC, r.System, r.Processors,
+					countOrDash(r.Cores), strconv.FormatFloat(r.MemoryGiB, 'f', -1, 64), r.NICs)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Printf("Total: %d node(s), %d CPU(s), %d core(s), %.2f TiB memory, %d NIC(s); %d failed\n",
+				totals.Nodes, totals.Processors, totals.Cores, totals.MemoryTiB, totals.NICs, totals.Failed)
+		}
+
+		if err := checkOutcome(totals.Nodes, totals.Failed, "hardware could not be read for %d node(s) or BMC(s)", totals.Failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// hardwareRows turns the systems of BMC t (or the error reading them) into
+// report rows named after the node xnames derived from t's xname or, without
+// one, after the host and system.
+func hardwareRows(t bmcTarget, systems []redfish.SystemHardware, err error) []hardwareRow {
+	if err != nil {
+		return []hardwareRow{{Node: t.label(), BMC: t.Host, Error: err.Error()}}
+	}
+	bmcXname := ""
+	if x, err := xname.Parse(t.Xname); err == nil && x.Kind == xname.KindBMC {
+		bmcXname = x.String()
+	}
+	rows := make([]hardwareRow, 0, len(systems))
+	for i, s := range systems {
+		r := hardwareRow{
+			Node:           t.Host + " " + path.Base(s.SystemPath),
+			BMC:            t.Host,
+			System:         path.Base(s.SystemPath),
+			Model:          s.Model,
+			Processors:     s.Processors,
+			ProcessorModel: s.ProcessorModel,
+			Cores:          s.Cores,
+			MemoryGiB:      s.MemoryGiB,
+			NICs:           s.NICs,
+		}
+		if bmcXname != "" {
+			r.Node = xname.BMCXnameToNodeN(bmcXname, i)
+		}
+		if s.Err != nil {
+			r.Error = s.Err.Error()
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+// countOrDash renders a count the BMC may not have reported.
+func countOrDash(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.Itoa(n)
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+	inventoryCmd.AddCommand(inventoryHardwareCmd)
+	inventoryCmd.PersistentFlags().StringVarP(&hwFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	inventoryCmd.PersistentFlags().StringVar(&hwHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	inventoryCmd.PersistentFlags().StringVar(&hwHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	inventoryCmd.PersistentFlags().BoolVar(&hwInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	inventoryCmd.PersistentFlags().DurationVar(&hwTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	inventoryCmd.PersistentFlags().IntVar(&hwBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	inventoryHardwareCmd.Flags().StringVarP(&hwOutput, "output", "o", "table", "output format: table, csv, or json")
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInventoryHardwareReport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"},{"@odata.id":"/redfish/v1/Systems/Node1"}]}`))
+		case "/redfish/v1/Systems/Node0", "/redfish/v1/Systems/Node1":
+			_, _ = w.Write([]byte(`{"ProcessorSummary":{"Count":2,"CoreCount":64},"MemorySummary":{"TotalSystemMemoryGiB":512}}`))
+		case "/redfish/v1/Systems/Node0/EthernetInterfaces", "/redfish/v1/Systems/Node1/EthernetInterfaces":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0/EthernetInterfaces/1"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	hosts := filepath.Join(t.TempDir(), "hosts.txt")
+	content := strings.TrimPrefix(srv.URL, "https://") + ",x9000c1s0b0\n127.0.0.1:1,x9000c1s1b0\n"
+	if err := os.WriteFile(hosts, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	hwHostsFile, hwInsecure = hosts, true
+	defer func() { hwHostsFile, hwInsecure, hwOutput = "", false, "table" }()
+
+	inventoryHardwareCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return inventoryHardwareCmd.RunE(inventoryHardwareCmd, nil) })
+	var oe *outcomeError
+	if !errors.As(err, &oe) || oe.code != exitPartial {
+		t.Fatalf("expected a partial failure, got %v\n%s", err, out)
+	}
+	for _, want := range []string{"x9000c1s0b0n0", "x9000c1s0b0n1", "x9000c1s1b0", "connect", "Total: 2 node(s), 4 CPU(s), 128 core(s), 1.00 TiB memory, 2 NIC(s); 1 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table output missing %q:\n%s", want, out)
+		}
+	}
+
+	hwOutput = "json"
+	out, _ = captureOutput(t, func() error { return inventoryHardwareCmd.RunE(inventoryHardwareCmd, nil) })
+	var report struct {
+		Nodes  []hardwareRow
+		Totals hardwareTotals
+	}
+	if err := json.Unmarshal([]byte(out[strings.Index(out, "{"):]), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(report.Nodes) != 3 || report.Nodes[2].Error == "" || report.Totals.Cores != 128 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	ledFile      string
+	ledHostsCSV  string
+	ledHostsFile string
+	ledXnames    []string
+	ledInsecure  bool
+	ledTimeout   time.Duration
+	ledBatchSize int
+	ledDryRun    bool
+)
+
+var ledCmd = &cobra.Command{
+	Use:   "led",
+	Short: "Turn the locator LED of the selected BMCs on, off, or blinking",
+	Long: `Set or read the locator LED ("blink the light") of the selected BMCs. The
+LED is the IndicatorLED or LocationIndicatorActive property of the first
+Chassis that has one, or else of the first ComputerSystem.
+
+--xname narrows the targets to the BMCs within the given cabinet, chassis,
+slot, or BMC xnames, or to the BMC of a node xname.`,
+}
+
+// ledSetCmd returns the command that sets the LED to state.
+func ledSetCmd(use, state string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: fmt.Sprintf("Set the locator LED to %s", state),
+		RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+			user, pass, err := redfishCredentials()
+			if err != nil {
+				return err
+			}
+			targets, err := ledTargets()
+			if err != nil {
+				return err
+			}
+			if ledDryRun {
+				for _, t := range targets {
+					fmt.Printf("[dry-run] would set the locator LED of %s (%s) to %s\n", t.label(), t.Host, state)
+				}
+				return nil
+			}
+
+			var mu sync.Mutex
+			var ok, failed int
+			forEachTarget(cmd.Context(), targets, ledBatchSize, ledTimeout, func(ctx context.Context, t bmcTarget) {
+				was, err := redfish.SetIndicator(ctx, t.Host, user, pass, ledInsecure, ledTimeout, state)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					failed++
+					diag.Warnf("%s: led %s: %v", t.label(), use, err)
+					return
+				}
+				ok++
+				diag.Infof("%s: %s LED %s -> %s", t.label(), was.Path, was.State, state)
+			})
+
+			fmt.Printf("LED %s: %d succeeded, %d failed\n", use, ok, failed)
+			if err := checkOutcome(ok, failed, "led %s failed for %d BMC(s)", use, failed); err != nil {
+				return err
+			}
+			return checkInterrupted(cmd.Context())
+		},
+	}
+}
+
+var ledStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the locator LED state of the selected BMCs",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := ledTargets()
+		if err != nil {
+			return err
+		}
+
+		// Results are kept per target so output follows inventory order
+		results := make([]*redfish.Indicator, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		var mu sync.Mutex
+		var ok, unsupported, failed int
+		forEachTarget(cmd.Context(), targets, ledBatchSize, ledTimeout, func(ctx context.Context, t bmcTarget) {
+			ind, err := redfish.GetIndicator(ctx, t.Host, user, pass, ledInsecure, ledTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrNoIndicator):
+				unsupported++
+				diag.Infof("%s: %v", t.label(), err)
+			case err != nil:
+				failed++
+				diag.Warnf("%s: led status: %v", t.label(), err)
+			default:
+				ok++
+				results[index[t]] = &ind
+			}
+		})
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "HOST\tRESOURCE\tLED")
+		for i, ind := range results {
+			if ind != nil {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", targets[i].label(), ind.Path, ind.State)
+			}
+		}
+		if ok > 0 {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("LED status: %d read, %d without a locator LED, %d failed\n", ok, unsupported, failed)
+		if err := checkOutcome(ok+unsupported, failed, "reading the locator LED failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// ledTargets resolves the hosts of the led commands and applies --xname.
+func ledTargets() ([]bmcTarget, error) {
+	targets, err := resolveHosts(ledFile, ledHostsCSV, ledHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	return filterByXname(targets, ledXnames)
+}
+
+func init() {
+	rootCmd.AddCommand(ledCmd)
+	ledCmd.AddCommand(ledSetCmd("on", redfish.IndicatorLit), ledSetCmd("off", redfish.IndicatorOff),
+		ledSetCmd("blink", redfish.IndicatorBlinking), ledStatusCmd)
+	ledCmd.PersistentFlags().StringVarP(&ledFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	ledCmd.PersistentFlags().StringVar(&ledHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	ledCmd.PersistentFlags().StringVar(&ledHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	ledCmd.PersistentFlags().StringSliceVar(&ledXnames, "xname", nil, "only the BMCs within these cabinet, chassis, slot, BMC, or node xnames (comma-separated or repeated)")
+	ledCmd.PersistentFlags().BoolVar(&ledInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	ledCmd.PersistentFlags().DurationVar(&ledTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	ledCmd.PersistentFlags().IntVar(&ledBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	ledCmd.PersistentFlags().BoolVar(&ledDryRun, "dry-run", false, "plan only: print which LEDs would be set")
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/inventory"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	mergeOut        string
+	mergeOnConflict string
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <file.yaml>... --out <merged.yaml>",
+	Short: "Combine several inventory files into one, reporting conflicts",
+	Long: `Concatenate bmcs[] and nodes[] of the given inventory files into --out,
+sorted by xname. The same xname with a different MAC or IP, and one MAC or IP
+used by two xnames, are conflicts; --on-conflict chooses whether they fail
+the merge (error) or the entry from the earlier (first-wins) or later
+(last-wins) file is kept.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if mergeOut == "" {
+			return fmt.Errorf("--out is required")
+		}
+		sources := make([]inventory.Source, 0, len(args))
+		for _, path := range args {
+			doc, err := readInventory(path)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, inventory.Source{Name: path, Doc: doc})
+		}
+		res, err := inventory.Merge(sources, mergeOnConflict)
+		for _, c := range res.Conflicts {
+			diag.Warnf("conflict: %s", c)
+		}
+		if err != nil {
+			if len(res.Conflicts) > 0 {
+				return fmt.Errorf("%w; nothing written (use --on-conflict first-wins or last-wins to resolve them)", err)
+			}
+			return err
+		}
+		out, err := yaml.Marshal(&res.Doc)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(mergeOut, out, 0o644); err != nil {
+			return err
+		}
+		resolved := ""
+		if len(res.Conflicts) > 0 {
+			resolved = fmt.Sprintf(", %d conflict(s) resolved %s", len(res.Conflicts), mergeOnConflict)
+		}
+		fmt.Printf("Merged %d file(s) into %s: %d BMC(s), %d node(s)%s\n",
+			len(args), mergeOut, len(res.Doc.BMCs), len(res.Doc.Nodes), resolved)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().StringVarP(&mergeOut, "out", "o", "", "path to write the merged inventory to")
+	mergeCmd.Flags().StringVar(&mergeOnConflict, "on-conflict", inventory.OnConflictError, "conflict handling: error, first-wins, or last-wins")
+}
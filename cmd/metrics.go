@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// metricPrefix starts the name of every metric in the --metrics-out file.
+const metricPrefix = "ochami_bootstrap_"
+
+// metricHelp is the HELP text of every metric a command may set, by name
+// without metricPrefix. Metrics are counters of what one run did, except
+// for the gauges listed in metricGauges.
+var metricHelp = map[string]string{
+	"bmcs_total":                   "BMCs targeted by the run.",
+	"bmcs_failed":                  "BMCs that could not be queried or updated.",
+	"nodes_discovered":             "Nodes found by discovery in the run.",
+	"ips_allocated":                "Node IPs newly allocated by discovery.",
+	"firmware_updates_triggered":   "Firmware updates started.",
+	"firmware_updates_failed":      "Firmware updates that could not be started or failed.",
+	"firmware_targets_total":       "Firmware targets whose version was read.",
+	"firmware_targets_compliant":   "Firmware targets at --expected-version.",
+	"firmware_updates_in_progress": "Firmware targets with an update in progress.",
+	"run_duration_seconds":         "Wall time of the run.",
+	"run_exit_status":              "Exit status of the run.",
+}
+
+var metricGauges = map[string]bool{"firmware_updates_in_progress": true, "run_duration_seconds": true, "run_exit_status": true}
+
+// runMetrics collects the metrics of this run for --metrics-out.
+var runMetrics struct {
+	sync.Mutex
+	command string
+	start   time.Time
+	values  map[string]float64
+}
+
+// startMetrics resets the metrics for a run of cmd.
+func startMetrics(cmd *cobra.Command) {
+	runMetrics.Lock()
+	defer runMetrics.Unlock()
+	runMetrics.command = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	runMetrics.start = time.Now()
+	runMetrics.values = map[string]float64{}
+}
+
+// setMetric records the value of a metric named in metricHelp.
+func setMetric(name string, v float64) {
+	runMetrics.Lock()
+	defer runMetrics.Unlock()
+	if runMetrics.values == nil {
+		runMetrics.values = map[string]float64{}
+	}
+	runMetrics.values[name] = v
+}
+
+// formatMetrics renders the metrics of the run, with its duration and exit
+// status, in the Prometheus text format, each labeled with the command.
+func formatMetrics(exitStatus int) string {
+	runMetrics.Lock()
+	defer runMetrics.Unlock()
+	values := map[string]float64{"run_exit_status": float64(exitStatus)}
+	if !runMetrics.start.IsZero() {
+		values["run_duration_seconds"] = time.Since(runMetrics.start).Seconds()
+	}
+	for k, v := range runMetrics.values {
+		values[k] = v
+	}
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	for _, name := range names {
+		kind := "counter"
+		if metricGauges[name] {
+			kind = "gauge"
+		}
+		fmt.Fprintf(&b, "# HELP %s%s %s\n", metricPrefix, name, metricHelp[name])
+		fmt.Fprintf(&b, "# TYPE %s%s %s\n", metricPrefix, name, kind)
+		fmt.Fprintf(&b, "%s%s{command=%q} %g\n", metricPrefix, name, runMetrics.command, values[name])
+	}
+	return b.String()
+}
+
+// writeMetrics replaces path with the metrics of the run, so the
+// node_exporter textfile collector never reads a partial file.
+func writeMetrics(path string, exitStatus int) error {
+	return writeFileAtomic(path, []byte(formatMetrics(exitStatus)))
+}
@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	startMetrics(firmwareStatusCmd)
+	setMetric("bmcs_total", 300)
+	setMetric("bmcs_failed", 2)
+	path := t.TempDir() + "/bootstrap.prom"
+	if err := writeMetrics(path, exitPartial); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(raw)
+	for _, want := range []string{
+		"# HELP ochami_bootstrap_bmcs_failed BMCs that could not be queried or updated.\n# TYPE ochami_bootstrap_bmcs_failed counter\nochami_bootstrap_bmcs_failed{command=\"firmware status\"} 2\n",
+		"ochami_bootstrap_bmcs_total{command=\"firmware status\"} 300\n",
+		"# TYPE ochami_bootstrap_run_duration_seconds gauge\nochami_bootstrap_run_duration_seconds{command=\"firmware status\"} ",
+		"ochami_bootstrap_run_exit_status{command=\"firmware status\"} 2\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics file lacks %q:\n%s", want, got)
+		}
+	}
+	// Metrics are sorted by name so the file diffs cleanly between runs
+	if strings.Index(got, "bmcs_failed") > strings.Index(got, "bmcs_total") {
+		t.Errorf("metrics are not sorted:\n%s", got)
+	}
+}
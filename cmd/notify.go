@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/httptimeout"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyWebhook string
+	notifyOn      string
+	notifyFormat  string
+)
+
+// notifyTimeout bounds the POST of a notification.
+const notifyTimeout = 15 * time.Second
+
+// notifySlackHosts is how many failed hosts a Slack message names before
+// summing up the rest.
+const notifySlackHosts = 20
+
+// runNotice holds what a --notify-webhook summary reports beyond the
+// metrics of the run. Only the commands that notify set it, through
+// noteFailures.
+var runNotice struct {
+	sync.Mutex
+	set        bool
+	failed     []string       // xnames, or addresses of BMCs without one
+	categories map[string]int // failures by redfish.Categorize category
+}
+
+// noteFailures records the BMCs a run could not handle, and their failures
+// by category, for the --notify-webhook summary sent when the run ends. A
+// later call replaces an earlier one, so a --watch loop reports its last
+// pass.
+func noteFailures(failed []string, categories map[string]int) {
+	runNotice.Lock()
+	defer runNotice.Unlock()
+	runNotice.set = true
+	runNotice.failed = slices.Clone(failed)
+	runNotice.categories = categories
+}
+
+// addNotifyFlags adds the --notify-* flags to a command that calls
+// noteFailures.
+func addNotifyFlags(c *cobra.Command) {
+	c.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "POST a JSON summary of the run (command, result, counts, duration, failed xnames, failure categories) to this URL when it ends")
+	c.Flags().StringVar(&notifyOn, "notify-on", "all", "when to send --notify-webhook: all or failure (any non-zero exit status)")
+	c.Flags().StringVar(&notifyFormat, "notify-format", "json", "--notify-webhook payload: json, or slack for a Slack-compatible {\"text\": ...} message")
+}
+
+// checkNotifyFlags validates the --notify-* flags before a command runs.
+func checkNotifyFlags() error {
+	if notifyOn != "all" && notifyOn != "failure" {
+		return fmt.Errorf("--notify-on must be all or failure, got %q", notifyOn)
+	}
+	if notifyFormat != "json" && notifyFormat != "slack" {
+		return fmt.Errorf("--notify-format must be json or slack, got %q", notifyFormat)
+	}
+	if notifyWebhook == "" {
+		return nil
+	}
+	u, err := url.Parse(notifyWebhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("--notify-webhook must be an http:// or https:// URL")
+	}
+	return nil
+}
+
+// notifySummary is the --notify-format json payload. It carries no
+// credentials or error messages: only xnames, counts, and categories.
+type notifySummary struct {
+	Command           string             `json:"command"`
+	Result            string             `json:"result"` // success, partial, failure, or interrupted
+	ExitStatus        int                `json:"exit_status"`
+	DurationSeconds   float64            `json:"duration_seconds"`
+	Counts            map[string]float64 `json:"counts"`
+	FailedHosts       []string           `json:"failed_hosts"`
+	FailureCategories map[string]int     `json:"failure_categories,omitempty"`
+}
+
+// buildNotifySummary collects the summary of the run that ended with
+// exitStatus.
+func buildNotifySummary(exitStatus int) notifySummary {
+	s := notifySummary{ExitStatus: exitStatus, Counts: map[string]float64{}, FailedHosts: []string{}}
+	switch exitStatus {
+	case 0:
+		s.Result = "success"
+	case exitPartial:
+		s.Result = "partial"
+	case exitInterrupted:
+		s.Result = "interrupted"
+	default:
+		s.Result = "failure"
+	}
+	runMetrics.Lock()
+	s.Command = runMetrics.command
+	if !runMetrics.start.IsZero() {
+		s.DurationSeconds = time.Since(runMetrics.start).Seconds()
+	}
+	for k, v := range runMetrics.values {
+		s.Counts[k] = v
+	}
+	runMetrics.Unlock()
+	runNotice.Lock()
+	s.FailedHosts = append(s.FailedHosts, runNotice.failed...)
+	s.FailureCategories = runNotice.categories
+	runNotice.Unlock()
+	return s
+}
+
+// slackText renders s as the text of a Slack message.
+func (s notifySummary) slackText() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: %s (exit status %d) after %s", rootCmd.Name(), s.Command, s.Result, s.ExitStatus,
+		(time.Duration(s.DurationSeconds) * time.Second).Round(time.Second))
+	names := make([]string, 0, len(s.Counts))
+	for k := range s.Counts {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	var counts []string
+	for _, k := range names {
+		counts = append(counts, fmt.Sprintf("%s %g", k, s.Counts[k]))
+	}
+	if len(counts) > 0 {
+		fmt.Fprintf(&b, "\nCounts: %s", strings.Join(counts, ", "))
+	}
+	var categories []string
+	for _, c := range redfish.Categories {
+		if s.FailureCategories[c] > 0 {
+			categories = append(categories, fmt.Sprintf("%s %d", c, s.FailureCategories[c]))
+		}
+	}
+	if len(categories) > 0 {
+		fmt.Fprintf(&b, "\nFailures by category: %s", strings.Join(categories, ", "))
+	}
+	if n := len(s.FailedHosts); n > 0 {
+		shown := s.FailedHosts[:min(n, notifySlackHosts)]
+		fmt.Fprintf(&b, "\nFailed (%d): %s", n, strings.Join(shown, ", "))
+		if n > len(shown) {
+			fmt.Fprintf(&b, " and %d more", n-len(shown))
+		}
+	}
+	return b.String()
+}
+
+// notify POSTs the summary of the run that ended with exitStatus to
+// --notify-webhook, if the command noted one and --notify-on allows it. A
+// failed notification is only a warning: it never changes the exit status.
+// Warnings leave out the URL, whose path is often the webhook's secret.
+func notify(exitStatus int) {
+	runNotice.Lock()
+	set := runNotice.set
+	runNotice.Unlock()
+	if notifyWebhook == "" || !set || (notifyOn == "failure" && exitStatus == 0) {
+		return
+	}
+	s := buildNotifySummary(exitStatus)
+	var payload any = s
+	if notifyFormat == "slack" {
+		payload = map[string]string{"text": s.slackText()}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		diag.Warnf("--notify-webhook: %v", err)
+		return
+	}
+	// The run's context may be cancelled by now; an interrupted run is
+	// still reported
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifyWebhook, bytes.NewReader(body))
+	if err != nil {
+		diag.Warnf("--notify-webhook: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Transport: httptimeout.Apply(&http.Transport{Proxy: http.ProxyFromEnvironment})}
+	resp, err := client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		diag.Warnf("--notify-webhook: POST to %s: %v", req.URL.Host, err)
+		return
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode >= 300 {
+		diag.Warnf("--notify-webhook: POST to %s: %s", req.URL.Host, resp.Status)
+	}
+}
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// webhook returns a server that records the bodies POSTed to it and
+// answers with status.
+func webhook(t *testing.T, status int) (string, *[]string) {
+	t.Helper()
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/services/T000/B000/secret", &bodies
+}
+
+func TestNotifyAfterFirmwareUpdate(t *testing.T) {
+	url, bodies := webhook(t, http.StatusOK)
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	t.Cleanup(func() {
+		fwHostsCSV, fwImageURI, fwDeadline, fwTargets, fwYes = "", "", "", nil, false
+		notifyWebhook, notifyOn, notifyFormat = "", "all", "json"
+		noteFailures(nil, nil)
+	})
+	// Hosts in TEST-NET are never contacted: the deadline has already passed
+	fwHostsCSV = "192.0.2.1,192.0.2.2"
+	fwImageURI = "http://example.com/fw.bin"
+	fwTargets = []string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}
+	fwDeadline = "2001-01-01T00:00:00Z"
+	fwYes = true
+	notifyWebhook = url
+
+	startMetrics(firmwareCmd)
+	firmwareCmd.SetContext(context.Background())
+	if out, err := captureOutput(t, func() error { return firmwareCmd.RunE(firmwareCmd, nil) }); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	notify(0)
+	if len(*bodies) != 1 {
+		t.Fatalf("webhook got %d POSTs, want 1", len(*bodies))
+	}
+	var got notifySummary
+	if err := json.Unmarshal([]byte((*bodies)[0]), &got); err != nil {
+		t.Fatalf("payload is not JSON: %v\n%s", err, (*bodies)[0])
+	}
+	if got.Command != "firmware" || got.Result != "success" || got.Counts["bmcs_total"] != 2 ||
+		strings.Join(got.FailedHosts, ",") != "192.0.2.1,192.0.2.2" {
+		t.Errorf("summary = %+v", got)
+	}
+	if strings.Contains((*bodies)[0], "pass") || strings.Contains((*bodies)[0], "example.com") {
+		t.Errorf("payload carries more than xnames, counts, and categories:\n%s", (*bodies)[0])
+	}
+
+	// --notify-on failure stays quiet on success
+	notifyOn = "failure"
+	notify(0)
+	if len(*bodies) != 1 {
+		t.Errorf("--notify-on failure posted after a successful run")
+	}
+}
+
+func TestNotifySlackAndFailures(t *testing.T) {
+	url, bodies := webhook(t, http.StatusOK)
+	t.Cleanup(func() {
+		notifyWebhook, notifyOn, notifyFormat = "", "all", "json"
+		noteFailures(nil, nil)
+	})
+	notifyWebhook, notifyOn, notifyFormat = url, "failure", "slack"
+	startMetrics(discoverCmd)
+	setMetric("bmcs_total", 40)
+	setMetric("bmcs_failed", 25)
+	var failed []string
+	for i := range 25 {
+		failed = append(failed, fmt.Sprintf("x9000c1s%db0", i))
+	}
+	noteFailures(failed, map[string]int{"unreachable": 24, "auth": 1})
+
+	notify(exitPartial)
+	if len(*bodies) != 1 {
+		t.Fatalf("webhook got %d POSTs, want 1", len(*bodies))
+	}
+	var msg struct{ Text string }
+	if err := json.Unmarshal([]byte((*bodies)[0]), &msg); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"ochami_bootstrap discover: partial (exit status 2) after ",
+		"Counts: bmcs_failed 25, bmcs_total 40",
+		"Failures by category: unreachable 24, auth 1",
+		"Failed (25): x9000c1s0b0, x9000c1s1b0, ",
+		" and 5 more",
+	} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("Slack text lacks %q:\n%s", want, msg.Text)
+		}
+	}
+
+	// A webhook that fails is only a warning, which names the host but not
+	// the secret path
+	failing, _ := webhook(t, http.StatusInternalServerError)
+	notifyWebhook = failing
+	out, _ := captureOutput(t, func() error { notify(exitFailure); return nil })
+	if !strings.Contains(out, "--notify-webhook: POST to 127.0.0.1:") || !strings.Contains(out, "500") || strings.Contains(out, "secret") {
+		t.Errorf("warning = %q", out)
+	}
+}
+
+func TestCheckNotifyFlags(t *testing.T) {
+	t.Cleanup(func() { notifyWebhook, notifyOn, notifyFormat = "", "all", "json" })
+	for _, c := range []struct{ webhook, on, format, err string }{
+		{"", "all", "json", ""},
+		{"https://hooks.example.com/x", "failure", "slack", ""},
+		{"", "sometimes", "json", "--notify-on"},
+		{"", "all", "xml", "--notify-format"},
+		{"hooks.example.com/x", "all", "json", "--notify-webhook"},
+	} {
+		notifyWebhook, notifyOn, notifyFormat = c.webhook, c.on, c.format
+		err := checkNotifyFlags()
+		if (c.err == "") != (err == nil) || err != nil && !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%+v: err = %v", c, err)
+		}
+	}
+}
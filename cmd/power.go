@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pwrFile      string
+	pwrHostsCSV  string
+	pwrHostsFile string
+	pwrInsecure  bool
+	pwrTimeout   time.Duration
+	pwrDryRun    bool
+	pwrBatchSize int
+	pwrGraceful  bool
+	pwrXnames    []string
+	pwrYes       bool
+)
+
+var powerCmd = &cobra.Command{
+	Use:   "power",
+	Short: "Control node power via Redfish ComputerSystem.Reset",
+}
+
+var powerOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Power on every system on the selected BMCs",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		return runPowerReset(cmd, "on", "On")
+	},
+}
+
+var powerOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Power off every system on the selected BMCs (ForceOff unless --graceful)",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		resetType := "ForceOff"
+		if pwrGraceful {
+			resetType = "GracefulShutdown"
+		}
+		return runPowerReset(cmd, "off", resetType)
+	},
+}
+
+var powerCycleCmd = &cobra.Command{
+	Use:   "cycle",
+	Short: "Power cycle every system on the selected BMCs (ForceRestart)",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		return runPowerReset(cmd, "cycle", "ForceRestart")
+	},
+}
+
+var powerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the PowerState of every system on the selected BMCs",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		sel, err := selectTargets(pwrFile, pwrHostsCSV, pwrHostsFile, pwrXnames)
+		if err != nil {
+			return err
+		}
+
+		if err := preflightCredentials(cmd.Context(), sel.targets, user, pass, pwrInsecure, pwrTimeout, preflightSample); err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var ok, failed int
+		forEachTarget(cmd.Context(), sel.targets, pwrBatchSize, pwrTimeout, func(ctx context.Context, t bmcTarget) {
+			systems, err := redfish.GetPowerStates(ctx, t.Host, user, pass, pwrInsecure, pwrTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: power status: %v", t.label(), err)
+				return
+			}
+			for _, s := range systems {
+				if s.Err != nil {
+					failed++
+					diag.Warnf("%s %s: power status: %v", t.label(), path.Base(s.SystemPath), s.Err)
+					continue
+				}
+				ok++
+				fmt.Printf("%s %s: %s\n", t.label(), path.Base(s.SystemPath), s.PowerState)
+			}
+		})
+
+		fmt.Printf("Power status: %d system(s) reported, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "power status failed for %d system(s) or BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// runPowerReset posts resetType to every system on the selected BMCs and
+// reports one line per system followed by a summary. Powering off or
+// cycling asks for confirmation first unless --yes is given.
+func runPowerReset(cmd *cobra.Command, action, resetType string) error {
+	user, pass, err := redfishCredentials()
+	if err != nil {
+		return err
+	}
+	sel, err := selectTargets(pwrFile, pwrHostsCSV, pwrHostsFile, pwrXnames)
+	if err != nil {
+		return err
+	}
+
+	if pwrDryRun {
+		for _, t := range sel.targets {
+			fmt.Printf("[dry-run] would POST ComputerSystem.Reset ResetType=%s to every system on %s (%s)\n", resetType, t.label(), t.Host)
+		}
+		return nil
+	}
+	if action != "on" {
+		if err := confirmDestructive("power "+action+" every system", sel, []string{"ResetType=" + resetType}, pwrYes); err != nil {
+			return err
+		}
+	}
+	if err := preflightCredentials(cmd.Context(), sel.targets, user, pass, pwrInsecure, pwrTimeout, preflightSample); err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var ok, failed int
+	forEachTarget(cmd.Context(), sel.targets, pwrBatchSize, pwrTimeout, func(ctx context.Context, t bmcTarget) {
+		systems, err := redfish.ResetSystems(ctx, t.Host, user, pass, pwrInsecure, pwrTimeout, resetType)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed++
+			diag.Warnf("%s: power %s: %v", t.label(), action, err)
+			return
+		}
+		for _, s := range systems {
+			if s.Err != nil {
+				failed++
+				diag.Warnf("%s %s: power %s: %v", t.label(), path.Base(s.SystemPath), action, s.Err)
+				continue
+			}
+			ok++
+			diag.Infof("%s %s: %s requested", t.label(), path.Base(s.SystemPath), resetType)
+		}
+	})
+
+	fmt.Printf("Power %s: %d system(s) succeeded, %d failed\n", action, ok, failed)
+	if err := checkOutcome(ok, failed, "power %s failed for %d system(s) or BMC(s)", action, failed); err != nil {
+		return err
+	}
+	return checkInterrupted(cmd.Context())
+}
+
+func init() {
+	rootCmd.AddCommand(powerCmd)
+	powerCmd.AddCommand(powerOnCmd, powerOffCmd, powerCycleCmd, powerStatusCmd)
+	powerCmd.PersistentFlags().StringVarP(&pwrFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	powerCmd.PersistentFlags().StringVar(&pwrHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	powerCmd.PersistentFlags().StringVar(&pwrHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	powerCmd.PersistentFlags().BoolVar(&pwrInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	powerCmd.PersistentFlags().DurationVar(&pwrTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	powerCmd.PersistentFlags().BoolVar(&pwrDryRun, "dry-run", false, "plan only: print reset actions without posting")
+	powerCmd.PersistentFlags().IntVar(&pwrBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	addPreflightFlag(powerCmd.PersistentFlags())
+	powerCmd.PersistentFlags().StringSliceVar(&pwrXnames, "xname", nil, "only the BMCs within these cabinet, chassis, slot, BMC, or node xnames (comma-separated or repeated)")
+	powerOffCmd.Flags().BoolVar(&pwrGraceful, "graceful", false, "use GracefulShutdown instead of ForceOff")
+	for _, c := range []*cobra.Command{powerOffCmd, powerCycleCmd} {
+		c.Flags().BoolVarP(&pwrYes, "yes", "y", false, "skip the confirmation prompt")
+	}
+}
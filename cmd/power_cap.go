@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	capOutput string
+	capWatts  int
+	capClear  bool
+)
+
+// powerCapRow is one chassis of the power cap report.
+type powerCapRow struct {
+	BMC           string   `json:"bmc"`
+	Xname         string   `json:"xname,omitempty"`
+	Chassis       string   `json:"chassis"`
+	Source        string   `json:"source"`
+	LimitWatts    *float64 `json:"limit_watts"`
+	ConsumedWatts *float64 `json:"consumed_watts,omitempty"`
+	MinWatts      *float64 `json:"min_watts,omitempty"`
+	MaxWatts      *float64 `json:"max_watts,omitempty"`
+}
+
+var powerCapCmd = &cobra.Command{
+	Use:   "cap",
+	Short: "Read or set the power limit of every chassis on the selected BMCs",
+	Long: `Read or set the power limit ("power cap") of every chassis on the selected
+BMCs. The limit is the PowerLimitWatts of the chassis' EnvironmentMetrics
+when it has one, else the PowerLimit of the first PowerControl entry of its
+Power resource. BMCs with neither are reported as unsupported.
+
+--xname narrows the targets to the BMCs within the given cabinet, chassis,
+slot, or BMC xnames, or to the BMC of a node xname.`,
+}
+
+var powerCapGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Print the power limit of every chassis on the selected BMCs",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if capOutput != "table" && capOutput != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := powerCapTargets()
+		if err != nil {
+			return err
+		}
+
+		// Limits are kept per target so output follows inventory order
+		results := make([][]redfish.PowerLimit, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		var mu sync.Mutex
+		var read, unsupported, failed int
+		forEachTarget(cmd.Context(), targets, pwrBatchSize, pwrTimeout, func(ctx context.Context, t bmcTarget) {
+			limits, err := redfish.GetPowerLimits(ctx, t.Host, user, pass, pwrInsecure, pwrTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrNoPowerLimit):
+				unsupported++
+				diag.Infof("%s: %v", t.label(), err)
+			case err != nil:
+				failed++
+				diag.Warnf("%s: power cap get: %v", t.label(), err)
+			default:
+				read++
+				results[index[t]] = limits
+			}
+		})
+
+		rows := []powerCapRow{}
+		for i, limits := range results {
+			for _, l := range limits {
+				rows = append(rows, powerCapRow{BMC: targets[i].Host, Xname: targets[i].Xname, Chassis: l.Chassis, Source: l.Source,
+					LimitWatts: l.LimitWatts, ConsumedWatts: l.ConsumedWatts, MinWatts: l.MinWatts, MaxWatts: l.MaxWatts})
+			}
+		}
+		if capOutput == "json" {
+			out, err := json.MarshalIndent(rows, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		} else {
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "HOST\tCHASSIS\tSOURCE\tLIMIT\tCONSUMED\tRANGE")
+			for _, r := range rows {
+				label := bmcTarget{Host: r.BMC, Xname: r.Xname}.label()
+				limit := "none"
+				if r.LimitWatts != nil {
+					limit = formatReading(r.LimitWatts, "W")
+				}
+				rng := "-"
+				if r.MinWatts != nil || r.MaxWatts != nil {
+					rng = redfish.PowerLimit{MinWatts: r.MinWatts, MaxWatts: r.MaxWatts}.Range()
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", label, r.Chassis, r.Source, limit, formatReading(r.ConsumedWatts, "W"), rng)
+			}
+			if len(rows) > 0 {
+				if err := tw.Flush(); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Power cap: %d chassis on %d BMC(s), %d unsupported, %d failed\n", len(rows), read, unsupported, failed)
+		}
+		if err := checkOutcome(read+unsupported, failed, "reading the power limit failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var powerCapSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set (--watts) or remove (--clear) the power limit of every chassis on the selected BMCs",
+	Long: `Set the power limit of every chassis on the selected BMCs to --watts, or
+remove it with --clear. A limit outside the range a chassis reports is
+refused for that BMC before anything is changed. BMCs without a power limit
+are reported as unsupported and, like failures, make the exit status
+non-zero.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if capClear == cmd.Flags().Changed("watts") {
+			return errors.New("exactly one of --watts or --clear is required")
+		}
+		if !capClear && capWatts <= 0 {
+			return errors.New("--watts must be positive")
+		}
+		var watts *float64
+		action := "remove the power limit"
+		if !capClear {
+			w := float64(capWatts)
+			watts = &w
+			action = "set the power limit to " + strconv.Itoa(capWatts) + " W"
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := powerCapTargets()
+		if err != nil {
+			return err
+		}
+		if pwrDryRun {
+			for _, t := range targets {
+				fmt.Printf("[dry-run] would %s on every chassis of %s (%s)\n", action, t.label(), t.Host)
+			}
+			return nil
+		}
+
+		var mu sync.Mutex
+		var ok, unsupported, failed int
+		forEachTarget(cmd.Context(), targets, pwrBatchSize, pwrTimeout, func(ctx context.Context, t bmcTarget) {
+			was, err := redfish.SetPowerLimits(ctx, t.Host, user, pass, pwrInsecure, pwrTimeout, watts)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrNoPowerLimit):
+				unsupported++
+				diag.Warnf("%s: power cap set: unsupported: %v", t.label(), err)
+			case err != nil:
+				failed++
+				diag.Warnf("%s: power cap set: %v", t.label(), err)
+			default:
+				ok++
+				for _, l := range was {
+					from := "none"
+					if l.LimitWatts != nil {
+						from = formatReading(l.LimitWatts, "W")
+					}
+					diag.Infof("%s %s: power limit %s -> %s", t.label(), l.Chassis, from, capLimitLabel(watts))
+				}
+			}
+		})
+
+		fmt.Printf("Power cap set: %d succeeded, %d unsupported, %d failed\n", ok, unsupported, failed)
+		if err := checkOutcome(ok, unsupported+failed, "power cap set failed on %d BMC(s) and is unsupported on %d", failed, unsupported); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// capLimitLabel renders a limit to set, or "none" when it is cleared.
+func capLimitLabel(watts *float64) string {
+	if watts == nil {
+		return "none"
+	}
+	return formatReading(watts, "W")
+}
+
+// powerCapTargets resolves the hosts of the power cap commands and applies
+// --xname.
+func powerCapTargets() ([]bmcTarget, error) {
+	targets, err := resolveHosts(pwrFile, pwrHostsCSV, pwrHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	return filterByXname(targets, pwrXnames)
+}
+
+func init() {
+	powerCmd.AddCommand(powerCapCmd)
+	powerCapCmd.AddCommand(powerCapGetCmd, powerCapSetCmd)
+	powerCapGetCmd.Flags().StringVarP(&capOutput, "output", "o", "table", "output format: table or json")
+	powerCapSetCmd.Flags().IntVar(&capWatts, "watts", 0, "power limit in watts for every chassis")
+	powerCapSetCmd.Flags().BoolVar(&capClear, "clear", false, "remove the power limit instead of setting one")
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPowerCapSet(t *testing.T) {
+	var patches atomic.Int32
+	capped := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Chassis":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Node0"}]}`)
+		case r.URL.Path == "/redfish/v1/Chassis/Node0":
+			fmt.Fprint(w, `{"Id":"Node0","Power":{"@odata.id":"/redfish/v1/Chassis/Node0/Power"}}`)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Chassis/Node0/Power":
+			fmt.Fprint(w, `{"PowerControl":[{"PowerConsumedWatts":180,"PowerLimit":{"LimitInWatts":null}}]}`)
+		case r.Method == "PATCH":
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), `"LimitInWatts":400`) {
+				t.Errorf("PATCH body = %s", body)
+			}
+			patches.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer capped.Close()
+	// A BMC whose chassis has no power limit
+	bare := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Chassis":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Enclosure"}]}`)
+		case "/redfish/v1/Chassis/Enclosure":
+			fmt.Fprint(w, `{"Id":"Enclosure"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer bare.Close()
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+
+	hostsFile := t.TempDir() + "/hosts"
+	hosts := fmt.Sprintf("%s,x1000c0s0b0\n%s,x1000c0s1b0\n10.0.0.1,x1001c0s0b0\n",
+		strings.TrimPrefix(capped.URL, "https://"), strings.TrimPrefix(bare.URL, "https://"))
+	if err := os.WriteFile(hostsFile, []byte(hosts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setPowerGlobals("")
+	pwrHostsFile, pwrXnames, capWatts = hostsFile, []string{"x1000"}, 400
+	t.Cleanup(func() { pwrHostsFile, pwrXnames, capWatts = "", nil, 0 })
+	if err := powerCapSetCmd.Flags().Set("watts", "400"); err != nil {
+		t.Fatal(err)
+	}
+
+	powerCapSetCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return powerCapSetCmd.RunE(powerCapSetCmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "is unsupported on 1") {
+		t.Fatalf("err = %v, want the BMC without a limit reported\n%s", err, out)
+	}
+	if !strings.Contains(out, "Power cap set: 1 succeeded, 1 unsupported, 0 failed") {
+		t.Errorf("summary does not separate unsupported BMCs:\n%s", out)
+	}
+	if !strings.Contains(out, "Node0: power limit none -> 400 W") {
+		t.Errorf("missing the per-chassis change:\n%s", out)
+	}
+	if patches.Load() != 1 {
+		t.Errorf("PATCH count = %d, want 1", patches.Load())
+	}
+}
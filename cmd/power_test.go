@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bootstrap/internal/redfish"
+)
+
+// mockRedfishPowerServer serves two systems. Resets posted to Node1 fail
+// when failNode1 is set. Posted ResetType values are recorded per path.
+func mockRedfishPowerServer(t *testing.T, failNode1 bool) (*httptest.Server, map[string]string, *sync.Mutex) {
+	t.Helper()
+	posted := map[string]string{}
+	var mu sync.Mutex
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"},{"@odata.id":"/redfish/v1/Systems/Node1"}]}`))
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/redfish/v1/Systems/Node"):
+			id := strings.TrimPrefix(r.URL.Path, "/redfish/v1/Systems/")
+			json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck
+				"Id":         id,
+				"PowerState": "Off",
+				"Actions": map[string]any{
+					"#ComputerSystem.Reset": map[string]any{
+						"target":                            "/redfish/v1/Systems/" + id + "/Actions/ComputerSystem.Reset",
+						"ResetType@Redfish.AllowableValues": []string{"On", "ForceOff", "GracefulShutdown", "ForceRestart"},
+					},
+				},
+			})
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/Actions/ComputerSystem.Reset"):
+			if failNode1 && strings.Contains(r.URL.Path, "Node1") {
+				http.Error(w, `{"error":"busy"}`, http.StatusServiceUnavailable)
+				return
+			}
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			posted[r.URL.Path] = body["ResetType"]
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	return server, posted, &mu
+}
+
+// captureOutput runs c with stdout and stderr redirected and returns what was written.
+func captureOutput(t *testing.T, c func() error) (string, error) {
+	t.Helper()
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	os.Stderr = w
+	runErr := c()
+	w.Close() //nolint:errcheck
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+	out, _ := io.ReadAll(r)
+	return string(out), runErr
+}
+
+func setPowerGlobals(host string) {
+	pwrFile = ""
+	pwrHostsCSV = host
+	pwrInsecure = true
+	pwrTimeout = 5 * time.Second
+	pwrDryRun = false
+	pwrBatchSize = 0
+	pwrGraceful = false
+	pwrXnames = nil
+	pwrYes = true
+}
+
+func TestPowerOnResetsEverySystem(t *testing.T) {
+	server, posted, mu := mockRedfishPowerServer(t, false)
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	setPowerGlobals(strings.TrimPrefix(server.URL, "https://"))
+
+	powerOnCmd.SetContext(context.Background())
+	output, err := captureOutput(t, func() error { return powerOnCmd.RunE(powerOnCmd, nil) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, output)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, p := range []string{"/redfish/v1/Systems/Node0/Actions/ComputerSystem.Reset", "/redfish/v1/Systems/Node1/Actions/ComputerSystem.Reset"} {
+		if posted[p] != "On" {
+			t.Errorf("ResetType for %s = %q, want On", p, posted[p])
+		}
+	}
+	if strings.Count(output, "On requested") != 2 {
+		t.Errorf("expected one line per system, got:\n%s", output)
+	}
+	if !strings.Contains(output, "2 system(s) succeeded, 0 failed") {
+		t.Errorf("missing summary in output:\n%s", output)
+	}
+}
+
+func TestPowerOffGracefulAndPartialFailure(t *testing.T) {
+	server, posted, mu := mockRedfishPowerServer(t, true)
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	setPowerGlobals(strings.TrimPrefix(server.URL, "https://"))
+	pwrGraceful = true
+
+	powerOffCmd.SetContext(context.Background())
+	output, err := captureOutput(t, func() error { return powerOffCmd.RunE(powerOffCmd, nil) })
+	if err == nil {
+		t.Fatalf("expected error when a system fails, output:\n%s", output)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := posted["/redfish/v1/Systems/Node0/Actions/ComputerSystem.Reset"]; got != "GracefulShutdown" {
+		t.Errorf("ResetType = %q, want GracefulShutdown", got)
+	}
+	if !strings.Contains(output, "1 system(s) succeeded, 1 failed") {
+		t.Errorf("missing summary in output:\n%s", output)
+	}
+}
+
+func TestPowerCycleDeclinedPostsNothing(t *testing.T) {
+	server, posted, mu := mockRedfishPowerServer(t, false)
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	setPowerGlobals(strings.TrimPrefix(server.URL, "https://"))
+	pwrYes = false
+	old := confirmInput
+	defer func() { confirmInput = old }()
+	confirmInput = strings.NewReader("no\n")
+
+	powerCycleCmd.SetContext(context.Background())
+	output, err := captureOutput(t, func() error { return powerCycleCmd.RunE(powerCycleCmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "pass --yes") {
+		t.Fatalf("err = %v, want the cycle aborted\n%s", err, output)
+	}
+	if !strings.Contains(output, "About to power cycle every system on 1 BMC(s):") || !strings.Contains(output, "ResetType=ForceRestart") {
+		t.Errorf("missing summary in output:\n%s", output)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posted) != 0 {
+		t.Errorf("posted %v after the operator declined", posted)
+	}
+}
+
+func TestPowerStatus(t *testing.T) {
+	server, _, _ := mockRedfishPowerServer(t, false)
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	setPowerGlobals(strings.TrimPrefix(server.URL, "https://"))
+
+	powerStatusCmd.SetContext(context.Background())
+	output, err := captureOutput(t, func() error { return powerStatusCmd.RunE(powerStatusCmd, nil) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "Node0: Off") || !strings.Contains(output, "Node1: Off") {
+		t.Errorf("expected per-system power states, got:\n%s", output)
+	}
+}
+
+func TestPowerDryRun(t *testing.T) {
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	setPowerGlobals("10.1.1.10,10.1.1.11")
+	pwrDryRun = true
+
+	powerCycleCmd.SetContext(context.Background())
+	output, err := captureOutput(t, func() error { return powerCycleCmd.RunE(powerCycleCmd, nil) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(output, "[dry-run]") != 2 || !strings.Contains(output, "ResetType=ForceRestart") {
+		t.Errorf("unexpected dry-run output:\n%s", output)
+	}
+}
+
+func TestPowerStatusRefusesChangedIdentity(t *testing.T) {
+	server, _, _ := mockRedfishPowerServer(t, false)
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	host := strings.TrimPrefix(server.URL, "https://")
+	inv := filepath.Join(t.TempDir(), "inventory.yaml")
+	content := "bmcs:\n  - xname: x9000c1s0b0\n    ip: " + host + "\n    tls_fingerprint: \"00:11:22\"\n"
+	if err := os.WriteFile(inv, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	setPowerGlobals("")
+	pwrFile = inv
+	redfish.ConfigureIdentity(false)
+	redfish.CloseIdleConnections()
+	t.Cleanup(func() { redfish.ConfigureIdentity(false) })
+
+	powerStatusCmd.SetContext(context.Background())
+	output, err := captureOutput(t, func() error { return powerStatusCmd.RunE(powerStatusCmd, nil) })
+	if err == nil || !strings.Contains(output, "BMC identity changed for x9000c1s0b0 ("+host+"): pinned TLS fingerprint 00:11:22, presented ") {
+		t.Fatalf("err = %v, want the BMC refused\n%s", err, output)
+	}
+	if strings.Contains(output, "Node0: Off") {
+		t.Errorf("power state read from a BMC with a changed identity:\n%s", output)
+	}
+}
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/pflag"
+)
+
+var skipPreflight bool
+
+// preflightSample is how many BMCs that answer preflightCredentials asks
+// before a fleet run starts.
+const preflightSample = 3
+
+// addPreflightFlag adds --skip-preflight to the flags of a command that
+// calls preflightCredentials.
+func addPreflightFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(&skipPreflight, "skip-preflight", false, "do not check the credentials on a few BMCs before contacting all of them (for sites whose BMCs use different credentials)")
+}
+
+// preflightCredentials tries user and pass on targets one at a time, in
+// order, until sample of them have answered, skipping those that do not; at
+// most 2*sample are tried, so a run whose first BMCs are down does not wait
+// on all of them. Probing one at a time stays within any --batch-size. When
+// every BMC that answered rejects the credentials with 401 or 403, it
+// returns an error before the run touches the rest of the fleet: a mistyped
+// password would otherwise fail every BMC, and lock the account on vendors
+// that count failed logins. No answer at all, or one BMC accepting them,
+// lets the run go ahead.
+func preflightCredentials(ctx context.Context, targets []bmcTarget, user, pass string, insecure bool, timeout time.Duration, sample int) error {
+	if skipPreflight {
+		return nil
+	}
+	var rejected []string
+	answered := 0
+	for _, t := range targets[:min(len(targets), 2*sample)] {
+		if answered == sample || ctx.Err() != nil {
+			break
+		}
+		switch redfish.CheckHost(ctx, t.Host, user, pass, insecure, timeout, true).Status {
+		case redfish.CheckAuthFailed:
+			rejected = append(rejected, t.label())
+			answered++
+		case redfish.CheckOK, redfish.CheckError:
+			answered++
+		}
+	}
+	if answered > 0 && len(rejected) == answered {
+		return fmt.Errorf("credentials appear invalid: all %d sampled BMC(s) (%s) rejected them with 401/403; no other BMC was contacted (check REDFISH_USER and the password, or use --skip-preflight if BMCs have different credentials)",
+			answered, strings.Join(rejected, ", "))
+	}
+	if answered > 0 {
+		diag.Logf("preflight: %d of %d sampled BMC(s) accepted the credentials", answered-len(rejected), answered)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// authBMC serves the service root to anyone and answers every other request
+// with status, counting the requests that reach it.
+func authBMC(t *testing.T, status int) (string, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.URL.Path == "/redfish/v1/" || r.URL.Path == "/redfish/v1":
+			fmt.Fprint(w, `{"RedfishVersion":"1.6.0"}`)
+		case status != http.StatusOK:
+			w.WriteHeader(status)
+		case r.URL.Path == "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case r.URL.Path == "/redfish/v1/Systems/Node0":
+			fmt.Fprint(w, `{"PowerState":"On","Actions":{"#ComputerSystem.Reset":{"target":"/redfish/v1/Systems/Node0/Actions/ComputerSystem.Reset"}}}`)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &requests
+}
+
+// deadBMC returns the address of a BMC that refuses connections.
+func deadBMC(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	srv.Close()
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestPreflightCredentials(t *testing.T) {
+	ctx := context.Background()
+	probe := func(hosts ...string) error {
+		var targets []bmcTarget
+		for _, h := range hosts {
+			targets = append(targets, bmcTarget{Host: h})
+		}
+		return preflightCredentials(ctx, targets, "user", "typo", true, 5*time.Second, 3)
+	}
+
+	r1, n1 := authBMC(t, http.StatusUnauthorized)
+	r2, _ := authBMC(t, http.StatusForbidden)
+	r3, _ := authBMC(t, http.StatusUnauthorized)
+	r4, n4 := authBMC(t, http.StatusUnauthorized)
+	ok, _ := authBMC(t, http.StatusOK)
+
+	// Every sampled BMC rejects the credentials: the fourth is never asked
+	if err := probe(r1, r2, r3, r4); err == nil || !strings.Contains(err.Error(), "credentials appear invalid: all 3 sampled BMC(s)") {
+		t.Errorf("three rejections: err = %v", err)
+	}
+	if n4.Load() != 0 {
+		t.Errorf("preflight contacted %d BMC(s) past the sample", n4.Load())
+	}
+
+	// BMCs that do not answer are skipped, not counted
+	if err := probe(deadBMC(t), deadBMC(t), r1, r2, r3); err == nil || !strings.Contains(err.Error(), "all 3 sampled") {
+		t.Errorf("rejections behind dead BMCs: err = %v", err)
+	}
+	// but only twice the sample is tried
+	if err := probe(deadBMC(t), deadBMC(t), deadBMC(t), deadBMC(t), deadBMC(t), deadBMC(t), r1); err != nil {
+		t.Errorf("no answer within the probe budget should let the run go ahead: %v", err)
+	}
+
+	// One BMC accepting them is a site with mixed credentials
+	before := n1.Load()
+	if err := probe(r1, ok, r2, r3); err != nil {
+		t.Errorf("mixed answers: err = %v", err)
+	}
+	if n1.Load() == before {
+		t.Error("the first BMC was not probed")
+	}
+	if err := probe(r1, deadBMC(t)); err == nil || !strings.Contains(err.Error(), "all 1 sampled") {
+		t.Errorf("the only BMC that answered rejected them: err = %v", err)
+	}
+
+	skipPreflight = true
+	t.Cleanup(func() { skipPreflight = false })
+	before = n1.Load()
+	if err := probe(r1, r2, r3); err != nil || n1.Load() != before {
+		t.Errorf("--skip-preflight: err = %v, %d request(s)", err, n1.Load()-before)
+	}
+}
+
+func TestPowerStopsOnInvalidCredentials(t *testing.T) {
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "typo")
+	var hosts []string
+	var counts []*atomic.Int64
+	for range 5 {
+		h, n := authBMC(t, http.StatusUnauthorized)
+		hosts, counts = append(hosts, h), append(counts, n)
+	}
+	pwrHostsCSV, pwrInsecure, pwrTimeout, pwrBatchSize = strings.Join(hosts, ","), true, 5*time.Second, 5
+	t.Cleanup(func() { pwrHostsCSV, pwrInsecure, pwrBatchSize, skipPreflight = "", false, 0, false })
+	powerOnCmd.SetContext(context.Background())
+
+	out, err := captureOutput(t, func() error { return powerOnCmd.RunE(powerOnCmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "credentials appear invalid") {
+		t.Fatalf("err = %v, want the preflight to stop the run\n%s", err, out)
+	}
+	for i, n := range counts[3:] {
+		if n.Load() != 0 {
+			t.Errorf("BMC %d was contacted after the preflight failed", i+3)
+		}
+	}
+
+	// --skip-preflight lets every BMC fail on its own
+	skipPreflight = true
+	out, err = captureOutput(t, func() error { return powerOnCmd.RunE(powerOnCmd, nil) })
+	if err == nil || strings.Contains(err.Error(), "credentials appear invalid") || strings.Count(out, "401 Unauthorized") != 5 {
+		t.Errorf("--skip-preflight: err = %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"bootstrap/internal/discover"
+)
+
+// progressInterval is how often a plain progress line is printed when
+// stdout is not a terminal.
+const progressInterval = 10 * time.Second
+
+// progressPrinter renders discovery progress: one line rewritten in place
+// on a terminal, otherwise a plain line at most every interval and one when
+// the run completes.
+type progressPrinter struct {
+	w        io.Writer
+	tty      bool
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+	drawn    bool // an in-place line is on screen
+}
+
+func newProgressPrinter(w io.Writer, tty bool) *progressPrinter {
+	return &progressPrinter{w: w, tty: tty, interval: progressInterval, start: time.Now()}
+}
+
+func (p *progressPrinter) update(pr discover.Progress) {
+	line := fmt.Sprintf("Discovering: %d/%d BMC(s), %d NIC(s) found, %d failed (%s)",
+		pr.Done, pr.Total, pr.NICs, pr.Failed, time.Since(p.start).Round(time.Second))
+	if p.tty {
+		fmt.Fprintf(p.w, "\r\033[K%s", line)
+		p.drawn = true
+		return
+	}
+	now := time.Now()
+	if pr.Done < pr.Total && now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	fmt.Fprintln(p.w, line)
+}
+
+// finish ends an in-place line so that later output starts on its own line.
+func (p *progressPrinter) finish() {
+	if p.drawn {
+		fmt.Fprintln(p.w)
+		p.drawn = false
+	}
+}
+
+// stdoutIsTerminal reports whether stdout is a character device.
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// slowestBMCs returns up to n timings, longest first.
+func slowestBMCs(timings []discover.BMCTiming, n int) []discover.BMCTiming {
+	sorted := slices.Clone(timings)
+	slices.SortStableFunc(sorted, func(a, b discover.BMCTiming) int { return cmp.Compare(b.Duration, a.Duration) })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"bootstrap/internal/discover"
+)
+
+func TestProgressPrinter(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgressPrinter(&buf, true)
+	p.update(discover.Progress{Done: 1, Total: 3, NICs: 2})
+	p.update(discover.Progress{Done: 2, Total: 3, NICs: 4, Failed: 1})
+	p.finish()
+	if got := buf.String(); strings.Count(got, "\r\033[K") != 2 || !strings.HasSuffix(got, "2/3 BMC(s), 4 NIC(s) found, 1 failed (0s)\n") {
+		t.Errorf("tty output = %q", got)
+	}
+
+	// Without a terminal, lines are rate-limited but the last one is always printed
+	buf.Reset()
+	p = newProgressPrinter(&buf, false)
+	p.interval = time.Hour
+	for done := 1; done <= 3; done++ {
+		p.update(discover.Progress{Done: done, Total: 3})
+	}
+	p.finish()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "Discovering: 1/3") || !strings.HasPrefix(lines[1], "Discovering: 3/3") {
+		t.Errorf("plain output = %q", buf.String())
+	}
+}
+
+func TestSlowestBMCs(t *testing.T) {
+	timings := []discover.BMCTiming{
+		{Xname: "a", Duration: 2 * time.Second},
+		{Xname: "b", Duration: 5 * time.Second},
+		{Xname: "c", Duration: time.Second},
+		{Xname: "d", Duration: 3 * time.Second},
+	}
+	var got []string
+	for _, s := range slowestBMCs(timings, 3) {
+		got = append(got, s.Xname)
+	}
+	if strings.Join(got, ",") != "b,d,a" {
+		t.Errorf("slowest = %v", got)
+	}
+}
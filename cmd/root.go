@@ -5,10 +5,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"bootstrap/internal/diag"
+	"bootstrap/internal/httplimit"
+	"bootstrap/internal/httptimeout"
+	"bootstrap/internal/netalloc"
+	"bootstrap/internal/redfish"
 
 	"github.com/spf13/cobra"
 )
@@ -16,22 +25,272 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "ochami_bootstrap",
 	Short: "Bootstrap inventory generation and NIC discovery via Redfish",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) { //nolint:revive
-		// propagate debug flag to internal diagnostics
-		diag.Debug = debugFlag
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		startMetrics(cmd)
+		// Flags not given on the command line come from the environment
+		// or the config file before anything reads them
+		var err error
+		if activeConfig, err = loadConfig(cmd.Root()); err != nil {
+			return err
+		}
+		if err := checkNotifyFlags(); err != nil {
+			return err
+		}
+		if err := activeConfig.apply(cmd); err != nil {
+			return err
+		}
+		// propagate logging flags to internal diagnostics; --debug is kept as
+		// an alias for --verbose
+		if err := diag.Configure(verboseFlag || debugFlag, quietFlag, logFormat); err != nil {
+			return err
+		}
+		diag.ConfigureStream(streamOutput)
+		if minSuccessPercent < 0 || minSuccessPercent > 100 {
+			return fmt.Errorf("--min-success-percent must be between 0 and 100, got %d", minSuccessPercent)
+		}
+		if maxRPSPerHost < 0 || maxRPS < 0 {
+			return errors.New("--max-rps-per-host and --max-rps must not be negative")
+		}
+		redfish.ConfigureRateLimit(maxRPSPerHost, maxRPS)
+		if staggerDelay < 0 || batchPause < 0 {
+			return errors.New("--stagger and --batch-pause must not be negative")
+		}
+		if traceBodyLimit < 0 {
+			return errors.New("--trace-body-limit must not be negative")
+		}
+		if err := diag.ConfigureTrace(tracePath, traceBodyLimit); err != nil {
+			return fmt.Errorf("--trace: %w", err)
+		}
+		if connectTimeout < 0 || tlsHandshakeTimeout < 0 || responseHeaderTimeout < 0 {
+			return errors.New("--connect-timeout, --tls-handshake-timeout, and --response-header-timeout must not be negative")
+		}
+		httptimeout.Configure(httptimeout.Timeouts{
+			Connect:        connectTimeout,
+			TLSHandshake:   tlsHandshakeTimeout,
+			ResponseHeader: responseHeaderTimeout,
+		})
+		if maxResponseSize < 0 {
+			return errors.New("--max-response-size must not be negative")
+		}
+		httplimit.Configure(maxResponseSize)
+		if err := redfish.ConfigureProxy(proxyURL); err != nil {
+			return fmt.Errorf("--proxy: %w", err)
+		}
+		if err := netalloc.ConfigureBackend(ipamBackend); err != nil {
+			return fmt.Errorf("--ipam-backend: %w", err)
+		}
+		redfish.ConfigureIdentity(acceptNewIdentity)
+		return redfish.ConfigureTLS(redfish.TLSOptions{
+			CACertFile:     caCertFile,
+			ClientCertFile: clientCertFile,
+			ClientKeyFile:  clientKeyFile,
+		})
 	},
+	SilenceErrors: true,
 }
 
-var debugFlag bool
+var (
+	debugFlag   bool
+	verboseFlag bool
+	quietFlag   bool
+	logFormat   string
+	// streamOutput writes each host's lines as they happen instead of
+	// grouped when the host finishes
+	streamOutput bool
 
-// Execute is the entry point for the CLI.
-func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	caCertFile     string
+	clientCertFile string
+	clientKeyFile  string
+
+	acceptNewIdentity bool
+
+	proxyURL string
+
+	connectTimeout        time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+
+	maxResponseSize int64
+
+	ipamBackend string
+
+	minSuccessPercent int
+
+	maxRPSPerHost float64
+	maxRPS        float64
+
+	staggerDelay time.Duration
+	batchPause   time.Duration
+
+	tracePath      string
+	traceBodyLimit int
+
+	metricsOut string
+)
+
+// Exit statuses for fleet commands: exitFailure when nothing succeeded or the
+// command could not run, exitPartial when some targets failed but at least
+// one (and at least --min-success-percent of them) succeeded.
+const (
+	exitFailure = 1
+	exitPartial = 2
+)
+
+// exitInterrupted is the exit status after SIGINT/SIGTERM (128 + SIGINT).
+const exitInterrupted = 130
+
+// errInterrupted is returned by commands that stopped early because their
+// context was cancelled, after saving or reporting partial results.
+var errInterrupted = errors.New("interrupted")
+
+// checkInterrupted returns errInterrupted if ctx has been cancelled.
+func checkInterrupted(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	return nil
+}
+
+// outcomeError reports a fleet operation in which some targets failed.
+type outcomeError struct {
+	msg  string
+	code int
+}
+
+func (e *outcomeError) Error() string { return e.msg }
+
+// checkOutcome returns nil when failed is zero. Otherwise it returns an error
+// with the formatted message whose exit status is exitPartial when ok meets
+// --min-success-percent of ok+failed (and is non-zero), or exitFailure.
+func checkOutcome(ok, failed int, format string, args ...any) error {
+	if failed == 0 {
+		return nil
+	}
+	code := exitFailure
+	if ok > 0 && ok*100 >= minSuccessPercent*(ok+failed) {
+		code = exitPartial
+	}
+	return &outcomeError{msg: fmt.Sprintf(format, args...), code: code}
+}
+
+// countCategories counts the categories of a map of BMC to
+// redfish.Categorize category.
+func countCategories(categories map[string]string) map[string]int {
+	counts := map[string]int{}
+	for _, c := range categories {
+		counts[c]++
+	}
+	return counts
+}
+
+// printCategories prints "Failures by category: unreachable 3, auth 1" for
+// the non-zero counts, keyed by redfish.Categorize category, and nothing
+// when there are none.
+func printCategories(counts map[string]int) {
+	var parts []string
+	for _, c := range redfish.Categories {
+		if counts[c] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", c, counts[c]))
+		}
+	}
+	if len(parts) > 0 {
+		fmt.Printf("Failures by category: %s\n", strings.Join(parts, ", "))
+	}
+}
+
+// legacyModeFlags maps the mode flags of the old flag-parsed CLI to the
+// subcommands that replaced them. They are accepted, with a warning, for
+// one release.
+var legacyModeFlags = map[string]string{
+	"--init-bmcs": "init-bmcs",
+	"--discover":  "discover",
+}
+
+// rewriteLegacyArgs turns a command line of the old CLI, such as
+// "--discover --file inventory.yaml", into its subcommand form
+// ("discover --file inventory.yaml"). Other command lines are returned as
+// they are.
+func rewriteLegacyArgs(args []string) []string {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		sub, ok := legacyModeFlags[strings.TrimSuffix(a, "=true")]
+		if !ok {
+			continue
+		}
+		diag.Warnf("%s is deprecated and will be removed in the next release; use \"%s %s\"", a, rootCmd.Name(), sub)
+		return append([]string{sub}, slices.Delete(slices.Clone(args), i, i+1)...)
+	}
+	return args
+}
+
+// Execute is the entry point for the CLI. It runs the command tree with ctx
+// and returns the process exit status.
+func Execute(ctx context.Context) int {
+	rootCmd.SetArgs(rewriteLegacyArgs(os.Args[1:]))
+	err := rootCmd.ExecuteContext(ctx)
+	redfish.CloseIdleConnections()
+	if cerr := diag.CloseTrace(); cerr != nil && err == nil {
+		err = fmt.Errorf("--trace: %w", cerr)
+	}
+	code := exitStatus(ctx, err)
+	if err != nil {
+		diag.Errorf("%v", err)
+	}
+	// Runs that never reached a command (e.g. a flag typo) leave no metrics
+	if metricsOut != "" && runMetrics.command != "" {
+		if err := writeMetrics(metricsOut, code); err != nil {
+			diag.Errorf("--metrics-out: %v", err)
+			if code == 0 {
+				code = exitFailure
+			}
+		}
+	}
+	notify(code)
+	return code
+}
+
+// exitStatus returns the process exit status for the error a command
+// returned.
+func exitStatus(ctx context.Context, err error) int {
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, errInterrupted) || ctx.Err() != nil {
+		return exitInterrupted
+	}
+	var oe *outcomeError
+	if errors.As(err, &oe) {
+		return oe.code
 	}
+	return exitFailure
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "enable verbose debug logging")
+	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "enable verbose debug logging (alias for --verbose)")
+	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "log every HTTP request with method, URL, status, and latency")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "print only warnings, errors, and final summaries")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log format: text or json")
+	rootCmd.PersistentFlags().BoolVar(&streamOutput, "stream", false, "in parallel runs, print each host's lines as they happen (prefixed with its xname) instead of grouped when the host finishes, e.g. to follow long --wait operations")
+	rootCmd.PersistentFlags().StringVar(&caCertFile, "ca-cert", "", "PEM bundle of CAs trusted for BMC certificates (default: system roots)")
+	rootCmd.PersistentFlags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate for BMCs that require mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&clientKeyFile, "client-key", "", "PEM private key for --client-cert")
+	rootCmd.PersistentFlags().BoolVar(&acceptNewIdentity, "accept-new-identity", false, "contact BMCs whose TLS certificate differs from the tls_fingerprint pinned in the inventory, with a warning, instead of refusing them")
+	rootCmd.PersistentFlags().StringVar(&passwordFile, "password-file", "", "read the Redfish password from this file instead of REDFISH_PASSWORD (default: $REDFISH_PASSWORD_FILE)")
+	rootCmd.PersistentFlags().StringVar(&credentialHelper, "credential-helper", "", "run this shell command and use its output as the Redfish password, e.g. a vault wrapper")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "send Redfish traffic through this http://, https://, socks5://, or socks5h:// proxy (default: HTTPS_PROXY/NO_PROXY)")
+	rootCmd.PersistentFlags().DurationVar(&connectTimeout, "connect-timeout", httptimeout.Default.Connect, "give up on a BMC or SMD address that does not answer the TCP connect within this long (0 = only --timeout); reported as connect-timeout")
+	rootCmd.PersistentFlags().DurationVar(&tlsHandshakeTimeout, "tls-handshake-timeout", httptimeout.Default.TLSHandshake, "give up on a TLS handshake not finished within this long after the connect (0 = only --timeout)")
+	rootCmd.PersistentFlags().DurationVar(&responseHeaderTimeout, "response-header-timeout", httptimeout.Default.ResponseHeader, "give up on a response whose headers do not arrive within this long after the request is sent (0 = only --timeout)")
+	rootCmd.PersistentFlags().Int64Var(&maxResponseSize, "max-response-size", httplimit.DefaultMaxBytes, "fail a Redfish or SMD request whose response body, once decompressed, is larger than this many bytes (0 = unlimited)")
+	rootCmd.PersistentFlags().StringVar(&ipamBackend, "ipam-backend", netalloc.BackendMemory, "where IP allocations are kept: memory, file (IPAM_FILE), redis (IPAM_REDIS_ADDR), or postgres (IPAM_POSTGRES_*); file, redis, and postgres are shared between runs")
+	rootCmd.PersistentFlags().Float64Var(&maxRPSPerHost, "max-rps-per-host", 0, "maximum Redfish requests per second to any one BMC (0 = unlimited)")
+	rootCmd.PersistentFlags().Float64Var(&maxRPS, "max-rps", 0, "maximum Redfish requests per second across all BMCs (0 = unlimited)")
+	rootCmd.PersistentFlags().DurationVar(&staggerDelay, "stagger", 0, "wait this long between starting one BMC and the next in fleet commands and discover (0 = no wait)")
+	rootCmd.PersistentFlags().DurationVar(&batchPause, "batch-pause", 0, "pause this long after every --batch-size BMCs complete (every BMC when serial) before starting more (0 = no pause)")
+	rootCmd.PersistentFlags().StringVar(&tracePath, "trace", "", "write every Redfish request and response (credentials redacted) to this file as JSON lines")
+	rootCmd.PersistentFlags().IntVar(&traceBodyLimit, "trace-body-limit", diag.DefaultTraceBodyLimit, "bytes of each request and response body kept in the --trace file")
+	rootCmd.PersistentFlags().StringVar(&metricsOut, "metrics-out", "", "write the run's counts and duration to this file in the Prometheus text format (for the node_exporter textfile collector)")
+	rootCmd.PersistentFlags().IntVar(&minSuccessPercent, "min-success-percent", 0, "when some BMCs fail, exit 2 (partial) only if at least this percentage succeeded; otherwise exit 1")
 }
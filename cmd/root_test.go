@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckOutcome(t *testing.T) {
+	defer func() { minSuccessPercent = 0 }()
+	tests := []struct {
+		ok, failed, minPercent int
+		want                   int // 0 for a nil error
+	}{
+		{ok: 5, failed: 0, want: 0},
+		{ok: 0, failed: 0, want: 0},
+		{ok: 4, failed: 1, want: exitPartial},
+		{ok: 0, failed: 3, want: exitFailure},
+		{ok: 1, failed: 9, minPercent: 10, want: exitPartial},
+		{ok: 1, failed: 9, minPercent: 11, want: exitFailure},
+		{ok: 9, failed: 1, minPercent: 100, want: exitFailure},
+	}
+	for _, tt := range tests {
+		minSuccessPercent = tt.minPercent
+		err := checkOutcome(tt.ok, tt.failed, "%d failed", tt.failed)
+		got := 0
+		if err != nil {
+			var oe *outcomeError
+			if !errors.As(err, &oe) {
+				t.Fatalf("checkOutcome(%d, %d) = %v, not an outcomeError", tt.ok, tt.failed, err)
+			}
+			got = oe.code
+		}
+		if got != tt.want {
+			t.Errorf("checkOutcome(%d, %d) with --min-success-percent=%d: code %d, want %d", tt.ok, tt.failed, tt.minPercent, got, tt.want)
+		}
+	}
+}
+
+func TestRewriteLegacyArgs(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"--discover --file inv.yaml", "discover --file inv.yaml"},
+		{"--file inv.yaml --init-bmcs=true", "init-bmcs --file inv.yaml"},
+		{"discover --file inv.yaml", "discover --file inv.yaml"},
+		{"sync -- --discover", "sync -- --discover"},
+	} {
+		if got := strings.Join(rewriteLegacyArgs(strings.Fields(tc.in)), " "); got != tc.want {
+			t.Errorf("rewriteLegacyArgs(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
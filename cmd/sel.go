@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	selFile      string
+	selHostsCSV  string
+	selHostsFile string
+	selInsecure  bool
+	selTimeout   time.Duration
+	selBatchSize int
+	selSince     string
+	selSeverity  string
+	selYes       bool
+	selDryRun    bool
+)
+
+// severityRank orders Redfish Health values for --severity; unknown values
+// rank with OK.
+var severityRank = map[string]int{"ok": 0, "warning": 1, "critical": 2}
+
+var selCmd = &cobra.Command{
+	Use:   "sel",
+	Short: "Read and clear the system event log (SEL) via Redfish LogServices",
+}
+
+var selListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print the SEL entries of every system on the selected BMCs",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		since, err := parseSince(selSince, time.Now())
+		if err != nil {
+			return err
+		}
+		minRank, ok := severityRank[strings.ToLower(selSeverity)]
+		if selSeverity != "" && !ok {
+			return fmt.Errorf("--severity must be OK, Warning, or Critical")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(selFile, selHostsCSV, selHostsFile)
+		if err != nil {
+			return err
+		}
+
+		// Results are kept per target so output follows inventory order
+		results := make([][]redfish.SEL, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		var mu sync.Mutex
+		var read, noSEL, failed int
+		forEachTarget(cmd.Context(), targets, selBatchSize, selTimeout, func(ctx context.Context, t bmcTarget) {
+			logs, err := redfish.ListSEL(ctx, t.Host, user, pass, selInsecure, selTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrNoSEL):
+				noSEL++
+				diag.Infof("%s: no SEL log service", t.label())
+			case err != nil:
+				failed++
+				diag.Warnf("%s: sel list: %v", t.label(), err)
+			default:
+				read++
+				results[index[t]] = logs
+			}
+		})
+
+		shown := 0
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "HOST\tLOG\tCREATED\tSEVERITY\tMESSAGE")
+		for i, logs := range results {
+			for _, l := range logs {
+				for _, e := range l.Entries {
+					if severityRank[strings.ToLower(e.Severity)] < minRank {
+						continue
+					}
+					if !since.IsZero() {
+						// Entries without a parseable time cannot be placed; keep them
+						if created, err := time.Parse(time.RFC3339, e.Created); err == nil && created.Before(since) {
+							continue
+						}
+					}
+					shown++
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", targets[i].label(), path.Base(path.Dir(path.Dir(l.Path))),
+						valueOrDash(e.Created), valueOrDash(e.Severity), valueOrDash(e.Message))
+				}
+			}
+		}
+		if shown > 0 {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("SEL: %d entries from %d BMC(s), %d without SEL, %d failed\n", shown, read, noSEL, failed)
+		if err := checkOutcome(read+noSEL, failed, "reading the SEL failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var selClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the SEL on every selected BMC (LogService.ClearLog)",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(selFile, selHostsCSV, selHostsFile)
+		if err != nil {
+			return err
+		}
+
+		if selDryRun {
+			for _, t := range targets {
+				fmt.Printf("[dry-run] would POST LogService.ClearLog to the SELs on %s (%s)\n", t.label(), t.Host)
+			}
+			return nil
+		}
+		if !selYes {
+			ok, err := confirm(fmt.Sprintf("Clear the SEL on %d BMC(s)?", len(targets)))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("aborted; pass --yes to clear without asking")
+			}
+		}
+
+		var mu sync.Mutex
+		var ok, failed int
+		forEachTarget(cmd.Context(), targets, selBatchSize, selTimeout, func(ctx context.Context, t bmcTarget) {
+			cleared, err := redfish.ClearSEL(ctx, t.Host, user, pass, selInsecure, selTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: sel clear: %v", t.label(), err)
+				return
+			}
+			ok++
+			diag.Infof("%s: cleared %d SEL(s)", t.label(), len(cleared))
+		})
+
+		fmt.Printf("SEL clear: %d succeeded, %d failed\n", ok, failed)
+		if err := checkOutcome(ok, failed, "sel clear failed for %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// parseSince interprets --since as a duration before now (e.g. 24h) or an
+// RFC 3339 timestamp. An empty value means no limit.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--since must be a duration like 24h or an RFC 3339 time, got %q", s)
+	}
+	return t, nil
+}
+
+func init() {
+	rootCmd.AddCommand(selCmd)
+	selCmd.AddCommand(selListCmd, selClearCmd)
+	selCmd.PersistentFlags().StringVarP(&selFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	selCmd.PersistentFlags().StringVar(&selHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	selCmd.PersistentFlags().StringVar(&selHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	selCmd.PersistentFlags().BoolVar(&selInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	selCmd.PersistentFlags().DurationVar(&selTimeout, "timeout", 60*time.Second, "per-BMC request timeout")
+	selCmd.PersistentFlags().IntVar(&selBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	selListCmd.Flags().StringVar(&selSince, "since", "", "only entries created within this duration (e.g. 24h) or after this RFC 3339 time")
+	selListCmd.Flags().StringVar(&selSeverity, "severity", "", "only entries at or above this severity: OK, Warning, or Critical")
+	selClearCmd.Flags().BoolVarP(&selYes, "yes", "y", false, "clear without asking for confirmation")
+	selClearCmd.Flags().BoolVar(&selDryRun, "dry-run", false, "plan only: print which BMCs would be cleared")
+}
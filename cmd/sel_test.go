@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSELListFiltersAndClearConfirms(t *testing.T) {
+	var clears atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case "/redfish/v1/Systems/Node0/LogServices/SEL":
+			fmt.Fprint(w, `{"Id":"SEL"}`)
+		case "/redfish/v1/Systems/Node0/LogServices/SEL/Entries":
+			fmt.Fprintf(w, `{"Members":[
+				{"Id":"1","Created":"2020-01-01T00:00:00Z","Severity":"Critical","Message":"old failure"},
+				{"Id":"2","Created":%q,"Severity":"OK","Message":"power on"},
+				{"Id":"3","Created":%q,"Severity":"Warning","Message":"fan slow"}]}`,
+				time.Now().UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339))
+		case "/redfish/v1/Systems/Node0/LogServices/SEL/Actions/LogService.ClearLog":
+			clears.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	selHostsCSV = strings.TrimPrefix(srv.URL, "https://")
+	selInsecure = true
+	selTimeout = 5 * time.Second
+	selSince, selSeverity = "24h", "warning"
+	defer func() { selHostsCSV, selSince, selSeverity = "", "", "" }()
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+
+	selListCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return selListCmd.RunE(selListCmd, nil) })
+	if err != nil {
+		t.Fatalf("sel list: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "fan slow") || strings.Contains(out, "power on") || strings.Contains(out, "old failure") {
+		t.Errorf("filtered output:\n%s", out)
+	}
+	if !strings.Contains(out, "SEL: 1 entries from 1 BMC(s), 0 without SEL, 0 failed") {
+		t.Errorf("summary missing:\n%s", out)
+	}
+
+	// Declining the prompt clears nothing
+	selClearCmd.SetContext(context.Background())
+	confirmInput = strings.NewReader("n\n")
+	defer func() { confirmInput = strings.NewReader("") }()
+	if _, err := captureOutput(t, func() error { return selClearCmd.RunE(selClearCmd, nil) }); err == nil {
+		t.Fatal("declined clear should fail")
+	}
+	if clears.Load() != 0 {
+		t.Fatal("SEL cleared without confirmation")
+	}
+	confirmInput = strings.NewReader("yes\n")
+	out, err = captureOutput(t, func() error { return selClearCmd.RunE(selClearCmd, nil) })
+	if err != nil || clears.Load() != 1 {
+		t.Fatalf("confirmed clear: %v (clears=%d)\n%s", err, clears.Load(), out)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	if got, err := parseSince("24h", now); err != nil || !got.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("parseSince(24h) = %v, %v", got, err)
+	}
+	if got, err := parseSince("2025-06-01T00:00:00Z", now); err != nil || got.Day() != 1 {
+		t.Errorf("parseSince(RFC 3339) = %v, %v", got, err)
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Error("parseSince(yesterday) should fail")
+	}
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sensFile       string
+	sensHostsCSV   string
+	sensHostsFile  string
+	sensInsecure   bool
+	sensTimeout    time.Duration
+	sensBatchSize  int
+	sensOnlyFaults bool
+	sensOutput     string
+)
+
+// sensorRow is one reading of the sensors report.
+type sensorRow struct {
+	BMC     string   `json:"bmc"`
+	Xname   string   `json:"xname,omitempty"`
+	Chassis string   `json:"chassis"`
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Reading *float64 `json:"reading"`
+	Units   string   `json:"units,omitempty"`
+	Health  string   `json:"health,omitempty"`
+	State   string   `json:"state,omitempty"`
+	Fault   bool     `json:"fault"`
+}
+
+var sensorsCmd = &cobra.Command{
+	Use:   "sensors",
+	Short: "Print the thermal and power sensor readings of every chassis",
+	Long: `Read the temperatures, fans, voltages, power readings, and power supplies of
+every chassis on the selected BMCs and print one line per sensor. Chassis
+with a ThermalSubsystem or PowerSubsystem are read through their Sensors
+collection; older ones through the Thermal and Power resources.
+
+Sensors whose health is not OK are marked with "!" in the first column.
+--only-faults prints only those. A BMC that cannot be read counts as
+failed; faults it reports do not change the exit status.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if sensOutput != "table" && sensOutput != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(sensFile, sensHostsCSV, sensHostsFile)
+		if err != nil {
+			return err
+		}
+
+		// Readings are kept per target so output follows inventory order
+		results := make([][]redfish.Sensor, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		var mu sync.Mutex
+		var read, failed int
+		forEachTarget(cmd.Context(), targets, sensBatchSize, sensTimeout, func(ctx context.Context, t bmcTarget) {
+			sensors, err := redfish.GetSensors(ctx, t.Host, user, pass, sensInsecure, sensTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: sensors: %v", t.label(), err)
+				return
+			}
+			read++
+			results[index[t]] = sensors
+		})
+
+		rows := []sensorRow{}
+		faults := 0
+		for i, sensors := range results {
+			for _, s := range sensors {
+				if s.Fault() {
+					faults++
+				} else if sensOnlyFaults {
+					continue
+				}
+				rows = append(rows, sensorRow{BMC: targets[i].Host, Xname: targets[i].Xname, Chassis: s.Chassis, Type: s.Type,
+					Name: s.Name, Reading: s.Reading, Units: s.Units, Health: s.Health, State: s.State, Fault: s.Fault()})
+			}
+		}
+
+		if sensOutput == "json" {
+			out, err := json.MarshalIndent(rows, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		} else {
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "\tHOST\tCHASSIS\tTYPE\tNAME\tREADING\tHEALTH\tSTATE")
+			for _, r := range rows {
+				mark := ""
+				if r.Fault {
+					mark = "!"
+				}
+				label := bmcTarget{Host: r.BMC, Xname: r.Xname}.label()
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", mark, label, r.Chassis, valueOrDash(r.Type),
+					valueOrDash(r.Name), formatReading(r.Reading, r.Units), valueOrDash(r.Health), valueOrDash(r.State))
+			}
+			if len(rows) > 0 {
+				if err := tw.Flush(); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Sensors: %d reading(s) from %d BMC(s), %d fault(s), %d failed\n", len(rows), read, faults, failed)
+		}
+		if err := checkOutcome(read, failed, "reading sensors failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+// formatReading renders a reading with its units, or "-" when there is none.
+func formatReading(v *float64, units string) string {
+	if v == nil {
+		return "-"
+	}
+	s := strconv.FormatFloat(*v, 'f', -1, 64)
+	if units != "" {
+		s += " " + units
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(sensorsCmd)
+	sensorsCmd.Flags().StringVarP(&sensFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	sensorsCmd.Flags().StringVar(&sensHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	sensorsCmd.Flags().StringVar(&sensHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	sensorsCmd.Flags().BoolVar(&sensInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	sensorsCmd.Flags().DurationVar(&sensTimeout, "timeout", 60*time.Second, "per-BMC request timeout")
+	sensorsCmd.Flags().IntVar(&sensBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	sensorsCmd.Flags().BoolVar(&sensOnlyFaults, "only-faults", false, "print only sensors whose health is not OK")
+	sensorsCmd.Flags().StringVarP(&sensOutput, "output", "o", "table", "output format: table or json")
+}
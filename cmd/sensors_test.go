@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSensorsOnlyFaults(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Chassis":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Chassis/Enclosure"}]}`))
+		case "/redfish/v1/Chassis/Enclosure":
+			_, _ = w.Write([]byte(`{"Id":"Enclosure","Thermal":{"@odata.id":"/redfish/v1/Chassis/Enclosure/Thermal"}}`))
+		case "/redfish/v1/Chassis/Enclosure/Thermal":
+			_, _ = w.Write([]byte(`{"Temperatures":[{"Name":"Inlet","ReadingCelsius":24,"Status":{"Health":"OK"}},
+				{"Name":"Outlet","ReadingCelsius":71,"Status":{"Health":"Critical"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+	sensHostsCSV, sensInsecure = strings.TrimPrefix(srv.URL, "https://"), true
+	t.Cleanup(func() { sensHostsCSV, sensInsecure, sensOnlyFaults, sensOutput = "", false, false, "table" })
+
+	sensorsCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return sensorsCmd.RunE(sensorsCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Inlet", "24 Cel", "!  ", "Outlet", "Sensors: 2 reading(s) from 1 BMC(s), 1 fault(s), 0 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table output missing %q:\n%s", want, out)
+		}
+	}
+
+	sensOnlyFaults, sensOutput = true, "json"
+	out, err = captureOutput(t, func() error { return sensorsCmd.RunE(sensorsCmd, nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []sensorRow
+	if err := json.Unmarshal([]byte(out[strings.Index(out, "["):]), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(rows) != 1 || rows[0].Name != "Outlet" || !rows[0].Fault || rows[0].Reading == nil || *rows[0].Reading != 71 {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+}
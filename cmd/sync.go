@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/smd"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncFile     string
+	syncSMDURL   string
+	syncInsecure bool
+	syncTimeout  time.Duration
+	syncDryRun   bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Push inventory records to OpenCHAMI services",
+}
+
+var syncSMDCmd = &cobra.Command{
+	Use:   "smd",
+	Short: "Create or update SMD EthernetInterfaces records for nodes[]",
+	Long: `For every nodes[] entry, look up the SMD EthernetInterfaces record for its
+MAC and create it, patch it, or leave it alone. The bearer token is read from
+the SMD_TOKEN environment variable.`,
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if syncFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		if syncSMDURL == "" && !syncDryRun {
+			return fmt.Errorf("--smd-url is required")
+		}
+		doc, err := readInventory(syncFile)
+		if err != nil {
+			return err
+		}
+		if len(doc.Nodes) == 0 {
+			return fmt.Errorf("input must contain non-empty nodes[]")
+		}
+
+		if syncDryRun {
+			for _, n := range doc.Nodes {
+				if n.MAC == "" {
+					diag.Warnf("%s: no MAC, would skip", n.Xname)
+					continue
+				}
+				body, err := json.Marshal(smd.FromEntry(n))
+				if err != nil {
+					return err
+				}
+				fmt.Printf("[dry-run] %s: %s\n", n.Xname, body)
+			}
+			return nil
+		}
+
+		c := smd.NewClient(syncSMDURL, os.Getenv("SMD_TOKEN"), syncInsecure, syncTimeout)
+		var created, updated, unchanged, failed int
+		for _, n := range doc.Nodes {
+			if n.MAC == "" {
+				failed++
+				diag.Warnf("%s: no MAC, skipping", n.Xname)
+				continue
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), syncTimeout)
+			outcome, err := c.Upsert(ctx, smd.FromEntry(n))
+			cancel()
+			if err != nil {
+				failed++
+				diag.Warnf("%s: sync: %v", n.Xname, err)
+				continue
+			}
+			switch outcome {
+			case smd.Created:
+				created++
+			case smd.Updated:
+				updated++
+			default:
+				unchanged++
+			}
+		}
+
+		fmt.Printf("SMD sync: %d created, %d updated, %d unchanged, %d failed\n", created, updated, unchanged, failed)
+		if err := checkOutcome(created+updated+unchanged, failed, "smd sync failed for %d node(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.AddCommand(syncSMDCmd)
+	syncSMDCmd.Flags().StringVarP(&syncFile, "file", "f", "", "Inventory file to read nodes[] from")
+	syncSMDCmd.Flags().StringVar(&syncSMDURL, "smd-url", "", "Base URL of the SMD service, e.g. https://smd.example.com")
+	syncSMDCmd.Flags().BoolVar(&syncInsecure, "insecure", false, "allow insecure TLS to SMD")
+	syncSMDCmd.Flags().DurationVar(&syncTimeout, "timeout", 30*time.Second, "per-record request timeout")
+	syncSMDCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "print the JSON payload per node without contacting SMD")
+}
@@ -0,0 +1,337 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/ratelimit"
+	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bmcTarget is a BMC selected for a fleet operation.
+type bmcTarget struct {
+	Host  string // address used to reach the Redfish service
+	Xname string // inventory xname; empty when the host came from --hosts
+}
+
+// label returns the name used for the target in command output.
+func (t bmcTarget) label() string {
+	if t.Xname != "" {
+		return t.Xname
+	}
+	return t.Host
+}
+
+// hostWithoutPort returns host with any :port removed.
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// resolveHosts determines the BMCs to operate on. Hosts given with hostsCSV
+// (comma-separated) and hostsFile are merged and take precedence over the
+// inventory file. A host listed more than once in them is targeted once.
+func resolveHosts(file, hostsCSV, hostsFile string) ([]bmcTarget, error) {
+	var targets []bmcTarget
+	for _, h := range strings.Split(hostsCSV, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			targets = append(targets, bmcTarget{Host: h})
+		}
+	}
+	if hostsFile != "" {
+		listed, err := readHostsFile(hostsFile)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, listed...)
+	}
+	if strings.TrimSpace(hostsCSV) != "" || hostsFile != "" {
+		if len(targets) == 0 {
+			return nil, errors.New("--hosts and --hosts-file list no hosts")
+		}
+		return dedupeTargets(targets), nil
+	}
+
+	if file == "" {
+		return nil, errors.New("at least one of --file, --hosts, or --hosts-file is required")
+	}
+	doc, err := readInventory(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.BMCs) == 0 {
+		return nil, fmt.Errorf("input must contain non-empty bmcs[]")
+	}
+	for _, b := range doc.BMCs {
+		targets = append(targets, bmcTarget{Host: b.Address(), Xname: b.Xname})
+		redfish.PinIdentity(b.Address(), b.Xname, b.TLSFingerprint)
+	}
+	return targets, nil
+}
+
+// filterByXname keeps the targets selected by any of the xnames in sel. A
+// cabinet, chassis, slot, or BMC xname selects the BMCs within it; a node
+// xname selects its BMC. Targets without an xname are never selected, and an
+// xname that selects nothing is an error so a typo does not go unnoticed.
+func filterByXname(targets []bmcTarget, sel []string) ([]bmcTarget, error) {
+	if len(sel) == 0 {
+		return targets, nil
+	}
+	parsed := make([]xname.Xname, 0, len(sel))
+	for _, s := range sel {
+		x, err := xname.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("--xname: %w", err)
+		}
+		parsed = append(parsed, x)
+	}
+	matched := make([]bool, len(parsed))
+	var out []bmcTarget
+	for _, t := range targets {
+		b, err := xname.Parse(t.Xname)
+		if err != nil || b.Kind != xname.KindBMC {
+			continue
+		}
+		selected := false
+		for i, x := range parsed {
+			if x.Kind == xname.KindNode {
+				x, _ = x.Ancestor(xname.KindBMC)
+			}
+			if a, ok := b.Ancestor(x.Kind); ok && a == x {
+				matched[i], selected = true, true
+			}
+		}
+		if selected {
+			out = append(out, t)
+		}
+	}
+	for i, ok := range matched {
+		if !ok {
+			return nil, fmt.Errorf("--xname %s matches none of the selected BMCs", sel[i])
+		}
+	}
+	return out, nil
+}
+
+// selection is the BMCs a fleet command operates on, with how many were
+// resolved before --xname narrowed them.
+type selection struct {
+	targets []bmcTarget
+	total   int
+	xnames  []string
+}
+
+// selectTargets resolves the hosts of a command and applies its --xname
+// selectors.
+func selectTargets(file, hostsCSV, hostsFile string, xnames []string) (selection, error) {
+	all, err := resolveHosts(file, hostsCSV, hostsFile)
+	if err != nil {
+		return selection{}, err
+	}
+	targets, err := filterByXname(all, xnames)
+	if err != nil {
+		return selection{}, err
+	}
+	return selection{targets: targets, total: len(all), xnames: xnames}, nil
+}
+
+// readHostsFile parses a --hosts-file. Each line is a host or IP, optionally
+// followed by a comma and the BMC's xname; blank lines and lines starting
+// with # are ignored.
+func readHostsFile(path string) ([]bmcTarget, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var targets []bmcTarget
+	for i, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		host, x, _ := strings.Cut(line, ",")
+		host, x = strings.TrimSpace(host), strings.TrimSpace(x)
+		if host == "" {
+			return nil, fmt.Errorf("%s:%d: missing host", path, i+1)
+		}
+		if x != "" {
+			parsed, err := xname.Parse(x)
+			if err != nil || parsed.Kind != xname.KindBMC {
+				return nil, fmt.Errorf("%s:%d: %q is not a BMC xname", path, i+1, x)
+			}
+			x = parsed.String()
+		}
+		targets = append(targets, bmcTarget{Host: host, Xname: x})
+	}
+	return targets, nil
+}
+
+// dedupeTargets drops repeated hosts, keeping the first occurrence. An xname
+// given only on a later occurrence is kept.
+func dedupeTargets(targets []bmcTarget) []bmcTarget {
+	index := make(map[string]int, len(targets))
+	out := targets[:0]
+	for _, t := range targets {
+		i, seen := index[t.Host]
+		if !seen {
+			index[t.Host] = len(out)
+			out = append(out, t)
+			continue
+		}
+		if out[i].Xname == "" {
+			out[i].Xname = t.Xname
+		}
+	}
+	return out
+}
+
+// readInventory loads and parses an inventory YAML file.
+func readInventory(path string) (inventory.FileFormat, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return inventory.FileFormat{}, err
+	}
+	doc, err := inventory.Decode(raw)
+	if err != nil {
+		return doc, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so a reader never sees a partly written file. A new file
+// gets mode 0644; an existing one keeps its mode.
+func writeFileAtomic(path string, data []byte) error {
+	mode := fs.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	// Only left behind when the rename is not reached
+	defer os.Remove(f.Name()) // nolint:errcheck
+	if _, err := f.Write(data); err != nil {
+		f.Close() // nolint:errcheck
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// writeInventory rewrites the named top-level sections ("bmcs", "nodes") of
+// the inventory file at path from doc, keeping comments and unknown keys
+// elsewhere in the file. A missing file is created from doc as a whole.
+// Either way the file is replaced atomically, so an interrupted run leaves
+// the old inventory in place.
+func writeInventory(path string, doc inventory.FileFormat, sections ...string) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		out, err := yaml.Marshal(&doc)
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(path, out)
+	}
+	if err != nil {
+		return err
+	}
+	_, d, err := inventory.ParseDocument(raw)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, section := range sections {
+		var v []inventory.Entry
+		switch section {
+		case "bmcs":
+			v = doc.BMCs
+		case "nodes":
+			v = doc.Nodes
+		case "controllers":
+			v = doc.Controllers
+		default:
+			return fmt.Errorf("unknown inventory section %q", section)
+		}
+		if err := d.Set(section, v); err != nil {
+			return err
+		}
+	}
+	out, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, out)
+}
+
+// newPacer returns the --stagger and --batch-pause pacing for a fan-out
+// running batchSize hosts at a time; the pause comes after every batchSize
+// completions (every host when serial).
+func newPacer(batchSize int) *ratelimit.Pacer {
+	return ratelimit.NewPacer(staggerDelay, batchPause, batchSize)
+}
+
+// forEachTarget calls fn for every target with at most batchSize hosts in
+// flight (0 or 1 runs serially, in order), paced by newPacer. The per-host
+// timeout starts only once a host has been given a slot, so queued hosts do
+// not burn their budget waiting. No new hosts are started after ctx is
+// cancelled. fn's ctx carries a diag.HostLog for the target, flushed when fn
+// returns.
+func forEachTarget(ctx context.Context, targets []bmcTarget, batchSize int, timeout time.Duration, fn func(ctx context.Context, t bmcTarget)) {
+	sem := make(chan struct{}, max(1, batchSize))
+	pacer := newPacer(batchSize)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		if pacer.Start(ctx) != nil {
+			<-sem
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(t bmcTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer pacer.Done()
+			hl := diag.NewHostLog(t.label())
+			defer hl.Flush()
+			hctx := diag.WithHostLog(ctx, hl)
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				hctx, cancel = context.WithTimeout(hctx, timeout)
+				defer cancel()
+			}
+			fn(hctx, t)
+		}(t)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolveHostsMergesHostsFile(t *testing.T) {
+	dir := t.TempDir()
+	hostsFile := filepath.Join(dir, "hosts.txt")
+	content := "# lab rack\n10.1.1.20\n\n10.1.1.21, x9000c1s0b1\n  # spare\n10.1.1.22,x9000c1s1b0\n"
+	if err := os.WriteFile(hostsFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := resolveHosts("", "10.1.1.21,10.1.1.30", hostsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bmcTarget{
+		{Host: "10.1.1.21", Xname: "x9000c1s0b1"},
+		{Host: "10.1.1.30"},
+		{Host: "10.1.1.20"},
+		{Host: "10.1.1.22", Xname: "x9000c1s1b0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveHosts = %+v, want %+v", got, want)
+	}
+
+	// --hosts-file takes precedence over the inventory file
+	inv := filepath.Join(dir, "inventory.yaml")
+	if err := os.WriteFile(inv, []byte("bmcs:\n  - xname: x1000c0s0b0\n    ip: 10.9.9.9\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := resolveHosts(inv, "", hostsFile); err != nil || len(got) != 3 {
+		t.Errorf("resolveHosts with --file and --hosts-file = %+v, %v", got, err)
+	}
+}
+
+func TestResolveHostsFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"bad-xname": "10.1.1.20,node7\n",
+		"no-host":   ",x9000c1s0b0\n",
+		"empty":     "# nothing here\n\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := resolveHosts("", "", path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if name != "empty" && !strings.Contains(err.Error(), path+":1:") {
+			t.Errorf("%s: error %q does not name the line", name, err)
+		}
+	}
+}
+
+func TestFilterByXname(t *testing.T) {
+	targets := []bmcTarget{
+		{Host: "10.1.1.20", Xname: "x9000c1s0b0"},
+		{Host: "10.1.1.21", Xname: "x9000c1s0b1"},
+		{Host: "10.1.1.22", Xname: "x9000c1s1b0"},
+		{Host: "10.1.2.20", Xname: "x9000c3s0b0"},
+		{Host: "10.9.9.9"},
+	}
+	cases := []struct {
+		sel  []string
+		want []string
+	}{
+		{nil, []string{"10.1.1.20", "10.1.1.21", "10.1.1.22", "10.1.2.20", "10.9.9.9"}},
+		{[]string{"x9000c1s0"}, []string{"10.1.1.20", "10.1.1.21"}},
+		{[]string{"X9000C1S1B0N1", "x9000c3"}, []string{"10.1.1.22", "10.1.2.20"}},
+		{[]string{"x9000c1s0b1", "x9000c1"}, []string{"10.1.1.20", "10.1.1.21", "10.1.1.22"}},
+	}
+	for _, c := range cases {
+		got, err := filterByXname(targets, c.sel)
+		if err != nil {
+			t.Fatalf("%v: %v", c.sel, err)
+		}
+		var hosts []string
+		for _, g := range got {
+			hosts = append(hosts, g.Host)
+		}
+		if strings.Join(hosts, ",") != strings.Join(c.want, ",") {
+			t.Errorf("%v selected %v, want %v", c.sel, hosts, c.want)
+		}
+	}
+	if _, err := filterByXname(targets, []string{"x9000c1s0", "x9000c2"}); err == nil || !strings.Contains(err.Error(), "x9000c2") {
+		t.Errorf("unmatched xname: err = %v", err)
+	}
+	if _, err := filterByXname(targets, []string{"node7"}); err == nil {
+		t.Error("invalid xname: expected an error")
+	}
+}
+
+func TestForEachTargetPacing(t *testing.T) {
+	targets := []bmcTarget{{Host: "a"}, {Host: "b"}, {Host: "c"}, {Host: "d"}}
+	staggerDelay, batchPause = 30*time.Millisecond, 0
+	t.Cleanup(func() { staggerDelay, batchPause = 0, 0 })
+	var mu sync.Mutex
+	var starts []time.Time
+	forEachTarget(context.Background(), targets, 4, 0, func(ctx context.Context, t bmcTarget) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+	})
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < 25*time.Millisecond {
+			t.Errorf("host %d started %v after the previous one, want >= --stagger", i, gap)
+		}
+	}
+
+	// Serially the pause comes after every host; cancelled during the first
+	staggerDelay, batchPause = 0, time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var ran atomic.Int32
+	start := time.Now()
+	forEachTarget(ctx, targets, 0, 0, func(ctx context.Context, t bmcTarget) { ran.Add(1) })
+	if n := ran.Load(); n != 1 {
+		t.Errorf("%d host(s) ran, want only the one before the pause", n)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("cancelled pause returned after %v", d)
+	}
+}
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/redfish"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	taskFile      string
+	taskHostsCSV  string
+	taskHostsFile string
+	taskInsecure  bool
+	taskTimeout   time.Duration
+	taskBatchSize int
+	taskID        string
+	taskDryRun    bool
+)
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "List, inspect, and cancel Redfish TaskService tasks",
+}
+
+var tasksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tasks on every selected BMC",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(taskFile, taskHostsCSV, taskHostsFile)
+		if err != nil {
+			return err
+		}
+
+		// Results are kept per target so the table follows inventory order
+		results := make([][]redfish.Task, len(targets))
+		index := make(map[bmcTarget]int, len(targets))
+		for i, t := range targets {
+			index[t] = i
+		}
+		var mu sync.Mutex
+		var ok, unsupported, failed int
+		forEachTarget(cmd.Context(), targets, taskBatchSize, taskTimeout, func(ctx context.Context, t bmcTarget) {
+			tasks, err := redfish.ListTasks(ctx, t.Host, user, pass, taskInsecure, taskTimeout)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrTaskServiceUnsupported):
+				unsupported++
+				diag.Infof("%s: TaskService not supported", t.label())
+			case err != nil:
+				failed++
+				diag.Warnf("%s: list tasks: %v", t.label(), err)
+			default:
+				ok++
+				results[index[t]] = tasks
+			}
+		})
+
+		total := 0
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "HOST\tID\tNAME\tSTATE\tPERCENT")
+		for i, tasks := range results {
+			for _, task := range tasks {
+				total++
+				percent := "-"
+				if task.PercentComplete != nil {
+					percent = strconv.Itoa(*task.PercentComplete) + "%"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", targets[i].label(), task.ID, valueOrDash(task.Name), valueOrDash(task.TaskState), percent)
+			}
+		}
+		if total > 0 {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Tasks: %d task(s), %d BMC(s) without TaskService, %d failed\n", total, unsupported, failed)
+		// A BMC without a TaskService answered correctly; it is not a failure
+		if err := checkOutcome(ok+unsupported, failed, "listing tasks failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var tasksShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the full task document, including Messages, from every selected BMC",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if taskID == "" {
+			return errors.New("--id is required")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(taskFile, taskHostsCSV, taskHostsFile)
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		var ok, unsupported, failed int
+		forEachTarget(cmd.Context(), targets, taskBatchSize, taskTimeout, func(ctx context.Context, t bmcTarget) {
+			raw, err := redfish.GetTask(ctx, t.Host, user, pass, taskInsecure, taskTimeout, taskID)
+			var buf bytes.Buffer
+			if err == nil {
+				err = json.Indent(&buf, raw, "", "  ")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrTaskServiceUnsupported):
+				unsupported++
+				diag.Infof("%s: TaskService not supported", t.label())
+			case err != nil:
+				failed++
+				diag.Warnf("%s: show task %s: %v", t.label(), taskID, err)
+			default:
+				ok++
+				fmt.Printf("%s:\n%s\n", t.label(), buf.String())
+			}
+		})
+		fmt.Printf("Show task %s: %d found, %d unsupported, %d failed\n", taskID, ok, unsupported, failed)
+		if err := checkOutcome(ok+unsupported, failed, "task %s could not be read on %d BMC(s)", taskID, failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+var tasksCancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancel a task on every selected BMC (Task.Cancel action, or DELETE)",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if taskID == "" {
+			return errors.New("--id is required")
+		}
+		user, pass, err := redfishCredentials()
+		if err != nil {
+			return err
+		}
+		targets, err := resolveHosts(taskFile, taskHostsCSV, taskHostsFile)
+		if err != nil {
+			return err
+		}
+
+		if taskDryRun {
+			for _, t := range targets {
+				fmt.Printf("[dry-run] would cancel task %s on %s (%s)\n", taskID, t.label(), t.Host)
+			}
+			return nil
+		}
+
+		var mu sync.Mutex
+		var ok, unsupported, failed int
+		forEachTarget(cmd.Context(), targets, taskBatchSize, taskTimeout, func(ctx context.Context, t bmcTarget) {
+			err := redfish.CancelTask(ctx, t.Host, user, pass, taskInsecure, taskTimeout, taskID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, redfish.ErrTaskServiceUnsupported):
+				unsupported++
+				diag.Infof("%s: TaskService not supported", t.label())
+			case err != nil:
+				failed++
+				diag.Warnf("%s: cancel task %s: %v", t.label(), taskID, err)
+			default:
+				ok++
+				diag.Infof("%s: cancelled task %s", t.label(), taskID)
+			}
+		})
+
+		fmt.Printf("Cancel task %s: %d succeeded, %d unsupported, %d failed\n", taskID, ok, unsupported, failed)
+		if err := checkOutcome(ok+unsupported, failed, "cancel failed on %d BMC(s)", failed); err != nil {
+			return err
+		}
+		return checkInterrupted(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tasksCmd)
+	tasksCmd.AddCommand(tasksListCmd, tasksShowCmd, tasksCancelCmd)
+	tasksCmd.PersistentFlags().StringVarP(&taskFile, "file", "f", "", "Inventory file to read bmcs[] from when --hosts is not provided")
+	tasksCmd.PersistentFlags().StringVar(&taskHostsCSV, "hosts", "", "Comma-separated list of BMC hosts to target (overrides --file; merged with --hosts-file)")
+	tasksCmd.PersistentFlags().StringVar(&taskHostsFile, "hosts-file", "", "File listing BMC hosts to target, one host or host,xname per line (overrides --file)")
+	tasksCmd.PersistentFlags().BoolVar(&taskInsecure, "insecure", false, "skip TLS certificate verification for BMCs")
+	tasksCmd.PersistentFlags().DurationVar(&taskTimeout, "timeout", 30*time.Second, "per-BMC request timeout")
+	tasksCmd.PersistentFlags().IntVar(&taskBatchSize, "batch-size", 0, "number of BMCs to contact concurrently (0 or 1 = serial, >1 = parallel)")
+	tasksShowCmd.Flags().StringVar(&taskID, "id", "", "task Id")
+	tasksCancelCmd.Flags().StringVar(&taskID, "id", "", "task Id")
+	tasksCancelCmd.Flags().BoolVar(&taskDryRun, "dry-run", false, "plan only: print which tasks would be cancelled")
+}
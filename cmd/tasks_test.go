@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTasksListReportsUnsupportedHosts(t *testing.T) {
+	withTasks := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/TaskService/Tasks":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/TaskService/Tasks/7"}]}`)
+		case "/redfish/v1/TaskService/Tasks/7":
+			fmt.Fprint(w, `{"Id":"7","Name":"BIOS Update","TaskState":"Running","PercentComplete":15}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer withTasks.Close()
+	without := httptest.NewTLSServer(http.NotFoundHandler())
+	defer without.Close()
+
+	taskHostsCSV = strings.TrimPrefix(withTasks.URL, "https://") + "," + strings.TrimPrefix(without.URL, "https://")
+	taskInsecure = true
+	taskTimeout = 5 * time.Second
+	t.Setenv("REDFISH_USER", "user")
+	t.Setenv("REDFISH_PASSWORD", "pass")
+
+	tasksListCmd.SetContext(context.Background())
+	out, err := captureOutput(t, func() error { return tasksListCmd.RunE(tasksListCmd, nil) })
+	if err != nil {
+		t.Fatalf("unsupported hosts must not fail the command: %v\n%s", err, out)
+	}
+	for _, want := range []string{"BIOS Update", "Running", "15%", "TaskService not supported", "1 task(s), 1 BMC(s) without TaskService, 0 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"bootstrap/internal/inventory"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	valFile   string
+	valSubnet string
+	valStrict bool
+	valSchema bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Lint an inventory file for malformed or duplicate records",
+	RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
+		if valSchema {
+			_, err := os.Stdout.Write(inventory.Schema())
+			return err
+		}
+		if valFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		doc, err := readInventory(valFile)
+		if err != nil {
+			return err
+		}
+		violations, err := inventory.Validate(doc, valSubnet)
+		if err != nil {
+			return err
+		}
+		var errs, warns int
+		for _, v := range violations {
+			if valStrict {
+				v.Severity = inventory.Error
+			}
+			if v.Severity == inventory.Error {
+				errs++
+			} else {
+				warns++
+			}
+			fmt.Println(v)
+		}
+		fmt.Printf("%s: %d error(s), %d warning(s)\n", valFile, errs, warns)
+		if errs > 0 {
+			return fmt.Errorf("%s is invalid", valFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVarP(&valFile, "file", "f", "", "Inventory file to validate")
+	validateCmd.Flags().StringVar(&valSubnet, "subnet", "", "Node subnet (CIDR); node IPs outside it are errors")
+	validateCmd.Flags().BoolVar(&valStrict, "strict", false, "treat missing MACs and IPs as errors")
+	validateCmd.Flags().BoolVar(&valSchema, "schema", false, "print the JSON Schema of the inventory file instead of validating one")
+}
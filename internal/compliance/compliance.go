@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package compliance checks the firmware versions of BMCs against a
+// manifest of blessed versions per component type and hardware model.
+package compliance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"bootstrap/internal/xname"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Component types of a Rule, which are also the kinds of host a rule
+// applies to: node controllers carry the nC and BIOS components, chassis
+// controllers the cC one.
+const (
+	TypeNC   = "nC"
+	TypeCC   = "cC"
+	TypeBIOS = "BIOS"
+)
+
+// Results of a Check and of a Host.
+const (
+	Compliant = "compliant"
+	Outdated  = "outdated"
+	// Unknown is a component no rule or no FirmwareInventory member
+	// accounts for, or a host that could not be read.
+	Unknown = "unknown"
+)
+
+// defaultComponents are the FirmwareInventory Ids a rule of each type
+// matches when it names none.
+var defaultComponents = map[string]string{
+	TypeNC:   "BMC",
+	TypeCC:   "BMC",
+	TypeBIOS: "*BIOS",
+}
+
+// Rule is the blessed version of one component type, for one hardware
+// model or, without Model, for every model that no other rule of its type
+// names.
+type Rule struct {
+	// Name identifies the rule in reports and remediation files; it
+	// defaults to Type, or Type-Model.
+	Name string `yaml:"name,omitempty" json:"name"`
+	Type string `yaml:"type" json:"type"`
+	// Model matches, ignoring case, the Model of the host's Manager or of
+	// one of its systems. It may be a path.Match pattern such as EX4*.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Component matches, ignoring case, the Ids of the FirmwareInventory
+	// members the rule checks, as a path.Match pattern; it defaults to BMC,
+	// or *BIOS for the BIOS type.
+	Component string `yaml:"component,omitempty" json:"component,omitempty"`
+	Version   string `yaml:"version" json:"version"`
+}
+
+// Manifest is the list of rules read from a manifest file.
+type Manifest struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// ParseManifest parses a YAML (or JSON) manifest, fills in the defaults of
+// its rules, and checks them.
+func ParseManifest(raw []byte) (Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return m, err
+	}
+	if len(m.Rules) == 0 {
+		return m, errors.New("manifest has no rules")
+	}
+	names := map[string]bool{}
+	for i := range m.Rules {
+		r := &m.Rules[i]
+		switch {
+		case strings.EqualFold(r.Type, TypeNC):
+			r.Type = TypeNC
+		case strings.EqualFold(r.Type, TypeCC):
+			r.Type = TypeCC
+		case strings.EqualFold(r.Type, TypeBIOS):
+			r.Type = TypeBIOS
+		default:
+			return m, fmt.Errorf("rule %d: type %q must be %s, %s, or %s", i+1, r.Type, TypeNC, TypeCC, TypeBIOS)
+		}
+		if r.Version == "" {
+			return m, fmt.Errorf("rule %d: version is required", i+1)
+		}
+		if r.Component == "" {
+			r.Component = defaultComponents[r.Type]
+		}
+		for _, p := range []string{r.Model, r.Component} {
+			if _, err := path.Match(p, ""); err != nil {
+				return m, fmt.Errorf("rule %d: pattern %q: %w", i+1, p, err)
+			}
+		}
+		if r.Name == "" {
+			r.Name = r.Type
+			if r.Model != "" {
+				r.Name += "-" + r.Model
+			}
+		}
+		if names[r.Name] {
+			return m, fmt.Errorf("rule %d: name %q is used twice (set name: on one of them)", i+1, r.Name)
+		}
+		names[r.Name] = true
+	}
+	return m, nil
+}
+
+// KindOf returns the kind of host a BMC is, TypeNC or TypeCC, from its
+// xname or, without one, from whether it reports any systems.
+func KindOf(x string, hasSystems bool) string {
+	if _, _, ok := xname.ParseChassisBMC(x); ok {
+		return TypeCC
+	}
+	if parsed, err := xname.Parse(x); err == nil && parsed.Kind == xname.KindBMC {
+		return TypeNC
+	}
+	if hasSystems {
+		return TypeNC
+	}
+	return TypeCC
+}
+
+// Component is a FirmwareInventory member of a host.
+type Component struct {
+	ID      string
+	Version string
+}
+
+// Host is what was read from one BMC.
+type Host struct {
+	Kind       string   // TypeNC or TypeCC (see KindOf)
+	Models     []string // of its Manager and systems, in any order
+	Components []Component
+}
+
+// Check is the outcome of one rule on one component of a host. Component
+// and Actual are empty when the host reports no component the rule matches.
+type Check struct {
+	Rule      string `json:"rule"`
+	Component string `json:"component,omitempty"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual,omitempty"`
+	Result    string `json:"result"`
+}
+
+// Result is the compliance of one host.
+type Result struct {
+	// Result is Outdated when a check is, else Unknown when a check is or
+	// no rule applies to the host, else Compliant.
+	Result string  `json:"result"`
+	Checks []Check `json:"checks"`
+}
+
+// Evaluate checks h against the rules of m. For each type that applies to
+// h's kind, the first rule naming one of its models is used, else the
+// first rule of that type without a model.
+func (m Manifest) Evaluate(h Host) Result {
+	types := []string{TypeCC}
+	if h.Kind == TypeNC {
+		types = []string{TypeNC, TypeBIOS}
+	}
+	var res Result
+	for _, t := range types {
+		r, ok := m.ruleFor(t, h.Models)
+		if !ok {
+			continue
+		}
+		found := false
+		for _, c := range h.Components {
+			if !match(r.Component, c.ID) {
+				continue
+			}
+			found = true
+			check := Check{Rule: r.Name, Component: c.ID, Expected: r.Version, Actual: c.Version, Result: Compliant}
+			if c.Version != r.Version {
+				check.Result = Outdated
+			}
+			res.Checks = append(res.Checks, check)
+		}
+		if !found {
+			res.Checks = append(res.Checks, Check{Rule: r.Name, Expected: r.Version, Result: Unknown})
+		}
+	}
+	res.Result = Compliant
+	if len(res.Checks) == 0 {
+		res.Result = Unknown
+	}
+	for _, c := range res.Checks {
+		switch {
+		case c.Result == Outdated:
+			res.Result = Outdated
+		case c.Result == Unknown && res.Result == Compliant:
+			res.Result = Unknown
+		}
+	}
+	return res
+}
+
+// ruleFor returns the rule of type t for a host with models.
+func (m Manifest) ruleFor(t string, models []string) (Rule, bool) {
+	var fallback *Rule
+	for i, r := range m.Rules {
+		if r.Type != t {
+			continue
+		}
+		if r.Model == "" {
+			if fallback == nil {
+				fallback = &m.Rules[i]
+			}
+			continue
+		}
+		for _, model := range models {
+			if match(r.Model, model) {
+				return r, true
+			}
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return Rule{}, false
+}
+
+// match reports whether s matches pattern, ignoring case.
+func match(pattern, s string) bool {
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(s))
+	return ok
+}
+
+// Summary counts the hosts of a report by Result.
+type Summary struct {
+	Hosts     int `json:"hosts"`
+	Compliant int `json:"compliant"`
+	Outdated  int `json:"outdated"`
+	Unknown   int `json:"unknown"`
+}
+
+// Add counts a host with result.
+func (s *Summary) Add(result string) {
+	s.Hosts++
+	switch result {
+	case Compliant:
+		s.Compliant++
+	case Outdated:
+		s.Outdated++
+	default:
+		s.Unknown++
+	}
+}
+
+// Percent returns n as a percentage of the hosts, 0 when there are none.
+func (s Summary) Percent(n int) float64 {
+	if s.Hosts == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(s.Hosts)
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package compliance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const manifest = `rules:
+  - type: nc
+    model: EX425
+    version: nc.1.9.8
+  - type: nC
+    version: nc.1.9.0
+  - type: cC
+    version: cc.1.9.8
+  - name: bios-ex425
+    type: BIOS
+    model: ex4*
+    version: ex425.bios-1.2.0
+`
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rule{
+		{Name: "nC-EX425", Type: TypeNC, Model: "EX425", Component: "BMC", Version: "nc.1.9.8"},
+		{Name: "nC", Type: TypeNC, Component: "BMC", Version: "nc.1.9.0"},
+		{Name: "cC", Type: TypeCC, Component: "BMC", Version: "cc.1.9.8"},
+		{Name: "bios-ex425", Type: TypeBIOS, Model: "ex4*", Component: "*BIOS", Version: "ex425.bios-1.2.0"},
+	}
+	if !reflect.DeepEqual(m.Rules, want) {
+		t.Errorf("rules = %+v\nwant %+v", m.Rules, want)
+	}
+
+	for _, bad := range []struct{ name, manifest, err string }{
+		{"empty", "rules: []\n", "no rules"},
+		{"type", "rules:\n  - type: PDU\n    version: 1\n", `type "PDU"`},
+		{"version", "rules:\n  - type: nC\n", "version is required"},
+		{"duplicate name", "rules:\n  - type: nC\n    version: 1\n  - type: nC\n    version: 2\n", `name "nC" is used twice`},
+		{"pattern", "rules:\n  - type: nC\n    model: \"[\"\n    version: 1\n", "pattern"},
+		{"unknown key", "rules:\n  - type: nC\n    versoin: 1\n", "versoin"},
+	} {
+		if _, err := ParseManifest([]byte(bad.manifest)); err == nil || !strings.Contains(err.Error(), bad.err) {
+			t.Errorf("%s: err = %v, want %q", bad.name, err, bad.err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	m, err := ParseManifest([]byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		host   Host
+		result string
+		checks []Check
+	}{
+		{
+			name: "model rule wins over the generic one",
+			host: Host{Kind: TypeNC, Models: []string{"WNC", "ex425"}, Components: []Component{
+				{"BMC", "nc.1.9.8"}, {"Node0.BIOS", "ex425.bios-1.2.0"}, {"Node1.BIOS", "ex425.bios-1.2.0"}, {"Node0.AccFPGA0", "x"},
+			}},
+			result: Compliant,
+			checks: []Check{
+				{Rule: "nC-EX425", Component: "BMC", Expected: "nc.1.9.8", Actual: "nc.1.9.8", Result: Compliant},
+				{Rule: "bios-ex425", Component: "Node0.BIOS", Expected: "ex425.bios-1.2.0", Actual: "ex425.bios-1.2.0", Result: Compliant},
+				{Rule: "bios-ex425", Component: "Node1.BIOS", Expected: "ex425.bios-1.2.0", Actual: "ex425.bios-1.2.0", Result: Compliant},
+			},
+		},
+		{
+			name:   "outdated beats unknown",
+			host:   Host{Kind: TypeNC, Models: []string{"EX235"}, Components: []Component{{"BMC", "nc.1.8.0"}}},
+			result: Outdated,
+			checks: []Check{
+				{Rule: "nC", Component: "BMC", Expected: "nc.1.9.0", Actual: "nc.1.8.0", Result: Outdated},
+			},
+		},
+		{
+			name:   "component missing",
+			host:   Host{Kind: TypeNC, Models: []string{"EX425"}, Components: []Component{{"BMC", "nc.1.9.8"}}},
+			result: Unknown,
+			checks: []Check{
+				{Rule: "nC-EX425", Component: "BMC", Expected: "nc.1.9.8", Actual: "nc.1.9.8", Result: Compliant},
+				{Rule: "bios-ex425", Expected: "ex425.bios-1.2.0", Result: Unknown},
+			},
+		},
+		{
+			name:   "chassis controller",
+			host:   Host{Kind: TypeCC, Components: []Component{{"BMC", "cc.1.9.8"}, {"Node0.BIOS", "old"}}},
+			result: Compliant,
+			checks: []Check{
+				{Rule: "cC", Component: "BMC", Expected: "cc.1.9.8", Actual: "cc.1.9.8", Result: Compliant},
+			},
+		},
+	}
+	for _, c := range cases {
+		res := m.Evaluate(c.host)
+		if res.Result != c.result || !reflect.DeepEqual(res.Checks, c.checks) {
+			t.Errorf("%s: got %s %+v\nwant %s %+v", c.name, res.Result, res.Checks, c.result, c.checks)
+		}
+	}
+
+	// No rule for the host's kind at all
+	only, _ := ParseManifest([]byte("rules:\n  - type: cC\n    version: 1\n"))
+	if res := only.Evaluate(Host{Kind: TypeNC, Components: []Component{{"BMC", "1"}}}); res.Result != Unknown || len(res.Checks) != 0 {
+		t.Errorf("host without rules = %+v, want unknown", res)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	for _, c := range []struct {
+		xname      string
+		hasSystems bool
+		want       string
+	}{
+		{"x9000c1s0b0", false, TypeNC},
+		{"X9000C1B0", true, TypeCC},
+		{"", true, TypeNC},
+		{"", false, TypeCC},
+	} {
+		if got := KindOf(c.xname, c.hasSystems); got != c.want {
+			t.Errorf("KindOf(%q, %v) = %s, want %s", c.xname, c.hasSystems, got, c.want)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var s Summary
+	for _, r := range []string{Compliant, Compliant, Outdated, Unknown} {
+		s.Add(r)
+	}
+	if s != (Summary{Hosts: 4, Compliant: 2, Outdated: 1, Unknown: 1}) || s.Percent(s.Compliant) != 50 {
+		t.Errorf("summary = %+v, %.1f%% compliant", s, s.Percent(s.Compliant))
+	}
+	if (Summary{}).Percent(0) != 0 {
+		t.Error("Percent of no hosts should be 0")
+	}
+}
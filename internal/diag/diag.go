@@ -3,20 +3,198 @@
 // SPDX-License-Identifier: MIT
 
 // Package diag implements diagnostic logging utilities.
+//
+// In the default text format, progress lines go to stdout and warnings to
+// stderr prefixed with "WARN: ", exactly as the commands have always printed
+// them. With the json format every record is written to stderr as a JSON
+// object. Final summaries are not logged; commands print them directly.
 package diag
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"time"
 )
 
 // Debug enables extra logging when true.
 var Debug bool
 
+var (
+	level   = slog.LevelInfo
+	jsonLog *slog.Logger // nil in text mode
+)
+
+// Configure sets the verbosity and output format. verbose enables debug
+// records (including one per HTTP request); quiet suppresses progress lines,
+// leaving warnings (per-host failures) and errors. format is "text" (or empty)
+// or "json".
+func Configure(verbose, quiet bool, format string) error {
+	if verbose && quiet {
+		return fmt.Errorf("--verbose and --quiet are mutually exclusive")
+	}
+	switch {
+	case verbose:
+		level = slog.LevelDebug
+	case quiet:
+		level = slog.LevelWarn
+	default:
+		level = slog.LevelInfo
+	}
+	Debug = verbose
+	switch format {
+	case "", "text":
+		jsonLog = nil
+	case "json":
+		jsonLog = slog.New(slog.NewJSONHandler(stderr{}, &slog.HandlerOptions{Level: level}))
+	default:
+		return fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+	return nil
+}
+
+// stderr writes to whatever os.Stderr is at the time of the call.
+type stderr struct{}
+
+func (stderr) Write(p []byte) (int, error) { return os.Stderr.Write(p) }
+
 // Logf writes formatted debug logs to stderr when Debug is true.
 func Logf(format string, args ...any) {
 	if !Debug {
 		return
 	}
+	if jsonLog != nil {
+		jsonLog.Debug(fmt.Sprintf(format, args...))
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
 	fmt.Fprintf(os.Stderr, "[DEBUG] "+format+"\n", args...)
 }
+
+// Infof writes a progress line: to stdout in text mode, as an info record in
+// json mode. It is suppressed by quiet.
+func Infof(format string, args ...any) {
+	if level > slog.LevelInfo {
+		return
+	}
+	if jsonLog != nil {
+		jsonLog.Info(fmt.Sprintf(format, args...))
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	fmt.Fprintf(os.Stdout, format+"\n", args...)
+}
+
+// Warnf writes a warning: "WARN: ..." on stderr in text mode, a warn record
+// in json mode.
+func Warnf(format string, args ...any) {
+	if level > slog.LevelWarn {
+		return
+	}
+	if jsonLog != nil {
+		jsonLog.Warn(fmt.Sprintf(format, args...))
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	fmt.Fprintf(os.Stderr, "WARN: "+format+"\n", args...)
+}
+
+// HostWarnf writes a warning about a failed host with its failure category:
+// "WARN: <host>: ... [<category>]" in text mode, a warn record with host and
+// category attributes in json mode.
+func HostWarnf(host, category, format string, args ...any) {
+	if level > slog.LevelWarn {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if jsonLog != nil {
+		jsonLog.Warn(host+": "+msg, slog.String("host", host), slog.String("category", category))
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	fmt.Fprintf(os.Stderr, "WARN: %s: %s [%s]\n", host, msg, category)
+}
+
+// ChangeWarnf writes a warning about a field of an inventory entry that
+// changed: "WARN: ..." in text mode, a warn record with xname, field, old,
+// and new attributes in json mode.
+func ChangeWarnf(xname, field, from, to, format string, args ...any) {
+	if level > slog.LevelWarn {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if jsonLog != nil {
+		jsonLog.Warn(msg, slog.String("xname", xname), slog.String("field", field), slog.String("old", from), slog.String("new", to))
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	fmt.Fprintf(os.Stderr, "WARN: %s\n", msg)
+}
+
+// Errorf writes an error to stderr (plain text, or an error record in json mode).
+func Errorf(format string, args ...any) {
+	if jsonLog != nil {
+		jsonLog.Error(fmt.Sprintf(format, args...))
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// Transport wraps rt so that, in verbose mode, every request is logged with
+// its method, URL, response status, and latency, and so that every request
+// is written to the trace file while ConfigureTrace is in effect.
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	return loggingTransport{rt: rt}
+}
+
+type loggingTransport struct {
+	rt http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	var resp *http.Response
+	var err error
+	if on, limit := tracing(); on {
+		resp, err = traceRoundTrip(t.rt, req, limit)
+	} else {
+		resp, err = t.rt.RoundTrip(req)
+	}
+	status := ""
+	if err == nil {
+		status = resp.Status
+	}
+	logRequest(req.Method, req.URL.Redacted(), status, time.Since(start))
+	return resp, err
+}
+
+// logRequest logs a completed HTTP request at debug level. status is empty
+// if the request failed.
+func logRequest(method, url, status string, latency time.Duration) {
+	if !Debug {
+		return
+	}
+	if jsonLog != nil {
+		jsonLog.LogAttrs(context.Background(), slog.LevelDebug, "request",
+			slog.String("method", method),
+			slog.String("url", url),
+			slog.String("status", status),
+			slog.Duration("latency", latency))
+		return
+	}
+	if status == "" {
+		status = "error"
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	fmt.Fprintf(os.Stderr, "[DEBUG] %s %s -> %s (%s)\n", method, url, status, latency.Round(time.Millisecond))
+}
@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package diag
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// capture returns what f writes to stdout and stderr.
+func capture(t *testing.T, f func()) (string, string) {
+	t.Helper()
+	oldOut, oldErr := os.Stdout, os.Stderr
+	or, ow, _ := os.Pipe()
+	er, ew, _ := os.Pipe()
+	os.Stdout, os.Stderr = ow, ew
+	f()
+	ow.Close() //nolint:errcheck
+	ew.Close() //nolint:errcheck
+	os.Stdout, os.Stderr = oldOut, oldErr
+	out, _ := io.ReadAll(or)
+	errOut, _ := io.ReadAll(er)
+	return string(out), string(errOut)
+}
+
+func TestTextModeMatchesPlainOutput(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(false, false, "text") })
+	if err := Configure(false, false, "text"); err != nil {
+		t.Fatal(err)
+	}
+	out, errOut := capture(t, func() {
+		Infof("Triggered firmware update on %s", "10.0.0.1")
+		Warnf("%s: discover: %v", "x1000c0s0b0", "timeout")
+		Logf("hidden")
+	})
+	if out != "Triggered firmware update on 10.0.0.1\n" || errOut != "WARN: x1000c0s0b0: discover: timeout\n" {
+		t.Errorf("stdout=%q stderr=%q", out, errOut)
+	}
+
+	if err := Configure(false, true, "text"); err != nil {
+		t.Fatal(err)
+	}
+	out, errOut = capture(t, func() {
+		Infof("progress")
+		Warnf("still shown")
+	})
+	if out != "" || errOut != "WARN: still shown\n" {
+		t.Errorf("quiet: stdout=%q stderr=%q", out, errOut)
+	}
+}
+
+func TestJSONModeLogsRequests(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(false, false, "text") })
+	if err := Configure(true, false, "json"); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	client := &http.Client{Transport: Transport(http.DefaultTransport)}
+
+	out, errOut := capture(t, func() {
+		resp, err := client.Get(srv.URL + "/redfish/v1")
+		if err == nil {
+			resp.Body.Close() //nolint:errcheck
+		}
+		Warnf("careful")
+	})
+	if out != "" {
+		t.Errorf("json mode should not write to stdout, got %q", out)
+	}
+	lines := strings.Split(strings.TrimSpace(errOut), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got:\n%s", errOut)
+	}
+	var req map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req["msg"] != "request" || req["method"] != "GET" || req["status"] != "200 OK" || req["latency"] == nil {
+		t.Errorf("unexpected request record: %v", req)
+	}
+	if !strings.Contains(lines[1], `"level":"WARN"`) {
+		t.Errorf("unexpected warn record: %s", lines[1])
+	}
+}
+
+func TestHostWarnfCategory(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(false, false, "text") })
+	if err := Configure(false, false, "text"); err != nil {
+		t.Fatal(err)
+	}
+	_, errOut := capture(t, func() { HostWarnf("x1000c0s0b0", "auth", "discover: %s", "401 Unauthorized") })
+	if errOut != "WARN: x1000c0s0b0: discover: 401 Unauthorized [auth]\n" {
+		t.Errorf("text: %q", errOut)
+	}
+
+	if err := Configure(false, false, "json"); err != nil {
+		t.Fatal(err)
+	}
+	_, errOut = capture(t, func() { HostWarnf("x1000c0s0b0", "auth", "discover: %s", "401 Unauthorized") })
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(errOut), &rec); err != nil {
+		t.Fatalf("%v: %s", err, errOut)
+	}
+	if rec["host"] != "x1000c0s0b0" || rec["category"] != "auth" || rec["msg"] != "x1000c0s0b0: discover: 401 Unauthorized" {
+		t.Errorf("json: %v", rec)
+	}
+}
+
+func TestConfigureRejectsBadInput(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(false, false, "text") })
+	if err := Configure(true, true, "text"); err == nil {
+		t.Error("expected error for --verbose with --quiet")
+	}
+	if err := Configure(false, false, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestChangeWarnfAttributes(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(false, false, "text") })
+	if err := Configure(false, false, "json"); err != nil {
+		t.Fatal(err)
+	}
+	_, errOut := capture(t, func() {
+		ChangeWarnf("x1000c0s0b0n0", "mac", "aa:bb:cc:dd:ee:00", "aa:bb:cc:dd:ee:01", "%s: MAC changed", "x1000c0s0b0n0")
+	})
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(errOut), &rec); err != nil {
+		t.Fatalf("%v: %s", err, errOut)
+	}
+	if rec["xname"] != "x1000c0s0b0n0" || rec["field"] != "mac" || rec["old"] != "aa:bb:cc:dd:ee:00" || rec["new"] != "aa:bb:cc:dd:ee:01" {
+		t.Errorf("json: %v", rec)
+	}
+}
+
+func TestHostLogFlushesAsOneBlock(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(false, false, "text"); ConfigureStream(false) })
+	if err := Configure(false, false, "text"); err != nil {
+		t.Fatal(err)
+	}
+	out, errOut := capture(t, func() {
+		a, b := NewHostLog("x1000c0s0b0"), NewHostLog("x1000c0s1b0")
+		a.Infof("triggered")
+		b.Failf("timeout", "firmware update failed: %v", "deadline exceeded")
+		a.Warnf("slow to answer")
+		Infof("between")
+		b.Flush()
+		a.Flush()
+		a.Flush() // nothing left
+	})
+	if out != "between\nx1000c0s0b0: triggered\n" {
+		t.Errorf("stdout=%q", out)
+	}
+	if errOut != "WARN: x1000c0s1b0: firmware update failed: deadline exceeded [timeout]\nWARN: x1000c0s0b0: slow to answer\n" {
+		t.Errorf("stderr=%q", errOut)
+	}
+
+	// Streamed lines are written at once
+	ConfigureStream(true)
+	out, _ = capture(t, func() {
+		a := NewHostLog("x1000c0s0b0")
+		a.Infof("waiting")
+		Infof("between")
+		a.Flush()
+	})
+	if out != "x1000c0s0b0: waiting\nbetween\n" {
+		t.Errorf("stream: stdout=%q", out)
+	}
+}
+
+func TestHostLogInfoAttrs(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(false, false, "text") })
+	attrs := []slog.Attr{slog.String("nic", "eth0"), slog.Int("rank", 1)}
+	if err := Configure(false, false, "text"); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := capture(t, func() {
+		h := NewHostLog("x1000c0s0b0")
+		h.InfoAttrsf(attrs, "NIC %s: chosen", "eth0")
+		h.Flush()
+	})
+	if out != "x1000c0s0b0: NIC eth0: chosen\n" {
+		t.Errorf("text: %q", out)
+	}
+
+	if err := Configure(false, false, "json"); err != nil {
+		t.Fatal(err)
+	}
+	_, errOut := capture(t, func() {
+		h := NewHostLog("x1000c0s0b0")
+		h.InfoAttrsf(attrs, "NIC %s: chosen", "eth0")
+		h.Flush()
+	})
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(errOut), &rec); err != nil {
+		t.Fatalf("%v: %s", err, errOut)
+	}
+	if rec["host"] != "x1000c0s0b0" || rec["nic"] != "eth0" || rec["rank"] != float64(1) || rec["msg"] != "x1000c0s0b0: NIC eth0: chosen" {
+		t.Errorf("json: %v", rec)
+	}
+}
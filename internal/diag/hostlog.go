@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package diag
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// outputMu serializes writes to stdout and stderr, so a flushed HostLog is
+// never split by another line.
+var outputMu sync.Mutex
+
+// stream is set by ConfigureStream.
+var stream bool
+
+// ConfigureStream makes every HostLog write its lines as they are logged
+// instead of when it is flushed. Lines of different hosts then interleave,
+// but long waits show progress.
+func ConfigureStream(on bool) {
+	stream = on
+}
+
+// HostLog collects the progress lines and warnings of one host of a parallel
+// run, each prefixed with the host's label, and writes them as one block on
+// Flush so that the lines of different hosts do not interleave. In stream
+// mode (ConfigureStream) lines are written at once. A HostLog is safe for
+// concurrent use.
+type HostLog struct {
+	label string
+
+	mu      sync.Mutex
+	records []hostRecord
+}
+
+type hostRecord struct {
+	level    slog.Level
+	category string
+	msg      string
+	attrs    []slog.Attr
+}
+
+// NewHostLog returns an empty HostLog for the host named label (its xname,
+// or its address when it has none).
+func NewHostLog(label string) *HostLog {
+	return &HostLog{label: label}
+}
+
+// Infof logs a progress line, "<label>: ..." on stdout in text mode.
+func (h *HostLog) Infof(format string, args ...any) {
+	h.add(slog.LevelInfo, "", fmt.Sprintf(format, args...))
+}
+
+// InfoAttrsf logs a progress line like Infof that, in json mode, also
+// carries attrs, for records whose fields are worth querying.
+func (h *HostLog) InfoAttrsf(attrs []slog.Attr, format string, args ...any) {
+	h.addRecord(hostRecord{level: slog.LevelInfo, msg: fmt.Sprintf(format, args...), attrs: attrs})
+}
+
+// Warnf logs a warning, "WARN: <label>: ..." on stderr in text mode.
+func (h *HostLog) Warnf(format string, args ...any) {
+	h.add(slog.LevelWarn, "", fmt.Sprintf(format, args...))
+}
+
+// Failf logs that the host failed, with the failure category, like
+// HostWarnf.
+func (h *HostLog) Failf(category, format string, args ...any) {
+	h.add(slog.LevelWarn, category, fmt.Sprintf(format, args...))
+}
+
+func (h *HostLog) add(l slog.Level, category, msg string) {
+	h.addRecord(hostRecord{level: l, category: category, msg: msg})
+}
+
+func (h *HostLog) addRecord(r hostRecord) {
+	if level > r.level {
+		return
+	}
+	if stream {
+		outputMu.Lock()
+		defer outputMu.Unlock()
+		h.write(r)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+}
+
+// Flush writes the lines logged since the last Flush as one block.
+func (h *HostLog) Flush() {
+	h.mu.Lock()
+	records := h.records
+	h.records = nil
+	h.mu.Unlock()
+	if len(records) == 0 {
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	for _, r := range records {
+		h.write(r)
+	}
+}
+
+func (h *HostLog) write(r hostRecord) {
+	if jsonLog != nil {
+		attrs := []any{slog.String("host", h.label)}
+		if r.category != "" {
+			attrs = append(attrs, slog.String("category", r.category))
+		}
+		for _, a := range r.attrs {
+			attrs = append(attrs, a)
+		}
+		jsonLog.Log(context.Background(), r.level, h.label+": "+r.msg, attrs...)
+		return
+	}
+	switch {
+	case r.level < slog.LevelWarn:
+		fmt.Fprintf(os.Stdout, "%s: %s\n", h.label, r.msg)
+	case r.category != "":
+		fmt.Fprintf(os.Stderr, "WARN: %s: %s [%s]\n", h.label, r.msg, r.category)
+	default:
+		fmt.Fprintf(os.Stderr, "WARN: %s: %s\n", h.label, r.msg)
+	}
+}
+
+type hostLogKey struct{}
+
+// WithHostLog returns a copy of ctx carrying h, for code deep in a host's
+// work to log through.
+func WithHostLog(ctx context.Context, h *HostLog) context.Context {
+	return context.WithValue(ctx, hostLogKey{}, h)
+}
+
+// HostLogFrom returns the HostLog carried by ctx, or nil.
+func HostLogFrom(ctx context.Context) *HostLog {
+	h, _ := ctx.Value(hostLogKey{}).(*HostLog)
+	return h
+}
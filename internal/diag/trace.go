@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTraceBodyLimit is how many bytes of each request and response body
+// a trace records by default.
+const DefaultTraceBodyLimit = 4096
+
+// redacted replaces credentials in a trace.
+const redacted = "REDACTED"
+
+// secretHeaders carry credentials or session tokens and are never traced.
+var secretHeaders = []string{"Authorization", "Proxy-Authorization", "X-Auth-Token", "Cookie", "Set-Cookie"}
+
+// secretFields matches JSON string members holding passwords, e.g. the body
+// of a SessionService login or an account PATCH, including a value cut off
+// by the body limit.
+var secretFields = regexp.MustCompile(`(?i)("[a-z]*password"\s*:\s*)"(?:[^"\\]|\\.)*(?:"|\\?$)`)
+
+// privateKeys matches PEM private key blocks inside a JSON string, such as
+// the key a certificate install sends with its certificate in
+// CertificateString: up to the END line, or to the end of the string when
+// the body limit or a malformed key cuts it short.
+var privateKeys = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:(?:[^"\\]|\\.)*?-----END [A-Z ]*PRIVATE KEY-----|(?:[^"\\]|\\.)*\\?)`)
+
+var (
+	traceMu    sync.Mutex
+	traceFile  *os.File // nil when tracing is off
+	traceLimit int
+)
+
+// TraceEntry is one line of a --trace file.
+type TraceEntry struct {
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	Status          int         `json:"status,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+	// Truncated is set when a body was longer than the limit.
+	Truncated bool    `json:"truncated,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ConfigureTrace starts writing a TraceEntry for every request made through
+// Transport to path as JSON lines, keeping up to bodyLimit bytes of each
+// body. An empty path turns tracing off.
+func ConfigureTrace(path string, bodyLimit int) error {
+	if err := CloseTrace(); err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	traceFile, traceLimit = f, bodyLimit
+	return nil
+}
+
+// CloseTrace stops tracing and closes the trace file.
+func CloseTrace() error {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if traceFile == nil {
+		return nil
+	}
+	err := traceFile.Close()
+	traceFile = nil
+	return err
+}
+
+func tracing() (bool, int) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	return traceFile != nil, traceLimit
+}
+
+func writeTrace(e *TraceEntry) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if traceFile != nil {
+		_, _ = traceFile.Write(append(line, '\n'))
+	}
+}
+
+// traceRoundTrip performs req with rt and traces it. The response body is
+// recorded as the caller reads it, and the entry is written when the body
+// is closed, so large downloads are not buffered.
+func traceRoundTrip(rt http.RoundTripper, req *http.Request, limit int) (*http.Response, error) {
+	e := &TraceEntry{Time: time.Now().UTC(), Method: req.Method, URL: req.URL.Redacted(), RequestHeaders: redactHeaders(req.Header)}
+	var reqBody *bodyRecorder
+	if req.Body != nil && req.Body != http.NoBody {
+		reqBody = &bodyRecorder{ReadCloser: req.Body, limit: limit}
+		req = req.Clone(req.Context())
+		req.Body = reqBody
+	}
+	start := time.Now()
+	resp, err := rt.RoundTrip(req)
+	e.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	if reqBody != nil {
+		var body string
+		body, e.Truncated = reqBody.recorded()
+		e.RequestBody = redactBody(body)
+	}
+	if err != nil {
+		e.Error = err.Error()
+		writeTrace(e)
+		return resp, err
+	}
+	e.Status = resp.StatusCode
+	e.ResponseHeaders = redactHeaders(resp.Header)
+	body := &bodyRecorder{ReadCloser: resp.Body, limit: limit}
+	body.done = func() {
+		s, truncated := body.recorded()
+		e.ResponseBody = redactBody(s)
+		e.Truncated = e.Truncated || truncated
+		writeTrace(e)
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// bodyRecorder records up to limit bytes of what is read through it and calls
+// done once when closed. A request body may still be read by the transport
+// after the response arrives, hence the lock.
+type bodyRecorder struct {
+	io.ReadCloser
+	limit     int
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	truncated bool
+	done      func()
+	once      sync.Once
+}
+
+func (c *bodyRecorder) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if room := c.limit - c.buf.Len(); room > 0 {
+		c.buf.Write(p[:min(n, room)])
+		c.truncated = c.truncated || n > room
+	} else if n > 0 {
+		c.truncated = true
+	}
+	return n, err
+}
+
+// recorded returns the bytes read so far and whether more were read.
+func (c *bodyRecorder) recorded() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String(), c.truncated
+}
+
+func (c *bodyRecorder) Close() error {
+	err := c.ReadCloser.Close()
+	if c.done != nil {
+		c.once.Do(c.done)
+	}
+	return err
+}
+
+// redactHeaders returns a copy of h without credentials.
+func redactHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		if _, ok := out[name]; ok {
+			out[name] = []string{redacted}
+		}
+	}
+	return out
+}
+
+// redactBody replaces the values of password members and the private keys
+// in a JSON body.
+func redactBody(body string) string {
+	if strings.Contains(body, "PRIVATE KEY-----") {
+		body = privateKeys.ReplaceAllString(body, redacted)
+	}
+	if !strings.Contains(strings.ToLower(body), "password") {
+		return body
+	}
+	return secretFields.ReplaceAllString(body, `$1"`+redacted+`"`)
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package discover
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
+)
+
+// aggregation is what the chassis controllers served, with
+// Options.Aggregate, for the BMCs of their chassis.
+type aggregation struct {
+	// systems holds, by BMC xname, the systems of each BMC whose nodes
+	// were all read completely, in node order; details holds their details
+	// with Options.CollectDetails
+	systems map[string][]redfish.SystemMACs
+	details map[string][]*redfish.SystemDetails
+	// used marks the bmcs[] indexes of the controllers that were queried,
+	// and pinned those whose tls_fingerprint was recorded
+	used   map[int]bool
+	pinned map[int]bool
+}
+
+// aggregateAll queries every chassis controller in bmcs not in opts.Skip
+// for the systems it serves on behalf of its node controllers.
+func aggregateAll(ctx context.Context, bmcs []inventory.Entry, opts Options) aggregation {
+	agg := aggregation{
+		systems: map[string][]redfish.SystemMACs{},
+		details: map[string][]*redfish.SystemDetails{},
+		used:    map[int]bool{},
+		pinned:  map[int]bool{},
+	}
+	listed := map[string]bool{}
+	for _, b := range bmcs {
+		if x, err := xname.Parse(b.Xname); err == nil && !opts.Skip[b.Xname] {
+			listed[x.String()] = true
+		}
+	}
+	for i := range bmcs {
+		b := &bmcs[i]
+		cc, chassis, ok := xname.ParseChassisBMC(b.Xname)
+		if !ok || opts.Skip[b.Xname] || ctx.Err() != nil {
+			continue
+		}
+		b.Xname = cc
+		if opts.Pacer.Start(ctx) != nil {
+			return agg
+		}
+		hl := diag.NewHostLog(cc)
+		agg.used[i] = true
+		agg.pinned[i] = aggregate(ctx, b, chassis, listed, opts, agg, hl)
+		opts.Pacer.Done()
+		hl.Flush()
+	}
+	return agg
+}
+
+// aggregate reads the systems chassis controller b serves and adds those
+// of every listed BMC whose systems all map to a node xname and have a
+// bootable NIC to agg. The BMCs left out are queried directly. It reports
+// whether b's tls_fingerprint was recorded.
+func aggregate(ctx context.Context, b *inventory.Entry, chassis string, listed map[string]bool, opts Options, agg aggregation, hl *diag.HostLog) bool {
+	bctx, cancel := context.WithTimeout(diag.WithHostLog(ctx, hl), hostTimeout(opts))
+	defer cancel()
+	host, err := resolveBMC(bctx, b, opts)
+	var systems []redfish.AggregatedSystem
+	if err == nil {
+		redfish.PinIdentity(host, b.Xname, b.TLSFingerprint)
+		systems, err = redfish.DiscoverAggregatedSystems(bctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout, opts.NICRules)
+	}
+	if err != nil {
+		hl.Warnf("aggregate: %v; querying the BMCs of %s directly", err, chassis)
+		return false
+	}
+
+	type node struct {
+		n   int
+		sys redfish.AggregatedSystem
+	}
+	byBMC := map[string][]node{}
+	incomplete := map[string]bool{}
+	for _, s := range systems {
+		label := s.Path
+		x, ok := "", false
+		for _, name := range []string{s.ID, s.Name, s.Path[strings.LastIndex(s.Path, "/")+1:]} {
+			if x, ok = xname.NodeInChassis(chassis, name); ok {
+				break
+			}
+		}
+		if !ok {
+			if s.Err != nil {
+				hl.Warnf("%s: %v", label, s.Err)
+			} else {
+				hl.Warnf("%s: no node xname in Id %q or Name %q; skipped", label, s.ID, s.Name)
+			}
+			continue
+		}
+		nx, _ := xname.Parse(x)
+		parent, _ := nx.Ancestor(xname.KindBMC)
+		bmc := parent.String()
+		if !listed[bmc] {
+			hl.Warnf("%s (%s): BMC %s is not in bmcs[]; skipped", label, x, bmc)
+			continue
+		}
+		byBMC[bmc] = append(byBMC[bmc], node{nx.Node, s})
+		switch {
+		case s.Err != nil:
+			hl.Warnf("%s (%s): %v; querying %s directly", label, x, s.Err, bmc)
+			incomplete[bmc] = true
+		case len(s.MACs) == 0:
+			hl.Warnf("%s (%s): no bootable NIC; querying %s directly", label, x, bmc)
+			incomplete[bmc] = true
+		}
+	}
+
+	served := 0
+	for bmc, nodes := range byBMC {
+		slices.SortFunc(nodes, func(a, b node) int { return a.n - b.n })
+		// Node xnames follow the position of a system on its BMC, so the
+		// nodes must be n0, n1, ... without a gap
+		for i, nd := range nodes {
+			if nd.n != i && !incomplete[bmc] {
+				hl.Warnf("%s: no system for node n%d; querying it directly", bmc, i)
+				incomplete[bmc] = true
+			}
+		}
+		if incomplete[bmc] {
+			continue
+		}
+		list := make([]redfish.SystemMACs, len(nodes))
+		for i, nd := range nodes {
+			list[i] = redfish.SystemMACs{SystemPath: nd.sys.Path, MACs: nd.sys.MACs, Status: nd.sys.Status, Details: &nd.sys.Details}
+		}
+		agg.systems[bmc] = list
+		if opts.CollectDetails {
+			agg.details[bmc] = systemDetails(list)
+		}
+		served += len(list)
+	}
+	hl.Infof("served %d system(s) of %d BMC(s) in %s", served, len(byBMC)-len(incomplete), chassis)
+	return pinIdentity(b, host, opts)
+}
+
+// result returns the result of BMC b from agg, normalizing b's xname, when
+// its chassis controller served all of its systems.
+func (agg aggregation) result(b *inventory.Entry) (bmcResult, bool) {
+	x, err := xname.Parse(b.Xname)
+	if err != nil || x.Kind != xname.KindBMC {
+		return bmcResult{}, false
+	}
+	systems, ok := agg.systems[x.String()]
+	if !ok {
+		return bmcResult{}, false
+	}
+	b.Xname = x.String()
+	return bmcResult{systems: systems, details: agg.details[b.Xname], aggregated: true}, true
+}
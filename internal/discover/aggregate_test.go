@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package discover
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"bootstrap/internal/inventory"
+)
+
+// mockAggregator serves the systems of x9000c1s0b0 and x9000c1s1b0 through
+// one chassis controller, with members listed by absolute URL as a proxy
+// does. s1b0n0 has no bootable NIC.
+func mockAggregator(t *testing.T) string {
+	t.Helper()
+	const proxied = "https://nc.invalid/redfish/v1/Systems/"
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		rest, ok := strings.CutPrefix(r.URL.Path, "/redfish/v1/Systems/")
+		parts := strings.Split(rest, "/")
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			fmt.Fprintf(w, `{"Members":[{"@odata.id":"%[1]ss0b0n1"},{"@odata.id":"%[1]ss0b0n0"},{"@odata.id":"%[1]ss1b0n0"}]}`, proxied)
+		case !ok:
+			http.NotFound(w, r)
+		case len(parts) == 1:
+			fmt.Fprintf(w, `{"Id":"%[1]s","Name":"Node","SerialNumber":"SN-%[1]s"}`, parts[0])
+		case len(parts) == 2 && parts[0] == "s1b0n0":
+			fmt.Fprint(w, `{"Members":[]}`)
+		case len(parts) == 2:
+			fmt.Fprintf(w, `{"Members":[{"@odata.id":"%s%s/EthernetInterfaces/eth0"}]}`, proxied, parts[0])
+		case len(parts) == 3:
+			n := parts[0][len(parts[0])-1:]
+			fmt.Fprintf(w, `{"Id":"eth0","MACAddress":"02:00:00:00:00:0%s","UefiDevicePath":"MAC(02000000000%s)/IPv4(0.0.0.0)"}`, n, n)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestUpdateNodesAggregate(t *testing.T) {
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{
+			// Never contacted: its controller serves both of its nodes
+			{Xname: "x9000c1s0b0", IP: "127.0.0.1:1"},
+			// Queried directly: its node has no bootable NIC through the proxy
+			{Xname: "x9000c1s1b0", IP: mockBMC(t)},
+			{Xname: "x9000c1b0", IP: mockAggregator(t)},
+		},
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, Aggregate: true, CollectDetails: true}
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Failed) != 0 || len(res.Categories) != 0 || !reflect.DeepEqual(res.Aggregated, []string{"x9000c1s0b0"}) {
+		t.Fatalf("Failed = %v, Categories = %v, Aggregated = %v", res.Failed, res.Categories, res.Aggregated)
+	}
+	got := map[string]string{}
+	for _, n := range res.Nodes {
+		got[n.Xname] = n.MAC
+		// Details of served systems come from the controller's reads
+		if strings.HasPrefix(n.Xname, "x9000c1s0b0") && n.Serial != "SN-"+strings.TrimPrefix(n.Xname, "x9000c1") {
+			t.Errorf("%s serial = %q", n.Xname, n.Serial)
+		}
+	}
+	want := map[string]string{
+		"x9000c1s0b0n0": "02:00:00:00:00:00",
+		"x9000c1s0b0n1": "02:00:00:00:00:01",
+		"x9000c1s1b0n0": "aa:bb:cc:dd:ee:00",
+		"x9000c1s1b0n1": "aa:bb:cc:dd:ee:01",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes = %v, want %v", got, want)
+	}
+}
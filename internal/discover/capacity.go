@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package discover
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/netalloc"
+	"bootstrap/internal/redfish"
+	"bootstrap/internal/xname"
+)
+
+// CapacityError reports a node subnet with fewer free addresses than the
+// nodes discovery expects to add.
+type CapacityError struct {
+	Subnet   string
+	Expected int // nodes expected behind the BMCs to query
+	New      int // of those, nodes without an address in the subnet yet
+	Free     int // free addresses in the subnet
+}
+
+// Short returns the number of addresses missing.
+func (e *CapacityError) Short() int { return e.New - e.Free }
+
+func (e *CapacityError) Error() string {
+	return fmt.Sprintf("node subnet %s is %d address(es) short: %d node(s) expected, %d of them new, %d address(es) free",
+		e.Subnet, e.Short(), e.Expected, e.New, e.Free)
+}
+
+// checkCapacity compares the free addresses of alloc with the nodes the
+// BMCs of doc to query are expected to have: opts.NodesPerBMC each, or with
+// opts.CountFirst the members of each BMC's Systems collection. Nodes that
+// already have an address in the subnet need no new one. A shortfall is a
+// *CapacityError, or only a warning with opts.IgnoreCapacity. It runs after
+// every existing address is reserved, so Free is what is left to allocate.
+func checkCapacity(ctx context.Context, doc *inventory.FileFormat, opts Options, alloc *netalloc.Allocator) error {
+	if opts.NodesPerBMC <= 0 && !opts.CountFirst {
+		return nil
+	}
+	have := map[string]int{}
+	for _, n := range doc.Nodes {
+		if net.ParseIP(n.IP) == nil || !alloc.Contains(n.IP) {
+			continue
+		}
+		if x, err := xname.Parse(n.Xname); err == nil && x.Kind == xname.KindNode {
+			bmc, _ := x.Ancestor(xname.KindBMC)
+			have[bmc.String()]++
+		}
+	}
+	st := alloc.Stats()
+	c := CapacityError{Subnet: st.Subnet, Free: st.Free}
+	for _, b := range doc.BMCs {
+		if opts.Skip[b.Xname] || ctx.Err() != nil {
+			continue
+		}
+		x, err := xname.Parse(b.Xname)
+		if err != nil || x.Kind != xname.KindBMC {
+			// Chassis controllers and bad xnames get no node addresses
+			continue
+		}
+		n := opts.NodesPerBMC
+		if opts.CountFirst {
+			n = countSystems(ctx, b, opts)
+		}
+		c.Expected += n
+		c.New += max(0, n-have[x.String()])
+	}
+	diag.Infof("Capacity: %d node(s) expected, %d of them new; %d address(es) free in %s", c.Expected, c.New, c.Free, c.Subnet)
+	if c.Short() <= 0 {
+		return nil
+	}
+	if opts.IgnoreCapacity {
+		diag.Warnf("%v; continuing anyway (--force)", &c)
+		return nil
+	}
+	return &c
+}
+
+// countSystems returns the number of systems behind b, or opts.NodesPerBMC
+// when they cannot be counted. b is a copy, so an address resolved for it is
+// not recorded.
+func countSystems(ctx context.Context, b inventory.Entry, opts Options) int {
+	bctx, cancel := context.WithTimeout(ctx, hostTimeout(opts))
+	defer cancel()
+	host, err := resolveBMC(bctx, &b, opts)
+	n := 0
+	if err == nil {
+		n, err = redfish.CountSystems(bctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout)
+	}
+	if err != nil {
+		diag.Warnf("%s: count systems: %v; assuming %d", b.Xname, err, opts.NodesPerBMC)
+		return opts.NodesPerBMC
+	}
+	return n
+}
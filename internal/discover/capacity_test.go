@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package discover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bootstrap/internal/inventory"
+)
+
+func TestUpdateNodesChecksCapacity(t *testing.T) {
+	host := mockBMC(t)
+	newDoc := func() inventory.FileFormat {
+		return inventory.FileFormat{
+			BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}, {Xname: "x9000c1s1b0", IP: host}, {Xname: "x9000c1b0", IP: host}},
+			// Already addressed, so it needs no new address
+			Nodes: []inventory.Entry{{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.1"}},
+		}
+	}
+	// /29: 6 usable addresses, 5 of them free
+	opts := Options{BMCSubnet: "10.0.0.0/29", NodeSubnet: "10.0.0.0/29", Insecure: true, Timeout: 5 * time.Second, NodesPerBMC: 4}
+
+	doc := newDoc()
+	_, err := UpdateNodes(context.Background(), &doc, opts)
+	var ce *CapacityError
+	if !errors.As(err, &ce) {
+		t.Fatalf("err = %v, want a CapacityError", err)
+	}
+	if ce.Expected != 8 || ce.New != 7 || ce.Free != 5 || ce.Short() != 2 {
+		t.Errorf("capacity = %+v, short %d; want 8 expected, 7 new, 5 free, 2 short", *ce, ce.Short())
+	}
+
+	// Each BMC has two systems, which fit
+	opts.CountFirst = true
+	doc = newDoc()
+	if _, err := UpdateNodes(context.Background(), &doc, opts); err != nil {
+		t.Errorf("--count-first: %v", err)
+	}
+
+	opts.CountFirst, opts.IgnoreCapacity = false, true
+	doc = newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil || res.Queried == 0 {
+		t.Errorf("--force: err = %v, queried %d", err, res.Queried)
+	}
+}
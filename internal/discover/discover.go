@@ -7,81 +7,716 @@ package discover
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
-	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"bootstrap/internal/diag"
 	"bootstrap/internal/inventory"
 	"bootstrap/internal/netalloc"
+	"bootstrap/internal/ratelimit"
 	"bootstrap/internal/redfish"
 	"bootstrap/internal/xname"
 )
 
+// IP allocation strategies for Options.IPStrategy.
+const (
+	// IPStrategySequential gives new nodes the next free address.
+	IPStrategySequential = "sequential"
+	// IPStrategyNIDOffset gives each node the subnet base + its nid +
+	// Options.IPOffset.
+	IPStrategyNIDOffset = "nid-offset"
+)
+
+// Options controls a discovery run.
+type Options struct {
+	BMCSubnet   string
+	NodeSubnet  string
+	NodeStartIP string // optional: skip all node IPs before this address
+	User        string
+	Pass        string
+	Insecure    bool
+	Timeout     time.Duration
+	// HostTimeout bounds all the queries made to one BMC; zero uses
+	// Timeout.
+	HostTimeout time.Duration
+	// Reserve lists extra IPs and ranges (e.g. "10.42.0.50-10.42.0.99") that
+	// must never be allocated, in addition to the file's reserved[].
+	Reserve []string
+	// ReleaseStale returns the IPs of nodes that were not rediscovered to the
+	// pool before new addresses are allocated.
+	ReleaseStale bool
+	// DefaultRole is the role given to nodes that do not already have one.
+	DefaultRole string
+	// CollectDetails also records node serial, model, and SKU and each
+	// BMC's firmware version.
+	CollectDetails bool
+	// IPStrategy is IPStrategySequential (the default when empty) or
+	// IPStrategyNIDOffset, which uses IPOffset.
+	IPStrategy string
+	IPOffset   int
+	// NICRules adjust which NICs are bootable (see redfish.ParseNICRules).
+	NICRules redfish.NICRules
+	// Skip holds the bmcs[] xnames (as written in the file) of BMCs not to
+	// query at all, e.g. from --skip-file. Their previous nodes are kept.
+	Skip map[string]bool
+	// NodesPerBMC is the number of nodes expected behind each BMC by the
+	// capacity check made before any BMC is queried; zero skips the check
+	// unless CountFirst is set. CountFirst counts the members of each BMC's
+	// Systems collection instead, falling back to NodesPerBMC for a BMC
+	// that cannot be counted. IgnoreCapacity turns a shortfall from a
+	// *CapacityError into a warning.
+	NodesPerBMC    int
+	CountFirst     bool
+	IgnoreCapacity bool
+	// PruneOrphans drops the previous nodes whose BMC is not in bmcs[]
+	// instead of keeping them (see Result.Orphans). Static entries are
+	// always kept.
+	PruneOrphans bool
+	// AllowDuplicateMACs keeps a node whose MAC was already reported for
+	// another node; by default the later one is left out.
+	AllowDuplicateMACs bool
+	// ReuseByMAC gives a node found under a new xname the record, and so
+	// the IP, of the previous node with its MAC, e.g. after a blade moved to
+	// another slot. The entry under the old xname is dropped.
+	ReuseByMAC bool
+	// IncludeChassisControllers queries the bmcs[] entries with a chassis
+	// controller xname (e.g. x9000c1b0) for their manager NIC, recorded in
+	// Result.Controllers. Without it those entries are skipped.
+	IncludeChassisControllers bool
+	// Aggregate first reads, through each chassis controller in bmcs[],
+	// the systems it serves on behalf of the node controllers of its
+	// chassis. A BMC whose systems were all served with a bootable NIC is
+	// not queried itself; the others are, as without Aggregate.
+	Aggregate bool
+	// Resolver looks up the bmcs[] entries without an IP by their host, or
+	// by their xname when they have none. Nil uses the system resolver.
+	Resolver *net.Resolver
+	// RecordBMCIPs stores the address a BMC's name resolved to as its ip.
+	RecordBMCIPs bool
+	// IncludeAbsent records the systems a BMC reports with Status.State
+	// Absent, such as the empty slots of a half-populated blade; by default
+	// they are left out (see Result.Absent).
+	IncludeAbsent bool
+	// PinIdentity stores the fingerprint of the TLS certificate each BMC
+	// presented as its tls_fingerprint when it has none yet, or when a
+	// changed one was accepted (redfish.ConfigureIdentity).
+	PinIdentity bool
+	// Progress, when set, is called after each BMC query completes.
+	Progress func(Progress)
+	// Pacer, when set, spaces out the BMC queries.
+	Pacer *ratelimit.Pacer
+}
+
+// Progress counts the BMC queries completed so far in a discovery run.
+type Progress struct {
+	Done   int // BMCs queried, including failures
+	Total  int // BMCs in the inventory, less those in Options.Skip
+	NICs   int // bootable NICs found so far
+	Failed int
+}
+
+// BMCTiming is how long the query of one BMC took.
+type BMCTiming struct {
+	Xname    string
+	Duration time.Duration
+}
+
+// DuplicateMAC is a MAC reported for two discovered nodes, usually by a
+// misconfigured BMC.
+type DuplicateMAC struct {
+	MAC    string
+	First  string // xname of the node that kept the MAC
+	Second string // xname of the later node reporting it
+	Kept   bool   // whether Second was kept (Options.AllowDuplicateMACs)
+}
+
+// Rename is a node whose MAC was previously recorded under another xname
+// (see Options.ReuseByMAC).
+type Rename struct {
+	MAC  string
+	From string
+	To   string
+}
+
+// MACChange is a rediscovered node whose MAC differs from the one recorded
+// under its xname, usually after a blade or NIC was swapped.
+type MACChange struct {
+	Xname string
+	Old   string
+	New   string
+}
+
+// Result is the outcome of UpdateNodes.
+type Result struct {
+	Nodes []inventory.Entry
+	// Released lists previous nodes whose IPs were returned to the pool.
+	Released []inventory.Entry
+	// Interrupted is set when ctx was cancelled before every BMC was
+	// queried. Nodes then holds what was discovered plus the previous
+	// entries of the BMCs that were not visited.
+	Interrupted bool
+	// CarriedOver counts previous entries kept for BMCs that were not
+	// discovered: listed in Options.Skip, failed, or left when ctx ended.
+	CarriedOver int
+	// Orphans lists previous entries whose BMC is not in bmcs[]. They are
+	// kept in Nodes unless Options.PruneOrphans is set.
+	Orphans []inventory.Entry
+	// Skipped lists the xnames of the BMCs not visited before ctx ended.
+	Skipped []string
+	// Listed lists the xnames of the BMCs skipped through Options.Skip.
+	Listed []string
+	// Queried counts the BMCs whose query ran to completion, and Failed
+	// lists the xnames of those among them that could not be discovered.
+	Queried int
+	Failed  []string
+	// Timings holds the duration of every completed BMC query, in
+	// completion order.
+	Timings []BMCTiming
+	// Duplicates lists the MACs discovered on more than one node.
+	Duplicates []DuplicateMAC
+	// Renamed lists the nodes that took over a previous entry by MAC.
+	Renamed []Rename
+	// MACChanges lists the nodes rediscovered under their xname with a
+	// different MAC, in BMC order.
+	MACChanges []MACChange
+	// Allocated counts the node IPs newly handed out, as opposed to those
+	// kept from the previous entries.
+	Allocated int
+	// Categories maps the xname of every BMC in Failed, and of every BMC
+	// that answered without a bootable NIC, to its redfish.Categorize
+	// category. BMCs with an invalid xname have none.
+	Categories map[string]string
+	// Controllers holds, with Options.IncludeChassisControllers, the chassis
+	// controllers discovered in BMC order, followed by the previous
+	// controllers[] entries that were not.
+	Controllers []inventory.Entry
+	// Subnets holds the address usage of the node subnet and, when it is a
+	// different one, the BMC subnet once every IP is allocated.
+	Subnets []netalloc.Stats
+	// Pinned lists, with Options.PinIdentity, the BMCs whose tls_fingerprint
+	// was recorded or replaced, in completion order.
+	Pinned []string
+	// Aggregated lists, with Options.Aggregate, the BMCs whose systems were
+	// read through their chassis controller, in BMC order.
+	Aggregated []string
+	// Absent lists the node xnames of the systems left out because their
+	// State was Absent, in BMC order. Empty with Options.IncludeAbsent.
+	Absent []string
+}
+
+// discovered is a node found on a BMC, before IP allocation.
+type discovered struct {
+	xname   string
+	mac     string
+	nid     int // 0 when the BMC has no nid
+	details *redfish.SystemDetails
+}
+
 // UpdateNodes reads existing nodes for reservations, discovers bootable NICs per BMC,
-// allocates IPs, and returns the new nodes list.
-// nodeStartIP is an optional IP address to start node allocation from (skips all IPs before it)
-func UpdateNodes(doc *inventory.FileFormat, bmcSubnet, nodeSubnet, nodeStartIP string, user, pass string, insecure bool, timeout time.Duration) ([]inventory.Entry, error) {
+// allocates IPs, and returns the new nodes list. Cancelling ctx stops new BMC
+// queries; the partial result is still returned with Interrupted set.
+func UpdateNodes(ctx context.Context, doc *inventory.FileFormat, opts Options) (Result, error) {
+	var res Result
+	bmcSubnet, nodeSubnet := opts.BMCSubnet, opts.NodeSubnet
+	switch opts.IPStrategy {
+	case "", IPStrategySequential, IPStrategyNIDOffset:
+	default:
+		return res, fmt.Errorf("unknown IP strategy %q (want %s or %s)", opts.IPStrategy, IPStrategySequential, IPStrategyNIDOffset)
+	}
+
 	// Create allocator for node IPs
 	nodeAlloc, err := netalloc.NewAllocator(nodeSubnet)
 	if err != nil {
-		return nil, fmt.Errorf("node ipam init: %w", err)
+		return res, fmt.Errorf("node ipam init: %w", err)
+	}
+
+	// Create BMC allocator if subnet is different, otherwise reuse node allocator
+	var bmcAlloc *netalloc.Allocator
+	if bmcSubnet == nodeSubnet {
+		bmcAlloc = nodeAlloc
+	} else {
+		bmcAlloc, err = netalloc.NewAllocator(bmcSubnet)
+		if err != nil {
+			return res, fmt.Errorf("bmc ipam init: %w", err)
+		}
 	}
 
-	// Reserve existing node IPs that are within the node subnet
+	// Explicit reservations (--reserve and reserved[]) come before anything else
+	reserved, err := netalloc.ParseReservations(append(append([]string{}, doc.Reserved...), opts.Reserve...))
+	if err != nil {
+		return res, err
+	}
+	for _, ip := range reserved {
+		switch {
+		case nodeAlloc.Contains(ip):
+			err = nodeAlloc.Reserve(ip)
+		case bmcAlloc.Contains(ip):
+			err = bmcAlloc.Reserve(ip)
+		default:
+			err = fmt.Errorf("%s is outside the node subnet %s and BMC subnet %s", ip, nodeSubnet, bmcSubnet)
+		}
+		if err != nil {
+			return res, fmt.Errorf("reserve: %w", err)
+		}
+	}
+
+	// Reserve static node IPs first, then the rest of the existing node IPs
+	// that are within the node subnet
 	for _, n := range doc.Nodes {
-		if ip := net.ParseIP(n.IP); ip != nil && nodeAlloc.Contains(n.IP) {
-			nodeAlloc.Reserve(ip.String())
+		if !n.Static {
+			continue
+		}
+		if net.ParseIP(n.IP) == nil {
+			diag.Warnf("%s: static entry has no valid IP", n.Xname)
+			continue
+		}
+		if !nodeAlloc.Contains(n.IP) {
+			diag.Warnf("%s: static IP %s is outside node subnet %s; keeping it", n.Xname, n.IP, nodeSubnet)
+			continue
+		}
+		if err := nodeAlloc.Reserve(n.IP); err != nil {
+			return res, fmt.Errorf("reserve static IP for %s: %w", n.Xname, err)
+		}
+	}
+	for _, n := range doc.Nodes {
+		if ip := net.ParseIP(n.IP); ip != nil && !n.Static && nodeAlloc.Contains(n.IP) {
+			if err := nodeAlloc.Reserve(ip.String()); err != nil {
+				return res, fmt.Errorf("reserve existing IP for %s: %w", n.Xname, err)
+			}
 		}
 	}
 
 	// Reserve all IPs before the start IP if specified
-	if nodeStartIP != "" {
-		if err := nodeAlloc.ReserveUpTo(nodeStartIP); err != nil {
-			return nil, fmt.Errorf("reserve up to node start IP: %w", err)
+	if opts.NodeStartIP != "" {
+		if err := nodeAlloc.ReserveUpTo(opts.NodeStartIP); err != nil {
+			return res, fmt.Errorf("reserve up to node start IP: %w", err)
 		}
 	}
 
-	// Create BMC allocator if subnet is different, otherwise reuse node allocator
-	var bmcAlloc *netalloc.Allocator
-	if bmcSubnet == nodeSubnet {
-		bmcAlloc = nodeAlloc
-	} else {
-		bmcAlloc, err = netalloc.NewAllocator(bmcSubnet)
-		if err != nil {
-			return nil, fmt.Errorf("bmc ipam init: %w", err)
+	// Reserve existing BMC IPs that are within the BMC subnet
+	if bmcAlloc != nodeAlloc {
+		for _, b := range doc.BMCs {
+			if ip := net.ParseIP(b.IP); ip != nil && bmcAlloc.Contains(b.IP) {
+				if err := bmcAlloc.Reserve(ip.String()); err != nil {
+					return res, fmt.Errorf("reserve BMC IP for %s: %w", b.Xname, err)
+				}
+			}
+		}
+	}
+
+	// Match existing nodes against the normalized xnames discovery produces
+	for i, n := range doc.Nodes {
+		if x, err := xname.Normalize(n.Xname); err == nil {
+			doc.Nodes[i].Xname = x
 		}
-		// Reserve existing BMC IPs that are within the BMC subnet
+	}
+
+	if err := checkCapacity(ctx, doc, opts, nodeAlloc); err != nil {
+		return res, err
+	}
+
+	sw := discoverAll(ctx, doc.BMCs, opts)
+	// Addresses recorded for BMCs by name are no longer free either
+	if opts.RecordBMCIPs && bmcAlloc != nodeAlloc {
 		for _, b := range doc.BMCs {
 			if ip := net.ParseIP(b.IP); ip != nil && bmcAlloc.Contains(b.IP) {
-				bmcAlloc.Reserve(ip.String())
+				if err := bmcAlloc.Reserve(ip.String()); err != nil {
+					return res, fmt.Errorf("reserve BMC IP for %s: %w", b.Xname, err)
+				}
 			}
 		}
 	}
+	visited := sw.visited
+	found, dups := checkDuplicateMACs(sw.found, opts.AllowDuplicateMACs)
+	res.Duplicates = dups
+	res.Interrupted = ctx.Err() != nil
+	res.Queried, res.Failed, res.Timings, res.Categories = len(visited), sw.failed, sw.timings, sw.categories
+	res.Pinned = sw.pinned
+	res.Aggregated = sw.aggregated
+	res.Absent = sw.absent
+	if opts.IncludeChassisControllers {
+		res.Controllers = mergeControllers(doc.Controllers, sw.controllers)
+	}
 
-	out := make([]inventory.Entry, 0, len(doc.BMCs))
+	renamed := map[string]*inventory.Entry{}
+	renamedFrom := map[string]bool{}
+	if opts.ReuseByMAC {
+		res.Renamed = findRenames(doc.Nodes, found)
+		for _, r := range res.Renamed {
+			renamed[r.To] = findByXname(doc.Nodes, r.From)
+			renamedFrom[r.From] = true
+			diag.Infof("xname changed: %s -> %s (MAC %s); reusing IP %s", r.From, r.To, r.MAC, renamed[r.To].IP)
+		}
+	}
+	// previous returns the entry a discovered node continues: the one with
+	// its xname or, with ReuseByMAC, the one it was renamed from
+	previous := func(x string) *inventory.Entry {
+		if n := findByXname(doc.Nodes, x); n != nil {
+			return n
+		}
+		return renamed[x]
+	}
 
+	// Listed BMCs, BMCs that failed, and on interruption the BMCs not yet
+	// visited keep their previous entries
 	for _, b := range doc.BMCs {
-		host := b.IP
-		if host == "" {
-			host = b.Xname
+		switch {
+		case visited[b.Xname]:
+		case opts.Skip[b.Xname]:
+			res.Listed = append(res.Listed, b.Xname)
+		case res.Interrupted:
+			res.Skipped = append(res.Skipped, b.Xname)
+		}
+	}
+	unanswered := slices.Concat(res.Failed, res.Listed, res.Skipped)
+	old := slices.DeleteFunc(slices.Clone(doc.Nodes), func(n inventory.Entry) bool { return renamedFrom[n.Xname] })
+
+	// Staleness is unknown for BMCs that were never queried or failed, and
+	// for orphans unless they are pruned
+	if opts.ReleaseStale && !res.Interrupted {
+		seen := make(map[string]bool, len(found))
+		for _, d := range found {
+			seen[d.xname] = true
 		}
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		systemMACs, err := redfish.DiscoverAllBootableMACs(ctx, host, user, pass, insecure, timeout)
-		cancel()
+		prev := inventory.MergeNodes(old, nil, unanswered, doc.BMCs)
+		for _, n := range prev.Carried {
+			seen[n.Xname] = true
+		}
+		if !opts.PruneOrphans {
+			for _, n := range prev.Orphans {
+				seen[n.Xname] = true
+			}
+		}
+		for _, n := range old {
+			if seen[n.Xname] || n.Static || net.ParseIP(n.IP) == nil || !nodeAlloc.Contains(n.IP) {
+				continue
+			}
+			if err := nodeAlloc.Release(n.IP); err != nil {
+				diag.Warnf("%s: release %s: %v", n.Xname, n.IP, err)
+				continue
+			}
+			res.Released = append(res.Released, n)
+		}
+	}
+
+	if opts.IPStrategy == IPStrategyNIDOffset {
+		// The addresses of rediscovered nodes are handed out again below, nid
+		// addresses first, so a node holding another node's nid address is
+		// the one that moves
+		isReserved := make(map[string]bool, len(reserved))
+		for _, ip := range reserved {
+			isReserved[ip] = true
+		}
+		for _, d := range found {
+			if n := previous(d.xname); n != nil && !n.Static && nodeAlloc.Contains(n.IP) && !isReserved[n.IP] {
+				if err := nodeAlloc.Release(n.IP); err != nil {
+					diag.Warnf("%s: release %s: %v", n.Xname, n.IP, err)
+				}
+			}
+		}
+	}
+
+	res.Nodes = make([]inventory.Entry, 0, len(found))
+	seen := make(map[string]bool, len(found))
+	var pending []int // indexes in res.Nodes allocated once every nid address is taken
+	for _, d := range found {
+		seen[d.xname] = true
+		existing := previous(d.xname)
+		if existing != nil {
+			// A renamed node's old entry is not carried over
+			seen[existing.Xname] = true
+		}
+		e := inventory.Entry{Xname: d.xname, MAC: d.mac, NID: d.nid, Role: opts.DefaultRole}
+		if existing != nil {
+			if e.NID == 0 {
+				e.NID = existing.NID
+			}
+			if existing.Role != "" {
+				e.Role = existing.Role
+			}
+			// Details from an earlier run survive runs that do not collect them
+			e.Serial, e.Model, e.SKU = existing.Serial, existing.Model, existing.SKU
+			// Keys this tool does not manage (location, notes, ...) are kept
+			e.Extra = existing.Extra
+		}
+		if d.details != nil {
+			e.Serial, e.Model, e.SKU = d.details.SerialNumber, d.details.Model, d.details.SKU
+		}
+		// A renamed node is reported through Renamed, not as a MAC change
+		if existing != nil && existing.Xname == d.xname && existing.MAC != "" &&
+			inventory.NormalizeMAC(existing.MAC) != inventory.NormalizeMAC(d.mac) {
+			change := MACChange{Xname: d.xname, Old: inventory.NormalizeMAC(existing.MAC), New: inventory.NormalizeMAC(d.mac)}
+			res.MACChanges = append(res.MACChanges, change)
+			note := ""
+			if existing.Static && net.ParseIP(existing.IP) != nil {
+				note = "; keeping static IP " + existing.IP
+			}
+			diag.ChangeWarnf(d.xname, "mac", change.Old, change.New, "%s: MAC changed %s -> %s%s", d.xname, change.Old, change.New, note)
+		}
+		if existing != nil && existing.Static && net.ParseIP(existing.IP) != nil {
+			// Static entries keep their IP even when the MAC changed
+			e.IP, e.Static = existing.IP, true
+			res.Nodes = append(res.Nodes, e)
+			continue
+		}
+		if opts.IPStrategy == IPStrategyNIDOffset {
+			if ip, ok := nidOffsetIP(nodeAlloc, e, opts.IPOffset); ok {
+				e.IP = ip
+				if existing == nil || existing.IP != ip {
+					res.Allocated++
+				}
+			} else {
+				pending = append(pending, len(res.Nodes))
+			}
+			res.Nodes = append(res.Nodes, e)
+			continue
+		}
+		// Only reuse existing IP if it's valid and within the node subnet
+		if existing != nil && net.ParseIP(existing.IP) != nil && nodeAlloc.Contains(existing.IP) {
+			e.IP = existing.IP
+		} else {
+			var err error
+			e.IP, err = nodeAlloc.Next()
+			if err != nil {
+				return res, fmt.Errorf("ip allocate for %s: %w", d.xname, err)
+			}
+			res.Allocated++
+		}
+		res.Nodes = append(res.Nodes, e)
+	}
+	// Nodes without a usable nid address keep their previous address if it
+	// is still free, or get the next one
+	for _, i := range pending {
+		e := &res.Nodes[i]
+		if existing := previous(e.Xname); existing != nil && nodeAlloc.Claim(existing.IP) == nil {
+			e.IP = existing.IP
+			continue
+		}
+		ip, err := nodeAlloc.Next()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "WARN: %s: discover: %v\n", b.Xname, err)
+			return res, fmt.Errorf("ip allocate for %s: %w", e.Xname, err)
+		}
+		e.IP = ip
+		res.Allocated++
+	}
+	merged := inventory.MergeNodes(old, res.Nodes, unanswered, doc.BMCs)
+	res.Nodes, res.CarriedOver, res.Orphans = merged.Nodes, len(merged.Carried), merged.Orphans
+	if opts.PruneOrphans {
+		orphan := make(map[string]bool, len(res.Orphans))
+		for _, n := range res.Orphans {
+			orphan[n.Xname] = !n.Static
+		}
+		res.Nodes = slices.DeleteFunc(res.Nodes, func(n inventory.Entry) bool { return orphan[n.Xname] })
+	}
+	for _, n := range res.Nodes {
+		seen[n.Xname] = true
+	}
+	// Static entries that were not rediscovered are carried over unchanged
+	for _, n := range doc.Nodes {
+		if n.Static && !seen[n.Xname] {
+			diag.Warnf("%s: static entry not rediscovered; keeping it", n.Xname)
+			res.Nodes = append(res.Nodes, n)
+		}
+	}
+	res.Subnets = []netalloc.Stats{nodeAlloc.Stats()}
+	if bmcAlloc != nodeAlloc {
+		res.Subnets = append(res.Subnets, bmcAlloc.Stats())
+	}
+	return res, nil
+}
+
+// checkDuplicateMACs warns about every node whose MAC was already found on
+// an earlier node, in BMC order, and leaves it out unless keep is set.
+func checkDuplicateMACs(found []discovered, keep bool) ([]discovered, []DuplicateMAC) {
+	owner := make(map[string]string, len(found))
+	out := found[:0:0]
+	var dups []DuplicateMAC
+	for _, d := range found {
+		// BMCs spell the same MAC differently (AA-BB-..., aabbccddeeff)
+		mac := inventory.NormalizeMAC(d.mac)
+		first, ok := owner[mac]
+		if !ok {
+			owner[mac] = d.xname
+			out = append(out, d)
 			continue
 		}
-		if len(systemMACs) == 0 {
-			fmt.Fprintf(os.Stderr, "WARN: %s: no systems discovered\n", b.Xname)
+		dups = append(dups, DuplicateMAC{MAC: mac, First: first, Second: d.xname, Kept: keep})
+		if keep {
+			diag.Warnf("DUPLICATE MAC %s reported for both %s and %s; keeping both (--allow-duplicate-macs)", mac, first, d.xname)
+			out = append(out, d)
 			continue
 		}
+		diag.Warnf("DUPLICATE MAC %s reported for both %s and %s; skipping %s (check its BMC, or pass --allow-duplicate-macs)", mac, first, d.xname, d.xname)
+	}
+	return out, dups
+}
+
+// nidOffsetIP claims the node subnet's base address + e.NID + offset for e.
+// When e has no nid or the address is outside the subnet or taken, it warns
+// and returns false, and e is allocated an address sequentially.
+func nidOffsetIP(alloc *netalloc.Allocator, e inventory.Entry, offset int) (string, bool) {
+	if e.NID <= 0 {
+		diag.Warnf("%s: no nid; allocating its IP sequentially", e.Xname)
+		return "", false
+	}
+	ip, err := alloc.Offset(e.NID + offset)
+	if err != nil {
+		diag.Warnf("%s: nid %d + offset %d: %v; allocating its IP sequentially", e.Xname, e.NID, offset, err)
+		return "", false
+	}
+	if err := alloc.Claim(ip); err != nil {
+		diag.Warnf("%s: nid address %v; allocating its IP sequentially", e.Xname, err)
+		return "", false
+	}
+	return ip, true
+}
+
+// sweep is what discoverAll learned from the BMCs.
+type sweep struct {
+	found   []discovered
+	visited map[string]bool // xnames of the BMCs whose query ran to completion
+	failed  []string        // xnames of those among them that failed
+	timings []BMCTiming
+	// categories holds the redfish.Categorize category of each failed BMC
+	// and of each BMC without bootable NICs
+	categories map[string]string
+	// controllers holds the chassis controllers discovered, in BMC order
+	controllers []inventory.Entry
+	// pinned holds the xnames of the BMCs whose identity was pinned
+	pinned []string
+	// aggregated holds the xnames of the BMCs served by their controller
+	aggregated []string
+	// absent holds the node xnames of the systems left out as Absent
+	absent []string
+}
+
+// bmcResult is the outcome of querying bmcs[index].
+type bmcResult struct {
+	index    int
+	badXname bool
+	systems  []redfish.SystemMACs
+	details  []*redfish.SystemDetails
+	err      error
+	duration time.Duration
+	// controller is set for a chassis controller that was queried, and
+	// controllerSkipped for one that was not (see
+	// Options.IncludeChassisControllers)
+	controller        *inventory.Entry
+	controllerSkipped bool
+	// pinned is set when the BMC's tls_fingerprint was recorded
+	pinned bool
+	// aggregated is set for a BMC whose systems its chassis controller
+	// served (Options.Aggregate), and aggregator for a controller queried
+	// for them and not for its manager NIC
+	aggregated bool
+	aggregator bool
+	// log holds what was logged about the BMC while it was queried, printed
+	// with the rest of its lines once its result is processed
+	log *diag.HostLog
+}
+
+// discoverAll queries every BMC not in opts.Skip and returns one record per
+// system with a bootable NIC, in BMC order. Unreachable BMCs are reported
+// and skipped. No new BMC is queried once ctx is cancelled. With
+// opts.CollectDetails the firmware version of each BMC is stored in bmcs.
+// Results are collected from a channel as queries complete, and
+// opts.Progress is driven from there rather than from the position in bmcs.
+func discoverAll(ctx context.Context, bmcs []inventory.Entry, opts Options) sweep {
+	// Counted before the queries start, as they normalize the xnames in bmcs
+	progress := Progress{Total: len(bmcs)}
+	for _, b := range bmcs {
+		if opts.Skip[b.Xname] {
+			progress.Total--
+		}
+	}
+	results := make(chan bmcResult)
+	go func() {
+		defer close(results)
+		var agg aggregation
+		if opts.Aggregate {
+			agg = aggregateAll(ctx, bmcs, opts)
+		}
+		for i := range bmcs {
+			if ctx.Err() != nil {
+				return
+			}
+			if opts.Skip[bmcs[i].Xname] {
+				continue
+			}
+			if r, ok := agg.result(&bmcs[i]); ok {
+				r.index = i
+				results <- r
+				continue
+			}
+			if agg.used[i] && !opts.IncludeChassisControllers {
+				results <- bmcResult{index: i, aggregator: true, pinned: agg.pinned[i]}
+				continue
+			}
+			if opts.Pacer.Start(ctx) != nil {
+				return
+			}
+			r := queryBMC(ctx, &bmcs[i], opts)
+			r.pinned = r.pinned || agg.pinned[i]
+			opts.Pacer.Done()
+			if r.err != nil && ctx.Err() != nil {
+				// Cancelled mid-query: the BMC counts as not visited
+				return
+			}
+			r.index = i
+			results <- r
+		}
+	}()
+
+	sw := sweep{visited: make(map[string]bool, len(bmcs)), categories: map[string]string{}}
+	perBMC := make([][]discovered, len(bmcs))
+	absent := make([][]string, len(bmcs))
+	controllers := make([]*inventory.Entry, len(bmcs))
+	for r := range results {
+		b := &bmcs[r.index]
+		hl := r.log
+		if hl == nil {
+			hl = diag.NewHostLog(b.Xname)
+		}
+		sw.visited[b.Xname] = true
+		progress.Done++
+		if r.pinned {
+			sw.pinned = append(sw.pinned, b.Xname)
+		}
+		if r.aggregated {
+			sw.aggregated = append(sw.aggregated, b.Xname)
+		}
+		switch {
+		case r.aggregator:
+			// aggregateAll logged what it served
+		case r.controllerSkipped:
+			hl.Infof("chassis controller; skipping (pass --include-chassis-controllers to discover it)")
+		case r.badXname:
+			// Node xnames are derived from the BMC's, so it must be a BMC xname
+			hl.Warnf("not a BMC xname (e.g. x9000c1s0b0); skipping")
+			sw.failed = append(sw.failed, b.Xname)
+			progress.Failed++
+		case r.err != nil:
+			sw.failed = append(sw.failed, b.Xname)
+			progress.Failed++
+			sw.categories[b.Xname] = redfish.Categorize(r.err)
+			hl.Failf(sw.categories[b.Xname], "discover: %v", r.err)
+		case r.controller != nil:
+			controllers[r.index] = r.controller
+		case len(r.systems) == 0:
+			hl.Warnf("no systems discovered")
+		}
+		if !r.badXname && !r.controllerSkipped && !r.aggregated && !r.aggregator {
+			sw.timings = append(sw.timings, BMCTiming{Xname: b.Xname, Duration: r.duration})
+		}
 
 		// Process each system (e.g., Node0, Node1) found on this BMC
-		for sysIdx, sysMacs := range systemMACs {
+		for sysIdx, sysMacs := range r.systems {
 			if len(sysMacs.MACs) == 0 {
-				fmt.Fprintf(os.Stderr, "WARN: %s %s: no NICs discovered\n", b.Xname, sysMacs.SystemPath)
+				hl.Warnf("%s: no NICs discovered", sysMacs.SystemPath)
 				continue
 			}
 
@@ -91,25 +726,292 @@ func UpdateNodes(doc *inventory.FileFormat, bmcSubnet, nodeSubnet, nodeStartIP s
 			// Generate node xname with proper node number
 			// For single-system BMCs, use node 0
 			// For multi-system BMCs, use the system index as node number
-			nodeX := xname.BMCXnameToNodeN(b.Xname, sysIdx)
-
-			existing := findByXname(doc.Nodes, nodeX)
-			ipStr := ""
-			// Only reuse existing IP if it's valid and within the node subnet
-			if existing != nil && net.ParseIP(existing.IP) != nil && nodeAlloc.Contains(existing.IP) {
-				ipStr = existing.IP
-				nodeAlloc.Reserve(ipStr)
-			} else {
-				var err error
-				ipStr, err = nodeAlloc.Next()
-				if err != nil {
-					return nil, fmt.Errorf("ip allocate for %s: %w", nodeX, err)
-				}
+			d := discovered{xname: xname.BMCXnameToNodeN(b.Xname, sysIdx), mac: mac}
+			if sysMacs.Status.Absent() && !opts.IncludeAbsent {
+				// A phantom entry would get a DHCP reservation
+				hl.Warnf("%s (%s): status %s; skipped (pass --include-absent to record it)", sysMacs.SystemPath, d.xname, sysMacs.Status)
+				absent[r.index] = append(absent[r.index], d.xname)
+				continue
+			}
+			if r.details != nil {
+				d.details = r.details[sysIdx]
+			}
+			// The BMC's nid is that of its first node; later systems follow on
+			if b.NID > 0 {
+				d.nid = b.NID + sysIdx
+			}
+			perBMC[r.index] = append(perBMC[r.index], d)
+			progress.NICs++
+		}
+		if !r.badXname && r.err == nil && r.controller == nil && !r.controllerSkipped && !r.aggregated && !r.aggregator && len(perBMC[r.index]) == 0 && len(absent[r.index]) == 0 {
+			sw.categories[b.Xname] = redfish.Categorize(redfish.ErrNoBootableNICs)
+		}
+		hl.Flush()
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+	for i, list := range perBMC {
+		sw.found = append(sw.found, list...)
+		sw.absent = append(sw.absent, absent[i]...)
+		if controllers[i] != nil {
+			sw.controllers = append(sw.controllers, *controllers[i])
+		}
+	}
+	return sw
+}
+
+// queryBMC discovers the bootable NICs (and, with opts.CollectDetails, the
+// details) of the systems on b, normalizing b's xname first. A chassis
+// controller is queried for its manager NIC instead.
+func queryBMC(ctx context.Context, b *inventory.Entry, opts Options) bmcResult {
+	if cc, _, ok := xname.ParseChassisBMC(b.Xname); ok {
+		b.Xname = cc
+		if !opts.IncludeChassisControllers {
+			return bmcResult{controllerSkipped: true}
+		}
+		return queryController(ctx, b, opts)
+	}
+	x, err := xname.Parse(b.Xname)
+	if err != nil || x.Kind != xname.KindBMC {
+		return bmcResult{badXname: true}
+	}
+	b.Xname = x.String()
+	start := time.Now()
+	r := bmcResult{log: diag.NewHostLog(b.Xname)}
+	bctx, cancel := context.WithTimeout(diag.WithHostLog(ctx, r.log), hostTimeout(opts))
+	defer cancel()
+	host, err := resolveBMC(bctx, b, opts)
+	r.err = err
+	if err == nil {
+		redfish.PinIdentity(host, b.Xname, b.TLSFingerprint)
+		r.systems, r.err = redfish.DiscoverAllBootableMACs(bctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout, opts.NICRules)
+	}
+	if r.err == nil && opts.CollectDetails {
+		r.details = collectDetails(bctx, b, host, r.systems, opts)
+	}
+	if r.err == nil {
+		r.pinned = pinIdentity(b, host, opts)
+	}
+	if ctx.Err() != nil && r.err == nil {
+		r.err = ctx.Err()
+	}
+	r.duration = time.Since(start)
+	return r
+}
+
+// queryController reads the manager NIC of chassis controller b: the MAC of
+// the manager interface and its IPv4 address, or b's own address when the
+// interface reports none.
+func queryController(ctx context.Context, b *inventory.Entry, opts Options) bmcResult {
+	start := time.Now()
+	bctx, cancel := context.WithTimeout(ctx, hostTimeout(opts))
+	defer cancel()
+	var r bmcResult
+	var info redfish.ManagerInfo
+	host, err := resolveBMC(bctx, b, opts)
+	if err == nil {
+		redfish.PinIdentity(host, b.Xname, b.TLSFingerprint)
+		info, err = redfish.GetManagerInfo(bctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout)
+	}
+	switch {
+	case err != nil:
+		r.err = err
+	case info.MACAddress == "":
+		r.err = errors.New("no manager NIC with a MAC address")
+	default:
+		c := inventory.Entry{Xname: b.Xname, MAC: info.MACAddress, IP: info.IPAddress}
+		if c.IP == "" && net.ParseIP(b.IP) != nil {
+			c.IP = b.IP
+		}
+		if opts.CollectDetails {
+			c.Serial, c.FirmwareVersion = info.SerialNumber, info.FirmwareVersion
+		}
+		r.controller = &c
+		r.pinned = pinIdentity(b, host, opts)
+	}
+	if ctx.Err() != nil && r.err == nil {
+		r.err = ctx.Err()
+	}
+	r.duration = time.Since(start)
+	return r
+}
+
+// pinIdentity stores, with opts.PinIdentity, the fingerprint of the
+// certificate host presented in b, and reports whether b's tls_fingerprint
+// changed. A fingerprint is only seen when a connection was made, not when
+// responses were replayed from a cache.
+func pinIdentity(b *inventory.Entry, host string, opts Options) bool {
+	if !opts.PinIdentity {
+		return false
+	}
+	seen := redfish.ObservedIdentity(host)
+	if seen == "" || redfish.SameFingerprint(seen, b.TLSFingerprint) {
+		return false
+	}
+	b.TLSFingerprint = seen
+	return true
+}
+
+// resolveBMC returns the address b is queried at: its IP or, when it has
+// none, the address its host (or else its xname) resolves to, preferring
+// IPv4. A port on the host is kept. With opts.RecordBMCIPs that address is
+// stored in b.
+func resolveBMC(ctx context.Context, b *inventory.Entry, opts Options) (string, error) {
+	if b.IP != "" {
+		return b.IP, nil
+	}
+	name := b.Address()
+	host, port, err := net.SplitHostPort(name)
+	if err != nil {
+		host, port = name, ""
+	}
+	if net.ParseIP(host) != nil {
+		return name, nil
+	}
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", host, err)
+	}
+	addr := addrs[0]
+	for _, a := range addrs {
+		if ip := net.ParseIP(a); ip != nil && ip.To4() != nil {
+			addr = a
+			break
+		}
+	}
+	if port != "" {
+		addr = net.JoinHostPort(addr, port)
+	}
+	diag.Logf("%s: %s resolved to %s", b.Xname, name, addr)
+	if opts.RecordBMCIPs {
+		b.IP = addr
+	}
+	return addr, nil
+}
+
+// NewResolver returns a resolver that sends every DNS query to server
+// (host or host:port, port 53 by default) instead of the system's name
+// servers.
+func NewResolver(server string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// hostTimeout bounds all the queries made to one BMC.
+func hostTimeout(opts Options) time.Duration {
+	if opts.HostTimeout <= 0 {
+		return opts.Timeout
+	}
+	return opts.HostTimeout
+}
+
+// mergeControllers returns the controllers discovered, keeping the keys
+// this tool does not manage and the details of their previous entries, and
+// then the previous entries that were not rediscovered.
+func mergeControllers(previous, found []inventory.Entry) []inventory.Entry {
+	out := make([]inventory.Entry, 0, len(found))
+	seen := make(map[string]bool, len(found))
+	for _, c := range found {
+		seen[c.Xname] = true
+		if p := findByXname(previous, c.Xname); p != nil {
+			c.Extra = p.Extra
+			if c.Serial == "" && c.FirmwareVersion == "" {
+				c.Serial, c.FirmwareVersion = p.Serial, p.FirmwareVersion
 			}
-			out = append(out, inventory.Entry{Xname: nodeX, MAC: mac, IP: ipStr})
 		}
+		out = append(out, c)
 	}
-	return out, nil
+	for _, p := range previous {
+		if !seen[p.Xname] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// collectDetails records b's firmware version and returns the details of
+// each system. Those were read with the systems; only a system that could
+// not be read then is read again, and is nil when that fails too. Failures
+// are logged to the diag.HostLog in ctx.
+func collectDetails(ctx context.Context, b *inventory.Entry, host string, systems []redfish.SystemMACs, opts Options) []*redfish.SystemDetails {
+	hl := diag.HostLogFrom(ctx)
+	if v, err := redfish.GetManagerFirmwareVersion(ctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout); err != nil {
+		hl.Warnf("firmware version: %v", err)
+	} else if v != "" {
+		b.FirmwareVersion = v
+	}
+	out := systemDetails(systems)
+	for i, s := range systems {
+		if out[i] != nil {
+			continue
+		}
+		d, err := redfish.GetSystemDetails(ctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout, s.SystemPath)
+		if err != nil {
+			hl.Warnf("%s: system details: %v", s.SystemPath, err)
+			continue
+		}
+		out[i] = &d
+	}
+	return out
+}
+
+// systemDetails returns the details read with each of systems.
+func systemDetails(systems []redfish.SystemMACs) []*redfish.SystemDetails {
+	out := make([]*redfish.SystemDetails, len(systems))
+	for i, s := range systems {
+		out[i] = s.Details
+	}
+	return out
+}
+
+// findRenames returns the discovered nodes with no previous entry of their
+// own whose MAC belongs to a previous entry that was not rediscovered under
+// its xname. Static entries stay with their xname.
+func findRenames(previous []inventory.Entry, found []discovered) []Rename {
+	foundX := make(map[string]bool, len(found))
+	for _, d := range found {
+		foundX[d.xname] = true
+	}
+	taken := map[string]bool{}
+	var out []Rename
+	for _, d := range found {
+		if findByXname(previous, d.xname) != nil {
+			continue
+		}
+		old := findByMAC(previous, d.mac)
+		if old == nil || old.Static || foundX[old.Xname] || taken[old.Xname] {
+			continue
+		}
+		taken[old.Xname] = true
+		out = append(out, Rename{MAC: d.mac, From: old.Xname, To: d.xname})
+	}
+	return out
+}
+
+// findByMAC returns the entry of list with MAC mac, ignoring case.
+func findByMAC(list []inventory.Entry, mac string) *inventory.Entry {
+	if mac == "" {
+		return nil
+	}
+	for i := range list {
+		if strings.EqualFold(list[i].MAC, mac) {
+			return &list[i]
+		}
+	}
+	return nil
 }
 
 func findByXname(list []inventory.Entry, x string) *inventory.Entry {
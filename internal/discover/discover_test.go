@@ -5,9 +5,20 @@
 package discover
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"bootstrap/internal/inventory"
+	"bootstrap/internal/redfish"
 )
 
 func TestFindByXname(t *testing.T) {
@@ -60,3 +71,788 @@ func TestFindByXname(t *testing.T) {
 		})
 	}
 }
+
+// mockBMC serves a BMC with two systems, each with one PXE-capable NIC.
+func mockBMC(t *testing.T) string {
+	t.Helper()
+	host, _ := countingMockBMC(t)
+	return host
+}
+
+// countingMockBMC is mockBMC, also returning the number of requests made so
+// far for a path.
+func countingMockBMC(t *testing.T) (string, func(path string) int) {
+	t.Helper()
+	var mu sync.Mutex
+	counts := map[string]int{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch p := r.URL.Path; {
+		case p == "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"},{"@odata.id":"/redfish/v1/Systems/Node1"}]}`)
+		case strings.HasSuffix(p, "/EthernetInterfaces"):
+			fmt.Fprintf(w, `{"Members":[{"@odata.id":"%s/eth0"}]}`, p)
+		case strings.HasSuffix(p, "/eth0"):
+			n := strings.TrimPrefix(strings.Split(p, "/")[4], "Node")
+			fmt.Fprintf(w, `{"Id":"eth0","MACAddress":"AA:BB:CC:DD:EE:0%s","UefiDevicePath":"PciRoot(0x0)/MAC(aabbccddee0%s)/IPv4(0.0.0.0)"}`, n, n)
+		case strings.HasPrefix(p, "/redfish/v1/Systems/Node"):
+			n := strings.TrimPrefix(p, "/redfish/v1/Systems/Node")
+			fmt.Fprintf(w, `{"Id":"Node%s","SerialNumber":"SN%s","Model":"EX425","SKU":"sku-%s"}`, n, n, n)
+		case p == "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case p == "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Id":"BMC","FirmwareVersion":"nc.1.9.8"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[path]
+	}
+}
+
+func TestUpdateNodesReleaseStale(t *testing.T) {
+	host := mockBMC(t)
+	newDoc := func() inventory.FileFormat {
+		return inventory.FileFormat{
+			BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}},
+			Nodes: []inventory.Entry{
+				{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.1"},
+				{Xname: "x9000c1s0b0n7", MAC: "aa:bb:cc:dd:ee:77", IP: "10.0.0.2"},
+			},
+		}
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second}
+
+	doc := newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Released) != 0 || res.Nodes[1].IP != "10.0.0.3" {
+		t.Fatalf("without --release-stale the stale IP must stay reserved, got %+v", res)
+	}
+	if res.Allocated != 1 {
+		t.Errorf("Allocated = %d, want 1 for the new node", res.Allocated)
+	}
+
+	opts.ReleaseStale = true
+	doc = newDoc()
+	res, err = UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Released) != 1 || res.Released[0].Xname != "x9000c1s0b0n7" {
+		t.Fatalf("Released = %+v, want x9000c1s0b0n7", res.Released)
+	}
+	want := []inventory.Entry{
+		{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.1"},
+		{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.2"},
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(res.Nodes[i], w) {
+			t.Errorf("Nodes[%d] = %+v, want %+v", i, res.Nodes[i], w)
+		}
+	}
+	if len(res.Subnets) != 1 || res.Subnets[0].String() != "subnet 10.0.0.0/24: 2/254 used" {
+		t.Errorf("Subnets = %v", res.Subnets)
+	}
+}
+
+func TestUpdateNodesReuseByMAC(t *testing.T) {
+	host := mockBMC(t)
+	newDoc := func() inventory.FileFormat {
+		// The blade moved from slot 0 to slot 3
+		return inventory.FileFormat{
+			BMCs: []inventory.Entry{{Xname: "x9000c1s3b0", IP: host}},
+			Nodes: []inventory.Entry{
+				{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.5", Role: "Compute"},
+				{Xname: "x9000c1s0b0n1", MAC: "AA:BB:CC:DD:EE:01", IP: "10.0.0.6", Static: true},
+			},
+		}
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, ReleaseStale: true}
+
+	doc := newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Renamed) != 0 || res.Nodes[0].IP == "10.0.0.5" {
+		t.Fatalf("without ReuseByMAC the IP follows the slot, got %+v", res.Nodes)
+	}
+
+	opts.ReuseByMAC = true
+	doc = newDoc()
+	res, err = UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Rename{{MAC: "aa:bb:cc:dd:ee:00", From: "x9000c1s0b0n0", To: "x9000c1s3b0n0"}}; !reflect.DeepEqual(res.Renamed, want) {
+		t.Errorf("Renamed = %+v, want %+v", res.Renamed, want)
+	}
+	if len(res.Released) != 0 {
+		t.Errorf("the renamed node's IP must not be released, got %+v", res.Released)
+	}
+	// The static entry stays with its xname
+	want := []inventory.Entry{
+		{Xname: "x9000c1s3b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.5", Role: "Compute"},
+		{Xname: "x9000c1s3b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.1"},
+		{Xname: "x9000c1s0b0n1", MAC: "AA:BB:CC:DD:EE:01", IP: "10.0.0.6", Static: true},
+	}
+	if !reflect.DeepEqual(res.Nodes, want) {
+		t.Errorf("Nodes = %+v, want %+v", res.Nodes, want)
+	}
+}
+
+func TestUpdateNodesReportsMACChanges(t *testing.T) {
+	host := mockBMC(t)
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}},
+		Nodes: []inventory.Entry{
+			// The same MAC in another form is not a change
+			{Xname: "x9000c1s0b0n0", MAC: "AA-BB-CC-DD-EE-00", IP: "10.0.0.1"},
+			{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:99", IP: "10.0.0.2", Role: "Compute"},
+		},
+	}
+	res, err := UpdateNodes(context.Background(), &doc, Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []MACChange{{Xname: "x9000c1s0b0n1", Old: "aa:bb:cc:dd:ee:99", New: "aa:bb:cc:dd:ee:01"}}; !reflect.DeepEqual(res.MACChanges, want) {
+		t.Errorf("MACChanges = %+v, want %+v", res.MACChanges, want)
+	}
+	// The swapped node keeps its xname's IP and role
+	if n := res.Nodes[1]; n.MAC != "aa:bb:cc:dd:ee:01" || n.IP != "10.0.0.2" || n.Role != "Compute" {
+		t.Errorf("Nodes[1] = %+v", n)
+	}
+}
+
+func TestUpdateNodesResolvesBMCNames(t *testing.T) {
+	_, port, _ := net.SplitHostPort(mockBMC(t))
+	// localhost comes from the hosts file; any other name needs the name
+	// server, which cannot be reached
+	resolver := &net.Resolver{PreferGo: true, Dial: func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("no name server")
+	}}
+	doc := inventory.FileFormat{BMCs: []inventory.Entry{
+		{Xname: "x9000c1s0b0", Host: "localhost:" + port},
+		{Xname: "x9000c1s1b0", Host: "bmc-missing.example.com"},
+	}}
+	res, err := UpdateNodes(context.Background(), &doc, Options{
+		BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second,
+		Resolver: resolver, RecordBMCIPs: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Nodes) != 2 {
+		t.Errorf("Nodes = %+v, want both systems of the BMC found by name", res.Nodes)
+	}
+	if got, want := doc.BMCs[0].IP, "127.0.0.1:"+port; got != want {
+		t.Errorf("recorded BMC IP = %q, want %q", got, want)
+	}
+	if doc.BMCs[1].IP != "" || res.Categories["x9000c1s1b0"] != redfish.CategoryDNS {
+		t.Errorf("unresolvable BMC: ip %q, category %q", doc.BMCs[1].IP, res.Categories["x9000c1s1b0"])
+	}
+}
+
+func TestUpdateNodesKeepsStaticIPs(t *testing.T) {
+	host := mockBMC(t)
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}},
+		Nodes: []inventory.Entry{
+			// MAC changed and IP outside the subnet: keep the IP anyway
+			{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:99", IP: "192.168.7.7", Static: true},
+			// not rediscovered: carried over, and never released
+			{Xname: "x9000c1s7b0n0", MAC: "aa:bb:cc:dd:ee:77", IP: "10.0.0.1", Static: true},
+		},
+	}
+	res, err := UpdateNodes(context.Background(), &doc, Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, ReleaseStale: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []inventory.Entry{
+		{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "192.168.7.7", Static: true},
+		{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.2"},
+		{Xname: "x9000c1s7b0n0", MAC: "aa:bb:cc:dd:ee:77", IP: "10.0.0.1", Static: true},
+	}
+	if len(res.Nodes) != len(want) {
+		t.Fatalf("Nodes = %+v, want %+v", res.Nodes, want)
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(res.Nodes[i], w) {
+			t.Errorf("Nodes[%d] = %+v, want %+v", i, res.Nodes[i], w)
+		}
+	}
+	if len(res.Released) != 0 {
+		t.Errorf("static entries must not be released, got %+v", res.Released)
+	}
+}
+
+func TestUpdateNodesHonorsReservations(t *testing.T) {
+	host := mockBMC(t)
+	doc := inventory.FileFormat{
+		BMCs:     []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}},
+		Reserved: []string{"10.0.0.1-10.0.0.2"},
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, Reserve: []string{"10.0.0.4"}}
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Nodes) != 2 || res.Nodes[0].IP != "10.0.0.3" || res.Nodes[1].IP != "10.0.0.5" {
+		t.Fatalf("reserved addresses were allocated: %+v", res.Nodes)
+	}
+
+	opts.Reserve = []string{"10.9.0.4"}
+	if _, err := UpdateNodes(context.Background(), &doc, opts); err == nil || !strings.Contains(err.Error(), "10.9.0.4") {
+		t.Fatalf("expected error for reservation outside the subnet, got %v", err)
+	}
+}
+
+func TestUpdateNodesInterruptedKeepsUnvisited(t *testing.T) {
+	host := mockBMC(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Cancel as soon as the first BMC has been queried
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{
+			{Xname: "x9000c1s0b0", IP: host},
+			{Xname: "x9000c1s1b0", IP: strings.TrimPrefix(srv.URL, "https://")},
+			{Xname: "x9000c1s2b0", IP: "127.0.0.1:1"},
+		},
+		Nodes: []inventory.Entry{
+			{Xname: "x9000c1s1b0n0", MAC: "aa:bb:cc:dd:ee:10", IP: "10.0.0.10"},
+			{Xname: "x9000c1s2b0n0", MAC: "aa:bb:cc:dd:ee:20", IP: "10.0.0.20"},
+		},
+	}
+	res, err := UpdateNodes(ctx, &doc, Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, ReleaseStale: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Interrupted || res.CarriedOver != 2 || len(res.Released) != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if strings.Join(res.Skipped, ",") != "x9000c1s1b0,x9000c1s2b0" {
+		t.Errorf("Skipped = %v", res.Skipped)
+	}
+	var got []string
+	for _, n := range res.Nodes {
+		got = append(got, n.Xname+"="+n.IP)
+	}
+	want := "x9000c1s0b0n0=10.0.0.1,x9000c1s0b0n1=10.0.0.2,x9000c1s1b0n0=10.0.0.10,x9000c1s2b0n0=10.0.0.20"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Nodes = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestUpdateNodesDerivesNIDAndRole(t *testing.T) {
+	host := mockBMC(t)
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host, NID: 5}},
+		Nodes: []inventory.Entry{
+			{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.9", Role: "management"},
+		},
+	}
+	res, err := UpdateNodes(context.Background(), &doc, Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, DefaultRole: "compute"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []inventory.Entry{
+		{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.1", NID: 5, Role: "compute"},
+		{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.9", NID: 6, Role: "management"},
+	}
+	if len(res.Nodes) != len(want) {
+		t.Fatalf("Nodes = %+v, want %+v", res.Nodes, want)
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(res.Nodes[i], w) {
+			t.Errorf("Nodes[%d] = %+v, want %+v", i, res.Nodes[i], w)
+		}
+	}
+}
+
+func TestUpdateNodesCollectDetails(t *testing.T) {
+	host, requests := countingMockBMC(t)
+	newDoc := func() inventory.FileFormat {
+		return inventory.FileFormat{
+			BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}},
+			Nodes: []inventory.Entry{
+				{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.9", Serial: "OLD", Model: "EX420"},
+			},
+		}
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second}
+
+	// Without --collect-details nothing new is added and earlier details are kept
+	doc := newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.BMCs[0].FirmwareVersion != "" || res.Nodes[0].Serial != "" || res.Nodes[1].Serial != "OLD" || res.Nodes[1].Model != "EX420" {
+		t.Fatalf("unexpected details without CollectDetails: bmc=%+v nodes=%+v", doc.BMCs[0], res.Nodes)
+	}
+
+	opts.CollectDetails = true
+	doc = newDoc()
+	before := requests("/redfish/v1/Systems/Node0")
+	res, err = UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The details come from the read that discovery makes anyway
+	if n := requests("/redfish/v1/Systems/Node0") - before; n != 1 {
+		t.Errorf("Node0 read %d times, want once", n)
+	}
+	if doc.BMCs[0].FirmwareVersion != "nc.1.9.8" {
+		t.Errorf("BMC firmware_version = %q", doc.BMCs[0].FirmwareVersion)
+	}
+	for i, n := range res.Nodes {
+		if n.Serial != fmt.Sprintf("SN%d", i) || n.Model != "EX425" || n.SKU != fmt.Sprintf("sku-%d", i) {
+			t.Errorf("Nodes[%d] details = %+v", i, n)
+		}
+	}
+}
+
+func TestUpdateNodesNormalizesBMCXnames(t *testing.T) {
+	host := mockBMC(t)
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{
+			{Xname: "X9000C1S0B0", IP: host},
+			{Xname: "x9000c1s1", IP: host},
+		},
+		Nodes: []inventory.Entry{{Xname: "X9000C1S0B0N1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.9"}},
+	}
+	res, err := UpdateNodes(context.Background(), &doc, Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.BMCs[0].Xname != "x9000c1s0b0" {
+		t.Errorf("BMC xname = %q, want it lowercased", doc.BMCs[0].Xname)
+	}
+	if !reflect.DeepEqual(res.Failed, []string{"x9000c1s1"}) || res.Queried != 2 {
+		t.Errorf("Failed = %v, Queried = %d; the slot xname must be skipped", res.Failed, res.Queried)
+	}
+	want := []inventory.Entry{
+		{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.1"},
+		{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.9"},
+	}
+	if !reflect.DeepEqual(res.Nodes, want) {
+		t.Errorf("Nodes = %+v, want %+v", res.Nodes, want)
+	}
+}
+
+func TestUpdateNodesReportsProgress(t *testing.T) {
+	host := mockBMC(t)
+	doc := inventory.FileFormat{BMCs: []inventory.Entry{
+		{Xname: "x9000c1s0b0", IP: host},
+		{Xname: "x9000c1s1b0", IP: "127.0.0.1:1"},
+		{Xname: "not-a-bmc", IP: host},
+	}}
+	var got []Progress
+	res, err := UpdateNodes(context.Background(), &doc, Options{
+		BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second,
+		Progress: func(p Progress) { got = append(got, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Progress{
+		{Done: 1, Total: 3, NICs: 2},
+		{Done: 2, Total: 3, NICs: 2, Failed: 1},
+		{Done: 3, Total: 3, NICs: 2, Failed: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("progress = %+v, want %+v", got, want)
+	}
+	// A BMC skipped for its xname was never queried, so it has no timing
+	if len(res.Timings) != 2 || res.Timings[0].Xname != "x9000c1s0b0" || res.Timings[1].Xname != "x9000c1s1b0" {
+		t.Errorf("Timings = %+v", res.Timings)
+	}
+}
+
+func TestUpdateNodesNIDOffset(t *testing.T) {
+	host := mockBMC(t)
+	newDoc := func(bmcs ...inventory.Entry) inventory.FileFormat {
+		return inventory.FileFormat{BMCs: bmcs, Nodes: []inventory.Entry{
+			{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.16"},
+			{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.15"},
+			{Xname: "x9000c1s1b0n0", MAC: "aa:bb:cc:dd:ee:00", IP: "10.0.0.3"},
+		}}
+	}
+	withNID := inventory.Entry{Xname: "x9000c1s0b0", IP: host, NID: 5}
+	withoutNID := inventory.Entry{Xname: "x9000c1s1b0", IP: host}
+	// Both BMCs are served by the same mock and so report the same MACs
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second,
+		IPStrategy: IPStrategyNIDOffset, IPOffset: 10, AllowDuplicateMACs: true}
+	want := map[string]string{
+		"x9000c1s0b0n0": "10.0.0.15", // nid 5 + 10, taken over from n1
+		"x9000c1s0b0n1": "10.0.0.16",
+		"x9000c1s1b0n0": "10.0.0.3", // no nid: keeps its address
+		"x9000c1s1b0n1": "10.0.0.1", // no nid, new: next free address
+	}
+	// The same addresses come out whichever BMC is listed first
+	for _, order := range [][]inventory.Entry{{withNID, withoutNID}, {withoutNID, withNID}} {
+		doc := newDoc(order...)
+		res, err := UpdateNodes(context.Background(), &doc, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]string{}
+		for _, n := range res.Nodes {
+			got[n.Xname] = n.IP
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("BMC order %s first: IPs = %v, want %v", order[0].Xname, got, want)
+		}
+	}
+
+	// A reserved nid address falls back to sequential allocation
+	doc := newDoc(withNID)
+	opts.Reserve = []string{"10.0.0.15"}
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Nodes[0].IP == "10.0.0.15" || res.Nodes[1].IP != "10.0.0.16" {
+		t.Errorf("with .15 reserved: %+v", res.Nodes)
+	}
+
+	opts.IPStrategy = "random"
+	if _, err := UpdateNodes(context.Background(), &doc, opts); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestUpdateNodesDuplicateMACs(t *testing.T) {
+	host := mockBMC(t)
+	// Both BMCs answer with the same systems, so every MAC is reported twice
+	newDoc := func() inventory.FileFormat {
+		return inventory.FileFormat{BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}, {Xname: "x9000c1s1b0", IP: host}}}
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second}
+
+	doc := newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DuplicateMAC{
+		{MAC: "aa:bb:cc:dd:ee:00", First: "x9000c1s0b0n0", Second: "x9000c1s1b0n0"},
+		{MAC: "aa:bb:cc:dd:ee:01", First: "x9000c1s0b0n1", Second: "x9000c1s1b0n1"},
+	}
+	if !reflect.DeepEqual(res.Duplicates, want) {
+		t.Errorf("Duplicates = %+v, want %+v", res.Duplicates, want)
+	}
+	if len(res.Nodes) != 2 || res.Nodes[1].Xname != "x9000c1s0b0n1" {
+		t.Errorf("the second occurrences should be skipped, got %+v", res.Nodes)
+	}
+
+	opts.AllowDuplicateMACs = true
+	doc = newDoc()
+	res, err = UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Duplicates) != 2 || !res.Duplicates[0].Kept || len(res.Nodes) != 4 {
+		t.Errorf("with AllowDuplicateMACs both should be kept, got %+v, %+v", res.Duplicates, res.Nodes)
+	}
+}
+
+func TestCheckDuplicateMACsMixedFormats(t *testing.T) {
+	found := []discovered{
+		{xname: "x9000c1s0b0n0", mac: "AA-BB-CC-DD-EE-00"},
+		{xname: "x9000c1s1b0n0", mac: "aa:bb:cc:dd:ee:00"},
+		{xname: "x9000c1s2b0n0", mac: "aabbccddee00"},
+		{xname: "x9000c1s3b0n0", mac: "aa:bb:cc:dd:ee:01"},
+	}
+	out, dups := checkDuplicateMACs(found, false)
+	want := []DuplicateMAC{
+		{MAC: "aa:bb:cc:dd:ee:00", First: "x9000c1s0b0n0", Second: "x9000c1s1b0n0"},
+		{MAC: "aa:bb:cc:dd:ee:00", First: "x9000c1s0b0n0", Second: "x9000c1s2b0n0"},
+	}
+	if !reflect.DeepEqual(dups, want) {
+		t.Errorf("duplicates = %+v, want %+v", dups, want)
+	}
+	if len(out) != 2 || out[0].xname != "x9000c1s0b0n0" || out[1].xname != "x9000c1s3b0n0" {
+		t.Errorf("kept %+v", out)
+	}
+}
+
+func TestUpdateNodesSkipKeepsListedNodes(t *testing.T) {
+	host := mockBMC(t)
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}, {Xname: "X9000C1S1B0", IP: "127.0.0.1:1"}},
+		Nodes: []inventory.Entry{
+			{Xname: "x9000c1s1b0n0", MAC: "aa:bb:cc:dd:ee:10", IP: "10.0.0.7", Serial: "RMA-1"},
+		},
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second,
+		ReleaseStale: true, Skip: map[string]bool{"X9000C1S1B0": true}}
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Failed) != 0 || res.Queried != 1 || !reflect.DeepEqual(res.Listed, []string{"X9000C1S1B0"}) {
+		t.Errorf("listed BMC should not be queried: %+v", res)
+	}
+	if len(res.Released) != 0 || res.CarriedOver != 1 || !reflect.DeepEqual(res.Nodes[2], doc.Nodes[0]) {
+		t.Errorf("listed node should be kept untouched, got %+v", res.Nodes)
+	}
+}
+
+func TestUpdateNodesKeepsFailedAndOrphanedNodes(t *testing.T) {
+	host := mockBMC(t)
+	newDoc := func() inventory.FileFormat {
+		return inventory.FileFormat{
+			BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}, {Xname: "x9000c1s1b0", IP: "127.0.0.1:1"}},
+			Nodes: []inventory.Entry{
+				{Xname: "x9000c1s1b0n0", MAC: "aa:bb:cc:dd:ee:10", IP: "10.0.0.7"},
+				{Xname: "x9000c1s9b0n0", MAC: "aa:bb:cc:dd:ee:90", IP: "10.0.0.9"},
+			},
+		}
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, ReleaseStale: true}
+
+	doc := newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Failed, []string{"x9000c1s1b0"}) || res.CarriedOver != 1 || len(res.Released) != 0 {
+		t.Errorf("failed BMC: %+v", res)
+	}
+	if len(res.Orphans) != 1 || res.Orphans[0].Xname != "x9000c1s9b0n0" || len(res.Nodes) != 4 {
+		t.Errorf("orphan should be reported and kept, got %+v", res.Nodes)
+	}
+
+	opts.PruneOrphans = true
+	doc = newDoc()
+	if res, err = UpdateNodes(context.Background(), &doc, opts); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, n := range res.Nodes {
+		got = append(got, n.Xname)
+	}
+	if !reflect.DeepEqual(got, []string{"x9000c1s0b0n0", "x9000c1s0b0n1", "x9000c1s1b0n0"}) {
+		t.Errorf("pruned nodes = %v", got)
+	}
+	if len(res.Released) != 1 || res.Released[0].Xname != "x9000c1s9b0n0" {
+		t.Errorf("Released = %+v, want the pruned orphan", res.Released)
+	}
+}
+
+func TestUpdateNodesCategorizesFailures(t *testing.T) {
+	host := mockBMC(t)
+	denied := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusUnauthorized)
+	}))
+	t.Cleanup(denied.Close)
+	// One system without any NIC
+	empty := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case "/redfish/v1/Systems/Node0/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(empty.Close)
+	// A listener that was closed refuses connections
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedHost := strings.TrimPrefix(closed.URL, "http://")
+	closed.Close()
+
+	doc := inventory.FileFormat{BMCs: []inventory.Entry{
+		{Xname: "x9000c1s0b0", IP: host},
+		{Xname: "x9000c1s1b0", IP: strings.TrimPrefix(denied.URL, "https://")},
+		{Xname: "x9000c1s2b0", IP: closedHost},
+		{Xname: "x9000c1s3b0", IP: strings.TrimPrefix(empty.URL, "https://")},
+	}}
+	res, err := UpdateNodes(context.Background(), &doc, Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"x9000c1s1b0": redfish.CategoryAuth,
+		"x9000c1s2b0": redfish.CategoryUnreachable,
+		"x9000c1s3b0": redfish.CategoryNoNICs,
+	}
+	if !reflect.DeepEqual(res.Categories, want) {
+		t.Errorf("Categories = %v, want %v", res.Categories, want)
+	}
+	if len(res.Failed) != 2 {
+		t.Errorf("Failed = %v, want the auth and unreachable BMCs", res.Failed)
+	}
+}
+
+func TestUpdateNodesChassisControllers(t *testing.T) {
+	host := mockBMC(t)
+	cc := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Id":"BMC","SerialNumber":"CC1","EthernetInterfaces":{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces"}}`)
+		case "/redfish/v1/Managers/BMC/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces/eth0"}]}`)
+		case "/redfish/v1/Managers/BMC/EthernetInterfaces/eth0":
+			fmt.Fprint(w, `{"Id":"eth0","MACAddress":"02:03:E8:00:00:00","IPv4Addresses":[{"Address":"192.168.100.9"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(cc.Close)
+	newDoc := func() inventory.FileFormat {
+		return inventory.FileFormat{
+			BMCs: []inventory.Entry{
+				{Xname: "X9000C1B0", IP: strings.TrimPrefix(cc.URL, "https://")},
+				{Xname: "x9000c1s0b0", IP: host},
+			},
+			Controllers: []inventory.Entry{
+				{Xname: "x9000c1b0", MAC: "02:03:e8:00:00:01", IP: "192.168.100.8", Extra: map[string]any{"location": "rack 4"}},
+				{Xname: "x9000c3b0", MAC: "02:03:e8:00:00:03", IP: "192.168.100.7"},
+			},
+		}
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second}
+
+	doc := newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Controllers != nil || len(res.Failed) != 0 || len(res.Nodes) != 2 {
+		t.Fatalf("without the option the controller must be skipped, got %+v", res)
+	}
+
+	opts.IncludeChassisControllers = true
+	doc = newDoc()
+	res, err = UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []inventory.Entry{
+		{Xname: "x9000c1b0", MAC: "02:03:e8:00:00:00", IP: "192.168.100.9", Extra: map[string]any{"location": "rack 4"}},
+		{Xname: "x9000c3b0", MAC: "02:03:e8:00:00:03", IP: "192.168.100.7"},
+	}
+	if !reflect.DeepEqual(res.Controllers, want) {
+		t.Errorf("Controllers = %+v, want %+v", res.Controllers, want)
+	}
+	if len(res.Nodes) != 2 || len(res.Categories) != 0 {
+		t.Errorf("Nodes = %+v, Categories = %v", res.Nodes, res.Categories)
+	}
+}
+
+func TestUpdateNodesPinsIdentity(t *testing.T) {
+	host := mockBMC(t)
+	redfish.ConfigureIdentity(false)
+	t.Cleanup(func() { redfish.ConfigureIdentity(false) })
+	doc := inventory.FileFormat{BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}}}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second, PinIdentity: true}
+	// run starts each pass as a new process would, with fresh connections
+	run := func(accept bool) Result {
+		t.Helper()
+		redfish.ConfigureIdentity(accept)
+		redfish.CloseIdleConnections()
+		res, err := UpdateNodes(context.Background(), &doc, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc.Nodes = res.Nodes
+		return res
+	}
+
+	res := run(false)
+	pinned := doc.BMCs[0].TLSFingerprint
+	if pinned == "" || pinned != redfish.ObservedIdentity(host) || !reflect.DeepEqual(res.Pinned, []string{"x9000c1s0b0"}) {
+		t.Fatalf("first contact: fingerprint %q, Pinned %v", pinned, res.Pinned)
+	}
+	if res = run(false); len(res.Pinned) != 0 || len(res.Failed) != 0 {
+		t.Fatalf("same certificate: Pinned %v, Failed %v", res.Pinned, res.Failed)
+	}
+
+	// Another BMC answering at the address keeps its predecessor's nodes
+	doc.BMCs[0].TLSFingerprint = "00:11:22"
+	res = run(false)
+	if res.Categories["x9000c1s0b0"] != redfish.CategoryIdentity || len(res.Nodes) != 2 || doc.BMCs[0].TLSFingerprint != "00:11:22" {
+		t.Fatalf("changed identity: Categories %v, %d node(s), fingerprint %q", res.Categories, len(res.Nodes), doc.BMCs[0].TLSFingerprint)
+	}
+
+	res = run(true)
+	if len(res.Failed) != 0 || doc.BMCs[0].TLSFingerprint != pinned {
+		t.Errorf("accepted identity: Failed %v, fingerprint %q, want %q", res.Failed, doc.BMCs[0].TLSFingerprint, pinned)
+	}
+}
+
+func TestUpdateNodesSkipsAbsentSystems(t *testing.T) {
+	// Node1 is the empty slot of a half-populated blade that still lists a NIC
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch p := r.URL.Path; {
+		case p == "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"},{"@odata.id":"/redfish/v1/Systems/Node1"}]}`)
+		case strings.HasSuffix(p, "/EthernetInterfaces"):
+			fmt.Fprintf(w, `{"Members":[{"@odata.id":"%s/eth0"}]}`, p)
+		case strings.HasSuffix(p, "/eth0"):
+			n := strings.TrimPrefix(strings.Split(p, "/")[4], "Node")
+			fmt.Fprintf(w, `{"Id":"eth0","MACAddress":"AA:BB:CC:DD:EE:0%s","UefiDevicePath":"PciRoot(0x0)/MAC(aabbccddee0%s)/IPv4(0.0.0.0)"}`, n, n)
+		case p == "/redfish/v1/Systems/Node0":
+			fmt.Fprint(w, `{"Id":"Node0","Status":{"State":"Enabled","Health":"OK"}}`)
+		case p == "/redfish/v1/Systems/Node1":
+			fmt.Fprint(w, `{"Id":"Node1","Status":{"State":"Absent","Health":"Critical"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	newDoc := func() inventory.FileFormat {
+		return inventory.FileFormat{
+			BMCs:  []inventory.Entry{{Xname: "x9000c1s0b0", IP: host}},
+			Nodes: []inventory.Entry{{Xname: "x9000c1s0b0n1", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.9"}},
+		}
+	}
+	opts := Options{BMCSubnet: "10.0.0.0/24", NodeSubnet: "10.0.0.0/24", Insecure: true, Timeout: 5 * time.Second}
+
+	doc := newDoc()
+	res, err := UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Nodes) != 1 || res.Nodes[0].Xname != "x9000c1s0b0n0" {
+		t.Errorf("Nodes = %+v, want only n0: the phantom entry of n1 is dropped", res.Nodes)
+	}
+	if !reflect.DeepEqual(res.Absent, []string{"x9000c1s0b0n1"}) {
+		t.Errorf("Absent = %v, want [x9000c1s0b0n1]", res.Absent)
+	}
+	if len(res.Categories) != 0 {
+		t.Errorf("Categories = %v, want none", res.Categories)
+	}
+
+	opts.IncludeAbsent = true
+	doc = newDoc()
+	res, err = UpdateNodes(context.Background(), &doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Nodes) != 2 || res.Nodes[1].IP != "10.0.0.9" || len(res.Absent) != 0 {
+		t.Errorf("with IncludeAbsent: Nodes = %+v, Absent = %v; want both nodes", res.Nodes, res.Absent)
+	}
+}
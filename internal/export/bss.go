@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/xname"
+)
+
+// BSSOptions controls BSS bootparams generation.
+type BSSOptions struct {
+	Kernel string
+	Initrd string
+	// Params is the kernel command line. It may contain {xname}, {ip}, and
+	// {nid} placeholders, in which case one entry is emitted per node.
+	Params string
+	// ByChassis emits one entry per chassis instead of a single entry.
+	ByChassis bool
+}
+
+// BootParams is a BSS bootparams record.
+type BootParams struct {
+	Hosts  []string `json:"hosts"`
+	Macs   []string `json:"macs"`
+	Kernel string   `json:"kernel"`
+	Initrd string   `json:"initrd"`
+	Params string   `json:"params"`
+}
+
+// BSS writes a JSON array of bootparams covering every node in nodes[]. A
+// node's nid is its nid field, or its 1-based position in nodes[] when unset. Every node must have a MAC
+// and an IP; otherwise nothing is written and the offenders are reported.
+func BSS(w io.Writer, doc inventory.FileFormat, opts BSSOptions) error {
+	var missing []string
+	for _, n := range doc.Nodes {
+		if n.MAC == "" || n.IP == "" {
+			missing = append(missing, n.Xname)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d node(s) missing a MAC or IP: %s", len(missing), strings.Join(missing, ", "))
+	}
+	if len(doc.Nodes) == 0 {
+		return fmt.Errorf("input must contain non-empty nodes[]")
+	}
+
+	var out []BootParams
+	if perNodeParams(opts.Params) {
+		for i, n := range doc.Nodes {
+			nid := n.NID
+			if nid == 0 {
+				nid = i + 1
+			}
+			out = append(out, BootParams{
+				Hosts:  []string{n.Xname},
+				Macs:   []string{n.MAC},
+				Kernel: opts.Kernel,
+				Initrd: opts.Initrd,
+				Params: expandParams(opts.Params, n, nid),
+			})
+		}
+	} else {
+		index := map[string]int{}
+		for _, n := range doc.Nodes {
+			key := ""
+			if opts.ByChassis {
+				key = xname.Chassis(n.Xname)
+			}
+			i, ok := index[key]
+			if !ok {
+				i = len(out)
+				index[key] = i
+				out = append(out, BootParams{Kernel: opts.Kernel, Initrd: opts.Initrd, Params: opts.Params})
+			}
+			out[i].Hosts = append(out[i].Hosts, n.Xname)
+			out[i].Macs = append(out[i].Macs, n.MAC)
+		}
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// perNodeParams reports whether params references a per-node placeholder.
+func perNodeParams(params string) bool {
+	for _, p := range []string{"{xname}", "{ip}", "{nid}"} {
+		if strings.Contains(params, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandParams substitutes the per-node placeholders in params.
+func expandParams(params string, n inventory.Entry, nid int) string {
+	return strings.NewReplacer("{xname}", n.Xname, "{ip}", n.IP, "{nid}", strconv.Itoa(nid)).Replace(params)
+}
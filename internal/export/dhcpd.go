@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"bootstrap/internal/inventory"
+)
+
+// DhcpdOptions controls ISC dhcpd host stanza generation.
+type DhcpdOptions struct {
+	// IncludeBMCs emits bmcs[] entries ahead of nodes[].
+	IncludeBMCs bool
+	// Group, when set, wraps all host stanzas in a named group.
+	Group string
+	// NextServer and Filename are emitted as group-level PXE options.
+	NextServer string
+	Filename   string
+}
+
+// Dhcpd writes one host <xname> { hardware ethernet ...; fixed-address ...; }
+// stanza per entry. Entries missing a MAC or IP are not written; their xnames
+// are returned. Duplicate MACs make dhcpd refuse to start, so they are
+// reported as an error before anything is written.
+func Dhcpd(w io.Writer, doc inventory.FileFormat, opts DhcpdOptions) ([]string, error) {
+	entries := selectEntries(doc, opts.IncludeBMCs)
+	if err := checkDuplicateMACs(entries); err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(w)
+	indent := ""
+	if opts.Group != "" {
+		fmt.Fprintf(bw, "group %q {\n", opts.Group)
+		if opts.NextServer != "" {
+			fmt.Fprintf(bw, "  next-server %s;\n", opts.NextServer)
+		}
+		if opts.Filename != "" {
+			fmt.Fprintf(bw, "  filename %q;\n", opts.Filename)
+		}
+		indent = "  "
+	}
+	var skipped []string
+	first := true
+	for _, e := range entries {
+		if e.MAC == "" || e.IP == "" {
+			skipped = append(skipped, e.Xname)
+			continue
+		}
+		if !first || opts.Group != "" {
+			fmt.Fprintln(bw)
+		}
+		first = false
+		fmt.Fprintf(bw, "%shost %s {\n", indent, e.Xname)
+		fmt.Fprintf(bw, "%s  hardware ethernet %s;\n", indent, strings.ToLower(e.MAC))
+		fmt.Fprintf(bw, "%s  fixed-address %s;\n", indent, e.IP)
+		fmt.Fprintf(bw, "%s}\n", indent)
+	}
+	if opts.Group != "" {
+		fmt.Fprintln(bw, "}")
+	}
+	return skipped, bw.Flush()
+}
+
+// checkDuplicateMACs returns an error naming every MAC used by more than one entry.
+func checkDuplicateMACs(entries []inventory.Entry) error {
+	owner := map[string]string{}
+	var dups []string
+	for _, e := range entries {
+		if e.MAC == "" {
+			continue
+		}
+		mac := strings.ToLower(e.MAC)
+		if prev, ok := owner[mac]; ok {
+			dups = append(dups, fmt.Sprintf("%s (%s and %s)", mac, prev, e.Xname))
+			continue
+		}
+		owner[mac] = e.Xname
+	}
+	if len(dups) > 0 {
+		return fmt.Errorf("duplicate MAC address(es): %s", strings.Join(dups, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package export renders inventory records in formats consumed by other services.
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"bootstrap/internal/inventory"
+)
+
+// DnsmasqOptions controls dnsmasq dhcp-host generation.
+type DnsmasqOptions struct {
+	// LeaseTime is appended to every line when set, e.g. "infinite" or "12h".
+	LeaseTime string
+	// IncludeBMCs emits bmcs[] entries ahead of nodes[].
+	IncludeBMCs bool
+}
+
+// Dnsmasq writes one dhcp-host=<mac>,<ip>,<xname>[,<lease>] line per entry.
+// Entries missing a MAC or IP are not written; their xnames are returned so
+// the caller can warn about them.
+func Dnsmasq(w io.Writer, doc inventory.FileFormat, opts DnsmasqOptions) ([]string, error) {
+	var skipped []string
+	for _, e := range selectEntries(doc, opts.IncludeBMCs) {
+		if e.MAC == "" || e.IP == "" {
+			skipped = append(skipped, e.Xname)
+			continue
+		}
+		line := fmt.Sprintf("dhcp-host=%s,%s,%s", e.MAC, e.IP, e.Xname)
+		if opts.LeaseTime != "" {
+			line += "," + opts.LeaseTime
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+// selectEntries returns nodes[], preceded by bmcs[] when includeBMCs is set.
+func selectEntries(doc inventory.FileFormat, includeBMCs bool) []inventory.Entry {
+	var out []inventory.Entry
+	if includeBMCs {
+		out = append(out, doc.BMCs...)
+	}
+	return append(out, doc.Nodes...)
+}
@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"bootstrap/internal/inventory"
+
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+func loadFixture(t *testing.T) inventory.FileFormat {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "inventory.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc inventory.FileFormat
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// checkGolden compares got against testdata/<name>, rewriting it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestDnsmasqGolden(t *testing.T) {
+	var buf bytes.Buffer
+	skipped, err := Dnsmasq(&buf, loadFixture(t), DnsmasqOptions{LeaseTime: "infinite", IncludeBMCs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"x9000c1s0b1n1"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+	checkGolden(t, "dnsmasq.golden", buf.Bytes())
+}
+
+func TestDnsmasqNodesOnly(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Dnsmasq(&buf, loadFixture(t), DnsmasqOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "dhcp-host=00:40:a6:88:d9:01,10.42.0.1,x9000c1s0b0n0\n" +
+		"dhcp-host=00:40:a6:88:d9:02,10.42.0.2,x9000c1s0b0n1\n" +
+		"dhcp-host=00:40:a6:88:d9:03,10.42.0.3,x9000c1s0b1n0\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDhcpdGolden(t *testing.T) {
+	var buf bytes.Buffer
+	skipped, err := Dhcpd(&buf, loadFixture(t), DhcpdOptions{Group: "compute", NextServer: "10.42.0.254", Filename: "ipxe.efi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"x9000c1s0b1n1"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+	checkGolden(t, "dhcpd.golden", buf.Bytes())
+}
+
+func TestDhcpdRejectsDuplicateMACs(t *testing.T) {
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{{Xname: "x9000c1s0b0", MAC: "AA:BB:CC:DD:EE:FF", IP: "192.168.100.1"}},
+		Nodes: []inventory.Entry{
+			{Xname: "x9000c1s0b0n0", MAC: "aa:bb:cc:dd:ee:ff", IP: "10.42.0.1"},
+		},
+	}
+	var buf bytes.Buffer
+	_, err := Dhcpd(&buf, doc, DhcpdOptions{IncludeBMCs: true})
+	if err == nil {
+		t.Fatal("expected duplicate MAC error")
+	}
+	if !strings.Contains(err.Error(), "x9000c1s0b0 and x9000c1s0b0n0") {
+		t.Errorf("error should name both xnames: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written on error, got:\n%s", buf.String())
+	}
+}
+
+func TestBSSRequiresMACAndIP(t *testing.T) {
+	var buf bytes.Buffer
+	err := BSS(&buf, loadFixture(t), BSSOptions{Kernel: "k", Initrd: "i"})
+	if err == nil || !strings.Contains(err.Error(), "x9000c1s0b1n1") {
+		t.Fatalf("expected error naming x9000c1s0b1n1, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written on validation failure, got:\n%s", buf.String())
+	}
+}
+
+func TestBSSGrouping(t *testing.T) {
+	doc := loadFixture(t)
+	doc.Nodes = doc.Nodes[:3]
+	doc.Nodes = append(doc.Nodes, inventory.Entry{Xname: "x9000c2s0b0n0", MAC: "00:40:a6:88:d9:04", IP: "10.42.0.5"})
+
+	var buf bytes.Buffer
+	if err := BSS(&buf, doc, BSSOptions{Kernel: "k", Initrd: "i", Params: "console=ttyS0", ByChassis: true}); err != nil {
+		t.Fatal(err)
+	}
+	var got []BootParams
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(got[0].Hosts) != 3 || got[1].Hosts[0] != "x9000c2s0b0n0" || got[1].Macs[0] != "00:40:a6:88:d9:04" {
+		t.Errorf("unexpected chassis grouping: %+v", got)
+	}
+
+	buf.Reset()
+	if err := BSS(&buf, doc, BSSOptions{Kernel: "k", Initrd: "i", Params: "ip={ip} host={xname} nid={nid}"}); err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || got[1].Params != "ip=10.42.0.2 host=x9000c1s0b0n1 nid=2" {
+		t.Errorf("unexpected per-node params: %+v", got)
+	}
+
+	buf.Reset()
+	doc.Nodes[1].NID = 42
+	if err := BSS(&buf, doc, BSSOptions{Params: "nid={nid}"}); err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got[1].Params != "nid=42" || got[2].Params != "nid=3" {
+		t.Errorf("nid field not preferred over position: %+v", got)
+	}
+}
+
+func TestHostsGolden(t *testing.T) {
+	var buf bytes.Buffer
+	skipped, err := Hosts(&buf, loadFixture(t), HostsOptions{Domain: "cluster.local", Alias: "nid{nid:06}", BMCSuffix: "-mgmt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("skipped = %v, want none", skipped)
+	}
+	checkGolden(t, "hosts.golden", buf.Bytes())
+}
+
+func TestAnsibleInventoryGolden(t *testing.T) {
+	doc := loadFixture(t)
+	doc.Nodes = append(doc.Nodes, inventory.Entry{Xname: "x9000c2s0b0n0", IP: "10.42.0.5", NID: 7, Role: "compute"})
+	var buf bytes.Buffer
+	if _, err := AnsibleInventory(&buf, doc, HostsOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "ansible.golden", buf.Bytes())
+}
+
+func TestHostsRejectsDuplicateIPs(t *testing.T) {
+	doc := inventory.FileFormat{
+		BMCs:  []inventory.Entry{{Xname: "x9000c1s0b0", IP: "10.42.0.1"}},
+		Nodes: []inventory.Entry{{Xname: "x9000c1s0b0n0", IP: "10.42.0.1"}, {Xname: "x9000c1s0b0n1"}},
+	}
+	var buf bytes.Buffer
+	_, err := Hosts(&buf, doc, HostsOptions{})
+	if err == nil || !strings.Contains(err.Error(), "10.42.0.1 (x9000c1s0b0 and x9000c1s0b0n0)") {
+		t.Fatalf("expected duplicate IP error naming both xnames, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written on error, got:\n%s", buf.String())
+	}
+}
+
+func TestSLSGolden(t *testing.T) {
+	doc := loadFixture(t)
+	doc.BMCs = append(doc.BMCs, inventory.Entry{Xname: "x9000c1b0", IP: "192.168.100.10"})
+	doc.Nodes[0].Role = "compute"
+	doc.Nodes[1].NID = 42
+	var buf bytes.Buffer
+	if err := SLS(&buf, doc, SLSOptions{Class: "Mountain"}); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "sls.golden", buf.Bytes())
+}
+
+func TestSLSRejectsMisplacedXnames(t *testing.T) {
+	doc := inventory.FileFormat{
+		BMCs: []inventory.Entry{{Xname: "x9000c1s0b0"}, {Xname: "x9000c1s0"}},
+		Nodes: []inventory.Entry{
+			{Xname: "x9000c1s0b0n0"}, {Xname: "x9000c1s0b0"}, {Xname: "node7"}, {Xname: "X9000C1S0B0N0"},
+		},
+	}
+	var buf bytes.Buffer
+	err := SLS(&buf, doc, SLSOptions{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"4 entr(ies) cannot be placed",
+		"bmcs[1]: x9000c1s0 is a slot xname, want a BMC xname",
+		"nodes[1]: x9000c1s0b0 is a BMC xname, want a node xname",
+		`nodes[2]: invalid xname "node7"`,
+		"nodes[3]: duplicate xname x9000c1s0b0n0",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error lacks %q: %v", want, err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written on error, got:\n%s", buf.String())
+	}
+	if err := SLS(&buf, loadFixture(t), SLSOptions{Class: "Rack"}); err == nil || !strings.Contains(err.Error(), `invalid class "Rack"`) {
+		t.Errorf("class: err = %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/xname"
+)
+
+// HostsOptions controls /etc/hosts and Ansible inventory generation.
+type HostsOptions struct {
+	// Domain, when set, adds <name>.<Domain> after every name.
+	Domain string
+	// Alias is a node alias pattern with {xname} and {nid} placeholders;
+	// {nid:N} pads the nid with zeros to N digits, e.g. nid{nid:06}.
+	Alias string
+	// BMCSuffix, when set, gives every BMC the alias of its first node (or
+	// that node's xname without Alias) followed by BMCSuffix, e.g. -mgmt.
+	BMCSuffix string
+}
+
+// hostRecord is one entry of the hosts output.
+type hostRecord struct {
+	addr    netip.Addr
+	xname   string
+	aliases []string
+	bmc     bool
+	entry   inventory.Entry
+}
+
+// Hosts writes one "<ip> <xname> [aliases...]" line per entry of bmcs[] and
+// nodes[], sorted by IP. Entries without an IP are not written; their xnames
+// are returned. An IP used by more than one entry is an error, reported
+// before anything is written.
+func Hosts(w io.Writer, doc inventory.FileFormat, opts HostsOptions) ([]string, error) {
+	records, skipped, err := hostRecords(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	for _, r := range records {
+		fmt.Fprintln(bw, strings.Join(append([]string{r.addr.String()}, withDomain(append([]string{r.xname}, r.aliases...), opts.Domain)...), " "))
+	}
+	return skipped, bw.Flush()
+}
+
+// AnsibleInventory writes an INI Ansible inventory with a group per chassis
+// for nodes ([x9000c1]) and for BMCs ([x9000c1_bmcs]), and the parent
+// groups [nodes] and [bmcs]. Hosts are named by xname (with Domain, when
+// set) and sorted by IP within a group; ansible_host is the IP. Entries are
+// selected and checked as for Hosts.
+func AnsibleInventory(w io.Writer, doc inventory.FileFormat, opts HostsOptions) ([]string, error) {
+	records, skipped, err := hostRecords(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string][]hostRecord{}
+	var ungrouped []hostRecord
+	for _, r := range records {
+		chassis := xname.Chassis(r.xname)
+		if chassis == "" {
+			ungrouped = append(ungrouped, r)
+			continue
+		}
+		if r.bmc {
+			chassis += "_bmcs"
+		}
+		groups[chassis] = append(groups[chassis], r)
+	}
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		names = append(names, g)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if c := xname.Compare(strings.TrimSuffix(a, "_bmcs"), strings.TrimSuffix(b, "_bmcs")); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, r := range ungrouped {
+		fmt.Fprintln(bw, ansibleHost(r, opts.Domain))
+	}
+	var nodeGroups, bmcGroups []string
+	for _, g := range names {
+		if len(ungrouped) > 0 || len(nodeGroups)+len(bmcGroups) > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "[%s]\n", g)
+		for _, r := range groups[g] {
+			fmt.Fprintln(bw, ansibleHost(r, opts.Domain))
+		}
+		if strings.HasSuffix(g, "_bmcs") {
+			bmcGroups = append(bmcGroups, g)
+		} else {
+			nodeGroups = append(nodeGroups, g)
+		}
+	}
+	for _, parent := range []struct {
+		name     string
+		children []string
+	}{{"nodes", nodeGroups}, {"bmcs", bmcGroups}} {
+		if len(parent.children) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "\n[%s:children]\n%s\n", parent.name, strings.Join(parent.children, "\n"))
+	}
+	return skipped, bw.Flush()
+}
+
+func ansibleHost(r hostRecord, domain string) string {
+	line := r.xname
+	if domain = strings.Trim(domain, "."); domain != "" {
+		line += "." + domain
+	}
+	line += " ansible_host=" + r.addr.String()
+	if r.entry.NID != 0 && !r.bmc {
+		line += " nid=" + strconv.Itoa(r.entry.NID)
+	}
+	if r.entry.Role != "" {
+		line += " role=" + r.entry.Role
+	}
+	return line
+}
+
+// hostRecords returns the entries of bmcs[] and nodes[] with an IP, sorted
+// by IP, and the xnames of those without one.
+func hostRecords(doc inventory.FileFormat, opts HostsOptions) ([]hostRecord, []string, error) {
+	// A node's nid is its nid field or its 1-based position, as for BSS
+	nodeName := map[string]string{}
+	for i, n := range doc.Nodes {
+		name := n.Xname
+		if opts.Alias != "" {
+			nid := n.NID
+			if nid == 0 {
+				nid = i + 1
+			}
+			name = expandAlias(opts.Alias, n.Xname, nid)
+		}
+		nodeName[n.Xname] = name
+	}
+
+	var records []hostRecord
+	var skipped, invalid []string
+	add := func(e inventory.Entry, bmc bool, aliases []string) {
+		if e.IP == "" {
+			skipped = append(skipped, e.Xname)
+			return
+		}
+		addr, err := netip.ParseAddr(e.IP)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s (%s)", e.IP, e.Xname))
+			return
+		}
+		records = append(records, hostRecord{addr: addr, xname: e.Xname, aliases: aliases, bmc: bmc, entry: e})
+	}
+	for _, b := range doc.BMCs {
+		var aliases []string
+		if opts.BMCSuffix != "" {
+			if name, ok := nodeName[firstNode(doc.Nodes, b.Xname)]; ok {
+				aliases = append(aliases, name+opts.BMCSuffix)
+			}
+		}
+		add(b, true, aliases)
+	}
+	for _, n := range doc.Nodes {
+		var aliases []string
+		if name := nodeName[n.Xname]; name != n.Xname {
+			aliases = append(aliases, name)
+		}
+		add(n, false, aliases)
+	}
+	if len(invalid) > 0 {
+		return nil, nil, fmt.Errorf("invalid IP address(es): %s", strings.Join(invalid, ", "))
+	}
+
+	slices.SortStableFunc(records, func(a, b hostRecord) int { return a.addr.Compare(b.addr) })
+	var dups []string
+	for i := 1; i < len(records); i++ {
+		if records[i].addr == records[i-1].addr {
+			dups = append(dups, fmt.Sprintf("%s (%s and %s)", records[i].addr, records[i-1].xname, records[i].xname))
+		}
+	}
+	if len(dups) > 0 {
+		return nil, nil, fmt.Errorf("duplicate IP address(es): %s", strings.Join(dups, "; "))
+	}
+	return records, skipped, nil
+}
+
+// firstNode returns the xname of the first node in nodes managed by the BMC
+// bmcX, or "" when there is none.
+func firstNode(nodes []inventory.Entry, bmcX string) string {
+	for _, n := range nodes {
+		x, err := xname.Parse(n.Xname)
+		if err != nil || x.Kind != xname.KindNode {
+			continue
+		}
+		if b, _ := x.Ancestor(xname.KindBMC); b.String() == bmcX {
+			return n.Xname
+		}
+	}
+	return ""
+}
+
+var nidPlaceholder = regexp.MustCompile(`\{nid(?::(\d+))?\}`)
+
+// expandAlias substitutes {xname}, {nid}, and {nid:N} in pattern.
+func expandAlias(pattern, x string, nid int) string {
+	s := strings.ReplaceAll(pattern, "{xname}", x)
+	return nidPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		width := nidPlaceholder.FindStringSubmatch(m)[1]
+		if width == "" {
+			return strconv.Itoa(nid)
+		}
+		w, _ := strconv.Atoi(width)
+		return fmt.Sprintf("%0*d", w, nid)
+	})
+}
+
+// withDomain follows every name with its FQDN in domain, if one is set.
+func withDomain(names []string, domain string) []string {
+	domain = strings.Trim(domain, ".")
+	if domain == "" {
+		return names
+	}
+	out := make([]string, 0, 2*len(names))
+	for _, n := range names {
+		out = append(out, n, n+"."+domain)
+	}
+	return out
+}
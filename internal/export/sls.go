@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/xname"
+)
+
+// SLS component types and classes.
+const (
+	SLSTypeNode       = "comptype_node"
+	SLSTypeNodeBMC    = "comptype_nodebmc"
+	SLSTypeChassisBMC = "comptype_chassis_bmc"
+)
+
+// SLSClasses are the hardware classes SLS accepts.
+var SLSClasses = []string{"River", "Mountain", "Hill"}
+
+// SLSOptions controls SLS hardware generation.
+type SLSOptions struct {
+	// Class is the class of every component: River, Mountain, or Hill.
+	// Empty means River.
+	Class string
+}
+
+// SLSHardware is one entry of the SLS Hardware map.
+type SLSHardware struct {
+	Parent          string              `json:"Parent"`
+	Children        []string            `json:"Children,omitempty"`
+	Xname           string              `json:"Xname"`
+	Type            string              `json:"Type"`
+	Class           string              `json:"Class"`
+	TypeString      string              `json:"TypeString"`
+	ExtraProperties *SLSExtraProperties `json:"ExtraProperties,omitempty"`
+}
+
+// SLSExtraProperties carries the inventory fields of an SLS entry.
+type SLSExtraProperties struct {
+	NID  int    `json:"NID,omitempty"`
+	Role string `json:"Role,omitempty"`
+	MAC  string `json:"MAC,omitempty"`
+	IP   string `json:"IP,omitempty"`
+}
+
+// SLSState is the part of an SLS dump that SLS writes: its Hardware map,
+// keyed by xname.
+type SLSState struct {
+	Hardware map[string]SLSHardware `json:"Hardware"`
+}
+
+// SLS writes the SLS Hardware map for bmcs[] and nodes[]. A BMC's parent is
+// its slot and its children are the nodes of nodes[] it manages; a chassis
+// controller in bmcs[] (e.g. x9000c1b0) is a comptype_chassis_bmc whose
+// parent is its chassis; a node's parent is its BMC. A node's NID is its
+// nid field, or its 1-based position in nodes[] when unset, as for BSS, and
+// its role is capitalized the way SLS spells it (compute -> Compute). Every
+// entry must have an xname of the kind its list holds, used only once;
+// otherwise nothing is written and the offenders are reported.
+func SLS(w io.Writer, doc inventory.FileFormat, opts SLSOptions) error {
+	class := opts.Class
+	if class == "" {
+		class = "River"
+	}
+	if !slices.Contains(SLSClasses, class) {
+		return fmt.Errorf("invalid class %q (want %s)", class, strings.Join(SLSClasses, ", "))
+	}
+
+	hw := map[string]SLSHardware{}
+	var invalid []string
+	add := func(where string, h SLSHardware) {
+		if _, ok := hw[h.Xname]; ok {
+			invalid = append(invalid, fmt.Sprintf("%s: duplicate xname %s", where, h.Xname))
+			return
+		}
+		hw[h.Xname] = h
+	}
+	for i, b := range doc.BMCs {
+		where := fmt.Sprintf("bmcs[%d]", i)
+		props := extraProperties(b, 0)
+		if cc, chassis, ok := xname.ParseChassisBMC(b.Xname); ok {
+			add(where, SLSHardware{Parent: chassis, Xname: cc, Type: SLSTypeChassisBMC, Class: class, TypeString: "ChassisBMC", ExtraProperties: props})
+			continue
+		}
+		x, err := placeXname(b.Xname, xname.KindBMC)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", where, err))
+			continue
+		}
+		slot, _ := x.Ancestor(xname.KindSlot)
+		add(where, SLSHardware{Parent: slot.String(), Xname: x.String(), Type: SLSTypeNodeBMC, Class: class, TypeString: "NodeBMC", ExtraProperties: props})
+	}
+	children := map[string][]string{}
+	for i, n := range doc.Nodes {
+		where := fmt.Sprintf("nodes[%d]", i)
+		x, err := placeXname(n.Xname, xname.KindNode)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", where, err))
+			continue
+		}
+		nid := n.NID
+		if nid == 0 {
+			nid = i + 1
+		}
+		bmc, _ := x.Ancestor(xname.KindBMC)
+		add(where, SLSHardware{Parent: bmc.String(), Xname: x.String(), Type: SLSTypeNode, Class: class, TypeString: "Node", ExtraProperties: extraProperties(n, nid)})
+		children[bmc.String()] = append(children[bmc.String()], x.String())
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d entr(ies) cannot be placed in the SLS hierarchy: %s", len(invalid), strings.Join(invalid, "; "))
+	}
+	if len(hw) == 0 {
+		return fmt.Errorf("input must contain non-empty bmcs[] or nodes[]")
+	}
+	for parent, kids := range children {
+		if h, ok := hw[parent]; ok && h.Type == SLSTypeNodeBMC {
+			slices.SortFunc(kids, xname.Compare)
+			h.Children = kids
+			hw[parent] = h
+		}
+	}
+
+	b, err := json.MarshalIndent(SLSState{Hardware: hw}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// placeXname parses s and checks that it names a component of kind want.
+func placeXname(s string, want xname.Kind) (xname.Xname, error) {
+	if s == "" {
+		return xname.Xname{}, fmt.Errorf("missing xname")
+	}
+	x, err := xname.Parse(s)
+	if err != nil {
+		return xname.Xname{}, err
+	}
+	if x.Kind != want {
+		return xname.Xname{}, fmt.Errorf("%s is a %s xname, want a %s xname", x, x.Kind, want)
+	}
+	return x, nil
+}
+
+// extraProperties returns the SLS ExtraProperties of e, or nil when it has
+// none.
+func extraProperties(e inventory.Entry, nid int) *SLSExtraProperties {
+	p := SLSExtraProperties{NID: nid, Role: slsRole(e.Role), MAC: e.MAC, IP: e.IP}
+	if p == (SLSExtraProperties{}) {
+		return nil
+	}
+	return &p
+}
+
+// slsRole capitalizes role the way SLS spells roles, e.g. compute ->
+// Compute.
+func slsRole(role string) string {
+	r := []rune(role)
+	if len(r) == 0 {
+		return ""
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
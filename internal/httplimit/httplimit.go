@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package httplimit caps the size of HTTP response bodies, so that a BMC or
+// service that sends an enormous or endless body fails the request instead
+// of exhausting memory. The cap applies to the body as decoded, after any
+// transparent gzip decompression, so a small compressed body cannot expand
+// past it either.
+package httplimit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// DefaultMaxBytes is the limit in effect until Configure is called.
+const DefaultMaxBytes int64 = 16 << 20
+
+var maxBytes atomic.Int64
+
+func init() { maxBytes.Store(DefaultMaxBytes) }
+
+// Configure sets the limit of every response body wrapped from then on; 0
+// removes it.
+func Configure(n int64) {
+	maxBytes.Store(n)
+}
+
+// Error is a response body that went over the limit.
+type Error struct {
+	Method string
+	Path   string
+	Limit  int64
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: response too large: more than %d bytes (see --max-response-size)", e.Method, e.Path, e.Limit)
+}
+
+// Limit wraps the body of resp so that reading past the configured limit
+// fails with an *Error naming the request, and returns resp.
+func Limit(resp *http.Response) *http.Response {
+	n := maxBytes.Load()
+	if n <= 0 {
+		return resp
+	}
+	e := &Error{Limit: n}
+	if resp.Request != nil {
+		e.Method, e.Path = resp.Request.Method, resp.Request.URL.Path
+	}
+	resp.Body = &body{ReadCloser: resp.Body, left: n, err: e}
+	return resp
+}
+
+// body reads at most left more bytes from ReadCloser, then fails with err
+// as soon as one more is available.
+type body struct {
+	io.ReadCloser
+	left int64
+	err  *Error
+	over bool
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	if b.over {
+		return 0, b.err
+	}
+	// One byte past the limit tells a body that ends exactly on it from
+	// one that goes on
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.left {
+		b.over = true
+		return int(b.left), b.err
+	}
+	b.left -= int64(n)
+	return n, err
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package httplimit
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// response is a GET of /redfish/v1/Systems answered with body.
+func response(t *testing.T, body io.Reader) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest("GET", "https://bmc/redfish/v1/Systems", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Response{Request: req, Body: io.NopCloser(body)}
+}
+
+// endless is a body that never ends.
+type endless struct{}
+
+func (endless) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestLimit(t *testing.T) {
+	Configure(10)
+	t.Cleanup(func() { Configure(DefaultMaxBytes) })
+
+	// Exactly the limit is fine
+	got, err := io.ReadAll(Limit(response(t, strings.NewReader("0123456789"))).Body)
+	if err != nil || string(got) != "0123456789" {
+		t.Errorf("body at the limit = %q, %v", got, err)
+	}
+
+	for name, body := range map[string]io.Reader{"one byte over": strings.NewReader("0123456789a"), "endless": endless{}} {
+		got, err := io.ReadAll(Limit(response(t, body)).Body)
+		var lerr *Error
+		if !errors.As(err, &lerr) || len(got) != 10 {
+			t.Fatalf("%s: read %d bytes, err = %v; want 10 and an *Error", name, len(got), err)
+		}
+		if msg := err.Error(); !strings.Contains(msg, "GET /redfish/v1/Systems: response too large: more than 10 bytes") {
+			t.Errorf("%s: error = %q", name, msg)
+		}
+	}
+
+	Configure(0)
+	if got, err := io.ReadAll(Limit(response(t, strings.NewReader(strings.Repeat("x", 100)))).Body); err != nil || len(got) != 100 {
+		t.Errorf("without a limit: read %d bytes, %v", len(got), err)
+	}
+}
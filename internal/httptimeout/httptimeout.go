@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package httptimeout bounds the phases of an HTTP request separately and
+// reports which phase a timed-out request was in. A BMC behind a dead switch
+// port never answers the TCP connect, while a wedged one accepts it and then
+// stalls; with only an overall timeout both burn all of it and look the same.
+package httptimeout
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Phases of a request, as named in an Error.
+const (
+	PhaseConnect        = "connect"
+	PhaseTLSHandshake   = "TLS handshake"
+	PhaseResponseHeader = "response headers"
+	PhaseResponseBody   = "response body"
+)
+
+// Timeouts bounds the phases of every request. A zero field leaves its
+// phase bounded only by the request's overall timeout.
+type Timeouts struct {
+	// Connect bounds the TCP connect to each address of a host, not the
+	// name lookup before it.
+	Connect time.Duration
+	// TLSHandshake bounds the TLS handshake after the connect.
+	TLSHandshake time.Duration
+	// ResponseHeader bounds the wait for the response headers once the
+	// request is written.
+	ResponseHeader time.Duration
+}
+
+// Default is the Timeouts in effect until Configure is called.
+var Default = Timeouts{Connect: 3 * time.Second, TLSHandshake: 5 * time.Second}
+
+var (
+	mu      sync.Mutex
+	current = Default
+)
+
+// Configure sets the Timeouts of every request made from then on. Transports
+// prepared by Apply before the call keep their old TLS handshake and response
+// header timeouts.
+func Configure(t Timeouts) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = t
+}
+
+func timeouts() Timeouts {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// Apply sets the dialer and the TLS handshake and response header timeouts
+// of tr from the configured Timeouts and returns tr.
+func Apply(tr *http.Transport) *http.Transport {
+	t := timeouts()
+	tr.DialContext = DialContext
+	tr.TLSHandshakeTimeout = t.TLSHandshake
+	tr.ResponseHeaderTimeout = t.ResponseHeader
+	return tr
+}
+
+// DialContext connects to addr like net.Dialer.DialContext, but looks the
+// host up first, bounded only by ctx, and gives each of its addresses the
+// configured Connect timeout. A slow name server therefore does not eat
+// into the connect budget, and a failed lookup stays a *net.DNSError.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips := []string{host}
+	if net.ParseIP(host) == nil {
+		if ips, err = net.DefaultResolver.LookupHost(ctx, host); err != nil {
+			return nil, err
+		}
+	}
+	d := net.Dialer{Timeout: timeouts().Connect, KeepAlive: 30 * time.Second}
+	var first error
+	for _, ip := range ips {
+		conn, err := d.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		if first == nil {
+			first = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, first
+}
+
+// Error is a request that timed out, with the phase it was in.
+type Error struct {
+	Phase string
+	Err   error
+}
+
+func (e *Error) Error() string { return e.Phase + " timed out: " + e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Timeout reports true, so that an Error is a net.Error that timed out.
+func (e *Error) Timeout() bool { return true }
+
+// Temporary reports true, like the timeouts of package net.
+func (e *Error) Temporary() bool { return true }
+
+// PhaseOf returns the phase err timed out in, or "" when it is not an Error.
+func PhaseOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Phase
+	}
+	return ""
+}
+
+// Do sends req with c like c.Do, but a request that times out, through the
+// Timeouts, c.Timeout, or req's context deadline, returns an *Error naming
+// the phase it was in. So does a read of the response body that times out.
+func Do(c *http.Client, req *http.Request) (*http.Response, error) {
+	p := &phase{name: PhaseConnect}
+	trace := &httptrace.ClientTrace{
+		ConnectStart:      func(string, string) { p.set(PhaseConnect) },
+		TLSHandshakeStart: func() { p.set(PhaseTLSHandshake) },
+		GotConn:           func(httptrace.GotConnInfo) { p.set(PhaseResponseHeader) },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, wrap(p.get(), err)
+	}
+	resp.Body = body{resp.Body}
+	return resp, nil
+}
+
+// phase is the phase a request has reached; trace hooks may run on other
+// goroutines.
+type phase struct {
+	mu   sync.Mutex
+	name string
+}
+
+func (p *phase) set(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.name = name
+}
+
+func (p *phase) get() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.name
+}
+
+// wrap returns err as an *Error in phase when it is a timeout.
+func wrap(phase string, err error) error {
+	var nerr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &nerr) && nerr.Timeout() {
+		return &Error{Phase: phase, Err: err}
+	}
+	return err
+}
+
+// body wraps the timeouts of a response body in an *Error.
+type body struct {
+	io.ReadCloser
+}
+
+func (b body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = wrap(PhaseResponseBody, err)
+	}
+	return n, err
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package httptimeout
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withTimeouts configures t for the test and restores the default after it.
+func withTimeouts(tb testing.TB, t Timeouts) {
+	tb.Helper()
+	Configure(t)
+	tb.Cleanup(func() { Configure(Default) })
+}
+
+func client(timeout time.Duration) *http.Client {
+	tr := Apply(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}})
+	return &http.Client{Timeout: timeout, Transport: tr}
+}
+
+func get(t *testing.T, c *http.Client, url string) error {
+	t.Helper()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Do(c, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	_, err = io.ReadAll(resp.Body)
+	return err
+}
+
+func TestTLSHandshakePhase(t *testing.T) {
+	// Accepts the connection but never answers the ClientHello, like a
+	// wedged BMC
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close() // nolint:errcheck
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close() // nolint:errcheck
+		}
+	}()
+	withTimeouts(t, Timeouts{Connect: time.Second, TLSHandshake: 100 * time.Millisecond})
+
+	start := time.Now()
+	err = get(t, client(10*time.Second), "https://"+ln.Addr().String()+"/")
+	if PhaseOf(err) != PhaseTLSHandshake {
+		t.Fatalf("err = %v, want a %s timeout", err, PhaseTLSHandshake)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("took %v; the handshake timeout did not cut the request short", d)
+	}
+	var nerr net.Error
+	if !errors.As(err, &nerr) || !nerr.Timeout() {
+		t.Errorf("err = %v, want a net.Error that timed out", err)
+	}
+}
+
+func TestResponsePhases(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/body" {
+			w.Write([]byte("{")) // nolint:errcheck
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	t.Run("header timeout", func(t *testing.T) {
+		withTimeouts(t, Timeouts{Connect: time.Second, TLSHandshake: time.Second, ResponseHeader: 100 * time.Millisecond})
+		err := get(t, client(10*time.Second), srv.URL+"/headers")
+		if PhaseOf(err) != PhaseResponseHeader {
+			t.Fatalf("err = %v, want a %s timeout", err, PhaseResponseHeader)
+		}
+		if !strings.HasPrefix(err.Error(), "response headers timed out: ") {
+			t.Errorf("err = %q, want it to name the phase", err)
+		}
+	})
+	t.Run("overall timeout", func(t *testing.T) {
+		withTimeouts(t, Timeouts{Connect: time.Second, TLSHandshake: time.Second})
+		err := get(t, client(200*time.Millisecond), srv.URL+"/headers")
+		if PhaseOf(err) != PhaseResponseHeader {
+			t.Fatalf("err = %v, want a %s timeout", err, PhaseResponseHeader)
+		}
+	})
+	t.Run("body", func(t *testing.T) {
+		withTimeouts(t, Timeouts{Connect: time.Second, TLSHandshake: time.Second})
+		err := get(t, client(200*time.Millisecond), srv.URL+"/body")
+		if PhaseOf(err) != PhaseResponseBody {
+			t.Fatalf("err = %v, want a %s timeout", err, PhaseResponseBody)
+		}
+	})
+}
+
+func TestOtherErrorsKept(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() // nolint:errcheck
+	err = get(t, client(time.Second), "https://"+addr+"/")
+	if err == nil || PhaseOf(err) != "" {
+		t.Errorf("refused connection: err = %v, want an error that is not a timeout", err)
+	}
+}
+
+func TestDialContextResolves(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close() // nolint:errcheck
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	conn, err := DialContext(t.Context(), "tcp", net.JoinHostPort("localhost", port))
+	if err != nil {
+		t.Fatalf("DialContext(localhost): %v", err)
+	}
+	conn.Close() // nolint:errcheck
+
+	_, err = DialContext(t.Context(), "tcp", "no-such-host.invalid:443")
+	var dnerr *net.DNSError
+	if !errors.As(err, &dnerr) {
+		t.Errorf("DialContext(no-such-host.invalid) = %v, want a *net.DNSError", err)
+	}
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package imageserver serves a single firmware image over HTTP so BMCs can
+// fetch it with SimpleUpdate, without a separate web server.
+package imageserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+)
+
+// Server is an ephemeral HTTP server for one file.
+type Server struct {
+	path string
+	name string
+	size int64
+	ln   net.Listener
+	srv  *http.Server
+
+	mu         sync.Mutex
+	downloaded map[string]bool // remote IPs that fetched the whole file
+	changed    chan struct{}   // closed and replaced on every completed download
+}
+
+// Start listens on addr (host:port; port 0 picks a free one) and serves the
+// file at path under /<file name>.
+func Start(addr, path string) (*Server, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !st.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	s := &Server{
+		path:       path,
+		name:       filepath.Base(path),
+		size:       st.Size(),
+		ln:         ln,
+		downloaded: map[string]bool{},
+		changed:    make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+url.PathEscape(s.name), s.serve)
+	s.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 30 * time.Second}
+	go s.srv.Serve(ln) // nolint:errcheck
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() *net.TCPAddr { return s.ln.Addr().(*net.TCPAddr) }
+
+// URL returns the image URL as seen by clients that reach this machine at
+// host.
+func (s *Server) URL(host string) string {
+	u := url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(host, fmt.Sprint(s.Addr().Port)),
+		Path:   "/" + s.name,
+	}
+	return u.String()
+}
+
+// Downloads returns how many distinct clients fetched the whole file.
+func (s *Server) Downloads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.downloaded)
+}
+
+// WaitForDownloads blocks until n distinct clients have fetched the whole
+// file or ctx is done. It returns ctx's error in the latter case.
+func (s *Server) WaitForDownloads(ctx context.Context, n int) error {
+	for {
+		s.mu.Lock()
+		done, changed := len(s.downloaded) >= n, s.changed
+		s.mu.Unlock()
+		if done {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close stops the server, letting in-flight downloads finish until ctx is
+// done.
+func (s *Server) Close(ctx context.Context) error {
+	err := s.srv.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return s.srv.Close()
+	}
+	return err
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		diag.Warnf("%s: serve %s: %v", ip, s.name, err)
+		http.Error(w, "image unavailable", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close() // nolint:errcheck
+
+	diag.Logf("%s: %s %s", ip, r.Method, r.URL.Path)
+	cw := &countingWriter{ResponseWriter: w}
+	http.ServeContent(cw, r, s.name, time.Time{}, f)
+	if r.Method != http.MethodGet || cw.n < s.size {
+		return
+	}
+	diag.Infof("%s downloaded %s (%d bytes)", ip, s.name, cw.n)
+	s.mu.Lock()
+	if !s.downloaded[ip] {
+		s.downloaded[ip] = true
+		close(s.changed)
+		s.changed = make(chan struct{})
+	}
+	s.mu.Unlock()
+}
+
+// countingWriter counts the body bytes written to a response.
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// LocalIPFor returns the address of the local interface used to reach host
+// (host or host:port). No packets are sent.
+func LocalIPFor(host string) (string, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+	conn, err := net.Dial("udp", host)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close() // nolint:errcheck
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
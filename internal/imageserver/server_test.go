@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package imageserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeAndWaitForDownloads(t *testing.T) {
+	image := bytes.Repeat([]byte("bios"), 4096)
+	path := filepath.Join(t.TempDir(), "bios.cap")
+	if err := os.WriteFile(path, image, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := Start("127.0.0.1:0", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close(context.Background()) // nolint:errcheck
+
+	u := srv.URL("127.0.0.1")
+	if !strings.HasSuffix(u, "/bios.cap") {
+		t.Fatalf("URL = %s", u)
+	}
+
+	// HEAD requests and partial reads are not downloads
+	if resp, err := http.Head(u); err != nil {
+		t.Fatal(err)
+	} else {
+		resp.Body.Close() // nolint:errcheck
+	}
+	if srv.Downloads() != 0 {
+		t.Fatalf("HEAD counted as a download")
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		waitErr <- srv.WaitForDownloads(ctx, 1)
+	}()
+
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close() // nolint:errcheck
+	if !bytes.Equal(got, image) {
+		t.Fatalf("got %d bytes, want %d", len(got), len(image))
+	}
+	if err := <-waitErr; err != nil {
+		t.Fatalf("WaitForDownloads: %v", err)
+	}
+
+	// A second fetch from the same client is not a second host
+	resp, err = http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close() // nolint:errcheck
+	if srv.Downloads() != 1 {
+		t.Errorf("Downloads = %d, want 1", srv.Downloads())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := srv.WaitForDownloads(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForDownloads(2) = %v, want deadline exceeded", err)
+	}
+
+	if resp, err := http.Get(srv.URL("127.0.0.1") + ".bak"); err == nil {
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("other paths should 404, got %s", resp.Status)
+		}
+		resp.Body.Close() // nolint:errcheck
+	}
+}
+
+func TestLocalIPFor(t *testing.T) {
+	ip, err := LocalIPFor("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip != "127.0.0.1" {
+		t.Errorf("LocalIPFor(127.0.0.1) = %s", ip)
+	}
+}
@@ -7,25 +7,92 @@ package initbmcs
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 
 	"bootstrap/internal/inventory"
 	"bootstrap/internal/netalloc"
+	"bootstrap/internal/xname"
 )
 
-func getBmcID(n int) int { return (n + 1) / 2 } //nolint:unused
-func getSlot(n int) int  { return ((n - 1) / 4) % 8 }
-func getBlade(n int) int { return ((n - 1) / 2) % 2 }
+// Geometry describes a mountain chassis: SlotsPerChassis blades of
+// NodesPerBlade nodes, with NodesPerBMC nodes behind each BMC. NodesPerChassis
+// may be less than a full chassis when it is partly populated.
+type Geometry struct {
+	NodesPerChassis int
+	NodesPerBMC     int
+	NodesPerBlade   int
+	SlotsPerChassis int
+}
+
+// DefaultGeometry is a fully populated EX4000 chassis: 8 slots of 4-node
+// blades with 2 nodes per BMC.
+var DefaultGeometry = Geometry{NodesPerChassis: 32, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 8}
 
-func getNCXname(chassis string, n int) string {
-	return fmt.Sprintf("%ss%db%d", chassis, getSlot(n), getBlade(n))
+// Validate checks that the geometry values fit together.
+func (g Geometry) Validate() error {
+	if g.NodesPerChassis < 1 || g.NodesPerBMC < 1 || g.NodesPerBlade < 1 || g.SlotsPerChassis < 1 {
+		return fmt.Errorf("nodes-per-chassis, nodes-per-bmc, nodes-per-blade, and slots-per-chassis must all be positive")
+	}
+	if g.NodesPerBlade%g.NodesPerBMC != 0 {
+		return fmt.Errorf("nodes-per-blade (%d) must be a multiple of nodes-per-bmc (%d)", g.NodesPerBlade, g.NodesPerBMC)
+	}
+	if g.NodesPerChassis%g.NodesPerBMC != 0 {
+		return fmt.Errorf("nodes-per-chassis (%d) must be a multiple of nodes-per-bmc (%d)", g.NodesPerChassis, g.NodesPerBMC)
+	}
+	if full := g.SlotsPerChassis * g.NodesPerBlade; g.NodesPerChassis > full {
+		return fmt.Errorf("nodes-per-chassis (%d) exceeds slots-per-chassis (%d) x nodes-per-blade (%d) = %d",
+			g.NodesPerChassis, g.SlotsPerChassis, g.NodesPerBlade, full)
+	}
+	// getNCMAC writes the slot and BMC index as single decimal digits
+	if g.SlotsPerChassis > 10 || g.NodesPerBlade/g.NodesPerBMC > 10 {
+		return fmt.Errorf("at most 10 slots per chassis and 10 BMCs per blade are supported")
+	}
+	return nil
 }
 
-func getNCMAC(macStart string, n int) string {
-	return fmt.Sprintf("%s:%d%d:%d0", macStart, 3, getSlot(n), getBlade(n))
+// Cabinet types accepted by init-bmcs. Mountain (liquid-cooled) chassis hold
+// blades with several nodes per BMC; river (air-cooled, x3000-style) racks
+// hold one 1U server and BMC per slot.
+const (
+	Mountain = "mountain"
+	River    = "river"
+)
+
+// Built-in MAC patterns after the chassis MAC prefix. A mountain node
+// controller is 3<slot>:<bmc>0, so slot 5 BMC 1 of 02:23:28:01 is
+// 02:23:28:01:35:10; a river BMC writes its slot in two decimal digits, so
+// slot 5 of 02:23:28:05 is 02:23:28:05:05:00.
+const (
+	mountainMACSuffix = ":3{slot}:{bmc}0"
+	riverMACSuffix    = ":{slot:02d}:00"
+)
+
+// ParseSlotRange parses a river slot range such as "1-36" or a single slot.
+func ParseSlotRange(spec string) (int, int, error) {
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(spec), "-")
+	first, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid slot range %q", spec)
+	}
+	last := first
+	if isRange {
+		if last, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+			return 0, 0, fmt.Errorf("invalid slot range %q", spec)
+		}
+	}
+	if first < 1 || last > 99 || first > last {
+		return 0, 0, fmt.Errorf("slot range %q must lie within 1-99 and start at or before its end", spec)
+	}
+	return first, last, nil
 }
 
-// ParseChassisSpec parses a chassis specification string into a map of chassis xnames to MAC prefixes.
+// ParseChassisSpec parses a chassis specification string into a map of
+// chassis xnames to MAC prefixes. A prefix may be followed by the chassis's
+// own BMC subnet, e.g. x9000c1=02:23:28:01/192.168.100 (see
+// SplitChassisValue).
 func ParseChassisSpec(spec string) map[string]string {
 	out := map[string]string{}
 	if strings.TrimSpace(spec) == "" {
@@ -47,35 +114,132 @@ func ParseChassisSpec(spec string) map[string]string {
 	return out
 }
 
-// Generate creates the BMC entries for an initial inventory.
+// SplitChassisValue splits a value of ParseChassisSpec into the MAC prefix
+// and the chassis's BMC subnet as a CIDR, or "" when it has none. The
+// subnet is a CIDR or the first three octets of a /24.
+func SplitChassisValue(v string) (macPrefix, subnet string, err error) {
+	macPrefix, subnet, ok := strings.Cut(v, "/")
+	if !ok {
+		return macPrefix, "", nil
+	}
+	subnet, err = normalizeSubnet(subnet)
+	return macPrefix, subnet, err
+}
+
+// Generate creates the BMC entries for an initial inventory of mountain
+// chassis, as the built-in template of one cabinet entry per chassis (see
+// MountainTemplate). chassis maps chassis xnames to MAC prefixes, each
+// optionally with its own subnet (see SplitChassisValue).
 // bmcSubnet should be in CIDR notation, e.g. "192.168.100.0/24"
-// startIP is an optional IP address to start allocation from (skips all IPs before it)
-func Generate(chassis map[string]string, nodesPerChassis, nodesPerBMC, startNID int, bmcSubnet, startIP string) ([]inventory.Entry, error) {
-	alloc, err := netalloc.NewAllocator(bmcSubnet)
+// startIP is an optional IP address, or number of the address within each
+// subnet, to start allocation from (skips all IPs before it)
+// Each BMC entry records the NID of the first node it manages.
+func Generate(chassis map[string]string, g Geometry, startNID int, bmcSubnet, startIP string) ([]inventory.Entry, error) {
+	t, err := MountainTemplate(chassis, g, startNID, bmcSubnet, startIP)
+	if err != nil {
+		return nil, err
+	}
+	return t.Generate()
+}
+
+// MountainTemplate returns the template init-bmcs uses for mountain
+// chassis: one cabinet entry per chassis, in xname order, with g's geometry
+// and the chassis's MAC prefix followed by mountainMACSuffix.
+func MountainTemplate(chassis map[string]string, g Geometry, startNID int, bmcSubnet, startIP string) (Template, error) {
+	if err := g.Validate(); err != nil {
+		return Template{}, err
+	}
+	return builtinTemplate(chassis, bmcSubnet, startIP, startNID, func(c CabinetTemplate, prefix string) CabinetTemplate {
+		c.Slots = fmt.Sprintf("0-%d", g.SlotsPerChassis-1)
+		c.NodesPerBlade, c.NodesPerBMC, c.NodesPerChassis = g.NodesPerBlade, g.NodesPerBMC, g.NodesPerChassis
+		c.MAC = prefix + mountainMACSuffix
+		return c
+	})
+}
+
+// GenerateRiver creates the BMC entries for river cabinets: one BMC, and one
+// node, in each slot from firstSlot to lastSlot, with xnames such as
+// x3000c0s5b0. NIDs increase by one per slot.
+func GenerateRiver(chassis map[string]string, firstSlot, lastSlot, startNID int, bmcSubnet, startIP string) ([]inventory.Entry, error) {
+	t, err := RiverTemplate(chassis, firstSlot, lastSlot, startNID, bmcSubnet, startIP)
 	if err != nil {
-		return nil, fmt.Errorf("bmc subnet init: %w", err)
+		return nil, err
 	}
+	return t.Generate()
+}
 
-	// Reserve all IPs before the start IP if specified
-	if startIP != "" {
-		if err := alloc.ReserveUpTo(startIP); err != nil {
-			return nil, fmt.Errorf("reserve up to start IP: %w", err)
+// RiverTemplate returns the template init-bmcs uses for river cabinets: one
+// cabinet entry per chassis, in xname order, with one BMC and node per slot
+// and the chassis's MAC prefix followed by riverMACSuffix.
+func RiverTemplate(chassis map[string]string, firstSlot, lastSlot, startNID int, bmcSubnet, startIP string) (Template, error) {
+	return builtinTemplate(chassis, bmcSubnet, startIP, startNID, func(c CabinetTemplate, prefix string) CabinetTemplate {
+		c.Slots = fmt.Sprintf("%d-%d", firstSlot, lastSlot)
+		c.NodesPerBlade, c.NodesPerBMC = 1, 1
+		c.MAC = prefix + riverMACSuffix
+		return c
+	})
+}
+
+// builtinTemplate returns a template with one cabinet entry per chassis of
+// the --chassis flag, in xname order, completed by cabinet.
+func builtinTemplate(chassis map[string]string, bmcSubnet, startIP string, startNID int, cabinet func(CabinetTemplate, string) CabinetTemplate) (Template, error) {
+	t := Template{Subnet: bmcSubnet, StartIP: startIP, StartNID: startNID}
+	for _, c := range slices.SortedFunc(maps.Keys(chassis), xname.Compare) {
+		x, err := xname.Parse(c)
+		if err != nil || x.Kind != xname.KindChassis {
+			return Template{}, fmt.Errorf("%q is not a chassis xname like x9000c1", c)
+		}
+		prefix, subnet, err := SplitChassisValue(chassis[c])
+		if err != nil {
+			return Template{}, fmt.Errorf("%s: %w", c, err)
 		}
+		t.Cabinets = append(t.Cabinets, cabinet(CabinetTemplate{Cabinet: x.Cabinet, Chassis: strconv.Itoa(x.Chassis), Subnet: subnet}, prefix))
 	}
+	return t, nil
+}
+
+// getCCMAC gives a chassis controller port 00:00 of its chassis's prefix,
+// which no node controller MAC (3<slot>:<blade>0) uses.
+func getCCMAC(macStart string) string {
+	return macStart + ":00:00"
+}
 
-	var bmcs []inventory.Entry
-	nid := startNID
-	for c, macPref := range chassis {
-		for i := nid; i < nid+nodesPerChassis; i += nodesPerBMC {
-			x := getNCXname(c, i)
-			ip, err := alloc.Next()
-			if err != nil {
-				return nil, fmt.Errorf("allocate IP for %s: %w", x, err)
-			}
-			mac := strings.ToLower(getNCMAC(macPref, i))
-			bmcs = append(bmcs, inventory.Entry{Xname: x, MAC: mac, IP: ip})
+// ChassisControllers creates one chassis controller (cC) entry per chassis,
+// e.g. x9000c1b0, in chassis order. Their IPs follow in the chassis's own
+// subnet or else bmcSubnet, skipping the addresses before startIP and those
+// of bmcs. A controller already in bmcs keeps its address.
+func ChassisControllers(chassis map[string]string, bmcs []inventory.Entry, bmcSubnet, startIP string) ([]inventory.Entry, error) {
+	plan := subnetPlan{start: startIP, taken: bmcs, allocs: map[string]*netalloc.Allocator{}}
+	kept := map[string]string{}
+	for _, b := range bmcs {
+		if b.IP != "" {
+			kept[b.Xname] = b.IP
+		}
+	}
+	var out []inventory.Entry
+	for _, c := range slices.SortedFunc(maps.Keys(chassis), xname.Compare) {
+		x := xname.ChassisBMC(c)
+		prefix, subnet, err := SplitChassisValue(chassis[c])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c, err)
+		}
+		if ip := kept[x]; ip != "" {
+			out = append(out, inventory.Entry{Xname: x, MAC: strings.ToLower(getCCMAC(prefix)), IP: ip})
+			continue
+		}
+		strict := subnet == ""
+		if strict {
+			subnet = bmcSubnet
+		}
+		alloc, err := plan.allocator(subnet, strict)
+		if err != nil {
+			return nil, fmt.Errorf("bmc subnet init: %w", err)
+		}
+		ip, err := alloc.Next()
+		if err != nil {
+			return nil, fmt.Errorf("allocate IP for %s: %w", x, err)
 		}
-		nid = nid + nodesPerChassis
+		out = append(out, inventory.Entry{Xname: x, MAC: strings.ToLower(getCCMAC(prefix)), IP: ip})
 	}
-	return bmcs, nil
+	return out, nil
 }
@@ -5,7 +5,9 @@
 package initbmcs
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"bootstrap/internal/inventory"
@@ -20,18 +22,31 @@ func TestParseChassisSpec(t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("ParseChassisSpec mismatch: got=%v want=%v", got, want)
 	}
+	for v, want := range map[string][2]string{
+		"02:23:28:01":                    {"02:23:28:01", ""},
+		"02:23:28:01/192.168.101":        {"02:23:28:01", "192.168.101.0/24"},
+		"02:23:28:01/192.168.100.128/25": {"02:23:28:01", "192.168.100.128/25"},
+	} {
+		prefix, subnet, err := SplitChassisValue(v)
+		if err != nil || prefix != want[0] || subnet != want[1] {
+			t.Errorf("SplitChassisValue(%q) = %q, %q, %v; want %q, %q", v, prefix, subnet, err, want[0], want[1])
+		}
+	}
+	if _, _, err := SplitChassisValue("02:23:28:01/192.168.300"); err == nil || !strings.Contains(err.Error(), "octet 300 exceeds 254") {
+		t.Errorf("octet over 254: err = %v", err)
+	}
 }
 
 func TestGenerateSingleChassisDeterministic(t *testing.T) {
 	chassis := map[string]string{"x9000c1": "02:23:28:01"}
-	bmcs, err := Generate(chassis, 4, 2, 1, "192.168.100.0/24", "")
+	bmcs, err := Generate(chassis, Geometry{NodesPerChassis: 4, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 8}, 1, "192.168.100.0/24", "")
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
 	}
 
 	want := []inventory.Entry{
-		{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "192.168.100.1"},
-		{Xname: "x9000c1s0b1", MAC: "02:23:28:01:30:10", IP: "192.168.100.2"},
+		{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "192.168.100.1", NID: 1},
+		{Xname: "x9000c1s0b1", MAC: "02:23:28:01:30:10", IP: "192.168.100.2", NID: 3},
 	}
 	if !reflect.DeepEqual(bmcs, want) {
 		t.Fatalf("Generate result mismatch:\n got: %#v\nwant: %#v", bmcs, want)
@@ -40,16 +55,243 @@ func TestGenerateSingleChassisDeterministic(t *testing.T) {
 
 func TestGenerateWithStartIP(t *testing.T) {
 	chassis := map[string]string{"x9000c1": "02:23:28:01"}
-	bmcs, err := Generate(chassis, 4, 2, 1, "192.168.100.0/24", "192.168.100.10")
+	bmcs, err := Generate(chassis, Geometry{NodesPerChassis: 4, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 8}, 1, "192.168.100.0/24", "192.168.100.10")
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
 	}
 
 	want := []inventory.Entry{
-		{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "192.168.100.10"},
-		{Xname: "x9000c1s0b1", MAC: "02:23:28:01:30:10", IP: "192.168.100.11"},
+		{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "192.168.100.10", NID: 1},
+		{Xname: "x9000c1s0b1", MAC: "02:23:28:01:30:10", IP: "192.168.100.11", NID: 3},
 	}
 	if !reflect.DeepEqual(bmcs, want) {
 		t.Fatalf("Generate result mismatch:\n got: %#v\nwant: %#v", bmcs, want)
 	}
 }
+
+// xnamesAndMACs flattens entries for comparison against known-good lists.
+func xnamesAndMACs(bmcs []inventory.Entry) []string {
+	out := make([]string, 0, len(bmcs))
+	for _, b := range bmcs {
+		out = append(out, b.Xname+" "+b.MAC)
+	}
+	return out
+}
+
+func TestGenerateMountainChassisKnownGood(t *testing.T) {
+	bmcs, err := Generate(map[string]string{"x9000c1": "02:23:28:01"}, DefaultGeometry, 1, "192.168.100.0/24", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"x9000c1s0b0 02:23:28:01:30:00", "x9000c1s0b1 02:23:28:01:30:10",
+		"x9000c1s1b0 02:23:28:01:31:00", "x9000c1s1b1 02:23:28:01:31:10",
+		"x9000c1s2b0 02:23:28:01:32:00", "x9000c1s2b1 02:23:28:01:32:10",
+		"x9000c1s3b0 02:23:28:01:33:00", "x9000c1s3b1 02:23:28:01:33:10",
+		"x9000c1s4b0 02:23:28:01:34:00", "x9000c1s4b1 02:23:28:01:34:10",
+		"x9000c1s5b0 02:23:28:01:35:00", "x9000c1s5b1 02:23:28:01:35:10",
+		"x9000c1s6b0 02:23:28:01:36:00", "x9000c1s6b1 02:23:28:01:36:10",
+		"x9000c1s7b0 02:23:28:01:37:00", "x9000c1s7b1 02:23:28:01:37:10",
+	}
+	if got := xnamesAndMACs(bmcs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("mountain xnames:\n got: %v\nwant: %v", got, want)
+	}
+	if bmcs[15].NID != 31 || bmcs[15].IP != "192.168.100.16" {
+		t.Errorf("last BMC = %+v, want NID 31 at 192.168.100.16", bmcs[15])
+	}
+}
+
+func TestGenerateRiverKnownGood(t *testing.T) {
+	bmcs, err := GenerateRiver(map[string]string{"x3000c0": "02:23:28:05"}, 1, 12, 100, "10.254.1.0/24", "10.254.1.20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"x3000c0s1b0 02:23:28:05:01:00", "x3000c0s2b0 02:23:28:05:02:00",
+		"x3000c0s3b0 02:23:28:05:03:00", "x3000c0s4b0 02:23:28:05:04:00",
+		"x3000c0s5b0 02:23:28:05:05:00", "x3000c0s6b0 02:23:28:05:06:00",
+		"x3000c0s7b0 02:23:28:05:07:00", "x3000c0s8b0 02:23:28:05:08:00",
+		"x3000c0s9b0 02:23:28:05:09:00", "x3000c0s10b0 02:23:28:05:10:00",
+		"x3000c0s11b0 02:23:28:05:11:00", "x3000c0s12b0 02:23:28:05:12:00",
+	}
+	if got := xnamesAndMACs(bmcs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("river xnames:\n got: %v\nwant: %v", got, want)
+	}
+	first, last := bmcs[0], bmcs[len(bmcs)-1]
+	if first.NID != 100 || first.IP != "10.254.1.20" || last.NID != 111 || last.IP != "10.254.1.31" {
+		t.Errorf("first=%+v last=%+v", first, last)
+	}
+}
+
+func TestParseSlotRange(t *testing.T) {
+	for spec, want := range map[string][2]int{"1-36": {1, 36}, " 5 - 7 ": {5, 7}, "17": {17, 17}} {
+		first, last, err := ParseSlotRange(spec)
+		if err != nil || first != want[0] || last != want[1] {
+			t.Errorf("ParseSlotRange(%q) = %d, %d, %v", spec, first, last, err)
+		}
+	}
+	for _, spec := range []string{"", "0-4", "9-3", "1-100", "a-b", "1-"} {
+		if _, _, err := ParseSlotRange(spec); err == nil {
+			t.Errorf("ParseSlotRange(%q) should fail", spec)
+		}
+	}
+}
+
+func TestGenerateEX2500Geometry(t *testing.T) {
+	g := Geometry{NodesPerChassis: 28, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 7}
+	bmcs, err := Generate(map[string]string{"x8000c0": "02:23:28:08"}, g, 1, "192.168.100.0/24", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"x8000c0s0b0 02:23:28:08:30:00", "x8000c0s0b1 02:23:28:08:30:10",
+		"x8000c0s1b0 02:23:28:08:31:00", "x8000c0s1b1 02:23:28:08:31:10",
+		"x8000c0s2b0 02:23:28:08:32:00", "x8000c0s2b1 02:23:28:08:32:10",
+		"x8000c0s3b0 02:23:28:08:33:00", "x8000c0s3b1 02:23:28:08:33:10",
+		"x8000c0s4b0 02:23:28:08:34:00", "x8000c0s4b1 02:23:28:08:34:10",
+		"x8000c0s5b0 02:23:28:08:35:00", "x8000c0s5b1 02:23:28:08:35:10",
+		"x8000c0s6b0 02:23:28:08:36:00", "x8000c0s6b1 02:23:28:08:36:10",
+	}
+	if got := xnamesAndMACs(bmcs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("EX2500 xnames:\n got: %v\nwant: %v", got, want)
+	}
+
+	// Four BMCs per 8-node blade
+	g = Geometry{NodesPerChassis: 16, NodesPerBMC: 2, NodesPerBlade: 8, SlotsPerChassis: 2}
+	bmcs, err = Generate(map[string]string{"x9000c1": "02:23:28:01"}, g, 1, "192.168.100.0/24", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"x9000c1s0b0 02:23:28:01:30:00", "x9000c1s0b1 02:23:28:01:30:10",
+		"x9000c1s0b2 02:23:28:01:30:20", "x9000c1s0b3 02:23:28:01:30:30",
+		"x9000c1s1b0 02:23:28:01:31:00", "x9000c1s1b1 02:23:28:01:31:10",
+		"x9000c1s1b2 02:23:28:01:31:20", "x9000c1s1b3 02:23:28:01:31:30",
+	}
+	if got := xnamesAndMACs(bmcs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("8-node blade xnames:\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestGenerateNodesPerBMC(t *testing.T) {
+	// Two 4-node blades; hand-computed BMC xnames, MACs (3<slot>:<bmc>0),
+	// IPs, and the NID of each BMC's first node
+	cases := []struct {
+		perBMC int
+		want   []string
+	}{
+		{1, []string{
+			"x9000c1s0b0 02:23:28:01:30:00 10.1.0.1 1", "x9000c1s0b1 02:23:28:01:30:10 10.1.0.2 2",
+			"x9000c1s0b2 02:23:28:01:30:20 10.1.0.3 3", "x9000c1s0b3 02:23:28:01:30:30 10.1.0.4 4",
+			"x9000c1s1b0 02:23:28:01:31:00 10.1.0.5 5", "x9000c1s1b1 02:23:28:01:31:10 10.1.0.6 6",
+			"x9000c1s1b2 02:23:28:01:31:20 10.1.0.7 7", "x9000c1s1b3 02:23:28:01:31:30 10.1.0.8 8",
+		}},
+		{2, []string{
+			"x9000c1s0b0 02:23:28:01:30:00 10.1.0.1 1", "x9000c1s0b1 02:23:28:01:30:10 10.1.0.2 3",
+			"x9000c1s1b0 02:23:28:01:31:00 10.1.0.3 5", "x9000c1s1b1 02:23:28:01:31:10 10.1.0.4 7",
+		}},
+		{4, []string{
+			"x9000c1s0b0 02:23:28:01:30:00 10.1.0.1 1",
+			"x9000c1s1b0 02:23:28:01:31:00 10.1.0.2 5",
+		}},
+	}
+	for _, c := range cases {
+		g := Geometry{NodesPerChassis: 8, NodesPerBMC: c.perBMC, NodesPerBlade: 4, SlotsPerChassis: 2}
+		bmcs, err := Generate(map[string]string{"x9000c1": "02:23:28:01"}, g, 1, "10.1.0.0/24", "")
+		if err != nil {
+			t.Fatalf("nodes-per-bmc %d: %v", c.perBMC, err)
+		}
+		var got []string
+		for _, b := range bmcs {
+			got = append(got, fmt.Sprintf("%s %s %s %d", b.Xname, b.MAC, b.IP, b.NID))
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("nodes-per-bmc %d:\n got: %v\nwant: %v", c.perBMC, got, c.want)
+		}
+	}
+
+	// A partly populated chassis stops after its last whole BMC
+	g := Geometry{NodesPerChassis: 4, NodesPerBMC: 4, NodesPerBlade: 4, SlotsPerChassis: 2}
+	bmcs, err := Generate(map[string]string{"x9000c1": "02:23:28:01"}, g, 1, "10.1.0.0/24", "")
+	if err != nil || len(bmcs) != 1 || bmcs[0].Xname != "x9000c1s0b0" {
+		t.Errorf("4 nodes, 4 per BMC: %v, %v", bmcs, err)
+	}
+	g.NodesPerChassis = 6
+	if err := g.Validate(); err == nil || !strings.Contains(err.Error(), "nodes-per-chassis (6) must be a multiple of nodes-per-bmc (4)") {
+		t.Errorf("6 nodes, 4 per BMC: err = %v", err)
+	}
+}
+
+func TestGeometryValidate(t *testing.T) {
+	if err := DefaultGeometry.Validate(); err != nil {
+		t.Fatalf("default geometry: %v", err)
+	}
+	bad := map[string]Geometry{
+		"too many nodes":   {NodesPerChassis: 32, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 7},
+		"blade not by bmc": {NodesPerChassis: 24, NodesPerBMC: 2, NodesPerBlade: 3, SlotsPerChassis: 8},
+		"odd chassis":      {NodesPerChassis: 5, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 8},
+		"zero":             {NodesPerChassis: 32, NodesPerBMC: 0, NodesPerBlade: 4, SlotsPerChassis: 8},
+		"too many slots":   {NodesPerChassis: 32, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 12},
+	}
+	for name, g := range bad {
+		if err := g.Validate(); err == nil {
+			t.Errorf("%s: expected an error for %+v", name, g)
+		}
+	}
+	if _, err := Generate(map[string]string{"x9000c1": "02:23:28:01"}, bad["too many nodes"], 1, "192.168.100.0/24", ""); err == nil {
+		t.Error("Generate accepted an inconsistent geometry")
+	}
+}
+
+func TestChassisControllers(t *testing.T) {
+	chassis := map[string]string{"x9000c3": "02:23:28:03", "x9000c1": "02:23:28:01"}
+	bmcs, err := Generate(map[string]string{"x9000c1": "02:23:28:01"}, DefaultGeometry, 1, "192.168.100.0/24", "192.168.100.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ccs, err := ChassisControllers(chassis, bmcs, "192.168.100.0/24", "192.168.100.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := xnamesAndMACs(ccs), []string{"x9000c1b0 02:23:28:01:00:00", "x9000c3b0 02:23:28:03:00:00"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("controllers:\n got: %v\nwant: %v", got, want)
+	}
+	// The 16 BMCs hold .10 to .25
+	if ccs[0].IP != "192.168.100.26" || ccs[1].IP != "192.168.100.27" {
+		t.Errorf("controller IPs = %s, %s", ccs[0].IP, ccs[1].IP)
+	}
+}
+
+func TestGeneratePerChassisSubnets(t *testing.T) {
+	chassis := map[string]string{"x9000c1": "02:23:28:01", "x9000c3": "02:23:28:03/192.168.101"}
+	g := Geometry{NodesPerChassis: 4, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 8}
+	bmcs, err := Generate(chassis, g, 1, "192.168.100.0/24", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ips []string
+	for _, b := range bmcs {
+		ips = append(ips, b.IP)
+	}
+	// A numeric start IP applies within each subnet
+	if want := []string{"192.168.100.10", "192.168.100.11", "192.168.101.10", "192.168.101.11"}; !reflect.DeepEqual(ips, want) {
+		t.Fatalf("IPs = %v, want %v", ips, want)
+	}
+	ccs, err := ChassisControllers(chassis, bmcs, "192.168.100.0/24", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ccs[0].IP != "192.168.100.12" || ccs[1].IP != "192.168.101.12" {
+		t.Errorf("controller IPs = %s, %s", ccs[0].IP, ccs[1].IP)
+	}
+}
+
+func TestGenerateRejectsOverlappingSubnets(t *testing.T) {
+	// x9000c3's /28 lies inside the shared subnet, where x9000c1 already
+	// has .10
+	chassis := map[string]string{"x9000c1": "02:23:28:01", "x9000c3": "02:23:28:03/192.168.100.0/28"}
+	_, err := Generate(chassis, DefaultGeometry, 1, "192.168.100.0/24", "192.168.100.10")
+	if err == nil || !strings.Contains(err.Error(), "IP 192.168.100.10 of x9000c3s0b0 is also used by x9000c1s0b0") {
+		t.Fatalf("err = %v", err)
+	}
+}
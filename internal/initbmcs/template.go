@@ -0,0 +1,469 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package initbmcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/netalloc"
+	"bootstrap/internal/xname"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template describes the BMCs of one or more racks. Generate expands it into
+// bmcs[] entries, cabinet by cabinet in file order.
+//
+//	subnet: 192.168.100.0/24
+//	start_ip: 192.168.100.10
+//	start_nid: 1
+//	cabinets:
+//	  - cabinet: 9000
+//	    chassis: 1,3
+//	    slots: 0-4,6-7
+//	    mac: "02:23:28:{chassis_hex}:3{slot}:{bmc}0"
+type Template struct {
+	// Subnet is where BMCs without an ip pattern or subnet of their own
+	// get their addresses, in order, starting at StartIP when it is set.
+	// StartIP is an address, or a number n for the nth address of every
+	// subnet.
+	Subnet   string            `yaml:"subnet"`
+	StartIP  string            `yaml:"start_ip"`
+	StartNID int               `yaml:"start_nid"`
+	Cabinets []CabinetTemplate `yaml:"cabinets"`
+
+	// Existing holds the BMCs already in the inventory. Their addresses are
+	// not handed out, and a generated BMC given one of them by an ip
+	// pattern is an error, unless it has the same xname. A generated BMC
+	// without an ip pattern keeps the address of the existing BMC with its
+	// xname.
+	Existing []inventory.Entry `yaml:"-"`
+}
+
+// CabinetTemplate describes the chassis of one cabinet that share a
+// geometry. Chassis and Slots are lists of numbers and ranges such as
+// "0-4,6-7". Each populated slot holds a blade of NodesPerBlade nodes with
+// NodesPerBMC nodes behind each BMC; NodesPerChassis, when set, stops a
+// chassis after that many nodes. MAC and IP are patterns with {cabinet},
+// {chassis}, {chassis_hex}, {slot}, {bmc}, and {nid} placeholders, each
+// optionally with a printf verb, e.g. {slot:02d}. Without IP, addresses come
+// from Subnet, or from the template subnet when it is empty. Subnets are
+// CIDRs or the first three octets of a /24, such as 192.168.100.
+type CabinetTemplate struct {
+	Cabinet         int    `yaml:"cabinet"`
+	Chassis         string `yaml:"chassis"`
+	Slots           string `yaml:"slots"`
+	NodesPerBlade   int    `yaml:"nodes_per_blade"`
+	NodesPerBMC     int    `yaml:"nodes_per_bmc"`
+	NodesPerChassis int    `yaml:"nodes_per_chassis"`
+	MAC             string `yaml:"mac"`
+	IP              string `yaml:"ip"`
+	Subnet          string `yaml:"subnet"`
+
+	// lines maps each field to its line in the template file, for errors
+	lines map[string]int
+}
+
+// Template defaults for fields a cabinet leaves out: an EX4000 chassis.
+const (
+	defaultTemplateSlots = "0-7"
+	defaultNodesPerBlade = 4
+	defaultNodesPerBMC   = 2
+)
+
+var cabinetFields = []string{"cabinet", "chassis", "slots", "nodes_per_blade", "nodes_per_bmc", "nodes_per_chassis", "mac", "ip", "subnet"}
+
+// UnmarshalYAML decodes a cabinet, rejects unknown fields, and records the
+// line of each field.
+func (c *CabinetTemplate) UnmarshalYAML(n *yaml.Node) error {
+	type plain CabinetTemplate
+	if err := n.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	c.lines = map[string]int{"": n.Line}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if !slices.Contains(cabinetFields, key.Value) {
+			return fmt.Errorf("line %d: unknown cabinet field %q (valid: %s)", key.Line, key.Value, strings.Join(cabinetFields, ", "))
+		}
+		c.lines[key.Value] = key.Line
+	}
+	return nil
+}
+
+// ParseTemplate reads a YAML template. Unknown fields are errors.
+func ParseTemplate(raw []byte) (Template, error) {
+	var t Template
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t); err != nil {
+		return Template{}, fmt.Errorf("parse template: %w", err)
+	}
+	return t, nil
+}
+
+// fieldError names the field of cabinet i, and its line when the cabinet
+// came from a file.
+func (c CabinetTemplate) fieldError(i int, field, format string, args ...any) error {
+	msg := fmt.Sprintf("cabinets[%d].%s: %s", i, field, fmt.Sprintf(format, args...))
+	line := c.lines[field]
+	if line == 0 {
+		line = c.lines[""]
+	}
+	if line > 0 {
+		return fmt.Errorf("line %d: %s", line, msg)
+	}
+	return errors.New(msg)
+}
+
+// templateBMC is one BMC of an expanded template before it gets an IP.
+type templateBMC struct {
+	entry   inventory.Entry
+	cabinet int // index into Template.Cabinets
+}
+
+// Generate expands the template into BMC entries: for each cabinet, its
+// chassis in the order listed, each chassis's slots in increasing order,
+// and the BMCs of each blade. NIDs count up from StartNID over the nodes
+// generated. A pattern that expands to an invalid MAC or IP, two BMCs with
+// the same xname, MAC, or IP, or a BMC with the IP of one in Existing, is an
+// error naming the cabinet's field and both BMCs.
+func (t Template) Generate() ([]inventory.Entry, error) {
+	if len(t.Cabinets) == 0 {
+		return nil, errors.New("template lists no cabinets")
+	}
+	nid := t.StartNID
+	if nid == 0 {
+		nid = 1
+	}
+	subnet := t.Subnet
+	if subnet != "" {
+		var err error
+		if subnet, err = normalizeSubnet(subnet); err != nil {
+			return nil, fmt.Errorf("subnet: %w", err)
+		}
+	}
+	var out []templateBMC
+	subnets := make([]string, len(t.Cabinets)) // where each cabinet's BMCs get addresses
+	for i, c := range t.Cabinets {
+		bmcs, next, err := c.expand(i, nid)
+		if err != nil {
+			return nil, err
+		}
+		nid = next
+		switch {
+		case c.IP != "":
+		case c.Subnet != "":
+			if subnets[i], err = normalizeSubnet(c.Subnet); err != nil {
+				return nil, c.fieldError(i, "subnet", "%v", err)
+			}
+		case subnet == "":
+			return nil, errors.New("subnet: required when a cabinet has no ip pattern or subnet")
+		default:
+			subnets[i] = subnet
+		}
+		for _, b := range bmcs {
+			out = append(out, templateBMC{entry: b, cabinet: i})
+		}
+	}
+
+	// Addresses from ip patterns and of existing BMCs are never handed out
+	plan := subnetPlan{start: t.StartIP, taken: slices.Clone(t.Existing), allocs: map[string]*netalloc.Allocator{}}
+	for _, b := range out {
+		if b.entry.IP != "" {
+			plan.taken = append(plan.taken, b.entry)
+		}
+	}
+	existing := map[string]string{} // IP -> xname of the existing BMC
+	kept := map[string]string{}     // xname -> IP of the existing BMC
+	for _, e := range t.Existing {
+		if e.IP != "" {
+			existing[e.IP] = e.Xname
+			kept[e.Xname] = e.IP
+		}
+	}
+
+	seen := map[string]string{} // xname, MAC, or IP -> xname of its BMC
+	entries := make([]inventory.Entry, 0, len(out))
+	for _, b := range out {
+		c := t.Cabinets[b.cabinet]
+		if b.entry.IP == "" && kept[b.entry.Xname] != "" {
+			b.entry.IP = kept[b.entry.Xname]
+		} else if b.entry.IP == "" {
+			alloc, err := plan.allocator(subnets[b.cabinet], subnets[b.cabinet] == subnet)
+			if err != nil {
+				if subnets[b.cabinet] == subnet {
+					return nil, fmt.Errorf("subnet: %w", err)
+				}
+				return nil, c.fieldError(b.cabinet, "subnet", "%v", err)
+			}
+			ip, err := alloc.Next()
+			if err != nil {
+				return nil, fmt.Errorf("allocate IP for %s: %w", b.entry.Xname, err)
+			}
+			b.entry.IP = ip
+		}
+		if _, dup := seen["xname "+b.entry.Xname]; dup {
+			return nil, c.fieldError(b.cabinet, "chassis", "%s is generated more than once", b.entry.Xname)
+		}
+		seen["xname "+b.entry.Xname] = b.entry.Xname
+		for _, k := range []struct{ field, name, value string }{{"mac", "MAC", b.entry.MAC}, {"ip", "IP", b.entry.IP}} {
+			if prev, dup := seen[k.field+" "+k.value]; dup {
+				field := k.field
+				if field == "ip" && c.IP == "" {
+					// The address was allocated from the cabinet's subnet
+					field = "subnet"
+				}
+				return nil, c.fieldError(b.cabinet, field, "%s %s of %s is also used by %s", k.name, k.value, b.entry.Xname, prev)
+			}
+			seen[k.field+" "+k.value] = b.entry.Xname
+		}
+		if prev, dup := existing[b.entry.IP]; dup && prev != b.entry.Xname {
+			return nil, c.fieldError(b.cabinet, "ip", "IP %s of %s is already used by %s in bmcs[]", b.entry.IP, b.entry.Xname, prev)
+		}
+		entries = append(entries, b.entry)
+	}
+	return entries, nil
+}
+
+// subnetPlan hands out BMC addresses with one allocator per subnet, each
+// skipping the addresses before the start IP and those of taken.
+type subnetPlan struct {
+	start  string
+	taken  []inventory.Entry
+	allocs map[string]*netalloc.Allocator
+}
+
+// allocator returns the allocator of subnet, creating it on first use. A
+// numeric start applies to every subnet, and a start address to the subnet
+// containing it; with strict, a start address outside subnet is an error.
+func (p *subnetPlan) allocator(subnet string, strict bool) (*netalloc.Allocator, error) {
+	if a := p.allocs[subnet]; a != nil {
+		return a, nil
+	}
+	a, err := netalloc.NewAllocator(subnet)
+	if err != nil {
+		return nil, err
+	}
+	start := p.start
+	if n, err := strconv.Atoi(start); err == nil {
+		if start, err = a.Offset(n); err != nil {
+			return nil, fmt.Errorf("start IP: %w", err)
+		}
+	}
+	if start != "" && (strict || a.Contains(start)) {
+		if err := a.ReserveUpTo(start); err != nil {
+			return nil, fmt.Errorf("reserve up to start IP: %w", err)
+		}
+	}
+	for _, e := range p.taken {
+		if a.Contains(e.IP) {
+			if err := a.Reserve(e.IP); err != nil {
+				return nil, fmt.Errorf("reserve IP of %s: %w", e.Xname, err)
+			}
+		}
+	}
+	p.allocs[subnet] = a
+	return a, nil
+}
+
+// normalizeSubnet returns s as a CIDR: s itself, or s + ".0/24" when it is
+// the first three octets of a /24, such as 192.168.100.
+func normalizeSubnet(s string) (string, error) {
+	if strings.Contains(s, "/") {
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return "", fmt.Errorf("invalid subnet %q", s)
+		}
+		return s, nil
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("subnet %q is neither a CIDR nor three octets such as 192.168.100", s)
+	}
+	for _, part := range parts {
+		if n, err := strconv.Atoi(part); err != nil || n < 0 {
+			return "", fmt.Errorf("invalid subnet %q", s)
+		}
+	}
+	if n := highOctet(s); n >= 0 {
+		return "", fmt.Errorf("subnet %q: octet %d exceeds 254", s, n)
+	}
+	return s + ".0/24", nil
+}
+
+// highOctet returns the first octet of the dotted address or prefix s that
+// exceeds 254, or -1 when there is none.
+func highOctet(s string) int {
+	for _, part := range strings.Split(s, ".") {
+		if n, err := strconv.Atoi(part); err == nil && n > 254 {
+			return n
+		}
+	}
+	return -1
+}
+
+// expand returns the BMCs of cabinet i (without allocated IPs), numbering
+// nodes from nid, and the next free NID.
+func (c CabinetTemplate) expand(i, nid int) ([]inventory.Entry, int, error) {
+	perBlade, perBMC := c.NodesPerBlade, c.NodesPerBMC
+	if perBlade == 0 {
+		perBlade = defaultNodesPerBlade
+	}
+	if perBMC == 0 {
+		perBMC = defaultNodesPerBMC
+	}
+	switch {
+	case c.Cabinet < 0:
+		return nil, 0, c.fieldError(i, "cabinet", "must not be negative")
+	case perBlade < 0:
+		return nil, 0, c.fieldError(i, "nodes_per_blade", "must be positive")
+	case perBMC < 0:
+		return nil, 0, c.fieldError(i, "nodes_per_bmc", "must be positive")
+	case perBlade%perBMC != 0:
+		return nil, 0, c.fieldError(i, "nodes_per_blade", "%d is not a multiple of nodes_per_bmc (%d)", perBlade, perBMC)
+	case c.NodesPerChassis < 0:
+		return nil, 0, c.fieldError(i, "nodes_per_chassis", "must not be negative")
+	case c.NodesPerChassis%perBMC != 0:
+		// A BMC manages all of its nodes or none, so the count would be
+		// rounded up to the next BMC
+		return nil, 0, c.fieldError(i, "nodes_per_chassis", "%d is not a multiple of nodes_per_bmc (%d)", c.NodesPerChassis, perBMC)
+	case c.MAC == "":
+		return nil, 0, c.fieldError(i, "mac", "a MAC pattern is required")
+	}
+	if c.Chassis == "" {
+		return nil, 0, c.fieldError(i, "chassis", "at least one chassis is required")
+	}
+	chassis, err := parseNumberList(c.Chassis)
+	if err != nil {
+		return nil, 0, c.fieldError(i, "chassis", "%v", err)
+	}
+	slotSpec := c.Slots
+	if slotSpec == "" {
+		slotSpec = defaultTemplateSlots
+	}
+	slots, err := parseNumberList(slotSpec)
+	if err != nil {
+		return nil, 0, c.fieldError(i, "slots", "%v", err)
+	}
+	for _, field := range []string{"mac", "ip"} {
+		if err := checkPattern(c.pattern(field)); err != nil {
+			return nil, 0, c.fieldError(i, field, "%v", err)
+		}
+	}
+
+	var out []inventory.Entry
+	for _, ch := range chassis {
+		chassisX := fmt.Sprintf("x%dc%d", c.Cabinet, ch)
+		if x, err := xname.Parse(chassisX); err != nil || x.Kind != xname.KindChassis {
+			return nil, 0, c.fieldError(i, "chassis", "%s is not a valid chassis xname", chassisX)
+		}
+		nodes := 0
+	chassisLoop:
+		for _, slot := range slots {
+			for bmc := 0; bmc < perBlade/perBMC; bmc++ {
+				if c.NodesPerChassis > 0 && nodes >= c.NodesPerChassis {
+					break chassisLoop
+				}
+				vals := map[string]int{"cabinet": c.Cabinet, "chassis": ch, "chassis_hex": ch, "slot": slot, "bmc": bmc, "nid": nid}
+				x := fmt.Sprintf("%ss%db%d", chassisX, slot, bmc)
+				mac := expandPattern(c.MAC, vals)
+				if hw, err := net.ParseMAC(mac); err != nil || len(hw) != 6 {
+					return nil, 0, c.fieldError(i, "mac", "%q expands to %q for %s, which is not a MAC address", c.MAC, mac, x)
+				}
+				e := inventory.Entry{Xname: x, MAC: strings.ToLower(mac), NID: nid}
+				if c.IP != "" {
+					e.IP = expandPattern(c.IP, vals)
+					if n := highOctet(e.IP); n >= 0 {
+						return nil, 0, c.fieldError(i, "ip", "%q expands to %q for %s, whose octet %d exceeds 254", c.IP, e.IP, x, n)
+					}
+					if ip := net.ParseIP(e.IP); ip == nil || ip.To4() == nil {
+						return nil, 0, c.fieldError(i, "ip", "%q expands to %q for %s, which is not an IPv4 address", c.IP, e.IP, x)
+					}
+				}
+				out = append(out, e)
+				nid += perBMC
+				nodes += perBMC
+			}
+		}
+	}
+	return out, nid, nil
+}
+
+func (c CabinetTemplate) pattern(field string) string {
+	if field == "mac" {
+		return c.MAC
+	}
+	return c.IP
+}
+
+// placeholderRe matches {name} or {name:verb}, e.g. {slot:02d}.
+var placeholderRe = regexp.MustCompile(`\{([a-z_]+)(?::([0-9]*[dxX]))?\}`)
+
+// placeholderVerbs are the default printf verbs of the placeholders.
+var placeholderVerbs = map[string]string{
+	"cabinet": "d", "chassis": "d", "chassis_hex": "02x", "slot": "d", "bmc": "d", "nid": "d",
+}
+
+// checkPattern reports an unknown placeholder or stray brace in p.
+func checkPattern(p string) error {
+	for _, m := range placeholderRe.FindAllStringSubmatch(p, -1) {
+		if _, ok := placeholderVerbs[m[1]]; !ok {
+			return fmt.Errorf("unknown placeholder {%s} (use cabinet, chassis, chassis_hex, slot, bmc, or nid)", m[1])
+		}
+	}
+	if rest := placeholderRe.ReplaceAllString(p, ""); strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("malformed placeholder in %q", p)
+	}
+	return nil
+}
+
+// expandPattern replaces the placeholders of p with vals.
+func expandPattern(p string, vals map[string]int) string {
+	return placeholderRe.ReplaceAllStringFunc(p, func(s string) string {
+		m := placeholderRe.FindStringSubmatch(s)
+		verb := m[2]
+		if verb == "" {
+			verb = placeholderVerbs[m[1]]
+		}
+		return fmt.Sprintf("%"+verb, vals[m[1]])
+	})
+}
+
+// parseNumberList parses a comma-separated list of numbers and ranges such
+// as "0-4,6-7" into sorted, distinct numbers.
+func parseNumberList(spec string) ([]int, error) {
+	var out []int
+	for _, part := range strings.Split(spec, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid number or range %q", strings.TrimSpace(part))
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil || last < first {
+				return nil, fmt.Errorf("invalid range %q", strings.TrimSpace(part))
+			}
+		}
+		if first < 0 {
+			return nil, fmt.Errorf("%q is out of range", strings.TrimSpace(part))
+		}
+		for n := first; n <= last; n++ {
+			if !slices.Contains(out, n) {
+				out = append(out, n)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
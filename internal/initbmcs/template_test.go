@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package initbmcs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"bootstrap/internal/inventory"
+)
+
+func TestTemplateGenerate(t *testing.T) {
+	tmpl, err := ParseTemplate([]byte(`subnet: 10.100.0.0/24
+start_ip: 10.100.0.10
+start_nid: 1000
+cabinets:
+  - cabinet: 9000
+    chassis: 1,3
+    slots: 4-6
+    nodes_per_blade: 2
+    mac: "02:23:28:{chassis_hex}:3{slot}:{bmc}0"
+  - cabinet: 3000
+    chassis: 0
+    slots: 17
+    nodes_per_blade: 1
+    nodes_per_bmc: 1
+    mac: "02:23:28:05:{slot:02d}:00"
+    ip: "10.101.{chassis}.{slot}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bmcs, err := tmpl.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, b := range bmcs {
+		got = append(got, strings.Join([]string{b.Xname, b.MAC, b.IP}, " "))
+	}
+	want := []string{
+		"x9000c1s4b0 02:23:28:01:34:00 10.100.0.10",
+		"x9000c1s5b0 02:23:28:01:35:00 10.100.0.11",
+		"x9000c1s6b0 02:23:28:01:36:00 10.100.0.12",
+		"x9000c3s4b0 02:23:28:03:34:00 10.100.0.13",
+		"x9000c3s5b0 02:23:28:03:35:00 10.100.0.14",
+		"x9000c3s6b0 02:23:28:03:36:00 10.100.0.15",
+		"x3000c0s17b0 02:23:28:05:17:00 10.101.0.17",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("template BMCs:\n got: %v\nwant: %v", got, want)
+	}
+	if bmcs[0].NID != 1000 || bmcs[5].NID != 1010 || bmcs[6].NID != 1012 {
+		t.Errorf("NIDs = %d, %d, %d; want 1000, 1010, 1012", bmcs[0].NID, bmcs[5].NID, bmcs[6].NID)
+	}
+}
+
+func TestTemplateSkipsSlots(t *testing.T) {
+	tmpl, err := ParseTemplate([]byte(`subnet: 192.168.100.0/24
+cabinets:
+  - cabinet: 9000
+    chassis: 1
+    slots: 0-4,6-7
+    mac: "02:23:28:{chassis_hex}:3{slot}:{bmc}0"
+  - cabinet: 3000
+    chassis: 0
+    slots: 1-2
+    nodes_per_blade: 1
+    nodes_per_bmc: 1
+    mac: "02:23:28:05:{slot:02d}:00"
+    ip: "10.254.1.{slot}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bmcs, err := tmpl.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bmcs) != 16 {
+		t.Fatalf("got %d BMCs, want 14 mountain and 2 river", len(bmcs))
+	}
+	for _, b := range bmcs {
+		if strings.HasPrefix(b.Xname, "x9000c1s5") {
+			t.Errorf("slot 5 was not skipped: %s", b.Xname)
+		}
+	}
+	if got := xnamesAndMACs(bmcs[9:11]); !reflect.DeepEqual(got, []string{"x9000c1s4b1 02:23:28:01:34:10", "x9000c1s6b0 02:23:28:01:36:00"}) {
+		t.Errorf("around the skipped slot: %v", got)
+	}
+	// NIDs run on over the gap and into the next cabinet
+	if bmcs[10].NID != 21 || bmcs[14].NID != 29 || bmcs[15].NID != 30 {
+		t.Errorf("NIDs = %d, %d, %d; want 21, 29, 30", bmcs[10].NID, bmcs[14].NID, bmcs[15].NID)
+	}
+	if bmcs[0].IP != "192.168.100.1" || bmcs[13].IP != "192.168.100.14" || bmcs[14].IP != "10.254.1.1" {
+		t.Errorf("IPs = %s, %s, %s", bmcs[0].IP, bmcs[13].IP, bmcs[14].IP)
+	}
+}
+
+func TestTemplateErrorsNameFieldAndLine(t *testing.T) {
+	cases := []struct {
+		name, template, want string
+	}{
+		{"unknown top-level field", "subnet: 10.0.0.0/24\ncabinetz: []\n", "line 2: field cabinetz not found"},
+		{"unknown cabinet field", "cabinets:\n  - cabinet: 1\n    slot: 3\n", `line 3: unknown cabinet field "slot"`},
+		{"bad slots", "subnet: 10.0.0.0/24\ncabinets:\n  - cabinet: 1\n    chassis: 0\n    slots: 0-x\n    mac: 02:00:00:00:00:{slot}\n",
+			`line 5: cabinets[0].slots: invalid range "0-x"`},
+		{"mac not a MAC", "subnet: 10.0.0.0/24\ncabinets:\n  - cabinet: 1\n    chassis: 0\n    mac: 02:00:{slot}\n",
+			`line 5: cabinets[0].mac: "02:00:{slot}" expands to "02:00:0" for x1c0s0b0, which is not a MAC address`},
+		{"unknown placeholder", "subnet: 10.0.0.0/24\ncabinets:\n  - cabinet: 1\n    chassis: 0\n    mac: 02:00:00:00:{blade}:00\n",
+			"line 5: cabinets[0].mac: unknown placeholder {blade}"},
+		{"duplicate MAC", "subnet: 10.0.0.0/24\ncabinets:\n  - cabinet: 1\n    chassis: 0\n    mac: 02:00:00:00:00:{bmc}0\n",
+			"line 5: cabinets[0].mac: MAC 02:00:00:00:00:00 of x1c0s1b0 is also used by x1c0s0b0"},
+		{"blade not by bmc", "cabinets:\n  - cabinet: 1\n    chassis: 0\n    nodes_per_blade: 3\n    mac: x\n",
+			"line 4: cabinets[0].nodes_per_blade: 3 is not a multiple of nodes_per_bmc (2)"},
+		{"odd nodes per chassis", "cabinets:\n  - cabinet: 1\n    chassis: 0\n    nodes_per_bmc: 4\n    nodes_per_chassis: 6\n    mac: x\n",
+			"line 5: cabinets[0].nodes_per_chassis: 6 is not a multiple of nodes_per_bmc (4)"},
+		{"ip octet over 254", "cabinets:\n  - cabinet: 1\n    chassis: 0\n    mac: 02:00:00:00:3{slot}:{bmc}0\n    ip: 10.0.{slot}.25{nid}\n",
+			`line 5: cabinets[0].ip: "10.0.{slot}.25{nid}" expands to "10.0.1.255" for x1c0s1b0, whose octet 255 exceeds 254`},
+		{"bad cabinet subnet", "cabinets:\n  - cabinet: 1\n    chassis: 0\n    mac: 02:00:00:00:3{slot}:{bmc}0\n    subnet: 10.0.0.0/33\n",
+			`line 5: cabinets[0].subnet: invalid subnet "10.0.0.0/33"`},
+		{"no subnet", "cabinets:\n  - cabinet: 1\n    chassis: 0\n    mac: 02:00:00:00:3{slot}:{bmc}0\n", "subnet: required"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate([]byte(tc.template))
+			if err == nil {
+				_, err = tmpl.Generate()
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMountainTemplateOrdersChassis(t *testing.T) {
+	chassis := map[string]string{"x9000c3": "02:23:28:03", "x9000c1": "02:23:28:01"}
+	g := Geometry{NodesPerChassis: 4, NodesPerBMC: 2, NodesPerBlade: 4, SlotsPerChassis: 8}
+	bmcs, err := Generate(chassis, g, 1, "192.168.100.0/24", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"x9000c1s0b0 02:23:28:01:30:00", "x9000c1s0b1 02:23:28:01:30:10",
+		"x9000c3s0b0 02:23:28:03:30:00", "x9000c3s0b1 02:23:28:03:30:10"}
+	if got := xnamesAndMACs(bmcs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("chassis order:\n got: %v\nwant: %v", got, want)
+	}
+	if bmcs[2].NID != 5 || bmcs[2].IP != "192.168.100.3" {
+		t.Errorf("first BMC of x9000c3 = %+v, want NID 5 at 192.168.100.3", bmcs[2])
+	}
+}
+
+func TestTemplateCabinetSubnetsAndExisting(t *testing.T) {
+	tmpl, err := ParseTemplate([]byte(`subnet: 192.168.100
+start_ip: 5
+cabinets:
+  - cabinet: 9000
+    chassis: 0
+    slots: 0
+    mac: "02:23:28:00:3{slot}:{bmc}0"
+  - cabinet: 9001
+    chassis: 0
+    slots: 0
+    mac: "02:23:28:10:3{slot}:{bmc}0"
+    subnet: 192.168.101.0/24
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// .5 is taken by an existing BMC, so the allocation moves past it
+	tmpl.Existing = []inventory.Entry{{Xname: "x3000c0s1b0", IP: "192.168.100.5"}}
+	bmcs, err := tmpl.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ips []string
+	for _, b := range bmcs {
+		ips = append(ips, b.IP)
+	}
+	if want := []string{"192.168.100.6", "192.168.100.7", "192.168.101.5", "192.168.101.6"}; !reflect.DeepEqual(ips, want) {
+		t.Fatalf("IPs = %v, want %v", ips, want)
+	}
+
+	// An ip pattern that lands on an existing BMC names both
+	tmpl.Cabinets[1].IP = "192.168.100.{bmc}"
+	tmpl.Existing = []inventory.Entry{{Xname: "x3000c0s1b0", IP: "192.168.100.1"}}
+	if _, err := tmpl.Generate(); err == nil || !strings.Contains(err.Error(), "IP 192.168.100.1 of x9001c0s0b1 is already used by x3000c0s1b0 in bmcs[]") {
+		t.Errorf("existing collision: err = %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	"bootstrap/internal/xname"
+)
+
+// SortEntries sorts entries by xname in natural order, so x9000c1s9b0 comes
+// before x9000c1s10b0. Entries with equal xnames keep their order.
+func SortEntries(entries []Entry) {
+	slices.SortStableFunc(entries, func(a, b Entry) int { return xname.Compare(a.Xname, b.Xname) })
+}
+
+// NormalizeMACs rewrites the MAC of every entry with NormalizeMAC.
+func NormalizeMACs(entries []Entry) {
+	for i := range entries {
+		entries[i].MAC = NormalizeMAC(entries[i].MAC)
+	}
+}
+
+// NormalizeMAC returns mac in lowercase colon-separated form, e.g.
+// AA-BB-CC-DD-EE-FF, aabb.ccdd.eeff, and AABBCCDDEEFF become
+// aa:bb:cc:dd:ee:ff. A value that is not a 48-bit MAC is returned unchanged.
+func NormalizeMAC(mac string) string {
+	s := strings.TrimSpace(mac)
+	if len(s) == 12 && !strings.ContainsAny(s, ":-.") {
+		// Bare hex digits, as some BMCs report them
+		s = s[0:2] + ":" + s[2:4] + ":" + s[4:6] + ":" + s[6:8] + ":" + s[8:10] + ":" + s[10:12]
+	}
+	hw, err := net.ParseMAC(s)
+	if err != nil || len(hw) != 6 {
+		return mac
+	}
+	return hw.String()
+}
+
+// Canonicalize sorts nodes[] and controllers[] (and bmcs[] when sortBMCs is
+// set) by xname and normalizes the MACs of every section, so that rewriting an inventory
+// gives the same file whatever order its entries were produced in.
+func Canonicalize(doc *FileFormat, sortBMCs bool) {
+	NormalizeMACs(doc.BMCs)
+	NormalizeMACs(doc.Nodes)
+	NormalizeMACs(doc.Controllers)
+	SortEntries(doc.Nodes)
+	SortEntries(doc.Controllers)
+	if sortBMCs {
+		SortEntries(doc.BMCs)
+	}
+}
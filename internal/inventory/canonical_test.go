@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeMAC(t *testing.T) {
+	cases := map[string]string{
+		"AA:BB:CC:DD:EE:FF":   "aa:bb:cc:dd:ee:ff",
+		"aa-bb-cc-dd-ee-ff":   "aa:bb:cc:dd:ee:ff",
+		"aabb.ccdd.eeff":      "aa:bb:cc:dd:ee:ff",
+		"AABBCCDDEEFF":        "aa:bb:cc:dd:ee:ff",
+		" aa:bb:cc:dd:ee:ff ": "aa:bb:cc:dd:ee:ff",
+		"":                    "",
+		"not-a-mac":           "not-a-mac",
+		// 64-bit EUI is left alone
+		"00:11:22:33:44:55:66:77": "00:11:22:33:44:55:66:77",
+	}
+	for in, want := range cases {
+		if got := NormalizeMAC(in); got != want {
+			t.Errorf("NormalizeMAC(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	doc := FileFormat{
+		BMCs: []Entry{{Xname: "x9000c1s10b0", MAC: "02:23:28:01:30:A0"}, {Xname: "x9000c1s9b0"}},
+		Nodes: []Entry{
+			{Xname: "x9000c1s10b0n0", MAC: "0040A688D910"},
+			{Xname: "x9000c1s9b0n1"},
+			{Xname: "x9000c1s9b0n0"},
+		},
+	}
+	Canonicalize(&doc, false)
+	if doc.BMCs[0].Xname != "x9000c1s10b0" || doc.BMCs[0].MAC != "02:23:28:01:30:a0" {
+		t.Errorf("bmcs[] should keep its order and have MACs normalized: %+v", doc.BMCs)
+	}
+	var got []string
+	for _, n := range doc.Nodes {
+		got = append(got, n.Xname)
+	}
+	if want := []string{"x9000c1s9b0n0", "x9000c1s9b0n1", "x9000c1s10b0n0"}; !slices.Equal(got, want) {
+		t.Errorf("nodes order = %v, want %v", got, want)
+	}
+	if doc.Nodes[2].MAC != "00:40:a6:88:d9:10" {
+		t.Errorf("node MAC not normalized: %q", doc.Nodes[2].MAC)
+	}
+
+	Canonicalize(&doc, true)
+	if doc.BMCs[0].Xname != "x9000c1s9b0" {
+		t.Errorf("sortBMCs should sort bmcs[]: %+v", doc.BMCs)
+	}
+}
@@ -0,0 +1,248 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode parses an inventory file like ParseDocument, without keeping its
+// node tree.
+func Decode(raw []byte) (FileFormat, error) {
+	doc, _, err := ParseDocument(raw)
+	return doc, err
+}
+
+// Keys FileFormat and Entry know, from their yaml tags, with the kind of
+// node each takes.
+var (
+	topKeys   = yamlKeys(reflect.TypeOf(FileFormat{}))
+	entryKeys = yamlKeys(reflect.TypeOf(Entry{}))
+)
+
+type knownKey struct {
+	name string
+	kind yaml.Kind
+}
+
+// requiredEntryKey is the key schema.json requires of every entry. An entry
+// without it is checked for a key that looks like a mistyped one.
+const requiredEntryKey = "xname"
+
+func yamlKeys(t reflect.Type) []knownKey {
+	var keys []knownKey
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		kind := yaml.ScalarNode
+		switch f.Type.Kind() {
+		case reflect.Slice:
+			kind = yaml.SequenceNode
+		case reflect.Map:
+			kind = yaml.MappingNode
+		}
+		keys = append(keys, knownKey{name, kind})
+	}
+	return keys
+}
+
+// checkKeys rejects keys of the top-level mapping and of the entries that
+// look like a misspelled known key, e.g. "IP" or "x_name", and hold the kind
+// of value that key takes; in an entry without an xname, a key a letter or
+// two from it, such as "xnmae", is taken for it too. Other unknown keys are
+// kept (Entry.Extra), so site-specific keys such as location, mode, or notes
+// still work.
+func checkKeys(top *yaml.Node) error {
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		k, v := top.Content[i], top.Content[i+1]
+		if err := checkKey(k, v, topKeys, "", "the top level"); err != nil {
+			return err
+		}
+		if v.Kind != yaml.SequenceNode || (k.Value != "bmcs" && k.Value != "nodes" && k.Value != "controllers") {
+			continue
+		}
+		prev := ""
+		for j, item := range v.Content {
+			if item.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d, column %d: %s must be a mapping with xname, mac, and ip", item.Line, item.Column, entryName(k.Value, j, "", prev))
+			}
+			x, missing := "", requiredEntryKey
+			if xn := mappingValue(item, requiredEntryKey); xn != nil {
+				x, missing = xn.Value, ""
+			}
+			for n := 0; n+1 < len(item.Content); n += 2 {
+				if err := checkKey(item.Content[n], item.Content[n+1], entryKeys, missing, entryName(k.Value, j, x, prev)); err != nil {
+					return err
+				}
+			}
+			if x != "" {
+				prev = x
+			}
+		}
+	}
+	return nil
+}
+
+// checkKey rejects k when it looks like a misspelled key of known, or like
+// a typo of missing, a required key its mapping lacks.
+func checkKey(k, v *yaml.Node, known []knownKey, missing, where string) error {
+	for _, key := range known {
+		if k.Value == key.name {
+			return nil
+		}
+	}
+	empty := v.Kind == yaml.ScalarNode && v.Tag == "!!null"
+	for _, key := range known {
+		if (v.Kind == key.kind || empty) && (misspelled(k.Value, key.name) || key.name == missing && mistyped(k.Value, key.name)) {
+			return fmt.Errorf("line %d, column %d: unknown key %q in %s (did you mean %q?)", k.Line, k.Column, k.Value, where, key.name)
+		}
+	}
+	return nil
+}
+
+// entryName names the index-th entry of section for an error: by its
+// xname, or else by its position and the xname of the entry before it.
+func entryName(section string, index int, xname, prev string) string {
+	switch {
+	case xname != "":
+		return fmt.Sprintf("%s[] entry %s", section, xname)
+	case prev != "":
+		return fmt.Sprintf("%s[%d] (the entry after %s)", section, index, prev)
+	}
+	return fmt.Sprintf("%s[%d]", section, index)
+}
+
+// misspelled reports whether key is probably a typo of known: the same
+// apart from case, - or _, or a plural s or es (nodess, macs). Closer calls
+// such as mode for model or notes for nodes are left to be extra keys.
+func misspelled(key, known string) bool {
+	norm := func(s string) string { return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s)) }
+	k, n := norm(key), norm(known)
+	for _, suffix := range []string{"", "s", "es"} {
+		if k == n+suffix || k+suffix == n {
+			return true
+		}
+	}
+	return false
+}
+
+// mistyped reports whether key is within two edits, or one swap of adjacent
+// letters, of known. Too loose for every key (mode and model), it is only
+// applied to a required key that is missing.
+func mistyped(key, known string) bool {
+	k := strings.ToLower(key)
+	for i := 0; i+1 < len(k); i++ {
+		if k[:i]+k[i+1:i+2]+k[i:i+1]+k[i+2:] == known {
+			return true
+		}
+	}
+	return editDistance(k, known) <= 2
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			diag, row[j] = row[j], min(row[j]+1, row[j-1]+1, diag+cost)
+		}
+	}
+	return row[len(b)]
+}
+
+var (
+	lineRe  = regexp.MustCompile(`line (\d+)`)
+	xnameRe = regexp.MustCompile(`^\s*(?:-\s+)?xname:\s*["']?([^"'\s#]+)`)
+	topRe   = regexp.MustCompile(`^([A-Za-z_][\w-]*):`)
+)
+
+// explainYAMLError adds to each line of a YAML syntax or type error the
+// entry it is in or follows, and a hint when it is about a line indented
+// with a tab, which YAML does not allow.
+func explainYAMLError(raw []byte, err error) error {
+	lines := strings.Split(string(raw), "\n")
+	explain := func(msg string) string {
+		m := lineRe.FindStringSubmatch(msg)
+		if m == nil {
+			return msg
+		}
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > len(lines) {
+			return msg
+		}
+		if near := nearestEntry(lines, n); near != "" {
+			msg += " (near " + near + ")"
+		}
+		// yaml reports some tabs on the line before the one that has it
+		for _, l := range lines[n-1 : min(n+1, len(lines))] {
+			if strings.HasPrefix(strings.TrimLeft(l, " "), "\t") {
+				msg += "; YAML must be indented with spaces, not tabs"
+				break
+			}
+		}
+		return msg
+	}
+	var terr *yaml.TypeError
+	if errors.As(err, &terr) {
+		msgs := make([]string, len(terr.Errors))
+		for i, e := range terr.Errors {
+			msgs[i] = explain(e)
+		}
+		return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+	}
+	return errors.New(explain(strings.TrimPrefix(err.Error(), "yaml: ")))
+}
+
+// nearestEntry names the entry line n (1-based) is in or after, from the
+// closest xname line above it within the same top-level section.
+func nearestEntry(lines []string, n int) string {
+	for i := n - 1; i >= 0; i-- {
+		if m := topRe.FindStringSubmatch(lines[i]); m != nil {
+			return ""
+		}
+		if m := xnameRe.FindStringSubmatch(lines[i]); m != nil {
+			section := ""
+			for j := i - 1; j >= 0; j-- {
+				if t := topRe.FindStringSubmatch(lines[j]); t != nil {
+					section = t[1]
+					break
+				}
+			}
+			return fmt.Sprintf("%s[] entry %s", section, m[1])
+		}
+	}
+	return ""
+}
+
+//go:embed schema.json
+var schema []byte
+
+// Schema returns a JSON Schema (draft 2020-12) of the inventory file, for
+// editors and CI to check files against without running this tool. Like
+// Decode, it allows keys it does not list.
+func Schema() []byte {
+	return bytes.Clone(schema)
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "misspelled entry key",
+			yaml: "bmcs:\n  - xname: x9000c1s0b0\n    mac: \"02:23:28:01:30:00\"\n  - x_name: x9000c1s1b0\n    ip: 10.0.0.2\n",
+			want: []string{"line 4, column 5", `unknown key "x_name"`, `did you mean "xname"`, "bmcs[1] (the entry after x9000c1s0b0)"},
+		},
+		{
+			name: "mistyped xname",
+			yaml: "bmcs:\n  - xname: x9000c1s0b0\n    mac: \"02:23:28:01:30:00\"\n  - xnmae: x9000c1s1b0\n    ip: 10.0.0.2\n",
+			want: []string{"line 4, column 5", `unknown key "xnmae"`, `did you mean "xname"`, "bmcs[1] (the entry after x9000c1s0b0)"},
+		},
+		{
+			name: "wrong case",
+			yaml: "nodes:\n  - xname: x9000c1s0b0n0\n    IP: 10.42.0.1\n",
+			want: []string{"line 3, column 5", `did you mean "ip"`, "nodes[] entry x9000c1s0b0n0"},
+		},
+		{
+			name: "plural entry key",
+			yaml: "nodes:\n  - xname: x9000c1s0b0n0\n    macs: \"02:23:28:01:30:00\"\n",
+			want: []string{"line 3, column 5", `did you mean "mac"`},
+		},
+		{
+			name: "misspelled section",
+			yaml: "bmcs: []\nnodess:\n  - xname: x9000c1s0b0n0\n",
+			want: []string{"line 2, column 1", `unknown key "nodess" in the top level`, `did you mean "nodes"`},
+		},
+		{
+			name: "tab indentation",
+			yaml: "bmcs:\n  - xname: x9000c1s0b0\n\tmac: \"02:23:28:01:30:00\"\n",
+			want: []string{"line 2", "near bmcs[] entry x9000c1s0b0", "indented with spaces, not tabs"},
+		},
+		{
+			name: "nid not a number",
+			yaml: "nodes:\n  - xname: x9000c1s0b0n0\n    nid: first\n",
+			want: []string{"line 3", "cannot unmarshal", "near nodes[] entry x9000c1s0b0n0"},
+		},
+		{
+			name: "section is a mapping",
+			yaml: "bmcs:\n  xname: x9000c1s0b0\n",
+			want: []string{"line 2", "cannot unmarshal !!map"},
+		},
+		{
+			name: "entry is not a mapping",
+			yaml: "bmcs:\n  - xname: x9000c1s0b0\n  - x9000c1s1b0\n",
+			want: []string{"line 3, column 5", "bmcs[1] (the entry after x9000c1s0b0) must be a mapping"},
+		},
+		{
+			name: "duplicate key",
+			yaml: "nodes:\n  - xname: x9000c1s0b0n0\n    ip: 10.42.0.1\n    ip: 10.42.0.2\n",
+			want: []string{"line 4", `"ip" already defined`, "near nodes[] entry x9000c1s0b0n0"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode([]byte(tc.yaml))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("error %q does not contain %q", err, w)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeKeepsOtherKeys(t *testing.T) {
+	// Keys a letter away from a known one, like notes (nodes) and mode
+	// (model), are not typos unless they differ only in case, - or _, or a
+	// plural
+	raw := "site: lab\nnotes:\n  - racked 2025-03\nbmcs:\n  - xname: x9000c1s0b0\n    location: rack1-u10\n    rack: 1\n    mode: uefi\n"
+	doc, err := Decode([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.BMCs[0].Extra["location"]; got != "rack1-u10" {
+		t.Errorf("location = %v", got)
+	}
+	if got := doc.BMCs[0].Extra["mode"]; got != "uefi" {
+		t.Errorf("mode = %v", got)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	var s struct {
+		Properties map[string]any `json:"properties"`
+		Defs       struct {
+			Entry struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"entry"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema(), &s); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+	// The schema lists every key the decoder knows
+	for _, k := range topKeys {
+		if _, ok := s.Properties[k.name]; !ok {
+			t.Errorf("schema lacks top-level key %s", k.name)
+		}
+	}
+	for _, k := range entryKeys {
+		if _, ok := s.Defs.Entry.Properties[k.name]; !ok {
+			t.Errorf("schema lacks entry key %s", k.name)
+		}
+	}
+}
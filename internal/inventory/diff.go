@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import "strings"
+
+// FieldChange is a single field that differs between two versions of an entry.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Change describes an entry that was added, removed, or modified.
+type Change struct {
+	Section string        `json:"section"`
+	Xname   string        `json:"xname"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// DiffResult lists the differences between two inventory files.
+type DiffResult struct {
+	Added    []Change `json:"added"`
+	Removed  []Change `json:"removed"`
+	Modified []Change `json:"modified"`
+}
+
+// Empty reports whether the two files were equivalent.
+func (d DiffResult) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Diff compares bmcs[] and nodes[] of two files by xname. MAC and IP changes
+// are reported field by field; MACs that differ only in letter case are equal.
+func Diff(oldDoc, newDoc FileFormat) DiffResult {
+	res := DiffResult{Added: []Change{}, Removed: []Change{}, Modified: []Change{}}
+	diffSection(&res, "bmcs", oldDoc.BMCs, newDoc.BMCs)
+	diffSection(&res, "nodes", oldDoc.Nodes, newDoc.Nodes)
+	return res
+}
+
+func diffSection(res *DiffResult, section string, oldList, newList []Entry) {
+	oldBy := make(map[string]Entry, len(oldList))
+	for _, e := range oldList {
+		oldBy[e.Xname] = e
+	}
+	newBy := make(map[string]Entry, len(newList))
+	for _, e := range newList {
+		newBy[e.Xname] = e
+		prev, ok := oldBy[e.Xname]
+		if !ok {
+			res.Added = append(res.Added, Change{Section: section, Xname: e.Xname, Fields: fieldChanges(Entry{}, e)})
+			continue
+		}
+		if fields := fieldChanges(prev, e); len(fields) > 0 {
+			res.Modified = append(res.Modified, Change{Section: section, Xname: e.Xname, Fields: fields})
+		}
+	}
+	for _, e := range oldList {
+		if _, ok := newBy[e.Xname]; !ok {
+			res.Removed = append(res.Removed, Change{Section: section, Xname: e.Xname, Fields: fieldChanges(e, Entry{})})
+		}
+	}
+}
+
+func fieldChanges(a, b Entry) []FieldChange {
+	var out []FieldChange
+	if !strings.EqualFold(a.MAC, b.MAC) {
+		out = append(out, FieldChange{Field: "mac", Old: a.MAC, New: b.MAC})
+	}
+	if a.IP != b.IP {
+		out = append(out, FieldChange{Field: "ip", Old: a.IP, New: b.IP})
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldDoc := FileFormat{
+		BMCs: []Entry{{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "192.168.100.1"}},
+		Nodes: []Entry{
+			{Xname: "x9000c1s0b0n0", MAC: "00:40:A6:88:D9:01", IP: "10.42.0.1"},
+			{Xname: "x9000c1s0b0n1", MAC: "00:40:a6:88:d9:02", IP: "10.42.0.2"},
+			{Xname: "x9000c1s0b1n0", MAC: "00:40:a6:88:d9:03", IP: "10.42.0.3"},
+		},
+	}
+	newDoc := FileFormat{
+		BMCs: []Entry{{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "192.168.100.1"}},
+		Nodes: []Entry{
+			{Xname: "x9000c1s0b0n0", MAC: "00:40:a6:88:d9:01", IP: "10.42.0.1"},
+			{Xname: "x9000c1s0b0n1", MAC: "00:40:a6:88:d9:22", IP: "10.42.0.12"},
+			{Xname: "x9000c1s0b1n1", MAC: "00:40:a6:88:d9:04", IP: "10.42.0.4"},
+		},
+	}
+	got := Diff(oldDoc, newDoc)
+	want := DiffResult{
+		Added: []Change{{Section: "nodes", Xname: "x9000c1s0b1n1", Fields: []FieldChange{
+			{Field: "mac", New: "00:40:a6:88:d9:04"}, {Field: "ip", New: "10.42.0.4"},
+		}}},
+		Removed: []Change{{Section: "nodes", Xname: "x9000c1s0b1n0", Fields: []FieldChange{
+			{Field: "mac", Old: "00:40:a6:88:d9:03"}, {Field: "ip", Old: "10.42.0.3"},
+		}}},
+		Modified: []Change{{Section: "nodes", Xname: "x9000c1s0b0n1", Fields: []FieldChange{
+			{Field: "mac", Old: "00:40:a6:88:d9:02", New: "00:40:a6:88:d9:22"},
+			{Field: "ip", Old: "10.42.0.2", New: "10.42.0.12"},
+		}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff =\n%+v\nwant\n%+v", got, want)
+	}
+	if !Diff(oldDoc, oldDoc).Empty() {
+		t.Error("diff of a file with itself should be empty")
+	}
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is an inventory file kept as a YAML node tree, so a command can
+// replace one top-level section and write the file back without losing
+// comments, key order, or keys FileFormat does not know about.
+type Document struct {
+	root yaml.Node
+}
+
+// ParseDocument decodes raw into a FileFormat and keeps its node tree for
+// Set and Bytes. An empty file yields an empty document.
+func ParseDocument(raw []byte) (FileFormat, *Document, error) {
+	var doc FileFormat
+	d := &Document{}
+	if err := yaml.Unmarshal(raw, &d.root); err != nil {
+		return doc, nil, explainYAMLError(raw, err)
+	}
+	if d.root.Kind == 0 {
+		d.root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if d.top().Kind != yaml.MappingNode {
+		return doc, nil, fmt.Errorf("line %d: inventory must be a mapping with bmcs and nodes", d.top().Line)
+	}
+	if err := checkKeys(d.top()); err != nil {
+		return doc, nil, err
+	}
+	if err := d.root.Decode(&doc); err != nil {
+		return doc, nil, explainYAMLError(raw, err)
+	}
+	return doc, d, nil
+}
+
+func (d *Document) top() *yaml.Node {
+	return d.root.Content[0]
+}
+
+// Set replaces the value of the top-level key (e.g. "nodes") with v, adding
+// the key at the end when it is missing. Everything else in the tree is left
+// as it was. When v is a list of entries, the comments of an old entry are
+// carried over to the new entry with the same xname.
+func (d *Document) Set(key string, v any) error {
+	var value yaml.Node
+	if err := value.Encode(v); err != nil {
+		return err
+	}
+	top := d.top()
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value != key {
+			continue
+		}
+		old := top.Content[i+1]
+		if old.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode {
+			byXname := map[string]*yaml.Node{}
+			for _, item := range old.Content {
+				if x := mappingValue(item, "xname"); x != nil {
+					byXname[x.Value] = item
+				}
+			}
+			for _, item := range value.Content {
+				if x := mappingValue(item, "xname"); x != nil && byXname[x.Value] != nil {
+					copyComments(byXname[x.Value], item)
+				}
+			}
+		}
+		value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		top.Content[i+1] = &value
+		return nil
+	}
+	top.Content = append(top.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	return nil
+}
+
+// Bytes encodes the tree with the same indentation as yaml.Marshal.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(&d.root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value of key in mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// copyComments copies the comments of mapping old, and of its keys and
+// values that are still present, onto to.
+func copyComments(old, to *yaml.Node) {
+	to.HeadComment, to.LineComment, to.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	if old.Kind != yaml.MappingNode || to.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(to.Content); i += 2 {
+		for j := 0; j+1 < len(old.Content); j += 2 {
+			if old.Content[j].Value != to.Content[i].Value {
+				continue
+			}
+			k, from := to.Content[i], old.Content[j]
+			k.HeadComment, k.LineComment, k.FootComment = from.HeadComment, from.LineComment, from.FootComment
+			copyComments(old.Content[j+1], to.Content[i+1])
+		}
+	}
+}
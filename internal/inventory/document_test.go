@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+const commentedHead = `# Site inventory for the lab cluster
+# owner: ops
+bmcs:
+    # rack 1
+    - xname: x9000c1s0b0
+      mac: "02:23:28:01:30:00"
+      ip: 192.168.100.1
+      location: rack1-u10 # front
+`
+
+const commentedTail = `# never hand these out
+reserved:
+    - 10.42.0.50-10.42.0.99 # vendor appliances
+site: lab
+`
+
+const commentedNodes = `nodes:
+    # first node
+    - xname: x9000c1s0b0n0
+      mac: 00:40:a6:88:d9:01
+      ip: 10.42.0.1
+      notes: swapped DIMM # 2025-03
+`
+
+func TestDocumentSetPreservesRestOfFile(t *testing.T) {
+	doc, d, err := ParseDocument([]byte(commentedHead + commentedNodes + commentedTail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.BMCs[0].Extra["location"]; got != "rack1-u10" {
+		t.Errorf("bmc location = %v", got)
+	}
+
+	// Rewrite nodes[]: the known node changes IP and a new one is added
+	nodes := append([]Entry{}, doc.Nodes...)
+	nodes[0].IP = "10.42.0.7"
+	nodes = append(nodes, Entry{Xname: "x9000c1s0b0n1", MAC: "00:40:a6:88:d9:02", IP: "10.42.0.8"})
+	if err := d.Set("nodes", nodes); err != nil {
+		t.Fatal(err)
+	}
+	out, err := d.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	if !strings.HasPrefix(got, commentedHead+"nodes:\n") || !strings.HasSuffix(got, commentedTail) {
+		t.Fatalf("content outside nodes changed:\n%s", got)
+	}
+	wantNodes := `nodes:
+    # first node
+    - xname: x9000c1s0b0n0
+      mac: 00:40:a6:88:d9:01
+      ip: 10.42.0.7
+      notes: swapped DIMM # 2025-03
+    - xname: x9000c1s0b0n1
+      mac: 00:40:a6:88:d9:02
+      ip: 10.42.0.8
+`
+	if mid := strings.TrimSuffix(strings.TrimPrefix(got, commentedHead), commentedTail); mid != wantNodes {
+		t.Errorf("nodes section =\n%s\nwant\n%s", mid, wantNodes)
+	}
+}
+
+func TestDocumentRoundTripUnchanged(t *testing.T) {
+	in := commentedHead + commentedNodes + commentedTail
+	doc, d, err := ParseDocument([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("nodes", doc.Nodes); err != nil {
+		t.Fatal(err)
+	}
+	out, err := d.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Errorf("round trip changed the file:\n%s", out)
+	}
+}
+
+func TestDocumentAddsMissingSection(t *testing.T) {
+	for _, in := range []string{"", "# only bmcs\nbmcs: []\n"} {
+		_, d, err := ParseDocument([]byte(in))
+		if err != nil {
+			t.Fatalf("ParseDocument(%q): %v", in, err)
+		}
+		if err := d.Set("nodes", []Entry{{Xname: "x9000c1s0b0n0"}}); err != nil {
+			t.Fatal(err)
+		}
+		out, err := d.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(out), strings.TrimSuffix(in, "\n")) || !strings.Contains(string(out), "nodes:\n    - xname: x9000c1s0b0n0\n") {
+			t.Errorf("Set on %q gave:\n%s", in, out)
+		}
+	}
+	if _, _, err := ParseDocument([]byte("- not a mapping\n")); err == nil {
+		t.Error("expected an error for a top-level sequence")
+	}
+}
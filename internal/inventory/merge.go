@@ -0,0 +1,288 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bootstrap/internal/xname"
+)
+
+// Strategies for resolving Merge conflicts.
+const (
+	// OnConflictError reports every conflict and fails the merge.
+	OnConflictError = "error"
+	// OnConflictFirstWins keeps the entry from the earliest input.
+	OnConflictFirstWins = "first-wins"
+	// OnConflictLastWins keeps the entry from the latest input.
+	OnConflictLastWins = "last-wins"
+)
+
+// Source is one input to Merge. Name identifies it in conflicts, usually
+// its path.
+type Source struct {
+	Name string
+	Doc  FileFormat
+}
+
+// Conflict is a clash between two entries found while merging: the same
+// xname with a different MAC or IP (Fields lists the differences), or one
+// MAC or IP used by two xnames. First and Second say where the entries came
+// from, in input order.
+type Conflict struct {
+	Field  string // "xname", "mac", or "ip"
+	Value  string
+	First  string
+	Second string
+	Fields []FieldChange
+}
+
+func (c Conflict) String() string {
+	s := fmt.Sprintf("%s %s: %s and %s", c.Field, c.Value, c.First, c.Second)
+	if len(c.Fields) > 0 {
+		parts := make([]string, 0, len(c.Fields))
+		for _, f := range c.Fields {
+			parts = append(parts, fmt.Sprintf("%s %s vs %s", f.Field, f.Old, f.New))
+		}
+		s += " differ (" + strings.Join(parts, ", ") + ")"
+	}
+	return s
+}
+
+// MergeResult is the outcome of Merge.
+type MergeResult struct {
+	Doc       FileFormat
+	Conflicts []Conflict
+}
+
+// located is an entry with where it came from: src indexes the inputs and
+// seq numbers every entry read.
+type located struct {
+	e       Entry
+	section string
+	source  string
+	src     int
+	seq     int
+}
+
+func (l located) String() string {
+	return fmt.Sprintf("%s %s (%s)", l.section, l.e.Xname, l.source)
+}
+
+// Merge concatenates the bmcs[], nodes[], and controllers[] of sources. Identical copies of
+// an entry collapse into one. The same xname with a different MAC or IP, and
+// one MAC or IP claimed by two xnames (across all sections), are conflicts:
+// with OnConflictFirstWins the entry read first is kept and the other one is
+// dropped, with OnConflictLastWins the entry read last is kept, and with
+// OnConflictError every conflict is reported and an error returned. Xnames
+// are normalized and each section is sorted by xname; reserved[] is the
+// union of the inputs' lists.
+func Merge(sources []Source, onConflict string) (MergeResult, error) {
+	var res MergeResult
+	switch onConflict {
+	case OnConflictError, OnConflictFirstWins, OnConflictLastWins:
+	default:
+		return res, fmt.Errorf("unknown conflict strategy %q (want %s, %s, or %s)", onConflict, OnConflictError, OnConflictFirstWins, OnConflictLastWins)
+	}
+
+	// Collapse entries by xname within each section
+	var all []located
+	seq := 0
+	for _, section := range []string{"bmcs", "nodes", "controllers"} {
+		byXname := map[string]int{} // xname -> index in all
+		for n, src := range sources {
+			list := src.Doc.BMCs
+			switch section {
+			case "nodes":
+				list = src.Doc.Nodes
+			case "controllers":
+				list = src.Doc.Controllers
+			}
+			for _, e := range list {
+				if x, err := xname.Normalize(e.Xname); err == nil {
+					e.Xname = x
+				} else if cc, _, ok := xname.ParseChassisBMC(e.Xname); ok {
+					e.Xname = cc
+				}
+				cur := located{e: e, section: section, source: src.Name, src: n, seq: seq}
+				seq++
+				i, ok := byXname[e.Xname]
+				if !ok || e.Xname == "" {
+					byXname[e.Xname] = len(all)
+					all = append(all, cur)
+					continue
+				}
+				prev := all[i]
+				fields := fieldChanges(prev.e, e)
+				if len(fields) == 0 {
+					continue
+				}
+				for j := range fields {
+					fields[j].Old, fields[j].New = valueOrNone(fields[j].Old), valueOrNone(fields[j].New)
+				}
+				res.Conflicts = append(res.Conflicts, Conflict{Field: "xname", Value: e.Xname, First: prev.source, Second: cur.source, Fields: fields})
+				if onConflict == OnConflictLastWins {
+					all[i] = cur
+				}
+			}
+		}
+	}
+
+	// One MAC or IP must not belong to two entries
+	order := slices.Clone(all)
+	slices.SortFunc(order, func(a, b located) int { return cmp.Or(cmp.Compare(a.src, b.src), cmp.Compare(a.seq, b.seq)) })
+	dropped := map[int]bool{} // by seq
+	claims := map[string]map[string]located{"mac": {}, "ip": {}}
+	for _, cur := range order {
+		for _, field := range []string{"mac", "ip"} {
+			value := cur.e.IP
+			if field == "mac" {
+				value = strings.ToLower(cur.e.MAC)
+			}
+			if value == "" {
+				continue
+			}
+			holder, ok := claims[field][value]
+			// A controller shares the MAC and IP of the bmcs[] entry it was
+			// discovered through
+			if ok && holder.e.Xname == cur.e.Xname && holder.section != cur.section {
+				continue
+			}
+			if ok && !dropped[holder.seq] {
+				res.Conflicts = append(res.Conflicts, Conflict{Field: field, Value: value, First: holder.String(), Second: cur.String()})
+				switch onConflict {
+				case OnConflictFirstWins:
+					dropped[cur.seq] = true
+				case OnConflictLastWins:
+					dropped[holder.seq] = true
+					claims[field][value] = cur
+				}
+				if dropped[cur.seq] {
+					break
+				}
+				continue
+			}
+			claims[field][value] = cur
+		}
+	}
+	if onConflict == OnConflictError && len(res.Conflicts) > 0 {
+		return res, fmt.Errorf("%d conflict(s) between the inputs", len(res.Conflicts))
+	}
+
+	res.Doc = FileFormat{BMCs: []Entry{}, Nodes: []Entry{}}
+	for _, l := range all {
+		if dropped[l.seq] {
+			continue
+		}
+		switch l.section {
+		case "bmcs":
+			res.Doc.BMCs = append(res.Doc.BMCs, l.e)
+		case "nodes":
+			res.Doc.Nodes = append(res.Doc.Nodes, l.e)
+		default:
+			res.Doc.Controllers = append(res.Doc.Controllers, l.e)
+		}
+	}
+	byXname := func(a, b Entry) int { return xname.Compare(a.Xname, b.Xname) }
+	slices.SortStableFunc(res.Doc.BMCs, byXname)
+	slices.SortStableFunc(res.Doc.Nodes, byXname)
+	slices.SortStableFunc(res.Doc.Controllers, byXname)
+
+	seen := map[string]bool{}
+	for _, src := range sources {
+		for _, r := range src.Doc.Reserved {
+			if !seen[r] {
+				seen[r] = true
+				res.Doc.Reserved = append(res.Doc.Reserved, r)
+			}
+		}
+	}
+	return res, nil
+}
+
+// valueOrNone renders an empty field in a conflict message.
+func valueOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// NodeMerge is the outcome of MergeNodes.
+type NodeMerge struct {
+	// Nodes holds the current entries followed by the old entries kept, in
+	// their old order.
+	Nodes []Entry
+	// Carried lists the old entries kept because their BMC is in
+	// failedBMCs.
+	Carried []Entry
+	// Orphans lists the old entries kept because their BMC is not in
+	// bmcs[], including entries whose xname is not a node xname.
+	Orphans []Entry
+}
+
+// MergeNodes merges the nodes found by a discovery run, current, into the
+// previous nodes[], old. The BMCs of bmcs that are not in failedBMCs
+// answered, so their nodes in current replace all of their old entries. The old
+// entries of a BMC in failedBMCs, which was not discovered this time, are
+// carried forward, and so are orphans, the old entries of a BMC missing
+// from bmcs; an old entry is dropped whenever current has its xname. Xnames are
+// compared in canonical form.
+func MergeNodes(old, current []Entry, failedBMCs []string, bmcs []Entry) NodeMerge {
+	failed := make(map[string]bool, len(failedBMCs))
+	for _, x := range failedBMCs {
+		failed[canonicalXname(x)] = true
+	}
+	known := make(map[string]bool, len(bmcs))
+	for _, b := range bmcs {
+		known[canonicalXname(b.Xname)] = true
+	}
+	found := make(map[string]bool, len(current))
+	for _, n := range current {
+		found[canonicalXname(n.Xname)] = true
+	}
+
+	m := NodeMerge{Nodes: slices.Clone(current)}
+	for _, n := range old {
+		if found[canonicalXname(n.Xname)] {
+			continue
+		}
+		bmc, ok := nodeBMC(n.Xname)
+		switch {
+		case !ok || !known[bmc]:
+			m.Orphans = append(m.Orphans, n)
+		case failed[bmc]:
+			m.Carried = append(m.Carried, n)
+		default:
+			continue
+		}
+		found[canonicalXname(n.Xname)] = true
+		m.Nodes = append(m.Nodes, n)
+	}
+	return m
+}
+
+// canonicalXname returns the canonical form of x, or x itself when it is
+// not an xname.
+func canonicalXname(x string) string {
+	if n, err := xname.Normalize(x); err == nil {
+		return n
+	}
+	return x
+}
+
+// nodeBMC returns the canonical xname of the BMC that node x belongs to. ok
+// is false when x is not a node xname.
+func nodeBMC(x string) (string, bool) {
+	p, err := xname.Parse(x)
+	if err != nil || p.Kind != xname.KindNode {
+		return "", false
+	}
+	b, _ := p.Ancestor(xname.KindBMC)
+	return b.String(), true
+}
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mergeSources() []Source {
+	return []Source{
+		{Name: "rack1.yaml", Doc: FileFormat{
+			BMCs: []Entry{{Xname: "x9000c1s0b0", MAC: "02:00:00:00:00:01", IP: "192.168.100.1"}},
+			Nodes: []Entry{
+				{Xname: "x9000c1s10b0n0", MAC: "00:40:a6:00:00:10", IP: "10.42.0.10"},
+				{Xname: "x9000c1s2b0n0", MAC: "00:40:a6:00:00:02", IP: "10.42.0.2"},
+			},
+			Reserved: []string{"10.42.0.200"},
+		}},
+		{Name: "rack2.yaml", Doc: FileFormat{
+			BMCs: []Entry{{Xname: "X9000C1S0B0", MAC: "02:00:00:00:00:01", IP: "192.168.100.1"}}, // same BMC
+			Nodes: []Entry{
+				{Xname: "x9000c1s2b0n0", MAC: "00:40:a6:00:00:22", IP: "10.42.0.2"},  // new MAC
+				{Xname: "x9000c1s3b0n0", MAC: "00:40:A6:00:00:10", IP: "10.42.0.3"},  // MAC of s10
+				{Xname: "x9000c1s4b0n0", MAC: "00:40:a6:00:00:04", IP: "10.42.0.10"}, // IP of s10
+			},
+			Reserved: []string{"10.42.0.200", "10.42.0.250"},
+		}},
+	}
+}
+
+func xnames(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Xname)
+	}
+	return out
+}
+
+func TestMergeFirstWins(t *testing.T) {
+	res, err := Merge(mergeSources(), OnConflictFirstWins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := xnames(res.Doc.BMCs); !reflect.DeepEqual(got, []string{"x9000c1s0b0"}) {
+		t.Errorf("BMCs = %v", got)
+	}
+	if got := xnames(res.Doc.Nodes); !reflect.DeepEqual(got, []string{"x9000c1s2b0n0", "x9000c1s10b0n0"}) {
+		t.Errorf("Nodes = %v", got)
+	}
+	if mac := res.Doc.Nodes[0].MAC; mac != "00:40:a6:00:00:02" {
+		t.Errorf("x9000c1s2b0n0 MAC = %s, want the first file's", mac)
+	}
+	if !reflect.DeepEqual(res.Doc.Reserved, []string{"10.42.0.200", "10.42.0.250"}) {
+		t.Errorf("Reserved = %v", res.Doc.Reserved)
+	}
+	var got []string
+	for _, c := range res.Conflicts {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"xname x9000c1s2b0n0: rack1.yaml and rack2.yaml differ (mac 00:40:a6:00:00:02 vs 00:40:a6:00:00:22)",
+		"mac 00:40:a6:00:00:10: nodes x9000c1s10b0n0 (rack1.yaml) and nodes x9000c1s3b0n0 (rack2.yaml)",
+		"ip 10.42.0.10: nodes x9000c1s10b0n0 (rack1.yaml) and nodes x9000c1s4b0n0 (rack2.yaml)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMergeLastWins(t *testing.T) {
+	res, err := Merge(mergeSources(), OnConflictLastWins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// s10 loses its MAC to s3 and is dropped, so s4 keeps the IP uncontested
+	want := []string{"x9000c1s2b0n0", "x9000c1s3b0n0", "x9000c1s4b0n0"}
+	if got := xnames(res.Doc.Nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("Nodes = %v, want %v", got, want)
+	}
+	if mac := res.Doc.Nodes[0].MAC; mac != "00:40:a6:00:00:22" {
+		t.Errorf("x9000c1s2b0n0 MAC = %s, want the last file's", mac)
+	}
+	if len(res.Conflicts) != 2 {
+		t.Errorf("got %d conflicts, want 2: %v", len(res.Conflicts), res.Conflicts)
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	res, err := Merge(mergeSources(), OnConflictError)
+	if err == nil || len(res.Conflicts) != 3 {
+		t.Fatalf("Merge = %d conflicts, %v; want 3 and an error", len(res.Conflicts), err)
+	}
+	// Inputs without conflicts merge cleanly
+	sources := mergeSources()[:1]
+	sources = append(sources, sources[0])
+	res, err = Merge(sources, OnConflictError)
+	if err != nil || len(res.Doc.Nodes) != 2 {
+		t.Errorf("merging a file with itself = %+v, %v", res, err)
+	}
+	if _, err := Merge(sources, "newest"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestMergeNodes(t *testing.T) {
+	bmcs := []Entry{{Xname: "x9000c1s0b0"}, {Xname: "X9000C1S1B0"}, {Xname: "x9000c1s2b0"}}
+	old := []Entry{
+		{Xname: "x9000c1s0b0n0", IP: "10.42.0.1"},
+		{Xname: "x9000c1s0b0n1", IP: "10.42.0.2"},
+		{Xname: "x9000c1s1b0n0", IP: "10.42.0.3"},
+		{Xname: "x9000c1s7b0n0", IP: "10.42.0.4"}, // BMC removed from bmcs[]
+		{Xname: "compute-01", IP: "10.42.0.5"},    // not a node xname
+		{Xname: "x9000c1s2b0n0", IP: "10.42.0.6"},
+	}
+	tests := []struct {
+		name    string
+		current []Entry
+		failed  []string
+		nodes   []string
+		carried []string
+		orphans []string
+	}{
+		{
+			name:    "every BMC answered",
+			current: []Entry{{Xname: "x9000c1s0b0n0"}, {Xname: "x9000c1s1b0n0"}, {Xname: "x9000c1s2b0n0"}},
+			nodes:   []string{"x9000c1s0b0n0", "x9000c1s1b0n0", "x9000c1s2b0n0", "x9000c1s7b0n0", "compute-01"},
+			orphans: []string{"x9000c1s7b0n0", "compute-01"},
+		},
+		{
+			name:    "failed BMC keeps its nodes",
+			current: []Entry{{Xname: "x9000c1s0b0n0"}, {Xname: "x9000c1s2b0n0"}},
+			failed:  []string{"x9000c1s1b0"},
+			nodes:   []string{"x9000c1s0b0n0", "x9000c1s2b0n0", "x9000c1s1b0n0", "x9000c1s7b0n0", "compute-01"},
+			carried: []string{"x9000c1s1b0n0"},
+			orphans: []string{"x9000c1s7b0n0", "compute-01"},
+		},
+		{
+			name:    "failed xnames are compared in canonical form",
+			current: []Entry{{Xname: "x9000c1s1b0n0"}, {Xname: "x9000c1s2b0n0"}},
+			failed:  []string{"X9000C1S0B0"},
+			nodes:   []string{"x9000c1s1b0n0", "x9000c1s2b0n0", "x9000c1s0b0n0", "x9000c1s0b0n1", "x9000c1s7b0n0", "compute-01"},
+			carried: []string{"x9000c1s0b0n0", "x9000c1s0b0n1"},
+			orphans: []string{"x9000c1s7b0n0", "compute-01"},
+		},
+		{
+			name:    "answering BMC replaces all its entries",
+			current: []Entry{{Xname: "x9000c1s0b0n1"}, {Xname: "x9000c1s1b0n0"}, {Xname: "x9000c1s2b0n0"}},
+			nodes:   []string{"x9000c1s0b0n1", "x9000c1s1b0n0", "x9000c1s2b0n0", "x9000c1s7b0n0", "compute-01"},
+			orphans: []string{"x9000c1s7b0n0", "compute-01"},
+		},
+		{
+			name:    "rediscovered node of a failed BMC is not duplicated",
+			current: []Entry{{Xname: "x9000c1s0b0n0"}, {Xname: "x9000c1s2b0n0"}},
+			failed:  []string{"x9000c1s0b0"},
+			nodes:   []string{"x9000c1s0b0n0", "x9000c1s2b0n0", "x9000c1s0b0n1", "x9000c1s7b0n0", "compute-01"},
+			carried: []string{"x9000c1s0b0n1"},
+			orphans: []string{"x9000c1s7b0n0", "compute-01"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := MergeNodes(old, tc.current, tc.failed, bmcs)
+			if got := xnames(m.Nodes); !reflect.DeepEqual(got, tc.nodes) {
+				t.Errorf("nodes = %v, want %v", got, tc.nodes)
+			}
+			if got := xnames(m.Carried); !reflect.DeepEqual(got, tc.carried) {
+				t.Errorf("carried = %v, want %v", got, tc.carried)
+			}
+			if got := xnames(m.Orphans); !reflect.DeepEqual(got, tc.orphans) {
+				t.Errorf("orphans = %v, want %v", got, tc.orphans)
+			}
+		})
+	}
+}
@@ -10,10 +10,57 @@ type Entry struct {
 	Xname string `yaml:"xname"`
 	MAC   string `yaml:"mac"`
 	IP    string `yaml:"ip"`
+	// Host is the DNS name a BMC without an IP is reached at; its xname is
+	// resolved when it has neither.
+	Host string `yaml:"host,omitempty"`
+	// Serial is the hardware serial number, when known.
+	Serial string `yaml:"serial,omitempty"`
+	// Model and SKU describe node hardware (discover --collect-details).
+	Model string `yaml:"model,omitempty"`
+	SKU   string `yaml:"sku,omitempty"`
+	// FirmwareVersion is the BMC's Manager firmware version
+	// (discover --collect-details).
+	FirmwareVersion string `yaml:"firmware_version,omitempty"`
+	// Static pins IP: discovery never reallocates it, even if the MAC changes.
+	Static bool `yaml:"static,omitempty"`
+	// NID is the node ID. On a BMC entry it is the NID of the first node the
+	// BMC manages.
+	NID int `yaml:"nid,omitempty"`
+	// Role is the node role, e.g. "compute" or "management".
+	Role string `yaml:"role,omitempty"`
+	// TLSFingerprint is the SHA-256 fingerprint of the BMC's TLS
+	// certificate, pinned by discover --pin-identity (BMC entries only).
+	// Connections to a BMC presenting another certificate are refused.
+	TLSFingerprint string `yaml:"tls_fingerprint,omitempty"`
+	// Firmware maps FirmwareInventory targets to the version last recorded
+	// by `firmware status --record` (BMC entries only).
+	Firmware map[string]string `yaml:"firmware,omitempty"`
+	// Extra holds keys this tool does not know (e.g. location, notes) so
+	// that rewriting an entry keeps them.
+	Extra map[string]any `yaml:",inline"`
+}
+
+// Address is where a BMC entry is reached: its IP, else its host, else its
+// xname.
+func (e Entry) Address() string {
+	switch {
+	case e.IP != "":
+		return e.IP
+	case e.Host != "":
+		return e.Host
+	}
+	return e.Xname
 }
 
 // FileFormat is the root YAML structure with bmcs and nodes.
 type FileFormat struct {
 	BMCs  []Entry `yaml:"bmcs"`
 	Nodes []Entry `yaml:"nodes"`
+	// Controllers holds the chassis controllers (cC xnames such as
+	// x9000c1b0) with their management NIC (discover
+	// --include-chassis-controllers).
+	Controllers []Entry `yaml:"controllers,omitempty"`
+	// Reserved lists IPs and ranges (e.g. "10.42.0.50-10.42.0.99") that
+	// discovery must never allocate.
+	Reserved []string `yaml:"reserved,omitempty"`
 }
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestStaticSurvivesRoundTrip(t *testing.T) {
+	in := FileFormat{Nodes: []Entry{
+		{Xname: "x9000c1s0b0n0", MAC: "00:40:a6:88:d9:01", IP: "10.42.0.1", Static: true},
+		{Xname: "x9000c1s0b0n1", MAC: "00:40:a6:88:d9:02", IP: "10.42.0.2"},
+	}}
+	raw, err := yaml.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(raw), "static:") != 1 {
+		t.Errorf("static should only be written when set:\n%s", raw)
+	}
+	var out FileFormat
+	if err := yaml.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Nodes[0].Static || out.Nodes[1].Static {
+		t.Errorf("static flag lost in round trip: %+v", out.Nodes)
+	}
+}
+
+func TestNIDAndRoleOptional(t *testing.T) {
+	var doc FileFormat
+	if err := yaml.Unmarshal([]byte("nodes:\n- xname: x9000c1s0b0n0\n  mac: 00:40:a6:88:d9:01\n  ip: 10.42.0.1\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if n := doc.Nodes[0]; n.NID != 0 || n.Role != "" {
+		t.Errorf("unexpected nid/role: %+v", n)
+	}
+	raw, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "nid:") || strings.Contains(string(raw), "role:") {
+		t.Errorf("empty nid/role should be omitted:\n%s", raw)
+	}
+
+	doc.Nodes[0].NID, doc.Nodes[0].Role = 7, "compute"
+	raw, err = yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out FileFormat
+	if err := yaml.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out.Nodes[0], doc.Nodes[0]) {
+		t.Errorf("round trip = %+v, want %+v", out.Nodes[0], doc.Nodes[0])
+	}
+}
+
+func TestFirmwareRoundTrip(t *testing.T) {
+	// A file without firmware maps is written back byte for byte
+	in := `bmcs:
+    - xname: x9000c1s0b0
+      mac: "02:23:28:01:30:00"
+      ip: 192.168.100.1
+nodes:
+    - xname: x9000c1s0b0n0
+      mac: 00:40:a6:88:d9:01
+      ip: 10.42.0.1
+`
+	var doc FileFormat
+	if err := yaml.Unmarshal([]byte(in), &doc); err != nil {
+		t.Fatal(err)
+	}
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Errorf("round trip changed the file:\n%s", out)
+	}
+
+	doc.BMCs[0].Firmware = map[string]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC": "nc.1.9.8"}
+	out, err = yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back FileFormat
+	if err := yaml.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, doc) {
+		t.Errorf("firmware lost in round trip: %+v", back.BMCs)
+	}
+}
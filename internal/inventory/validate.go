@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"bootstrap/internal/xname"
+)
+
+// Severity classifies a validation finding.
+type Severity int
+
+const (
+	// Warning findings do not make a file invalid on their own.
+	Warning Severity = iota
+	// Error findings make a file invalid.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "ERROR"
+	}
+	return "WARN"
+}
+
+// Violation is one problem found in an inventory file.
+type Violation struct {
+	Severity Severity
+	Section  string // "bmcs", "nodes", or "controllers"
+	Index    int    // position within Section
+	Xname    string
+	Message  string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s[%d] %s: %s", v.Severity, v.Section, v.Index, v.Xname, v.Message)
+}
+
+var macPattern = regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`)
+
+// wantKind is the kind of xname each section holds.
+var wantKind = map[string]xname.Kind{"bmcs": xname.KindBMC, "nodes": xname.KindNode}
+
+// Validate checks xname syntax, form, and kind, MAC format and uniqueness across all
+// sections, IP parseability and uniqueness, and, when subnet is non-empty,
+// that node IPs fall inside it. Missing MACs and IPs are reported as
+// warnings. Findings are returned in file order.
+func Validate(doc FileFormat, subnet string) ([]Violation, error) {
+	var prefix netip.Prefix
+	if subnet != "" {
+		p, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet %q: %w", subnet, err)
+		}
+		prefix = p.Masked()
+	}
+
+	var out []Violation
+	xnames := map[string]string{} // xname -> first "section[i]"
+	macs := map[string]string{}
+	ips := map[string]string{}
+	check := func(section string, list []Entry) {
+		for i, e := range list {
+			where := fmt.Sprintf("%s[%d]", section, i)
+			add := func(sev Severity, format string, args ...any) {
+				out = append(out, Violation{Severity: sev, Section: section, Index: i, Xname: e.Xname, Message: fmt.Sprintf(format, args...)})
+			}
+			// A controller discovered through its bmcs[] entry shares that
+			// entry's xname, and usually its MAC and IP
+			duplicate := func(first string) bool {
+				return section != "controllers" || first != xnames[e.Xname] || !strings.HasPrefix(first, "bmcs[")
+			}
+
+			cc, _, isCC := xname.ParseChassisBMC(e.Xname)
+			switch {
+			case e.Xname == "":
+				add(Error, "missing xname")
+			case section == "controllers" && !isCC:
+				add(Error, "%s is not a chassis controller xname (e.g. x9000c1b0)", e.Xname)
+			case isCC && section == "nodes":
+				add(Error, "%s is a chassis controller xname, want a node xname", e.Xname)
+			case isCC:
+				// Chassis controllers may also be listed in bmcs[] to be discovered
+				if cc != e.Xname {
+					add(Error, "xname %q is not in canonical form (want %s)", e.Xname, cc)
+				}
+			default:
+				x, err := xname.Parse(e.Xname)
+				if err != nil {
+					add(Error, "invalid xname %q", e.Xname)
+					break
+				}
+				if !xname.IsValid(e.Xname) {
+					add(Error, "xname %q is not in canonical form (want %s)", e.Xname, x)
+				}
+				if want := wantKind[section]; x.Kind != want {
+					add(Error, "%s is a %s xname, want a %s xname", x, x.Kind, want)
+				}
+			}
+			if e.Xname != "" {
+				if first, ok := xnames[e.Xname]; ok && duplicate(first) {
+					add(Error, "duplicate xname (also %s)", first)
+				} else if !ok {
+					xnames[e.Xname] = where
+				}
+			}
+
+			switch {
+			case e.MAC == "":
+				add(Warning, "missing MAC")
+			case !macPattern.MatchString(e.MAC):
+				add(Error, "malformed MAC %q", e.MAC)
+			default:
+				mac := strings.ToLower(e.MAC)
+				if first, ok := macs[mac]; ok {
+					if duplicate(first) {
+						add(Error, "duplicate MAC %s (also %s)", mac, first)
+					}
+				} else {
+					macs[mac] = where
+				}
+			}
+
+			if e.IP == "" {
+				add(Warning, "missing IP")
+				continue
+			}
+			addr, err := netip.ParseAddr(e.IP)
+			if err != nil {
+				add(Error, "unparseable IP %q", e.IP)
+				continue
+			}
+			if first, ok := ips[addr.String()]; ok {
+				if duplicate(first) {
+					add(Error, "duplicate IP %s (also %s)", addr, first)
+				}
+			} else {
+				ips[addr.String()] = where
+			}
+			if section == "nodes" && prefix.IsValid() && !prefix.Contains(addr) {
+				add(Error, "IP %s outside subnet %s", addr, prefix)
+			}
+		}
+	}
+	check("bmcs", doc.BMCs)
+	check("nodes", doc.Nodes)
+	check("controllers", doc.Controllers)
+	return out, nil
+}
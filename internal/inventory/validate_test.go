@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateClean(t *testing.T) {
+	doc := FileFormat{
+		BMCs:  []Entry{{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "192.168.100.1"}},
+		Nodes: []Entry{{Xname: "x9000c1s0b0n0", MAC: "00:40:a6:88:d9:01", IP: "10.42.0.1"}},
+	}
+	got, err := Validate(doc, "10.42.0.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no violations, got %v", got)
+	}
+}
+
+func TestValidateFindsEveryProblem(t *testing.T) {
+	doc := FileFormat{
+		BMCs: []Entry{
+			{Xname: "x9000c1s0b0", MAC: "02:23:28:01:30:00", IP: "10.42.0.1"},
+			{Xname: "bmc-10-0-0-7", MAC: "02:23:28:01:30:zz", IP: "192.168.100.2"},
+		},
+		Nodes: []Entry{
+			{Xname: "x9000c1s0b0n0", MAC: "02:23:28:01:30:00", IP: "10.42.0.1"},
+			{Xname: "x9000c1s0b0n0", MAC: "", IP: "10.43.0.9"},
+			{Xname: "x9000c1s0b0n1", MAC: "00:40:a6:88:d9:03", IP: "not-an-ip"},
+			{Xname: "x9000c1s0b1n0", MAC: "00:40:a6:88:d9:04"},
+		},
+	}
+	got, err := Validate(doc, "10.42.0.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, v := range got {
+		lines = append(lines, v.String())
+	}
+	want := []string{
+		`ERROR: bmcs[1] bmc-10-0-0-7: invalid xname "bmc-10-0-0-7"`,
+		`ERROR: bmcs[1] bmc-10-0-0-7: malformed MAC "02:23:28:01:30:zz"`,
+		`ERROR: nodes[0] x9000c1s0b0n0: duplicate MAC 02:23:28:01:30:00 (also bmcs[0])`,
+		`ERROR: nodes[0] x9000c1s0b0n0: duplicate IP 10.42.0.1 (also bmcs[0])`,
+		`ERROR: nodes[1] x9000c1s0b0n0: duplicate xname (also nodes[0])`,
+		`WARN: nodes[1] x9000c1s0b0n0: missing MAC`,
+		`ERROR: nodes[1] x9000c1s0b0n0: IP 10.43.0.9 outside subnet 10.42.0.0/24`,
+		`ERROR: nodes[2] x9000c1s0b0n1: unparseable IP "not-an-ip"`,
+		`WARN: nodes[3] x9000c1s0b1n0: missing IP`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("violations:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateRejectsBadSubnet(t *testing.T) {
+	if _, err := Validate(FileFormat{}, "10.42.0.0/99"); err == nil {
+		t.Fatal("expected error for invalid subnet")
+	}
+}
+
+func TestValidateXnameFormAndKind(t *testing.T) {
+	doc := FileFormat{
+		BMCs: []Entry{
+			{Xname: "X9000C1S0B0", MAC: "02:23:28:01:30:00", IP: "192.168.100.1"},
+			{Xname: "x9000c1s1", MAC: "02:23:28:01:31:00", IP: "192.168.100.2"},
+		},
+		Nodes: []Entry{{Xname: "x9000c1s0b0", MAC: "00:40:a6:88:d9:01", IP: "10.42.0.1"}},
+	}
+	got, err := Validate(doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, v := range got {
+		lines = append(lines, v.String())
+	}
+	want := []string{
+		`ERROR: bmcs[0] X9000C1S0B0: xname "X9000C1S0B0" is not in canonical form (want x9000c1s0b0)`,
+		`ERROR: bmcs[1] x9000c1s1: x9000c1s1 is a slot xname, want a BMC xname`,
+		`ERROR: nodes[0] x9000c1s0b0: x9000c1s0b0 is a BMC xname, want a node xname`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("violations:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateControllers(t *testing.T) {
+	doc := FileFormat{
+		BMCs: []Entry{{Xname: "x9000c1b0", MAC: "02:03:e8:00:00:00", IP: "192.168.100.10"}},
+		Controllers: []Entry{
+			{Xname: "x9000c1b0", MAC: "02:03:e8:00:00:00", IP: "192.168.100.10"},
+			{Xname: "x9000c1s0b0", MAC: "02:03:e8:00:01:00", IP: "192.168.100.11"},
+			{Xname: "x9000c3b0", MAC: "02:03:e8:00:00:00", IP: "192.168.100.12"},
+		},
+	}
+	got, err := Validate(doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, v := range got {
+		lines = append(lines, v.String())
+	}
+	want := []string{
+		`ERROR: controllers[1] x9000c1s0b0: x9000c1s0b0 is not a chassis controller xname (e.g. x9000c1b0)`,
+		`ERROR: controllers[2] x9000c3b0: duplicate MAC 02:03:e8:00:00:00 (also bmcs[0])`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("violations:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
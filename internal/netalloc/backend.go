@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package netalloc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	ipam "github.com/metal-stack/go-ipam"
+)
+
+// Storage backends for ConfigureBackend.
+const (
+	// BackendMemory keeps allocations in the process only (the default).
+	BackendMemory = "memory"
+	// BackendFile keeps allocations in the JSON file named by IPAM_FILE.
+	BackendFile = "file"
+	// BackendRedis keeps allocations in the Redis server at
+	// IPAM_REDIS_ADDR.
+	BackendRedis = "redis"
+	// BackendPostgres keeps allocations in the database described by the
+	// IPAM_POSTGRES_* variables.
+	BackendPostgres = "postgres"
+)
+
+// backendConfig is what ConfigureBackend selected. The storage is opened on
+// the first NewAllocator call, so commands that allocate nothing never
+// connect to it.
+var backendConfig struct {
+	mu      sync.Mutex
+	kind    string
+	env     map[string]string
+	storage ipam.Storage
+}
+
+// backendEnv lists the environment variables each backend reads, with
+// their defaults; an empty default marks a required variable.
+var backendEnv = map[string]map[string]string{
+	BackendMemory: {},
+	BackendFile:   {"IPAM_FILE": ""},
+	BackendRedis:  {"IPAM_REDIS_ADDR": "localhost:6379"},
+	BackendPostgres: {
+		"IPAM_POSTGRES_HOST":     "localhost",
+		"IPAM_POSTGRES_PORT":     "5432",
+		"IPAM_POSTGRES_USER":     "",
+		"IPAM_POSTGRES_PASSWORD": "",
+		"IPAM_POSTGRES_DB":       "",
+		"IPAM_POSTGRES_SSLMODE":  string(ipam.SSLModeRequire),
+	},
+}
+
+// ConfigureBackend selects where every later Allocator keeps its
+// allocations: BackendMemory (the default when kind is empty), or a
+// BackendFile, BackendRedis, or BackendPostgres storage shared with other
+// runs. The connection details come from the environment and are checked
+// here; the storage itself is opened by the first NewAllocator call.
+func ConfigureBackend(kind string) error {
+	if kind == "" {
+		kind = BackendMemory
+	}
+	vars, ok := backendEnv[kind]
+	if !ok {
+		return fmt.Errorf("unknown IPAM backend %q (want memory, file, redis, or postgres)", kind)
+	}
+	env := make(map[string]string, len(vars))
+	for name, def := range vars {
+		v := os.Getenv(name)
+		if v == "" {
+			v = def
+		}
+		// The password alone may be empty, e.g. for trust authentication
+		if v == "" && name != "IPAM_POSTGRES_PASSWORD" {
+			return fmt.Errorf("IPAM backend %s requires %s", kind, name)
+		}
+		env[name] = v
+	}
+	if addr, ok := env["IPAM_REDIS_ADDR"]; ok {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("IPAM_REDIS_ADDR %q: want host:port", addr)
+		}
+	}
+	backendConfig.mu.Lock()
+	defer backendConfig.mu.Unlock()
+	backendConfig.kind, backendConfig.env, backendConfig.storage = kind, env, nil
+	return nil
+}
+
+// newIpamer returns the Ipamer for a new Allocator: a private in-memory one
+// by default, or one on the configured shared storage, opened once.
+func newIpamer(ctx context.Context) (ipam.Ipamer, error) {
+	backendConfig.mu.Lock()
+	defer backendConfig.mu.Unlock()
+	kind, env := backendConfig.kind, backendConfig.env
+	if kind == "" || kind == BackendMemory {
+		return ipam.New(ctx), nil
+	}
+	if backendConfig.storage == nil {
+		var s ipam.Storage
+		var err error
+		switch kind {
+		case BackendFile:
+			s = ipam.NewLocalFile(ctx, env["IPAM_FILE"])
+		case BackendRedis:
+			host, port, _ := net.SplitHostPort(env["IPAM_REDIS_ADDR"])
+			s, err = ipam.NewRedis(ctx, host, port)
+		case BackendPostgres:
+			s, err = ipam.NewPostgresStorage(env["IPAM_POSTGRES_HOST"], env["IPAM_POSTGRES_PORT"], env["IPAM_POSTGRES_USER"],
+				env["IPAM_POSTGRES_PASSWORD"], env["IPAM_POSTGRES_DB"], ipam.SSLMode(env["IPAM_POSTGRES_SSLMODE"]))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("open %s IPAM backend: %w", kind, err)
+		}
+		backendConfig.storage = s
+	}
+	return ipam.NewWithStorage(backendConfig.storage), nil
+}
+
+// openPrefix returns the prefix for cidr, creating it unless the storage
+// already holds it from an earlier or concurrent run.
+func openPrefix(ctx context.Context, ipm ipam.Ipamer, cidr string) (*ipam.Prefix, error) {
+	if p, err := ipm.PrefixFrom(ctx, cidr); err == nil {
+		return p, nil
+	}
+	p, err := ipm.NewPrefix(ctx, cidr)
+	if err != nil {
+		// Another run may have created it in the meantime
+		if existing, ferr := ipm.PrefixFrom(ctx, cidr); ferr == nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("subnet %s: %w", cidr, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package netalloc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigureBackendChecksEnvironment(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureBackend("") })
+	t.Setenv("IPAM_FILE", "")
+	t.Setenv("IPAM_REDIS_ADDR", "")
+	t.Setenv("IPAM_POSTGRES_DB", "")
+	for _, tc := range []struct {
+		kind, env, value, want string
+	}{
+		{"etcd", "", "", "unknown IPAM backend"},
+		{BackendFile, "", "", "requires IPAM_FILE"},
+		{BackendRedis, "IPAM_REDIS_ADDR", "redis.example.com", "want host:port"},
+		{BackendPostgres, "IPAM_POSTGRES_USER", "ipam", "requires IPAM_POSTGRES_DB"},
+	} {
+		if tc.env != "" {
+			t.Setenv(tc.env, tc.value)
+		}
+		if err := ConfigureBackend(tc.kind); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.kind, err, tc.want)
+		}
+	}
+	if err := ConfigureBackend(""); err != nil {
+		t.Errorf("memory: %v", err)
+	}
+}
+
+// sharedBackendRuns checks that two runs on the configured backend see each
+// other's allocations: each ConfigureBackend call drops the open storage, as
+// a new process would.
+func sharedBackendRuns(t *testing.T, kind, cidr string) {
+	t.Helper()
+	t.Cleanup(func() { _ = ConfigureBackend("") })
+	if err := ConfigureBackend(kind); err != nil {
+		t.Fatal(err)
+	}
+	first, err := NewAllocator(cidr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip, err := first.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ConfigureBackend(kind); err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewAllocator(cidr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := second.Stats(); st.Used != 1 {
+		t.Errorf("second run Stats = %+v, want the first run's address counted", st)
+	}
+	next, err := second.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == ip {
+		t.Errorf("second run was given %s again", ip)
+	}
+	if err := second.Claim(ip); err == nil {
+		t.Errorf("second run claimed %s, held by the first", ip)
+	}
+	// The API is unchanged: releasing frees the address for everyone
+	if err := second.Release(ip); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Claim(ip); err != nil {
+		t.Errorf("claim after release: %v", err)
+	}
+}
+
+func TestFileBackendSharesAllocations(t *testing.T) {
+	t.Setenv("IPAM_FILE", filepath.Join(t.TempDir(), "ipam.json"))
+	sharedBackendRuns(t, BackendFile, "10.42.0.0/24")
+}
+
+// The Redis and Postgres tests need a server: they run only when
+// IPAM_REDIS_ADDR or IPAM_POSTGRES_DB points at one, each in a subnet of its
+// own so reruns and other users of the server do not collide.
+func TestRedisBackendSharesAllocations(t *testing.T) {
+	if os.Getenv("IPAM_REDIS_ADDR") == "" {
+		t.Skip("IPAM_REDIS_ADDR not set")
+	}
+	sharedBackendRuns(t, BackendRedis, testSubnet())
+}
+
+func TestPostgresBackendSharesAllocations(t *testing.T) {
+	if os.Getenv("IPAM_POSTGRES_DB") == "" {
+		t.Skip("IPAM_POSTGRES_DB not set")
+	}
+	sharedBackendRuns(t, BackendPostgres, testSubnet())
+}
+
+// testSubnet returns a /29 in 10.200.0.0/16 unlikely to be in use already.
+func testSubnet() string {
+	n := time.Now().UnixNano() / 1000 % (1 << 13)
+	return fmt.Sprintf("10.200.%d.%d/29", n>>5, n&31<<3)
+}
@@ -7,45 +7,148 @@ package netalloc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net"
+	"net/netip"
+	"strings"
+	"sync"
 
 	ipam "github.com/metal-stack/go-ipam"
 )
 
-// Allocator manages IP address allocation within a specified subnet.
+// Allocator manages IP address allocation within a specified subnet. It is
+// safe for concurrent use.
 type Allocator struct {
+	mu     sync.Mutex
 	ipm    ipam.Ipamer
 	prefix *ipam.Prefix
+	// used counts the addresses allocated or reserved through a
+	used int
 }
 
-// NewAllocator creates a new Allocator for the given CIDR subnet.
+// ErrOutsideSubnet is returned for an address outside the allocator's
+// subnet.
+var ErrOutsideSubnet = errors.New("not in subnet")
+
+// NewAllocator creates a new Allocator for the given CIDR subnet, kept in
+// the backend chosen by ConfigureBackend. On a shared backend the subnet and
+// the addresses other runs hold in it are reused.
 func NewAllocator(cidr string) (*Allocator, error) {
 	ctx := context.Background()
-	ipm := ipam.New(ctx)
-	pr, err := ipm.NewPrefix(ctx, cidr)
+	ipm, err := newIpamer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := openPrefix(ctx, ipm, cidr)
 	if err != nil {
 		return nil, err
 	}
 	// Previously we reserved the first host (gateway) to avoid collisions.
 	// Removing that reservation allows allocation of the .1 address when desired.
-	return &Allocator{ipm: ipm, prefix: pr}, nil
+	// The prefix holds its first address, and the broadcast address of an
+	// IPv4 subnet, itself; any other address was taken by an earlier run
+	held := 1
+	if p, err := netip.ParsePrefix(pr.Cidr); err == nil && p.Addr().Is4() && p.Bits() < 32 {
+		held = 2
+	}
+	return &Allocator{ipm: ipm, prefix: pr, used: int(pr.Usage().AcquiredIPs) - held}, nil
+}
+
+// checkIP returns an error for an unparseable ip or one outside the subnet,
+// wrapping ErrOutsideSubnet for the latter.
+func (a *Allocator) checkIP(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid IP %q", ip)
+	}
+	if !a.Contains(ip) {
+		return fmt.Errorf("%s is %w %s", ip, ErrOutsideSubnet, a.prefix.Cidr)
+	}
+	return nil
 }
 
 // Reserve marks the specified IP address as reserved in the allocator.
-func (a *Allocator) Reserve(ip string) {
-	_, _ = a.ipm.AcquireSpecificIP(context.Background(), a.prefix.Cidr, ip)
+// Reserving an address that is already reserved or allocated is not an
+// error. An address outside the subnet wraps ErrOutsideSubnet, and any other
+// error is a real failure.
+func (a *Allocator) Reserve(ip string) error {
+	if err := a.checkIP(ip); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err := a.ipm.AcquireSpecificIP(context.Background(), a.prefix.Cidr, ip)
+	switch {
+	case errors.Is(err, ipam.ErrAlreadyAllocated):
+		return nil
+	case err != nil:
+		return fmt.Errorf("reserve %s: %w", ip, err)
+	}
+	a.used++
+	return nil
 }
 
 // Next allocates and returns the next available IP address in the subnet.
 func (a *Allocator) Next() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	addr, err := a.ipm.AcquireIP(context.Background(), a.prefix.Cidr)
 	if err != nil {
 		return "", err
 	}
+	a.used++
 	return addr.IP.String(), nil
 }
 
+// ErrUnavailable is returned by Claim for an address that is already
+// allocated or reserved.
+var ErrUnavailable = errors.New("already allocated or reserved")
+
+// Claim allocates exactly ip. Unlike Reserve, it fails with ErrUnavailable
+// when ip is already taken.
+func (a *Allocator) Claim(ip string) error {
+	if err := a.checkIP(ip); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err := a.ipm.AcquireSpecificIP(context.Background(), a.prefix.Cidr, ip)
+	if errors.Is(err, ipam.ErrAlreadyAllocated) {
+		return fmt.Errorf("%s: %w", ip, ErrUnavailable)
+	}
+	if err != nil {
+		return fmt.Errorf("claim %s: %w", ip, err)
+	}
+	a.used++
+	return nil
+}
+
+// Offset returns the address n places after the subnet's network address,
+// e.g. 10.42.0.11 for n = 11 in 10.42.0.0/24. It fails when that address is
+// outside the subnet.
+func (a *Allocator) Offset(n int) (string, error) {
+	prefix, err := netip.ParsePrefix(a.prefix.Cidr)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 {
+		return "", fmt.Errorf("offset %d is negative", n)
+	}
+	b := prefix.Masked().Addr().AsSlice()
+	carry := uint64(n)
+	for i := len(b) - 1; i >= 0 && carry > 0; i-- {
+		sum := uint64(b[i]) + carry&0xff
+		b[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	if carry > 0 || !prefix.Contains(addr) {
+		return "", fmt.Errorf("offset %d is outside subnet %s", n, a.prefix.Cidr)
+	}
+	return addr.String(), nil
+}
+
 // Contains checks if the given IP address is within the allocator's subnet.
 func (a *Allocator) Contains(ip string) bool {
 	_, n, err := net.ParseCIDR(a.prefix.Cidr)
@@ -66,7 +169,7 @@ func (a *Allocator) ReserveUpTo(startIP string) error {
 		return nil
 	}
 	if !a.Contains(startIP) {
-		return fmt.Errorf("start IP %s is not in subnet %s", startIP, a.prefix.Cidr)
+		return fmt.Errorf("start IP %s is %w %s", startIP, ErrOutsideSubnet, a.prefix.Cidr)
 	}
 	// Parse the start IP
 	startParsed := net.ParseIP(startIP)
@@ -75,17 +178,22 @@ func (a *Allocator) ReserveUpTo(startIP string) error {
 	}
 
 	// Reserve IPs until we reach the start IP
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	for {
 		addr, err := a.ipm.AcquireIP(context.Background(), a.prefix.Cidr)
 		if err != nil {
 			// No more IPs available or error
 			return nil
 		}
+		a.used++
 		allocatedIP := net.ParseIP(addr.IP.String())
 		// Stop when we've reserved everything before startIP
 		if allocatedIP.Equal(startParsed) || isIPGreaterThan(allocatedIP, startParsed) {
 			// Release this IP since we don't want to reserve it
-			_, _ = a.ipm.ReleaseIP(context.Background(), addr)
+			if _, err := a.ipm.ReleaseIP(context.Background(), addr); err == nil {
+				a.used--
+			}
 			return nil
 		}
 	}
@@ -108,3 +216,87 @@ func isIPGreaterThan(ip1, ip2 net.IP) bool {
 	}
 	return false
 }
+
+// Release returns a previously reserved or allocated IP address to the pool.
+func (a *Allocator) Release(ip string) error {
+	if err := a.checkIP(ip); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.ipm.ReleaseIPFromPrefix(context.Background(), a.prefix.Cidr, ip); err != nil {
+		return err
+	}
+	a.used--
+	return nil
+}
+
+// Stats counts the addresses of a subnet: Total usable addresses, of which
+// Used are allocated or reserved and Free are not.
+type Stats struct {
+	Subnet string
+	Total  int
+	Used   int
+	Free   int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("subnet %s: %d/%d used", s.Subnet, s.Used, s.Total)
+}
+
+// Stats returns the address counts of the subnet. The network and broadcast
+// addresses of an IPv4 subnet are not counted, and Total is capped at 2^31.
+func (a *Allocator) Stats() Stats {
+	prefix, _ := netip.ParsePrefix(a.prefix.Cidr)
+	bits := prefix.Addr().BitLen() - prefix.Bits()
+	total := math.MaxInt32
+	if bits < 31 {
+		total = 1 << bits
+	}
+	if prefix.Addr().Is4() && bits > 1 {
+		total -= 2
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return Stats{Subnet: a.prefix.Cidr, Total: total, Used: a.used, Free: total - a.used}
+}
+
+// maxRangeSize bounds a single reservation range (a /16 worth of addresses).
+const maxRangeSize = 1 << 16
+
+// ParseReservations expands IPs and inclusive ranges such as
+// "10.42.0.50-10.42.0.99" into individual addresses. Each item may itself be
+// a comma-separated list.
+func ParseReservations(items []string) ([]string, error) {
+	var out []string
+	for _, item := range items {
+		for _, s := range strings.Split(item, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			lo, hi, isRange := strings.Cut(s, "-")
+			first, err := netip.ParseAddr(strings.TrimSpace(lo))
+			if err != nil {
+				return nil, fmt.Errorf("invalid reservation %q: %w", s, err)
+			}
+			last := first
+			if isRange {
+				if last, err = netip.ParseAddr(strings.TrimSpace(hi)); err != nil {
+					return nil, fmt.Errorf("invalid reservation %q: %w", s, err)
+				}
+				if last.Less(first) || first.Is4() != last.Is4() {
+					return nil, fmt.Errorf("invalid reservation %q: range end is before its start", s)
+				}
+			}
+			n := 0
+			for a := first; a.IsValid() && !last.Less(a); a = a.Next() {
+				if n++; n > maxRangeSize {
+					return nil, fmt.Errorf("reservation %q is too large (max %d addresses)", s, maxRangeSize)
+				}
+				out = append(out, a.String())
+			}
+		}
+	}
+	return out, nil
+}
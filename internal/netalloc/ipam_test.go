@@ -5,7 +5,9 @@
 package netalloc
 
 import (
+	"errors"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -93,3 +95,154 @@ func TestAllocatorReserveUpToInvalidIP(t *testing.T) {
 		t.Fatalf("expected error when reserving IP outside subnet")
 	}
 }
+
+func TestAllocatorRelease(t *testing.T) {
+	a, err := NewAllocator("10.0.2.0/29")
+	if err != nil {
+		t.Fatalf("NewAllocator: %v", err)
+	}
+	for _, ip := range []string{"10.0.2.1", "10.0.2.2"} {
+		if err := a.Reserve(ip); err != nil {
+			t.Fatalf("Reserve(%s): %v", ip, err)
+		}
+	}
+	if err := a.Release("10.0.2.1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if ip, _ := a.Next(); ip != "10.0.2.1" {
+		t.Fatalf("got %s want released 10.0.2.1", ip)
+	}
+}
+
+func TestAllocatorReserveErrors(t *testing.T) {
+	a, err := NewAllocator("10.0.3.0/29")
+	if err != nil {
+		t.Fatalf("NewAllocator: %v", err)
+	}
+	if err := a.Reserve("10.0.3.2"); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := a.Reserve("10.0.3.2"); err != nil {
+		t.Fatalf("reserving twice should be a no-op, got %v", err)
+	}
+	if err := a.Reserve("10.0.4.2"); err == nil {
+		t.Fatal("expected error for IP outside the subnet")
+	}
+	if err := a.Reserve("10.0.3.x"); err == nil {
+		t.Fatal("expected error for malformed IP")
+	}
+}
+
+func TestParseReservations(t *testing.T) {
+	got, err := ParseReservations([]string{"10.42.0.50-10.42.0.53, 10.42.0.200", "10.42.0.9"})
+	if err != nil {
+		t.Fatalf("ParseReservations: %v", err)
+	}
+	want := "10.42.0.50,10.42.0.51,10.42.0.52,10.42.0.53,10.42.0.200,10.42.0.9"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("got %v want %s", got, want)
+	}
+	for _, bad := range []string{"10.42.0.300", "10.42.0.9-10.42.0.1", "10.0.0.0-10.2.0.0", "10.42.0.1-"} {
+		if _, err := ParseReservations([]string{bad}); err == nil {
+			t.Errorf("ParseReservations(%q): expected error", bad)
+		}
+	}
+}
+
+func TestAllocatorOffsetAndClaim(t *testing.T) {
+	a, err := NewAllocator("10.0.0.0/23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, want := range map[int]string{11: "10.0.0.11", 300: "10.0.1.44"} {
+		if got, err := a.Offset(n); err != nil || got != want {
+			t.Errorf("Offset(%d) = %s, %v; want %s", n, got, err, want)
+		}
+	}
+	if _, err := a.Offset(512); err == nil {
+		t.Error("expected an error for an offset past the subnet")
+	}
+
+	if err := a.Claim("10.0.0.11"); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if err := a.Claim("10.0.0.11"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("second Claim = %v, want ErrUnavailable", err)
+	}
+	if err := a.Reserve("10.0.0.12"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Claim("10.0.0.12"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Claim of a reserved IP = %v, want ErrUnavailable", err)
+	}
+	if err := a.Claim("10.0.2.1"); err == nil || errors.Is(err, ErrUnavailable) {
+		t.Errorf("Claim outside the subnet = %v", err)
+	}
+}
+
+func TestAllocatorConcurrentNext(t *testing.T) {
+	a, err := NewAllocator("10.0.5.0/24")
+	if err != nil {
+		t.Fatalf("NewAllocator: %v", err)
+	}
+	const workers, each = 8, 25
+	ips := make(chan string, workers*each)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range each {
+				ip, err := a.Next()
+				if err != nil {
+					t.Errorf("Next: %v", err)
+					return
+				}
+				ips <- ip
+			}
+		}()
+	}
+	wg.Wait()
+	close(ips)
+	seen := map[string]bool{}
+	for ip := range ips {
+		if seen[ip] {
+			t.Fatalf("%s allocated twice", ip)
+		}
+		seen[ip] = true
+	}
+	if len(seen) != workers*each {
+		t.Fatalf("got %d addresses, want %d", len(seen), workers*each)
+	}
+	st := a.Stats()
+	if st.Used != workers*each || st.Total != 254 || st.Free != 254-workers*each {
+		t.Errorf("Stats = %+v", st)
+	}
+	if got, want := st.String(), "subnet 10.0.5.0/24: 200/254 used"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestAllocatorDoubleReserveAndStats(t *testing.T) {
+	a, err := NewAllocator("10.0.6.0/29")
+	if err != nil {
+		t.Fatalf("NewAllocator: %v", err)
+	}
+	for range 2 {
+		if err := a.Reserve("10.0.6.3"); err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+	}
+	if st := a.Stats(); st.Used != 1 || st.Total != 6 || st.Free != 5 {
+		t.Errorf("Stats after double reserve = %+v", st)
+	}
+	if err := a.Reserve("10.0.7.3"); !errors.Is(err, ErrOutsideSubnet) {
+		t.Errorf("Reserve outside the subnet = %v, want ErrOutsideSubnet", err)
+	}
+	if err := a.Release("10.0.6.3"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if st := a.Stats(); st.Used != 0 {
+		t.Errorf("Stats after release = %+v", st)
+	}
+}
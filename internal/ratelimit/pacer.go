@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+)
+
+// Pacer spaces out the hosts of a fan-out, where a Limiter spaces out
+// requests: Stagger between the launch of one host and the next, and Pause
+// once every Every hosts have completed. A nil *Pacer does not pace.
+type Pacer struct {
+	stagger time.Duration
+	pause   time.Duration
+	every   int
+
+	mu         sync.Mutex
+	next       time.Time // earliest launch allowed by the stagger
+	pauseUntil time.Time // no launch before this after a batch completed
+	done       int
+}
+
+// NewPacer returns a Pacer for the given delays, pausing after every every
+// completions (at least 1), or nil when both delays are zero.
+func NewPacer(stagger, pause time.Duration, every int) *Pacer {
+	if stagger <= 0 && pause <= 0 {
+		return nil
+	}
+	return &Pacer{stagger: stagger, pause: pause, every: max(1, every)}
+}
+
+// Start blocks until the next host may be launched or ctx is done, in which
+// case it returns ctx's error.
+func (p *Pacer) Start(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	now := time.Now()
+	at, why := now, ""
+	if p.next.After(at) {
+		at, why = p.next, "stagger"
+	}
+	if p.pauseUntil.After(at) {
+		at, why = p.pauseUntil, "batch pause"
+	}
+	p.next = at.Add(p.stagger)
+	p.mu.Unlock()
+	wait := at.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+	diag.Logf("pacing: waiting %s before the next host (%s)", wait.Round(time.Millisecond), why)
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done records a completed host; every Every completions the next launch
+// waits for the pause.
+func (p *Pacer) Done() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if p.pause > 0 && p.done%p.every == 0 {
+		p.pauseUntil = time.Now().Add(p.pause)
+		diag.Logf("pacing: %d host(s) completed; pausing %s", p.done, p.pause)
+	}
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// launch starts n hosts in turn, completing each right away, and returns how
+// long it took.
+func launch(t *testing.T, p *Pacer, n int) time.Duration {
+	t.Helper()
+	start := time.Now()
+	for range n {
+		if err := p.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		p.Done()
+	}
+	return time.Since(start)
+}
+
+func TestPacerStagger(t *testing.T) {
+	if d := launch(t, NewPacer(50*time.Millisecond, 0, 1), 3); d < 90*time.Millisecond {
+		t.Errorf("3 hosts with a 50ms stagger took %v, want >= 100ms", d)
+	}
+}
+
+func TestPacerBatchPause(t *testing.T) {
+	// Pauses after hosts 2 and 4; none after the last
+	if d := launch(t, NewPacer(0, 50*time.Millisecond, 2), 5); d < 90*time.Millisecond || d > 140*time.Millisecond {
+		t.Errorf("5 hosts pausing 50ms every 2 took %v, want about 100ms", d)
+	}
+}
+
+func TestPacerCancelled(t *testing.T) {
+	p := NewPacer(time.Hour, 0, 1)
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.Start(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Start = %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("cancelled Start returned after %v", d)
+	}
+}
+
+func TestNilPacer(t *testing.T) {
+	p := NewPacer(0, 0, 10)
+	if p != nil {
+		t.Fatalf("NewPacer without delays = %+v, want nil", p)
+	}
+	if d := launch(t, p, 3); d > 10*time.Millisecond {
+		t.Errorf("nil Pacer waited %v", d)
+	}
+}
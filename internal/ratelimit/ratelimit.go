@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package ratelimit spaces out requests to BMCs with token buckets: one per
+// host and one shared by the whole process.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// bucket is a token bucket holding at most one token, so requests are
+// spaced evenly rather than allowed to burst. tokens goes negative while
+// callers are waiting for their turn.
+type bucket struct {
+	rate   float64 // tokens per second
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token at now and returns how long the caller must wait
+// before using it.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens = min(1, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	} else {
+		b.tokens = 1
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Limiter caps the request rate per host and overall. The zero value and a
+// nil *Limiter do not limit.
+type Limiter struct {
+	perHost float64
+	mu      sync.Mutex
+	global  *bucket
+	hosts   map[string]*bucket
+}
+
+// New returns a Limiter allowing perHost requests per second to any one host
+// and global requests per second in total. Zero disables either limit.
+func New(perHost, global float64) *Limiter {
+	l := &Limiter{perHost: perHost, hosts: map[string]*bucket{}}
+	if global > 0 {
+		l.global = &bucket{rate: global}
+	}
+	return l
+}
+
+// Wait blocks until a request to host is allowed or ctx is done, in which
+// case it returns ctx's error and gives its place back.
+func (l *Limiter) Wait(ctx context.Context, host string) error {
+	if l == nil || (l.perHost <= 0 && l.global == nil) {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	var taken []*bucket
+	var wait time.Duration
+	if l.perHost > 0 {
+		b := l.hosts[host]
+		if b == nil {
+			b = &bucket{rate: l.perHost}
+			l.hosts[host] = b
+		}
+		wait = max(wait, b.reserve(now))
+		taken = append(taken, b)
+	}
+	if l.global != nil {
+		wait = max(wait, l.global.reserve(now))
+		taken = append(taken, l.global)
+	}
+	l.mu.Unlock()
+	if wait == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for _, b := range taken {
+			b.tokens++
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
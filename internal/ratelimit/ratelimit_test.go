@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// elapsed returns how long it takes to make one request to each host in turn.
+func elapsed(t *testing.T, l *Limiter, hosts ...string) time.Duration {
+	t.Helper()
+	start := time.Now()
+	for _, h := range hosts {
+		if err := l.Wait(context.Background(), h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return time.Since(start)
+}
+
+func TestPerHostLimit(t *testing.T) {
+	l := New(20, 0) // one request every 50ms per host
+	if d := elapsed(t, l, "a", "a", "a"); d < 90*time.Millisecond {
+		t.Errorf("3 requests to one host took %v, want >= 100ms", d)
+	}
+	l = New(20, 0)
+	if d := elapsed(t, l, "a", "b", "c"); d > 40*time.Millisecond {
+		t.Errorf("requests to different hosts took %v, want no wait", d)
+	}
+}
+
+func TestGlobalLimit(t *testing.T) {
+	l := New(0, 20)
+	if d := elapsed(t, l, "a", "b", "c"); d < 90*time.Millisecond {
+		t.Errorf("3 requests under --max-rps 20 took %v, want >= 100ms", d)
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	l := New(1, 0)
+	if err := l.Wait(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("cancelled Wait returned after %v", d)
+	}
+	// The cancelled waiter gave its place back: the next request is due one
+	// interval after the first, not two
+	l.hosts["a"].last = time.Now().Add(-time.Second)
+	if d := elapsed(t, l, "a"); d > 100*time.Millisecond {
+		t.Errorf("Wait after cancellation took %v", d)
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	if err := l.Wait(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if d := elapsed(t, New(0, 0), "a", "a", "a"); d > 10*time.Millisecond {
+		t.Errorf("unlimited Limiter waited %v", d)
+	}
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type rfAccount struct {
+	OID      string `json:"@odata.id"`
+	UserName string `json:"UserName"`
+}
+
+// verifyAttempts and verifyDelay bound how long SetAccountPassword retries
+// the login with the new password while the BMC applies it.
+var (
+	verifyAttempts = 3
+	verifyDelay    = 2 * time.Second
+)
+
+// findAccount returns the URI of the AccountService account named username.
+func (c *client) findAccount(ctx context.Context, username string) (string, error) {
+	members, err := c.listMembers(ctx, "/AccountService/Accounts")
+	if err != nil {
+		return "", err
+	}
+	accounts, err := fetchAll[rfAccount](ctx, c, members)
+	if err != nil {
+		return "", err
+	}
+	for i, a := range accounts {
+		if a.UserName == username {
+			return members[i], nil
+		}
+	}
+	return "", fmt.Errorf("no account named %q in AccountService", username)
+}
+
+// FindAccount returns the URI of the account named username on host.
+func FindAccount(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, username string) (string, error) {
+	return newClient(host, user, pass, insecure, timeout).findAccount(ctx, username)
+}
+
+// SetAccountPassword PATCHes the Password of the account named username on
+// host and returns its URI once a fresh login with the new password
+// succeeds. Changing the password of the account used to authenticate can
+// make the BMC drop or reject the PATCH response even though the change took
+// effect, so a PATCH error is reported only when that login fails too.
+func SetAccountPassword(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, username, newPassword string) (string, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	path, err := c.findAccount(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	patchErr := c.patch(ctx, path, map[string]any{"Password": newPassword})
+	// A rejection other than 401 (e.g. the password policy) is definitive
+	var se *StatusError
+	if errors.As(patchErr, &se) && se.Code != http.StatusUnauthorized {
+		return path, patchErr
+	}
+
+	fresh := newClient(host, username, newPassword, insecure, timeout)
+	var ignored map[string]any
+	var verifyErr error
+	for attempt := 0; attempt < verifyAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return path, ctx.Err()
+			case <-time.After(verifyDelay):
+			}
+		}
+		if verifyErr = fresh.get(ctx, path, &ignored); verifyErr == nil {
+			return path, nil
+		}
+	}
+	if patchErr != nil {
+		return path, patchErr
+	}
+	return path, fmt.Errorf("password changed but login with the new password failed: %w", verifyErr)
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// accountsBMC serves an AccountService with the accounts admin and ops. Each
+// PATCH is answered with patchStatus after the new password is stored, or
+// ignored when patchStatus is 400.
+func accountsBMC(t *testing.T, patchStatus int) string {
+	t.Helper()
+	var mu sync.Mutex
+	passwords := map[string]string{"admin": "old", "ops": "old"}
+	ids := map[string]string{"1": "admin", "2": "ops"}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		user, pass, _ := r.BasicAuth()
+		if passwords[user] != pass {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/redfish/v1/AccountService/Accounts/")
+		switch {
+		case r.URL.Path == "/redfish/v1/AccountService/Accounts":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/AccountService/Accounts/1"},{"@odata.id":"/redfish/v1/AccountService/Accounts/2"}]}`))
+		case r.Method == "GET" && ids[id] != "":
+			w.Header().Set("ETag", `"acct-`+id+`"`)
+			_, _ = w.Write([]byte(`{"UserName":"` + ids[id] + `"}`))
+		case r.Method == "PATCH" && ids[id] != "":
+			var body struct{ Password string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if r.Header.Get("If-Match") != `"acct-`+id+`"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			if patchStatus != http.StatusBadRequest {
+				passwords[ids[id]] = body.Password
+			}
+			w.WriteHeader(patchStatus)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestSetAccountPassword(t *testing.T) {
+	defer func(d time.Duration) { verifyDelay = d }(verifyDelay)
+	verifyDelay = 0
+	ctx := context.Background()
+
+	// Another account: the PATCH succeeds and the new password logs in
+	host := accountsBMC(t, http.StatusNoContent)
+	path, err := SetAccountPassword(ctx, host, "admin", "old", true, 5*time.Second, "ops", "n3w")
+	if err != nil || path != "/redfish/v1/AccountService/Accounts/2" {
+		t.Fatalf("SetAccountPassword(ops) = %q, %v", path, err)
+	}
+
+	// The account used to authenticate: the BMC answers the PATCH with 401
+	// because the session's password is already stale
+	host = accountsBMC(t, http.StatusUnauthorized)
+	if _, err := SetAccountPassword(ctx, host, "admin", "old", true, 5*time.Second, "admin", "n3w"); err != nil {
+		t.Fatalf("SetAccountPassword(admin) as admin: %v", err)
+	}
+
+	// A rejected password is reported without retrying the login
+	host = accountsBMC(t, http.StatusBadRequest)
+	if _, err := SetAccountPassword(ctx, host, "admin", "old", true, 5*time.Second, "ops", "short"); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("expected the 400 to be reported, got %v", err)
+	}
+
+	host = accountsBMC(t, http.StatusNoContent)
+	if _, err := SetAccountPassword(ctx, host, "admin", "old", true, 5*time.Second, "nobody", "n3w"); err == nil || !strings.Contains(err.Error(), `no account named "nobody"`) {
+		t.Errorf("expected a missing account error, got %v", err)
+	}
+}
+
+func TestSetAccountPasswordVerifies(t *testing.T) {
+	defer func(d time.Duration) { verifyDelay = d }(verifyDelay)
+	verifyDelay = 0
+	// The BMC claims success but keeps the old password
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pass, _ := r.BasicAuth(); pass != "old" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/redfish/v1/AccountService/Accounts":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/AccountService/Accounts/1"}]}`))
+		case r.Method == "GET":
+			_, _ = w.Write([]byte(`{"UserName":"admin"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+	_, err := SetAccountPassword(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "admin", "old", true, 5*time.Second, "admin", "n3w")
+	if err == nil || !strings.Contains(err.Error(), "login with the new password failed") {
+		t.Errorf("expected a verification failure, got %v", err)
+	}
+}
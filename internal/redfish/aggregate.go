@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// AggregatedSystem is a ComputerSystem that a chassis controller serves on
+// behalf of one of its node controllers.
+type AggregatedSystem struct {
+	// Path is the system's path on the controller.
+	Path string
+	ID   string
+	Name string
+	// MACs are its bootable MACs, chosen as by DiscoverAllBootableMACs.
+	MACs    []string
+	Status  ResourceStatus
+	Details SystemDetails
+	// Err is set when the system or its interfaces could not be read.
+	Err error
+}
+
+// DiscoverAggregatedSystems reads every system in the Systems collection of
+// the chassis controller host, with its bootable MACs, through that one
+// service. Members a proxy lists by absolute URL are read through host all
+// the same, never from the node controllers themselves. An error is
+// returned only when the collection cannot be read; a system that cannot be
+// read has its Err set.
+func DiscoverAggregatedSystems(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, rules NICRules) ([]AggregatedSystem, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	members, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vendor, _ := Vendor(host)
+	out := make([]AggregatedSystem, 0, len(members))
+	for _, m := range members {
+		s := AggregatedSystem{Path: localPath(m)}
+		var sys rfComputerSystem
+		if err := c.get(ctx, s.Path, &sys); err != nil {
+			s.Err = err
+			out = append(out, s)
+			continue
+		}
+		s.ID, s.Name, s.Status, s.Details = sys.ID, sys.Name, sys.Status, *sys.details()
+		nics, err := c.listLocalEthernetInterfaces(ctx, s.Path)
+		if err != nil {
+			s.Err = err
+		} else {
+			var decisions []nicDecision
+			s.MACs, decisions = bootableMACs(nics, rules, vendor)
+			if rules.Explain {
+				explainNICs(ctx, host, s.Path, decisions)
+			}
+		}
+		out = append(out, s)
+	}
+	return out, ctx.Err()
+}
+
+// listLocalEthernetInterfaces is listEthernetInterfaces for a system behind
+// a proxy: every member is read through c's host.
+func (c *client) listLocalEthernetInterfaces(ctx context.Context, sysPath string) ([]rfEthernetInterface, error) {
+	members, err := c.listMembers(ctx, sysPath+"/EthernetInterfaces")
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range members {
+		members[i] = localPath(m)
+	}
+	return fetchAll[rfEthernetInterface](ctx, c, members)
+}
+
+// localPath turns an absolute @odata.id, as a proxy may give for the
+// resources it serves, into a path on the service that returned it, so the
+// request goes through the proxy rather than to the proxied BMC.
+func localPath(oid string) string {
+	u, err := url.Parse(oid)
+	if err != nil || u.Host == "" {
+		return oid
+	}
+	p := u.EscapedPath()
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	return p
+}
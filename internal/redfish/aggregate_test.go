@@ -0,0 +1,20 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import "testing"
+
+func TestLocalPath(t *testing.T) {
+	cases := map[string]string{
+		"/redfish/v1/Systems/s0b0n0":                                              "/redfish/v1/Systems/s0b0n0",
+		"https://x9000c1s0b0/redfish/v1/Systems/Node0":                            "/redfish/v1/Systems/Node0",
+		"https://10.1.0.5:443/redfish/v1/Systems/Node0/EthernetInterfaces?$top=2": "/redfish/v1/Systems/Node0/EthernetInterfaces?$top=2",
+	}
+	for in, want := range cases {
+		if got := localPath(in); got != want {
+			t.Errorf("localPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type rfBios struct {
+	Attributes map[string]any `json:"Attributes"`
+	Settings   struct {
+		SettingsObject struct {
+			OID string `json:"@odata.id"`
+		} `json:"SettingsObject"`
+		SupportedApplyTimes []string `json:"SupportedApplyTimes"`
+	} `json:"@Redfish.Settings"`
+}
+
+// SystemBios holds the BIOS attributes of a single system. Pending lists
+// values staged in the settings object that apply at the next reset. Err is
+// set when that system's BIOS could not be read.
+type SystemBios struct {
+	SystemPath string
+	Attributes map[string]any
+	Pending    map[string]any
+	Err        error
+}
+
+// AttributeChange is one BIOS attribute whose current value differs from the
+// desired one.
+type AttributeChange struct {
+	Name    string
+	Current any
+	Desired any
+}
+
+// BiosUpdate is the outcome of SetBios for a single system. Changes is empty
+// when every attribute already had (or had pending) its desired value, in
+// which case nothing was sent. Path and Body are the PATCH target and body.
+type BiosUpdate struct {
+	SystemPath     string
+	Changes        []AttributeChange
+	Path           string
+	Body           map[string]any
+	RebootRequired bool
+	Err            error
+}
+
+// biosPath returns the Bios resource of the system at sysPath.
+func (c *client) biosPath(ctx context.Context, sysPath string) (string, error) {
+	var sys struct {
+		Bios struct {
+			OID string `json:"@odata.id"`
+		} `json:"Bios"`
+	}
+	if err := c.get(ctx, sysPath, &sys); err != nil {
+		return "", err
+	}
+	if sys.Bios.OID == "" {
+		return sysPath + "/Bios", nil
+	}
+	return sys.Bios.OID, nil
+}
+
+// readBios returns the Bios resource of the system at sysPath and the
+// attributes pending in its settings object, if it has one.
+func (c *client) readBios(ctx context.Context, sysPath string) (string, rfBios, map[string]any, error) {
+	var bios rfBios
+	path, err := c.biosPath(ctx, sysPath)
+	if err != nil {
+		return "", bios, nil, err
+	}
+	if err := c.get(ctx, path, &bios); err != nil {
+		return path, bios, nil, err
+	}
+	var pending map[string]any
+	if settings := bios.Settings.SettingsObject.OID; settings != "" {
+		var staged struct {
+			Attributes map[string]any `json:"Attributes"`
+		}
+		if err := c.get(ctx, settings, &staged); err != nil {
+			return path, bios, nil, err
+		}
+		// The settings object may echo every attribute; keep real changes
+		for k, v := range staged.Attributes {
+			if !sameValue(bios.Attributes[k], v) {
+				if pending == nil {
+					pending = map[string]any{}
+				}
+				pending[k] = v
+			}
+		}
+	}
+	return path, bios, pending, nil
+}
+
+// GetBios returns the BIOS attributes of every system on a BMC.
+func GetBios(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]SystemBios, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SystemBios, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		_, bios, pending, err := c.readBios(ctx, sysPath)
+		out = append(out, SystemBios{SystemPath: sysPath, Attributes: bios.Attributes, Pending: pending, Err: err})
+	}
+	return out, nil
+}
+
+// SetBios stages attrs in the BIOS settings object of every system on a BMC
+// (or PATCHes the Bios resource when there is none), sending only attributes
+// whose current or pending value differs. String values are converted to the
+// type of the current value, so "true" sets a boolean attribute. Unknown
+// attributes are an error for that system. The settings object's ETag is
+// sent as If-Match, with an @Redfish.SettingsApplyTime of OnReset when the
+// BMC supports it. With dryRun the changes and PATCH body are returned but
+// nothing is sent.
+func SetBios(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, attrs map[string]any, dryRun bool) ([]BiosUpdate, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(attrs))
+	for k := range attrs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	out := make([]BiosUpdate, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		res := BiosUpdate{SystemPath: sysPath}
+		path, bios, pending, err := c.readBios(ctx, sysPath)
+		if err != nil {
+			res.Err = err
+			out = append(out, res)
+			continue
+		}
+		patch := map[string]any{}
+		for _, name := range names {
+			current, ok := bios.Attributes[name]
+			if !ok {
+				res.Err = fmt.Errorf("unknown BIOS attribute %s", name)
+				break
+			}
+			desired, err := coerceAttribute(current, attrs[name])
+			if err != nil {
+				res.Err = fmt.Errorf("BIOS attribute %s: %w", name, err)
+				break
+			}
+			if sameValue(current, desired) {
+				continue
+			}
+			if v, ok := pending[name]; ok && sameValue(v, desired) {
+				continue
+			}
+			res.Changes = append(res.Changes, AttributeChange{Name: name, Current: current, Desired: desired})
+			patch[name] = desired
+		}
+		if res.Err != nil || len(res.Changes) == 0 {
+			out = append(out, res)
+			continue
+		}
+
+		// BIOS changes take effect at POST unless the BMC applies them at once
+		res.Path, res.Body, res.RebootRequired = path, map[string]any{"Attributes": patch}, true
+		if settings := bios.Settings.SettingsObject.OID; settings != "" {
+			res.Path = settings
+			if times := bios.Settings.SupportedApplyTimes; len(times) > 0 {
+				applyTime := times[0]
+				if containsFold(times, "OnReset") {
+					applyTime = "OnReset"
+				}
+				res.Body["@Redfish.SettingsApplyTime"] = map[string]any{"ApplyTime": applyTime}
+				res.RebootRequired = applyTime != "Immediate"
+			}
+		}
+		if !dryRun {
+			res.Err = c.patch(ctx, res.Path, res.Body)
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// coerceAttribute converts a string desired value to the type of current
+// (boolean or number); other values are returned unchanged.
+func coerceAttribute(current, desired any) (any, error) {
+	s, ok := desired.(string)
+	if !ok {
+		return desired, nil
+	}
+	switch current.(type) {
+	case bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("want a boolean, got %q", s)
+		}
+		return b, nil
+	case float64:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("want a number, got %q", s)
+		}
+		return f, nil
+	}
+	return desired, nil
+}
+
+// sameValue compares attribute values of possibly different numeric types,
+// e.g. a JSON float64 and a YAML int.
+func sameValue(a, b any) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// biosBMC serves one system whose Bios stages changes in a settings object.
+// PATCH bodies are sent to patches.
+func biosBMC(t *testing.T, patches chan<- map[string]any) string {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`))
+		case r.URL.Path == "/redfish/v1/Systems/Node0":
+			_, _ = w.Write([]byte(`{"Bios":{"@odata.id":"/redfish/v1/Systems/Node0/Bios"}}`))
+		case r.URL.Path == "/redfish/v1/Systems/Node0/Bios":
+			_, _ = w.Write([]byte(`{
+				"Attributes":{"BootMode":"Legacy","SriovEnable":false,"NumaNodesPerSocket":1,"ProcTurbo":"Enabled"},
+				"@Redfish.Settings":{
+					"SettingsObject":{"@odata.id":"/redfish/v1/Systems/Node0/Bios/Settings"},
+					"SupportedApplyTimes":["Immediate","OnReset"]
+				}
+			}`))
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Systems/Node0/Bios/Settings":
+			w.Header().Set("ETag", `"bios-7"`)
+			_, _ = w.Write([]byte(`{"Attributes":{"BootMode":"Legacy","ProcTurbo":"Disabled"}}`))
+		case r.Method == "PATCH" && r.URL.Path == "/redfish/v1/Systems/Node0/Bios/Settings":
+			if r.Header.Get("If-Match") != `"bios-7"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			patches <- body
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestGetBios(t *testing.T) {
+	host := biosBMC(t, nil)
+	systems, err := GetBios(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(systems) != 1 || systems[0].Err != nil {
+		t.Fatalf("unexpected result: %+v", systems)
+	}
+	if systems[0].Attributes["BootMode"] != "Legacy" {
+		t.Errorf("Attributes = %v", systems[0].Attributes)
+	}
+	// BootMode is echoed unchanged by the settings object, so only ProcTurbo is pending
+	if want := map[string]any{"ProcTurbo": "Disabled"}; !reflect.DeepEqual(systems[0].Pending, want) {
+		t.Errorf("Pending = %v, want %v", systems[0].Pending, want)
+	}
+}
+
+func TestSetBios(t *testing.T) {
+	patches := make(chan map[string]any, 1)
+	host := biosBMC(t, patches)
+	attrs := map[string]any{
+		"BootMode":           "Uefi",
+		"SriovEnable":        "true", // converted to a boolean
+		"NumaNodesPerSocket": 1,      // already set
+		"ProcTurbo":          "Disabled",
+	}
+	res, err := SetBios(context.Background(), host, "u", "p", true, 5*time.Second, attrs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Err != nil {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	wantChanges := []AttributeChange{
+		{Name: "BootMode", Current: "Legacy", Desired: "Uefi"},
+		{Name: "SriovEnable", Current: false, Desired: true},
+	}
+	if !reflect.DeepEqual(res[0].Changes, wantChanges) {
+		t.Errorf("Changes = %+v, want %+v", res[0].Changes, wantChanges)
+	}
+	if !res[0].RebootRequired {
+		t.Error("expected RebootRequired for an OnReset apply time")
+	}
+	body := <-patches
+	want := map[string]any{
+		"Attributes":                 map[string]any{"BootMode": "Uefi", "SriovEnable": true},
+		"@Redfish.SettingsApplyTime": map[string]any{"ApplyTime": "OnReset"},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("PATCH body = %v, want %v", body, want)
+	}
+}
+
+func TestSetBiosNoOpAndErrors(t *testing.T) {
+	patches := make(chan map[string]any, 1)
+	host := biosBMC(t, patches)
+	ctx := context.Background()
+
+	// Already current or pending: nothing is sent
+	res, err := SetBios(ctx, host, "u", "p", true, 5*time.Second, map[string]any{"BootMode": "Legacy", "ProcTurbo": "Disabled"}, false)
+	if err != nil || len(res) != 1 || res[0].Err != nil || len(res[0].Changes) != 0 {
+		t.Fatalf("no-op SetBios = %+v, %v", res, err)
+	}
+	// Dry run computes the body without sending it
+	res, err = SetBios(ctx, host, "u", "p", true, 5*time.Second, map[string]any{"BootMode": "Uefi"}, true)
+	if err != nil || len(res) != 1 || res[0].Body == nil || res[0].Path != "/redfish/v1/Systems/Node0/Bios/Settings" {
+		t.Fatalf("dry-run SetBios = %+v, %v", res, err)
+	}
+	select {
+	case body := <-patches:
+		t.Fatalf("unexpected PATCH %v", body)
+	default:
+	}
+
+	for attrs, want := range map[string]string{
+		"NoSuchAttribute": "unknown BIOS attribute",
+		"SriovEnable":     "want a boolean",
+	} {
+		res, err := SetBios(ctx, host, "u", "p", true, 5*time.Second, map[string]any{attrs: "maybe"}, false)
+		if err != nil || len(res) != 1 || res[0].Err == nil || !strings.Contains(res[0].Err.Error(), want) {
+			t.Errorf("SetBios(%s=maybe) = %+v, %v; want error containing %q", attrs, res, err, want)
+		}
+	}
+}
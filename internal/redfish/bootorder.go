@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BootOption is one member of a system's BootOptions collection.
+type BootOption struct {
+	Reference   string // BootOptionReference, as listed in BootOrder
+	DisplayName string
+}
+
+// SystemBootOrder is the persistent boot order of a single system. Err is
+// set when that system could not be read or updated.
+type SystemBootOrder struct {
+	SystemPath string
+	Order      []string // BootOrder as read from the system
+	Options    []BootOption
+	// NewOrder and Changed are set by SetBootOrder: the order computed from
+	// the BootOrderSpec and whether it differs from Order.
+	NewOrder []string
+	Changed  bool
+	Err      error
+}
+
+// Name returns the DisplayName of the boot option with BootOptionReference
+// ref, or ref itself when the system does not list one.
+func (s SystemBootOrder) Name(ref string) string {
+	for _, o := range s.Options {
+		if o.Reference == ref && o.DisplayName != "" {
+			return o.DisplayName
+		}
+	}
+	return ref
+}
+
+type rfBootOption struct {
+	ID          string `json:"Id"`
+	Reference   string `json:"BootOptionReference"`
+	DisplayName string `json:"DisplayName"`
+}
+
+// BootOrderSpec reorders a BootOrder by the DisplayName of its entries.
+// First and Last hold glob patterns (* and ?) matched case-insensitively
+// against the whole DisplayName, or the reference when an entry has none.
+type BootOrderSpec struct {
+	First []string
+	Last  []string
+}
+
+// Apply returns the boot order of s with the entries matching First moved to
+// the front and those matching Last moved to the end, grouped by pattern in
+// the order given. Entries keep their relative order within a group and the
+// others stay in between as they were. An entry matching both a First and a
+// Last pattern goes first. A pattern that matches no entry is an error.
+func (spec BootOrderSpec) Apply(s SystemBootOrder) ([]string, error) {
+	if len(spec.First) == 0 && len(spec.Last) == 0 {
+		return nil, fmt.Errorf("no --first or --last pattern given")
+	}
+	first, err := compileGlobs(spec.First)
+	if err != nil {
+		return nil, err
+	}
+	last, err := compileGlobs(spec.Last)
+	if err != nil {
+		return nil, err
+	}
+	firstGroups := make([][]string, len(first))
+	lastGroups := make([][]string, len(last))
+	var middle []string
+	for _, ref := range s.Order {
+		name := s.Name(ref)
+		if i := matchGlobs(first, name); i >= 0 {
+			firstGroups[i] = append(firstGroups[i], ref)
+		} else if i := matchGlobs(last, name); i >= 0 {
+			lastGroups[i] = append(lastGroups[i], ref)
+		} else {
+			middle = append(middle, ref)
+		}
+	}
+	for i, g := range firstGroups {
+		if len(g) == 0 {
+			return nil, fmt.Errorf("--first %q matches no boot option", spec.First[i])
+		}
+	}
+	for i, re := range last {
+		// A --last pattern may only match entries that went first
+		if !slices.ContainsFunc(s.Order, func(ref string) bool { return re.MatchString(s.Name(ref)) }) {
+			return nil, fmt.Errorf("--last %q matches no boot option", spec.Last[i])
+		}
+	}
+	order := make([]string, 0, len(s.Order))
+	for _, g := range firstGroups {
+		order = append(order, g...)
+	}
+	order = append(order, middle...)
+	for _, g := range lastGroups {
+		order = append(order, g...)
+	}
+	return order, nil
+}
+
+// compileGlobs turns glob patterns into anchored case-insensitive regular
+// expressions. Unlike path.Match, * also matches "/", which device paths in
+// DisplayNames often contain.
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("empty boot option pattern")
+		}
+		expr := regexp.QuoteMeta(p)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		re, err := regexp.Compile("(?is)^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("boot option pattern %q: %w", p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// matchGlobs returns the index of the first of res matching name, or -1.
+func matchGlobs(res []*regexp.Regexp, name string) int {
+	for i, re := range res {
+		if re.MatchString(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// readBootOrder reads the BootOrder and BootOptions of the system at sysPath,
+// returning the system as read and its ETag for a following PATCH.
+func (c *client) readBootOrder(ctx context.Context, sysPath string) (SystemBootOrder, rfBootSystem, string, error) {
+	res := SystemBootOrder{SystemPath: sysPath}
+	var sys rfBootSystem
+	etag, err := c.getWithETag(ctx, sysPath, &sys)
+	if err != nil {
+		return res, sys, "", err
+	}
+	if sys.Boot.BootOrder == nil {
+		return res, sys, "", fmt.Errorf("system does not report a BootOrder")
+	}
+	res.Order = sys.Boot.BootOrder
+	optionsPath := sys.Boot.BootOptions.OID
+	if optionsPath == "" {
+		// Without BootOptions the references are all there is to match
+		return res, sys, etag, nil
+	}
+	members, err := c.listMembers(ctx, optionsPath)
+	if err != nil {
+		return res, sys, "", fmt.Errorf("boot options: %w", err)
+	}
+	for _, m := range members {
+		var o rfBootOption
+		if err := c.get(ctx, m, &o); err != nil {
+			return res, sys, "", fmt.Errorf("boot option %s: %w", m, err)
+		}
+		ref := o.Reference
+		if ref == "" {
+			ref = o.ID
+		}
+		res.Options = append(res.Options, BootOption{Reference: ref, DisplayName: o.DisplayName})
+	}
+	return res, sys, etag, nil
+}
+
+// GetBootOrders returns the BootOrder and BootOptions of every system on a
+// BMC.
+func GetBootOrders(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]SystemBootOrder, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SystemBootOrder, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		res, _, _, err := c.readBootOrder(ctx, sysPath)
+		res.Err = err
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// SetBootOrder reorders the persistent BootOrder of every system on a BMC by
+// spec. Systems whose order would not change are left alone. The BootOrder
+// is PATCHed with the system's ETag as If-Match, through the @Redfish.Settings
+// object when the system publishes one. With apply unset nothing is PATCHed,
+// so the returned orders show what would change.
+func SetBootOrder(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, spec BootOrderSpec, apply bool) ([]SystemBootOrder, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SystemBootOrder, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		res, sys, etag, err := c.readBootOrder(ctx, sysPath)
+		if err == nil {
+			res.NewOrder, err = spec.Apply(res)
+		}
+		if err == nil {
+			res.Changed = !slices.Equal(res.Order, res.NewOrder)
+			if res.Changed && apply {
+				err = c.patchBootSettings(ctx, sysPath, sys, etag, map[string]any{"Boot": map[string]any{"BootOrder": res.NewOrder}})
+			}
+		}
+		res.Err = err
+		out = append(out, res)
+	}
+	return out, nil
+}
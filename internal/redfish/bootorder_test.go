@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBootOrderSpecApply(t *testing.T) {
+	sys := SystemBootOrder{
+		Order: []string{"Boot0001", "Boot0002", "Boot0003", "Boot0004", "Boot0005"},
+		Options: []BootOption{
+			{Reference: "Boot0001", DisplayName: "HDD 1: Samsung SSD"},
+			{Reference: "Boot0002", DisplayName: "UEFI HTTPv4 (MAC:00408C1A2B3C)"},
+			{Reference: "Boot0003", DisplayName: "PXE IPv4 Mellanox Port 1"},
+			{Reference: "Boot0004", DisplayName: "UEFI Shell"},
+			{Reference: "Boot0005", DisplayName: "pxe ipv4 PciRoot(0x0)/Pci(0x1,0x0)"},
+		},
+	}
+	cases := []struct {
+		name        string
+		first, last []string
+		want        string
+	}{
+		{"first and last", []string{"pxe*ipv4*"}, []string{"hdd*"}, "Boot0003 Boot0005 Boot0002 Boot0004 Boot0001"},
+		{"groups in pattern order", []string{"*httpv4*", "pxe*"}, nil, "Boot0002 Boot0003 Boot0005 Boot0001 Boot0004"},
+		{"first wins over last", []string{"*pxe*"}, []string{"*v4*"}, "Boot0003 Boot0005 Boot0001 Boot0004 Boot0002"},
+		{"reference without a name", []string{"boot0004"}, nil, "Boot0004 Boot0001 Boot0002 Boot0003 Boot0005"},
+		{"unmatched first", []string{"*cdrom*"}, nil, `--first "*cdrom*" matches no boot option`},
+		{"unmatched last", nil, []string{"usb*"}, `--last "usb*" matches no boot option`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := sys
+			if tc.name == "reference without a name" {
+				s.Options = nil
+			}
+			order, err := BootOrderSpec{First: tc.first, Last: tc.last}.Apply(s)
+			got := strings.Join(order, " ")
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// bootOrderBMC serves one system with the given BootOrder and two boot
+// options and records every PATCH as "path If-Match body".
+func bootOrderBMC(t *testing.T, order string, settings bool) (string, *[]string) {
+	t.Helper()
+	var patches []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Systems/Node0":
+			w.Header().Set("ETag", `"sys-1"`)
+			extra := ""
+			if settings {
+				extra = `,"@Redfish.Settings":{"SettingsObject":{"@odata.id":"/redfish/v1/Systems/Node0/Settings"},"SupportedApplyTimes":["OnReset"]}`
+			}
+			fmt.Fprintf(w, `{"Boot":{"BootOrder":%s,"BootOptions":{"@odata.id":"/redfish/v1/Systems/Node0/BootOptions"}}%s}`, order, extra)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Systems/Node0/Settings":
+			w.Header().Set("ETag", `"settings-1"`)
+			fmt.Fprint(w, `{}`)
+		case r.URL.Path == "/redfish/v1/Systems/Node0/BootOptions":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0/BootOptions/1"},{"@odata.id":"/redfish/v1/Systems/Node0/BootOptions/2"}]}`)
+		case r.URL.Path == "/redfish/v1/Systems/Node0/BootOptions/1":
+			fmt.Fprint(w, `{"Id":"1","BootOptionReference":"Boot0001","DisplayName":"Hard Drive"}`)
+		case r.URL.Path == "/redfish/v1/Systems/Node0/BootOptions/2":
+			fmt.Fprint(w, `{"Id":"2","BootOptionReference":"Boot0002","DisplayName":"UEFI PXE IPv4"}`)
+		case r.Method == "PATCH":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			patches = append(patches, r.URL.Path+" "+r.Header.Get("If-Match")+" "+string(b))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &patches
+}
+
+func TestSetBootOrder(t *testing.T) {
+	spec := BootOrderSpec{First: []string{"*pxe*"}, Last: []string{"hard*"}}
+	cases := []struct {
+		name     string
+		order    string
+		settings bool
+		apply    bool
+		want     []string
+	}{
+		{"patches the system", `["Boot0001","Boot0002"]`, false, true,
+			[]string{`/redfish/v1/Systems/Node0 "sys-1" {"Boot":{"BootOrder":["Boot0002","Boot0001"]}}`}},
+		{"through the settings object", `["Boot0001","Boot0002"]`, true, true,
+			[]string{`/redfish/v1/Systems/Node0/Settings "settings-1" {"@Redfish.SettingsApplyTime":{"ApplyTime":"OnReset"},"Boot":{"BootOrder":["Boot0002","Boot0001"]}}`}},
+		{"dry run", `["Boot0001","Boot0002"]`, false, false, nil},
+		{"no-op", `["Boot0002","Boot0001"]`, false, true, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, patches := bootOrderBMC(t, tc.order, tc.settings)
+			res, err := SetBootOrder(context.Background(), host, "u", "p", true, 5*time.Second, spec, tc.apply)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res) != 1 || res[0].Err != nil {
+				t.Fatalf("results = %+v", res)
+			}
+			if !reflect.DeepEqual(res[0].NewOrder, []string{"Boot0002", "Boot0001"}) || res[0].Changed != (tc.name != "no-op") {
+				t.Errorf("NewOrder = %v, Changed = %v", res[0].NewOrder, res[0].Changed)
+			}
+			if !reflect.DeepEqual(*patches, tc.want) {
+				t.Errorf("PATCHes = %v\nwant      %v", *patches, tc.want)
+			}
+		})
+	}
+}
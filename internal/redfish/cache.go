@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bootstrap/internal/diag"
+)
+
+// CacheOptions configures the on-disk cache of Redfish GET responses.
+type CacheOptions struct {
+	// Dir holds one file per host and request URI. Empty disables the
+	// cache.
+	Dir string
+	// Replay serves every GET from Dir without contacting any BMC; other
+	// requests fail. Without it, successful GET responses are written to
+	// Dir.
+	Replay bool
+	// MaxAge, with Replay, refuses entries recorded longer ago than this.
+	// Zero accepts entries of any age.
+	MaxAge time.Duration
+}
+
+// cache is the cache every client uses; set by ConfigureCache.
+var cache CacheOptions
+
+// ErrNotCached reports a GET that Replay cannot serve because the cache has
+// no usable response for it.
+var ErrNotCached = errors.New("no cached response")
+
+// cacheEntry is the file written for one GET response.
+type cacheEntry struct {
+	Host string          `json:"host"`
+	Path string          `json:"path"`
+	Time time.Time       `json:"time"`
+	ETag string          `json:"etag,omitempty"`
+	Body json.RawMessage `json:"body"`
+}
+
+// ConfigureCache sets the cache of subsequent requests. A zero CacheOptions
+// turns it off.
+func ConfigureCache(opts CacheOptions) error {
+	switch {
+	case opts.Dir == "" && opts.Replay:
+		return errors.New("replaying from the cache requires a cache directory")
+	case opts.Replay:
+		if fi, err := os.Stat(opts.Dir); err != nil {
+			return fmt.Errorf("cache: %w", err)
+		} else if !fi.IsDir() {
+			return fmt.Errorf("cache: %s is not a directory", opts.Dir)
+		}
+	case opts.Dir != "":
+		if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+			return fmt.Errorf("cache: %w", err)
+		}
+	}
+	cache = opts
+	return nil
+}
+
+// cacheFile returns the file of the response to a GET of uri on host. Each
+// host has its own directory, so the cache of one rack can be picked out.
+func cacheFile(host, uri string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, host)
+	sum := sha256.Sum256([]byte(uri))
+	return filepath.Join(cache.Dir, safe, hex.EncodeToString(sum[:12])+".json")
+}
+
+// replayResponse answers req from the cache.
+func replayResponse(req *http.Request) (*http.Response, error) {
+	uri := req.URL.RequestURI()
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("%s %s on %s: only GET requests can be replayed from the cache", req.Method, uri, req.URL.Host)
+	}
+	raw, err := os.ReadFile(cacheFile(req.URL.Host, uri))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for GET %s on %s in %s", ErrNotCached, uri, req.URL.Host, cache.Dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return nil, fmt.Errorf("cache: %s: %w", cacheFile(req.URL.Host, uri), err)
+	}
+	if age := time.Since(e.Time); cache.MaxAge > 0 && age > cache.MaxAge {
+		return nil, fmt.Errorf("%w for GET %s on %s: the entry is %s old, over the maximum age of %s",
+			ErrNotCached, uri, req.URL.Host, age.Round(time.Second), cache.MaxAge)
+	}
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	if e.ETag != "" {
+		header.Set("ETag", e.ETag)
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(e.Body)),
+		Request:    req,
+	}, nil
+}
+
+// recordResponse writes the body of a successful GET response to the cache
+// and gives resp a fresh copy of it to read. Only a failure to read the
+// body is returned; a failure to write the cache is warned about.
+func recordResponse(req *http.Request, resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if !json.Valid(body) {
+		return nil
+	}
+	uri := req.URL.RequestURI()
+	raw, err := json.Marshal(cacheEntry{Host: req.URL.Host, Path: uri, Time: time.Now().UTC(), ETag: resp.Header.Get("ETag"), Body: body})
+	if err == nil {
+		err = writeCacheFile(cacheFile(req.URL.Host, uri), raw)
+	}
+	if err != nil {
+		diag.Warnf("cache: GET %s on %s: %v", uri, req.URL.Host, err)
+	}
+	return nil
+}
+
+// writeCacheFile replaces path with data through a temporary file, so a
+// concurrent reader never sees a partial entry.
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()           //nolint:errcheck
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheRecordAndReplay(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case "/redfish/v1/Systems/Node0/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0/EthernetInterfaces/eth0"}]}`)
+		case "/redfish/v1/Systems/Node0/EthernetInterfaces/eth0":
+			fmt.Fprint(w, `{"Id":"eth0","MACAddress":"aa:bb:cc:dd:ee:01","UefiDevicePath":"MAC(aabbccddee01)/IPv4(0.0.0.0)"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	host := strings.TrimPrefix(srv.URL, "https://")
+	dir := t.TempDir()
+	t.Cleanup(func() { _ = ConfigureCache(CacheOptions{}) })
+	discover := func() ([]SystemMACs, error) {
+		return DiscoverAllBootableMACs(context.Background(), host, "u", "p", true, 5*time.Second, NICRules{})
+	}
+
+	if err := ConfigureCache(CacheOptions{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	sent := requests.Load()
+
+	if err := ConfigureCache(CacheOptions{Dir: dir, Replay: true, MaxAge: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := discover()
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, recorded) || len(replayed) != 1 {
+		t.Errorf("replayed %+v, recorded %+v", replayed, recorded)
+	}
+	if requests.Load() != sent {
+		t.Errorf("replay contacted the BMC")
+	}
+
+	// A host that was never recorded fails clearly
+	_, err = DiscoverAllBootableMACs(context.Background(), "10.0.0.1", "u", "p", true, time.Second, NICRules{})
+	if !errors.Is(err, ErrNotCached) || Categorize(err) != CategoryNotCached || !strings.Contains(err.Error(), "GET /redfish/v1/Systems on 10.0.0.1") {
+		t.Errorf("uncached host: err = %v", err)
+	}
+
+	// Entries older than MaxAge are refused
+	if err := ConfigureCache(CacheOptions{Dir: dir, Replay: true, MaxAge: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := discover(); !errors.Is(err, ErrNotCached) || !strings.Contains(err.Error(), "over the maximum age of 1ns") {
+		t.Errorf("stale entry: err = %v", err)
+	}
+	if err := ConfigureCache(CacheOptions{Dir: t.TempDir() + "/missing", Replay: true}); err == nil {
+		t.Error("replaying from a missing directory must fail")
+	}
+}
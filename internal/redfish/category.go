@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"bootstrap/internal/httptimeout"
+)
+
+// Failure categories returned by Categorize, in the order summaries list
+// them.
+const (
+	CategoryUnreachable = "unreachable"
+	CategoryDNS         = "dns"
+	CategoryAuth        = "auth"
+	// CategoryConnectTimeout is a BMC that never answered the TCP connect,
+	// e.g. behind a switch port that is down; CategoryTimeout is one that
+	// accepted the connection and then stalled.
+	CategoryConnectTimeout = "connect-timeout"
+	CategoryTimeout        = "timeout"
+	CategoryTLS            = "tls"
+	CategoryIdentity       = "identity-changed"
+	CategoryRedfishError   = "redfish-error"
+	CategoryNoNICs         = "no-nics"
+	CategoryNotCached      = "not-cached"
+)
+
+// Categories lists every failure category in summary order.
+var Categories = []string{CategoryUnreachable, CategoryDNS, CategoryAuth, CategoryConnectTimeout, CategoryTimeout, CategoryTLS, CategoryIdentity, CategoryRedfishError, CategoryNoNICs, CategoryNotCached}
+
+// ErrNoBootableNICs reports a BMC that answered but had no bootable NIC on
+// any of its systems.
+var ErrNoBootableNICs = errors.New("no bootable NICs found")
+
+// Categorize sorts a failed BMC request into one of the Category values, so
+// that a summary can tell a powered-off rack (unreachable) from wrong
+// credentials (auth), BMCs that never answer (connect-timeout), or ones that
+// answer and stall (timeout; the error names the phase). A BMC name that does
+// not resolve is dns, whether or not the lookup timed out, and one whose
+// certificate does not match its pinned identity is identity-changed. Errors
+// that are none of the others, such as unexpected statuses or malformed
+// responses, are redfish-error. A nil error has no category.
+func Categorize(err error) string {
+	var (
+		nerr  net.Error
+		dnerr *net.DNSError
+		operr *net.OpError
+		verr  *tls.CertificateVerificationError
+		uerr  x509.UnknownAuthorityError
+		herr  x509.HostnameError
+		rerr  tls.RecordHeaderError
+		aerr  tls.AlertError
+		ierr  *IdentityError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrNoBootableNICs):
+		return CategoryNoNICs
+	case errors.Is(err, ErrNotCached):
+		return CategoryNotCached
+	case errors.As(err, &ierr):
+		return CategoryIdentity
+	case errors.As(err, &dnerr):
+		return CategoryDNS
+	case httptimeout.PhaseOf(err) == httptimeout.PhaseConnect,
+		errors.As(err, &operr) && operr.Op == "dial" && operr.Timeout():
+		return CategoryConnectTimeout
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &nerr) && nerr.Timeout():
+		return CategoryTimeout
+	case isStatus(err, http.StatusUnauthorized, http.StatusForbidden):
+		return CategoryAuth
+	case errors.As(err, &verr), errors.As(err, &uerr), errors.As(err, &herr),
+		errors.As(err, &rerr), errors.As(err, &aerr), strings.Contains(err.Error(), "tls: "),
+		strings.Contains(err.Error(), "HTTP response to HTTPS client"):
+		return CategoryTLS
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EHOSTUNREACH),
+		errors.Is(err, syscall.ENETUNREACH), errors.Is(err, syscall.ECONNRESET),
+		errors.As(err, &operr) && operr.Op == "dial":
+		return CategoryUnreachable
+	}
+	return CategoryRedfishError
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"bootstrap/internal/httptimeout"
+)
+
+func TestCategorize(t *testing.T) {
+	dial := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://10.0.0.1/redfish/v1", Err: &net.OpError{Op: "dial", Net: "tcp", Err: err}}
+	}
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"connection refused", dial(os.NewSyscallError("connect", syscall.ECONNREFUSED)), CategoryUnreachable},
+		{"no route to host", dial(os.NewSyscallError("connect", syscall.EHOSTUNREACH)), CategoryUnreachable},
+		{"unknown host", &url.Error{Op: "Get", URL: "https://bmc", Err: &net.DNSError{Err: "no such host", Name: "bmc", IsNotFound: true}}, CategoryDNS},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", Name: "bmc", IsTimeout: true}, CategoryDNS},
+		{"dial timeout", dial(&timeoutError{}), CategoryConnectTimeout},
+		{"connect phase", &httptimeout.Error{Phase: httptimeout.PhaseConnect, Err: context.DeadlineExceeded}, CategoryConnectTimeout},
+		{"TLS handshake phase", &httptimeout.Error{Phase: httptimeout.PhaseTLSHandshake, Err: &timeoutError{}}, CategoryTimeout},
+		{"deadline exceeded", fmt.Errorf("list systems: %w", context.DeadlineExceeded), CategoryTimeout},
+		{"401", &StatusError{Method: "GET", Path: "/redfish/v1/Systems", Status: "401 Unauthorized", Code: 401}, CategoryAuth},
+		{"403 wrapped", fmt.Errorf("systems: %w", &StatusError{Status: "403 Forbidden", Code: 403}), CategoryAuth},
+		{"unknown authority", explainTLSError("bmc", &url.Error{Op: "Get", URL: "https://bmc", Err: x509.UnknownAuthorityError{}}), CategoryTLS},
+		{"plain HTTP", errors.New("http: server gave HTTP response to HTTPS client"), CategoryTLS},
+		{"500", &StatusError{Status: "500 Internal Server Error", Code: 500}, CategoryRedfishError},
+		{"bad JSON", &json.SyntaxError{}, CategoryRedfishError},
+		{"no NICs", fmt.Errorf("x9000c1s0b0: %w", ErrNoBootableNICs), CategoryNoNICs},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Categorize(c.err); got != c.want {
+				t.Errorf("Categorize(%v) = %q, want %q", c.err, got, c.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
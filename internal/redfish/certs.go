@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"bootstrap/internal/httptimeout"
+)
+
+// ErrCertificateServiceUnsupported is returned, wrapped with the BMC's
+// Redfish version, when a BMC has no CertificateService.
+var ErrCertificateServiceUnsupported = errors.New("CertificateService not supported")
+
+const (
+	certificateServicePath = "/CertificateService"
+	defaultGenerateCSRPath = certificateServicePath + "/Actions/CertificateService.GenerateCSR"
+)
+
+// certVerifyAttempts and certVerifyDelay bound how long VerifyCertificate
+// waits for the BMC's web server to restart with the new certificate.
+var (
+	certVerifyAttempts = 5
+	certVerifyDelay    = 3 * time.Second
+)
+
+type rfCertificateService struct {
+	Actions struct {
+		Replace struct {
+			Target string `json:"target"`
+		} `json:"#CertificateService.ReplaceCertificate"`
+		GenerateCSR struct {
+			Target string `json:"target"`
+		} `json:"#CertificateService.GenerateCSR"`
+	} `json:"Actions"`
+}
+
+// CSRRequest holds the subject of a certificate signing request. The
+// Redfish GenerateCSR action requires every field except AlternativeNames.
+type CSRRequest struct {
+	CommonName         string
+	AlternativeNames   []string
+	Organization       string
+	OrganizationalUnit string
+	City               string
+	State              string
+	Country            string
+}
+
+// certificateService returns the CertificateService of the BMC, or
+// ErrCertificateServiceUnsupported with the Redfish version it reports.
+func (c *client) certificateService(ctx context.Context) (rfCertificateService, error) {
+	var svc rfCertificateService
+	err := c.get(ctx, certificateServicePath, &svc)
+	if isStatus(err, http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented) {
+		var root struct {
+			RedfishVersion string `json:"RedfishVersion"`
+		}
+		version := "unknown"
+		if c.get(ctx, c.base, &root) == nil && root.RedfishVersion != "" {
+			version = root.RedfishVersion
+		}
+		return svc, fmt.Errorf("%w (Redfish %s)", ErrCertificateServiceUnsupported, version)
+	}
+	return svc, err
+}
+
+// httpsCertificates returns the URI of the HTTPS certificate collection of
+// the first Manager, as linked from its NetworkProtocol resource.
+func (c *client) httpsCertificates(ctx context.Context) (string, error) {
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil {
+		return "", err
+	}
+	if len(managers) == 0 {
+		return "", errors.New("no managers reported by BMC")
+	}
+	var np rfNetworkProtocol
+	if err := c.get(ctx, managers[0]+"/NetworkProtocol", &np); err != nil {
+		return "", err
+	}
+	if np.HTTPS.Certificates.OID == "" {
+		return managers[0] + "/NetworkProtocol/HTTPS/Certificates", nil
+	}
+	return np.HTTPS.Certificates.OID, nil
+}
+
+// InstallCertificate installs the PEM certificate (followed by its private
+// key when the BMC did not generate the key itself) as the HTTPS
+// certificate of host. An existing certificate is replaced with the
+// CertificateService.ReplaceCertificate action; otherwise the certificate
+// is POSTed to the empty collection. It returns the URI it wrote.
+func InstallCertificate(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, pem string) (string, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	svc, err := c.certificateService(ctx)
+	if err != nil {
+		return "", err
+	}
+	coll, err := c.httpsCertificates(ctx)
+	if err != nil {
+		return "", err
+	}
+	members, err := c.listMembers(ctx, coll)
+	if err != nil {
+		return "", err
+	}
+	// The web server restarts with the new certificate
+	defer forgetHost(host)
+	if len(members) > 0 && svc.Actions.Replace.Target != "" {
+		return members[0], c.post(ctx, svc.Actions.Replace.Target, map[string]any{
+			"CertificateString": pem,
+			"CertificateType":   "PEM",
+			"CertificateUri":    map[string]string{"@odata.id": members[0]},
+		})
+	}
+	return coll, c.post(ctx, coll, map[string]any{
+		"CertificateString": pem,
+		"CertificateType":   "PEM",
+	})
+}
+
+// GenerateCSR asks host to create a key pair for its HTTPS certificate and
+// returns the PEM signing request for it.
+func GenerateCSR(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, req CSRRequest) (string, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	svc, err := c.certificateService(ctx)
+	if err != nil {
+		return "", err
+	}
+	coll, err := c.httpsCertificates(ctx)
+	if err != nil {
+		return "", err
+	}
+	target := svc.Actions.GenerateCSR.Target
+	if target == "" {
+		target = defaultGenerateCSRPath
+	}
+	body := map[string]any{
+		"CertificateCollection": map[string]string{"@odata.id": coll},
+		"CommonName":            req.CommonName,
+		"Organization":          req.Organization,
+		"OrganizationalUnit":    req.OrganizationalUnit,
+		"City":                  req.City,
+		"State":                 req.State,
+		"Country":               req.Country,
+	}
+	if len(req.AlternativeNames) > 0 {
+		body["AlternativeNames"] = req.AlternativeNames
+	}
+	var resp struct {
+		CSRString string `json:"CSRString"`
+	}
+	if err := c.postDecode(ctx, target, body, &resp); err != nil {
+		return "", err
+	}
+	if resp.CSRString == "" {
+		return "", errors.New("GenerateCSR returned no CSRString")
+	}
+	return resp.CSRString, nil
+}
+
+// VerifyCertificate connects to host with certificate verification against
+// the CAs set by ConfigureTLS (or the system roots) and reads the service
+// root, retrying while the BMC restarts its web server. Each attempt uses a
+// new connection so the old certificate cannot be seen through a kept one.
+func VerifyCertificate(ctx context.Context, host string, timeout time.Duration) error {
+	serverName := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		serverName = h
+	}
+	var err error
+	for attempt := 0; attempt < certVerifyAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(certVerifyDelay):
+			}
+		}
+		cfg := tlsConfig(host, false)
+		// The new certificate is expected to differ from a pinned one
+		cfg.VerifyConnection = nil
+		cfg.ServerName = serverName
+		c := newClientWith(httptimeout.Apply(&http.Transport{Proxy: proxy, TLSClientConfig: cfg, DisableKeepAlives: true}), host, "", "", timeout)
+		var root map[string]any
+		if err = c.get(ctx, c.base, &root); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// certsBMC serves a CertificateService whose HTTPS collection holds
+// installed certificates (none to start with), and records the actions
+// posted to it.
+func certsBMC(t *testing.T, installed int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var posted []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/redfish/v1/CertificateService":
+			_, _ = w.Write([]byte(`{"Actions":{
+				"#CertificateService.ReplaceCertificate":{"target":"/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate"},
+				"#CertificateService.GenerateCSR":{"target":"/redfish/v1/CertificateService/Actions/CertificateService.GenerateCSR"}}}`))
+		case "/redfish/v1/Managers":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Managers/bmc"}]}`))
+		case "/redfish/v1/Managers/bmc/NetworkProtocol":
+			_, _ = w.Write([]byte(`{"HTTPS":{"Certificates":{"@odata.id":"/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates"}}}`))
+		case "/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates":
+			if r.Method == "GET" {
+				members := []map[string]string{}
+				for i := 1; i <= installed; i++ {
+					members = append(members, map[string]string{"@odata.id": "/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates/" + strconv.Itoa(i)})
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"Members": members})
+				return
+			}
+			fallthrough
+		default:
+			if r.Method != "POST" {
+				http.NotFound(w, r)
+				return
+			}
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			posted = append(posted, r.URL.Path+" "+string(b))
+			if strings.HasSuffix(r.URL.Path, ".GenerateCSR") {
+				_, _ = w.Write([]byte(`{"CSRString":"-----BEGIN CERTIFICATE REQUEST-----\nMIIB\n-----END CERTIFICATE REQUEST-----\n"}`))
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &posted
+}
+
+func TestInstallCertificate(t *testing.T) {
+	// An existing certificate is replaced in place
+	srv, posted := certsBMC(t, 1)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	uri, err := InstallCertificate(context.Background(), host, "u", "p", true, 5*time.Second, "PEM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate {"CertificateString":"PEM","CertificateType":"PEM","CertificateUri":{"@odata.id":"/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates/1"}}`
+	if uri != "/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates/1" || len(*posted) != 1 || (*posted)[0] != want {
+		t.Errorf("InstallCertificate = %s, posted %v", uri, *posted)
+	}
+
+	// An empty collection gets a new member
+	srv, posted = certsBMC(t, 0)
+	host = strings.TrimPrefix(srv.URL, "https://")
+	if _, err := InstallCertificate(context.Background(), host, "u", "p", true, 5*time.Second, "PEM"); err != nil {
+		t.Fatal(err)
+	}
+	want = `/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates {"CertificateString":"PEM","CertificateType":"PEM"}`
+	if len(*posted) != 1 || (*posted)[0] != want {
+		t.Errorf("posted %v, want %s", *posted, want)
+	}
+}
+
+func TestGenerateCSR(t *testing.T) {
+	srv, posted := certsBMC(t, 1)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	csr, err := GenerateCSR(context.Background(), host, "u", "p", true, 5*time.Second, CSRRequest{CommonName: "10.1.0.5", AlternativeNames: []string{"x9000c1s0b0"}, Country: "US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(csr, "-----BEGIN CERTIFICATE REQUEST-----") {
+		t.Errorf("CSR = %q", csr)
+	}
+	if len(*posted) != 1 || !strings.Contains((*posted)[0], `"AlternativeNames":["x9000c1s0b0"]`) ||
+		!strings.Contains((*posted)[0], `"CertificateCollection":{"@odata.id":"/redfish/v1/Managers/bmc/NetworkProtocol/HTTPS/Certificates"}`) ||
+		!strings.Contains((*posted)[0], `"CommonName":"10.1.0.5"`) {
+		t.Errorf("posted %v", *posted)
+	}
+}
+
+func TestCertificateServiceUnsupported(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redfish/v1" {
+			_, _ = w.Write([]byte(`{"RedfishVersion":"1.0.2"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	_, err := InstallCertificate(context.Background(), host, "u", "p", true, 5*time.Second, "PEM")
+	if !errors.Is(err, ErrCertificateServiceUnsupported) || !strings.Contains(err.Error(), "Redfish 1.0.2") {
+		t.Errorf("err = %v, want unsupported with the Redfish version", err)
+	}
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"RedfishVersion":"1.6.0"}`))
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	t.Cleanup(func() { _ = ConfigureTLS(TLSOptions{}) })
+	oldAttempts, oldDelay := certVerifyAttempts, certVerifyDelay
+	certVerifyAttempts, certVerifyDelay = 2, time.Millisecond
+	defer func() { certVerifyAttempts, certVerifyDelay = oldAttempts, oldDelay }()
+
+	if err := VerifyCertificate(context.Background(), host, 5*time.Second); err == nil {
+		t.Error("a certificate from an unknown CA was accepted")
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureTLS(TLSOptions{CACertFile: caFile}); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyCertificate(context.Background(), host, 5*time.Second); err != nil {
+		t.Errorf("verification with the CA failed: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"bootstrap/internal/httptimeout"
+)
+
+// Outcomes reported by CheckHost, from worst to best.
+const (
+	CheckUnreachable = "unreachable"
+	CheckTLSError    = "tls-error"
+	CheckAuthFailed  = "auth-failed"
+	CheckError       = "error" // reachable, but not a working Redfish service
+	CheckOK          = "ok"
+)
+
+// CheckResult is the outcome of CheckHost. Latency is the round trip of the
+// service root request and RedfishVersion the version it reported.
+type CheckResult struct {
+	Status         string
+	Latency        time.Duration
+	RedfishVersion string
+	Err            error
+}
+
+// CheckHost opens a TCP connection to host (port 443 unless host names one)
+// within the httptimeout connect timeout, fetches the Redfish service root,
+// and, when authenticate is set, reads the Systems collection to confirm
+// that user and pass are accepted.
+func CheckHost(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, authenticate bool) CheckResult {
+	addr := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addr = net.JoinHostPort(host, "443")
+	}
+	// Through a proxy the BMC cannot be dialed directly; the request below
+	// finds out whether the proxy reaches it
+	if !proxied(host) {
+		conn, err := httptimeout.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return CheckResult{Status: CheckUnreachable, Err: err}
+		}
+		conn.Close() // nolint:errcheck
+	}
+
+	c := newClient(host, user, pass, insecure, timeout)
+	start := time.Now()
+	root, err := c.getServiceRoot(ctx, host)
+	res := CheckResult{Latency: time.Since(start), RedfishVersion: root.RedfishVersion}
+	if err == nil && authenticate {
+		var systems rfCollection
+		err = c.get(ctx, "/Systems", &systems)
+	}
+	res.Status, res.Err = classifyCheckError(err), err
+	return res
+}
+
+// classifyCheckError maps a request error to a CheckHost outcome.
+func classifyCheckError(err error) string {
+	var (
+		verr *tls.CertificateVerificationError
+		uerr x509.UnknownAuthorityError
+		herr x509.HostnameError
+		rerr tls.RecordHeaderError
+		aerr tls.AlertError
+	)
+	switch {
+	case err == nil:
+		return CheckOK
+	case isStatus(err, http.StatusUnauthorized, http.StatusForbidden):
+		return CheckAuthFailed
+	case errors.As(err, &verr), errors.As(err, &uerr), errors.As(err, &herr),
+		errors.As(err, &rerr), errors.As(err, &aerr), strings.Contains(err.Error(), "tls: "),
+		strings.Contains(err.Error(), "HTTP response to HTTPS client"):
+		return CheckTLSError
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return CheckUnreachable
+	}
+	return CheckError
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckHost(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1":
+			fmt.Fprint(w, `{"RedfishVersion":"1.11.0"}`)
+		case "/redfish/v1/Systems":
+			if u, p, _ := r.BasicAuth(); u != "admin" || p != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"Members":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := httptest.NewTLSServer(handler)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+
+	// A port nothing listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := l.Addr().String()
+	l.Close() // nolint:errcheck
+
+	ctx := context.Background()
+	tests := []struct {
+		name, host, user, pass string
+		auth                   bool
+		want                   string
+	}{
+		{"ok", host, "admin", "secret", true, CheckOK},
+		{"anonymous root", host, "", "", false, CheckOK},
+		{"bad password", host, "admin", "wrong", true, CheckAuthFailed},
+		{"plain http", strings.TrimPrefix(plain.URL, "http://"), "admin", "secret", true, CheckTLSError},
+		{"nothing listening", closed, "admin", "secret", true, CheckUnreachable},
+	}
+	for _, tt := range tests {
+		res := CheckHost(ctx, tt.host, tt.user, tt.pass, true, 2*time.Second, tt.auth)
+		if res.Status != tt.want {
+			t.Errorf("%s: status %s (%v), want %s", tt.name, res.Status, res.Err, tt.want)
+		}
+		if tt.want == CheckOK && (res.RedfishVersion != "1.11.0" || res.Latency <= 0) {
+			t.Errorf("%s: version %q latency %s", tt.name, res.RedfishVersion, res.Latency)
+		}
+	}
+
+	// Certificate verification failures are TLS errors too
+	if res := CheckHost(ctx, host, "admin", "secret", false, 2*time.Second, true); res.Status != CheckTLSError {
+		t.Errorf("untrusted certificate: status %s (%v), want %s", res.Status, res.Err, CheckTLSError)
+	}
+}
@@ -7,16 +7,21 @@ package redfish
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"bootstrap/internal/diag"
+	"bootstrap/internal/httplimit"
+	"bootstrap/internal/httptimeout"
+	"bootstrap/internal/ratelimit"
 )
 
 type client struct {
@@ -26,14 +31,17 @@ type client struct {
 	pass string
 }
 
+// newClient returns a client for host that shares the host's pooled
+// transport, and so its keep-alive connections, with every other client of
+// host in this run.
 func newClient(host, user, pass string, insecure bool, timeout time.Duration) *client {
-	tr := &http.Transport{}
-	if insecure {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
+	return newClientWith(sharedTransport(host, insecure), host, user, pass, timeout)
+}
+
+func newClientWith(tr http.RoundTripper, host, user, pass string, timeout time.Duration) *client {
 	return &client{
 		base: "https://" + host + "/redfish/v1",
-		http: &http.Client{Timeout: timeout, Transport: tr},
+		http: &http.Client{Timeout: timeout, Transport: diag.Transport(tr)},
 		user: user,
 		pass: pass,
 	}
@@ -43,11 +51,19 @@ type rfCollection struct {
 	Members []struct {
 		OID string `json:"@odata.id"`
 	} `json:"Members"`
+	NextLink       string `json:"Members@odata.nextLink"`
+	LegacyNextLink string `json:"@odata.nextLink"`
 }
 
+// maxCollectionPages bounds how many nextLink pages are followed, so a
+// misbehaving BMC cannot keep a client paging forever.
+const maxCollectionPages = 100
+
 type rfEthernetInterface struct {
 	ID               string `json:"Id"`
 	Name             string `json:"Name"`
+	Description      string `json:"Description"`
+	LinkStatus       string `json:"LinkStatus"`
 	InterfaceEnabled *bool  `json:"InterfaceEnabled"`
 	MACAddress       string `json:"MACAddress"`
 	UefiDevicePath   string `json:"UefiDevicePath"`
@@ -55,6 +71,8 @@ type rfEthernetInterface struct {
 		Address string `json:"Address"`
 		Origin  string `json:"AddressOrigin"`
 	} `json:"IPv4Addresses"`
+	// Oem is kept raw for vendor rules (see oemPXE).
+	Oem json.RawMessage `json:"Oem"`
 }
 
 type rfFirmwareInventory struct {
@@ -73,7 +91,8 @@ type rfFirmwareInventory struct {
 }
 
 type rfUpdateService struct {
-	Status struct {
+	MultipartHTTPPushURI string `json:"MultipartHttpPushUri"`
+	Status               struct {
 		Health     string `json:"Health"`
 		State      string `json:"State"`
 		Conditions []struct {
@@ -122,12 +141,6 @@ func GetUpdateServiceStatus(ctx context.Context, host, user, pass string, insecu
 	return out, nil
 }
 
-type rfTaskCollection struct {
-	Members []struct {
-		OID string `json:"@odata.id"`
-	} `json:"Members"`
-}
-
 type rfTask struct {
 	ID        string `json:"Id"`
 	Name      string `json:"Name"`
@@ -140,14 +153,14 @@ type rfTask struct {
 // TaskState values and checks Name/Message for update/firmware keywords.
 func GetActiveUpdateTasks(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]string, error) {
 	c := newClient(host, user, pass, insecure, timeout)
-	var coll rfTaskCollection
-	if err := c.get(ctx, "/TaskService/Tasks", &coll); err != nil {
+	members, err := c.listMembers(ctx, "/TaskService/Tasks")
+	if err != nil {
 		return nil, err
 	}
 	var out []string
-	for _, m := range coll.Members {
+	for _, m := range members {
 		var t rfTask
-		if err := c.get(ctx, m.OID, &t); err != nil {
+		if err := c.get(ctx, m, &t); err != nil {
 			// skip tasks we can't fetch
 			continue
 		}
@@ -208,35 +221,154 @@ func GetFirmwareInventory(ctx context.Context, host, user, pass string, insecure
 	return out, nil
 }
 
+// FirmwareComponent is one member of the UpdateService FirmwareInventory.
+type FirmwareComponent struct {
+	Path       string
+	ID         string
+	Name       string
+	Version    string
+	Updateable bool
+}
+
+type rfFirmwareComponent struct {
+	ID         string `json:"Id"`
+	Name       string `json:"Name"`
+	Version    string `json:"Version"`
+	Updateable bool   `json:"Updateable"`
+}
+
+// ListFirmwareInventory reads every member of the FirmwareInventory
+// collection of host, following all of its pages.
+func ListFirmwareInventory(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]FirmwareComponent, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	members, err := c.listMembers(ctx, "/UpdateService/FirmwareInventory")
+	if err != nil {
+		return nil, err
+	}
+	rf, err := fetchAll[rfFirmwareComponent](ctx, c, members)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FirmwareComponent, len(rf))
+	for i, fw := range rf {
+		id := fw.ID
+		if id == "" {
+			id = path.Base(members[i])
+		}
+		out[i] = FirmwareComponent{Path: members[i], ID: id, Name: fw.Name, Version: fw.Version, Updateable: fw.Updateable}
+	}
+	return out, nil
+}
+
+// StatusError is returned when a Redfish request gets a non-2xx response.
+type StatusError struct {
+	Method string
+	Path   string
+	Status string // e.g. "404 Not Found"
+	Code   int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	if e.Method == http.MethodGet {
+		return fmt.Sprintf("redfish %s: %s: %s", e.Path, e.Status, e.Body)
+	}
+	return fmt.Sprintf("redfish %s %s: %s: %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+// newStatusError reads the body of a failed response into a StatusError.
+func newStatusError(resp *http.Response, path string) error {
+	b, _ := io.ReadAll(resp.Body)
+	return &StatusError{
+		Method: resp.Request.Method,
+		Path:   path,
+		Status: resp.Status,
+		Code:   resp.StatusCode,
+		Body:   strings.TrimSpace(string(b)),
+	}
+}
+
+// isStatus reports whether err is a StatusError with one of the given codes.
+func isStatus(err error, codes ...int) bool {
+	var se *StatusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	for _, c := range codes {
+		if se.Code == c {
+			return true
+		}
+	}
+	return false
+}
+
+// limiter spaces out requests from every client; set by ConfigureRateLimit.
+var limiter *ratelimit.Limiter
+
+// ConfigureRateLimit caps subsequent requests at perHost per second to any
+// one BMC and global per second in total. Zero disables either limit.
+func ConfigureRateLimit(perHost, global float64) {
+	limiter = ratelimit.New(perHost, global)
+}
+
+// do sends req once the rate limit allows it, explaining certificate
+// verification failures and naming the phase of a timeout. The response
+// body is capped by httplimit. GETs are recorded in, or replayed from, the
+// cache set by ConfigureCache.
+func (c *client) do(req *http.Request) (*http.Response, error) {
+	if cache.Replay {
+		return replayResponse(req)
+	}
+	if err := limiter.Wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+	resp, err := httptimeout.Do(c.http, req)
+	if err != nil {
+		return nil, explainTLSError(req.URL.Host, err)
+	}
+	httplimit.Limit(resp)
+	if cache.Dir != "" && req.Method == http.MethodGet && resp.StatusCode < 300 {
+		if err := recordResponse(req, resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
 func (c *client) get(ctx context.Context, path string, v any) error {
 	path = c.resolvePath(path)
-	diag.Logf("GET %s", path)
 	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
 	if err != nil {
 		return err
 	}
-	req.SetBasicAuth(c.user, c.pass)
+	// The service root may be read anonymously
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
 	req.Header.Set("Accept", "application/json")
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close() // nolint:errcheck
-	diag.Logf("GET %s -> %s", path, resp.Status)
 	if resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("redfish %s: %s: %s", path, resp.Status, strings.TrimSpace(string(b)))
+		return newStatusError(resp, path)
 	}
 	return json.NewDecoder(resp.Body).Decode(v)
 }
 
 func (c *client) post(ctx context.Context, path string, body any) error {
+	return c.postDecode(ctx, path, body, nil)
+}
+
+// postDecode is like post but decodes the response body into v unless v is
+// nil.
+func (c *client) postDecode(ctx context.Context, path string, body, v any) error {
 	path = c.resolvePath(path)
 	b, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
-	diag.Logf("POST %s", path)
 	req, err := http.NewRequestWithContext(ctx, "POST", path, strings.NewReader(string(b)))
 	if err != nil {
 		return err
@@ -244,101 +376,268 @@ func (c *client) post(ctx context.Context, path string, body any) error {
 	req.SetBasicAuth(c.user, c.pass)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode >= 300 {
+		return newStatusError(resp, path)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *client) delete(ctx context.Context, path string) error {
+	path = c.resolvePath(path)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.user, c.pass)
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close() // nolint:errcheck
-	diag.Logf("POST %s -> %s", path, resp.Status)
 	if resp.StatusCode >= 300 {
-		rb, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("redfish POST %s: %s: %s", path, resp.Status, strings.TrimSpace(string(rb)))
+		return newStatusError(resp, path)
 	}
 	return nil
 }
 
+// patch PATCHes path with If-Match set to the ETag of a fresh GET of it (see
+// patchWithETag).
 func (c *client) patch(ctx context.Context, path string, body any) error {
+	etag, err := c.fetchETag(ctx, path)
+	if err != nil {
+		return err
+	}
+	return c.patchWithETag(ctx, path, body, etag)
+}
+
+// patchWithETag PATCHes path with If-Match set to etag, the ETag the caller
+// read the resource with. A 412 Precondition Failed means the resource
+// changed since; its ETag is read again and the PATCH retried once. Every
+// write of a resource goes through here or patch, so BMCs that require
+// If-Match (iLO, OpenBMC) accept it and concurrent tools do not silently
+// overwrite each other.
+func (c *client) patchWithETag(ctx context.Context, path string, body any, etag string) error {
+	err := c.patchIfMatch(ctx, path, body, etag)
+	if !isStatus(err, http.StatusPreconditionFailed) {
+		return err
+	}
+	diag.Logf("PATCH %s: ETag %s is stale; refetching and retrying once", path, etag)
+	if etag, err = c.fetchETag(ctx, path); err != nil {
+		return err
+	}
+	return c.patchIfMatch(ctx, path, body, etag)
+}
+
+// fetchETag returns the ETag of path, or "" when the BMC does not report one.
+func (c *client) fetchETag(ctx context.Context, path string) (string, error) {
+	var ignored json.RawMessage
+	return c.getWithETag(ctx, path, &ignored)
+}
+
+// patchIfMatch sends a PATCH with an If-Match header when etag is non-empty.
+func (c *client) patchIfMatch(ctx context.Context, path string, body any, etag string) error {
+	path = c.resolvePath(path)
 	b, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
-	diag.Logf("PATCH %s", path)
-	req, err := http.NewRequestWithContext(ctx, "PATCH", c.base+path, strings.NewReader(string(b)))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", path, strings.NewReader(string(b)))
 	if err != nil {
 		return err
 	}
 	req.SetBasicAuth(c.user, c.pass)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.http.Do(req)
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close() // nolint:errcheck
-	diag.Logf("PATCH %s -> %s", path, resp.Status)
 	if resp.StatusCode >= 300 {
-		rb, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("redfish PATCH %s: %s: %s", path, resp.Status, strings.TrimSpace(string(rb)))
+		return newStatusError(resp, path)
 	}
 	return nil
 }
 
-func (c *client) firstSystemPath(ctx context.Context) (string, error) {
-	var coll rfCollection
-	if err := c.get(ctx, "/Systems", &coll); err != nil {
+// getWithETag is like get but also returns the resource's ETag, taken from the
+// ETag response header or, failing that, the @odata.etag property.
+func (c *client) getWithETag(ctx context.Context, path string, v any) (string, error) {
+	path = c.resolvePath(path)
+	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.user, c.pass)
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode >= 300 {
+		return "", newStatusError(resp, path)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
 		return "", err
 	}
-	if len(coll.Members) == 0 {
-		return "", errors.New("no systems reported by BMC")
+	if err := json.Unmarshal(raw, v); err != nil {
+		return "", err
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		var meta struct {
+			ETag string `json:"@odata.etag"`
+		}
+		if json.Unmarshal(raw, &meta) == nil {
+			etag = meta.ETag
+		}
 	}
-	return coll.Members[0].OID, nil
+	return etag, nil
+}
+
+// listMembers returns the @odata.id of every member of a collection,
+// following Members@odata.nextLink (or the legacy @odata.nextLink) across pages.
+func (c *client) listMembers(ctx context.Context, path string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for page := 0; path != ""; page++ {
+		if page == maxCollectionPages || seen[path] {
+			return nil, fmt.Errorf("redfish %s: collection paging did not terminate after %d page(s)", path, page)
+		}
+		seen[path] = true
+		var coll rfCollection
+		if err := c.get(ctx, path, &coll); err != nil {
+			return nil, err
+		}
+		for _, m := range coll.Members {
+			out = append(out, m.OID)
+		}
+		path = coll.NextLink
+		if path == "" {
+			path = coll.LegacyNextLink
+		}
+	}
+	return out, nil
+}
+
+func (c *client) firstSystemPath(ctx context.Context) (string, error) {
+	paths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return "", err
+	}
+	return paths[0], nil
 }
 
 func (c *client) listSystemPaths(ctx context.Context) ([]string, error) {
-	var coll rfCollection
-	if err := c.get(ctx, "/Systems", &coll); err != nil {
+	paths, err := c.listMembers(ctx, "/Systems")
+	if err != nil {
 		return nil, err
 	}
-	if len(coll.Members) == 0 {
+	if len(paths) == 0 {
 		return nil, errors.New("no systems reported by BMC")
 	}
-	paths := make([]string, len(coll.Members))
-	for i, member := range coll.Members {
-		paths[i] = member.OID
-	}
 	return paths, nil
 }
 
+// listEthernetInterfaces reads every EthernetInterface of sysPath, in one
+// request when the service supports $expand and otherwise one per member.
 func (c *client) listEthernetInterfaces(ctx context.Context, sysPath string) ([]rfEthernetInterface, error) {
-	var coll rfCollection
-	if err := c.get(ctx, sysPath+"/EthernetInterfaces", &coll); err != nil {
+	if c.supportsExpand(ctx) {
+		nics, ok := expandedMembers(ctx, c, sysPath+"/EthernetInterfaces", func(nic rfEthernetInterface) bool {
+			return nic.ID != "" && nic.MACAddress != ""
+		})
+		if ok {
+			return nics, nil
+		}
+	}
+	members, err := c.listMembers(ctx, sysPath+"/EthernetInterfaces")
+	if err != nil {
 		return nil, err
 	}
-	var out []rfEthernetInterface
-	for _, m := range coll.Members {
-		var nic rfEthernetInterface
-		if err := c.get(ctx, m.OID, &nic); err != nil {
-			return nil, err
+	return fetchAll[rfEthernetInterface](ctx, c, members)
+}
+
+// memberFetchWorkers bounds the concurrent member GETs made against one BMC.
+const memberFetchWorkers = 4
+
+// fetchAll GETs every member path with up to memberFetchWorkers requests in
+// flight and returns the results in member order. The first failure cancels
+// the remaining requests and is returned.
+func fetchAll[T any](ctx context.Context, c *client, members []string) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	out := make([]T, len(members))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, memberFetchWorkers)
+loop:
+	for i, m := range members {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
 		}
-		out = append(out, nic)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := c.get(ctx, m, &out[i]); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
-func isBootable(n rfEthernetInterface) bool {
+// isBootable reports whether n looks like a NIC to PXE boot from, and the
+// rule that decided it: a UefiDevicePath naming PXE, an IP stack, or a MAC;
+// an IPv4 address obtained by DHCP; or else a MAC on an interface that is
+// not disabled.
+func isBootable(n rfEthernetInterface) (bool, string) {
 	uefi := strings.ToLower(n.UefiDevicePath)
-	if strings.Contains(uefi, "pxe") || strings.Contains(uefi, "ipv4") || strings.Contains(uefi, "ipv6") || strings.Contains(uefi, "mac(") {
-		return true
+	for _, token := range []string{"pxe", "ipv4", "ipv6", "mac("} {
+		if strings.Contains(uefi, token) {
+			return true, fmt.Sprintf("UefiDevicePath contains %q", token)
+		}
 	}
 	for _, a := range n.IPv4Addresses {
 		if strings.EqualFold(a.Origin, "dhcp") {
-			return true
+			return true, "an IPv4 address has AddressOrigin DHCP"
 		}
 	}
-	if n.MACAddress != "" && (n.InterfaceEnabled == nil || *n.InterfaceEnabled) {
-		return true
+	switch {
+	case n.MACAddress == "":
+		return false, "no MAC address"
+	case n.InterfaceEnabled != nil && !*n.InterfaceEnabled:
+		return false, "InterfaceEnabled is false, and neither its UefiDevicePath nor a DHCP address marks it bootable"
 	}
-	return false
+	return true, "it has a MAC address and is not disabled"
 }
 
 // isValidMAC checks if a MAC address string is valid
@@ -371,54 +670,102 @@ func isValidMAC(mac string) bool {
 type SystemMACs struct {
 	SystemPath string
 	MACs       []string
+	// Status is the system's Status, empty when it could not be read.
+	Status ResourceStatus
+	// Details are the system's asset details, read with it; nil when the
+	// system could not be read.
+	Details *SystemDetails
 }
 
-// DiscoverAllBootableMACs returns bootable MAC addresses for all systems on a BMC.
-// Returns a slice of SystemMACs, one entry per system (e.g., Node0, Node1).
-func DiscoverAllBootableMACs(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]SystemMACs, error) {
+// DiscoverAllBootableMACs returns bootable MAC addresses for all systems on a BMC,
+// chosen with rules and the vendor of host, when known (see bootableMACs).
+// Returns a slice of SystemMACs, one entry per system (e.g., Node0, Node1),
+// with the Status of each system so that callers can leave out absent ones.
+func DiscoverAllBootableMACs(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, rules NICRules) ([]SystemMACs, error) {
 	c := newClient(host, user, pass, insecure, timeout)
 	sysPaths, err := c.listSystemPaths(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	// Oem PXE marks are read only from BMCs not known to be of another vendor
+	vendor, _ := Vendor(host)
 	result := make([]SystemMACs, 0, len(sysPaths))
 	for _, sysPath := range sysPaths {
+		var sys rfComputerSystem
+		var details *SystemDetails
+		if err := c.get(ctx, sysPath, &sys); err != nil {
+			// Without a Status the system is not gated on it
+			diag.Logf("%s: %v; status unknown", sysPath, err)
+		} else {
+			details = sys.details()
+		}
 		nics, err := c.listEthernetInterfaces(ctx, sysPath)
 		if err != nil {
 			// Skip this system but continue with others
+			if rules.Explain {
+				explainError(ctx, host, sysPath, err)
+			}
 			continue
 		}
 
-		// collect bootable MACs, fallback to first valid MAC if none
-		macs := make([]string, 0, len(nics))
-		for _, nic := range nics {
-			if !isValidMAC(nic.MACAddress) {
-				continue
-			}
-			if isBootable(nic) {
-				macs = append(macs, strings.ToLower(nic.MACAddress))
-			}
+		macs, decisions := bootableMACs(nics, rules, vendor)
+		if rules.Explain {
+			explainNICs(ctx, host, sysPath, decisions)
 		}
-		if len(macs) == 0 {
-			for _, nic := range nics {
-				if isValidMAC(nic.MACAddress) {
-					macs = append(macs, strings.ToLower(nic.MACAddress))
-					break
-				}
-			}
-		}
-
 		if len(macs) > 0 {
 			result = append(result, SystemMACs{
 				SystemPath: sysPath,
 				MACs:       macs,
+				Status:     sys.Status,
+				Details:    details,
 			})
 		}
 	}
 	return result, nil
 }
 
+// SystemDetails is the asset information of a ComputerSystem.
+type SystemDetails struct {
+	SerialNumber string
+	Model        string
+	SKU          string
+}
+
+// GetSystemDetails returns the SerialNumber, Model, and SKU of the system at
+// sysPath (as returned in SystemMACs.SystemPath). Discovery already returns
+// them in SystemMACs.Details; this reads them again.
+func GetSystemDetails(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, sysPath string) (SystemDetails, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	var sys rfComputerSystem
+	if err := c.get(ctx, sysPath, &sys); err != nil {
+		return SystemDetails{}, err
+	}
+	return *sys.details(), nil
+}
+
+// details returns the asset details of s.
+func (s rfComputerSystem) details() *SystemDetails {
+	return &SystemDetails{SerialNumber: s.SerialNumber, Model: s.Model, SKU: s.SKU}
+}
+
+// GetManagerFirmwareVersion returns the FirmwareVersion of the first Manager on host.
+func GetManagerFirmwareVersion(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (string, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil {
+		return "", err
+	}
+	if len(managers) == 0 {
+		return "", errors.New("no managers reported by BMC")
+	}
+	var mgr rfManager
+	if err := c.get(ctx, managers[0], &mgr); err != nil {
+		return "", err
+	}
+	return mgr.FirmwareVersion, nil
+}
+
 // DiscoverBootableMACs returns MAC addresses of bootable NICs for the first system on a BMC.
 // Deprecated: Use DiscoverAllBootableMACs to discover all systems on a BMC.
 func DiscoverBootableMACs(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]string, error) {
@@ -437,7 +784,7 @@ func DiscoverBootableMACs(ctx context.Context, host, user, pass string, insecure
 		if !isValidMAC(nic.MACAddress) {
 			continue
 		}
-		if isBootable(nic) {
+		if ok, _ := isBootable(nic); ok {
 			macs = append(macs, strings.ToLower(nic.MACAddress))
 		}
 	}
@@ -454,35 +801,14 @@ func DiscoverBootableMACs(ctx context.Context, host, user, pass string, insecure
 
 // SimpleUpdate triggers a Redfish SimpleUpdate action on the given targets.
 // imageURI is a URL accessible by the BMC (e.g., http/https), targets are the FirmwareInventory targets.
-// transferProtocol is typically "HTTP" or "HTTPS".
+// transferProtocol is typically "HTTP" or "HTTPS". applyTime, when set, is
+// sent as @Redfish.OperationApplyTime (see SelectApplyTime).
 // If expectedVersion is provided and force is false, the update is skipped if any target already has that version.
-func SimpleUpdate(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, imageURI string, targets []string, transferProtocol string, expectedVersion string, force bool) error {
+func SimpleUpdate(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, imageURI string, targets []string, transferProtocol, applyTime string, expectedVersion string, force bool) error {
 	c := newClient(host, user, pass, insecure, timeout)
 
-	// Check current versions if expectedVersion is provided and not forcing
-	if expectedVersion != "" && !force {
-		allAtExpectedVersion := true
-		var versionInfo []string
-
-		for _, target := range targets {
-			var fw rfFirmwareInventory
-			if err := c.get(ctx, target, &fw); err != nil {
-				// If we can't get version, proceed with update
-				allAtExpectedVersion = false
-				continue
-			}
-
-			versionInfo = append(versionInfo, fmt.Sprintf("%s: %s", target, fw.Version))
-
-			if fw.Version != expectedVersion {
-				allAtExpectedVersion = false
-			}
-		}
-
-		if allAtExpectedVersion && len(versionInfo) > 0 {
-			return fmt.Errorf("skipping update: all targets already at expected version %s\n%s",
-				expectedVersion, strings.Join(versionInfo, "\n"))
-		}
+	if err := c.checkExpectedVersion(ctx, targets, expectedVersion, force); err != nil {
+		return err
 	}
 
 	payload := map[string]any{
@@ -490,12 +816,55 @@ func SimpleUpdate(ctx context.Context, host, user, pass string, insecure bool, t
 		"TransferProtocol": transferProtocol,
 		"Targets":          targets,
 	}
+	if applyTime != "" {
+		payload["@Redfish.OperationApplyTime"] = applyTime
+	}
 	// Vendor path per provided examples
 	if err := c.post(ctx, "/UpdateService/Actions/SimpleUpdate", payload); err != nil {
 		return err
 	}
 
-	// Check firmware inventory status for any conditions/errors
+	return c.checkUpdateConditions(ctx, targets)
+}
+
+// ErrAlreadyAtVersion is returned, wrapped, when an update is skipped because
+// its targets already run the expected version. It is not a failure.
+var ErrAlreadyAtVersion = errors.New("skipping update")
+
+// checkExpectedVersion returns ErrAlreadyAtVersion when expectedVersion is
+// set, force is false, and every target already reports that version.
+func (c *client) checkExpectedVersion(ctx context.Context, targets []string, expectedVersion string, force bool) error {
+	if expectedVersion == "" || force {
+		return nil
+	}
+	allAtExpectedVersion := true
+	var versionInfo []string
+
+	for _, target := range targets {
+		var fw rfFirmwareInventory
+		if err := c.get(ctx, target, &fw); err != nil {
+			// If we can't get version, proceed with update
+			allAtExpectedVersion = false
+			continue
+		}
+
+		versionInfo = append(versionInfo, fmt.Sprintf("%s: %s", target, fw.Version))
+
+		if fw.Version != expectedVersion {
+			allAtExpectedVersion = false
+		}
+	}
+
+	if allAtExpectedVersion && len(versionInfo) > 0 {
+		return fmt.Errorf("%w: all targets already at expected version %s\n%s",
+			ErrAlreadyAtVersion, expectedVersion, strings.Join(versionInfo, "\n"))
+	}
+	return nil
+}
+
+// checkUpdateConditions reports Warning or Critical conditions on the targets
+// shortly after an update was submitted.
+func (c *client) checkUpdateConditions(ctx context.Context, targets []string) error {
 	// Wait a moment for the status to update
 	time.Sleep(2 * time.Second)
 
@@ -522,6 +891,371 @@ func SimpleUpdate(ctx context.Context, host, user, pass string, insecure bool, t
 	return nil
 }
 
+type rfComputerSystem struct {
+	ID           string         `json:"Id"`
+	Name         string         `json:"Name"`
+	PowerState   string         `json:"PowerState"`
+	SerialNumber string         `json:"SerialNumber"`
+	Model        string         `json:"Model"`
+	SKU          string         `json:"SKU"`
+	Status       ResourceStatus `json:"Status"`
+	Actions      struct {
+		Reset struct {
+			Target          string   `json:"target"`
+			AllowableValues []string `json:"ResetType@Redfish.AllowableValues"`
+		} `json:"#ComputerSystem.Reset"`
+	} `json:"Actions"`
+}
+
+// SystemPower reports the power state of a single ComputerSystem on a BMC.
+// Err is set when that system could not be queried or reset.
+type SystemPower struct {
+	SystemPath string
+	PowerState string
+	Err        error
+}
+
+// CountSystems returns the number of members of the Systems collection of
+// a BMC, without reading the systems themselves.
+func CountSystems(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (int, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	members, err := c.listMembers(ctx, "/Systems")
+	return len(members), err
+}
+
+// GetPowerStates returns the PowerState of every system on a BMC.
+// An error is returned only when the Systems collection cannot be read;
+// failures for individual systems are reported in the per-system Err field.
+func GetPowerStates(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]SystemPower, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SystemPower, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		var sys rfComputerSystem
+		if err := c.get(ctx, sysPath, &sys); err != nil {
+			out = append(out, SystemPower{SystemPath: sysPath, Err: err})
+			continue
+		}
+		out = append(out, SystemPower{SystemPath: sysPath, PowerState: sys.PowerState})
+	}
+	return out, nil
+}
+
+// ResetSystems posts a ComputerSystem.Reset action with the given ResetType
+// (e.g. On, ForceOff, GracefulShutdown, ForceRestart) to every system on a BMC.
+// The action target advertised by the system is used when present. If the
+// system lists allowable reset types and resetType is not among them, the
+// reset is not attempted and the system's Err explains why.
+func ResetSystems(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, resetType string) ([]SystemPower, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SystemPower, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		var sys rfComputerSystem
+		if err := c.get(ctx, sysPath, &sys); err != nil {
+			out = append(out, SystemPower{SystemPath: sysPath, Err: err})
+			continue
+		}
+		if allowed := sys.Actions.Reset.AllowableValues; len(allowed) > 0 && !containsFold(allowed, resetType) {
+			out = append(out, SystemPower{
+				SystemPath: sysPath,
+				PowerState: sys.PowerState,
+				Err:        fmt.Errorf("reset type %s not supported (allowed: %s)", resetType, strings.Join(allowed, ", ")),
+			})
+			continue
+		}
+		target := sys.Actions.Reset.Target
+		if target == "" {
+			target = sysPath + "/Actions/ComputerSystem.Reset"
+		}
+		err := c.post(ctx, target, map[string]any{"ResetType": resetType})
+		out = append(out, SystemPower{SystemPath: sysPath, PowerState: sys.PowerState, Err: err})
+	}
+	return out, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type rfBootSystem struct {
+	Boot struct {
+		Target        string   `json:"BootSourceOverrideTarget"`
+		Enabled       string   `json:"BootSourceOverrideEnabled"`
+		Mode          string   `json:"BootSourceOverrideMode"`
+		AllowedTarget []string `json:"BootSourceOverrideTarget@Redfish.AllowableValues"`
+		BootOrder     []string `json:"BootOrder"`
+		BootOptions   struct {
+			OID string `json:"@odata.id"`
+		} `json:"BootOptions"`
+	} `json:"Boot"`
+	Settings struct {
+		SettingsObject struct {
+			OID string `json:"@odata.id"`
+		} `json:"SettingsObject"`
+		SupportedApplyTimes []string `json:"SupportedApplyTimes"`
+	} `json:"@Redfish.Settings"`
+}
+
+// BootOverride describes the boot source override of a single system.
+// Err is set when that system could not be queried or updated.
+type BootOverride struct {
+	SystemPath string
+	Target     string
+	Enabled    string
+	Mode       string
+	Err        error
+}
+
+// BootOverridePatch returns the ComputerSystem PATCH body that sets the boot
+// source override to target (e.g. Pxe) with the given enablement (Once or Continuous).
+func BootOverridePatch(target, enabled string) map[string]any {
+	return map[string]any{
+		"Boot": map[string]any{
+			"BootSourceOverrideTarget":  target,
+			"BootSourceOverrideEnabled": enabled,
+		},
+	}
+}
+
+// GetBootOverrides returns the boot source override settings of every system on a BMC.
+func GetBootOverrides(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]BootOverride, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]BootOverride, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		var sys rfBootSystem
+		if err := c.get(ctx, sysPath, &sys); err != nil {
+			out = append(out, BootOverride{SystemPath: sysPath, Err: err})
+			continue
+		}
+		out = append(out, BootOverride{
+			SystemPath: sysPath,
+			Target:     sys.Boot.Target,
+			Enabled:    sys.Boot.Enabled,
+			Mode:       sys.Boot.Mode,
+		})
+	}
+	return out, nil
+}
+
+// SetBootOverride sets the boot source override on every system on a BMC.
+// Each system is fetched first so its ETag can be sent as If-Match. Systems
+// that publish a @Redfish.Settings object are patched through that object with
+// an @Redfish.SettingsApplyTime of Immediate when supported (otherwise the
+// first supported apply time). The returned entries reflect the requested values.
+func SetBootOverride(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, target, enabled string) ([]BootOverride, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]BootOverride, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		res := BootOverride{SystemPath: sysPath, Target: target, Enabled: enabled}
+		var sys rfBootSystem
+		etag, err := c.getWithETag(ctx, sysPath, &sys)
+		if err != nil {
+			res.Err = err
+			out = append(out, res)
+			continue
+		}
+		if allowed := sys.Boot.AllowedTarget; len(allowed) > 0 && !containsFold(allowed, target) {
+			res.Err = fmt.Errorf("boot target %s not supported (allowed: %s)", target, strings.Join(allowed, ", "))
+			out = append(out, res)
+			continue
+		}
+		res.Mode = sys.Boot.Mode
+		res.Err = c.patchBootSettings(ctx, sysPath, sys, etag, BootOverridePatch(target, enabled))
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// patchBootSettings PATCHes body to the system at sysPath, read as sys with
+// ETag etag, or to its @Redfish.Settings object when it publishes one, with
+// an @Redfish.SettingsApplyTime of Immediate when supported (otherwise the
+// first supported apply time).
+func (c *client) patchBootSettings(ctx context.Context, sysPath string, sys rfBootSystem, etag string, body map[string]any) error {
+	settings := sys.Settings.SettingsObject.OID
+	if settings == "" {
+		return c.patchWithETag(ctx, sysPath, body, etag)
+	}
+	if times := sys.Settings.SupportedApplyTimes; len(times) > 0 {
+		applyTime := times[0]
+		if containsFold(times, "Immediate") {
+			applyTime = "Immediate"
+		}
+		body["@Redfish.SettingsApplyTime"] = map[string]any{"ApplyTime": applyTime}
+	}
+	// The settings object carries its own ETag
+	return c.patch(ctx, settings, body)
+}
+
+type rfManager struct {
+	SerialNumber    string         `json:"SerialNumber"`
+	FirmwareVersion string         `json:"FirmwareVersion"`
+	Status          ResourceStatus `json:"Status"`
+	EthernetIfaces  struct {
+		OID string `json:"@odata.id"`
+	} `json:"EthernetInterfaces"`
+	Actions struct {
+		Reset struct {
+			Target          string   `json:"target"`
+			AllowableValues []string `json:"ResetType@Redfish.AllowableValues"`
+		} `json:"#Manager.Reset"`
+	} `json:"Actions"`
+}
+
+// ManagerInfo summarizes the first Manager (BMC) resource of a Redfish service.
+type ManagerInfo struct {
+	Path            string
+	MACAddress      string
+	IPAddress       string
+	SerialNumber    string
+	FirmwareVersion string
+}
+
+// ProbeServiceRoot checks that host answers GET /redfish/v1 with a Redfish service root.
+func ProbeServiceRoot(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) error {
+	c := newClient(host, user, pass, insecure, timeout)
+	_, err := c.getServiceRoot(ctx, host)
+	return err
+}
+
+// GetManagerInfo returns the MAC address and serial number of the first Manager on host.
+// The MAC is taken from the manager interface carrying the host's IPv4 address
+// when one matches, otherwise from the first interface with a valid MAC. The
+// IPv4 address of that interface, if any, is returned alongside.
+func GetManagerInfo(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (ManagerInfo, error) {
+	return newClient(host, user, pass, insecure, timeout).managerInfo(ctx, host)
+}
+
+func (c *client) managerInfo(ctx context.Context, host string) (ManagerInfo, error) {
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil {
+		return ManagerInfo{}, err
+	}
+	if len(managers) == 0 {
+		return ManagerInfo{}, errors.New("no managers reported by BMC")
+	}
+	var mgr rfManager
+	if err := c.get(ctx, managers[0], &mgr); err != nil {
+		return ManagerInfo{}, err
+	}
+	info := ManagerInfo{Path: managers[0], SerialNumber: mgr.SerialNumber, FirmwareVersion: mgr.FirmwareVersion}
+	ifacesPath := mgr.EthernetIfaces.OID
+	if ifacesPath == "" {
+		ifacesPath = info.Path + "/EthernetInterfaces"
+	}
+	ifaces, err := c.listMembers(ctx, ifacesPath)
+	if err != nil {
+		return info, err
+	}
+	hostIP := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostIP = h
+	}
+	for _, m := range ifaces {
+		var nic rfEthernetInterface
+		if err := c.get(ctx, m, &nic); err != nil {
+			return info, err
+		}
+		if !isValidMAC(nic.MACAddress) {
+			continue
+		}
+		for _, a := range nic.IPv4Addresses {
+			if a.Address == hostIP {
+				info.MACAddress = strings.ToLower(nic.MACAddress)
+				info.IPAddress = hostIP
+				return info, nil
+			}
+		}
+		if info.MACAddress == "" {
+			info.MACAddress = strings.ToLower(nic.MACAddress)
+			for _, a := range nic.IPv4Addresses {
+				if net.ParseIP(a.Address).To4() != nil {
+					info.IPAddress = a.Address
+					break
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// ResetManager posts a Manager.Reset action with the given ResetType (e.g.
+// GracefulRestart, ForceRestart) to the first Manager on host. The action
+// target advertised by the manager is used when present, and a reset type
+// outside its allowable values is refused without posting.
+func ResetManager(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, resetType string) error {
+	c := newClient(host, user, pass, insecure, timeout)
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil {
+		return err
+	}
+	if len(managers) == 0 {
+		return errors.New("no managers reported by BMC")
+	}
+	var mgr rfManager
+	if err := c.get(ctx, managers[0], &mgr); err != nil {
+		return err
+	}
+	if allowed := mgr.Actions.Reset.AllowableValues; len(allowed) > 0 && !containsFold(allowed, resetType) {
+		return fmt.Errorf("reset type %s not supported (allowed: %s)", resetType, strings.Join(allowed, ", "))
+	}
+	target := mgr.Actions.Reset.Target
+	if target == "" {
+		target = managers[0] + "/Actions/Manager.Reset"
+	}
+	// The restart drops every connection the BMC had open
+	defer forgetHost(host)
+	return c.post(ctx, target, map[string]any{"ResetType": resetType})
+}
+
+// recoveryPollTimeout bounds each WaitForRecovery and WaitForAddress probe.
+const recoveryPollTimeout = 10 * time.Second
+
+// WaitForRecovery polls host every interval after a reset until it has gone
+// offline and then answers an authenticated request again, and returns the
+// time elapsed since since. Each poll opens a new connection, so a stale
+// keep-alive connection cannot mask the restart. If the BMC is never seen
+// offline it counts as recovered once it answers after settle has passed.
+func WaitForRecovery(ctx context.Context, host, user, pass string, insecure bool, since time.Time, interval, settle time.Duration) (time.Duration, error) {
+	wentDown := false
+	for {
+		select {
+		case <-ctx.Done():
+			return time.Since(since), ctx.Err()
+		case <-time.After(interval):
+		}
+		tr := httptimeout.Apply(&http.Transport{Proxy: proxy, TLSClientConfig: tlsConfig(host, insecure), DisableKeepAlives: true})
+		c := newClientWith(tr, host, user, pass, recoveryPollTimeout)
+		var root rfCollection
+		err := c.get(ctx, "/Managers", &root)
+		switch {
+		case err != nil:
+			wentDown = true
+		case wentDown || time.Since(since) >= settle:
+			return time.Since(since), nil
+		}
+	}
+}
+
 // SetAuthorizedKeys configures the SSH authorized keys on a BMC.
 // The Redfish path used is /Managers/BMC/NetworkProtocol with an OEM payload.
 func SetAuthorizedKeys(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, authorizedKey string) error {
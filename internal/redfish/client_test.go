@@ -6,15 +6,25 @@ package redfish
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestIsBootable_UefiPXE(t *testing.T) {
 	nic := rfEthernetInterface{UefiDevicePath: "VenHw(PXE)"}
-	if !isBootable(nic) {
+	if ok, _ := isBootable(nic); !ok {
 		t.Fatal("expected bootable due to UEFI PXE")
 	}
 }
@@ -24,14 +34,14 @@ func TestIsBootable_DHCPOrigin(t *testing.T) {
 		Address string "json:\"Address\""
 		Origin  string "json:\"AddressOrigin\""
 	}{{Address: "10.0.0.2", Origin: "DHCP"}}}
-	if !isBootable(nic) {
+	if ok, _ := isBootable(nic); !ok {
 		t.Fatal("expected bootable due to DHCP origin")
 	}
 }
 
 func TestIsBootable_MACEnabled(t *testing.T) {
 	nic := rfEthernetInterface{MACAddress: "AA:BB:CC:DD:EE:FF"}
-	if !isBootable(nic) {
+	if ok, _ := isBootable(nic); !ok {
 		t.Fatal("expected bootable with MAC and default enabled")
 	}
 }
@@ -39,13 +49,13 @@ func TestIsBootable_MACEnabled(t *testing.T) {
 func TestIsBootable_MACDisabled(t *testing.T) {
 	enabled := false
 	nic := rfEthernetInterface{MACAddress: "AA:BB:CC:DD:EE:FF", InterfaceEnabled: &enabled}
-	if isBootable(nic) {
+	if ok, _ := isBootable(nic); ok {
 		t.Fatal("expected not bootable when interface disabled")
 	}
 }
 
 func TestIsBootable_False(t *testing.T) {
-	if isBootable(rfEthernetInterface{}) {
+	if ok, _ := isBootable(rfEthernetInterface{}); ok {
 		t.Fatal("expected not bootable for empty NIC")
 	}
 }
@@ -137,7 +147,9 @@ func TestClientURLs(t *testing.T) {
 				_, err := c.listEthernetInterfaces(context.Background(), "/Systems/1")
 				return err
 			},
+			// The service root is read once for $expand support
 			wantPaths: []string{
+				"/redfish/v1",
 				"/redfish/v1/Systems/1/EthernetInterfaces",
 				"/redfish/v1/Systems/1/EthernetInterfaces/1",
 			},
@@ -235,8 +247,11 @@ func TestResolvePath(t *testing.T) {
 
 func TestDiscoverBootableMACs(t *testing.T) {
 	var gotPaths []string
+	var mu sync.Mutex
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
 		gotPaths = append(gotPaths, r.URL.Path)
+		mu.Unlock()
 		w.Header().Set("Content-Type", "application/json")
 		// Return mock Redfish responses
 		switch r.URL.Path {
@@ -310,11 +325,14 @@ func TestDiscoverBootableMACs(t *testing.T) {
 
 	// Verify the correct Redfish paths were requested
 	expectedPaths := []string{
+		"/redfish/v1",
 		"/redfish/v1/Systems",
 		"/redfish/v1/Systems/Self/EthernetInterfaces",
 		"/redfish/v1/Systems/Self/EthernetInterfaces/1",
 		"/redfish/v1/Systems/Self/EthernetInterfaces/2",
 	}
+	// Member GETs run concurrently, so compare the requests in sorted order.
+	sort.Strings(gotPaths)
 	if len(gotPaths) != len(expectedPaths) {
 		t.Errorf("got %d requests, want %d", len(gotPaths), len(expectedPaths))
 	}
@@ -331,8 +349,11 @@ func TestDiscoverBootableMACs(t *testing.T) {
 
 func TestDiscoverAllBootableMACs_MultipleSystems(t *testing.T) {
 	var gotPaths []string
+	var mu sync.Mutex
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
 		gotPaths = append(gotPaths, r.URL.Path)
+		mu.Unlock()
 		w.Header().Set("Content-Type", "application/json")
 		// Simulate BMC with multiple systems (Node0, Node1)
 		switch r.URL.Path {
@@ -431,8 +452,11 @@ func TestDiscoverAllBootableMACs_MultipleSystems(t *testing.T) {
 
 func TestDiscoverBootableMACs_WithInvalidMACs(t *testing.T) {
 	var gotPaths []string
+	var mu sync.Mutex
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
 		gotPaths = append(gotPaths, r.URL.Path)
+		mu.Unlock()
 		w.Header().Set("Content-Type", "application/json")
 		// Simulate HPE Cray system with "Not Available" MACs
 		switch r.URL.Path {
@@ -517,12 +541,15 @@ func TestDiscoverBootableMACs_WithInvalidMACs(t *testing.T) {
 
 	// Verify all interfaces were queried but only valid MACs returned
 	expectedPaths := []string{
+		"/redfish/v1",
 		"/redfish/v1/Systems",
 		"/redfish/v1/Systems/Node0/EthernetInterfaces",
 		"/redfish/v1/Systems/Node0/EthernetInterfaces/HPCNet2",
 		"/redfish/v1/Systems/Node0/EthernetInterfaces/HPCNet3",
 		"/redfish/v1/Systems/Node0/EthernetInterfaces/ManagementEthernet",
 	}
+	// Member GETs run concurrently, so compare the requests in sorted order.
+	sort.Strings(gotPaths)
 	if len(gotPaths) != len(expectedPaths) {
 		t.Errorf("got %d requests, want %d", len(gotPaths), len(expectedPaths))
 	}
@@ -569,7 +596,7 @@ func TestSimpleUpdate_WithStatusConditions(t *testing.T) {
 	ctx := context.Background()
 	host := server.URL[len("https://"):]
 	err := SimpleUpdate(ctx, host, "user", "pass", true, 10*time.Second, "http://example.com/firmware.bin",
-		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "", false)
+		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "", "", false)
 
 	if err == nil {
 		t.Fatal("expected error due to status condition, got nil")
@@ -614,12 +641,12 @@ func TestSimpleUpdate_SkipWhenAlreadyAtVersion(t *testing.T) {
 
 	// Should skip update when already at expected version
 	err := SimpleUpdate(ctx, host, "user", "pass", true, 10*time.Second, "http://example.com/firmware.bin",
-		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "nc.1.9.8", false)
+		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "", "nc.1.9.8", false)
 
 	if err == nil {
 		t.Fatal("expected error indicating skipped update, got nil")
 	}
-	if !contains(err.Error(), "skipping update") {
+	if !errors.Is(err, ErrAlreadyAtVersion) || !contains(err.Error(), "skipping update") {
 		t.Errorf("expected 'skipping update' message, got: %v", err)
 	}
 	if !contains(err.Error(), "nc.1.9.8") {
@@ -656,7 +683,7 @@ func TestSimpleUpdate_ForceWhenAlreadyAtVersion(t *testing.T) {
 
 	// Should force update even when already at expected version
 	err := SimpleUpdate(ctx, host, "user", "pass", true, 10*time.Second, "http://example.com/firmware.bin",
-		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "nc.1.9.8", true)
+		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "", "nc.1.9.8", true)
 
 	if err != nil {
 		t.Fatalf("expected no error with force=true, got: %v", err)
@@ -695,7 +722,7 @@ func TestSimpleUpdate_UpdateWhenDifferentVersion(t *testing.T) {
 
 	// Should proceed with update when version differs
 	err := SimpleUpdate(ctx, host, "user", "pass", true, 10*time.Second, "http://example.com/firmware.bin",
-		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "nc.1.9.8", false)
+		[]string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}, "HTTP", "", "nc.1.9.8", false)
 
 	if err != nil {
 		t.Fatalf("expected no error when updating to different version, got: %v", err)
@@ -704,3 +731,401 @@ func TestSimpleUpdate_UpdateWhenDifferentVersion(t *testing.T) {
 		t.Error("expected SimpleUpdate POST to be called when version differs")
 	}
 }
+
+func TestSetBootOverride_SendsIfMatch(t *testing.T) {
+	var gotIfMatch string
+	var gotBody map[string]any
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`))
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Systems/Node0":
+			w.Header().Set("ETag", `W/"abc123"`)
+			_, _ = w.Write([]byte(`{"Boot":{"BootSourceOverrideTarget":"None","BootSourceOverrideTarget@Redfish.AllowableValues":["None","Pxe","Hdd"]}}`))
+		case r.Method == "PATCH" && r.URL.Path == "/redfish/v1/Systems/Node0":
+			gotIfMatch = r.Header.Get("If-Match")
+			if gotIfMatch != `W/"abc123"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&gotBody)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := server.URL[len("https://"):]
+	res, err := SetBootOverride(context.Background(), host, "user", "pass", true, 5*time.Second, "Pxe", "Once")
+	if err != nil {
+		t.Fatalf("SetBootOverride failed: %v", err)
+	}
+	if len(res) != 1 || res[0].Err != nil {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+	boot, _ := gotBody["Boot"].(map[string]any)
+	if boot["BootSourceOverrideTarget"] != "Pxe" || boot["BootSourceOverrideEnabled"] != "Once" {
+		t.Errorf("unexpected PATCH body: %v", gotBody)
+	}
+}
+
+func TestSetBootOverride_UsesSettingsObject(t *testing.T) {
+	var patchedPath string
+	var gotBody map[string]any
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Self"}]}`))
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Systems/Self":
+			_, _ = w.Write([]byte(`{
+				"Boot":{"BootSourceOverrideTarget":"None"},
+				"@Redfish.Settings":{
+					"SettingsObject":{"@odata.id":"/redfish/v1/Systems/Self/Settings"},
+					"SupportedApplyTimes":["OnReset","Immediate"]
+				}
+			}`))
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Systems/Self/Settings":
+			_, _ = w.Write([]byte(`{"@odata.etag":"\"settings-1\""}`))
+		case r.Method == "PATCH":
+			patchedPath = r.URL.Path
+			if r.Header.Get("If-Match") != `"settings-1"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&gotBody)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := server.URL[len("https://"):]
+	res, err := SetBootOverride(context.Background(), host, "user", "pass", true, 5*time.Second, "Pxe", "Continuous")
+	if err != nil {
+		t.Fatalf("SetBootOverride failed: %v", err)
+	}
+	if len(res) != 1 || res[0].Err != nil {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+	if patchedPath != "/redfish/v1/Systems/Self/Settings" {
+		t.Errorf("PATCH sent to %q, want settings object", patchedPath)
+	}
+	applyTime, _ := gotBody["@Redfish.SettingsApplyTime"].(map[string]any)
+	if applyTime["ApplyTime"] != "Immediate" {
+		t.Errorf("expected Immediate apply time, got body %v", gotBody)
+	}
+}
+
+func TestTLSVerificationUsesCACert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"RedfishVersion":"1.6.0"}`))
+	}))
+	defer srv.Close()
+	t.Cleanup(func() { _ = ConfigureTLS(TLSOptions{}) })
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	// Without the CA, verification must fail with a hint.
+	err := ProbeServiceRoot(context.Background(), host, "u", "p", false, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "--ca-cert") {
+		t.Fatalf("expected verification failure mentioning --ca-cert, got %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureTLS(TLSOptions{CACertFile: caFile}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ProbeServiceRoot(context.Background(), host, "u", "p", false, 5*time.Second); err != nil {
+		t.Fatalf("expected verification to succeed with the CA bundle, got %v", err)
+	}
+
+	if err := ConfigureTLS(TLSOptions{ClientCertFile: caFile}); err == nil {
+		t.Error("expected error when --client-key is missing")
+	}
+}
+
+func TestEthernetInterfacesPagination(t *testing.T) {
+	const total, pageSize = 120, 50
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`))
+		case r.URL.Path == "/redfish/v1/Systems/Node0/EthernetInterfaces":
+			skip := 0
+			fmt.Sscanf(r.URL.Query().Get("$skip"), "%d", &skip) //nolint:errcheck
+			var members []map[string]string
+			for i := skip; i < total && i < skip+pageSize; i++ {
+				members = append(members, map[string]string{"@odata.id": fmt.Sprintf("/redfish/v1/Systems/Node0/EthernetInterfaces/%d", i)})
+			}
+			page := map[string]any{"Members": members, "Members@odata.count": total}
+			if next := skip + pageSize; next < total {
+				// Use the legacy property on the second page to cover both spellings.
+				key := "Members@odata.nextLink"
+				if skip > 0 {
+					key = "@odata.nextLink"
+				}
+				page[key] = fmt.Sprintf("/redfish/v1/Systems/Node0/EthernetInterfaces?$skip=%d", next)
+			}
+			_ = json.NewEncoder(w).Encode(page)
+		case strings.HasPrefix(r.URL.Path, "/redfish/v1/Systems/Node0/EthernetInterfaces/"):
+			var i int
+			fmt.Sscanf(path.Base(r.URL.Path), "%d", &i) //nolint:errcheck
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"Id":             path.Base(r.URL.Path),
+				"MACAddress":     fmt.Sprintf("02:00:00:00:%02x:%02x", i/256, i%256),
+				"UefiDevicePath": "PciRoot(0x0)/MAC()/IPv4()",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := httptest.NewTLSServer(handler)
+	defer srv.Close()
+
+	got, err := DiscoverAllBootableMACs(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 5*time.Second, NICRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].MACs) != total {
+		t.Fatalf("expected %d MACs across three pages, got %d", total, len(got[0].MACs))
+	}
+	if got[0].MACs[total-1] != "02:00:00:00:00:77" {
+		t.Errorf("last MAC = %s, want 02:00:00:00:00:77", got[0].MACs[total-1])
+	}
+}
+
+func TestListMembersStopsOnPagingLoop(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}],"Members@odata.nextLink":"/redfish/v1/Systems"}`))
+	}))
+	defer srv.Close()
+
+	c := newClient(strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 5*time.Second)
+	if _, err := c.listMembers(context.Background(), "/Systems"); err == nil {
+		t.Fatal("expected an error for a collection whose nextLink loops")
+	}
+}
+
+func TestListEthernetInterfacesParallelKeepsOrder(t *testing.T) {
+	const n = 10
+	var inFlight, peak int32
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/redfish/v1/Systems/Node0/EthernetInterfaces" {
+			var members []map[string]string
+			for i := 0; i < n; i++ {
+				members = append(members, map[string]string{"@odata.id": fmt.Sprintf("/redfish/v1/Systems/Node0/EthernetInterfaces/%d", i)})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"Members": members})
+			return
+		}
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		var i int
+		fmt.Sscanf(path.Base(r.URL.Path), "%d", &i) //nolint:errcheck
+		// Earlier members answer more slowly, so completion order is reversed.
+		time.Sleep(time.Duration(n-i) * 5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		if i == 7 && r.URL.Query().Get("fail") != "" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"Id": path.Base(r.URL.Path)})
+	}))
+	defer srv.Close()
+
+	c := newClient("example.com", "u", "p", true, 5*time.Second)
+	c.base = srv.URL + "/redfish/v1"
+	nics, err := c.listEthernetInterfaces(context.Background(), "/redfish/v1/Systems/Node0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, nic := range nics {
+		if nic.ID != fmt.Sprint(i) {
+			t.Fatalf("nics[%d].Id = %q; collection order not preserved", i, nic.ID)
+		}
+	}
+	if peak < 2 || peak > memberFetchWorkers {
+		t.Errorf("peak concurrency = %d, want between 2 and %d", peak, memberFetchWorkers)
+	}
+
+	members := []string{"/redfish/v1/Systems/Node0/EthernetInterfaces/6", "/redfish/v1/Systems/Node0/EthernetInterfaces/7?fail=1"}
+	if _, err := fetchAll[rfEthernetInterface](context.Background(), c, members); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected the member failure to be returned, got %v", err)
+	}
+}
+
+func TestResetManager(t *testing.T) {
+	var mu sync.Mutex
+	var posted []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Actions":{"#Manager.Reset":{"target":"/redfish/v1/Managers/BMC/Actions/Manager.Reset","ResetType@Redfish.AllowableValues":["GracefulRestart","ForceRestart"]}}}`)
+		case r.Method == "POST":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			posted = append(posted, r.URL.Path+" "+body["ResetType"])
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	if err := ResetManager(context.Background(), host, "u", "p", true, 5*time.Second, "GracefulRestart"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ResetManager(context.Background(), host, "u", "p", true, 5*time.Second, "PowerCycle"); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("expected unsupported reset type error, got %v", err)
+	}
+	if len(posted) != 1 || posted[0] != "/redfish/v1/Managers/BMC/Actions/Manager.Reset GracefulRestart" {
+		t.Errorf("posted = %v", posted)
+	}
+}
+
+func TestWaitForRecovery(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		n := polls
+		mu.Unlock()
+		// Still up for the first poll, down for the next two, then back
+		if n == 2 || n == 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"Members":[]}`)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	took, err := WaitForRecovery(context.Background(), host, "u", "p", true, time.Now(), 10*time.Millisecond, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if polls != 4 || took <= 0 {
+		t.Errorf("recovered after %d polls (%s), want 4", polls, took)
+	}
+	polls = 100 // never goes down again
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForRecovery(ctx, host, "u", "p", true, time.Now(), 10*time.Millisecond, time.Hour); err == nil {
+		t.Error("expected timeout when the BMC never went offline before settle")
+	}
+}
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"RedfishVersion":"1.6.0"}`)) //nolint:errcheck
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	ConfigureRateLimit(20, 0)
+	defer ConfigureRateLimit(0, 0)
+	start := time.Now()
+	for range 3 {
+		if err := ProbeServiceRoot(context.Background(), host, "u", "p", true, 5*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s took %v, want >= 100ms", d)
+	}
+
+	// A waiter blocked behind the limit returns its context's error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ProbeServiceRoot(ctx, host, "u", "p", true, 5*time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("ProbeServiceRoot with cancelled context = %v", err)
+	}
+}
+
+// strictETagBMC serves one resource that, like iLO and OpenBMC, rejects a
+// PATCH whose If-Match is not its current ETag. Another writer changes the
+// resource after each of the first `races` GETs.
+func strictETagBMC(t *testing.T, races int) (string, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	version, gets := 1, 0
+	var patches []string // If-Match and outcome of each PATCH
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/redfish/v1/Managers/BMC/NetworkProtocol" {
+			http.NotFound(w, r)
+			return
+		}
+		etag := fmt.Sprintf(`"v%d"`, version)
+		switch r.Method {
+		case "GET":
+			w.Header().Set("ETag", etag)
+			fmt.Fprint(w, `{}`)
+			if gets++; gets <= races {
+				version++
+			}
+		case "PATCH":
+			if r.Header.Get("If-Match") != etag {
+				patches = append(patches, r.Header.Get("If-Match")+" 412")
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			patches = append(patches, etag+" 204")
+			version++
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &patches
+}
+
+func TestPatchSendsIfMatchAndRetriesOnce(t *testing.T) {
+	cases := []struct {
+		name    string
+		races   int
+		want    []string
+		wantErr bool
+	}{
+		{"current ETag", 0, []string{`"v1" 204`}, false},
+		{"refetched after a 412", 1, []string{`"v1" 412`, `"v2" 204`}, false},
+		{"gives up after one retry", 2, []string{`"v1" 412`, `"v2" 412`}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, patches := strictETagBMC(t, tc.races)
+			err := SetAuthorizedKeys(context.Background(), host, "u", "p", true, 5*time.Second, "ssh-ed25519 AAAA")
+			if (err != nil) != tc.wantErr || tc.wantErr && !isStatus(err, http.StatusPreconditionFailed) {
+				t.Fatalf("err = %v, want error %v", err, tc.wantErr)
+			}
+			if strings.Join(*patches, ", ") != strings.Join(tc.want, ", ") {
+				t.Errorf("PATCHes = %v, want %v", *patches, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNoConsole is returned when none of the console paths exists on a BMC.
+var ErrNoConsole = errors.New("no console log resource")
+
+// ConsolePath is a console or boot log resource to try. Path is a Redfish
+// path in which {system} and {manager} stand for every System and Manager
+// of the BMC. A Vendor limits the path to BMCs whose service root reports
+// that Vendor.
+type ConsolePath struct {
+	Vendor string
+	Path   string
+}
+
+// DefaultConsolePaths are tried, in order, when no paths are configured.
+var DefaultConsolePaths = []ConsolePath{
+	{Path: "{system}/LogServices/Console"},
+	{Path: "{system}/LogServices/SOL"},
+	// OpenBMC's host logger keeps the host console output
+	{Vendor: "OpenBMC", Path: "{system}/LogServices/HostLogger"},
+	{Path: "{manager}/LogServices/Console"},
+}
+
+// ParseConsolePath parses a --path value: a path, or vendor=path to try it
+// only on BMCs of that vendor.
+func ParseConsolePath(s string) (ConsolePath, error) {
+	vendor, path, ok := strings.Cut(s, "=")
+	if !ok {
+		vendor, path = "", s
+	}
+	vendor, path = strings.TrimSpace(vendor), strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "{system}") && !strings.HasPrefix(path, "{manager}") {
+		return ConsolePath{}, fmt.Errorf("console path %q must start with /, {system}, or {manager}", s)
+	}
+	return ConsolePath{Vendor: vendor, Path: path}, nil
+}
+
+func (p ConsolePath) String() string {
+	if p.Vendor == "" {
+		return p.Path
+	}
+	return p.Vendor + "=" + p.Path
+}
+
+// Console is the output read from one console resource.
+type Console struct {
+	Path string
+	Data []byte
+}
+
+// CaptureConsole reads the console output of host from the first of paths
+// that exists on it, for every System or Manager the path expands to. A
+// LogService or LogEntry collection gives the Message of each entry, one per
+// line; any other resource, such as an Oem one-shot endpoint, is kept as
+// served. ErrNoConsole means none of the paths exists.
+func CaptureConsole(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, paths []ConsolePath) ([]Console, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	root, err := c.getServiceRoot(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	members := map[string][]string{}
+	expand := func(placeholder, collection, path string) ([]string, error) {
+		if !strings.Contains(path, placeholder) {
+			return []string{path}, nil
+		}
+		list, ok := members[collection]
+		if !ok {
+			var err error
+			list, err = c.listMembers(ctx, collection)
+			if err != nil && !isStatus(err, http.StatusNotFound) {
+				return nil, err
+			}
+			members[collection] = list
+		}
+		out := make([]string, 0, len(list))
+		for _, m := range list {
+			out = append(out, strings.ReplaceAll(path, placeholder, m))
+		}
+		return out, nil
+	}
+	for _, p := range paths {
+		if p.Vendor != "" && !strings.EqualFold(p.Vendor, root.Vendor) {
+			continue
+		}
+		expanded, err := expand("{system}", "/Systems", p.Path)
+		if err != nil {
+			return nil, err
+		}
+		var concrete []string
+		for _, e := range expanded {
+			more, err := expand("{manager}", "/Managers", e)
+			if err != nil {
+				return nil, err
+			}
+			concrete = append(concrete, more...)
+		}
+		var out []Console
+		for _, path := range concrete {
+			data, err := c.readConsole(ctx, path)
+			if isStatus(err, http.StatusNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			out = append(out, Console{Path: path, Data: data})
+		}
+		if len(out) > 0 {
+			return out, nil
+		}
+	}
+	return nil, ErrNoConsole
+}
+
+// readConsole reads the console resource at path.
+func (c *client) readConsole(ctx context.Context, path string) ([]byte, error) {
+	raw, contentType, err := c.getRaw(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(contentType, "json") {
+		return raw, nil
+	}
+	var res struct {
+		Members *json.RawMessage `json:"Members"`
+		Entries struct {
+			OID string `json:"@odata.id"`
+		} `json:"Entries"`
+	}
+	if json.Unmarshal(raw, &res) != nil {
+		return raw, nil
+	}
+	entries := ""
+	switch {
+	case res.Members != nil:
+		entries = path
+	case res.Entries.OID != "":
+		entries = res.Entries.OID
+	default:
+		return raw, nil
+	}
+	list, err := c.listLogEntries(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, e := range list {
+		buf.WriteString(e.Message)
+		if !strings.HasSuffix(e.Message, "\n") {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// getRaw is like get but returns the body as served, with its Content-Type.
+func (c *client) getRaw(ctx context.Context, path string) ([]byte, string, error) {
+	path = c.resolvePath(path)
+	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.SetBasicAuth(c.user, c.pass)
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode >= 300 {
+		return nil, "", newStatusError(resp, path)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, resp.Header.Get("Content-Type"), nil
+}
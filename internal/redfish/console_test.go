@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func consoleServer(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"Vendor":"Contoso"}`)
+		case "/redfish/v1/Systems":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"},{"@odata.id":"/redfish/v1/Systems/Node1"}]}`)
+		case "/redfish/v1/Systems/Node0/LogServices/Console":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			fmt.Fprint(w, `{"Id":"Console","Entries":{"@odata.id":"/redfish/v1/Systems/Node0/LogServices/Console/Entries"}}`)
+		case "/redfish/v1/Systems/Node0/LogServices/Console/Entries":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"Members":[{"Id":"1","Message":"PXE-E18: Server response timeout."},{"Id":"2","Message":"Booting from disk\n"}]}`)
+		case "/redfish/v1/Systems/Node1/Oem/Contoso/ConsoleDump":
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, "raw console\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestCaptureConsole(t *testing.T) {
+	host := consoleServer(t)
+	capture := func(paths ...string) ([]Console, error) {
+		t.Helper()
+		var parsed []ConsolePath
+		for _, p := range paths {
+			cp, err := ParseConsolePath(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed = append(parsed, cp)
+		}
+		return CaptureConsole(context.Background(), host, "u", "p", true, 5*time.Second, parsed)
+	}
+
+	// Node1 has no Console service; the Oem path of another vendor is not tried
+	got, err := capture("OtherVendor={system}/Oem/Contoso/ConsoleDump", "{system}/LogServices/Console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Path != "/redfish/v1/Systems/Node0/LogServices/Console" ||
+		string(got[0].Data) != "PXE-E18: Server response timeout.\nBooting from disk\n" {
+		t.Fatalf("LogService console = %+v", got)
+	}
+
+	got, err = capture("contoso={system}/Oem/Contoso/ConsoleDump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || string(got[0].Data) != "raw console\n" {
+		t.Errorf("Oem console = %+v", got)
+	}
+
+	if _, err := capture("{manager}/LogServices/Console", "/redfish/v1/Systems/Node1/LogServices/SOL"); !errors.Is(err, ErrNoConsole) {
+		t.Errorf("no console resource: err = %v, want ErrNoConsole", err)
+	}
+}
+
+func TestParseConsolePath(t *testing.T) {
+	if p, err := ParseConsolePath(" OpenBMC = {system}/LogServices/HostLogger"); err != nil || p.Vendor != "OpenBMC" || p.Path != "{system}/LogServices/HostLogger" {
+		t.Errorf("vendor path = %+v, %v", p, err)
+	}
+	if _, err := ParseConsolePath("LogServices/Console"); err == nil {
+		t.Error("relative path: expected an error")
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"bootstrap/internal/diag"
+)
+
+// expandSupport caches, per service root URL, whether the service
+// advertises $expand=. (ExpandQuery with NoLinks), so each BMC is asked once
+// per run.
+var (
+	expandMu      sync.Mutex
+	expandSupport = map[string]bool{}
+)
+
+// supportsExpand reports whether the service advertises $expand=. in the
+// ProtocolFeaturesSupported of its service root. A service root that cannot
+// be read counts as no support.
+func (c *client) supportsExpand(ctx context.Context) bool {
+	expandMu.Lock()
+	ok, known := expandSupport[c.base]
+	expandMu.Unlock()
+	if known {
+		return ok
+	}
+	var root struct {
+		ProtocolFeaturesSupported struct {
+			ExpandQuery *struct {
+				NoLinks bool `json:"NoLinks"`
+			} `json:"ExpandQuery"`
+		} `json:"ProtocolFeaturesSupported"`
+	}
+	if err := c.get(ctx, c.base, &root); err != nil {
+		if ctx.Err() != nil {
+			// Not an answer from the service; ask again next time
+			return false
+		}
+		diag.Logf("%s: service root: %v; not using $expand", c.base, err)
+	}
+	ok = root.ProtocolFeaturesSupported.ExpandQuery != nil && root.ProtocolFeaturesSupported.ExpandQuery.NoLinks
+	expandMu.Lock()
+	expandSupport[c.base] = ok
+	expandMu.Unlock()
+	return ok
+}
+
+// expandedMembers GETs collection path with $expand=. and decodes every
+// member into a T. ok is false when the expanded form cannot be used: the
+// request failed, the collection is paged, or a member was not expanded
+// (complete reports whether a decoded member has its expected fields).
+func expandedMembers[T any](ctx context.Context, c *client, path string, complete func(T) bool) ([]T, bool) {
+	var coll struct {
+		Members        []json.RawMessage `json:"Members"`
+		NextLink       string            `json:"Members@odata.nextLink"`
+		LegacyNextLink string            `json:"@odata.nextLink"`
+	}
+	if err := c.get(ctx, path+"?$expand=.", &coll); err != nil {
+		diag.Logf("%s: $expand: %v; reading members one by one", path, err)
+		return nil, false
+	}
+	if coll.NextLink != "" || coll.LegacyNextLink != "" {
+		return nil, false
+	}
+	out := make([]T, len(coll.Members))
+	for i, m := range coll.Members {
+		if err := json.Unmarshal(m, &out[i]); err != nil || !complete(out[i]) {
+			diag.Logf("%s: $expand returned an incomplete member; reading members one by one", path)
+			return nil, false
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// expandServer serves one system with two NICs. The service root advertises
+// $expand when advertise is set, and expanded answers the expanded
+// collection with the given members (or 400 when empty).
+func expandServer(t *testing.T, advertise bool, expanded string, requests *atomic.Int32) *client {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.URL.Path == "/redfish/v1":
+			if advertise {
+				fmt.Fprint(w, `{"ProtocolFeaturesSupported":{"ExpandQuery":{"ExpandAll":false,"Levels":true,"Links":false,"NoLinks":true,"MaxLevels":1}}}`)
+			} else {
+				fmt.Fprint(w, `{"RedfishVersion":"1.6.0"}`)
+			}
+		case r.URL.Path == "/redfish/v1/Systems/1/EthernetInterfaces" && r.URL.Query().Get("$expand") == ".":
+			if expanded == "" {
+				http.Error(w, "not supported", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"Members":[%s]}`, expanded)
+		case r.URL.Path == "/redfish/v1/Systems/1/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/1/EthernetInterfaces/1"},{"@odata.id":"/redfish/v1/Systems/1/EthernetInterfaces/2"}]}`)
+		case r.URL.Path == "/redfish/v1/Systems/1/EthernetInterfaces/1":
+			fmt.Fprint(w, `{"Id":"1","MACAddress":"aa:bb:cc:dd:ee:01"}`)
+		case r.URL.Path == "/redfish/v1/Systems/1/EthernetInterfaces/2":
+			fmt.Fprint(w, `{"Id":"2","MACAddress":"aa:bb:cc:dd:ee:02"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	c := newClient("example.com", "admin", "password", true, 0)
+	c.base = ts.URL + "/redfish/v1"
+	return c
+}
+
+func TestListEthernetInterfacesExpand(t *testing.T) {
+	full := `{"@odata.id":"/redfish/v1/Systems/1/EthernetInterfaces/1","Id":"1","MACAddress":"aa:bb:cc:dd:ee:01"},` +
+		`{"@odata.id":"/redfish/v1/Systems/1/EthernetInterfaces/2","Id":"2","MACAddress":"aa:bb:cc:dd:ee:02"}`
+	for _, tc := range []struct {
+		name      string
+		advertise bool
+		expanded  string
+		want      int32 // requests for two listings, the service root included
+	}{
+		// Root once, then one expanded GET per listing
+		{"expanded", true, full, 3},
+		// Root once, then the collection and two members per listing
+		{"not advertised", false, full, 7},
+		// Each listing tries $expand, then falls back
+		{"expand rejected", true, "", 9},
+		{"members not expanded", true, `{"@odata.id":"/redfish/v1/Systems/1/EthernetInterfaces/1"},{"@odata.id":"/redfish/v1/Systems/1/EthernetInterfaces/2"}`, 9},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int32
+			c := expandServer(t, tc.advertise, tc.expanded, &requests)
+			for range 2 {
+				nics, err := c.listEthernetInterfaces(context.Background(), "/Systems/1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(nics) != 2 || nics[0].MACAddress != "aa:bb:cc:dd:ee:01" || nics[1].ID != "2" {
+					t.Fatalf("nics = %+v", nics)
+				}
+			}
+			if got := requests.Load(); got != tc.want {
+				t.Errorf("%d requests, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Facts identifies the Redfish service of a BMC: RedfishVersion, Vendor,
+// and Product from the service root, and FirmwareVersion and Model from its
+// first Manager. Fields the BMC does not report are empty.
+type Facts struct {
+	RedfishVersion  string `json:"redfish_version"`
+	Vendor          string `json:"vendor"`
+	Product         string `json:"product"`
+	FirmwareVersion string `json:"firmware_version"`
+	Model           string `json:"model"`
+}
+
+// rfServiceRoot is the part of /redfish/v1 this package reads.
+type rfServiceRoot struct {
+	RedfishVersion string `json:"RedfishVersion"`
+	Vendor         string `json:"Vendor"`
+	Product        string `json:"Product"`
+}
+
+// vendors caches the Vendor of each host's service root for the rest of the
+// process, so later calls can consult it without reading the root again.
+var vendors sync.Map
+
+// Vendor returns the Vendor the service root of host reported to an earlier
+// call in this process, and whether the root has been read.
+func Vendor(host string) (string, bool) {
+	v, ok := vendors.Load(host)
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// getServiceRoot reads the service root of host and remembers its Vendor.
+func (c *client) getServiceRoot(ctx context.Context, host string) (rfServiceRoot, error) {
+	var root rfServiceRoot
+	if err := c.get(ctx, c.base, &root); err != nil {
+		return root, err
+	}
+	vendors.Store(host, root.Vendor)
+	return root, nil
+}
+
+// GetFacts reads the service root and the first Manager of host. Only a
+// failure to read the service root, or the Manager of a BMC that lists one,
+// is an error; a BMC without Managers leaves their fields empty.
+func GetFacts(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (Facts, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	root, err := c.getServiceRoot(ctx, host)
+	if err != nil {
+		return Facts{}, err
+	}
+	f := Facts{RedfishVersion: root.RedfishVersion, Vendor: root.Vendor, Product: root.Product}
+	managers, err := c.listMembers(ctx, "/Managers")
+	if isStatus(err, http.StatusNotFound) || err == nil && len(managers) == 0 {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	var mgr struct {
+		FirmwareVersion string `json:"FirmwareVersion"`
+		Model           string `json:"Model"`
+	}
+	if err := c.get(ctx, managers[0], &mgr); err != nil {
+		return f, err
+	}
+	f.FirmwareVersion, f.Model = mgr.FirmwareVersion, mgr.Model
+	return f, nil
+}
+
+// isHPE reports whether vendor names HPE (or HP), whose iLO marks PXE
+// interfaces in Oem data.
+func isHPE(vendor string) bool {
+	return strings.HasPrefix(strings.ToLower(vendor), "hp")
+}
+
+// Models is the hardware model of a BMC: the Model of its first Manager and
+// of each of its systems, in collection order. Fields the BMC does not
+// report are empty.
+type Models struct {
+	Manager string
+	Systems []string
+}
+
+// GetModels reads the Model of the first Manager and of every system of
+// host. A BMC without Managers or Systems, such as a chassis controller,
+// leaves those fields empty.
+func GetModels(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (Models, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	var m Models
+	type modeled struct {
+		Model string `json:"Model"`
+	}
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return m, err
+	}
+	if len(managers) > 0 {
+		var mgr modeled
+		if err := c.get(ctx, managers[0], &mgr); err != nil {
+			return m, err
+		}
+		m.Manager = mgr.Model
+	}
+	systems, err := c.listMembers(ctx, "/Systems")
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return m, err
+	}
+	sys, err := fetchAll[modeled](ctx, c, systems)
+	if err != nil {
+		return m, err
+	}
+	for _, s := range sys {
+		m.Systems = append(m.Systems, s.Model)
+	}
+	return m, nil
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetFacts(t *testing.T) {
+	managers := true
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1":
+			fmt.Fprint(w, `{"RedfishVersion":"1.11.0","Vendor":"HPE","Product":"ProLiant DL385 Gen10 Plus"}`)
+		case r.URL.Path == "/redfish/v1/Managers" && managers:
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/1"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers/1":
+			fmt.Fprint(w, `{"FirmwareVersion":"iLO 5 v2.72","Model":"iLO 5"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	if _, known := Vendor(host); known {
+		t.Fatal("vendor known before the service root was read")
+	}
+	f, err := GetFacts(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Facts{RedfishVersion: "1.11.0", Vendor: "HPE", Product: "ProLiant DL385 Gen10 Plus", FirmwareVersion: "iLO 5 v2.72", Model: "iLO 5"}
+	if f != want {
+		t.Errorf("facts = %+v, want %+v", f, want)
+	}
+	if v, known := Vendor(host); v != "HPE" || !known {
+		t.Errorf("Vendor = %q, %v; want HPE, true", v, known)
+	}
+
+	// Without Managers their fields are left empty
+	managers = false
+	f, err = GetFacts(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil || f.Vendor != "HPE" || f.FirmwareVersion != "" || f.Model != "" {
+		t.Errorf("no managers: facts = %+v, err = %v", f, err)
+	}
+}
+
+func TestGetModels(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Model":"WNC"}`)
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"},{"@odata.id":"/redfish/v1/Systems/Node1"}]}`)
+		case "/redfish/v1/Systems/Node0", "/redfish/v1/Systems/Node1":
+			fmt.Fprint(w, `{"Model":"EX425"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	m, err := GetModels(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Manager != "WNC" || len(m.Systems) != 2 || m.Systems[1] != "EX425" {
+		t.Errorf("models = %+v, want WNC with two EX425 systems", m)
+	}
+
+	// A chassis controller has no Systems
+	cc := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Model":"WCC"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer cc.Close()
+	m, err = GetModels(context.Background(), strings.TrimPrefix(cc.URL, "https://"), "u", "p", true, 5*time.Second)
+	if err != nil || m.Manager != "WCC" || len(m.Systems) != 0 {
+		t.Errorf("chassis controller: models = %+v, err = %v", m, err)
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"time"
+)
+
+// SystemHardware summarizes the processors, memory, and NICs of a single
+// ComputerSystem. Cores is zero when the BMC does not report
+// ProcessorSummary.CoreCount. Err is set when the system could not be read.
+type SystemHardware struct {
+	SystemPath     string
+	Model          string
+	Processors     int
+	ProcessorModel string
+	Cores          int
+	MemoryGiB      float64
+	NICs           int
+	Err            error
+}
+
+type rfHardwareSystem struct {
+	Model            string `json:"Model"`
+	ProcessorSummary struct {
+		Count     int    `json:"Count"`
+		CoreCount int    `json:"CoreCount"`
+		Model     string `json:"Model"`
+	} `json:"ProcessorSummary"`
+	MemorySummary struct {
+		TotalSystemMemoryGiB float64 `json:"TotalSystemMemoryGiB"`
+	} `json:"MemorySummary"`
+}
+
+// GetHardware returns the ProcessorSummary, MemorySummary, and number of
+// EthernetInterfaces of every system on a BMC, in collection order.
+func GetHardware(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]SystemHardware, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	sysPaths, err := c.listSystemPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SystemHardware, 0, len(sysPaths))
+	for _, sysPath := range sysPaths {
+		hw := SystemHardware{SystemPath: sysPath}
+		var sys rfHardwareSystem
+		if hw.Err = c.get(ctx, sysPath, &sys); hw.Err == nil {
+			hw.Model = sys.Model
+			hw.Processors = sys.ProcessorSummary.Count
+			hw.ProcessorModel = sys.ProcessorSummary.Model
+			hw.Cores = sys.ProcessorSummary.CoreCount
+			hw.MemoryGiB = sys.MemorySummary.TotalSystemMemoryGiB
+			var nics []string
+			nics, hw.Err = c.listMembers(ctx, sysPath+"/EthernetInterfaces")
+			hw.NICs = len(nics)
+		}
+		out = append(out, hw)
+	}
+	return out, nil
+}
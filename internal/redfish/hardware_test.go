@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetHardware(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Systems":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"},{"@odata.id":"/redfish/v1/Systems/Node1"}]}`))
+		case "/redfish/v1/Systems/Node0":
+			_, _ = w.Write([]byte(`{"Model":"EX425","ProcessorSummary":{"Count":2,"CoreCount":128,"Model":"AMD EPYC 7763"},"MemorySummary":{"TotalSystemMemoryGiB":512}}`))
+		case "/redfish/v1/Systems/Node0/EthernetInterfaces":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0/EthernetInterfaces/1"},{"@odata.id":"/redfish/v1/Systems/Node0/EthernetInterfaces/2"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	systems, err := GetHardware(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(systems) != 2 {
+		t.Fatalf("got %d systems, want 2", len(systems))
+	}
+	got := systems[0]
+	if got.Err != nil || got.Processors != 2 || got.Cores != 128 || got.MemoryGiB != 512 || got.NICs != 2 || got.ProcessorModel != "AMD EPYC 7763" {
+		t.Errorf("Node0 = %+v", got)
+	}
+	if systems[1].Err == nil {
+		t.Error("expected an error for the missing Node1")
+	}
+}
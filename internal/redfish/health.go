@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ResourceStatus is the Status of a Redfish resource. Either field may be
+// empty when the service does not report it.
+type ResourceStatus struct {
+	State  string `json:"State"`
+	Health string `json:"Health"`
+}
+
+// Absent reports whether the resource is reported as not present, such as
+// the system of an empty slot in a half-populated blade.
+func (s ResourceStatus) Absent() bool { return strings.EqualFold(s.State, "Absent") }
+
+// Critical reports whether the resource's health is Critical.
+func (s ResourceStatus) Critical() bool { return strings.EqualFold(s.Health, "Critical") }
+
+// String returns s as "State/Health", with "?" for a field not reported.
+func (s ResourceStatus) String() string {
+	state, health := s.State, s.Health
+	if state == "" {
+		state = "?"
+	}
+	if health == "" {
+		health = "?"
+	}
+	return state + "/" + health
+}
+
+// GetManagerStatus returns the Status of the first Manager on host.
+func GetManagerStatus(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (ResourceStatus, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil {
+		return ResourceStatus{}, err
+	}
+	if len(managers) == 0 {
+		return ResourceStatus{}, errors.New("no managers reported by BMC")
+	}
+	var mgr rfManager
+	if err := c.get(ctx, managers[0], &mgr); err != nil {
+		return ResourceStatus{}, err
+	}
+	return mgr.Status, nil
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetManagerStatus(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"Id":"BMC","Status":{"State":"Enabled","Health":"Critical"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	st, err := GetManagerStatus(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Critical() || st.Absent() || st.String() != "Enabled/Critical" {
+		t.Errorf("status = %+v (%s), want Enabled/Critical", st, st)
+	}
+}
+
+func TestResourceStatus(t *testing.T) {
+	cases := []struct {
+		st               ResourceStatus
+		absent, critical bool
+		str              string
+	}{
+		{ResourceStatus{State: "Enabled", Health: "OK"}, false, false, "Enabled/OK"},
+		{ResourceStatus{State: "absent"}, true, false, "absent/?"},
+		{ResourceStatus{Health: "CRITICAL"}, false, true, "?/CRITICAL"},
+		{ResourceStatus{}, false, false, "?/?"},
+	}
+	for _, c := range cases {
+		if c.st.Absent() != c.absent || c.st.Critical() != c.critical || c.st.String() != c.str {
+			t.Errorf("%+v: Absent %v, Critical %v, String %q; want %v, %v, %q", c.st, c.st.Absent(), c.st.Critical(), c.st.String(), c.absent, c.critical, c.str)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"bootstrap/internal/diag"
+)
+
+// IdentityError reports a BMC whose TLS certificate is not the one pinned
+// for its address, e.g. because re-cabling put a different BMC there.
+type IdentityError struct {
+	Host      string
+	Xname     string
+	Pinned    string
+	Presented string
+}
+
+func (e *IdentityError) Error() string {
+	return fmt.Sprintf("BMC identity changed for %s: pinned TLS fingerprint %s, presented %s (check the cabling; pass --accept-new-identity if the BMC or its certificate was replaced)",
+		identityLabel(e.Host, e.Xname), e.Pinned, e.Presented)
+}
+
+// identities holds the pinned and observed TLS fingerprints by host.
+var identities struct {
+	mu       sync.Mutex
+	accept   bool
+	pinned   map[string]pin
+	observed map[string]string
+	warned   map[string]bool
+}
+
+type pin struct {
+	xname       string
+	fingerprint string
+}
+
+// ConfigureIdentity clears the pinned identities; with accept, a BMC whose
+// certificate no longer matches its pin is warned about instead of refused.
+func ConfigureIdentity(accept bool) {
+	identities.mu.Lock()
+	defer identities.mu.Unlock()
+	identities.accept = accept
+	identities.pinned = map[string]pin{}
+	identities.observed = map[string]string{}
+	identities.warned = map[string]bool{}
+}
+
+// PinIdentity makes every later connection to host check that the BMC
+// presents the certificate with the given fingerprint (see Fingerprint);
+// xname names it in the mismatch report. An empty fingerprint pins nothing.
+func PinIdentity(host, xname, fingerprint string) {
+	if fingerprint == "" {
+		return
+	}
+	identities.mu.Lock()
+	defer identities.mu.Unlock()
+	if identities.pinned == nil {
+		identities.pinned = map[string]pin{}
+	}
+	identities.pinned[host] = pin{xname: xname, fingerprint: fingerprint}
+}
+
+// ObservedIdentity returns the fingerprint of the certificate host presented
+// on the last connection this run, or "" if none was made.
+func ObservedIdentity(host string) string {
+	identities.mu.Lock()
+	defer identities.mu.Unlock()
+	return identities.observed[host]
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a DER certificate as
+// colon-separated upper-case hex, the form `openssl x509 -fingerprint
+// -sha256` prints.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// SameFingerprint compares fingerprints ignoring case and separators, so a
+// pin pasted in another notation still matches.
+func SameFingerprint(a, b string) bool {
+	strip := func(s string) string { return strings.ReplaceAll(strings.ReplaceAll(s, ":", ""), " ", "") }
+	return strings.EqualFold(strip(a), strip(b))
+}
+
+// verifyIdentity returns the tls.Config.VerifyConnection hook for host: it
+// records the presented certificate and checks it against host's pin.
+func verifyIdentity(host string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("BMC presented no TLS certificate")
+		}
+		presented := Fingerprint(cs.PeerCertificates[0].Raw)
+		identities.mu.Lock()
+		defer identities.mu.Unlock()
+		if identities.observed == nil {
+			identities.observed = map[string]string{}
+		}
+		identities.observed[host] = presented
+		p, ok := identities.pinned[host]
+		if !ok || SameFingerprint(p.fingerprint, presented) {
+			return nil
+		}
+		if !identities.accept {
+			return &IdentityError{Host: host, Xname: p.xname, Pinned: p.fingerprint, Presented: presented}
+		}
+		if !identities.warned[host] {
+			if identities.warned == nil {
+				identities.warned = map[string]bool{}
+			}
+			identities.warned[host] = true
+			diag.Warnf("BMC identity changed for %s: pinned TLS fingerprint %s, presented %s; accepted (--accept-new-identity)",
+				identityLabel(host, p.xname), p.fingerprint, presented)
+		}
+		return nil
+	}
+}
+
+func identityLabel(host, xname string) string {
+	if xname == "" {
+		return host
+	}
+	return fmt.Sprintf("%s (%s)", xname, host)
+}
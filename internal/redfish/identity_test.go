@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdentityPinning(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	want := Fingerprint(srv.Certificate().Raw)
+	t.Cleanup(func() { ConfigureIdentity(false) })
+	count := func(accept bool, pinned string) error {
+		t.Helper()
+		ConfigureIdentity(accept)
+		CloseIdleConnections()
+		PinIdentity(host, "x9000c1s0b0", pinned)
+		_, err := CountSystems(context.Background(), host, "u", "p", true, 5*time.Second)
+		return err
+	}
+
+	if err := count(false, ""); err != nil || ObservedIdentity(host) != want {
+		t.Fatalf("unpinned: err %v, observed %q, want %q", err, ObservedIdentity(host), want)
+	}
+	// A pin in another notation still matches
+	if err := count(false, strings.ToLower(strings.ReplaceAll(want, ":", ""))); err != nil {
+		t.Fatalf("matching pin: %v", err)
+	}
+
+	err := count(false, "00:11:22")
+	var ierr *IdentityError
+	if !errors.As(err, &ierr) || ierr.Xname != "x9000c1s0b0" || ierr.Pinned != "00:11:22" || ierr.Presented != want {
+		t.Fatalf("changed identity: err = %v", err)
+	}
+	if c := Categorize(err); c != CategoryIdentity {
+		t.Errorf("Categorize = %q, want %q", c, CategoryIdentity)
+	}
+
+	if err := count(true, "00:11:22"); err != nil {
+		t.Errorf("accepted identity: %v", err)
+	}
+}
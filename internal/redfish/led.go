@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrNoIndicator is returned when neither a Chassis nor a ComputerSystem of
+// the BMC has an IndicatorLED or LocationIndicatorActive property.
+var ErrNoIndicator = errors.New("no Chassis or System with a locator LED")
+
+// Locator LED states, as in the Redfish IndicatorLED enumeration.
+const (
+	IndicatorLit      = "Lit"
+	IndicatorBlinking = "Blinking"
+	IndicatorOff      = "Off"
+)
+
+type rfIndicator struct {
+	IndicatorLED            string `json:"IndicatorLED"`
+	LocationIndicatorActive *bool  `json:"LocationIndicatorActive"`
+}
+
+// Indicator is the locator LED of a BMC and the resource it belongs to.
+type Indicator struct {
+	Path string // Chassis or ComputerSystem resource
+	// State is Lit, Blinking, or Off. LocationIndicatorActive, which
+	// replaces the deprecated IndicatorLED, reads as Lit or Off.
+	State string
+	rf    rfIndicator
+	etag  string
+}
+
+func (r rfIndicator) supported() bool {
+	return r.IndicatorLED != "" || r.LocationIndicatorActive != nil
+}
+
+// findIndicator returns the first Chassis with a locator LED or, when no
+// chassis has one, the first such ComputerSystem.
+func (c *client) findIndicator(ctx context.Context) (Indicator, error) {
+	for _, coll := range []string{"/Chassis", "/Systems"} {
+		members, err := c.listMembers(ctx, coll)
+		if isStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			return Indicator{}, err
+		}
+		for _, m := range members {
+			var ind Indicator
+			if ind.etag, err = c.getWithETag(ctx, m, &ind.rf); err != nil {
+				return Indicator{}, err
+			}
+			if !ind.rf.supported() {
+				continue
+			}
+			ind.Path = m
+			ind.State = ind.rf.IndicatorLED
+			if ind.rf.LocationIndicatorActive != nil && ind.State == "" {
+				ind.State = IndicatorOff
+				if *ind.rf.LocationIndicatorActive {
+					ind.State = IndicatorLit
+				}
+			}
+			return ind, nil
+		}
+	}
+	return Indicator{}, ErrNoIndicator
+}
+
+// GetIndicator returns the locator LED of host.
+func GetIndicator(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (Indicator, error) {
+	return newClient(host, user, pass, insecure, timeout).findIndicator(ctx)
+}
+
+// SetIndicator sets the locator LED of host to state (Lit, Blinking, or
+// Off) and returns it as it was before. LocationIndicatorActive is used
+// where the resource has it, except for Blinking, which only IndicatorLED
+// can express; with LocationIndicatorActive alone Blinking turns it on.
+func SetIndicator(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, state string) (Indicator, error) {
+	if state != IndicatorLit && state != IndicatorBlinking && state != IndicatorOff {
+		return Indicator{}, fmt.Errorf("invalid locator LED state %q", state)
+	}
+	c := newClient(host, user, pass, insecure, timeout)
+	ind, err := c.findIndicator(ctx)
+	if err != nil {
+		return ind, err
+	}
+	var body map[string]any
+	if ind.rf.LocationIndicatorActive != nil && (state != IndicatorBlinking || ind.rf.IndicatorLED == "") {
+		body = map[string]any{"LocationIndicatorActive": state != IndicatorOff}
+	} else {
+		body = map[string]any{"IndicatorLED": state}
+	}
+	if err := c.patchWithETag(ctx, ind.Path, body, ind.etag); err != nil {
+		return ind, fmt.Errorf("PATCH %s: %w", ind.Path, err)
+	}
+	return ind, nil
+}
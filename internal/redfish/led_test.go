@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ledBMC serves one Chassis and one System with the given bodies and
+// returns the path and body of the last PATCH.
+func ledBMC(t *testing.T, chassis, system string) (string, *string) {
+	t.Helper()
+	var patched string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Chassis":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Enclosure"}]}`)
+		case r.URL.Path == "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Chassis/Enclosure":
+			w.Header().Set("ETag", `"c-1"`)
+			fmt.Fprint(w, chassis)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Systems/Node0":
+			w.Header().Set("ETag", `"s-1"`)
+			fmt.Fprint(w, system)
+		case r.Method == "PATCH":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			patched = r.URL.Path + " " + r.Header.Get("If-Match") + " " + string(b)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &patched
+}
+
+func TestSetIndicator(t *testing.T) {
+	cases := []struct {
+		name, chassis, system, state string
+		wasState, wantPatch          string
+	}{
+		{"chassis LocationIndicatorActive", `{"LocationIndicatorActive":false,"IndicatorLED":"Off"}`, `{}`, IndicatorLit,
+			"Off", `/redfish/v1/Chassis/Enclosure "c-1" {"LocationIndicatorActive":true}`},
+		{"blink needs IndicatorLED", `{"LocationIndicatorActive":true,"IndicatorLED":"Lit"}`, `{}`, IndicatorBlinking,
+			"Lit", `/redfish/v1/Chassis/Enclosure "c-1" {"IndicatorLED":"Blinking"}`},
+		{"falls back to the system", `{}`, `{"IndicatorLED":"Blinking"}`, IndicatorOff,
+			"Blinking", `/redfish/v1/Systems/Node0 "s-1" {"IndicatorLED":"Off"}`},
+		{"blink with LocationIndicatorActive only", `{}`, `{"LocationIndicatorActive":false}`, IndicatorBlinking,
+			"Off", `/redfish/v1/Systems/Node0 "s-1" {"LocationIndicatorActive":true}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			host, patched := ledBMC(t, c.chassis, c.system)
+			was, err := SetIndicator(context.Background(), host, "u", "p", true, 5*time.Second, c.state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if was.State != c.wasState || *patched != c.wantPatch {
+				t.Errorf("was %q, PATCH %s", was.State, *patched)
+			}
+		})
+	}
+
+	host, patched := ledBMC(t, `{}`, `{}`)
+	if _, err := GetIndicator(context.Background(), host, "u", "p", true, 5*time.Second); !errors.Is(err, ErrNoIndicator) {
+		t.Errorf("expected ErrNoIndicator, got %v", err)
+	}
+	if _, err := SetIndicator(context.Background(), host, "u", "p", true, 5*time.Second, IndicatorLit); !errors.Is(err, ErrNoIndicator) || *patched != "" {
+		t.Errorf("expected ErrNoIndicator without a PATCH, got %v, %q", err, *patched)
+	}
+}
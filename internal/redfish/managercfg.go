@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rfNetworkProtocol is a ManagerNetworkProtocol resource. Redfish has no
+// standard remote syslog property, so syslog is read from the Oem objects
+// of Cray EX BMCs (Oem.Syslog) and HPE iLO (Oem.Hpe.RemoteSyslog*).
+type rfNetworkProtocol struct {
+	HTTPS struct {
+		Certificates struct {
+			OID string `json:"@odata.id"`
+		} `json:"Certificates"`
+	} `json:"HTTPS"`
+	NTP *struct {
+		ProtocolEnabled *bool    `json:"ProtocolEnabled"`
+		NTPServers      []string `json:"NTPServers"`
+	} `json:"NTP"`
+	Oem struct {
+		Syslog *struct {
+			ProtocolEnabled *bool    `json:"ProtocolEnabled"`
+			SyslogServers   []string `json:"SyslogServers"`
+		} `json:"Syslog"`
+		Hpe *struct {
+			RemoteSyslogEnabled *bool   `json:"RemoteSyslogEnabled"`
+			RemoteSyslogServer  *string `json:"RemoteSyslogServer"`
+			RemoteSyslogPort    *int    `json:"RemoteSyslogPort"`
+		} `json:"Hpe"`
+	} `json:"Oem"`
+}
+
+// ManagerConfig is the desired time and logging configuration of a BMC. A
+// nil field is left as it is; an empty, non-nil server list clears it.
+type ManagerConfig struct {
+	NTPEnabled    *bool
+	NTPServers    []string
+	SyslogEnabled *bool
+	// SyslogServers are host or host:port remote log targets.
+	SyslogServers []string
+}
+
+// ConfigChange is one field whose current value differs from the desired one.
+type ConfigChange struct {
+	Field   string // e.g. "ntp.servers"
+	Current string
+	Desired string
+}
+
+// ManagerConfigPlan is what ApplyManagerConfig would change on one BMC.
+type ManagerConfigPlan struct {
+	Path    string // NetworkProtocol resource
+	Changes []ConfigChange
+	// Unsupported lists requested fields the BMC has no property for.
+	Unsupported []string
+	etag        string
+	payload     map[string]any
+}
+
+// PlanManagerConfig reads the NetworkProtocol of the first Manager on host
+// and compares it with want.
+func PlanManagerConfig(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, want ManagerConfig) (ManagerConfigPlan, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil {
+		return ManagerConfigPlan{}, err
+	}
+	if len(managers) == 0 {
+		return ManagerConfigPlan{}, errors.New("no managers reported by BMC")
+	}
+	plan := ManagerConfigPlan{Path: managers[0] + "/NetworkProtocol", payload: map[string]any{}}
+	var np rfNetworkProtocol
+	if plan.etag, err = c.getWithETag(ctx, plan.Path, &np); err != nil {
+		return plan, err
+	}
+	planNTP(&plan, np, want)
+	planSyslog(&plan, np, want)
+	return plan, nil
+}
+
+func planNTP(plan *ManagerConfigPlan, np rfNetworkProtocol, want ManagerConfig) {
+	if want.NTPEnabled == nil && want.NTPServers == nil {
+		return
+	}
+	if np.NTP == nil {
+		plan.Unsupported = append(plan.Unsupported, "ntp")
+		return
+	}
+	ntp := map[string]any{}
+	if want.NTPEnabled != nil && !boolIs(np.NTP.ProtocolEnabled, *want.NTPEnabled) {
+		plan.change("ntp.enabled", formatBool(np.NTP.ProtocolEnabled), strconv.FormatBool(*want.NTPEnabled))
+		ntp["ProtocolEnabled"] = *want.NTPEnabled
+	}
+	if cur := nonEmpty(np.NTP.NTPServers); want.NTPServers != nil && !slices.Equal(cur, want.NTPServers) {
+		plan.change("ntp.servers", formatList(cur), formatList(want.NTPServers))
+		ntp["NTPServers"] = want.NTPServers
+	}
+	if len(ntp) > 0 {
+		plan.payload["NTP"] = ntp
+	}
+}
+
+func planSyslog(plan *ManagerConfigPlan, np rfNetworkProtocol, want ManagerConfig) {
+	if want.SyslogEnabled == nil && want.SyslogServers == nil {
+		return
+	}
+	oem := map[string]any{}
+	switch {
+	case np.Oem.Syslog != nil:
+		syslog := map[string]any{}
+		if want.SyslogEnabled != nil && !boolIs(np.Oem.Syslog.ProtocolEnabled, *want.SyslogEnabled) {
+			plan.change("syslog.enabled", formatBool(np.Oem.Syslog.ProtocolEnabled), strconv.FormatBool(*want.SyslogEnabled))
+			syslog["ProtocolEnabled"] = *want.SyslogEnabled
+		}
+		if cur := nonEmpty(np.Oem.Syslog.SyslogServers); want.SyslogServers != nil && !slices.Equal(cur, want.SyslogServers) {
+			plan.change("syslog.servers", formatList(cur), formatList(want.SyslogServers))
+			syslog["SyslogServers"] = want.SyslogServers
+		}
+		if len(syslog) > 0 {
+			oem["Syslog"] = syslog
+		}
+	case np.Oem.Hpe != nil && np.Oem.Hpe.RemoteSyslogServer != nil:
+		// iLO has a single remote syslog server with a separate port
+		hpe := map[string]any{}
+		hp := np.Oem.Hpe
+		if want.SyslogEnabled != nil && !boolIs(hp.RemoteSyslogEnabled, *want.SyslogEnabled) {
+			plan.change("syslog.enabled", formatBool(hp.RemoteSyslogEnabled), strconv.FormatBool(*want.SyslogEnabled))
+			hpe["RemoteSyslogEnabled"] = *want.SyslogEnabled
+		}
+		if want.SyslogServers != nil {
+			if len(want.SyslogServers) > 1 {
+				plan.Unsupported = append(plan.Unsupported, "syslog.servers beyond the first")
+			}
+			curServer, curPort := *hp.RemoteSyslogServer, 0
+			if hp.RemoteSyslogPort != nil {
+				curPort = *hp.RemoteSyslogPort
+			}
+			server, port := "", 0
+			if len(want.SyslogServers) > 0 {
+				server, port = splitSyslogTarget(want.SyslogServers[0])
+			}
+			if server != curServer || (port != 0 && port != curPort) {
+				plan.change("syslog.servers", formatList(nonEmpty([]string{joinSyslogTarget(curServer, curPort)})), formatList(nonEmpty([]string{joinSyslogTarget(server, port)})))
+				hpe["RemoteSyslogServer"] = server
+				if port != 0 {
+					hpe["RemoteSyslogPort"] = port
+				}
+			}
+		}
+		if len(hpe) > 0 {
+			oem["Hpe"] = hpe
+		}
+	default:
+		plan.Unsupported = append(plan.Unsupported, "syslog")
+	}
+	if len(oem) > 0 {
+		plan.payload["Oem"] = oem
+	}
+}
+
+func (p *ManagerConfigPlan) change(field, current, desired string) {
+	p.Changes = append(p.Changes, ConfigChange{Field: field, Current: current, Desired: desired})
+}
+
+// ApplyManagerConfig PATCHes the changes of plan, made by PlanManagerConfig
+// for the same host, with the ETag read then as If-Match. A plan without
+// changes is not sent.
+func ApplyManagerConfig(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, plan ManagerConfigPlan) error {
+	if len(plan.payload) == 0 {
+		return nil
+	}
+	c := newClient(host, user, pass, insecure, timeout)
+	if err := c.patchWithETag(ctx, plan.Path, plan.payload, plan.etag); err != nil {
+		return fmt.Errorf("PATCH %s: %w", plan.Path, err)
+	}
+	return nil
+}
+
+// splitSyslogTarget splits host:port; port is 0 when target has none.
+func splitSyslogTarget(target string) (string, int) {
+	if h, p, err := net.SplitHostPort(target); err == nil {
+		if n, err := strconv.Atoi(p); err == nil {
+			return h, n
+		}
+	}
+	return target, 0
+}
+
+func joinSyslogTarget(host string, port int) string {
+	if host == "" || port == 0 {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// boolIs reports whether b is set to want.
+func boolIs(b *bool, want bool) bool {
+	return b != nil && *b == want
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return "unset"
+	}
+	return strconv.FormatBool(*b)
+}
+
+func formatList(l []string) string {
+	return "[" + strings.Join(l, ", ") + "]"
+}
+
+// nonEmpty drops the empty placeholders some BMCs return in fixed-size
+// server lists.
+func nonEmpty(l []string) []string {
+	out := []string{}
+	for _, s := range l {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// networkProtocolBMC serves one Manager whose NetworkProtocol is np, and
+// returns the body of the PATCH it receives.
+func networkProtocolBMC(t *testing.T, np string) (string, *string) {
+	t.Helper()
+	var patched string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers/BMC/NetworkProtocol" && r.Method == "GET":
+			w.Header().Set("ETag", `"np-1"`)
+			fmt.Fprint(w, np)
+		case r.URL.Path == "/redfish/v1/Managers/BMC/NetworkProtocol" && r.Method == "PATCH":
+			if r.Header.Get("If-Match") != `"np-1"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			patched = string(b)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &patched
+}
+
+func TestManagerConfigCray(t *testing.T) {
+	host, patched := networkProtocolBMC(t, `{
+		"NTP":{"ProtocolEnabled":false,"NTPServers":["10.1.0.1",""]},
+		"Oem":{"Syslog":{"ProtocolEnabled":true,"SyslogServers":[]}}}`)
+	on := true
+	want := ManagerConfig{NTPEnabled: &on, NTPServers: []string{"10.1.0.1"}, SyslogEnabled: &on, SyslogServers: []string{"10.1.0.3:514"}}
+	plan, err := PlanManagerConfig(context.Background(), host, "u", "p", true, 5*time.Second, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChanges := []ConfigChange{
+		{Field: "ntp.enabled", Current: "false", Desired: "true"},
+		{Field: "syslog.servers", Current: "[]", Desired: "[10.1.0.3:514]"},
+	}
+	if !slices.Equal(plan.Changes, wantChanges) || len(plan.Unsupported) != 0 {
+		t.Errorf("changes = %+v, unsupported = %v", plan.Changes, plan.Unsupported)
+	}
+	if err := ApplyManagerConfig(context.Background(), host, "u", "p", true, 5*time.Second, plan); err != nil {
+		t.Fatal(err)
+	}
+	if *patched != `{"NTP":{"ProtocolEnabled":true},"Oem":{"Syslog":{"SyslogServers":["10.1.0.3:514"]}}}` {
+		t.Errorf("PATCH body = %s", *patched)
+	}
+}
+
+func TestManagerConfigHPEAndUnsupported(t *testing.T) {
+	host, patched := networkProtocolBMC(t, `{"Oem":{"Hpe":{"RemoteSyslogEnabled":false,"RemoteSyslogServer":"","RemoteSyslogPort":514}}}`)
+	on := true
+	want := ManagerConfig{NTPServers: []string{"10.1.0.1"}, SyslogEnabled: &on, SyslogServers: []string{"10.1.0.3:1514", "10.1.0.4"}}
+	plan, err := PlanManagerConfig(context.Background(), host, "u", "p", true, 5*time.Second, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(plan.Unsupported, []string{"ntp", "syslog.servers beyond the first"}) || len(plan.Changes) != 2 {
+		t.Errorf("changes = %+v, unsupported = %v", plan.Changes, plan.Unsupported)
+	}
+	if err := ApplyManagerConfig(context.Background(), host, "u", "p", true, 5*time.Second, plan); err != nil {
+		t.Fatal(err)
+	}
+	if *patched != `{"Oem":{"Hpe":{"RemoteSyslogEnabled":true,"RemoteSyslogPort":1514,"RemoteSyslogServer":"10.1.0.3"}}}` {
+		t.Errorf("PATCH body = %s", *patched)
+	}
+
+	// Nothing to change: nothing is sent
+	host, patched = networkProtocolBMC(t, `{"NTP":{"ProtocolEnabled":true,"NTPServers":["10.1.0.1"]}}`)
+	plan, err = PlanManagerConfig(context.Background(), host, "u", "p", true, 5*time.Second, ManagerConfig{NTPServers: []string{"10.1.0.1"}, SyslogEnabled: &on})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyManagerConfig(context.Background(), host, "u", "p", true, 5*time.Second, plan); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 || !slices.Equal(plan.Unsupported, []string{"syslog"}) || *patched != "" {
+		t.Errorf("changes = %+v, unsupported = %v, patched %q", plan.Changes, plan.Unsupported, *patched)
+	}
+}
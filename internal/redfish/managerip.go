@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"bootstrap/internal/httptimeout"
+)
+
+// IPv4Static is a static IPv4 configuration of a manager interface.
+type IPv4Static struct {
+	Address    string
+	SubnetMask string
+	Gateway    string // may be empty
+}
+
+// rfManagerInterface is the addressing of a manager EthernetInterface.
+// IPv4StaticAddresses is raw so that its presence can be told from its
+// absence; older BMCs only take IPv4Addresses with AddressOrigin Static.
+type rfManagerInterface struct {
+	MACAddress    string `json:"MACAddress"`
+	IPv4Addresses []struct {
+		Address string `json:"Address"`
+	} `json:"IPv4Addresses"`
+	IPv4StaticAddresses json.RawMessage `json:"IPv4StaticAddresses"`
+	DHCPv4              *struct {
+		DHCPEnabled *bool `json:"DHCPEnabled"`
+	} `json:"DHCPv4"`
+}
+
+// ManagerAddressChange is what SetManagerIPv4 changed.
+type ManagerAddressChange struct {
+	Path       string // the EthernetInterface PATCHed
+	MACAddress string // its MAC, lowercase
+}
+
+// ErrAddressUnconfirmed is returned by SetManagerIPv4 when the PATCH got no
+// answer: the BMC may have moved to its new address before replying.
+var ErrAddressUnconfirmed = errors.New("no answer to the address change")
+
+// SetManagerIPv4 gives the interface of the first Manager on host that
+// carries host's address, or else has MAC mac, the static address cfg and
+// turns DHCPv4 off on it. Any error but ErrAddressUnconfirmed means the
+// BMC kept its address. The returned change is filled in as soon as the
+// interface is known.
+func SetManagerIPv4(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, mac string, cfg IPv4Static) (ManagerAddressChange, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	managers, err := c.listMembers(ctx, "/Managers")
+	if err != nil {
+		return ManagerAddressChange{}, err
+	}
+	if len(managers) == 0 {
+		return ManagerAddressChange{}, errors.New("no managers reported by BMC")
+	}
+	var mgr rfManager
+	if err := c.get(ctx, managers[0], &mgr); err != nil {
+		return ManagerAddressChange{}, err
+	}
+	ifacesPath := mgr.EthernetIfaces.OID
+	if ifacesPath == "" {
+		ifacesPath = managers[0] + "/EthernetInterfaces"
+	}
+	ifaces, err := c.listMembers(ctx, ifacesPath)
+	if err != nil {
+		return ManagerAddressChange{}, err
+	}
+	hostIP := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostIP = h
+	}
+
+	var (
+		change ManagerAddressChange
+		nic    rfManagerInterface
+		etag   string
+	)
+	for _, m := range ifaces {
+		var n rfManagerInterface
+		tag, err := c.getWithETag(ctx, m, &n)
+		if err != nil {
+			return ManagerAddressChange{}, err
+		}
+		byAddress := false
+		for _, a := range n.IPv4Addresses {
+			byAddress = byAddress || a.Address == hostIP
+		}
+		if byAddress || (change.Path == "" && mac != "" && strings.EqualFold(n.MACAddress, mac)) {
+			change = ManagerAddressChange{Path: m, MACAddress: strings.ToLower(n.MACAddress)}
+			nic, etag = n, tag
+			if byAddress {
+				break
+			}
+		}
+	}
+	if change.Path == "" {
+		return change, fmt.Errorf("no interface of %s has address %s or MAC %s", managers[0], hostIP, mac)
+	}
+
+	addr := map[string]any{"Address": cfg.Address, "SubnetMask": cfg.SubnetMask}
+	if cfg.Gateway != "" {
+		addr["Gateway"] = cfg.Gateway
+	}
+	payload := map[string]any{}
+	if nic.IPv4StaticAddresses != nil {
+		payload["IPv4StaticAddresses"] = []any{addr}
+	} else {
+		addr["AddressOrigin"] = "Static"
+		payload["IPv4Addresses"] = []any{addr}
+	}
+	if nic.DHCPv4 != nil {
+		payload["DHCPv4"] = map[string]any{"DHCPEnabled": false}
+	}
+	if err := c.patchWithETag(ctx, change.Path, payload, etag); err != nil {
+		var serr *StatusError
+		if errors.As(err, &serr) {
+			return change, fmt.Errorf("PATCH %s: %w", change.Path, err)
+		}
+		return change, fmt.Errorf("PATCH %s: %w: %w", change.Path, ErrAddressUnconfirmed, err)
+	}
+	return change, nil
+}
+
+// WaitForAddress polls host every interval until its first Manager answers
+// an authenticated request on an interface with MAC mac (any interface when
+// mac is empty), so another device that happens to hold the address is not
+// mistaken for the BMC. Every probe uses a new connection.
+func WaitForAddress(ctx context.Context, host, user, pass string, insecure bool, mac string, interval time.Duration) error {
+	var last error
+	for {
+		pctx, cancel := context.WithTimeout(ctx, recoveryPollTimeout)
+		tr := httptimeout.Apply(&http.Transport{Proxy: proxy, TLSClientConfig: tlsConfig(host, insecure), DisableKeepAlives: true})
+		info, err := newClientWith(tr, host, user, pass, recoveryPollTimeout).managerInfo(pctx, host)
+		cancel()
+		switch {
+		case err != nil && ctx.Err() != nil && last != nil:
+			// A probe cut short says less than the one before it
+		case err != nil:
+			last = err
+		case mac != "" && info.MACAddress != strings.ToLower(mac):
+			last = fmt.Errorf("answered by an interface with MAC %s, not %s", info.MACAddress, mac)
+		default:
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last: %v)", ctx.Err(), last)
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// managerNICBMC serves one Manager with one interface, nic, and answers a
+// PATCH of it with patch (0 drops the connection). It returns the host and
+// the PATCH body it received.
+func managerNICBMC(t *testing.T, nic string, patch int) (string, *string) {
+	t.Helper()
+	var patched string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers/BMC":
+			fmt.Fprint(w, `{"EthernetInterfaces":{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces"}}`)
+		case r.URL.Path == "/redfish/v1/Managers/BMC/EthernetInterfaces":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces/eth0"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers/BMC/EthernetInterfaces/eth0" && r.Method == "GET":
+			w.Header().Set("ETag", `"eth0-1"`)
+			fmt.Fprint(w, nic)
+		case r.URL.Path == "/redfish/v1/Managers/BMC/EthernetInterfaces/eth0" && r.Method == "PATCH":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			patched = string(b)
+			if patch == 0 {
+				// The BMC moved before it answered
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close() // nolint:errcheck
+				return
+			}
+			w.WriteHeader(patch)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &patched
+}
+
+func TestSetManagerIPv4(t *testing.T) {
+	cfg := IPv4Static{Address: "10.1.0.5", SubnetMask: "255.255.255.0", Gateway: "10.1.0.1"}
+
+	host, patched := managerNICBMC(t, `{"MACAddress":"02:23:28:01:30:00","IPv4Addresses":[{"Address":"127.0.0.1"}],
+		"IPv4StaticAddresses":[],"DHCPv4":{"DHCPEnabled":true}}`, http.StatusNoContent)
+	change, err := SetManagerIPv4(context.Background(), host, "u", "p", true, 5*time.Second, "", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Path != "/redfish/v1/Managers/BMC/EthernetInterfaces/eth0" || change.MACAddress != "02:23:28:01:30:00" {
+		t.Errorf("change = %+v", change)
+	}
+	if want := `{"DHCPv4":{"DHCPEnabled":false},"IPv4StaticAddresses":[{"Address":"10.1.0.5","Gateway":"10.1.0.1","SubnetMask":"255.255.255.0"}]}`; *patched != want {
+		t.Errorf("PATCH body = %s\nwant %s", *patched, want)
+	}
+
+	// Without IPv4StaticAddresses the address is set as a static IPv4Addresses
+	// entry; the interface is found by MAC
+	host, patched = managerNICBMC(t, `{"MACAddress":"02:23:28:01:30:00","IPv4Addresses":[{"Address":"10.9.9.9"}]}`, http.StatusNoContent)
+	if _, err := SetManagerIPv4(context.Background(), host, "u", "p", true, 5*time.Second, "02:23:28:01:30:00", cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"IPv4Addresses":[{"Address":"10.1.0.5","AddressOrigin":"Static","Gateway":"10.1.0.1","SubnetMask":"255.255.255.0"}]}`; *patched != want {
+		t.Errorf("PATCH body = %s\nwant %s", *patched, want)
+	}
+	if _, err := SetManagerIPv4(context.Background(), host, "u", "p", true, 5*time.Second, "02:23:28:01:30:99", cfg); err == nil || !strings.Contains(err.Error(), "no interface") {
+		t.Errorf("unknown MAC: err = %v", err)
+	}
+
+	// A refused change kept the old address; a lost answer may not have
+	host, _ = managerNICBMC(t, `{"MACAddress":"02:23:28:01:30:00","IPv4Addresses":[{"Address":"127.0.0.1"}]}`, http.StatusBadRequest)
+	if _, err := SetManagerIPv4(context.Background(), host, "u", "p", true, 5*time.Second, "", cfg); err == nil || errors.Is(err, ErrAddressUnconfirmed) {
+		t.Errorf("400: err = %v, want a refusal", err)
+	}
+	host, _ = managerNICBMC(t, `{"MACAddress":"02:23:28:01:30:00","IPv4Addresses":[{"Address":"127.0.0.1"}]}`, 0)
+	if _, err := SetManagerIPv4(context.Background(), host, "u", "p", true, 5*time.Second, "", cfg); !errors.Is(err, ErrAddressUnconfirmed) {
+		t.Errorf("dropped connection: err = %v, want ErrAddressUnconfirmed", err)
+	}
+}
+
+func TestWaitForAddressChecksMAC(t *testing.T) {
+	host, _ := managerNICBMC(t, `{"MACAddress":"02:23:28:01:30:00","IPv4Addresses":[{"Address":"127.0.0.1"}]}`, http.StatusNoContent)
+	if err := WaitForAddress(context.Background(), host, "u", "p", true, "02:23:28:01:30:00", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := WaitForAddress(ctx, host, "u", "p", true, "02:23:28:01:30:10", 10*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "not 02:23:28:01:30:10") {
+		t.Errorf("another device's MAC: err = %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"bootstrap/internal/diag"
+)
+
+// DefaultNICExclude matches the Description of Cray EX high-speed-network
+// ports, which must never be used to PXE boot.
+const DefaultNICExclude = "hsn"
+
+// NICRules adjust which EthernetInterfaces count as bootable. The zero value
+// applies only the built-in heuristics.
+type NICRules struct {
+	// Explain logs every EthernetInterface of each system read, and the
+	// rule that chose or rejected it (discover --explain-nics).
+	Explain bool
+
+	exclude *regexp.Regexp
+	include []string
+}
+
+// ParseNICRules builds NICRules. NICs whose Description matches the
+// case-insensitive regular expression exclude are never bootable, unless
+// their Id is listed in include, which forces them to be.
+func ParseNICRules(exclude string, include []string) (NICRules, error) {
+	var r NICRules
+	if exclude != "" {
+		re, err := regexp.Compile("(?i)" + exclude)
+		if err != nil {
+			return r, fmt.Errorf("NIC exclude pattern: %w", err)
+		}
+		r.exclude = re
+	}
+	for _, id := range include {
+		if id = strings.TrimSpace(id); id != "" {
+			r.include = append(r.include, id)
+		}
+	}
+	return r, nil
+}
+
+func (r NICRules) included(n rfEthernetInterface) bool {
+	return slices.ContainsFunc(r.include, func(id string) bool { return strings.EqualFold(id, n.ID) })
+}
+
+func (r NICRules) excluded(n rfEthernetInterface) bool {
+	return r.exclude != nil && r.exclude.MatchString(n.Description)
+}
+
+// nicDecision is how bootableMACs treated one EthernetInterface: its rank
+// among the MACs returned (1 for the one to boot from), or 0 when it was not
+// chosen, and the rule that decided it.
+type nicDecision struct {
+	nic    rfEthernetInterface
+	rank   int
+	reason string
+}
+
+// bootableMACs returns the lowercase MACs of nics to PXE boot from, best
+// first, and a decision for every NIC, in the order of nics. Interfaces
+// forced by rules come first. Otherwise the candidates are the interfaces
+// the vendor's Oem data marks for PXE or, when there are none, those
+// isBootable accepts, with LinkUp interfaces ahead of the rest. Oem data is
+// ignored when hostVendor is known and not HPE. When nothing qualifies, the
+// first valid MAC that is not excluded is used.
+func bootableMACs(nics []rfEthernetInterface, rules NICRules, hostVendor string) ([]string, []nicDecision) {
+	readOem := hostVendor == "" || isHPE(hostVendor)
+	decisions := make([]nicDecision, len(nics))
+	var forced, vendor, heuristic, usable []int
+	for i, n := range nics {
+		d := &decisions[i]
+		d.nic = n
+		switch {
+		case n.MACAddress == "":
+			d.reason = "no MAC address"
+		case !isValidMAC(n.MACAddress):
+			d.reason = fmt.Sprintf("invalid MAC address %q", n.MACAddress)
+		case rules.included(n):
+			forced = append(forced, i)
+			d.reason = "forced: its Id is listed in --nic-include"
+		case rules.excluded(n):
+			d.reason = fmt.Sprintf("excluded: Description %q matches --nic-exclude", n.Description)
+		default:
+			usable = append(usable, i)
+			bootable, why := isBootable(n)
+			switch {
+			case readOem && oemPXE(n.Oem):
+				vendor = append(vendor, i)
+				d.reason = "vendor: its Oem data marks it for PXE"
+			case bootable:
+				heuristic = append(heuristic, i)
+				d.reason = "heuristic: " + why
+			default:
+				d.reason = "heuristic: " + why
+			}
+		}
+	}
+	candidates := vendor
+	if len(candidates) == 0 {
+		candidates = heuristic
+	} else {
+		for _, i := range heuristic {
+			decisions[i].reason += "; not used, as the vendor's Oem data marks other NICs for PXE"
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		return boolRank(nics[b].LinkStatus == "LinkUp") - boolRank(nics[a].LinkStatus == "LinkUp")
+	})
+	chosen := append(forced, candidates...)
+	if len(chosen) == 0 && len(usable) > 0 {
+		chosen = usable[:1]
+		d := &decisions[chosen[0]]
+		d.reason = "fallback: no NIC qualified, so the first usable one is used (" + d.reason + ")"
+	}
+	macs := make([]string, 0, len(chosen))
+	for rank, i := range chosen {
+		decisions[i].rank = rank + 1
+		macs = append(macs, strings.ToLower(nics[i].MACAddress))
+	}
+	return macs, decisions
+}
+
+// explainNICs logs the decisions of bootableMACs for the system at sysPath
+// (NICRules.Explain), through the HostLog in ctx or, without one, a HostLog
+// for host. In json mode every NIC is a record with its fields as
+// attributes.
+func explainNICs(ctx context.Context, host, sysPath string, decisions []nicDecision) {
+	hl, done := explainLog(ctx, host)
+	defer done()
+	if len(decisions) == 0 {
+		hl.Infof("%s: no EthernetInterfaces", sysPath)
+		return
+	}
+	for _, d := range decisions {
+		n := d.nic
+		var origins []string
+		for _, a := range n.IPv4Addresses {
+			if a.Origin != "" {
+				origins = append(origins, a.Origin)
+			}
+		}
+		verdict := "rejected"
+		if d.rank > 0 {
+			verdict = fmt.Sprintf("chosen #%d", d.rank)
+		}
+		attrs := []slog.Attr{
+			slog.String("system", sysPath),
+			slog.String("nic", n.ID),
+			slog.String("mac", n.MACAddress),
+			slog.String("uefi_device_path", n.UefiDevicePath),
+			slog.Any("ipv4_origins", origins),
+			slog.String("link_status", n.LinkStatus),
+			slog.Int("rank", d.rank),
+			slog.String("reason", d.reason),
+		}
+		hl.InfoAttrsf(attrs, "%s: NIC %s: %s: %s (MAC %s, link %s, UefiDevicePath %s, IPv4 origins %s)",
+			sysPath, valueOr(n.ID, "-"), verdict, d.reason, valueOr(n.MACAddress, "-"), valueOr(n.LinkStatus, "-"),
+			valueOr(n.UefiDevicePath, "-"), valueOr(strings.Join(origins, ","), "-"))
+	}
+}
+
+// explainError logs, for NICRules.Explain, why the EthernetInterfaces of
+// the system at sysPath were not considered.
+func explainError(ctx context.Context, host, sysPath string, err error) {
+	hl, done := explainLog(ctx, host)
+	defer done()
+	hl.Infof("%s: EthernetInterfaces could not be read; system skipped: %v", sysPath, err)
+}
+
+// explainLog returns the HostLog explanations go to: the one in ctx, which
+// its owner flushes, or a new one for host, flushed by done.
+func explainLog(ctx context.Context, host string) (hl *diag.HostLog, done func()) {
+	if hl := diag.HostLogFrom(ctx); hl != nil {
+		return hl, func() {}
+	}
+	hl = diag.NewHostLog(host)
+	return hl, hl.Flush
+}
+
+// valueOr returns s, or def when s is empty.
+func valueOr(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// oemPXE reports whether the Oem object of an EthernetInterface marks it as
+// a PXE interface, as HPE iLO does with Oem.Hpe (or Oem.Hp on older
+// firmware) members such as "PXEEnabled": true or "BootProtocol": "PXE".
+func oemPXE(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	var oem map[string]map[string]any
+	if json.Unmarshal(raw, &oem) != nil {
+		return false
+	}
+	for _, vendor := range []string{"Hpe", "Hp"} {
+		for key, v := range oem[vendor] {
+			if !strings.Contains(strings.ToLower(key), "pxe") && !strings.EqualFold(key, "BootProtocol") {
+				continue
+			}
+			switch v := v.(type) {
+			case bool:
+				if v {
+					return true
+				}
+			case string:
+				if strings.EqualFold(v, "PXE") || strings.EqualFold(v, "Enabled") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBootableMACs(t *testing.T) {
+	defaults, err := ParseNICRules(DefaultNICExclude, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forced, err := ParseNICRules(DefaultNICExclude, []string{"hsn1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hsn := rfEthernetInterface{ID: "hsn0", Description: "HSN port 0", MACAddress: "02:00:00:00:00:01", UefiDevicePath: "MAC(020000000001)"}
+	hsn1 := rfEthernetInterface{ID: "hsn1", Description: "HSN port 1", MACAddress: "02:00:00:00:00:02"}
+	down := rfEthernetInterface{ID: "1", MACAddress: "AA:00:00:00:00:01", LinkStatus: "LinkDown", UefiDevicePath: "PciRoot(0x0)/MAC(aa0000000001)/IPv4(0.0.0.0)"}
+	up := rfEthernetInterface{ID: "2", MACAddress: "AA:00:00:00:00:02", LinkStatus: "LinkUp", UefiDevicePath: "PciRoot(0x0)/MAC(aa0000000002)/IPv4(0.0.0.0)"}
+	hpe := rfEthernetInterface{ID: "3", MACAddress: "AA:00:00:00:00:03", Oem: json.RawMessage(`{"Hpe":{"PXEEnabled":true}}`)}
+	disabled := false
+	off := rfEthernetInterface{ID: "4", MACAddress: "AA:00:00:00:00:04", InterfaceEnabled: &disabled}
+
+	tests := []struct {
+		name   string
+		nics   []rfEthernetInterface
+		rules  NICRules
+		vendor string
+		want   []string
+	}{
+		{"hsn excluded, LinkUp first", []rfEthernetInterface{hsn, down, up}, defaults, "", []string{"aa:00:00:00:00:02", "aa:00:00:00:00:01"}},
+		{"no rules keep the old heuristics", []rfEthernetInterface{hsn, down}, NICRules{}, "", []string{"02:00:00:00:00:01", "aa:00:00:00:00:01"}},
+		{"OEM PXE beats the heuristics", []rfEthernetInterface{up, hpe}, defaults, "HPE", []string{"aa:00:00:00:00:03"}},
+		{"OEM data of another vendor is ignored", []rfEthernetInterface{up, hpe}, defaults, "Dell", []string{"aa:00:00:00:00:02", "aa:00:00:00:00:03"}},
+		{"include forces an excluded NIC", []rfEthernetInterface{up, hsn1}, forced, "", []string{"02:00:00:00:00:02", "aa:00:00:00:00:02"}},
+		{"fallback skips excluded NICs", []rfEthernetInterface{hsn1, off}, defaults, "", []string{"aa:00:00:00:00:04"}},
+		{"only excluded NICs", []rfEthernetInterface{hsn, hsn1}, defaults, "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := bootableMACs(tt.nics, tt.rules, tt.vendor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bootableMACs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBootableMACsDecisions(t *testing.T) {
+	rules, err := ParseNICRules(DefaultNICExclude, []string{"mgmt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabled := false
+	type decision struct {
+		rank   int
+		reason string
+	}
+	decide := func(nics []rfEthernetInterface, vendor string) []decision {
+		_, ds := bootableMACs(nics, rules, vendor)
+		out := make([]decision, len(ds))
+		for i, d := range ds {
+			out[i] = decision{d.rank, d.reason}
+		}
+		return out
+	}
+
+	got := decide([]rfEthernetInterface{
+		{ID: "1", MACAddress: "AA:00:00:00:00:01", UefiDevicePath: "PciRoot(0x0)/MAC(aa0000000001)/IPv4(0.0.0.0)"},
+		{ID: "2", MACAddress: "AA:00:00:00:00:02", LinkStatus: "LinkUp", IPv4Addresses: []struct {
+			Address string "json:\"Address\""
+			Origin  string "json:\"AddressOrigin\""
+		}{{Address: "10.0.0.2", Origin: "DHCP"}}},
+		{ID: "hsn0", Description: "HSN port 0", MACAddress: "02:00:00:00:00:01"},
+		{ID: "4", MACAddress: "AA:00:00:00:00:04", InterfaceEnabled: &disabled},
+		{ID: "5", MACAddress: "Not Available"},
+		{ID: "mgmt", MACAddress: "AA:00:00:00:00:06", InterfaceEnabled: &disabled},
+	}, "")
+	want := []decision{
+		{3, `heuristic: UefiDevicePath contains "ipv4"`},
+		{2, "heuristic: an IPv4 address has AddressOrigin DHCP"},
+		{0, `excluded: Description "HSN port 0" matches --nic-exclude`},
+		{0, "heuristic: InterfaceEnabled is false, and neither its UefiDevicePath nor a DHCP address marks it bootable"},
+		{0, `invalid MAC address "Not Available"`},
+		{1, "forced: its Id is listed in --nic-include"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decisions:\n got %v\nwant %v", got, want)
+	}
+
+	// The vendor tier pushes out the heuristics; the fallback explains itself
+	hpe := rfEthernetInterface{ID: "3", MACAddress: "AA:00:00:00:00:03", Oem: json.RawMessage(`{"Hpe":{"PXEEnabled":true}}`)}
+	got = decide([]rfEthernetInterface{{ID: "1", MACAddress: "AA:00:00:00:00:01", UefiDevicePath: "VenHw(PXE)"}, hpe}, "HPE")
+	if got[0].rank != 0 || !strings.HasSuffix(got[0].reason, "not used, as the vendor's Oem data marks other NICs for PXE") ||
+		got[1] != (decision{1, "vendor: its Oem data marks it for PXE"}) {
+		t.Errorf("vendor tier: %v", got)
+	}
+	got = decide([]rfEthernetInterface{{ID: "1"}, {ID: "4", MACAddress: "AA:00:00:00:00:04", InterfaceEnabled: &disabled}}, "")
+	if got[0] != (decision{0, "no MAC address"}) || got[1].rank != 1 || !strings.HasPrefix(got[1].reason, "fallback: no NIC qualified, so the first usable one is used (heuristic: InterfaceEnabled is false") {
+		t.Errorf("fallback: %v", got)
+	}
+}
+
+func TestOemPXE(t *testing.T) {
+	for raw, want := range map[string]bool{
+		`{"Hpe":{"PXEEnabled":true}}`:      true,
+		`{"Hp":{"BootProtocol":"PXE"}}`:    true,
+		`{"Hpe":{"PXEEnabled":false}}`:     false,
+		`{"Dell":{"PXEEnabled":true}}`:     false,
+		`{"Hpe":{"InterfaceType":"Host"}}`: false,
+		``:                                 false,
+	} {
+		if got := oemPXE(json.RawMessage(raw)); got != want {
+			t.Errorf("oemPXE(%s) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestParseNICRulesRejectsBadPattern(t *testing.T) {
+	if _, err := ParseNICRules("hsn(", nil); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+)
+
+// ErrNoPowerLimit is returned when no Chassis of the BMC has a power limit
+// that can be read or set.
+var ErrNoPowerLimit = errors.New("no Chassis with a power limit")
+
+// Sources of a PowerLimit. Oem limits use the vendor name instead.
+const (
+	PowerLimitEnvironmentMetrics = "EnvironmentMetrics"
+	PowerLimitPowerControl       = "PowerControl"
+)
+
+// PowerLimit is the power limit of one Chassis.
+type PowerLimit struct {
+	Chassis string // Chassis Id
+	Path    string // resource that holds the limit
+	Source  string // EnvironmentMetrics, PowerControl, or an Oem vendor
+	// LimitWatts is nil when no limit is set
+	LimitWatts    *float64
+	ConsumedWatts *float64
+	// MinWatts and MaxWatts bound the limit when the BMC reports them
+	MinWatts *float64
+	MaxWatts *float64
+	etag     string
+	body     func(watts *float64) map[string]any
+}
+
+type rfEnvironmentMetrics struct {
+	PowerWatts *struct {
+		Reading *float64 `json:"Reading"`
+	} `json:"PowerWatts"`
+	PowerLimitWatts *struct {
+		SetPoint     *float64 `json:"SetPoint"`
+		ControlMode  string   `json:"ControlMode"`
+		AllowableMin *float64 `json:"AllowableMin"`
+		AllowableMax *float64 `json:"AllowableMax"`
+	} `json:"PowerLimitWatts"`
+}
+
+type rfPowerLimits struct {
+	PowerControl []struct {
+		PowerConsumedWatts *float64 `json:"PowerConsumedWatts"`
+		PowerCapacityWatts *float64 `json:"PowerCapacityWatts"`
+		PowerLimit         *struct {
+			LimitInWatts *float64 `json:"LimitInWatts"`
+		} `json:"PowerLimit"`
+	} `json:"PowerControl"`
+	Oem json.RawMessage `json:"Oem"`
+}
+
+// powerLimitOem reads and sets a vendor power limit kept in the Oem object
+// of a Chassis Power resource.
+type powerLimitOem struct {
+	vendor string
+	// read returns the limit from the Oem object, or false when the
+	// object has none of this vendor
+	read func(oem json.RawMessage) (PowerLimit, bool)
+	// body returns the Power PATCH that sets watts, or clears the limit
+	// when watts is nil
+	body func(watts *float64) map[string]any
+}
+
+// oemPowerLimits are tried in order on chassis whose Power resource has no
+// standard PowerLimit. Vendors are added here once qualified on hardware.
+var oemPowerLimits []powerLimitOem
+
+// findPowerLimits returns the power limit of every Chassis that has one.
+// EnvironmentMetrics PowerLimitWatts is preferred, then the PowerLimit of
+// the deprecated Power resource's PowerControl, then oemPowerLimits.
+func (c *client) findPowerLimits(ctx context.Context) ([]PowerLimit, error) {
+	members, err := c.listMembers(ctx, "/Chassis")
+	if err != nil {
+		return nil, err
+	}
+	var out []PowerLimit
+	for _, m := range members {
+		var ch struct {
+			rfChassis
+			EnvironmentMetrics rfLink `json:"EnvironmentMetrics"`
+		}
+		if err := c.get(ctx, m, &ch); err != nil {
+			return nil, err
+		}
+		id := ch.ID
+		if id == "" {
+			id = path.Base(m)
+		}
+		limit, ok, err := c.chassisPowerLimit(ctx, ch.EnvironmentMetrics.OID, ch.Power.OID)
+		if err != nil {
+			return nil, fmt.Errorf("chassis %s: %w", id, err)
+		}
+		if ok {
+			limit.Chassis = id
+			out = append(out, limit)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNoPowerLimit
+	}
+	return out, nil
+}
+
+// chassisPowerLimit reads the limit of one chassis from its
+// EnvironmentMetrics or Power resource. ok is false when neither has one.
+func (c *client) chassisPowerLimit(ctx context.Context, metricsPath, powerPath string) (PowerLimit, bool, error) {
+	if metricsPath != "" {
+		var em rfEnvironmentMetrics
+		etag, err := c.getWithETag(ctx, metricsPath, &em)
+		if err != nil {
+			return PowerLimit{}, false, err
+		}
+		if pl := em.PowerLimitWatts; pl != nil {
+			limit := PowerLimit{Path: metricsPath, Source: PowerLimitEnvironmentMetrics, MinWatts: pl.AllowableMin,
+				MaxWatts: pl.AllowableMax, etag: etag, body: environmentMetricsLimit}
+			if pl.ControlMode != "Disabled" {
+				limit.LimitWatts = pl.SetPoint
+			}
+			if em.PowerWatts != nil {
+				limit.ConsumedWatts = em.PowerWatts.Reading
+			}
+			return limit, true, nil
+		}
+	}
+	if powerPath == "" {
+		return PowerLimit{}, false, nil
+	}
+	var pw rfPowerLimits
+	etag, err := c.getWithETag(ctx, powerPath, &pw)
+	if err != nil {
+		return PowerLimit{}, false, err
+	}
+	for i, pc := range pw.PowerControl {
+		if pc.PowerLimit == nil {
+			continue
+		}
+		return PowerLimit{Path: powerPath, Source: PowerLimitPowerControl, LimitWatts: pc.PowerLimit.LimitInWatts,
+			ConsumedWatts: pc.PowerConsumedWatts, MaxWatts: pc.PowerCapacityWatts, etag: etag,
+			body: powerControlLimit(i)}, true, nil
+	}
+	for _, oem := range oemPowerLimits {
+		if limit, ok := oem.read(pw.Oem); ok {
+			limit.Path, limit.Source, limit.etag, limit.body = powerPath, oem.vendor, etag, oem.body
+			return limit, true, nil
+		}
+	}
+	return PowerLimit{}, false, nil
+}
+
+// environmentMetricsLimit returns the EnvironmentMetrics PATCH that sets the
+// limit to watts, or disables it when watts is nil.
+func environmentMetricsLimit(watts *float64) map[string]any {
+	if watts == nil {
+		return map[string]any{"PowerLimitWatts": map[string]any{"ControlMode": "Disabled"}}
+	}
+	return map[string]any{"PowerLimitWatts": map[string]any{"SetPoint": *watts, "ControlMode": "Automatic"}}
+}
+
+// powerControlLimit returns a function building the Power PATCH that sets
+// LimitInWatts of PowerControl entry i; earlier entries are left unchanged
+// with empty objects.
+func powerControlLimit(i int) func(watts *float64) map[string]any {
+	return func(watts *float64) map[string]any {
+		entries := make([]any, i+1)
+		for j := range i {
+			entries[j] = map[string]any{}
+		}
+		var limit any // null clears the limit
+		if watts != nil {
+			limit = *watts
+		}
+		entries[i] = map[string]any{"PowerLimit": map[string]any{"LimitInWatts": limit}}
+		return map[string]any{"PowerControl": entries}
+	}
+}
+
+// GetPowerLimits returns the power limit of every Chassis of host that has
+// one, or ErrNoPowerLimit.
+func GetPowerLimits(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]PowerLimit, error) {
+	return newClient(host, user, pass, insecure, timeout).findPowerLimits(ctx)
+}
+
+// SetPowerLimits sets the power limit of every Chassis of host that has one
+// to watts, or removes it when watts is nil, and returns the limits as they
+// were. A limit outside the range a chassis reports is refused before any
+// chassis is changed.
+func SetPowerLimits(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, watts *float64) ([]PowerLimit, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	limits, err := c.findPowerLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if watts != nil {
+		for _, l := range limits {
+			if (l.MinWatts != nil && *watts < *l.MinWatts) || (l.MaxWatts != nil && *l.MaxWatts > 0 && *watts > *l.MaxWatts) {
+				return limits, fmt.Errorf("chassis %s: %g W is outside the allowed range %s", l.Chassis, *watts, l.Range())
+			}
+		}
+	}
+	for _, l := range limits {
+		if err := c.patchWithETag(ctx, l.Path, l.body(watts), l.etag); err != nil {
+			return limits, fmt.Errorf("chassis %s: PATCH %s: %w", l.Chassis, l.Path, err)
+		}
+	}
+	return limits, nil
+}
+
+// Range formats the allowed range of the limit, e.g. "100-500 W", with "?"
+// for an unknown bound.
+func (l PowerLimit) Range() string {
+	bound := func(v *float64) string {
+		if v == nil {
+			return "?"
+		}
+		return fmt.Sprintf("%g", *v)
+	}
+	return bound(l.MinWatts) + "-" + bound(l.MaxWatts) + " W"
+}
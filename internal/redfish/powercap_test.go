@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// powerCapBMC serves one Chassis with the given chassis, EnvironmentMetrics,
+// and Power bodies and returns the path, If-Match, and body of the last
+// PATCH.
+func powerCapBMC(t *testing.T, chassis, metrics, power string) (string, *string) {
+	t.Helper()
+	var patched string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Chassis":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Node0"}]}`)
+		case r.URL.Path == "/redfish/v1/Chassis/Node0":
+			fmt.Fprint(w, chassis)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Chassis/Node0/EnvironmentMetrics":
+			w.Header().Set("ETag", `"m-1"`)
+			fmt.Fprint(w, metrics)
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/Chassis/Node0/Power":
+			w.Header().Set("ETag", `"p-1"`)
+			fmt.Fprint(w, power)
+		case r.Method == "PATCH":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			patched = r.URL.Path + " " + r.Header.Get("If-Match") + " " + string(b)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &patched
+}
+
+const (
+	chassisWithMetrics = `{"Id":"Node0","EnvironmentMetrics":{"@odata.id":"/redfish/v1/Chassis/Node0/EnvironmentMetrics"},"Power":{"@odata.id":"/redfish/v1/Chassis/Node0/Power"}}`
+	chassisWithPower   = `{"Id":"Node0","Power":{"@odata.id":"/redfish/v1/Chassis/Node0/Power"}}`
+)
+
+func TestSetPowerLimits(t *testing.T) {
+	w := 350.0
+	cases := []struct {
+		name, chassis, metrics, power string
+		watts                         *float64
+		wantWas, wantPatch            string
+	}{
+		{"environment metrics", chassisWithMetrics,
+			`{"PowerWatts":{"Reading":210},"PowerLimitWatts":{"SetPoint":500,"ControlMode":"Automatic","AllowableMin":100,"AllowableMax":600}}`, `{}`, &w,
+			"EnvironmentMetrics 500", `/redfish/v1/Chassis/Node0/EnvironmentMetrics "m-1" {"PowerLimitWatts":{"ControlMode":"Automatic","SetPoint":350}}`},
+		{"environment metrics clear", chassisWithMetrics,
+			`{"PowerLimitWatts":{"SetPoint":500,"ControlMode":"Disabled"}}`, `{}`, nil,
+			"EnvironmentMetrics none", `/redfish/v1/Chassis/Node0/EnvironmentMetrics "m-1" {"PowerLimitWatts":{"ControlMode":"Disabled"}}`},
+		{"power control", chassisWithMetrics, `{}`,
+			`{"PowerControl":[{"Name":"Total"},{"PowerConsumedWatts":180,"PowerLimit":{"LimitInWatts":null}}]}`, &w,
+			"PowerControl none", `/redfish/v1/Chassis/Node0/Power "p-1" {"PowerControl":[{},{"PowerLimit":{"LimitInWatts":350}}]}`},
+		{"power control clear", chassisWithPower, ``,
+			`{"PowerControl":[{"PowerLimit":{"LimitInWatts":400}}]}`, nil,
+			"PowerControl 400", `/redfish/v1/Chassis/Node0/Power "p-1" {"PowerControl":[{"PowerLimit":{"LimitInWatts":null}}]}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, patched := powerCapBMC(t, tc.chassis, tc.metrics, tc.power)
+			was, err := SetPowerLimits(context.Background(), host, "u", "p", true, 5*time.Second, tc.watts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(was) != 1 || was[0].Chassis != "Node0" {
+				t.Fatalf("limits = %+v, want one for Node0", was)
+			}
+			limit := "none"
+			if was[0].LimitWatts != nil {
+				limit = fmt.Sprintf("%g", *was[0].LimitWatts)
+			}
+			if got := was[0].Source + " " + limit; got != tc.wantWas {
+				t.Errorf("was = %q, want %q", got, tc.wantWas)
+			}
+			if *patched != tc.wantPatch {
+				t.Errorf("PATCH = %s\nwant    %s", *patched, tc.wantPatch)
+			}
+		})
+	}
+}
+
+func TestSetPowerLimitsOutOfRange(t *testing.T) {
+	host, patched := powerCapBMC(t, chassisWithMetrics,
+		`{"PowerLimitWatts":{"SetPoint":500,"ControlMode":"Automatic","AllowableMin":100,"AllowableMax":600}}`, `{}`)
+	w := 50.0
+	_, err := SetPowerLimits(context.Background(), host, "u", "p", true, 5*time.Second, &w)
+	if err == nil || !strings.Contains(err.Error(), "outside the allowed range 100-600 W") {
+		t.Fatalf("err = %v, want the range refused", err)
+	}
+	if *patched != "" {
+		t.Errorf("PATCH sent despite the range: %s", *patched)
+	}
+}
+
+func TestGetPowerLimitsOem(t *testing.T) {
+	host, _ := powerCapBMC(t, chassisWithPower, ``, `{"PowerControl":[{"Name":"Total"}],"Oem":{"Acme":{"CapWatts":275}}}`)
+	if _, err := GetPowerLimits(context.Background(), host, "u", "p", true, 5*time.Second); !errors.Is(err, ErrNoPowerLimit) {
+		t.Fatalf("err = %v, want ErrNoPowerLimit without a vendor hook", err)
+	}
+
+	saved := oemPowerLimits
+	t.Cleanup(func() { oemPowerLimits = saved })
+	oemPowerLimits = []powerLimitOem{{
+		vendor: "Acme",
+		read: func(raw json.RawMessage) (PowerLimit, bool) {
+			var oem struct {
+				Acme *struct {
+					CapWatts *float64 `json:"CapWatts"`
+				} `json:"Acme"`
+			}
+			if json.Unmarshal(raw, &oem) != nil || oem.Acme == nil {
+				return PowerLimit{}, false
+			}
+			return PowerLimit{LimitWatts: oem.Acme.CapWatts}, true
+		},
+		body: func(watts *float64) map[string]any {
+			return map[string]any{"Oem": map[string]any{"Acme": map[string]any{"CapWatts": watts}}}
+		},
+	}}
+	limits, err := GetPowerLimits(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limits) != 1 || limits[0].Source != "Acme" || limits[0].LimitWatts == nil || *limits[0].LimitWatts != 275 {
+		t.Errorf("limits = %+v, want the Acme limit of 275 W", limits)
+	}
+}
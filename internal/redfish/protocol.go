@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type rfSimpleUpdateInfo struct {
+	AllowableValues []string `json:"TransferProtocol@Redfish.AllowableValues"`
+	Actions         struct {
+		SimpleUpdate struct {
+			AllowableValues []string           `json:"TransferProtocol@Redfish.AllowableValues"`
+			ActionInfo      string             `json:"@Redfish.ActionInfo"`
+			ApplyTimes      rfApplyTimeSupport `json:"@Redfish.OperationApplyTimeSupport"`
+		} `json:"#UpdateService.SimpleUpdate"`
+	} `json:"Actions"`
+	MultipartApplyTimes rfApplyTimeSupport `json:"MultipartHttpPushUri@Redfish.OperationApplyTimeSupport"`
+}
+
+// rfApplyTimeSupport is a @Redfish.OperationApplyTimeSupport annotation.
+type rfApplyTimeSupport struct {
+	SupportedValues []string `json:"SupportedValues"`
+}
+
+type rfActionInfo struct {
+	Parameters []struct {
+		Name            string   `json:"Name"`
+		AllowableValues []string `json:"AllowableValues"`
+	} `json:"Parameters"`
+}
+
+// UpdateCapabilities is what the UpdateService of a BMC advertises: the
+// TransferProtocol values SimpleUpdate accepts, and the
+// @Redfish.OperationApplyTime values of SimpleUpdate and of a multipart
+// push. A nil slice means the service does not advertise them.
+type UpdateCapabilities struct {
+	TransferProtocols      []string
+	SimpleUpdateApplyTimes []string
+	MultipartApplyTimes    []string
+}
+
+// GetUpdateCapabilities reads the UpdateService of a BMC and, when the
+// SimpleUpdate action names one, its ActionInfo. TransferProtocol values
+// come from the action, its ActionInfo, or the service itself, in that
+// order; SimpleUpdate apply times from the action's
+// OperationApplyTimeSupport or its ActionInfo.
+func GetUpdateCapabilities(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) (UpdateCapabilities, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	var rf rfSimpleUpdateInfo
+	if err := c.get(ctx, "/UpdateService", &rf); err != nil {
+		return UpdateCapabilities{}, err
+	}
+	action := rf.Actions.SimpleUpdate
+	caps := UpdateCapabilities{
+		TransferProtocols:      action.AllowableValues,
+		SimpleUpdateApplyTimes: action.ApplyTimes.SupportedValues,
+		MultipartApplyTimes:    rf.MultipartApplyTimes.SupportedValues,
+	}
+	if action.ActionInfo != "" && (len(caps.TransferProtocols) == 0 || len(caps.SimpleUpdateApplyTimes) == 0) {
+		var info rfActionInfo
+		if err := c.get(ctx, action.ActionInfo, &info); err != nil {
+			return caps, fmt.Errorf("SimpleUpdate ActionInfo: %w", err)
+		}
+		for _, p := range info.Parameters {
+			switch {
+			case len(p.AllowableValues) == 0:
+			case p.Name == "TransferProtocol" && len(caps.TransferProtocols) == 0:
+				caps.TransferProtocols = p.AllowableValues
+			case p.Name == "@Redfish.OperationApplyTime" && len(caps.SimpleUpdateApplyTimes) == 0:
+				caps.SimpleUpdateApplyTimes = p.AllowableValues
+			}
+		}
+	}
+	if len(caps.TransferProtocols) == 0 {
+		caps.TransferProtocols = rf.AllowableValues
+	}
+	return caps, nil
+}
+
+// GetTransferProtocols returns the TransferProtocol values the UpdateService
+// of a BMC accepts for SimpleUpdate (see GetUpdateCapabilities). It returns
+// nil when the service does not advertise them.
+func GetTransferProtocols(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]string, error) {
+	caps, err := GetUpdateCapabilities(ctx, host, user, pass, insecure, timeout)
+	return caps.TransferProtocols, err
+}
+
+// Apply times for a firmware update, sent as @Redfish.OperationApplyTime.
+const (
+	ApplyTimeImmediate                = "Immediate"
+	ApplyTimeOnReset                  = "OnReset"
+	ApplyTimeAtMaintenanceWindowStart = "AtMaintenanceWindowStart"
+)
+
+// ParseApplyTime returns the canonical spelling of an apply time, matched
+// case-insensitively. An empty s stays empty: no apply time is requested.
+func ParseApplyTime(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, v := range []string{ApplyTimeImmediate, ApplyTimeOnReset, ApplyTimeAtMaintenanceWindowStart} {
+		if strings.EqualFold(s, v) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unknown apply time %q (want %s, %s, or %s)", s, ApplyTimeImmediate, ApplyTimeOnReset, ApplyTimeAtMaintenanceWindowStart)
+}
+
+// SelectApplyTime returns the apply time to send given the values the BMC
+// supports. requested must be among them. A BMC that advertises none may
+// flash at once whatever it is sent, so only Immediate is accepted, and is
+// returned as "" so that nothing is sent.
+func SelectApplyTime(supported []string, requested string) (string, error) {
+	if requested == "" {
+		return "", nil
+	}
+	if len(supported) == 0 {
+		if requested == ApplyTimeImmediate {
+			return "", nil
+		}
+		return "", fmt.Errorf("apply time %s is not supported (BMC advertises no OperationApplyTimeSupport)", requested)
+	}
+	for _, v := range supported {
+		if strings.EqualFold(v, requested) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("apply time %s is not supported (BMC allows %s)", requested, strings.Join(supported, ", "))
+}
+
+// SelectTransferProtocol returns the TransferProtocol to send with
+// SimpleUpdate given the allowed values of the BMC. Without auto it is
+// requested, which must be allowed; with auto it is the allowed protocol
+// matching the scheme of imageURI. A BMC that advertises no values accepts
+// any protocol, so requested (or, with auto, the scheme) is returned as is.
+func SelectTransferProtocol(allowed []string, requested, imageURI string, auto bool) (string, error) {
+	want := requested
+	if auto {
+		u, err := url.Parse(imageURI)
+		if err != nil || u.Scheme == "" {
+			return "", fmt.Errorf("cannot pick a TransferProtocol: image URI %q has no scheme", imageURI)
+		}
+		want = strings.ToUpper(u.Scheme)
+	}
+	if len(allowed) == 0 {
+		return want, nil
+	}
+	for _, p := range allowed {
+		if strings.EqualFold(p, want) {
+			return p, nil
+		}
+	}
+	if auto {
+		return "", fmt.Errorf("no TransferProtocol matches image URI scheme %s (BMC allows %s)", want, strings.Join(allowed, ", "))
+	}
+	return "", fmt.Errorf("TransferProtocol %s is not supported (BMC allows %s; use --protocol or --auto-protocol)", want, strings.Join(allowed, ", "))
+}
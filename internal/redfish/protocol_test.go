@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetTransferProtocols(t *testing.T) {
+	cases := []struct {
+		name, service string
+		want          []string
+	}{
+		{"action", `{"Actions":{"#UpdateService.SimpleUpdate":{"TransferProtocol@Redfish.AllowableValues":["HTTPS"]}},"TransferProtocol@Redfish.AllowableValues":["HTTP"]}`, []string{"HTTPS"}},
+		{"action info", `{"Actions":{"#UpdateService.SimpleUpdate":{"@Redfish.ActionInfo":"/redfish/v1/UpdateService/SimpleUpdateActionInfo"}}}`, []string{"TFTP", "HTTP"}},
+		{"service", `{"TransferProtocol@Redfish.AllowableValues":["HTTP","HTTPS"]}`, []string{"HTTP", "HTTPS"}},
+		{"not advertised", `{"Actions":{"#UpdateService.SimpleUpdate":{"target":"/x"}}}`, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/redfish/v1/UpdateService":
+					fmt.Fprint(w, tc.service)
+				case "/redfish/v1/UpdateService/SimpleUpdateActionInfo":
+					fmt.Fprint(w, `{"Parameters":[{"Name":"ImageURI"},{"Name":"TransferProtocol","AllowableValues":["TFTP","HTTP"]}]}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+			got, err := GetTransferProtocols(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("protocols = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectTransferProtocol(t *testing.T) {
+	cases := []struct {
+		name           string
+		allowed        []string
+		requested, uri string
+		auto           bool
+		want, wantErr  string
+	}{
+		{"allowed", []string{"HTTP", "HTTPS"}, "http", "http://s/fw.bin", false, "HTTP", ""},
+		{"not advertised", nil, "HTTP", "https://s/fw.bin", false, "HTTP", ""},
+		{"not allowed", []string{"HTTPS", "TFTP"}, "HTTP", "http://s/fw.bin", false, "", "TransferProtocol HTTP is not supported (BMC allows HTTPS, TFTP"},
+		{"auto", []string{"HTTP", "HTTPS"}, "HTTP", "https://s/fw.bin", true, "HTTPS", ""},
+		{"auto tftp", []string{"TFTP"}, "HTTP", "tftp://s/fw.bin", true, "TFTP", ""},
+		{"auto not allowed", []string{"HTTP"}, "HTTP", "https://s/fw.bin", true, "", "no TransferProtocol matches image URI scheme HTTPS"},
+		{"auto without scheme", []string{"HTTP"}, "HTTP", "s/fw.bin", true, "", "has no scheme"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SelectTransferProtocol(tc.allowed, tc.requested, tc.uri, tc.auto)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("SelectTransferProtocol = %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetUpdateCapabilitiesApplyTimes(t *testing.T) {
+	cases := []struct {
+		name, service     string
+		simple, multipart []string
+	}{
+		{"action", `{"Actions":{"#UpdateService.SimpleUpdate":{"@Redfish.OperationApplyTimeSupport":{"SupportedValues":["Immediate","OnReset"]}}},` +
+			`"MultipartHttpPushUri@Redfish.OperationApplyTimeSupport":{"SupportedValues":["OnReset"]}}`, []string{"Immediate", "OnReset"}, []string{"OnReset"}},
+		{"action info", `{"Actions":{"#UpdateService.SimpleUpdate":{"@Redfish.ActionInfo":"/redfish/v1/UpdateService/SimpleUpdateActionInfo"}}}`,
+			[]string{"Immediate", "AtMaintenanceWindowStart"}, nil},
+		{"not advertised", `{"Actions":{"#UpdateService.SimpleUpdate":{"target":"/x"}}}`, nil, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/redfish/v1/UpdateService":
+					fmt.Fprint(w, tc.service)
+				case "/redfish/v1/UpdateService/SimpleUpdateActionInfo":
+					fmt.Fprint(w, `{"Parameters":[{"Name":"@Redfish.OperationApplyTime","AllowableValues":["Immediate","AtMaintenanceWindowStart"]}]}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+			caps, err := GetUpdateCapabilities(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(caps.SimpleUpdateApplyTimes, tc.simple) || !slices.Equal(caps.MultipartApplyTimes, tc.multipart) {
+				t.Errorf("apply times = %v / %v, want %v / %v", caps.SimpleUpdateApplyTimes, caps.MultipartApplyTimes, tc.simple, tc.multipart)
+			}
+		})
+	}
+}
+
+func TestSelectApplyTime(t *testing.T) {
+	cases := []struct {
+		name          string
+		supported     []string
+		requested     string
+		want, wantErr string
+	}{
+		{"none requested", []string{"OnReset"}, "", "", ""},
+		{"supported", []string{"Immediate", "OnReset"}, ApplyTimeOnReset, "OnReset", ""},
+		{"not supported", []string{"Immediate"}, ApplyTimeOnReset, "", "apply time OnReset is not supported (BMC allows Immediate)"},
+		{"not advertised", nil, ApplyTimeOnReset, "", "advertises no OperationApplyTimeSupport"},
+		{"immediate not advertised", nil, ApplyTimeImmediate, "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SelectApplyTime(tc.supported, tc.requested)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("SelectApplyTime = %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+	if v, err := ParseApplyTime("onreset"); v != ApplyTimeOnReset || err != nil {
+		t.Errorf("ParseApplyTime(onreset) = %q, %v", v, err)
+	}
+	if _, err := ParseApplyTime("later"); err == nil {
+		t.Error("ParseApplyTime(later) succeeded")
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// proxy picks the proxy of every Redfish transport; set by ConfigureProxy.
+// By default HTTPS_PROXY, HTTP_PROXY, and NO_PROXY are honored.
+var proxy = http.ProxyFromEnvironment
+
+// ConfigureProxy sends all subsequent Redfish connections through the proxy
+// at rawURL, an http://, https://, socks5://, or socks5h:// URL. An empty
+// rawURL restores the proxy environment variables.
+func ConfigureProxy(rawURL string) error {
+	if rawURL == "" {
+		proxy = http.ProxyFromEnvironment
+		CloseIdleConnections()
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL %q: %w", rawURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q in %q: use http, https, socks5, or socks5h", u.Scheme, rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy URL %q has no host", rawURL)
+	}
+	proxy = http.ProxyURL(u)
+	// Pooled transports hold the previous proxy
+	CloseIdleConnections()
+	return nil
+}
+
+// proxied reports whether connections to host go through a proxy.
+func proxied(host string) bool {
+	u, err := proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: host}})
+	return err == nil && u != nil
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connectProxy returns an HTTP proxy that tunnels every CONNECT to backend,
+// whatever host was asked for, and the hosts it was asked for.
+func connectProxy(t *testing.T, backend string) (string, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var asked []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		asked = append(asked, r.Host)
+		mu.Unlock()
+		upstream, err := net.Dial("tcp", backend)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close() // nolint:errcheck
+			return
+		}
+		go func() {
+			_, _ = io.Copy(upstream, rw)
+			upstream.Close() // nolint:errcheck
+		}()
+		_, _ = io.Copy(conn, upstream)
+		conn.Close() // nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), asked...)
+	}
+}
+
+func TestConfigureProxy(t *testing.T) {
+	bmc, _ := handshakeServer(t)
+	proxyURL, asked := connectProxy(t, bmc)
+	if err := ConfigureProxy(proxyURL); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ConfigureProxy("") })
+
+	// Only reachable through the proxy
+	const host = "bmc.invalid:443"
+	if err := ProbeServiceRoot(context.Background(), host, "u", "p", true, 5*time.Second); err != nil {
+		t.Fatalf("probe through proxy: %v", err)
+	}
+	if res := CheckHost(context.Background(), host, "u", "p", true, 5*time.Second, false); res.Status != CheckOK {
+		t.Errorf("check through proxy: %s %v", res.Status, res.Err)
+	}
+	if got := asked(); len(got) == 0 || got[0] != host {
+		t.Errorf("proxy asked for %v, want %s", got, host)
+	}
+
+	for _, bad := range []string{"ftp://proxy:21", "proxy:3128", "socks5://"} {
+		if err := ConfigureProxy(bad); err == nil {
+			t.Errorf("ConfigureProxy(%q) should fail", bad)
+		} else if !strings.Contains(err.Error(), "proxy") {
+			t.Errorf("ConfigureProxy(%q): unhelpful error %v", bad, err)
+		}
+	}
+}
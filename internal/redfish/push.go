@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FirmwareImage is a local firmware image shared by concurrent pushes. Each
+// push streams the file through its own section reader, so the image is
+// opened once and never buffered in memory.
+type FirmwareImage struct {
+	name string
+	f    *os.File
+	size int64
+}
+
+// OpenFirmwareImage opens the image at path for MultipartUpdate.
+func OpenFirmwareImage(path string) (*FirmwareImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close() // nolint:errcheck
+		return nil, err
+	}
+	if !st.Mode().IsRegular() {
+		f.Close() // nolint:errcheck
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	return &FirmwareImage{name: filepath.Base(path), f: f, size: st.Size()}, nil
+}
+
+// Name returns the image's file name.
+func (img *FirmwareImage) Name() string { return img.name }
+
+// Size returns the image size in bytes.
+func (img *FirmwareImage) Size() int64 { return img.size }
+
+// Close closes the underlying file.
+func (img *FirmwareImage) Close() error { return img.f.Close() }
+
+// MultipartUpdate pushes img to the UpdateService's MultipartHttpPushUri as a
+// multipart/form-data POST with an UpdateParameters part carrying targets and
+// an UpdateFile part carrying the image bytes. applyTime, expectedVersion,
+// and force behave as in SimpleUpdate.
+func MultipartUpdate(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, img *FirmwareImage, targets []string, applyTime, expectedVersion string, force bool) error {
+	c := newClient(host, user, pass, insecure, timeout)
+
+	if err := c.checkExpectedVersion(ctx, targets, expectedVersion, force); err != nil {
+		return err
+	}
+
+	var us rfUpdateService
+	if err := c.get(ctx, "/UpdateService", &us); err != nil {
+		return err
+	}
+	if us.MultipartHTTPPushURI == "" {
+		return fmt.Errorf("UpdateService does not advertise MultipartHttpPushUri; use --image-uri for SimpleUpdate")
+	}
+
+	body, contentType, length, err := multipartBody(img, targets, applyTime)
+	if err != nil {
+		return err
+	}
+	path := c.resolvePath(us.MultipartHTTPPushURI)
+	req, err := http.NewRequestWithContext(ctx, "POST", path, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = length
+	req.SetBasicAuth(c.user, c.pass)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode >= 300 {
+		return newStatusError(resp, path)
+	}
+
+	return c.checkUpdateConditions(ctx, targets)
+}
+
+// multipartBody returns a reader for the multipart request body, its content
+// type, and its exact length. Only the part headers are held in memory; the
+// image is read from disk as the request is sent.
+func multipartBody(img *FirmwareImage, targets []string, applyTime string) (io.Reader, string, int64, error) {
+	parameters := map[string]any{"Targets": targets}
+	if applyTime != "" {
+		parameters["@Redfish.OperationApplyTime"] = applyTime
+	}
+	params, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	var head bytes.Buffer
+	mw := multipart.NewWriter(&head)
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="UpdateParameters"`)
+	h.Set("Content-Type", "application/json")
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if _, err := pw.Write(params); err != nil {
+		return nil, "", 0, err
+	}
+	h = textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="UpdateFile"; filename=%q`, img.name))
+	h.Set("Content-Type", "application/octet-stream")
+	if _, err := mw.CreatePart(h); err != nil {
+		return nil, "", 0, err
+	}
+	prefix := append([]byte(nil), head.Bytes()...)
+
+	head.Reset()
+	if err := mw.Close(); err != nil {
+		return nil, "", 0, err
+	}
+	suffix := head.Bytes()
+
+	r := io.MultiReader(bytes.NewReader(prefix), io.NewSectionReader(img.f, 0, img.size), bytes.NewReader(suffix))
+	return r, mw.FormDataContentType(), int64(len(prefix)) + img.size + int64(len(suffix)), nil
+}
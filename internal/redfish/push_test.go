@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMultipartUpdate(t *testing.T) {
+	image := bytes.Repeat([]byte("firmware"), 64*1024)
+	imgPath := filepath.Join(t.TempDir(), "bmc.fwpkg")
+	if err := os.WriteFile(imgPath, image, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	img, err := OpenFirmwareImage(imgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer img.Close() // nolint:errcheck
+
+	var gotTargets []string
+	var gotApplyTime string
+	var gotFile []byte
+	var gotName string
+	var gotLength int64
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/redfish/v1/UpdateService":
+			_, _ = w.Write([]byte(`{"MultipartHttpPushUri":"/redfish/v1/UpdateService/update-multipart"}`))
+		case r.Method == "POST" && r.URL.Path == "/redfish/v1/UpdateService/update-multipart":
+			gotLength = r.ContentLength
+			mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mt != "multipart/form-data" {
+				t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mr := multipart.NewReader(r.Body, params["boundary"])
+			for {
+				part, err := mr.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Errorf("next part: %v", err)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				switch part.FormName() {
+				case "UpdateParameters":
+					var p struct {
+						Targets   []string
+						ApplyTime string `json:"@Redfish.OperationApplyTime"`
+					}
+					if err := json.NewDecoder(part).Decode(&p); err != nil {
+						t.Errorf("decode UpdateParameters: %v", err)
+					}
+					gotTargets, gotApplyTime = p.Targets, p.ApplyTime
+				case "UpdateFile":
+					gotName = part.FileName()
+					gotFile, _ = io.ReadAll(part)
+				}
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	targets := []string{"/redfish/v1/UpdateService/FirmwareInventory/BMC"}
+	host := strings.TrimPrefix(srv.URL, "https://")
+	if err := MultipartUpdate(context.Background(), host, "u", "p", true, 10*time.Second, img, targets, "", "", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotTargets) != 1 || gotTargets[0] != targets[0] || gotApplyTime != "" {
+		t.Errorf("Targets = %v, apply time %q; want %v and none", gotTargets, gotApplyTime, targets)
+	}
+	if gotName != "bmc.fwpkg" || !bytes.Equal(gotFile, image) {
+		t.Errorf("UpdateFile %q: got %d bytes, want %d", gotName, len(gotFile), len(image))
+	}
+	if gotLength <= int64(len(image)) {
+		t.Errorf("Content-Length = %d, want the exact multipart length", gotLength)
+	}
+
+	// The same image can be pushed again from the start, here staged
+	gotFile = nil
+	if err := MultipartUpdate(context.Background(), host, "u", "p", true, 10*time.Second, img, targets, ApplyTimeOnReset, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if gotApplyTime != ApplyTimeOnReset {
+		t.Errorf("apply time = %q, want OnReset", gotApplyTime)
+	}
+	if !bytes.Equal(gotFile, image) {
+		t.Errorf("second push sent %d bytes, want %d", len(gotFile), len(image))
+	}
+}
+
+func TestMultipartUpdateWithoutPushURI(t *testing.T) {
+	imgPath := filepath.Join(t.TempDir(), "bmc.fwpkg")
+	if err := os.WriteFile(imgPath, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	img, err := OpenFirmwareImage(imgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer img.Close() // nolint:errcheck
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	err = MultipartUpdate(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 10*time.Second, img, nil, "", "", false)
+	if err == nil || !strings.Contains(err.Error(), "MultipartHttpPushUri") {
+		t.Fatalf("expected missing MultipartHttpPushUri error, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrNoSEL is returned when neither the Systems nor the Managers of a BMC
+// have a SEL log service.
+var ErrNoSEL = errors.New("no SEL log service")
+
+// LogEntry is a simplified Redfish LogEntry.
+type LogEntry struct {
+	OID       string `json:"@odata.id"`
+	ID        string `json:"Id"`
+	Created   string `json:"Created"`
+	Severity  string `json:"Severity"`
+	Message   string `json:"Message"`
+	MessageID string `json:"MessageId"`
+}
+
+// SEL is the system event log of one System or Manager. Path is the
+// LogService URI.
+type SEL struct {
+	Path    string
+	Entries []LogEntry
+}
+
+type rfLogService struct {
+	Entries struct {
+		OID string `json:"@odata.id"`
+	} `json:"Entries"`
+	Actions struct {
+		ClearLog struct {
+			Target string `json:"target"`
+		} `json:"#LogService.ClearLog"`
+	} `json:"Actions"`
+}
+
+type selService struct {
+	path string
+	svc  rfLogService
+}
+
+// selServices returns the SEL log services of every System, or, when no
+// System has one, of every Manager.
+func (c *client) selServices(ctx context.Context) ([]selService, error) {
+	for _, collection := range []string{"/Systems", "/Managers"} {
+		members, err := c.listMembers(ctx, collection)
+		if isStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var out []selService
+		for _, m := range members {
+			path := m + "/LogServices/SEL"
+			var svc rfLogService
+			err := c.get(ctx, path, &svc)
+			if isStatus(err, http.StatusNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, selService{path: path, svc: svc})
+		}
+		if len(out) > 0 {
+			return out, nil
+		}
+	}
+	return nil, ErrNoSEL
+}
+
+// logEntryPage is one page of a LogEntry collection. BMCs usually embed the
+// entries; some only link them.
+type logEntryPage struct {
+	Members        []LogEntry `json:"Members"`
+	NextLink       string     `json:"Members@odata.nextLink"`
+	LegacyNextLink string     `json:"@odata.nextLink"`
+}
+
+// listLogEntries reads every page of the entry collection at path, fetching
+// entries that are only linked.
+func (c *client) listLogEntries(ctx context.Context, path string) ([]LogEntry, error) {
+	var out []LogEntry
+	seen := map[string]bool{}
+	for page := 0; path != ""; page++ {
+		if page == maxCollectionPages || seen[path] {
+			return nil, fmt.Errorf("redfish %s: collection paging did not terminate after %d page(s)", path, page)
+		}
+		seen[path] = true
+		var p logEntryPage
+		if err := c.get(ctx, path, &p); err != nil {
+			return nil, err
+		}
+		for _, e := range p.Members {
+			if e.ID == "" && e.Message == "" && e.OID != "" {
+				if err := c.get(ctx, e.OID, &e); err != nil {
+					return nil, err
+				}
+			}
+			out = append(out, e)
+		}
+		path = p.NextLink
+		if path == "" {
+			path = p.LegacyNextLink
+		}
+	}
+	return out, nil
+}
+
+// ListSEL returns the SEL entries of every System on host, falling back to
+// the Managers' SELs when no System has one.
+func ListSEL(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]SEL, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	services, err := c.selServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SEL, 0, len(services))
+	for _, s := range services {
+		entries := s.svc.Entries.OID
+		if entries == "" {
+			entries = s.path + "/Entries"
+		}
+		list, err := c.listLogEntries(ctx, entries)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, SEL{Path: s.path, Entries: list})
+	}
+	return out, nil
+}
+
+// ClearSEL posts LogService.ClearLog to every SEL that ListSEL would read
+// and returns the paths of the cleared log services.
+func ClearSEL(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]string, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	services, err := c.selServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cleared []string
+	for _, s := range services {
+		target := s.svc.Actions.ClearLog.Target
+		if target == "" {
+			target = s.path + "/Actions/LogService.ClearLog"
+		}
+		if err := c.post(ctx, target, map[string]any{}); err != nil {
+			return cleared, err
+		}
+		cleared = append(cleared, s.path)
+	}
+	return cleared, nil
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListSELFollowsPagesAndLinks(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/redfish/v1/Systems?":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case "/redfish/v1/Systems/Node0/LogServices/SEL?":
+			fmt.Fprint(w, `{"Id":"SEL","Entries":{"@odata.id":"/redfish/v1/Systems/Node0/LogServices/SEL/Entries"}}`)
+		case "/redfish/v1/Systems/Node0/LogServices/SEL/Entries?":
+			fmt.Fprint(w, `{"Members":[{"Id":"1","Created":"2025-06-01T10:00:00Z","Severity":"OK","Message":"boot"}],
+				"Members@odata.nextLink":"/redfish/v1/Systems/Node0/LogServices/SEL/Entries?$skip=1"}`)
+		case "/redfish/v1/Systems/Node0/LogServices/SEL/Entries?$skip=1":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0/LogServices/SEL/Entries/2"}]}`)
+		case "/redfish/v1/Systems/Node0/LogServices/SEL/Entries/2?":
+			fmt.Fprint(w, `{"Id":"2","Created":"2025-06-01T10:05:00Z","Severity":"Critical","Message":"DIMM error"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	logs, err := ListSEL(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].Path != "/redfish/v1/Systems/Node0/LogServices/SEL" {
+		t.Fatalf("logs = %+v", logs)
+	}
+	e := logs[0].Entries
+	if len(e) != 2 || e[0].Message != "boot" || e[1].ID != "2" || e[1].Severity != "Critical" {
+		t.Errorf("entries = %+v", e)
+	}
+}
+
+func TestSELFallsBackToManagers(t *testing.T) {
+	var mu sync.Mutex
+	var cleared []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Systems/Node0"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`)
+		case r.URL.Path == "/redfish/v1/Managers/BMC/LogServices/SEL":
+			fmt.Fprint(w, `{"Id":"SEL","Actions":{"#LogService.ClearLog":{"target":"/redfish/v1/Managers/BMC/LogServices/SEL/Actions/LogService.ClearLog"}}}`)
+		case r.URL.Path == "/redfish/v1/Managers/BMC/LogServices/SEL/Entries":
+			fmt.Fprint(w, `{"Members":[{"Id":"1","Message":"fan ok"}]}`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/Actions/LogService.ClearLog"):
+			mu.Lock()
+			cleared = append(cleared, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	logs, err := ListSEL(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].Path != "/redfish/v1/Managers/BMC/LogServices/SEL" || len(logs[0].Entries) != 1 {
+		t.Fatalf("logs = %+v", logs)
+	}
+	paths, err := ClearSEL(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("ClearSEL = %v, %v", paths, err)
+	}
+	if len(cleared) != 1 || cleared[0] != "/redfish/v1/Managers/BMC/LogServices/SEL/Actions/LogService.ClearLog" {
+		t.Errorf("cleared = %v", cleared)
+	}
+
+	empty := httptest.NewTLSServer(http.NotFoundHandler())
+	defer empty.Close()
+	if _, err := ListSEL(context.Background(), strings.TrimPrefix(empty.URL, "https://"), "u", "p", true, 5*time.Second); !errors.Is(err, ErrNoSEL) {
+		t.Errorf("err = %v, want ErrNoSEL", err)
+	}
+}
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"path"
+	"time"
+)
+
+// Sensor is one thermal or power reading of a chassis. Reading is nil when
+// the BMC reports none (e.g. an absent PSU).
+type Sensor struct {
+	Chassis string // Id of the chassis, e.g. "Enclosure" or "Blade0"
+	Type    string // Temperature, Fan, Voltage, Power, PowerSupply, or a Sensor ReadingType
+	Name    string
+	Reading *float64
+	Units   string
+	Health  string // OK, Warning, Critical, or empty when not reported
+	State   string // Enabled, Absent, ...
+}
+
+// Fault reports whether the sensor's health is reported and not OK.
+func (s Sensor) Fault() bool {
+	return s.Health != "" && s.Health != "OK"
+}
+
+type rfStatus struct {
+	Health string `json:"Health"`
+	State  string `json:"State"`
+}
+
+type rfLink struct {
+	OID string `json:"@odata.id"`
+}
+
+type rfChassis struct {
+	ID               string `json:"Id"`
+	Thermal          rfLink `json:"Thermal"`
+	Power            rfLink `json:"Power"`
+	ThermalSubsystem rfLink `json:"ThermalSubsystem"`
+	PowerSubsystem   rfLink `json:"PowerSubsystem"`
+	Sensors          rfLink `json:"Sensors"`
+}
+
+// rfThermal and rfPower are the deprecated Thermal and Power resources,
+// which embed their readings.
+type rfThermal struct {
+	Temperatures []struct {
+		Name           string   `json:"Name"`
+		ReadingCelsius *float64 `json:"ReadingCelsius"`
+		Status         rfStatus `json:"Status"`
+	} `json:"Temperatures"`
+	Fans []struct {
+		Name         string   `json:"Name"`
+		FanName      string   `json:"FanName"`
+		Reading      *float64 `json:"Reading"`
+		ReadingUnits string   `json:"ReadingUnits"`
+		Status       rfStatus `json:"Status"`
+	} `json:"Fans"`
+}
+
+type rfPower struct {
+	PowerControl []struct {
+		Name               string   `json:"Name"`
+		PowerConsumedWatts *float64 `json:"PowerConsumedWatts"`
+		Status             rfStatus `json:"Status"`
+	} `json:"PowerControl"`
+	PowerSupplies []struct {
+		Name                 string   `json:"Name"`
+		LastPowerOutputWatts *float64 `json:"LastPowerOutputWatts"`
+		Status               rfStatus `json:"Status"`
+	} `json:"PowerSupplies"`
+	Voltages []struct {
+		Name         string   `json:"Name"`
+		ReadingVolts *float64 `json:"ReadingVolts"`
+		Status       rfStatus `json:"Status"`
+	} `json:"Voltages"`
+}
+
+// rfSensor is a member of the Sensors collection that ThermalSubsystem and
+// PowerSubsystem report their readings through.
+type rfSensor struct {
+	Name         string   `json:"Name"`
+	ReadingType  string   `json:"ReadingType"`
+	Reading      *float64 `json:"Reading"`
+	ReadingUnits string   `json:"ReadingUnits"`
+	Status       rfStatus `json:"Status"`
+}
+
+type rfPowerSupply struct {
+	Name   string   `json:"Name"`
+	Status rfStatus `json:"Status"`
+}
+
+// GetSensors returns the thermal and power sensors of every chassis on a
+// BMC, in collection order. A chassis with a ThermalSubsystem or
+// PowerSubsystem is read through its Sensors collection and PowerSupplies;
+// otherwise the deprecated Thermal and Power resources are used.
+func GetSensors(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]Sensor, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	members, err := c.listMembers(ctx, "/Chassis")
+	if err != nil {
+		return nil, err
+	}
+	chassis, err := fetchAll[rfChassis](ctx, c, members)
+	if err != nil {
+		return nil, err
+	}
+	var out []Sensor
+	for i, ch := range chassis {
+		id := ch.ID
+		if id == "" {
+			id = path.Base(members[i])
+		}
+		var sensors []Sensor
+		if ch.Sensors.OID != "" && (ch.ThermalSubsystem.OID != "" || ch.PowerSubsystem.OID != "") {
+			sensors, err = c.subsystemSensors(ctx, id, ch)
+		} else {
+			sensors, err = c.legacySensors(ctx, id, ch)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sensors...)
+	}
+	return out, nil
+}
+
+func (c *client) subsystemSensors(ctx context.Context, chassis string, ch rfChassis) ([]Sensor, error) {
+	members, err := c.listMembers(ctx, ch.Sensors.OID)
+	if err != nil {
+		return nil, err
+	}
+	readings, err := fetchAll[rfSensor](ctx, c, members)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Sensor, 0, len(readings))
+	for _, r := range readings {
+		out = append(out, Sensor{Chassis: chassis, Type: r.ReadingType, Name: r.Name, Reading: r.Reading,
+			Units: r.ReadingUnits, Health: r.Status.Health, State: r.Status.State})
+	}
+	if ch.PowerSubsystem.OID == "" {
+		return out, nil
+	}
+	// PSU health is not a reading, so it is not in the Sensors collection
+	psus, err := c.listMembers(ctx, ch.PowerSubsystem.OID+"/PowerSupplies")
+	if isStatus(err, 404) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	supplies, err := fetchAll[rfPowerSupply](ctx, c, psus)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range supplies {
+		out = append(out, Sensor{Chassis: chassis, Type: "PowerSupply", Name: p.Name, Health: p.Status.Health, State: p.Status.State})
+	}
+	return out, nil
+}
+
+func (c *client) legacySensors(ctx context.Context, chassis string, ch rfChassis) ([]Sensor, error) {
+	var out []Sensor
+	if ch.Thermal.OID != "" {
+		var th rfThermal
+		if err := c.get(ctx, ch.Thermal.OID, &th); err != nil {
+			return nil, err
+		}
+		for _, t := range th.Temperatures {
+			out = append(out, Sensor{Chassis: chassis, Type: "Temperature", Name: t.Name, Reading: t.ReadingCelsius,
+				Units: "Cel", Health: t.Status.Health, State: t.Status.State})
+		}
+		for _, f := range th.Fans {
+			name := f.Name
+			if name == "" {
+				name = f.FanName
+			}
+			out = append(out, Sensor{Chassis: chassis, Type: "Fan", Name: name, Reading: f.Reading,
+				Units: f.ReadingUnits, Health: f.Status.Health, State: f.Status.State})
+		}
+	}
+	if ch.Power.OID != "" {
+		var pw rfPower
+		if err := c.get(ctx, ch.Power.OID, &pw); err != nil {
+			return nil, err
+		}
+		for _, p := range pw.PowerControl {
+			out = append(out, Sensor{Chassis: chassis, Type: "Power", Name: p.Name, Reading: p.PowerConsumedWatts,
+				Units: "W", Health: p.Status.Health, State: p.Status.State})
+		}
+		for _, p := range pw.PowerSupplies {
+			out = append(out, Sensor{Chassis: chassis, Type: "PowerSupply", Name: p.Name, Reading: p.LastPowerOutputWatts,
+				Units: "W", Health: p.Status.Health, State: p.Status.State})
+		}
+		for _, v := range pw.Voltages {
+			out = append(out, Sensor{Chassis: chassis, Type: "Voltage", Name: v.Name, Reading: v.ReadingVolts,
+				Units: "V", Health: v.Status.Health, State: v.Status.State})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetSensors(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/Chassis":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Enclosure"},{"@odata.id":"/redfish/v1/Chassis/Blade0"}]}`)
+		case "/redfish/v1/Chassis/Enclosure":
+			// Deprecated Thermal and Power resources
+			fmt.Fprint(w, `{"Id":"Enclosure","Thermal":{"@odata.id":"/redfish/v1/Chassis/Enclosure/Thermal"},
+				"Power":{"@odata.id":"/redfish/v1/Chassis/Enclosure/Power"}}`)
+		case "/redfish/v1/Chassis/Enclosure/Thermal":
+			fmt.Fprint(w, `{"Temperatures":[{"Name":"Inlet","ReadingCelsius":24,"Status":{"Health":"OK","State":"Enabled"}}],
+				"Fans":[{"FanName":"Fan1","Reading":0,"ReadingUnits":"RPM","Status":{"Health":"Critical","State":"Enabled"}}]}`)
+		case "/redfish/v1/Chassis/Enclosure/Power":
+			fmt.Fprint(w, `{"PowerSupplies":[{"Name":"PSU1","Status":{"State":"Absent"}}],
+				"Voltages":[{"Name":"12V","ReadingVolts":12.1,"Status":{"Health":"OK"}}]}`)
+		case "/redfish/v1/Chassis/Blade0":
+			fmt.Fprint(w, `{"Id":"Blade0","ThermalSubsystem":{"@odata.id":"/redfish/v1/Chassis/Blade0/ThermalSubsystem"},
+				"PowerSubsystem":{"@odata.id":"/redfish/v1/Chassis/Blade0/PowerSubsystem"},
+				"Sensors":{"@odata.id":"/redfish/v1/Chassis/Blade0/Sensors"}}`)
+		case "/redfish/v1/Chassis/Blade0/Sensors":
+			// Paged collection
+			if r.URL.Query().Get("$skip") == "" {
+				fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Blade0/Sensors/CPU0Temp"}],
+					"Members@odata.nextLink":"/redfish/v1/Chassis/Blade0/Sensors?$skip=1"}`)
+				return
+			}
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Blade0/Sensors/NodePower"}]}`)
+		case "/redfish/v1/Chassis/Blade0/Sensors/CPU0Temp":
+			fmt.Fprint(w, `{"Name":"CPU0 Temp","ReadingType":"Temperature","Reading":91,"ReadingUnits":"Cel","Status":{"Health":"Warning"}}`)
+		case "/redfish/v1/Chassis/Blade0/Sensors/NodePower":
+			fmt.Fprint(w, `{"Name":"Node Power","ReadingType":"Power","Reading":410.5,"ReadingUnits":"W","Status":{"Health":"OK"}}`)
+		case "/redfish/v1/Chassis/Blade0/PowerSubsystem/PowerSupplies":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/Chassis/Blade0/PowerSubsystem/PowerSupplies/0"}]}`)
+		case "/redfish/v1/Chassis/Blade0/PowerSubsystem/PowerSupplies/0":
+			fmt.Fprint(w, `{"Name":"Rectifier0","Status":{"Health":"OK","State":"Enabled"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	sensors, err := GetSensors(context.Background(), strings.TrimPrefix(srv.URL, "https://"), "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range sensors {
+		reading := "-"
+		if s.Reading != nil {
+			reading = fmt.Sprint(*s.Reading)
+		}
+		got = append(got, fmt.Sprintf("%s/%s/%s=%s%s:%s:%v", s.Chassis, s.Type, s.Name, reading, s.Units, s.Health, s.Fault()))
+	}
+	want := []string{
+		"Enclosure/Temperature/Inlet=24Cel:OK:false",
+		"Enclosure/Fan/Fan1=0RPM:Critical:true",
+		"Enclosure/PowerSupply/PSU1=-W::false",
+		"Enclosure/Voltage/12V=12.1V:OK:false",
+		"Blade0/Temperature/CPU0 Temp=91Cel:Warning:true",
+		"Blade0/Power/Node Power=410.5W:OK:false",
+		"Blade0/PowerSupply/Rectifier0=-:OK:false",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("sensors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
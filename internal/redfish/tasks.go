@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"bootstrap/internal/diag"
+)
+
+// ErrTaskServiceUnsupported is returned when a BMC has no TaskService.
+var ErrTaskServiceUnsupported = errors.New("TaskService not supported")
+
+const tasksPath = "/TaskService/Tasks"
+
+// TaskMessage is one entry of a task's Messages list.
+type TaskMessage struct {
+	MessageID string `json:"MessageId"`
+	Message   string `json:"Message"`
+	Severity  string `json:"Severity"`
+}
+
+// Task is a simplified Redfish Task. PercentComplete is nil when the BMC
+// does not report progress.
+type Task struct {
+	ID              string        `json:"Id"`
+	Name            string        `json:"Name"`
+	TaskState       string        `json:"TaskState"`
+	TaskStatus      string        `json:"TaskStatus"`
+	PercentComplete *int          `json:"PercentComplete"`
+	StartTime       string        `json:"StartTime"`
+	EndTime         string        `json:"EndTime"`
+	Messages        []TaskMessage `json:"Messages"`
+	Actions         struct {
+		Cancel struct {
+			Target string `json:"target"`
+		} `json:"#Task.Cancel"`
+	} `json:"Actions"`
+}
+
+// taskServiceMissing reports whether err means the BMC has no TaskService.
+func taskServiceMissing(err error) bool {
+	return isStatus(err, http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented)
+}
+
+// ListTasks returns every task in the BMC's TaskService. Tasks that vanish
+// while the collection is read are skipped.
+func ListTasks(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration) ([]Task, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	members, err := c.listMembers(ctx, tasksPath)
+	if taskServiceMissing(err) {
+		return nil, ErrTaskServiceUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Task, 0, len(members))
+	for _, m := range members {
+		var t Task
+		if err := c.get(ctx, m, &t); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			diag.Logf("%s: skip task %s: %v", host, m, err)
+			continue
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// GetTask returns the task with the given Id as the raw JSON document the
+// BMC served.
+func GetTask(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, id string) (json.RawMessage, error) {
+	c := newClient(host, user, pass, insecure, timeout)
+	var raw json.RawMessage
+	err := c.get(ctx, tasksPath+"/"+url.PathEscape(id), &raw)
+	if isStatus(err, http.StatusNotFound) {
+		// Tell a missing task apart from a missing TaskService
+		if _, lerr := c.listMembers(ctx, tasksPath); taskServiceMissing(lerr) {
+			return nil, ErrTaskServiceUnsupported
+		}
+	}
+	return raw, err
+}
+
+// CancelTask cancels the task with the given Id, using the Task.Cancel
+// action when the task advertises it and a DELETE on the task otherwise.
+func CancelTask(ctx context.Context, host, user, pass string, insecure bool, timeout time.Duration, id string) error {
+	c := newClient(host, user, pass, insecure, timeout)
+	path := tasksPath + "/" + url.PathEscape(id)
+	var t Task
+	if err := c.get(ctx, path, &t); err != nil {
+		if isStatus(err, http.StatusNotFound) {
+			if _, lerr := c.listMembers(ctx, tasksPath); taskServiceMissing(lerr) {
+				return ErrTaskServiceUnsupported
+			}
+		}
+		return err
+	}
+	if target := t.Actions.Cancel.Target; target != "" {
+		return c.post(ctx, target, map[string]any{})
+	}
+	return c.delete(ctx, path)
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockTaskService serves task 1 (with a Task.Cancel action) and task 2
+// (without), and records every mutating request as "METHOD path".
+func mockTaskService(t *testing.T) (string, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			mu.Lock()
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		switch r.URL.Path {
+		case "/redfish/v1/TaskService/Tasks":
+			fmt.Fprint(w, `{"Members":[{"@odata.id":"/redfish/v1/TaskService/Tasks/1"},{"@odata.id":"/redfish/v1/TaskService/Tasks/2"},{"@odata.id":"/redfish/v1/TaskService/Tasks/gone"}]}`)
+		case "/redfish/v1/TaskService/Tasks/1":
+			fmt.Fprint(w, `{"Id":"1","Name":"Firmware Update","TaskState":"Running","PercentComplete":40,
+				"Messages":[{"MessageId":"Update.1.0.TransferringToComponent","Message":"Transferring"}],
+				"Actions":{"#Task.Cancel":{"target":"/redfish/v1/TaskService/Tasks/1/Actions/Task.Cancel"}}}`)
+		case "/redfish/v1/TaskService/Tasks/2":
+			fmt.Fprint(w, `{"Id":"2","Name":"Export","TaskState":"Completed"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), calls...)
+	}
+}
+
+func TestListTasks(t *testing.T) {
+	host, _ := mockTaskService(t)
+	tasks, err := ListTasks(context.Background(), host, "u", "p", true, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2 (vanished task skipped): %+v", len(tasks), tasks)
+	}
+	if tasks[0].ID != "1" || tasks[0].TaskState != "Running" || tasks[0].PercentComplete == nil || *tasks[0].PercentComplete != 40 {
+		t.Errorf("task 1 = %+v", tasks[0])
+	}
+	if tasks[1].PercentComplete != nil {
+		t.Errorf("task 2 should have no PercentComplete: %+v", tasks[1])
+	}
+}
+
+func TestGetTaskReturnsMessages(t *testing.T) {
+	host, _ := mockTaskService(t)
+	raw, err := GetTask(context.Background(), host, "u", "p", true, 5*time.Second, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var task Task
+	if err := json.Unmarshal(raw, &task); err != nil {
+		t.Fatal(err)
+	}
+	if len(task.Messages) != 1 || task.Messages[0].MessageID != "Update.1.0.TransferringToComponent" {
+		t.Errorf("Messages = %+v", task.Messages)
+	}
+	if _, err := GetTask(context.Background(), host, "u", "p", true, 5*time.Second, "9"); err == nil || errors.Is(err, ErrTaskServiceUnsupported) {
+		t.Errorf("missing task: got %v, want a not found error", err)
+	}
+}
+
+func TestCancelTask(t *testing.T) {
+	host, calls := mockTaskService(t)
+	for _, id := range []string{"1", "2"} {
+		if err := CancelTask(context.Background(), host, "u", "p", true, 5*time.Second, id); err != nil {
+			t.Fatalf("cancel %s: %v", id, err)
+		}
+	}
+	want := []string{
+		"POST /redfish/v1/TaskService/Tasks/1/Actions/Task.Cancel",
+		"DELETE /redfish/v1/TaskService/Tasks/2",
+	}
+	if got := calls(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
+
+func TestTaskServiceUnsupported(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	if _, err := ListTasks(context.Background(), host, "u", "p", true, 5*time.Second); !errors.Is(err, ErrTaskServiceUnsupported) {
+		t.Errorf("ListTasks = %v, want ErrTaskServiceUnsupported", err)
+	}
+	if _, err := GetTask(context.Background(), host, "u", "p", true, 5*time.Second, "1"); !errors.Is(err, ErrTaskServiceUnsupported) {
+		t.Errorf("GetTask = %v, want ErrTaskServiceUnsupported", err)
+	}
+	if err := CancelTask(context.Background(), host, "u", "p", true, 5*time.Second, "1"); !errors.Is(err, ErrTaskServiceUnsupported) {
+		t.Errorf("CancelTask = %v, want ErrTaskServiceUnsupported", err)
+	}
+}
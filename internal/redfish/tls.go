@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSOptions configures certificate handling for BMC connections.
+type TLSOptions struct {
+	// CACertFile is a PEM bundle of CAs trusted for BMC certificates. When
+	// empty, the system roots are used.
+	CACertFile string
+	// ClientCertFile and ClientKeyFile are a PEM certificate and key
+	// presented to BMCs that require mutual TLS.
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// baseTLS is cloned into every transport; set by ConfigureTLS.
+var baseTLS = &tls.Config{}
+
+// ConfigureTLS loads the CA bundle and client certificate used by all
+// subsequent Redfish connections.
+func ConfigureTLS(opts TLSOptions) error {
+	cfg := &tls.Config{}
+	if opts.CACertFile != "" {
+		pem, err := os.ReadFile(opts.CACertFile)
+		if err != nil {
+			return fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in %s", opts.CACertFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if (opts.ClientCertFile == "") != (opts.ClientKeyFile == "") {
+		return errors.New("--client-cert and --client-key must be given together")
+	}
+	if opts.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	baseTLS = cfg
+	// Pooled transports hold the previous configuration
+	CloseIdleConnections()
+	return nil
+}
+
+// tlsConfig returns the TLS configuration for a new transport to host. Its
+// certificate is checked against any identity pinned for host, with or
+// without insecure.
+func tlsConfig(host string, insecure bool) *tls.Config {
+	cfg := baseTLS.Clone()
+	cfg.InsecureSkipVerify = insecure
+	cfg.VerifyConnection = verifyIdentity(host)
+	return cfg
+}
+
+// explainTLSError adds a hint to certificate verification failures.
+func explainTLSError(host string, err error) error {
+	var verr *tls.CertificateVerificationError
+	var uerr x509.UnknownAuthorityError
+	var herr x509.HostnameError
+	if errors.As(err, &verr) || errors.As(err, &uerr) || errors.As(err, &herr) {
+		return fmt.Errorf("%w (TLS verification of %s failed: pass --ca-cert with the BMC's CA bundle, or --insecure to skip verification)", err, host)
+	}
+	return err
+}
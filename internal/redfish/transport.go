@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"bootstrap/internal/httptimeout"
+)
+
+// Keep-alive settings of the pooled transports. A TLS handshake costs a
+// weak BMC CPU a few hundred milliseconds, so connections are kept for the
+// whole run; a few per BMC cover the parallel interface reads of discovery.
+const (
+	maxIdleConnsPerBMC = 4
+	idleConnTimeout    = 90 * time.Second
+)
+
+type transportKey struct {
+	host     string
+	insecure bool
+}
+
+var (
+	transportsMu sync.Mutex
+	transports   = map[transportKey]*http.Transport{}
+)
+
+// sharedTransport returns the transport every client of host uses, so that
+// consecutive calls to the same BMC reuse its connections.
+func sharedTransport(host string, insecure bool) *http.Transport {
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	key := transportKey{host: host, insecure: insecure}
+	tr := transports[key]
+	if tr == nil {
+		tr = httptimeout.Apply(&http.Transport{
+			Proxy:               proxy,
+			TLSClientConfig:     tlsConfig(host, insecure),
+			MaxIdleConnsPerHost: maxIdleConnsPerBMC,
+			IdleConnTimeout:     idleConnTimeout,
+		})
+		transports[key] = tr
+	}
+	return tr
+}
+
+// forgetHost closes the idle connections to host and drops its transport,
+// e.g. after a BMC reset has made them stale.
+func forgetHost(host string) {
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	for key, tr := range transports {
+		if key.host == host {
+			tr.CloseIdleConnections()
+			delete(transports, key)
+		}
+	}
+}
+
+// CloseIdleConnections closes the idle keep-alive connections to every BMC
+// and empties the transport pool. Call it when a run is done, and it is
+// called when the TLS configuration changes; call it after
+// httptimeout.Configure too.
+func CloseIdleConnections() {
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	for key, tr := range transports {
+		tr.CloseIdleConnections()
+		delete(transports, key)
+	}
+}
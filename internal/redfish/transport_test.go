@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package redfish
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bootstrap/internal/httplimit"
+)
+
+// handshakeServer returns a TLS Redfish stub and a count of the TLS
+// handshakes it has completed.
+func handshakeServer(t testing.TB) (string, *atomic.Int64) {
+	var handshakes atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"RedfishVersion":"1.6.0"}`))
+	}))
+	srv.TLS = &tls.Config{GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshakes.Add(1)
+		return nil, nil
+	}}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	t.Cleanup(CloseIdleConnections)
+	return strings.TrimPrefix(srv.URL, "https://"), &handshakes
+}
+
+func TestClientsShareConnections(t *testing.T) {
+	host, handshakes := handshakeServer(t)
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		if err := ProbeServiceRoot(ctx, host, "u", "p", true, 5*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := handshakes.Load(); n != 1 {
+		t.Errorf("10 sequential calls made %d TLS handshakes, want 1", n)
+	}
+
+	// A reset BMC gets fresh connections
+	forgetHost(host)
+	if err := ProbeServiceRoot(ctx, host, "u", "p", true, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := handshakes.Load(); n != 2 {
+		t.Errorf("after forgetHost: %d handshakes, want 2", n)
+	}
+}
+
+func BenchmarkProbeServiceRoot(b *testing.B) {
+	host, handshakes := handshakeServer(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ProbeServiceRoot(ctx, host, "u", "p", true, 5*time.Second); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(handshakes.Load())/float64(b.N), "handshakes/op")
+}
+
+func TestGzipAndResponseLimit(t *testing.T) {
+	// FirmwareInventory members gzip-compressed when asked, and a Systems
+	// collection that never ends
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redfish/v1/UpdateService/FirmwareInventory":
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+				t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get("Accept-Encoding"))
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			fmt.Fprintf(gz, `{"Members":[{"@odata.id":"/redfish/v1/UpdateService/FirmwareInventory/BMC"}],"Padding":%q}`, strings.Repeat(" ", 4096))
+			gz.Close() //nolint:errcheck
+		case "/redfish/v1/UpdateService/FirmwareInventory/BMC":
+			fmt.Fprint(w, `{"Id":"BMC","Version":"nc.1.9.8"}`)
+		case "/redfish/v1/Systems":
+			fmt.Fprint(w, `{"Members":[`)
+			for r.Context().Err() == nil {
+				if _, err := fmt.Fprint(w, `{"@odata.id":"/redfish/v1/Systems/Node0"},`); err != nil {
+					return
+				}
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(CloseIdleConnections)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	httplimit.Configure(1024)
+	t.Cleanup(func() { httplimit.Configure(httplimit.DefaultMaxBytes) })
+	ctx := context.Background()
+
+	if _, err := ListFirmwareInventory(ctx, host, "u", "p", true, 5*time.Second); err == nil || !strings.Contains(err.Error(), "GET /redfish/v1/UpdateService/FirmwareInventory: response too large") {
+		t.Errorf("a 4 KiB body decompressed under a 1 KiB limit: err = %v", err)
+	}
+	_, err := GetModels(ctx, host, "u", "p", true, 5*time.Second)
+	var lerr *httplimit.Error
+	if !errors.As(err, &lerr) || lerr.Path != "/redfish/v1/Systems" {
+		t.Errorf("endless body: err = %v, want an *httplimit.Error for /redfish/v1/Systems", err)
+	}
+
+	httplimit.Configure(httplimit.DefaultMaxBytes)
+	fw, err := ListFirmwareInventory(ctx, host, "u", "p", true, 5*time.Second)
+	if err != nil || len(fw) != 1 || fw[0].Version != "nc.1.9.8" {
+		t.Errorf("gzip-compressed collection = %+v, %v", fw, err)
+	}
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package scan finds live BMCs by probing a subnet for Redfish service roots.
+package scan
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/inventory"
+	"bootstrap/internal/redfish"
+)
+
+// maxAddresses bounds the size of a scanned subnet (a /16).
+const maxAddresses = 1 << 16
+
+// Options controls a subnet scan.
+type Options struct {
+	User        string
+	Pass        string
+	Insecure    bool
+	Timeout     time.Duration // per-address probe timeout
+	Concurrency int           // addresses probed in parallel
+	Port        int           // HTTPS port; 0 means the default (443)
+}
+
+// Hosts returns the usable host addresses of an IPv4 CIDR in ascending order.
+// The network and broadcast addresses are excluded for prefixes shorter than /31.
+func Hosts(cidr string) ([]netip.Addr, error) {
+	p, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet %q: %w", cidr, err)
+	}
+	if !p.Addr().Is4() {
+		return nil, fmt.Errorf("only IPv4 subnets can be scanned: %s", cidr)
+	}
+	p = p.Masked()
+	hostBits := 32 - p.Bits()
+	if 1<<hostBits > maxAddresses {
+		return nil, fmt.Errorf("subnet %s is too large to scan (max /16)", cidr)
+	}
+	var out []netip.Addr
+	for a := p.Addr(); p.Contains(a); a = a.Next() {
+		out = append(out, a)
+	}
+	if hostBits >= 2 {
+		out = out[1 : len(out)-1]
+	}
+	return out, nil
+}
+
+// PlaceholderXname returns the placeholder xname recorded for a scanned BMC,
+// e.g. "bmc-192-168-100-7". Operators are expected to replace it.
+func PlaceholderXname(ip string) string {
+	return "bmc-" + strings.ReplaceAll(ip, ".", "-")
+}
+
+// Subnet probes every host address in cidr for a Redfish service and returns
+// a BMC entry for each responder whose IP is not in skip. Entries carry the
+// IP, the Manager MAC address and serial number when available, and a
+// placeholder xname. Results are sorted by IP address.
+func Subnet(ctx context.Context, cidr string, skip map[string]bool, opts Options) ([]inventory.Entry, error) {
+	addrs, err := Hosts(cidr)
+	if err != nil {
+		return nil, err
+	}
+	sem := make(chan struct{}, max(1, opts.Concurrency))
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		found []inventory.Entry
+	)
+	for _, a := range addrs {
+		ip := a.String()
+		if skip[ip] {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if e, ok := probe(ctx, ip, opts); ok {
+				mu.Lock()
+				found = append(found, e)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Slice(found, func(i, j int) bool {
+		return netip.MustParseAddr(found[i].IP).Less(netip.MustParseAddr(found[j].IP))
+	})
+	return found, nil
+}
+
+// probe checks a single address and collects Manager details from responders.
+func probe(ctx context.Context, ip string, opts Options) (inventory.Entry, bool) {
+	host := ip
+	if opts.Port != 0 {
+		host = ip + ":" + strconv.Itoa(opts.Port)
+	}
+	pctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	err := redfish.ProbeServiceRoot(pctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout)
+	cancel()
+	if err != nil {
+		return inventory.Entry{}, false
+	}
+	e := inventory.Entry{Xname: PlaceholderXname(ip), IP: ip}
+	// Manager details take several requests; give them their own budget.
+	dctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	info, err := redfish.GetManagerInfo(dctx, host, opts.User, opts.Pass, opts.Insecure, opts.Timeout)
+	if err != nil {
+		diag.Warnf("%s: manager details: %v", ip, err)
+	}
+	e.MAC = info.MACAddress
+	e.Serial = info.SerialNumber
+	return e, true
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package scan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHosts(t *testing.T) {
+	tests := []struct {
+		cidr      string
+		wantCount int
+		wantFirst string
+		wantLast  string
+		wantErr   bool
+	}{
+		{"192.168.100.0/24", 254, "192.168.100.1", "192.168.100.254", false},
+		{"10.0.0.0/30", 2, "10.0.0.1", "10.0.0.2", false},
+		{"10.0.0.7/32", 1, "10.0.0.7", "10.0.0.7", false},
+		{"10.0.0.0/8", 0, "", "", true},
+		{"fd00::/120", 0, "", "", true},
+		{"bogus", 0, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			got, err := Hosts(tt.cidr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.wantCount {
+				t.Fatalf("got %d hosts, want %d", len(got), tt.wantCount)
+			}
+			if got[0].String() != tt.wantFirst || got[len(got)-1].String() != tt.wantLast {
+				t.Errorf("range = %s..%s, want %s..%s", got[0], got[len(got)-1], tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestSubnetRecordsResponders(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/redfish/v1":
+			_, _ = w.Write([]byte(`{"RedfishVersion":"1.9.0"}`))
+		case "/redfish/v1/Managers":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC"}]}`))
+		case "/redfish/v1/Managers/BMC":
+			_, _ = w.Write([]byte(`{"SerialNumber":"SN123","EthernetInterfaces":{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces"}}`))
+		case "/redfish/v1/Managers/BMC/EthernetInterfaces":
+			_, _ = w.Write([]byte(`{"Members":[{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces/usb0"},{"@odata.id":"/redfish/v1/Managers/BMC/EthernetInterfaces/eth0"}]}`))
+		case "/redfish/v1/Managers/BMC/EthernetInterfaces/usb0":
+			_, _ = w.Write([]byte(`{"MACAddress":"AA:AA:AA:AA:AA:AA","IPv4Addresses":[{"Address":"169.254.0.1"}]}`))
+		case "/redfish/v1/Managers/BMC/EthernetInterfaces/eth0":
+			_, _ = w.Write([]byte(`{"MACAddress":"02:23:28:01:30:00","IPv4Addresses":[{"Address":"127.0.0.1"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	opts := Options{User: "u", Pass: "p", Insecure: true, Timeout: 2 * time.Second, Concurrency: 4, Port: port}
+	got, err := Subnet(context.Background(), "127.0.0.1/32", nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d entries, want 1", len(got))
+	}
+	e := got[0]
+	if e.IP != "127.0.0.1" || e.MAC != "02:23:28:01:30:00" || e.Serial != "SN123" || e.Xname != "bmc-127-0-0-1" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	// Known addresses are skipped so repeated scans are additive.
+	got, err = Subnet(context.Background(), "127.0.0.1/32", map[string]bool{"127.0.0.1": true}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected known address to be skipped, got %+v", got)
+	}
+}
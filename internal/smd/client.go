@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+// Package smd implements a minimal client for the OpenCHAMI State Management
+// Database (SMD) EthernetInterfaces API.
+package smd
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"bootstrap/internal/diag"
+	"bootstrap/internal/httplimit"
+	"bootstrap/internal/httptimeout"
+	"bootstrap/internal/inventory"
+)
+
+// ErrNotFound is returned when SMD has no record for the requested ID.
+var ErrNotFound = errors.New("not found")
+
+const ethernetInterfacesPath = "/hsm/v2/Inventory/EthernetInterfaces"
+
+var pxeSuffix = regexp.MustCompile(`-pxe\d+$`)
+
+// IPAddress is one entry of an EthernetInterface's IPAddresses list.
+type IPAddress struct {
+	IPAddress string `json:"IPAddress"`
+	Network   string `json:"Network,omitempty"`
+}
+
+// EthernetInterface is an SMD EthernetInterfaces record.
+type EthernetInterface struct {
+	ID          string      `json:"ID,omitempty"`
+	Description string      `json:"Description,omitempty"`
+	MACAddress  string      `json:"MACAddress"`
+	ComponentID string      `json:"ComponentID"`
+	IPAddresses []IPAddress `json:"IPAddresses"`
+}
+
+// Outcome reports what Upsert did with a record.
+type Outcome int
+
+const (
+	// Unchanged means SMD already held an identical record.
+	Unchanged Outcome = iota
+	// Created means the record was POSTed.
+	Created
+	// Updated means an existing record was PATCHed.
+	Updated
+)
+
+// Client talks to a single SMD instance.
+type Client struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// NewClient returns a client for the SMD service at baseURL, e.g.
+// "https://smd.example.com". An empty token sends no Authorization header.
+func NewClient(baseURL, token string, insecure bool, timeout time.Duration) *Client {
+	// --proxy is for the BMC network; SMD only follows HTTPS_PROXY and NO_PROXY
+	tr := httptimeout.Apply(&http.Transport{Proxy: http.ProxyFromEnvironment})
+	if insecure {
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &Client{
+		base:  strings.TrimRight(baseURL, "/"),
+		token: token,
+		http:  &http.Client{Timeout: timeout, Transport: diag.Transport(tr)},
+	}
+}
+
+// ComponentID derives the SMD component xname from an inventory node xname by
+// dropping any -pxeN suffix, e.g. x1000c0s0b0n0-pxe1 -> x1000c0s0b0n0.
+func ComponentID(xname string) string {
+	return pxeSuffix.ReplaceAllString(xname, "")
+}
+
+// InterfaceID returns the SMD record ID for a MAC address: lowercase hex
+// with the separators removed.
+func InterfaceID(mac string) string {
+	return strings.ToLower(strings.NewReplacer(":", "", "-", "").Replace(mac))
+}
+
+// FromEntry builds the EthernetInterfaces record for an inventory node entry.
+func FromEntry(e inventory.Entry) EthernetInterface {
+	ei := EthernetInterface{
+		MACAddress:  strings.ToLower(e.MAC),
+		ComponentID: ComponentID(e.Xname),
+		IPAddresses: []IPAddress{},
+	}
+	if e.IP != "" {
+		ei.IPAddresses = append(ei.IPAddresses, IPAddress{IPAddress: e.IP})
+	}
+	return ei
+}
+
+// GetEthernetInterface fetches the record with the given ID. It returns
+// ErrNotFound when SMD has no such record.
+func (c *Client) GetEthernetInterface(ctx context.Context, id string) (EthernetInterface, error) {
+	var ei EthernetInterface
+	resp, err := c.do(ctx, "GET", ethernetInterfacesPath+"/"+url.PathEscape(id), nil)
+	if err != nil {
+		return ei, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode == http.StatusNotFound {
+		return ei, ErrNotFound
+	}
+	if err := checkStatus(resp); err != nil {
+		return ei, err
+	}
+	return ei, json.NewDecoder(resp.Body).Decode(&ei)
+}
+
+// Upsert creates ei when SMD has no record for its MAC and patches the
+// ComponentID and IPAddresses of an existing record that differs.
+func (c *Client) Upsert(ctx context.Context, ei EthernetInterface) (Outcome, error) {
+	id := InterfaceID(ei.MACAddress)
+	cur, err := c.GetEthernetInterface(ctx, id)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return Created, c.send(ctx, "POST", ethernetInterfacesPath, ei)
+	case err != nil:
+		return Unchanged, err
+	}
+	if cur.ComponentID == ei.ComponentID && sameIPs(cur.IPAddresses, ei.IPAddresses) {
+		return Unchanged, nil
+	}
+	patch := map[string]any{
+		"ComponentID": ei.ComponentID,
+		"IPAddresses": ei.IPAddresses,
+	}
+	return Updated, c.send(ctx, "PATCH", ethernetInterfacesPath+"/"+url.PathEscape(id), patch)
+}
+
+// sameIPs compares the address sets of two IPAddresses lists.
+func sameIPs(a, b []IPAddress) bool {
+	addrs := func(l []IPAddress) []string {
+		out := make([]string, 0, len(l))
+		for _, ip := range l {
+			out = append(out, ip.IPAddress)
+		}
+		slices.Sort(out)
+		return out
+	}
+	return slices.Equal(addrs(a), addrs(b))
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body any) error {
+	resp, err := c.do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	return checkStatus(resp)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	u := c.base + path
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = strings.NewReader(string(b))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := httptimeout.Do(c.http, req)
+	if err != nil {
+		return nil, err
+	}
+	return httplimit.Limit(resp), nil
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	b, _ := io.ReadAll(resp.Body)
+	return fmt.Errorf("smd %s %s: %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(b)))
+}
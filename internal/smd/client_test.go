@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2025 OpenCHAMI Contributors
+//
+// SPDX-License-Identifier: MIT
+
+package smd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bootstrap/internal/inventory"
+)
+
+func TestComponentID(t *testing.T) {
+	cases := map[string]string{
+		"x1000c0s0b0n0":      "x1000c0s0b0n0",
+		"x1000c0s0b0n0-pxe1": "x1000c0s0b0n0",
+		"x1000c0s0b0n0-pxe":  "x1000c0s0b0n0-pxe",
+	}
+	for in, want := range cases {
+		if got := ComponentID(in); got != want {
+			t.Errorf("ComponentID(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := InterfaceID("AA:BB:cc:00:11:22"); got != "aabbcc001122" {
+		t.Errorf("InterfaceID = %q", got)
+	}
+}
+
+// fakeSMD stores EthernetInterfaces records keyed by ID and records the
+// method of every write.
+type fakeSMD struct {
+	mu      sync.Mutex
+	records map[string]EthernetInterface
+	writes  []string
+	token   string
+}
+
+func (f *fakeSMD) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token = r.Header.Get("Authorization")
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, ethernetInterfacesPath), "/")
+	switch r.Method {
+	case "GET":
+		ei, ok := f.records[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ei)
+	case "POST":
+		var ei EthernetInterface
+		_ = json.NewDecoder(r.Body).Decode(&ei)
+		f.records[InterfaceID(ei.MACAddress)] = ei
+		f.writes = append(f.writes, "POST")
+		w.WriteHeader(http.StatusCreated)
+	case "PATCH":
+		var ei EthernetInterface
+		_ = json.NewDecoder(r.Body).Decode(&ei)
+		cur := f.records[id]
+		cur.ComponentID, cur.IPAddresses = ei.ComponentID, ei.IPAddresses
+		f.records[id] = cur
+		f.writes = append(f.writes, "PATCH "+id)
+	}
+}
+
+func TestUpsertCreatesUpdatesAndSkips(t *testing.T) {
+	fake := &fakeSMD{records: map[string]EthernetInterface{
+		"aabbccddee01": {MACAddress: "aa:bb:cc:dd:ee:01", ComponentID: "x1000c0s0b0n0", IPAddresses: []IPAddress{{IPAddress: "10.0.0.1"}}},
+		"aabbccddee02": {MACAddress: "aa:bb:cc:dd:ee:02", ComponentID: "x1000c0s0b0n1", IPAddresses: []IPAddress{{IPAddress: "10.0.0.99"}}},
+	}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	c := NewClient(srv.URL+"/", "tok", false, 5*time.Second)
+
+	entries := []inventory.Entry{
+		{Xname: "x1000c0s0b0n0", MAC: "aa:bb:cc:dd:ee:01", IP: "10.0.0.1"},
+		{Xname: "x1000c0s0b0n1", MAC: "AA:BB:CC:DD:EE:02", IP: "10.0.0.2"},
+		{Xname: "x1000c0s1b0n0-pxe1", MAC: "aa:bb:cc:dd:ee:03", IP: "10.0.0.3"},
+	}
+	want := []Outcome{Unchanged, Updated, Created}
+	for i, e := range entries {
+		got, err := c.Upsert(context.Background(), FromEntry(e))
+		if err != nil {
+			t.Fatalf("Upsert(%s): %v", e.Xname, err)
+		}
+		if got != want[i] {
+			t.Errorf("Upsert(%s) = %v, want %v", e.Xname, got, want[i])
+		}
+	}
+	if fake.token != "Bearer tok" {
+		t.Errorf("Authorization = %q", fake.token)
+	}
+	if len(fake.writes) != 2 || fake.writes[0] != "PATCH aabbccddee02" || fake.writes[1] != "POST" {
+		t.Errorf("unexpected writes: %v", fake.writes)
+	}
+	if got := fake.records["aabbccddee03"].ComponentID; got != "x1000c0s1b0n0" {
+		t.Errorf("created ComponentID = %q", got)
+	}
+	if got := fake.records["aabbccddee02"].IPAddresses; len(got) != 1 || got[0].IPAddress != "10.0.0.2" {
+		t.Errorf("patched IPAddresses = %v", got)
+	}
+}
@@ -6,19 +6,26 @@
 package xname
 
 import (
+	"cmp"
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
 var trailingB = regexp.MustCompile(`b(\d+)$`)
 
 // BMCXnameToNode converts e.g. x1000c0s0b0 -> x1000c0s0n0, x...b1 -> x...n1.
-// If it does not match, we append "-n0".
-func BMCXnameToNode(bmcX string) string {
-	if m := trailingB.FindStringSubmatch(bmcX); m != nil {
-		return trailingB.ReplaceAllString(bmcX, "n$1")
+// It returns an error if bmcX is not a BMC xname.
+func BMCXnameToNode(bmcX string) (string, error) {
+	x, err := Parse(bmcX)
+	if err != nil {
+		return "", err
 	}
-	return bmcX + "-n0"
+	if x.Kind != KindBMC {
+		return "", fmt.Errorf("%q is a %s xname, not a BMC xname", bmcX, x.Kind)
+	}
+	return trailingB.ReplaceAllString(x.String(), "n$1"), nil
 }
 
 // BMCXnameToNodeN converts a BMC xname to a node xname with a specific node number.
@@ -27,3 +34,243 @@ func BMCXnameToNodeN(bmcX string, nodeNum int) string {
 	// Append nY where Y is the nodeNum
 	return fmt.Sprintf("%sn%d", bmcX, nodeNum)
 }
+
+var chassisPrefix = regexp.MustCompile(`^x\d+c\d+`)
+
+// Chassis returns the cabinet+chassis prefix of an xname, e.g.
+// x9000c1s0b0n0 -> x9000c1. It returns "" if the xname has no chassis.
+func Chassis(x string) string {
+	return chassisPrefix.FindString(x)
+}
+
+// crayXname matches cabinet, chassis, slot, BMC, and node xnames, e.g. x1000,
+// x1000c0, x1000c0s0, x1000c0s0b0, and x1000c0s0b0n0.
+var crayXname = regexp.MustCompile(`^x\d{1,4}(c\d+(s\d+(b\d+(n\d+)?)?)?)?$`)
+
+// IsValid reports whether x is a syntactically valid Cray-style xname in
+// canonical (lowercase) form.
+func IsValid(x string) bool {
+	return crayXname.MatchString(x)
+}
+
+// chassisBMCXname matches a chassis controller (cC) xname such as x9000c1b0,
+// in any case.
+var chassisBMCXname = regexp.MustCompile(`(?i)^x(\d{1,4})c(\d+)b(\d+)$`)
+
+// ParseChassisBMC parses a chassis controller (cC) xname such as x9000c1b0
+// and returns its canonical form and its chassis, e.g. x9000c1. ok is false
+// when s is not a cC xname.
+func ParseChassisBMC(s string) (canonical, chassis string, ok bool) {
+	n, ok := chassisBMCParts(s)
+	if !ok {
+		return "", "", false
+	}
+	chassis = fmt.Sprintf("x%dc%d", n[0], n[1])
+	return fmt.Sprintf("%sb%d", chassis, n[2]), chassis, true
+}
+
+// chassisBMCParts returns the cabinet, chassis, and BMC numbers of a chassis
+// controller xname.
+func chassisBMCParts(s string) ([3]int, bool) {
+	var n [3]int
+	m := chassisBMCXname.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return n, false
+	}
+	for i := range n {
+		v, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return n, false
+		}
+		n[i] = v
+	}
+	return n, true
+}
+
+// ChassisBMC returns the xname of the first chassis controller of chassis,
+// e.g. x9000c1 -> x9000c1b0.
+func ChassisBMC(chassis string) string {
+	return chassis + "b0"
+}
+
+// Kind is the component an xname names.
+type Kind int
+
+// Xname kinds, from the widest to the narrowest component.
+const (
+	KindCabinet Kind = iota + 1
+	KindChassis
+	KindSlot
+	KindBMC
+	KindNode
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindCabinet:
+		return "cabinet"
+	case KindChassis:
+		return "chassis"
+	case KindSlot:
+		return "slot"
+	case KindBMC:
+		return "BMC"
+	case KindNode:
+		return "node"
+	}
+	return "unknown"
+}
+
+// Xname is a parsed xname. Components below Kind are zero.
+type Xname struct {
+	Kind    Kind
+	Cabinet int
+	Chassis int
+	Slot    int
+	BMC     int
+	Node    int
+}
+
+// anyCaseXname is crayXname without the case requirement, for Parse.
+var anyCaseXname = regexp.MustCompile(`(?i)^x(\d{1,4})(?:c(\d+)(?:s(\d+)(?:b(\d+)(?:n(\d+))?)?)?)?$`)
+
+// Parse parses a cabinet, chassis, slot, BMC, or node xname. Letters may be
+// in either case and surrounding space is ignored, so X9000C1S0B0 parses
+// as x9000c1s0b0.
+func Parse(s string) (Xname, error) {
+	m := anyCaseXname.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Xname{}, fmt.Errorf("invalid xname %q", s)
+	}
+	var x Xname
+	fields := []*int{&x.Cabinet, &x.Chassis, &x.Slot, &x.BMC, &x.Node}
+	for i, f := range fields {
+		if m[i+1] == "" {
+			break
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return Xname{}, fmt.Errorf("invalid xname %q: %w", s, err)
+		}
+		*f = n
+		x.Kind = Kind(i + 1)
+	}
+	return x, nil
+}
+
+// Ancestor returns the component of kind k that contains x, e.g. the slot
+// x9000c1s0 for x9000c1s0b1n0. ok is false when x is wider than k.
+func (x Xname) Ancestor(k Kind) (Xname, bool) {
+	if k > x.Kind || k < KindCabinet {
+		return Xname{}, false
+	}
+	a := Xname{Kind: k, Cabinet: x.Cabinet}
+	fields := []struct{ dst, src *int }{{&a.Chassis, &x.Chassis}, {&a.Slot, &x.Slot}, {&a.BMC, &x.BMC}, {&a.Node, &x.Node}}
+	for i, f := range fields {
+		if Kind(i+2) > k {
+			break
+		}
+		*f.dst = *f.src
+	}
+	return a, true
+}
+
+// String returns the canonical form of x, e.g. x9000c1s0b0n1.
+func (x Xname) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "x%d", x.Cabinet)
+	parts := []struct {
+		letter string
+		n      int
+	}{{"c", x.Chassis}, {"s", x.Slot}, {"b", x.BMC}, {"n", x.Node}}
+	for i, p := range parts {
+		if Kind(i+2) > x.Kind {
+			break
+		}
+		fmt.Fprintf(&b, "%s%d", p.letter, p.n)
+	}
+	return b.String()
+}
+
+// Normalize returns the canonical form of s, e.g. X9000C1S0B0 -> x9000c1s0b0,
+// or an error if s is not an xname.
+func Normalize(s string) (string, error) {
+	x, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return x.String(), nil
+}
+
+// Compare orders xnames by component number rather than by text, so
+// x1000c0s2b0 sorts before x1000c0s10b0 and a BMC before its nodes. Strings
+// that are not xnames sort after all xnames, by text.
+func Compare(a, b string) int {
+	xa, ra, errA := parseForCompare(a)
+	xb, rb, errB := parseForCompare(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return 1
+	case errB != nil:
+		return -1
+	}
+	for _, c := range [][2]int{
+		{xa.Cabinet, xb.Cabinet}, {xa.Chassis, xb.Chassis}, {xa.Slot, xb.Slot},
+		{xa.BMC, xb.BMC}, {xa.Node, xb.Node}, {ra[0], rb[0]}, {ra[1], rb[1]},
+	} {
+		if c[0] != c[1] {
+			return cmp.Compare(c[0], c[1])
+		}
+	}
+	return 0
+}
+
+// parseForCompare parses s like Parse, and also a chassis controller xname,
+// which sorts after its chassis and before the chassis's slots. rank breaks
+// ties between xnames with the same numbers: the kind, then the chassis
+// controller number.
+func parseForCompare(s string) (x Xname, rank [2]int, err error) {
+	x, err = Parse(s)
+	if err == nil {
+		return x, [2]int{2 * int(x.Kind)}, nil
+	}
+	n, ok := chassisBMCParts(s)
+	if !ok {
+		return Xname{}, rank, err
+	}
+	return Xname{Kind: KindChassis, Cabinet: n[0], Chassis: n[1]}, [2]int{2*int(KindChassis) + 1, n[2]}, nil
+}
+
+var (
+	nodeXnameIn = regexp.MustCompile(`(?i)x\d{1,4}c\d+s\d+b\d+n\d+`)
+	slotBMCNode = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])s(\d+)b(\d+)n(\d+)(?:$|[^0-9])`)
+	slotNode    = regexp.MustCompile(`(?i)slot[\s_-]*(\d+)\D*?node[\s_-]*(\d+)`)
+)
+
+// NodeInChassis maps the Id or Name of a system that a chassis controller
+// serves on behalf of a node controller to its node xname, e.g. for chassis
+// x9000c1: "x9000c1s3b0n1", "s3b0n1", or "Slot3 Node1" (BMC 0) ->
+// x9000c1s3b0n1. A full xname in another chassis is not mapped.
+func NodeInChassis(chassis, name string) (string, bool) {
+	if m := nodeXnameIn.FindString(name); m != "" {
+		x, err := Parse(m)
+		if err != nil || Chassis(x.String()) != chassis {
+			return "", false
+		}
+		return x.String(), true
+	}
+	var n [3]int
+	if m := slotBMCNode.FindStringSubmatch(name); m != nil {
+		n[0], _ = strconv.Atoi(m[1])
+		n[1], _ = strconv.Atoi(m[2])
+		n[2], _ = strconv.Atoi(m[3])
+	} else if m := slotNode.FindStringSubmatch(name); m != nil {
+		n[0], _ = strconv.Atoi(m[1])
+		n[2], _ = strconv.Atoi(m[2])
+	} else {
+		return "", false
+	}
+	return fmt.Sprintf("%ss%db%dn%d", chassis, n[0], n[1], n[2]), true
+}
@@ -4,7 +4,10 @@
 
 package xname
 
-import "testing"
+import (
+	"cmp"
+	"testing"
+)
 
 func TestBMCXnameToNode(t *testing.T) {
 	cases := []struct {
@@ -13,12 +16,17 @@ func TestBMCXnameToNode(t *testing.T) {
 	}{
 		{"x1000c0s0b0", "x1000c0s0n0"},
 		{"x1000c0s0b1", "x1000c0s0n1"},
-		{"x9999c1s2", "x9999c1s2-n0"},
+		{"X1000C0S0B1", "x1000c0s0n1"},
 	}
 	for _, c := range cases {
-		got := BMCXnameToNode(c.in)
-		if got != c.out {
-			t.Fatalf("BMCXnameToNode(%q)=%q want %q", c.in, got, c.out)
+		got, err := BMCXnameToNode(c.in)
+		if err != nil || got != c.out {
+			t.Fatalf("BMCXnameToNode(%q)=%q, %v want %q", c.in, got, err, c.out)
+		}
+	}
+	for _, in := range []string{"x9999c1s2", "x1000c0s0b0n0", "bmc-10-0-0-1", ""} {
+		if got, err := BMCXnameToNode(in); err == nil {
+			t.Errorf("BMCXnameToNode(%q)=%q, want an error", in, got)
 		}
 	}
 }
@@ -44,3 +52,160 @@ func TestBMCXnameToNodeN(t *testing.T) {
 		}
 	}
 }
+
+func TestChassis(t *testing.T) {
+	cases := map[string]string{
+		"x9000c1s0b0n0": "x9000c1",
+		"x1000c12":      "x1000c12",
+		"x1000":         "",
+		"bmc-10-0-0-1":  "",
+	}
+	for in, want := range cases {
+		if got := Chassis(in); got != want {
+			t.Errorf("Chassis(%q)=%q want %q", in, got, want)
+		}
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	for _, x := range []string{"x1000", "x1000c0", "x9000c1s0b0", "x9000c1s0b0n1"} {
+		if !IsValid(x) {
+			t.Errorf("IsValid(%q) = false, want true", x)
+		}
+	}
+	for _, x := range []string{"", "x", "x10000c0", "x1000c0b0", "x1000c0s0b0n0-pxe1", "bmc-10-0-0-1", "X1000c0"} {
+		if IsValid(x) {
+			t.Errorf("IsValid(%q) = true, want false", x)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	cases := map[string]Xname{
+		"x3000":         {Kind: KindCabinet, Cabinet: 3000},
+		"x9000c1":       {Kind: KindChassis, Cabinet: 9000, Chassis: 1},
+		"x9000c1s7":     {Kind: KindSlot, Cabinet: 9000, Chassis: 1, Slot: 7},
+		"x3000c0s36b0":  {Kind: KindBMC, Cabinet: 3000, Chassis: 0, Slot: 36},
+		"x9000c3s0b1n1": {Kind: KindNode, Cabinet: 9000, Chassis: 3, Slot: 0, BMC: 1, Node: 1},
+	}
+	for in, want := range cases {
+		got, err := Parse(in)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %+v, %v want %+v", in, got, err, want)
+			continue
+		}
+		if got.String() != in {
+			t.Errorf("Parse(%q).String() = %q", in, got.String())
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"X9000C1S0B0":     "x9000c1s0b0",
+		" x9000c1s0b0n0 ": "x9000c1s0b0n0",
+		"x9000c01s00b0":   "x9000c1s0b0",
+		"x3000c0":         "x3000c0",
+	}
+	for in, want := range cases {
+		if got, err := Normalize(in); err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "x", "c1", "x10000", "x9000c", "x9000c1s", "x9000c1b0", "x9000c1s0n0",
+		"x9000c1s0b0n0n1", "x-1c0", "x9000c1s0b0 n0", "x9000c1s0b0-n0", "bmc-10-0-0-1", "y9000c1"} {
+		if got, err := Normalize(in); err == nil {
+			t.Errorf("Normalize(%q) = %q, want an error", in, got)
+		}
+	}
+}
+
+// FuzzNormalize checks that anything Normalize accepts comes out valid and
+// stays the same when normalized again.
+func FuzzNormalize(f *testing.F) {
+	for _, seed := range []string{"x9000c1s0b0", "X1C2S3B4N5", "x0", "x9000c1s0b0n0n", "x٣c1", "x00001"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		out, err := Normalize(in)
+		if err != nil {
+			return
+		}
+		if !IsValid(out) {
+			t.Fatalf("Normalize(%q) = %q, which is not valid", in, out)
+		}
+		if again, err := Normalize(out); err != nil || again != out {
+			t.Fatalf("Normalize(%q) = %q, %v, want %q", out, again, err, out)
+		}
+	})
+}
+
+func TestParseChassisBMC(t *testing.T) {
+	for _, tc := range []struct {
+		in, canonical, chassis string
+		ok                     bool
+	}{
+		{"x9000c1b0", "x9000c1b0", "x9000c1", true},
+		{"X9000C01B0", "x9000c1b0", "x9000c1", true},
+		{"x9000c1s0b0", "", "", false},
+		{"x9000c1", "", "", false},
+	} {
+		canonical, chassis, ok := ParseChassisBMC(tc.in)
+		if canonical != tc.canonical || chassis != tc.chassis || ok != tc.ok {
+			t.Errorf("ParseChassisBMC(%q) = %q, %q, %v; want %q, %q, %v", tc.in, canonical, chassis, ok, tc.canonical, tc.chassis, tc.ok)
+		}
+	}
+	if got := ChassisBMC("x9000c3"); got != "x9000c3b0" {
+		t.Errorf("ChassisBMC = %q", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	sorted := []string{"x1000", "x1000c0", "x1000c0b0", "x1000c0s2b0", "x1000c0s2b0n1", "x1000c0s10b0", "x1000c1s0b0", "X3000C0S1B0", "bmc-10-0-0-1", "node7"}
+	for i := range sorted {
+		for j := range sorted {
+			if got, want := Compare(sorted[i], sorted[j]), cmp.Compare(i, j); got != want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", sorted[i], sorted[j], got, want)
+			}
+		}
+	}
+}
+
+func TestAncestor(t *testing.T) {
+	x, err := Parse("x9000c1s2b1n0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[Kind]string{KindCabinet: "x9000", KindChassis: "x9000c1", KindSlot: "x9000c1s2", KindBMC: "x9000c1s2b1", KindNode: "x9000c1s2b1n0"} {
+		if a, ok := x.Ancestor(k); !ok || a.String() != want || a.Kind != k {
+			t.Errorf("Ancestor(%s) = %v, %v; want %s", k, a, ok, want)
+		}
+	}
+	bmc, _ := Parse("x9000c1s2b1")
+	if _, ok := bmc.Ancestor(KindNode); ok {
+		t.Error("a BMC has no node ancestor")
+	}
+}
+
+func TestNodeInChassis(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"x9000c1s3b0n1", "x9000c1s3b0n1"},
+		{"Node X9000C1S3B1N0", "x9000c1s3b1n0"},
+		{"s3b0n1", "x9000c1s3b0n1"},
+		{"Blade_s7b1n0", "x9000c1s7b1n0"},
+		{"Slot3 Node1", "x9000c1s3b0n1"},
+		{"slot_12-node_0", "x9000c1s12b0n0"},
+		{"x9000c2s3b0n1", ""}, // another chassis
+		{"Node0", ""},
+		{"sys3b0n1", ""},
+	}
+	for _, c := range cases {
+		got, ok := NodeInChassis("x9000c1", c.name)
+		if got != c.want || ok != (c.want != "") {
+			t.Errorf("NodeInChassis(%q) = %q, %v; want %q", c.name, got, ok, c.want)
+		}
+	}
+}
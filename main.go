@@ -5,8 +5,20 @@
 // Package main is the entry point for the ex-bootstrap application.
 package main
 
-import "bootstrap/cmd"
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"bootstrap/cmd"
+)
 
 func main() {
-	cmd.Execute()
+	// SIGINT/SIGTERM cancel the command context so long runs can stop
+	// launching new BMC requests and save what they have.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cmd.Execute(ctx)
+	stop()
+	os.Exit(code)
 }